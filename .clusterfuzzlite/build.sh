@@ -7,3 +7,5 @@ compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/header Fuzz header_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/transportparameters Fuzz transportparameter_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/tokens Fuzz token_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/handshake Fuzz handshake_fuzzer
+compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/fecframes Fuzz fec_frame_fuzzer
+compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/fecdecoder Fuzz fec_decoder_fuzzer
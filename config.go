@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/quicvarint"
 )
@@ -22,6 +23,20 @@ func (c *Config) maxRetryTokenAge() time.Duration {
 	return c.handshakeTimeout()
 }
 
+func (c *Config) newCongestionController() func(congestion.Config) congestion.Controller {
+	if c.NewCongestionController != nil {
+		return c.NewCongestionController
+	}
+	switch c.CongestionControl {
+	case protocol.CongestionControlCubic:
+		return congestion.NewCubic
+	case protocol.CongestionControlBBR:
+		return congestion.NewBBR
+	default:
+		return congestion.NewReno
+	}
+}
+
 func validateConfig(config *Config) error {
 	if config == nil {
 		return nil
@@ -39,6 +54,30 @@ func validateConfig(config *Config) error {
 	if config.MaxConnectionReceiveWindow > quicvarint.Max {
 		config.MaxConnectionReceiveWindow = quicvarint.Max
 	}
+	if config.FECRepairWindowShare < 0 || config.FECRepairWindowShare > 1 {
+		return fmt.Errorf("invalid FEC repair window share: %f", config.FECRepairWindowShare)
+	}
+	if config.FECRecoveredLossBackoff < 0 || config.FECRecoveredLossBackoff > 1 {
+		return fmt.Errorf("invalid FEC recovered loss backoff: %f", config.FECRecoveredLossBackoff)
+	}
+	if config.FECControlFrameClass > protocol.MaxFECProtectionClass {
+		return fmt.Errorf("invalid FEC protection class for control frames: %d", config.FECControlFrameClass)
+	}
+	if config.DatagramSendQueueLen < 0 {
+		return fmt.Errorf("invalid datagram send queue length: %d", config.DatagramSendQueueLen)
+	}
+	if config.DatagramReceiveQueueLen < 0 {
+		return fmt.Errorf("invalid datagram receive queue length: %d", config.DatagramReceiveQueueLen)
+	}
+	if config.MaxPaths < 0 || config.MaxPaths > protocol.MaxPaths {
+		return fmt.Errorf("invalid maximum number of paths: %d", config.MaxPaths)
+	}
+	if config.MultipathScheduler > protocol.MultipathSchedulerRoundRobin {
+		return fmt.Errorf("invalid multipath scheduler: %d", config.MultipathScheduler)
+	}
+	if config.CongestionControl > protocol.CongestionControlBBR {
+		return fmt.Errorf("invalid congestion control algorithm: %d", config.CongestionControl)
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
@@ -95,25 +134,53 @@ func populateConfig(config *Config) *Config {
 		maxIncomingUniStreams = 0
 	}
 
+	maxPaths := config.MaxPaths
+	if maxPaths == 0 {
+		maxPaths = protocol.DefaultMaxPaths
+	}
+	datagramSendQueueLen := config.DatagramSendQueueLen
+	if datagramSendQueueLen == 0 {
+		datagramSendQueueLen = protocol.DefaultDatagramSendQueueLen
+	}
+	datagramReceiveQueueLen := config.DatagramReceiveQueueLen
+	if datagramReceiveQueueLen == 0 {
+		datagramReceiveQueueLen = protocol.DefaultDatagramReceiveQueueLen
+	}
+
 	return &Config{
-		GetConfigForClient:             config.GetConfigForClient,
-		Versions:                       versions,
-		HandshakeIdleTimeout:           handshakeIdleTimeout,
-		MaxIdleTimeout:                 idleTimeout,
-		KeepAlivePeriod:                config.KeepAlivePeriod,
-		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
-		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
-		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
-		MaxConnectionReceiveWindow:     maxConnectionReceiveWindow,
-		AllowConnectionWindowIncrease:  config.AllowConnectionWindowIncrease,
-		MaxIncomingStreams:             maxIncomingStreams,
-		MaxIncomingUniStreams:          maxIncomingUniStreams,
-		TokenStore:                     config.TokenStore,
-		EnableDatagrams:                config.EnableDatagrams,
-		EnableFEC:                      config.EnableFEC,
-		DecoderFECScheme:               config.DecoderFECScheme,
-		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
-		Allow0RTT:                      config.Allow0RTT,
-		Tracer:                         config.Tracer,
+		GetConfigForClient:               config.GetConfigForClient,
+		Versions:                         versions,
+		HandshakeIdleTimeout:             handshakeIdleTimeout,
+		MaxIdleTimeout:                   idleTimeout,
+		KeepAlivePeriod:                  config.KeepAlivePeriod,
+		InitialStreamReceiveWindow:       initialStreamReceiveWindow,
+		MaxStreamReceiveWindow:           maxStreamReceiveWindow,
+		InitialConnectionReceiveWindow:   initialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:       maxConnectionReceiveWindow,
+		AllowConnectionWindowIncrease:    config.AllowConnectionWindowIncrease,
+		MaxIncomingStreams:               maxIncomingStreams,
+		MaxIncomingUniStreams:            maxIncomingUniStreams,
+		TokenStore:                       config.TokenStore,
+		EnableDatagrams:                  config.EnableDatagrams,
+		DatagramSendQueueLen:             datagramSendQueueLen,
+		DatagramReceiveQueueLen:          datagramReceiveQueueLen,
+		EnableFEC:                        config.EnableFEC,
+		DecoderFECScheme:                 config.DecoderFECScheme,
+		FECWireFormat:                    config.FECWireFormat,
+		FECRepairWindowShare:             config.FECRepairWindowShare,
+		FECRecoveredLossBackoff:          config.FECRecoveredLossBackoff,
+		FECControlFrames:                 config.FECControlFrames,
+		FECControlFrameClass:             config.FECControlFrameClass,
+		EnableMultipath:                  config.EnableMultipath,
+		MaxPaths:                         maxPaths,
+		MultipathScheduler:               config.MultipathScheduler,
+		FECRepairPathDiversity:           config.FECRepairPathDiversity,
+		CongestionControl:                config.CongestionControl,
+		NewCongestionController:          config.NewCongestionController,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		EnableStreamResetPartialDelivery: config.EnableStreamResetPartialDelivery,
+		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
+		Allow0RTT:                        config.Allow0RTT,
+		Tracer:                           config.Tracer,
 	}
 }
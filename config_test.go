@@ -7,7 +7,9 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 	"github.com/quic-go/quic-go/quicvarint"
 
@@ -50,6 +52,36 @@ var _ = Describe("Config", func() {
 			Expect(conf.MaxStreamReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 			Expect(conf.MaxConnectionReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 		})
+
+		It("errors on invalid FEC congestion control values", func() {
+			Expect(validateConfig(&Config{FECRepairWindowShare: 0.3, FECRecoveredLossBackoff: 1})).To(Succeed())
+			Expect(validateConfig(&Config{FECRepairWindowShare: 1.1})).To(MatchError("invalid FEC repair window share: 1.100000"))
+			Expect(validateConfig(&Config{FECRecoveredLossBackoff: -0.5})).To(MatchError("invalid FEC recovered loss backoff: -0.500000"))
+		})
+
+		It("errors on an invalid FEC protection class for control frames", func() {
+			Expect(validateConfig(&Config{FECControlFrames: protocol.FECControlFrameMaxData, FECControlFrameClass: protocol.FECProtectionLow})).To(Succeed())
+			Expect(validateConfig(&Config{FECControlFrameClass: 3})).To(MatchError("invalid FEC protection class for control frames: 3"))
+		})
+
+		It("errors on invalid datagram queue lengths", func() {
+			Expect(validateConfig(&Config{DatagramSendQueueLen: 10, DatagramReceiveQueueLen: 10})).To(Succeed())
+			Expect(validateConfig(&Config{DatagramSendQueueLen: -1})).To(MatchError("invalid datagram send queue length: -1"))
+			Expect(validateConfig(&Config{DatagramReceiveQueueLen: -1})).To(MatchError("invalid datagram receive queue length: -1"))
+		})
+
+		It("errors on invalid multipath values", func() {
+			Expect(validateConfig(&Config{EnableMultipath: true, MaxPaths: 2, MultipathScheduler: protocol.MultipathSchedulerRoundRobin})).To(Succeed())
+			Expect(validateConfig(&Config{MaxPaths: -1})).To(MatchError("invalid maximum number of paths: -1"))
+			Expect(validateConfig(&Config{MaxPaths: protocol.MaxPaths})).To(Succeed())
+			Expect(validateConfig(&Config{MaxPaths: protocol.MaxPaths + 1})).To(MatchError("invalid maximum number of paths: 65"))
+			Expect(validateConfig(&Config{MultipathScheduler: 2})).To(MatchError("invalid multipath scheduler: 2"))
+		})
+
+		It("errors on invalid congestion control algorithms", func() {
+			Expect(validateConfig(&Config{CongestionControl: protocol.CongestionControlBBR})).To(Succeed())
+			Expect(validateConfig(&Config{CongestionControl: 3})).To(MatchError("invalid congestion control algorithm: 3"))
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -65,7 +97,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer", "NewCongestionController":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -97,6 +129,10 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Second))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(10))
+			case "DatagramReceiveQueueLen":
+				f.Set(reflect.ValueOf(20))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "DisablePathMTUDiscovery":
@@ -107,6 +143,30 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(true))
 			case "DecoderFECScheme":
 				f.Set(reflect.ValueOf(protocol.XORFECScheme))
+			case "FECWireFormat":
+				f.Set(reflect.ValueOf(protocol.FECWireFormatDraft))
+			case "FECRepairWindowShare":
+				f.Set(reflect.ValueOf(0.25))
+			case "FECRecoveredLossBackoff":
+				f.Set(reflect.ValueOf(0.9))
+			case "FECControlFrames":
+				f.Set(reflect.ValueOf(protocol.FECControlFramesFlowControl))
+			case "FECControlFrameClass":
+				f.Set(reflect.ValueOf(protocol.FECProtectionHigh))
+			case "EnableMultipath":
+				f.Set(reflect.ValueOf(true))
+			case "MaxPaths":
+				f.Set(reflect.ValueOf(3))
+			case "MultipathScheduler":
+				f.Set(reflect.ValueOf(protocol.MultipathSchedulerRoundRobin))
+			case "FECRepairPathDiversity":
+				f.Set(reflect.ValueOf(true))
+			case "CongestionControl":
+				f.Set(reflect.ValueOf(protocol.CongestionControlBBR))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableStreamResetPartialDelivery":
+				f.Set(reflect.ValueOf(true))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -119,6 +179,23 @@ var _ = Describe("Config", func() {
 		Expect(c.handshakeTimeout()).To(Equal(11 * time.Second))
 	})
 
+	It("selects the congestion controller", func() {
+		funcPtr := func(f func(congestion.Config) congestion.Controller) uintptr { return reflect.ValueOf(f).Pointer() }
+		Expect(funcPtr((&Config{}).newCongestionController())).To(Equal(funcPtr(congestion.NewReno)))
+		Expect(funcPtr((&Config{CongestionControl: protocol.CongestionControlCubic}).newCongestionController())).To(Equal(funcPtr(congestion.NewCubic)))
+		Expect(funcPtr((&Config{CongestionControl: protocol.CongestionControlBBR}).newCongestionController())).To(Equal(funcPtr(congestion.NewBBR)))
+		var called bool
+		c := &Config{
+			CongestionControl: protocol.CongestionControlBBR,
+			NewCongestionController: func(c congestion.Config) congestion.Controller {
+				called = true
+				return congestion.NewCubic(c)
+			},
+		}
+		c.newCongestionController()(congestion.Config{RTTStats: utils.NewRTTStats(), InitialMaxDatagramSize: 1234})
+		Expect(called).To(BeTrue())
+	})
+
 	Context("cloning", func() {
 		It("clones function fields", func() {
 			var calledAllowConnectionWindowIncrease, calledTracer bool
@@ -170,6 +247,9 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingStreams))
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
+			Expect(c.MaxPaths).To(Equal(protocol.DefaultMaxPaths))
+			Expect(c.DatagramSendQueueLen).To(Equal(protocol.DefaultDatagramSendQueueLen))
+			Expect(c.DatagramReceiveQueueLen).To(Equal(protocol.DefaultDatagramReceiveQueueLen))
 			Expect(c.GetConfigForClient).To(BeNil())
 		})
 	})
@@ -0,0 +1,60 @@
+// Package congestion defines the interface between quic-go and congestion controllers.
+//
+// A custom congestion controller is configured using quic.Config.NewCongestionController.
+// quic-go tracks the bytes in flight, detects lost packets and measures the delivery rate,
+// and the Controller decides how much data may be sent and when.
+//
+// This package should not be considered stable.
+package congestion
+
+import (
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+type (
+	// A Controller is a congestion controller.
+	// Every path of a connection uses its own Controller.
+	// Its methods are called from the connection's run loop, and don't need to be safe for concurrent use.
+	Controller = congestion.Controller
+	// The Config is passed to the constructor of a Controller.
+	Config = congestion.ControllerConfig
+	// A RateSample is the delivery rate measured when a packet is acknowledged or declared lost,
+	// as described in draft-cheng-iccrg-delivery-rate-estimation.
+	RateSample = congestion.RateSample
+	// An AckedPacket is a packet that was acknowledged.
+	AckedPacket = congestion.AckedPacket
+	// A LostPacket is a packet that was declared lost.
+	LostPacket = congestion.LostPacket
+	// RTTStats are the RTT statistics of a path.
+	RTTStats = congestion.RTTStats
+	// Bandwidth is a rate, in bits per second.
+	Bandwidth = congestion.Bandwidth
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// The PacketNumber is the packet number of a packet.
+	PacketNumber = protocol.PacketNumber
+)
+
+const (
+	// BitsPerSecond is 1 bit per second.
+	BitsPerSecond = congestion.BitsPerSecond
+	// BytesPerSecond is 1 byte per second.
+	BytesPerSecond = congestion.BytesPerSecond
+)
+
+// NewReno creates a Controller that uses TCP NewReno (RFC 6582).
+// This is the congestion controller used by default.
+func NewReno(c Config) Controller {
+	return congestion.NewRenoController(c)
+}
+
+// NewCubic creates a Controller that uses Cubic (RFC 9438).
+func NewCubic(c Config) Controller {
+	return congestion.NewCubicController(c)
+}
+
+// NewBBR creates a Controller that uses BBR (draft-ietf-ccwg-bbr).
+func NewBBR(c Config) Controller {
+	return congestion.NewBBRController(c)
+}
@@ -24,6 +24,10 @@ type connIDManager struct {
 	highestRetired            uint64
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
+	// connection IDs used on paths that are not (yet) the active path
+	pathConnIDs map[pathID]newConnID
+	// sequence numbers of retired path connection IDs larger than highestRetired
+	retiredPathSeqs []uint64
 
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
@@ -59,7 +63,7 @@ func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
 	if err := h.add(f); err != nil {
 		return err
 	}
-	if h.queue.Len() >= protocol.MaxActiveConnectionIDs {
+	if h.queue.Len()+len(h.pathConnIDs) >= protocol.MaxActiveConnectionIDs {
 		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
 	}
 	return nil
@@ -68,7 +72,7 @@ func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
 func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 	// If the NEW_CONNECTION_ID frame is reordered, such that its sequence number is smaller than the currently active
 	// connection ID or if it was already retired, send the RETIRE_CONNECTION_ID frame immediately.
-	if f.SequenceNumber < h.activeSequenceNumber || f.SequenceNumber < h.highestRetired {
+	if f.SequenceNumber < h.activeSequenceNumber || f.SequenceNumber < h.highestRetired || h.isRetiredPathSeq(f.SequenceNumber) {
 		h.queueControlFrame(&wire.RetireConnectionIDFrame{
 			SequenceNumber: f.SequenceNumber,
 		})
@@ -89,12 +93,23 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			})
 			h.queue.Remove(el)
 		}
+		for id, c := range h.pathConnIDs {
+			if c.SequenceNumber < f.RetirePriorTo {
+				h.RetireConnIDForPath(id)
+			}
+		}
 		h.highestRetired = f.RetirePriorTo
+		h.pruneRetiredPathSeqs()
 	}
 
 	if f.SequenceNumber == h.activeSequenceNumber {
 		return nil
 	}
+	for _, c := range h.pathConnIDs {
+		if c.SequenceNumber == f.SequenceNumber {
+			return nil
+		}
+	}
 
 	if err := h.addConnectionID(f.SequenceNumber, f.ConnectionID, f.StatelessResetToken); err != nil {
 		return err
@@ -142,27 +157,105 @@ func (h *connIDManager) addConnectionID(seq uint64, connID protocol.ConnectionID
 }
 
 func (h *connIDManager) updateConnectionID() {
+	h.retireActiveConnectionID()
+	front := h.queue.Remove(h.queue.Front())
+	h.setActiveConnectionID(front)
+	h.addStatelessResetToken(*h.activeStatelessResetToken)
+}
+
+func (h *connIDManager) retireActiveConnectionID() {
 	h.queueControlFrame(&wire.RetireConnectionIDFrame{
 		SequenceNumber: h.activeSequenceNumber,
 	})
 	h.highestRetired = max(h.highestRetired, h.activeSequenceNumber)
+	h.pruneRetiredPathSeqs()
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+}
 
-	front := h.queue.Remove(h.queue.Front())
-	h.activeSequenceNumber = front.SequenceNumber
-	h.activeConnectionID = front.ConnectionID
-	h.activeStatelessResetToken = &front.StatelessResetToken
+func (h *connIDManager) setActiveConnectionID(c newConnID) {
+	h.activeSequenceNumber = c.SequenceNumber
+	h.activeConnectionID = c.ConnectionID
+	h.activeStatelessResetToken = &c.StatelessResetToken
 	h.packetsSinceLastChange = 0
 	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
-	h.addStatelessResetToken(*h.activeStatelessResetToken)
+}
+
+// GetConnIDForPath returns the connection ID used on a path that is not the active path.
+// An endpoint must not use the same connection ID on different paths,
+// so every path is assigned an unused connection ID provided by the peer.
+// It returns false if no unused connection ID is available.
+func (h *connIDManager) GetConnIDForPath(id pathID) (protocol.ConnectionID, bool) {
+	if c, ok := h.pathConnIDs[id]; ok {
+		return c.ConnectionID, true
+	}
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	c := h.queue.Remove(h.queue.Front())
+	if h.pathConnIDs == nil {
+		h.pathConnIDs = make(map[pathID]newConnID)
+	}
+	h.pathConnIDs[id] = c
+	h.addStatelessResetToken(c.StatelessResetToken)
+	return c.ConnectionID, true
+}
+
+// SwitchToPath is called when the connection starts using a path.
+// The path's connection ID becomes the active connection ID, and the previously active connection ID is retired.
+// If no connection ID was assigned to the path, the active connection ID continues to be used.
+func (h *connIDManager) SwitchToPath(id pathID) {
+	c, ok := h.pathConnIDs[id]
+	if !ok {
+		return
+	}
+	delete(h.pathConnIDs, id)
+	h.retireActiveConnectionID()
+	h.setActiveConnectionID(c)
+}
+
+// RetireConnIDForPath retires the connection ID assigned to a path, when the path is abandoned.
+func (h *connIDManager) RetireConnIDForPath(id pathID) {
+	c, ok := h.pathConnIDs[id]
+	if !ok {
+		return
+	}
+	delete(h.pathConnIDs, id)
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{SequenceNumber: c.SequenceNumber})
+	h.removeStatelessResetToken(c.StatelessResetToken)
+	if c.SequenceNumber >= h.highestRetired {
+		h.retiredPathSeqs = append(h.retiredPathSeqs, c.SequenceNumber)
+	}
+}
+
+func (h *connIDManager) isRetiredPathSeq(seq uint64) bool {
+	for _, s := range h.retiredPathSeqs {
+		if s == seq {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *connIDManager) pruneRetiredPathSeqs() {
+	var n int
+	for _, s := range h.retiredPathSeqs {
+		if s >= h.highestRetired {
+			h.retiredPathSeqs[n] = s
+			n++
+		}
+	}
+	h.retiredPathSeqs = h.retiredPathSeqs[:n]
 }
 
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+	for _, c := range h.pathConnIDs {
+		h.removeStatelessResetToken(c.StatelessResetToken)
+	}
 }
 
 // is called when the server performs a Retry
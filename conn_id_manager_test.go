@@ -361,4 +361,113 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("using multiple paths", func() {
+		addConnIDs := func(seqs ...uint64) {
+			for _, seq := range seqs {
+				ExpectWithOffset(1, m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      seq,
+					ConnectionID:        protocol.ParseConnectionID([]byte{byte(seq), byte(seq), byte(seq), byte(seq)}),
+					StatelessResetToken: protocol.StatelessResetToken{byte(seq)},
+				})).To(Succeed())
+			}
+		}
+
+		It("assigns an unused connection ID to a path", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeFalse())
+			addConnIDs(1, 2)
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+			// the same connection ID is returned for the same path
+			connID, ok = m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			connID, ok = m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			// the active connection ID isn't changed, since no unused connection IDs are left
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(initialConnID))
+		})
+
+		It("counts the connection IDs assigned to paths towards the limit", func() {
+			addConnIDs(1, 2)
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			for i := uint64(3); i < protocol.MaxActiveConnectionIDs; i++ {
+				addConnIDs(i)
+			}
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber: protocol.MaxActiveConnectionIDs,
+				ConnectionID:   protocol.ParseConnectionID([]byte{1, 3, 3, 7}),
+			})).To(MatchError(&qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}))
+		})
+
+		It("switches to a path", func() {
+			addConnIDs(1, 2)
+			m.SetStatelessResetToken(protocol.StatelessResetToken{42})
+			_, ok := m.GetConnIDForPath(7)
+			Expect(ok).To(BeTrue())
+			m.SwitchToPath(7)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{42}}))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			Expect(m.pathConnIDs).To(BeEmpty())
+		})
+
+		It("keeps the active connection ID when switching to a path without a connection ID", func() {
+			m.SwitchToPath(7)
+			Expect(frameQueue).To(BeEmpty())
+			Expect(m.Get()).To(Equal(initialConnID))
+		})
+
+		It("retires the connection ID of a path", func() {
+			addConnIDs(1, 2)
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			m.RetireConnIDForPath(1)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}}))
+			// a retransmission of the NEW_CONNECTION_ID frame is retired right away
+			frameQueue = nil
+			addConnIDs(1)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+		})
+
+		It("retires the connection ID of a path when the peer requests it", func() {
+			addConnIDs(1, 2)
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber: 3,
+				ConnectionID:   protocol.ParseConnectionID([]byte{3, 3, 3, 3}),
+				RetirePriorTo:  2,
+			})).To(Succeed())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+			Expect(m.pathConnIDs).To(BeEmpty())
+		})
+
+		It("ignores retransmissions of NEW_CONNECTION_ID frames for connection IDs assigned to paths", func() {
+			addConnIDs(1)
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			addConnIDs(1)
+			Expect(m.queue.Len()).To(BeZero())
+			Expect(frameQueue).To(BeEmpty())
+		})
+
+		It("removes the stateless reset tokens of paths when it is closed", func() {
+			addConnIDs(1)
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			m.Close()
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}}))
+		})
+	})
 })
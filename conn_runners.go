@@ -0,0 +1,164 @@
+package quic
+
+import (
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+type connRunnerRef struct {
+	runner connRunner
+	refs   int
+}
+
+// connRunners is used by a client connection that uses multiple transports.
+// It registers the connection IDs and stateless reset tokens of the connection with the packet handler managers
+// of all transports, such that packets received on any of the transports are passed to the connection.
+type connRunners struct {
+	mutex   sync.Mutex
+	runners []*connRunnerRef
+
+	connIDs     map[protocol.ConnectionID]packetHandler
+	resetTokens map[protocol.StatelessResetToken]packetHandler
+	// Retired connection IDs are only deleted by the runners after a delay.
+	// They need to be removed from runners that are not used any more.
+	retiredConnIDs map[protocol.ConnectionID]struct{}
+}
+
+var _ connRunner = &connRunners{}
+
+// newConnRunners creates a new connRunners.
+// The initial connection ID was already added to the runner by the caller.
+func newConnRunners(runner connRunner, initialConnID protocol.ConnectionID, handler packetHandler) *connRunners {
+	return &connRunners{
+		runners:        []*connRunnerRef{{runner: runner, refs: 1}},
+		connIDs:        map[protocol.ConnectionID]packetHandler{initialConnID: handler},
+		resetTokens:    make(map[protocol.StatelessResetToken]packetHandler),
+		retiredConnIDs: make(map[protocol.ConnectionID]struct{}),
+	}
+}
+
+// AddRunner starts using a runner.
+// If the runner is not in use yet, all connection IDs and stateless reset tokens are added to it.
+func (r *connRunners) AddRunner(runner connRunner) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, ref := range r.runners {
+		if ref.runner == runner {
+			ref.refs++
+			return
+		}
+	}
+	r.runners = append(r.runners, &connRunnerRef{runner: runner, refs: 1})
+	for connID, handler := range r.connIDs {
+		runner.Add(connID, handler)
+	}
+	for token, handler := range r.resetTokens {
+		runner.AddResetToken(token, handler)
+	}
+}
+
+// RemoveRunner stops using a runner.
+// Once the runner is not used any more, all connection IDs and stateless reset tokens are removed from it.
+// The last runner is never removed, since a closed connection needs to be replaced with a closed connection.
+func (r *connRunners) RemoveRunner(runner connRunner) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, ref := range r.runners {
+		if ref.runner != runner {
+			continue
+		}
+		if ref.refs > 1 || len(r.runners) == 1 {
+			ref.refs--
+			return
+		}
+		r.runners = append(r.runners[:i], r.runners[i+1:]...)
+		for connID := range r.connIDs {
+			runner.Remove(connID)
+		}
+		for connID := range r.retiredConnIDs {
+			runner.Remove(connID)
+		}
+		for token := range r.resetTokens {
+			runner.RemoveResetToken(token)
+		}
+		return
+	}
+}
+
+func (r *connRunners) Add(connID protocol.ConnectionID, handler packetHandler) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.connIDs[connID] = handler
+	added := true
+	for _, ref := range r.runners {
+		if !ref.runner.Add(connID, handler) {
+			added = false
+		}
+	}
+	return added
+}
+
+// GetStatelessResetToken returns the stateless reset token generated by the runner of the transport the connection was dialed on.
+func (r *connRunners) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.runners[0].runner.GetStatelessResetToken(connID)
+}
+
+func (r *connRunners) Retire(connID protocol.ConnectionID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.connIDs, connID)
+	r.retiredConnIDs[connID] = struct{}{}
+	for _, ref := range r.runners {
+		ref.runner.Retire(connID)
+	}
+}
+
+func (r *connRunners) Remove(connID protocol.ConnectionID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.connIDs, connID)
+	for _, ref := range r.runners {
+		ref.runner.Remove(connID)
+	}
+}
+
+func (r *connRunners) ReplaceWithClosed(connIDs []protocol.ConnectionID, connClosePacket []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, connID := range connIDs {
+		delete(r.connIDs, connID)
+	}
+	for _, ref := range r.runners {
+		ref.runner.ReplaceWithClosed(connIDs, connClosePacket)
+	}
+}
+
+func (r *connRunners) AddResetToken(token protocol.StatelessResetToken, handler packetHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.resetTokens[token] = handler
+	for _, ref := range r.runners {
+		ref.runner.AddResetToken(token, handler)
+	}
+}
+
+func (r *connRunners) RemoveResetToken(token protocol.StatelessResetToken) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.resetTokens, token)
+	for _, ref := range r.runners {
+		ref.runner.RemoveResetToken(token)
+	}
+}
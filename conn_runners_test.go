@@ -0,0 +1,112 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Runners", func() {
+	var (
+		runners        *connRunners
+		runner1        *MockConnRunner
+		handler        *MockPacketHandler
+		initialConnID  = protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connID         = protocol.ParseConnectionID([]byte{5, 6, 7, 8})
+		statelessReset = protocol.StatelessResetToken{1, 2, 3}
+	)
+
+	BeforeEach(func() {
+		runner1 = NewMockConnRunner(mockCtrl)
+		handler = NewMockPacketHandler(mockCtrl)
+		runners = newConnRunners(runner1, initialConnID, handler)
+	})
+
+	It("forwards calls to the runner", func() {
+		runner1.EXPECT().Add(connID, handler).Return(true)
+		Expect(runners.Add(connID, handler)).To(BeTrue())
+		runner1.EXPECT().AddResetToken(statelessReset, handler)
+		runners.AddResetToken(statelessReset, handler)
+		runner1.EXPECT().GetStatelessResetToken(connID).Return(statelessReset)
+		Expect(runners.GetStatelessResetToken(connID)).To(Equal(statelessReset))
+		runner1.EXPECT().RemoveResetToken(statelessReset)
+		runners.RemoveResetToken(statelessReset)
+		runner1.EXPECT().Retire(connID)
+		runners.Retire(connID)
+		runner1.EXPECT().Remove(initialConnID)
+		runners.Remove(initialConnID)
+	})
+
+	It("adds the connection IDs and stateless reset tokens to new runners", func() {
+		runner1.EXPECT().Add(connID, handler).Return(true)
+		Expect(runners.Add(connID, handler)).To(BeTrue())
+		runner1.EXPECT().AddResetToken(statelessReset, handler)
+		runners.AddResetToken(statelessReset, handler)
+
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(initialConnID, handler).Return(true)
+		runner2.EXPECT().Add(connID, handler).Return(true)
+		runner2.EXPECT().AddResetToken(statelessReset, handler)
+		runners.AddRunner(runner2)
+		// adding the runner a second time doesn't add the connection IDs again
+		runners.AddRunner(runner2)
+
+		newConnID := protocol.ParseConnectionID([]byte{9, 9, 9, 9})
+		runner1.EXPECT().Add(newConnID, handler).Return(true)
+		runner2.EXPECT().Add(newConnID, handler).Return(true)
+		Expect(runners.Add(newConnID, handler)).To(BeTrue())
+		runner1.EXPECT().ReplaceWithClosed([]protocol.ConnectionID{connID}, []byte("foobar"))
+		runner2.EXPECT().ReplaceWithClosed([]protocol.ConnectionID{connID}, []byte("foobar"))
+		runners.ReplaceWithClosed([]protocol.ConnectionID{connID}, []byte("foobar"))
+	})
+
+	It("removes the connection IDs and stateless reset tokens from runners that are not used anymore", func() {
+		runner1.EXPECT().AddResetToken(statelessReset, handler)
+		runners.AddResetToken(statelessReset, handler)
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(initialConnID, handler).Return(true)
+		runner2.EXPECT().AddResetToken(statelessReset, handler)
+		runners.AddRunner(runner2)
+		runners.AddRunner(runner2)
+
+		runners.RemoveRunner(runner2)
+		runner2.EXPECT().Remove(initialConnID)
+		runner2.EXPECT().RemoveResetToken(statelessReset)
+		runners.RemoveRunner(runner2)
+
+		// the stateless reset token is only removed from the remaining runner
+		runner1.EXPECT().RemoveResetToken(statelessReset)
+		runners.RemoveResetToken(statelessReset)
+
+		// the first runner can be removed as well, as long as another runner is used
+		runner3 := NewMockConnRunner(mockCtrl)
+		runner3.EXPECT().Add(initialConnID, handler).Return(true)
+		runners.AddRunner(runner3)
+		runner1.EXPECT().Remove(initialConnID)
+		runners.RemoveRunner(runner1)
+		runner3.EXPECT().GetStatelessResetToken(connID).Return(statelessReset)
+		Expect(runners.GetStatelessResetToken(connID)).To(Equal(statelessReset))
+	})
+
+	It("removes retired connection IDs from runners that are not used anymore", func() {
+		runner2 := NewMockConnRunner(mockCtrl)
+		runner2.EXPECT().Add(initialConnID, handler).Return(true)
+		runners.AddRunner(runner2)
+		runner1.EXPECT().Retire(initialConnID)
+		runner2.EXPECT().Retire(initialConnID)
+		runners.Retire(initialConnID)
+
+		runner1.EXPECT().Remove(initialConnID)
+		runners.RemoveRunner(runner1)
+		// retired connection IDs are not added to new runners
+		runner3 := NewMockConnRunner(mockCtrl)
+		runners.AddRunner(runner3)
+	})
+
+	It("never removes the last runner", func() {
+		runners.RemoveRunner(runner1)
+		runner1.EXPECT().Retire(initialConnID)
+		runners.Retire(initialConnID)
+	})
+})
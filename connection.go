@@ -3,11 +3,13 @@ package quic
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -28,6 +30,7 @@ import (
 type unpacker interface {
 	UnpackLongHeader(hdr *wire.Header, rcvTime time.Time, data []byte, v protocol.Version) (*unpackedPacket, error)
 	UnpackShortHeader(rcvTime time.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
+	UnpackPathShortHeader(opener handshake.ShortHeaderOpener, rcvTime time.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
 }
 
 type streamGetter interface {
@@ -64,6 +67,7 @@ type cryptoStreamHandler interface {
 	DiscardInitialKeys()
 	io.Closer
 	ConnectionState() handshake.ConnectionState
+	Get1RTTPathAEAD(protocol.PathID) (handshake.PathAEAD, error)
 }
 
 type receivedPacket struct {
@@ -134,6 +138,9 @@ type connection struct {
 	version     protocol.Version
 	config      *Config
 
+	// connMutex protects conn and sendQueue, which change when the connection migrates to a new path.
+	// They are only modified by the run loop, so the run loop can access them without holding the mutex.
+	connMutex sync.Mutex
 	conn      sendConn
 	sendQueue sender
 
@@ -184,6 +191,12 @@ type connection struct {
 	handshakeComplete  bool
 	handshakeConfirmed bool
 
+	// set if both endpoints support the ACK frequency extension
+	ackFrequencyEnabled bool
+	// set if both endpoints support the reliable stream reset extension
+	// It is read by the streams when the application resets a stream.
+	resetStreamAtEnabled atomic.Bool
+
 	receivedRetry       bool
 	versionNegotiated   bool
 	receivedFirstPacket bool
@@ -217,6 +230,18 @@ type connection struct {
 
 	fecReceiver fec.Receiver
 	repairQueue *repairQueue
+	// the epoch of the last FEC_WINDOW frame received, used to ignore reordered frames
+	fecPeerWindowEpoch    protocol.FECWindowEpoch
+	receivedFECPeerWindow bool
+
+	pathManager         *pathManager         // only set for the server
+	pathManagerOutgoing *pathManagerOutgoing // only set for the client
+	// the largest packet number of a non-probing packet, used by the server to detect migrations
+	largestNonProbingPN protocol.PacketNumber
+
+	// multipath is set once the use of multiple paths was negotiated
+	multipath          *multipathManager
+	multipathScheduler *multipathScheduler
 }
 
 var (
@@ -255,6 +280,7 @@ var newConnection = func(
 		tracer:              tracer,
 		logger:              logger,
 		version:             v,
+		largestNonProbingPN: protocol.InvalidPacketNumber,
 	}
 	if origDestConnID.Len() > 0 {
 		s.logID = origDestConnID.String()
@@ -278,7 +304,8 @@ var newConnection = func(
 		s.queueControlFrame,
 		connIDGenerator,
 	)
-	fecReceiver, err := fec.NewReceiver(s.config.DecoderFECScheme)
+	s.pathManager = newPathManager(s.connIDManager.GetConnIDForPath, s.connIDManager.RetireConnIDForPath, s.logger)
+	fecReceiver, err := fec.NewClassReceiver(s.config.DecoderFECScheme)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -291,6 +318,9 @@ var newConnection = func(
 		s.rttStats,
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
+		s.config.FECRepairWindowShare,
+		s.config.FECRecoveredLossBackoff,
+		s.config.newCongestionController(),
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -306,7 +336,6 @@ var newConnection = func(
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
-		DisableActiveMigration:          true,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -329,6 +358,17 @@ var newConnection = func(
 		params.EnableFEC = 0x0
 	}
 	params.DecoderFECScheme = s.config.DecoderFECScheme
+	params.FECWireFormat = s.config.FECWireFormat
+	// Multipath requires non-zero-length connection IDs.
+	if s.config.EnableMultipath && srcConnID.Len() > 0 {
+		maxPathID := protocol.PathID(max(s.config.MaxPaths, 1) - 1)
+		params.InitialMaxPathID = &maxPathID
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	params.EnableResetStreamAt = s.config.EnableStreamResetPartialDelivery
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -345,7 +385,7 @@ var newConnection = func(
 		s.version,
 	)
 	s.cryptoStreamHandler = cs
-	s.packer = newPacketPackerWithFEC(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective, s.repairQueue)
+	s.packer = newPacketPackerWithFEC(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective, s.repairQueue, s.config.FECControlFrames, s.config.FECControlFrameClass)
 	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
 	s.cryptoStreamManager = newCryptoStreamManager(cs, s.initialStream, s.handshakeStream, s.oneRTTStream)
 	return s
@@ -381,24 +421,28 @@ var newClientConnection = func(
 		versionNegotiated:   hasNegotiatedVersion,
 		version:             v,
 	}
+	// When migrating to a new path, the client might use multiple transports.
+	// The connection IDs are then registered with the packet handler managers of all of them.
+	runners := newConnRunners(runner, srcConnID, s)
+	s.pathManagerOutgoing = newPathManagerOutgoing(runners, runner, s.scheduleSending)
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { runners.AddResetToken(token, s) },
+		runners.RemoveResetToken,
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		nil,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		func(connID protocol.ConnectionID) { runners.Add(connID, s) },
+		runners.GetStatelessResetToken,
+		runners.Remove,
+		runners.Retire,
+		runners.ReplaceWithClosed,
 		s.queueControlFrame,
 		connIDGenerator,
 	)
-	fecReceiver, err := fec.NewReceiver(s.config.DecoderFECScheme)
+	fecReceiver, err := fec.NewClassReceiver(s.config.DecoderFECScheme)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -411,6 +455,9 @@ var newClientConnection = func(
 		s.rttStats,
 		false, // has no effect
 		s.conn.capabilities().ECN,
+		s.config.FECRepairWindowShare,
+		s.config.FECRecoveredLossBackoff,
+		s.config.newCongestionController(),
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -447,6 +494,17 @@ var newClientConnection = func(
 		params.EnableFEC = 0x0
 	}
 	params.DecoderFECScheme = s.config.DecoderFECScheme
+	params.FECWireFormat = s.config.FECWireFormat
+	// Multipath requires non-zero-length connection IDs.
+	if s.config.EnableMultipath && srcConnID.Len() > 0 {
+		maxPathID := protocol.PathID(max(s.config.MaxPaths, 1) - 1)
+		params.InitialMaxPathID = &maxPathID
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	params.EnableResetStreamAt = s.config.EnableStreamResetPartialDelivery
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -463,7 +521,7 @@ var newClientConnection = func(
 	s.cryptoStreamHandler = cs
 	s.cryptoStreamManager = newCryptoStreamManager(cs, s.initialStream, s.handshakeStream, oneRTTStream)
 	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
-	s.packer = newPacketPackerWithFEC(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective, s.repairQueue)
+	s.packer = newPacketPackerWithFEC(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective, s.repairQueue, s.config.FECControlFrames, s.config.FECControlFrameClass)
 	if len(tlsConf.ServerName) > 0 {
 		s.tokenStoreKey = tlsConf.ServerName
 	} else {
@@ -483,6 +541,9 @@ func (s *connection) preSetup() {
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue()
 	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams)
+	s.frameParser.SetSupportsMultipath(s.config.EnableMultipath)
+	s.frameParser.SetSupportsAckFrequency(s.config.EnableAckFrequency)
+	s.frameParser.SetSupportsResetStreamAt(s.config.EnableStreamResetPartialDelivery)
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
@@ -516,7 +577,7 @@ func (s *connection) preSetup() {
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config.DatagramSendQueueLen, s.config.DatagramReceiveQueueLen, s.tracer, s.logger)
 	s.repairQueue = newRepairQueue(s.scheduleSending)
 	s.connState.Version = s.version
 }
@@ -536,11 +597,7 @@ func (s *connection) run() error {
 	if err := s.handleHandshakeEvents(); err != nil {
 		return err
 	}
-	go func() {
-		if err := s.sendQueue.Run(); err != nil {
-			s.destroyImpl(err)
-		}
-	}()
+	s.runSendQueue(s.sendQueue)
 
 	if s.perspective == protocol.PerspectiveClient {
 		s.scheduleSending() // so the ClientHello actually gets sent
@@ -638,6 +695,15 @@ runLoop:
 				s.closeLocal(err)
 			}
 		}
+		if s.multipath != nil {
+			for _, path := range s.multipath.Paths() {
+				if timeout := path.sentPacketHandler.GetLossDetectionTimeout(); !timeout.IsZero() && timeout.Before(now) {
+					if err := path.sentPacketHandler.OnLossDetectionTimeout(); err != nil {
+						s.closeLocal(err)
+					}
+				}
+			}
+		}
 
 		if keepAliveTime := s.nextKeepAliveTime(); !keepAliveTime.IsZero() && !now.Before(keepAliveTime) {
 			// send a PING frame since there is no activity in the connection
@@ -674,6 +740,11 @@ runLoop:
 
 	s.cryptoStreamHandler.Close()
 	s.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
+	if s.multipath != nil {
+		for _, path := range s.multipath.Paths() {
+			path.sendQueue.Close()
+		}
+	}
 	s.handleCloseError(&closeErr)
 	if s.tracer != nil && s.tracer.Close != nil {
 		if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
@@ -703,7 +774,7 @@ func (s *connection) supportsDatagrams() bool {
 }
 
 func (s *connection) fecEnabled() bool {
-	return s.peerParams.EnableFEC == 0x1 && s.config.EnableFEC
+	return s.peerParams != nil && s.peerParams.EnableFEC == 0x1 && s.config.EnableFEC && s.peerParams.FECWireFormat == s.config.FECWireFormat
 }
 
 func (s *connection) ConnectionState() ConnectionState {
@@ -712,10 +783,16 @@ func (s *connection) ConnectionState() ConnectionState {
 	cs := s.cryptoStreamHandler.ConnectionState()
 	s.connState.TLS = cs.ConnectionState
 	s.connState.Used0RTT = cs.Used0RTT
+	s.connMutex.Lock()
 	s.connState.GSO = s.conn.capabilities().GSO
+	s.connMutex.Unlock()
 	return s.connState
 }
 
+func (s *connection) LossEstimate() LossEstimate {
+	return s.sentPacketHandler.LossEstimate()
+}
+
 // Time when the connection should time out
 func (s *connection) nextIdleTimeoutTime() time.Time {
 	idleTimeout := max(s.idleTimeout, s.rttStats.PTO(true)*3)
@@ -747,12 +824,22 @@ func (s *connection) maybeResetTimer() {
 		}
 	}
 
-	s.timer.SetTimer(
-		deadline,
-		s.receivedPacketHandler.GetAlarmTimeout(),
-		s.sentPacketHandler.GetLossDetectionTimeout(),
-		s.pacingDeadline,
-	)
+	if s.pathManagerOutgoing != nil && s.handshakeConfirmed {
+		if t := s.pathManagerOutgoing.NextProbeTime(); !t.IsZero() {
+			deadline = utils.MinTime(deadline, t)
+		}
+	}
+
+	ackAlarm := s.receivedPacketHandler.GetAlarmTimeout()
+	lossTime := s.sentPacketHandler.GetLossDetectionTimeout()
+	if s.multipath != nil {
+		for _, path := range s.multipath.Paths() {
+			ackAlarm = utils.MinNonZeroTime(ackAlarm, path.receivedPacketHandler.GetAlarmTimeout())
+			lossTime = utils.MinNonZeroTime(lossTime, path.sentPacketHandler.GetLossDetectionTimeout())
+		}
+	}
+
+	s.timer.SetTimer(deadline, ackAlarm, lossTime, s.pacingDeadline)
 }
 
 func (s *connection) idleTimeoutStartTime() time.Time {
@@ -775,8 +862,7 @@ func (s *connection) handleHandshakeComplete() error {
 	// The server applies transport parameters right away, but the client side has to wait for handshake completion.
 	// During a 0-RTT connection, the client is only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveClient {
-		s.applyTransportParameters()
-		return nil
+		return s.applyTransportParameters()
 	}
 
 	// All these only apply to the server side.
@@ -819,6 +905,10 @@ func (s *connection) handleHandshakeConfirmed() error {
 		}
 		s.mtuDiscoverer.Start(min(maxPacketSize, protocol.MaxPacketBufferSize))
 	}
+	// Migrating to the preferred address is not supported on multipath connections.
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil && s.multipath == nil {
+		s.migrateToPreferredAddress(s.peerParams.PreferredAddress)
+	}
 	return nil
 }
 
@@ -912,6 +1002,15 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 }
 
 func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protocol.ConnectionID) bool {
+	// Packets sent on the paths of a multipath connection are identified by their connection ID.
+	if s.multipath != nil {
+		if connID, err := wire.ParseConnectionID(p.data, s.srcConnIDLen); err == nil {
+			if id, ok := s.multipath.PathForConnID(connID); ok {
+				return s.handleMultipathPacket(id, connID, p)
+			}
+		}
+	}
+
 	var wasQueued bool
 
 	defer func() {
@@ -956,13 +1055,510 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	// The server handles packets received from a new remote address as a potential connection migration.
+	onNewPath := s.pathManager != nil &&
+		s.handshakeConfirmed &&
+		p.remoteAddr != nil &&
+		!addrsEqual(p.remoteAddr, s.conn.RemoteAddr())
+	isNonProbing, pathChallenge, err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log, onNewPath)
+	if err != nil {
+		s.closeLocal(err)
+		return false
+	}
+	if s.pathManager == nil {
+		return true
+	}
+	isLargestNonProbing := isNonProbing && (s.largestNonProbingPN == protocol.InvalidPacketNumber || pn > s.largestNonProbingPN)
+	if isLargestNonProbing {
+		s.largestNonProbingPN = pn
+	}
+	if onNewPath {
+		s.handleNewPathPacket(p, pathChallenge, isNonProbing, isLargestNonProbing)
+	}
+	return true
+}
+
+// handleNewPathPacket is called by the server for a packet received from a remote address
+// other than the remote address of the active path.
+func (s *connection) handleNewPathPacket(p receivedPacket, pathChallenge *wire.PathChallengeFrame, isNonProbing, isLargestNonProbing bool) {
+	id, connID, frames, shouldSwitch := s.pathManager.HandlePacket(
+		p.remoteAddr,
+		p.info,
+		p.rcvTime,
+		s.rttStats.PTO(false),
+		pathChallenge,
+		isNonProbing && isLargestNonProbing,
+		p.Size(),
+	)
+	if len(frames) > 0 {
+		size := min(protocol.ByteCount(protocol.MinInitialPacketSize), s.pathManager.AmplificationLimit(id))
+		if size > 0 {
+			conn := s.conn.forRemoteAddr(p.remoteAddr, p.info)
+			s.pathManager.SentPacket(id, s.sendPathProbe(conn, connID, frames, size, p.rcvTime))
+		}
+	}
+	// Only switch to the new path if this is the packet with the largest packet number,
+	// see section 9.3 of RFC 9000.
+	if shouldSwitch && isLargestNonProbing {
+		s.switchServerPath(id, p.remoteAddr, p.info)
+	}
+}
+
+func (s *connection) switchServerPath(id pathID, addr net.Addr, info packetInfo) {
+	s.logger.Debugf("Migrating connection to %s (path %d)", addr, id)
+	s.pathManager.SwitchedPath(id)
+	s.connIDManager.SwitchToPath(id)
+	// If only the port changed, this is most likely a NAT rebinding,
+	// and there's no need to reset the congestion controller.
+	resetCongestion := !onlyPortChanged(s.conn.RemoteAddr(), addr)
+	s.switchToNewPath(s.conn.forRemoteAddr(addr, info), resetCongestion)
+}
+
+// switchToNewPath makes the connection send all subsequent packets using conn.
+func (s *connection) switchToNewPath(conn sendConn, resetCongestion bool) {
+	q := newSendQueue(conn)
+	s.connMutex.Lock()
+	oldQueue := s.sendQueue
+	s.conn = conn
+	s.sendQueue = q
+	s.connMutex.Unlock()
+	s.runSendQueue(q)
+	// packets that were already queued are still sent on the old path
+	oldQueue.Close()
+
+	if !resetCongestion {
+		return
+	}
+	s.sentPacketHandler.MigratedPath(getMaxPacketSize(conn.RemoteAddr()))
+	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize)
+	if !s.config.DisablePathMTUDiscovery && conn.capabilities().DF {
+		maxPacketSize := protocol.ByteCount(protocol.MaxByteCount)
+		if s.peerParams.MaxUDPPayloadSize > 0 && s.peerParams.MaxUDPPayloadSize < maxPacketSize {
+			maxPacketSize = s.peerParams.MaxUDPPayloadSize
+		}
+		s.mtuDiscoverer.Start(min(maxPacketSize, protocol.MaxPacketBufferSize))
+	}
+}
+
+func (s *connection) runSendQueue(q sender) {
+	go func() {
+		if err := q.Run(); err != nil {
+			// Errors of send queues that were replaced when migrating to a new path are ignored.
+			s.connMutex.Lock()
+			isActive := s.sendQueue == q
+			s.connMutex.Unlock()
+			if isActive {
+				s.destroyImpl(err)
+			}
+		}
+	}()
+}
+
+func (s *connection) handlePathResponseFrame(f *wire.PathResponseFrame) {
+	if s.perspective == protocol.PerspectiveClient {
+		id, ok := s.pathManagerOutgoing.HandlePathResponseFrame(f)
+		if !ok || s.multipath == nil {
+			return
+		}
+		if path := s.multipath.Path(protocol.PathID(id)); path != nil && !path.abandoned {
+			s.logger.Debugf("Validated path %d", id)
+			path.validated = true
+		}
+		return
+	}
+	// The PATH_RESPONSE can be received on any path.
+	if s.multipath != nil {
+		for _, path := range s.multipath.Paths() {
+			if !path.validated && !path.pathChallengeSent.IsZero() && path.pathChallenge == f.Data {
+				s.logger.Debugf("Validated path %d", path.id)
+				path.validated = true
+				return
+			}
+		}
+	}
+	id, addr, info, shouldSwitch := s.pathManager.HandlePathResponseFrame(f)
+	if shouldSwitch {
+		s.switchServerPath(id, addr, info)
+	}
+}
+
+// handleOutgoingPaths is called by the client to switch to a new path, and to send PATH_CHALLENGEs on new paths.
+func (s *connection) handleOutgoingPaths(now time.Time) error {
+	pm := s.pathManagerOutgoing
+	for _, id := range pm.PathsToRetire() {
+		if s.multipath != nil {
+			if path := s.multipath.Path(protocol.PathID(id)); path != nil {
+				s.abandonMultipathPath(path)
+			}
+			continue
+		}
+		s.connIDManager.RetireConnIDForPath(id)
+	}
+	if id, conn, ok := pm.ShouldSwitchPath(); ok {
+		s.logger.Debugf("Migrating connection to %s (path %d)", conn.LocalAddr(), id)
+		// make sure that we use a new connection ID on the new path
+		s.connIDManager.GetConnIDForPath(id)
+		s.connIDManager.SwitchToPath(id)
+		s.switchToNewPath(conn, true)
+	}
+	pto := s.rttStats.PTO(false)
+	for {
+		id, conn, pathChallenge, ok := pm.NextPathToProbe(now, pto)
+		if !ok {
+			return nil
+		}
+		if s.multipath != nil {
+			s.probeMultipathPath(id, conn, pathChallenge, now, pto)
+			continue
+		}
+		connID, ok := s.connIDManager.GetConnIDForPath(id)
+		if !ok {
+			s.logger.Debugf("No connection ID available to probe path %d", id)
+			pm.BackoffProbe(id, now, pto)
+			continue
+		}
+		s.sendPathProbe(conn, connID, []ackhandler.Frame{{Frame: pathChallenge}}, protocol.MinInitialPacketSize, now)
+	}
+}
+
+// sendPathProbe sends a packet containing path probing frames on a path other than the active path.
+// These packets are padded to size, and are not congestion controlled.
+// It returns the number of bytes sent.
+func (s *connection) sendPathProbe(conn sendConn, connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, now time.Time) protocol.ByteCount {
+	p, buf, err := s.packer.PackPathProbePacket(connID, frames, size, s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack path probe packet: %s", err)
+		return 0
+	}
+	ecn := protocol.ECNNon
+	if !conn.capabilities().ECN {
+		ecn = protocol.ECNUnsupported
+	}
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
+	s.sentPacketHandler.SentPacket(now, p.PacketNumber, protocol.InvalidPacketNumber, nil, nil, protocol.Encryption1RTT, ecn, buf.Len(), false)
+	// Write errors are not fatal, since the path might not be usable.
+	if err := conn.Write(buf.Data, 0, ecn); err != nil {
+		s.logger.Debugf("Failed to send path probe packet to %s: %s", conn.RemoteAddr(), err)
+	}
+	n := buf.Len()
+	buf.Release()
+	return n
+}
+
+// migrateToPreferredAddress probes the server's preferred address, and switches to it once it is validated.
+func (s *connection) migrateToPreferredAddress(pa *wire.PreferredAddress) {
+	var addr netip.AddrPort
+	if ip, ok := s.conn.RemoteAddr().(*net.UDPAddr); ok && ip.IP.To4() == nil {
+		addr = pa.IPv6
+	} else {
+		addr = pa.IPv4
+	}
+	if !addr.IsValid() || addr.Addr().IsUnspecified() || addr.Port() == 0 {
+		return
+	}
+	s.logger.Debugf("Probing the server's preferred address %s", addr)
+	conn := s.conn.forRemoteAddr(net.UDPAddrFromAddrPort(addr), packetInfo{})
+	p := s.pathManagerOutgoing.NewPath(conn, nil, maxPreferredAddressProbes, true)
+	s.pathManagerOutgoing.startProbing(p.id)
+}
+
+// AddPath creates a new path that uses the given Transport.
+func (s *connection) AddPath(t *Transport) (*Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("server cannot initiate connection migration")
+	}
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil, errors.New("can only add a path after the handshake has completed")
+	}
+	if s.peerParams.DisableActiveMigration {
+		return nil, errors.New("server disabled connection migration")
+	}
+	if err := t.init(false); err != nil {
+		return nil, err
+	}
+	if t.connIDLen != s.srcConnIDLen {
+		return nil, fmt.Errorf("transport uses a connection ID length of %d, expected %d", t.connIDLen, s.srcConnIDLen)
+	}
+	p := s.pathManagerOutgoing.NewPath(newSendConn(t.conn, s.RemoteAddr(), packetInfo{}, s.logger), t.handlerMap, 0, false)
+	return &Path{
+		id:          p.id,
+		pathManager: s.pathManagerOutgoing,
+		validated:   p.validated,
+		closed:      p.closed,
+		connCtx:     s.ctx,
+		multipath:   s.multipath != nil,
+	}, nil
+}
+
+// enableMultipath is called once both endpoints negotiated the multipath extension.
+// Connection IDs are issued for all paths that the peer is allowed to open.
+func (s *connection) enableMultipath(peerMaxPathID protocol.PathID) {
+	s.multipath = newMultipathManager(
+		protocol.PathID(max(s.config.MaxPaths, 1)-1),
+		s.connIDGenerator.generator,
+		s.connIDGenerator.addConnectionID,
+		s.connIDGenerator.getStatelessResetToken,
+		s.connIDGenerator.removeConnectionID,
+		s.connIDGenerator.retireConnectionID,
+		s.connIDGenerator.replaceWithClosed,
+		s.connIDManager.addStatelessResetToken,
+		s.connIDManager.removeStatelessResetToken,
+		s.queueControlFrame,
+	)
+	s.multipathScheduler = newMultipathScheduler(s.config.MultipathScheduler)
+	if err := s.multipath.SetPeerMaxPathID(peerMaxPathID); err != nil {
+		s.closeLocal(err)
+	}
+}
+
+// newMultipathPath creates a path of a multipath connection, using conn to send packets.
+// The path has its own packet number space, congestion controller and RTT estimate.
+func (s *connection) newMultipathPath(id protocol.PathID, conn sendConn) (*mpPath, error) {
+	aead, err := s.cryptoStreamHandler.Get1RTTPathAEAD(id)
+	if err != nil {
+		return nil, err
+	}
+	rttStats := &utils.RTTStats{}
+	rttStats.SetMaxAckDelay(s.peerParams.MaxAckDelay)
+	sph, rph := ackhandler.NewPathAckHandler(
+		getMaxPacketSize(conn.RemoteAddr()),
+		rttStats,
+		conn.capabilities().ECN,
+		s.config.FECRepairWindowShare,
+		s.config.FECRecoveredLossBackoff,
+		s.config.newCongestionController(),
+		s.perspective,
+		s.logger,
+	)
+	path := &mpPath{
+		id:                    id,
+		conn:                  conn,
+		sendQueue:             newSendQueue(conn),
+		rttStats:              rttStats,
+		sentPacketHandler:     sph,
+		receivedPacketHandler: rph,
+		aead:                  aead,
+	}
+	s.runSendQueue(path.sendQueue)
+	s.multipath.AddPath(path)
+	return path, nil
+}
+
+// handleMultipathPacket handles a packet received on a path of a multipath connection, other than the initial path.
+// The server creates the path when it receives the first packet, and validates it by sending a PATH_CHALLENGE.
+func (s *connection) handleMultipathPacket(id protocol.PathID, destConnID protocol.ConnectionID, p receivedPacket) bool {
+	var wasQueued bool
+	defer func() {
+		if !wasQueued {
+			p.buffer.Decrement()
+		}
+	}()
+
+	path := s.multipath.Path(id)
+	if path == nil {
+		if s.perspective == protocol.PerspectiveClient || !s.handshakeConfirmed || p.remoteAddr == nil {
+			s.logger.Debugf("Dropping packet for path %d, which was not opened yet", id)
+			return false
+		}
+		var err error
+		path, err = s.newMultipathPath(id, s.conn.forRemoteAddr(p.remoteAddr, p.info))
+		if err != nil {
+			s.closeLocal(err)
+			return false
+		}
+		s.logger.Debugf("Client opened path %d from %s", id, p.remoteAddr)
+		s.queuePathChallenge(path, p.rcvTime)
+	}
+	if path.abandoned {
+		s.logger.Debugf("Dropping packet for abandoned path %d", id)
+		return false
+	}
+
+	pn, pnLen, keyPhase, data, err := s.unpacker.UnpackPathShortHeader(path.aead, p.rcvTime, p.data)
+	if err != nil {
+		wasQueued = s.handleUnpackError(err, p, logging.PacketType1RTT)
+		return false
+	}
+
+	if s.logger.Debug() {
+		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s on path %d, 1-RTT", pn, p.Size(), destConnID, id)
+		wire.LogShortHeader(s.logger, destConnID, pn, pnLen, keyPhase)
+	}
+
+	if path.receivedPacketHandler.IsPotentiallyDuplicate(pn, protocol.Encryption1RTT) {
+		s.logger.Debugf("Dropping (potentially) duplicate packet.")
+		return false
+	}
+	path.rcvdBytes += p.Size()
+	// Retransmit the PATH_CHALLENGE if the path wasn't validated within a PTO.
+	if s.perspective == protocol.PerspectiveServer && !path.validated && p.rcvTime.Sub(path.pathChallengeSent) > path.rttStats.PTO(false) {
+		s.queuePathChallenge(path, p.rcvTime)
+	}
+
+	s.lastPacketReceivedTime = p.rcvTime
+	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
+	s.keepAlivePingSent = false
+
+	// PATH_CHALLENGEs are answered on the path they were received on.
+	isAckEliciting, _, pathChallenge, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, nil, true)
+	if err != nil {
+		s.closeLocal(err)
+		return false
+	}
+	if pathChallenge != nil {
+		path.probeFrames = append(path.probeFrames, ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}})
+	}
+	if err := path.receivedPacketHandler.ReceivedPacket(pn, p.ecn, protocol.Encryption1RTT, p.rcvTime, isAckEliciting); err != nil {
 		s.closeLocal(err)
 		return false
 	}
 	return true
 }
 
+// queuePathChallenge is used by the server to validate a path opened by the client.
+func (s *connection) queuePathChallenge(path *mpPath, now time.Time) {
+	_, _ = rand.Read(path.pathChallenge[:])
+	path.pathChallengeSent = now
+	path.probeFrames = append(path.probeFrames, ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: path.pathChallenge}})
+}
+
+// probeMultipathPath is called by the client to send a PATH_CHALLENGE on a new path of a multipath connection.
+func (s *connection) probeMultipathPath(id pathID, conn sendConn, pathChallenge *wire.PathChallengeFrame, now time.Time, pto time.Duration) {
+	pm := s.pathManagerOutgoing
+	if protocol.PathID(id) > s.multipath.MaxPathID() {
+		s.logger.Debugf("Can't open path %d, the maximum path ID is %d", id, s.multipath.MaxPathID())
+		_ = pm.closePath(id)
+		return
+	}
+	path := s.multipath.Path(protocol.PathID(id))
+	if path == nil {
+		if _, ok := s.multipath.DestConnID(protocol.PathID(id)); !ok {
+			s.logger.Debugf("No connection ID available to probe path %d", id)
+			pm.BackoffProbe(id, now, pto)
+			return
+		}
+		var err error
+		path, err = s.newMultipathPath(protocol.PathID(id), conn)
+		if err != nil {
+			s.logger.Debugf("Failed to open path %d: %s", id, err)
+			pm.BackoffProbe(id, now, pto)
+			return
+		}
+	}
+	path.probeFrames = append(path.probeFrames, ackhandler.Frame{Frame: pathChallenge})
+}
+
+// abandonMultipathPath stops using a path of a multipath connection.
+// Packets in flight on the path are declared lost, and their frames are retransmitted on the other paths.
+func (s *connection) abandonMultipathPath(path *mpPath) {
+	if path.abandoned {
+		return
+	}
+	s.logger.Debugf("Abandoning path %d", path.id)
+	s.queueControlFrame(&wire.PathAbandonFrame{PathID: path.id, ErrorCode: qerr.NoError})
+	s.multipath.AbandonPath(path.id)
+	path.sentPacketHandler.MigratedPath(getMaxPacketSize(path.conn.RemoteAddr()))
+	path.probeFrames = nil
+	path.sendQueue.Close()
+	if s.pathManagerOutgoing != nil {
+		_ = s.pathManagerOutgoing.closePath(pathID(path.id))
+	}
+}
+
+func (s *connection) checkMultipathNegotiated(f wire.Frame) error {
+	if s.multipath == nil {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("received %s frame, although multipath was not negotiated", reflect.ValueOf(f).Elem().Type().Name()),
+		}
+	}
+	return nil
+}
+
+func (s *connection) handlePathAckFrame(f *wire.PathAckFrame) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	if f.PathID == protocol.InitialPathID {
+		return s.handleAckFrame(&f.AckFrame, protocol.Encryption1RTT)
+	}
+	path := s.multipath.Path(f.PathID)
+	if path == nil {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("received PATH_ACK for unknown path %d", f.PathID),
+		}
+	}
+	acked1RTTPacket, err := path.sentPacketHandler.ReceivedAck(&f.AckFrame, protocol.Encryption1RTT, s.lastPacketReceivedTime)
+	if err != nil || !acked1RTTPacket {
+		return err
+	}
+	return path.aead.SetLargestAcked(f.LargestAcked())
+}
+
+func (s *connection) handlePathAbandonFrame(f *wire.PathAbandonFrame) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	if f.PathID == protocol.InitialPathID {
+		// The initial path can't be abandoned, since it's the only path that can be migrated.
+		s.logger.Debugf("Ignoring PATH_ABANDON for the initial path")
+		return nil
+	}
+	if path := s.multipath.Path(f.PathID); path != nil {
+		s.abandonMultipathPath(path)
+		return nil
+	}
+	s.multipath.AbandonPath(f.PathID)
+	return nil
+}
+
+func (s *connection) handlePathStatusFrame(f *wire.PathStatusFrame) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	path := s.multipath.Path(f.PathID)
+	if path == nil {
+		return nil
+	}
+	// PATH_STATUS frames can be reordered. Only the most recent status is applied.
+	if f.StatusSequenceNumber < path.statusSeq {
+		return nil
+	}
+	path.statusSeq = f.StatusSequenceNumber + 1
+	path.backup = f.Backup
+	return nil
+}
+
+func (s *connection) handlePathNewConnectionIDFrame(f *wire.PathNewConnectionIDFrame) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	if f.PathID == protocol.InitialPathID {
+		return s.handleNewConnectionIDFrame(&f.NewConnectionIDFrame)
+	}
+	return s.multipath.HandlePathNewConnectionIDFrame(f)
+}
+
+func (s *connection) handlePathRetireConnectionIDFrame(f *wire.PathRetireConnectionIDFrame, destConnID protocol.ConnectionID) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	if f.PathID == protocol.InitialPathID {
+		return s.connIDGenerator.Retire(f.SequenceNumber, destConnID)
+	}
+	return s.multipath.HandlePathRetireConnectionIDFrame(f, destConnID)
+}
+
+func (s *connection) handleMaxPathIDFrame(f *wire.MaxPathIDFrame) error {
+	if err := s.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	return s.multipath.SetPeerMaxPathID(f.MaxPathID)
+}
+
 func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -1241,7 +1837,7 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, ecn, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
+	isAckEliciting, _, _, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log, false)
 	if err != nil {
 		return err
 	}
@@ -1255,16 +1851,17 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	log func([]logging.Frame),
-) error {
+	onNewPath bool,
+) (isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
 
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	isAckEliciting, isNonProbing, pathChallenge, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log, onNewPath)
 	if err != nil {
-		return err
+		return false, nil, err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	return isNonProbing, pathChallenge, s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
 }
 
 func (s *connection) handleFrames(
@@ -1272,7 +1869,8 @@ func (s *connection) handleFrames(
 	destConnID protocol.ConnectionID,
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
-) (isAckEliciting bool, _ error) {
+	onNewPath bool,
+) (isAckEliciting, isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []logging.Frame
@@ -1284,7 +1882,7 @@ func (s *connection) handleFrames(
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel, s.version)
 		if err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1293,6 +1891,9 @@ func (s *connection) handleFrames(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !isProbingFrame(frame) {
+			isNonProbing = true
+		}
 		if log != nil {
 			frames = append(frames, logutils.ConvertFrame(frame))
 		}
@@ -1306,7 +1907,7 @@ func (s *connection) handleFrames(
 			blockData, err := s.handleSourceSymbolFrame(f)
 			if err != nil {
 				if log == nil {
-					return false, err
+					return false, false, nil, err
 				}
 				// If we're logging, we need to keep parsing (but not handling) all frames.
 				handleErr = err
@@ -1314,7 +1915,7 @@ func (s *connection) handleFrames(
 			for len(blockData) > 0 {
 				l, frame, err := s.frameParser.ParseNext(blockData, encLevel, s.version)
 				if err != nil {
-					return false, err
+					return false, false, nil, err
 				}
 				blockData = blockData[l:]
 				if frame == nil {
@@ -1333,7 +1934,7 @@ func (s *connection) handleFrames(
 				}
 				if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 					if log == nil {
-						return false, err
+						return false, false, nil, err
 					}
 					handleErr = err
 				}
@@ -1342,15 +1943,18 @@ func (s *connection) handleFrames(
 			blockData, err := s.handleRepairFrame(f)
 			if err != nil {
 				if log == nil {
-					return false, err
+					return false, false, nil, err
 				}
 				// If we're logging, we need to keep parsing (but not handling) all frames.
 				handleErr = err
 			}
+			if len(blockData) > 0 && s.tracer != nil && s.tracer.RecoveredSourceSymbols != nil {
+				s.tracer.RecoveredSourceSymbols(f.Metadata.BlockID, protocol.ByteCount(len(blockData)))
+			}
 			for len(blockData) > 0 {
 				l, frame, err := s.frameParser.ParseNext(blockData, encLevel, s.version)
 				if err != nil {
-					return false, err
+					return false, false, nil, err
 				}
 				blockData = blockData[l:]
 				if frame == nil {
@@ -1369,15 +1973,28 @@ func (s *connection) handleFrames(
 				}
 				if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 					if log == nil {
-						return false, err
+						return false, false, nil, err
 					}
 					handleErr = err
 				}
 			}
+		case *wire.PathChallengeFrame:
+			// PATH_CHALLENGEs received on a new path are answered on that path.
+			if onNewPath {
+				wire.LogFrame(s.logger, f, false)
+				pathChallenge = f
+				continue
+			}
+			if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
+				if log == nil {
+					return false, false, nil, err
+				}
+				handleErr = err
+			}
 		default:
 			if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 				if log == nil {
-					return false, err
+					return false, false, nil, err
 				}
 				// If we're logging, we need to keep parsing (but not handling) all frames.
 				handleErr = err
@@ -1388,7 +2005,7 @@ func (s *connection) handleFrames(
 	if log != nil {
 		log(frames)
 		if handleErr != nil {
-			return false, handleErr
+			return false, false, nil, handleErr
 		}
 	}
 
@@ -1398,7 +2015,7 @@ func (s *connection) handleFrames(
 	// and an ACK serialized after that CRYPTO frame. In this case, we still want to process the ACK frame.
 	if !handshakeWasComplete && s.handshakeComplete {
 		if err := s.handleHandshakeComplete(); err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 	}
 
@@ -1434,8 +2051,7 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1446,6 +2062,26 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.FECWindowFrame:
+		err = s.handleFECWindowFrame(frame)
+	case *wire.PathAckFrame:
+		err = s.handlePathAckFrame(frame)
+	case *wire.PathAbandonFrame:
+		err = s.handlePathAbandonFrame(frame)
+	case *wire.PathStatusFrame:
+		err = s.handlePathStatusFrame(frame)
+	case *wire.PathNewConnectionIDFrame:
+		err = s.handlePathNewConnectionIDFrame(frame)
+	case *wire.PathRetireConnectionIDFrame:
+		err = s.handlePathRetireConnectionIDFrame(frame, destConnID)
+	case *wire.MaxPathIDFrame:
+		err = s.handleMaxPathIDFrame(frame)
+	case *wire.PathsBlockedFrame, *wire.PathCIDsBlockedFrame:
+		err = s.checkMultipathNegotiated(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAck()
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1503,8 +2139,9 @@ func (s *connection) handleHandshakeEvents() error {
 		case handshake.EventReceivedTransportParameters:
 			err = s.handleTransportParameters(ev.TransportParameters)
 		case handshake.EventRestoredTransportParameters:
-			s.restoreTransportParameters(ev.TransportParameters)
-			close(s.earlyConnReadyChan)
+			if err = s.restoreTransportParameters(ev.TransportParameters); err == nil {
+				close(s.earlyConnReadyChan)
+			}
 		case handshake.EventReceivedReadKeys:
 			// Queue all packets for decryption that have been undecryptable so far.
 			s.undecryptablePacketsToProcess = s.undecryptablePackets
@@ -1619,6 +2256,17 @@ func (s *connection) handleHandshakeDoneFrame() error {
 	return nil
 }
 
+func (s *connection) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max_ack_delay (%s) smaller than min_ack_delay (%s)", f.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	s.receivedPacketHandler.ReceivedAckFrequencyFrame(f)
+	return nil
+}
+
 func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.EncryptionLevel) error {
 	acked1RTTPacket, err := s.sentPacketHandler.ReceivedAck(frame, encLevel, s.lastPacketReceivedTime)
 	if err != nil {
@@ -1650,21 +2298,65 @@ func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 }
 
 func (s *connection) handleSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]byte, error) {
+	if s.fecReceiver == nil || !s.fecEnabled() {
+		return nil, s.rejectFECFrame(f, logging.FECFrameRejectNotNegotiated, "received SOURCE_SYMBOL frame, but FEC was not negotiated")
+	}
+	if f.Format != s.config.FECWireFormat {
+		return nil, s.rejectFECFrame(f, logging.FECFrameRejectNotNegotiated, fmt.Sprintf("received SOURCE_SYMBOL frame in the %s wire format", f.Format))
+	}
 	blockData, err := s.fecReceiver.HandleSourceSymbolFrame(f)
 	if err != nil {
-		return nil, err
+		return nil, s.rejectFECFrame(f, fecFrameRejectReason(err), err.Error())
 	}
 	return blockData, nil
 }
 
 func (s *connection) handleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
+	if s.fecReceiver == nil || !s.fecEnabled() {
+		return nil, s.rejectFECFrame(f, logging.FECFrameRejectNotNegotiated, "received REPAIR frame, but FEC was not negotiated")
+	}
+	if f.Format != s.config.FECWireFormat {
+		return nil, s.rejectFECFrame(f, logging.FECFrameRejectNotNegotiated, fmt.Sprintf("received REPAIR frame in the %s wire format", f.Format))
+	}
 	blockData, err := s.fecReceiver.HandleRepairFrame(f)
 	if err != nil {
-		return nil, err
+		return nil, s.rejectFECFrame(f, fecFrameRejectReason(err), err.Error())
 	}
 	return blockData, nil
 }
 
+func (s *connection) handleFECWindowFrame(f *wire.FECWindowFrame) error {
+	if !s.fecEnabled() {
+		return s.rejectFECFrame(f, logging.FECFrameRejectNotNegotiated, "received FEC_WINDOW frame, but FEC was not negotiated")
+	}
+	// FEC_WINDOW frames can be reordered, the frame with the highest epoch carries the current window.
+	if s.receivedFECPeerWindow && f.Epoch <= s.fecPeerWindowEpoch {
+		return nil
+	}
+	s.fecPeerWindowEpoch = f.Epoch
+	s.receivedFECPeerWindow = true
+	s.packer.SetFECPeerWindow(f.Size)
+	return nil
+}
+
+func fecFrameRejectReason(err error) logging.FECFrameRejectReason {
+	if errors.Is(err, fec.ErrSymbolOutsideWindow) {
+		return logging.FECFrameRejectOutsideWindow
+	}
+	return logging.FECFrameRejectInvalidSymbol
+}
+
+// rejectFECFrame traces the rejection of a FEC frame, and returns the error that the connection is closed with.
+func (s *connection) rejectFECFrame(f wire.Frame, reason logging.FECFrameRejectReason, msg string) error {
+	if s.tracer != nil && s.tracer.RejectedFECFrame != nil {
+		s.tracer.RejectedFECFrame(logutils.ConvertFrame(f), reason)
+	}
+	return &qerr.TransportError{
+		ErrorCode:    qerr.FECError,
+		ErrorMessage: msg,
+	}
+}
+
 // closeLocal closes the connection and send a CONNECTION_CLOSE containing the error
 func (s *connection) closeLocal(e error) {
 	s.closeOnce.Do(func() {
@@ -1749,6 +2441,9 @@ func (s *connection) handleCloseError(closeErr *closeError) {
 
 	s.streamsMap.CloseWithError(e)
 	s.connIDManager.Close()
+	if s.multipath != nil {
+		s.multipath.Close()
+	}
 	if s.datagramQueue != nil {
 		s.datagramQueue.CloseWithError(e)
 	}
@@ -1763,24 +2458,42 @@ func (s *connection) handleCloseError(closeErr *closeError) {
 
 	// If this is a remote close we're done here
 	if closeErr.remote {
-		s.connIDGenerator.ReplaceWithClosed(nil)
+		s.replaceConnIDsWithClosed(nil)
 		return
 	}
 	if closeErr.immediate {
-		s.connIDGenerator.RemoveAll()
+		s.removeConnIDs()
 		return
 	}
 	// Don't send out any CONNECTION_CLOSE if this is an error that occurred
 	// before we even sent out the first packet.
 	if s.perspective == protocol.PerspectiveClient && !s.sentFirstPacket {
-		s.connIDGenerator.RemoveAll()
+		s.removeConnIDs()
 		return
 	}
 	connClosePacket, err := s.sendConnectionClose(e)
 	if err != nil {
 		s.logger.Debugf("Error sending CONNECTION_CLOSE: %s", err)
 	}
+	s.replaceConnIDsWithClosed(connClosePacket)
+}
+
+// replaceConnIDsWithClosed replaces all connection IDs issued by us,
+// including those issued for the paths of a multipath connection, with a closed connection.
+func (s *connection) replaceConnIDsWithClosed(connClosePacket []byte) {
 	s.connIDGenerator.ReplaceWithClosed(connClosePacket)
+	if s.multipath != nil {
+		s.multipath.ReplaceWithClosed(connClosePacket)
+	}
+}
+
+// removeConnIDs removes all connection IDs issued by us,
+// including those issued for the paths of a multipath connection.
+func (s *connection) removeConnIDs() {
+	s.connIDGenerator.RemoveAll()
+	if s.multipath != nil {
+		s.multipath.RemoveAll()
+	}
 }
 
 func (s *connection) dropEncryptionLevel(encLevel protocol.EncryptionLevel) error {
@@ -1799,13 +2512,18 @@ func (s *connection) dropEncryptionLevel(encLevel protocol.EncryptionLevel) erro
 		if err := s.connFlowController.Reset(); err != nil {
 			return err
 		}
+		// The server didn't process any of the FEC blocks sent in 0-RTT packets.
+		// FEC protection is enabled again once the new transport parameters are applied.
+		if err := s.packer.SetFECScheme(protocol.FECDisabled, s.config.FECWireFormat); err != nil {
+			return err
+		}
 		return s.framer.Handle0RTTRejection()
 	}
 	return s.cryptoStreamManager.Drop(encLevel)
 }
 
 // is called for the client, when restoring transport parameters saved for 0-RTT
-func (s *connection) restoreTransportParameters(params *wire.TransportParameters) {
+func (s *connection) restoreTransportParameters(params *wire.TransportParameters) error {
 	if s.logger.Debug() {
 		s.logger.Debugf("Restoring Transport Parameters: %s", params)
 	}
@@ -1814,9 +2532,17 @@ func (s *connection) restoreTransportParameters(params *wire.TransportParameters
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.streamsMap.UpdateLimits(params)
+	// Session tickets with an unsupported FEC scheme are not used for 0-RTT,
+	// so this is not expected to fail.
+	if s.fecEnabled() {
+		if err := s.packer.SetFECScheme(params.DecoderFECScheme, params.FECWireFormat); err != nil {
+			return err
+		}
+	}
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
 	s.connStateMutex.Unlock()
+	return nil
 }
 
 func (s *connection) handleTransportParameters(params *wire.TransportParameters) error {
@@ -1841,7 +2567,9 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
-		s.applyTransportParameters()
+		if err := s.applyTransportParameters(); err != nil {
+			return err
+		}
 		// On the server side, the early connection is ready as soon as we processed
 		// the client's transport parameters.
 		close(s.earlyConnReadyChan)
@@ -1883,7 +2611,7 @@ func (s *connection) checkTransportParameters(params *wire.TransportParameters)
 	return nil
 }
 
-func (s *connection) applyTransportParameters() {
+func (s *connection) applyTransportParameters() error {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
 	s.idleTimeout = utils.MinNonZeroDuration(s.config.MaxIdleTimeout, params.MaxIdleTimeout)
@@ -1896,23 +2624,52 @@ func (s *connection) applyTransportParameters() {
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
+	// The client migrates to the preferred_address once the handshake is confirmed.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
 	if s.fecEnabled() {
-		fecSender, err := fec.NewSender(params.DecoderFECScheme)
-		if err != nil {
-			panic(err.Error())
+		if err := s.packer.SetFECScheme(params.DecoderFECScheme, params.FECWireFormat); err != nil {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: err.Error(),
+			}
 		}
-		s.packer.SetFECSender(fecSender)
+		// Advertise the size of our decoding window.
+		// The draft wire format doesn't define the FEC_WINDOW frame, so the peer has to assume the default window.
+		if params.FECWireFormat == protocol.FECWireFormatLegacy {
+			s.queueControlFrame(&wire.FECWindowFrame{Size: protocol.MaxFECDecodingWindow})
+		}
+	}
+	if s.config.EnableMultipath && params.InitialMaxPathID != nil && s.srcConnIDLen > 0 && s.handshakeDestConnID.Len() > 0 {
+		s.enableMultipath(*params.InitialMaxPathID)
+	}
+	// The ACK frequency of the paths of a multipath connection is not changed.
+	if s.config.EnableAckFrequency && params.MinAckDelay != nil && s.multipath == nil {
+		s.ackFrequencyEnabled = true
+		s.sentPacketHandler.EnableAckFrequency(*params.MinAckDelay)
+	}
+	if s.config.EnableStreamResetPartialDelivery && params.EnableResetStreamAt {
+		s.resetStreamAtEnabled.Store(true)
 	}
+	return nil
 }
 
 func (s *connection) triggerSending(now time.Time) error {
 	s.pacingDeadline = time.Time{}
 
+	if s.pathManagerOutgoing != nil && s.handshakeConfirmed {
+		if err := s.handleOutgoingPaths(now); err != nil {
+			return err
+		}
+	}
+	if s.multipath != nil && s.handshakeConfirmed {
+		s.sendMultipathProbes(now)
+		if s.multipath.HasActivePaths() {
+			return s.triggerSendingMultipath(now)
+		}
+	}
+
 	sendMode := s.sentPacketHandler.SendMode(now)
 	//nolint:exhaustive // No need to handle pacing limited here.
 	switch sendMode {
@@ -1973,27 +2730,10 @@ func (s *connection) sendPackets(now time.Time) error {
 	// Performance-wise, this doesn't matter, since we only send a very small (<10) number of
 	// MTU probe packets per connection.
 	if s.handshakeConfirmed && s.mtuDiscoverer != nil && s.mtuDiscoverer.ShouldSendProbe(now) {
-		ping, size := s.mtuDiscoverer.GetPing()
-		p, buf, err := s.packer.PackMTUProbePacket(ping, size, s.version)
-		if err != nil {
-			return err
-		}
-		ecn := s.sentPacketHandler.ECNMode(true)
-		s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
-		s.registerPackedShortHeaderPacket(p, ecn, now)
-		s.sendQueue.Send(buf, 0, ecn)
-		// This is kind of a hack. We need to trigger sending again somehow.
-		s.pacingDeadline = deadlineSendImmediately
-		return nil
+		return s.sendMTUProbePacket(now)
 	}
 
-	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
-		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
-	}
-	s.windowUpdateQueue.QueueAll()
-	if cf := s.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
-		s.queueControlFrame(cf)
-	}
+	s.queueControlFramesForSending(now)
 
 	if !s.handshakeConfirmed {
 		packet, err := s.packer.PackCoalescedPacket(false, s.mtuDiscoverer.CurrentSize(), s.version)
@@ -2019,6 +2759,38 @@ func (s *connection) sendPackets(now time.Time) error {
 	return s.sendPacketsWithoutGSO(now)
 }
 
+// sendMTUProbePacket sends a Path MTU Discovery probe packet on the initial path.
+func (s *connection) sendMTUProbePacket(now time.Time) error {
+	ping, size := s.mtuDiscoverer.GetPing()
+	p, buf, err := s.packer.PackMTUProbePacket(ping, size, s.version)
+	if err != nil {
+		return err
+	}
+	ecn := s.sentPacketHandler.ECNMode(true)
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
+	s.registerPackedShortHeaderPacket(p, ecn, now)
+	s.sendQueue.Send(buf, 0, ecn)
+	// This is kind of a hack. We need to trigger sending again somehow.
+	s.pacingDeadline = deadlineSendImmediately
+	return nil
+}
+
+// queueControlFramesForSending queues the control frames that are generated right before sending packets.
+func (s *connection) queueControlFramesForSending(now time.Time) {
+	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
+		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
+	}
+	s.windowUpdateQueue.QueueAll()
+	if cf := s.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
+		s.queueControlFrame(cf)
+	}
+	if s.ackFrequencyEnabled && s.handshakeConfirmed {
+		if f := s.sentPacketHandler.GetAckFrequencyFrame(now); f != nil {
+			s.queueControlFrame(f)
+		}
+	}
+}
+
 func (s *connection) sendPacketsWithoutGSO(now time.Time) error {
 	for {
 		buf := getPacketBuffer()
@@ -2120,6 +2892,301 @@ func (s *connection) resetPacingDeadline() {
 	s.pacingDeadline = deadline
 }
 
+// setPacingDeadline sets the pacing deadline, unless an earlier deadline is already set.
+func (s *connection) setPacingDeadline(deadline time.Time) {
+	s.pacingDeadline = utils.MinNonZeroTime(s.pacingDeadline, deadline)
+}
+
+// sendMultipathProbes sends the PATH_CHALLENGE and PATH_RESPONSE frames queued for the paths of a multipath connection.
+// These packets are padded, and are not congestion controlled.
+// Before a path opened by the client is validated, the server sends at most 3 times the number of bytes received on that path.
+func (s *connection) sendMultipathProbes(now time.Time) {
+	for _, path := range s.multipath.Paths() {
+		if len(path.probeFrames) == 0 || path.sendQueue.WouldBlock() {
+			continue
+		}
+		if _, ok := s.multipath.DestConnID(path.id); !ok {
+			continue
+		}
+		size := protocol.ByteCount(protocol.MinInitialPacketSize)
+		if s.perspective == protocol.PerspectiveServer && !path.validated {
+			size = min(size, amplificationFactor*path.rcvdBytes-path.sentBytes)
+		}
+		if s.sendMultipathProbePacket(path, path.probeFrames, size, now) > 0 {
+			path.probeFrames = nil
+		}
+	}
+}
+
+// sendMultipathProbePacket sends a packet containing path probing frames on a path of a multipath connection.
+// It returns the number of bytes sent.
+func (s *connection) sendMultipathProbePacket(path *mpPath, frames []ackhandler.Frame, size protocol.ByteCount, now time.Time) protocol.ByteCount {
+	p, buf, err := s.packer.PackMultipathProbePacket(s.multipathPackerPath(path, 0), frames, size, s.version)
+	if err != nil {
+		s.logger.Debugf("Failed to pack probe packet for path %d: %s", path.id, err)
+		return 0
+	}
+	ecn := path.sentPacketHandler.ECNMode(false)
+	s.registerPackedMultipathPacket(path, p, ecn, now)
+	n := buf.Len()
+	path.sendQueue.Send(buf, 0, ecn)
+	return n
+}
+
+// triggerSendingMultipath sends packets once a path other than the initial path was validated.
+// The multipathScheduler selects the path that each packet is sent on.
+// GSO is not used on multipath connections.
+func (s *connection) triggerSendingMultipath(now time.Time) error {
+	paths := s.multipath.Paths()
+	active := pathSet(0).Add(protocol.InitialPathID)
+	for _, path := range paths {
+		if path.validated {
+			active = active.Add(path.id)
+		}
+	}
+
+	for s.sentPacketHandler.SendMode(now) == ackhandler.SendPTOAppData {
+		if s.sendQueue.WouldBlock() {
+			s.scheduleSending()
+			return nil
+		}
+		if err := s.sendProbePacket(protocol.Encryption1RTT, now); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		if !path.validated {
+			continue
+		}
+		if err := s.sendMultipathPTOProbes(path, active.Remove(path.id), now); err != nil {
+			return err
+		}
+	}
+
+	s.queueControlFramesForSending(now)
+	if s.mtuDiscoverer != nil && s.mtuDiscoverer.ShouldSendProbe(now) &&
+		s.sentPacketHandler.SendMode(now) == ackhandler.SendAny && !s.sendQueue.WouldBlock() {
+		if err := s.sendMTUProbePacket(now); err != nil {
+			return err
+		}
+	}
+
+	for {
+		candidates := s.multipathSendCandidates(paths, now)
+		if len(candidates) == 0 {
+			break
+		}
+		var preferred pathSet
+		if s.config.FECRepairPathDiversity {
+			var available pathSet
+			for _, c := range candidates {
+				available = available.Add(c.id)
+			}
+			preferred = s.packer.PreferredRepairPaths(available)
+		}
+		id, _ := s.multipathScheduler.SelectPath(candidates, preferred)
+		sent, err := s.sendMultipathPacket(id, active.Remove(id), now)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			break
+		}
+		// Prioritize receiving of packets over sending out more packets.
+		if len(s.receivedPackets) > 0 {
+			s.setPacingDeadline(deadlineSendImmediately)
+			return nil
+		}
+	}
+	return s.sendMultipathAckOnlyPackets(paths, now)
+}
+
+// multipathSendCandidates returns the paths that a packet can be sent on right now.
+func (s *connection) multipathSendCandidates(paths []*mpPath, now time.Time) []schedulerPath {
+	candidates := make([]schedulerPath, 0, len(paths)+1)
+	if s.canSendOnPath(s.sentPacketHandler, s.sendQueue, now) {
+		candidates = append(candidates, schedulerPath{id: protocol.InitialPathID, rtt: s.rttStats.SmoothedRTT()})
+	}
+	for _, path := range paths {
+		if !path.validated {
+			continue
+		}
+		if _, ok := s.multipath.DestConnID(path.id); !ok {
+			continue
+		}
+		if s.canSendOnPath(path.sentPacketHandler, path.sendQueue, now) {
+			candidates = append(candidates, schedulerPath{id: path.id, rtt: path.rttStats.SmoothedRTT(), backup: path.backup})
+		}
+	}
+	return candidates
+}
+
+// canSendOnPath says if congestion control, pacing and the send queue allow sending a packet on a path.
+// If the path can't send right now, the pacing deadline is set accordingly.
+func (s *connection) canSendOnPath(sph ackhandler.SentPacketHandler, q sender, now time.Time) bool {
+	if q.WouldBlock() {
+		// The run loop only waits for the send queue of the initial path to become available.
+		s.setPacingDeadline(now.Add(protocol.MinPacingDelay))
+		return false
+	}
+	//nolint:exhaustive // Only need to handle pacing limited here.
+	switch sph.SendMode(now) {
+	case ackhandler.SendAny:
+		return true
+	case ackhandler.SendPacingLimited:
+		deadline := sph.TimeUntilSend()
+		if deadline.IsZero() {
+			deadline = deadlineSendImmediately
+		}
+		s.setPacingDeadline(deadline)
+	}
+	return false
+}
+
+// sendMultipathPacket packs and sends a packet on a path of a multipath connection.
+// It returns false if there was nothing to send.
+func (s *connection) sendMultipathPacket(id protocol.PathID, otherPaths pathSet, now time.Time) (bool, error) {
+	buf := getPacketBuffer()
+	if id == protocol.InitialPathID {
+		ecn := s.sentPacketHandler.ECNMode(true)
+		p, err := s.packer.AppendPathPacket(buf, s.initialPackerPath(otherPaths), s.mtuDiscoverer.CurrentSize(), s.version)
+		if err != nil {
+			buf.Release()
+			if err == errNothingToPack {
+				return false, nil
+			}
+			return false, err
+		}
+		s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, ecn, buf.Len(), false)
+		s.registerPackedShortHeaderPacket(p, ecn, now)
+		s.sendQueue.Send(buf, 0, ecn)
+		return true, nil
+	}
+
+	path := s.multipath.Path(id)
+	ecn := path.sentPacketHandler.ECNMode(true)
+	p, err := s.packer.AppendPathPacket(buf, s.multipathPackerPath(path, otherPaths), getMaxPacketSize(path.conn.RemoteAddr()), s.version)
+	if err != nil {
+		buf.Release()
+		if err == errNothingToPack {
+			return false, nil
+		}
+		return false, err
+	}
+	s.registerPackedMultipathPacket(path, p, ecn, now)
+	path.sendQueue.Send(buf, 0, ecn)
+	return true, nil
+}
+
+// sendMultipathPTOProbes sends probe packets on a path of a multipath connection whose PTO expired.
+func (s *connection) sendMultipathPTOProbes(path *mpPath, otherPaths pathSet, now time.Time) error {
+	for path.sentPacketHandler.SendMode(now) == ackhandler.SendPTOAppData {
+		if path.sendQueue.WouldBlock() {
+			s.setPacingDeadline(now.Add(protocol.MinPacingDelay))
+			return nil
+		}
+		// Queue probe packets until we actually send out a packet,
+		// or until there are no more packets to queue.
+		var sent bool
+		for !sent && path.sentPacketHandler.QueueProbePacket(protocol.Encryption1RTT) {
+			var err error
+			if sent, err = s.sendMultipathPacket(path.id, otherPaths, now); err != nil {
+				return err
+			}
+		}
+		if sent {
+			continue
+		}
+		s.retransmissionQueue.AddPing(protocol.Encryption1RTT)
+		sent, err := s.sendMultipathPacket(path.id, otherPaths, now)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			return fmt.Errorf("connection BUG: couldn't pack probe packet for path %d", path.id)
+		}
+	}
+	return nil
+}
+
+// sendMultipathAckOnlyPackets sends ACK-only packets on all paths of a multipath connection that have ACKs queued.
+func (s *connection) sendMultipathAckOnlyPackets(paths []*mpPath, now time.Time) error {
+	if !s.sendQueue.WouldBlock() {
+		if err := s.maybeSendAckOnlyPacket(now); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		if path.sendQueue.WouldBlock() {
+			continue
+		}
+		if _, ok := s.multipath.DestConnID(path.id); !ok {
+			continue
+		}
+		ecn := path.sentPacketHandler.ECNMode(true)
+		p, buf, err := s.packer.PackPathAckOnlyPacket(s.multipathPackerPath(path, 0), getMaxPacketSize(path.conn.RemoteAddr()), s.version)
+		if err != nil {
+			if err == errNothingToPack {
+				continue
+			}
+			return err
+		}
+		s.registerPackedMultipathPacket(path, p, ecn, now)
+		path.sendQueue.Send(buf, 0, ecn)
+	}
+	return nil
+}
+
+// initialPackerPath returns the packerPath for the initial path of a multipath connection.
+func (s *connection) initialPackerPath(otherPaths pathSet) *packerPath {
+	return &packerPath{
+		id:                  protocol.InitialPathID,
+		connID:              s.connIDManager.Get(),
+		pnManager:           s.sentPacketHandler,
+		acks:                s.receivedPacketHandler,
+		otherPaths:          otherPaths,
+		repairPathDiversity: s.config.FECRepairPathDiversity,
+	}
+}
+
+func (s *connection) multipathPackerPath(path *mpPath, otherPaths pathSet) *packerPath {
+	connID, _ := s.multipath.DestConnID(path.id)
+	return &packerPath{
+		id:                  path.id,
+		connID:              connID,
+		pnManager:           path.sentPacketHandler,
+		acks:                path.receivedPacketHandler,
+		sealer:              path.aead,
+		otherPaths:          otherPaths,
+		repairPathDiversity: s.config.FECRepairPathDiversity,
+	}
+}
+
+func (s *connection) registerPackedMultipathPacket(path *mpPath, p shortHeaderPacket, ecn protocol.ECN, now time.Time) {
+	if s.logger.Debug() {
+		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s on path %d, 1-RTT (ECN: %s)", p.PacketNumber, p.Length, s.logID, path.id, ecn)
+		wire.LogShortHeader(s.logger, p.DestConnID, p.PacketNumber, p.PacketNumberLen, p.KeyPhase)
+		if p.Ack != nil {
+			wire.LogFrame(s.logger, &wire.PathAckFrame{PathID: path.id, AckFrame: *p.Ack}, true)
+		}
+		for _, f := range p.Frames {
+			wire.LogFrame(s.logger, f.Frame, true)
+		}
+		for _, f := range p.StreamFrames {
+			wire.LogFrame(s.logger, f.Frame, true)
+		}
+	}
+	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && (len(p.StreamFrames) > 0 || ackhandler.HasAckElicitingFrames(p.Frames)) {
+		s.firstAckElicitingPacketAfterIdleSentTime = now
+	}
+	largestAcked := protocol.InvalidPacketNumber
+	if p.Ack != nil {
+		largestAcked = p.Ack.LargestAcked()
+	}
+	path.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, false)
+	path.sentBytes += p.Length
+}
+
 func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 	if !s.handshakeConfirmed {
 		ecn := s.sentPacketHandler.ECNMode(false)
@@ -2148,6 +3215,10 @@ func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 }
 
 func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel, now time.Time) error {
+	// Once the peer acknowledges packets less frequently, it might delay the ACK for the probe packet.
+	if encLevel == protocol.Encryption1RTT && s.ackFrequencyEnabled {
+		s.framer.QueueControlFrame(&wire.ImmediateAckFrame{})
+	}
 	// Queue probe packets until we actually send out a packet,
 	// or until there are no more packets to queue.
 	var packet *coalescedPacket
@@ -2491,38 +3562,56 @@ func (s *connection) onHasStreamData(id protocol.StreamID) {
 	s.scheduleSending()
 }
 
+func (s *connection) onStreamPriorityChanged(id protocol.StreamID, prio protocol.StreamPriority) {
+	s.framer.SetStreamPriority(id, prio)
+	s.scheduleSending()
+}
+
+func (s *connection) supportsResetStreamAt() bool {
+	return s.resetStreamAtEnabled.Load()
+}
+
 func (s *connection) onStreamCompleted(id protocol.StreamID) {
+	// Delete the stream before removing it from the framer,
+	// so that the framer ignores concurrent priority changes.
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
 	}
+	s.framer.RemoveStream(id)
 }
 
 func (s *connection) SendDatagram(p []byte) error {
-	if !s.supportsDatagrams() {
-		return errors.New("datagram support disabled")
-	}
-
-	f := &wire.DatagramFrame{DataLenPresent: true}
-	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
-		return &DatagramTooLargeError{
-			PeerMaxDatagramFrameSize: int64(s.peerParams.MaxDatagramFrameSize),
-		}
-	}
-	f.Data = make([]byte, len(p))
-	copy(f.Data, p)
-	return s.datagramQueue.Add(f)
+	return s.SendDatagramWithOptions(p, DatagramOptions{})
 }
 
 func (s *connection) SendDatagramWithFEC(p []byte) error {
+	return s.SendDatagramWithFECClass(p, protocol.FECProtectionDefault)
+}
+
+func (s *connection) SendDatagramWithFECClass(p []byte, class FECProtectionClass) error {
+	return s.SendDatagramWithOptions(p, DatagramOptions{FECProtected: true, FECProtectionClass: class})
+}
+
+func (s *connection) SendDatagramWithOptions(p []byte, opts DatagramOptions) error {
 	if !s.supportsDatagrams() {
 		return errors.New("datagram support disabled")
 	}
 
-	if !s.fecEnabled() {
-		return errors.New("FEC disabled")
+	if opts.Priority > MaxDatagramPriority {
+		return fmt.Errorf("invalid datagram priority: %d", opts.Priority)
 	}
 
-	f := &wire.DatagramFrame{DataLenPresent: true, FECProtected: true}
+	f := &wire.DatagramFrame{DataLenPresent: true}
+	if opts.FECProtected {
+		if !s.fecEnabled() {
+			return errors.New("FEC disabled")
+		}
+		if opts.FECProtectionClass > protocol.MaxFECProtectionClass {
+			return fmt.Errorf("invalid FEC protection class: %d", opts.FECProtectionClass)
+		}
+		f.FECProtected = true
+		f.FECProtectionClass = opts.FECProtectionClass
+	}
 	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
 		return &DatagramTooLargeError{
 			PeerMaxDatagramFrameSize: int64(s.peerParams.MaxDatagramFrameSize),
@@ -2530,7 +3619,14 @@ func (s *connection) SendDatagramWithFEC(p []byte) error {
 	}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
-	return s.datagramQueue.Add(f)
+	return s.datagramQueue.AddWithOptions(f, opts.Priority, opts.Expiry, opts.NonBlocking)
+}
+
+func (s *connection) DatagramStats() DatagramStats {
+	if s.datagramQueue == nil {
+		return DatagramStats{}
+	}
+	return s.datagramQueue.Stats()
 }
 
 func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
@@ -2541,10 +3637,14 @@ func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
 }
 
 func (s *connection) LocalAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *connection) RemoteAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
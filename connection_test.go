@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/fec"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/mocks"
 	mockackhandler "github.com/quic-go/quic-go/internal/mocks/ackhandler"
@@ -304,9 +305,11 @@ var _ = Describe("Connection", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("rejects PATH_RESPONSE frames", func() {
+		It("ignores PATH_RESPONSE frames that don't match a PATH_CHALLENGE", func() {
 			err := conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})
-			Expect(err).To(MatchError("unexpected PATH_RESPONSE frame"))
+			Expect(err).ToNot(HaveOccurred())
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(BeEmpty())
 		})
 
 		It("handles PATH_CHALLENGE frames", func() {
@@ -317,6 +320,24 @@ var _ = Describe("Connection", func() {
 			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PathResponseFrame{Data: data}}}))
 		})
 
+		It("handles ACK_FREQUENCY and IMMEDIATE_ACK frames", func() {
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestMaxAckDelay: 20 * time.Millisecond, ReorderingThreshold: 3}
+			rph.EXPECT().ReceivedAckFrequencyFrame(f)
+			Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			rph.EXPECT().ReceivedImmediateAck()
+			Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+		})
+
+		It("rejects ACK_FREQUENCY frames requesting a max_ack_delay smaller than the min_ack_delay", func() {
+			err := conn.handleFrame(&wire.AckFrequencyFrame{RequestMaxAckDelay: protocol.MinAckDelay - time.Microsecond}, protocol.Encryption1RTT, protocol.ConnectionID{})
+			Expect(err).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: "requested max_ack_delay (999µs) smaller than min_ack_delay (1ms)",
+			}))
+		})
+
 		It("rejects NEW_TOKEN frames", func() {
 			err := conn.handleNewTokenFrame(&wire.NewTokenFrame{})
 			Expect(err).To(HaveOccurred())
@@ -324,6 +345,173 @@ var _ = Describe("Connection", func() {
 			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
 		})
 
+		Context("FEC frames", func() {
+			expectFECError := func(err error) {
+				ExpectWithOffset(1, err).To(HaveOccurred())
+				ExpectWithOffset(1, err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+				ExpectWithOffset(1, err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FECError))
+			}
+
+			enableFEC := func() {
+				conn.config.EnableFEC = true
+				conn.config.DecoderFECScheme = protocol.XORFECScheme
+				conn.peerParams = &wire.TransportParameters{EnableFEC: 0x1}
+				receiver, err := fec.NewClassReceiver(protocol.XORFECScheme)
+				Expect(err).ToNot(HaveOccurred())
+				conn.fecReceiver = receiver
+			}
+
+			It("rejects SOURCE_SYMBOL frames if FEC wasn't negotiated", func() {
+				Expect(conn.fecReceiver).To(BeNil())
+				f := &wire.SourceSymbolFrame{SSID: 1, Payload: []byte{0x1}}
+				tracer.EXPECT().RejectedFECFrame(&logging.SourceSymbolFrame{SID: 1, Length: 1}, logging.FECFrameRejectNotNegotiated)
+				_, err := conn.handleSourceSymbolFrame(f)
+				expectFECError(err)
+			})
+
+			It("rejects REPAIR frames if FEC wasn't negotiated", func() {
+				Expect(conn.fecReceiver).To(BeNil())
+				f := &wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: 1}, Payload: []byte{0, 1, 2}}
+				tracer.EXPECT().RejectedFECFrame(&logging.RepairFrame{BlockID: 1, Length: 3}, logging.FECFrameRejectNotNegotiated)
+				_, err := conn.handleRepairFrame(f)
+				expectFECError(err)
+			})
+
+			It("rejects FEC_WINDOW frames if FEC wasn't negotiated", func() {
+				f := &wire.FECWindowFrame{Epoch: 1, Size: 10}
+				tracer.EXPECT().RejectedFECFrame(f, logging.FECFrameRejectNotNegotiated)
+				expectFECError(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{}))
+			})
+
+			It("rejects FEC frames if the peer didn't enable FEC", func() {
+				enableFEC()
+				conn.peerParams = &wire.TransportParameters{}
+				tracer.EXPECT().RejectedFECFrame(gomock.Any(), logging.FECFrameRejectNotNegotiated)
+				_, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 1, Payload: []byte{0x1}})
+				expectFECError(err)
+			})
+
+			It("rejects FEC frames if the peer uses a different wire format", func() {
+				enableFEC()
+				conn.peerParams.FECWireFormat = protocol.FECWireFormatDraft
+				tracer.EXPECT().RejectedFECFrame(gomock.Any(), logging.FECFrameRejectNotNegotiated)
+				_, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 1, Payload: []byte{0x1}})
+				expectFECError(err)
+			})
+
+			It("rejects FEC frames that use a different wire format", func() {
+				enableFEC()
+				tracer.EXPECT().RejectedFECFrame(&logging.SourceSymbolFrame{SID: 1, Length: 1}, logging.FECFrameRejectNotNegotiated)
+				_, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: 1, Payload: []byte{0x1}})
+				expectFECError(err)
+				tracer.EXPECT().RejectedFECFrame(&logging.RepairFrame{BlockID: 1, Length: 3}, logging.FECFrameRejectNotNegotiated)
+				_, err = conn.handleRepairFrame(&wire.RepairFrame{Format: protocol.FECWireFormatDraft, Metadata: protocol.BlockMetadata{BlockID: 1}, Payload: []byte{0, 1, 2}})
+				expectFECError(err)
+			})
+
+			It("handles FEC frames in the draft wire format", func() {
+				enableFEC()
+				conn.config.FECWireFormat = protocol.FECWireFormatDraft
+				conn.peerParams.FECWireFormat = protocol.FECWireFormatDraft
+				data, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: 0, Payload: []byte{0x1}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0x1}))
+			})
+
+			It("handles FEC frames if FEC was negotiated", func() {
+				enableFEC()
+				sender, err := fec.NewSender(protocol.XORFECScheme)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 0, Payload: []byte{0x1}})
+				Expect(err).ToNot(HaveOccurred())
+				repairs, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 1, Payload: []byte{0x2}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(repairs).To(HaveLen(1))
+
+				data, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 0, Payload: []byte{0x1}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0x1}))
+				data, err = conn.handleRepairFrame(repairs[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0x2}))
+			})
+
+			It("applies the decoding window of the peer", func() {
+				enableFEC()
+				packer.EXPECT().SetFECPeerWindow(protocol.FECWindowSize(10))
+				Expect(conn.handleFrame(&wire.FECWindowFrame{Epoch: 1, Size: 10}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				// reordered FEC_WINDOW frames are ignored
+				Expect(conn.handleFrame(&wire.FECWindowFrame{Epoch: 0, Size: 5}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				Expect(conn.handleFrame(&wire.FECWindowFrame{Epoch: 1, Size: 5}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+				packer.EXPECT().SetFECPeerWindow(protocol.FECWindowSize(20))
+				Expect(conn.handleFrame(&wire.FECWindowFrame{Epoch: 2, Size: 20}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("handles FEC frames of different protection classes separately", func() {
+				enableFEC()
+				sender, err := fec.NewSenderWithClass(protocol.XORFECScheme, protocol.FECProtectionHigh)
+				Expect(err).ToNot(HaveOccurred())
+				repairs, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{Class: protocol.FECProtectionHigh, SSID: 0, Payload: []byte{0x3}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(repairs).To(HaveLen(1))
+
+				// The default class uses the same SSID, but a different block.
+				data, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 0, Payload: []byte{0x1}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0x1}))
+				// The source symbol of the high protection class is lost, and recovered from the repair symbol.
+				data, err = conn.handleRepairFrame(repairs[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte{0x3}))
+			})
+
+			It("rejects DATAGRAM frames with an invalid protection class", func() {
+				enableFEC()
+				conn.peerParams.MaxDatagramFrameSize = 1000
+				Expect(conn.SendDatagramWithFECClass([]byte("foobar"), protocol.MaxFECProtectionClass+1)).To(MatchError("invalid FEC protection class: 3"))
+				Expect(conn.SendDatagramWithFECClass([]byte("foobar"), protocol.FECProtectionHigh)).To(Succeed())
+				f := conn.datagramQueue.Peek()
+				Expect(f.FECProtected).To(BeTrue())
+				Expect(f.FECProtectionClass).To(Equal(protocol.FECProtectionHigh))
+			})
+
+			It("sends DATAGRAM frames with options", func() {
+				enableFEC()
+				conn.peerParams.MaxDatagramFrameSize = 1000
+				Expect(conn.SendDatagramWithOptions([]byte("foobar"), DatagramOptions{Priority: MaxDatagramPriority + 1})).To(MatchError("invalid datagram priority: 8"))
+				Expect(conn.SendDatagramWithOptions([]byte("foo"), DatagramOptions{})).To(Succeed())
+				Expect(conn.SendDatagramWithOptions([]byte("bar"), DatagramOptions{
+					Priority:           MaxDatagramPriority,
+					FECProtected:       true,
+					FECProtectionClass: protocol.FECProtectionLow,
+				})).To(Succeed())
+				f := conn.datagramQueue.Peek()
+				Expect(f.Data).To(Equal([]byte("bar")))
+				Expect(f.FECProtected).To(BeTrue())
+				Expect(f.FECProtectionClass).To(Equal(protocol.FECProtectionLow))
+				conn.datagramQueue.Pop()
+				Expect(conn.DatagramStats()).To(Equal(DatagramStats{Sent: 1}))
+			})
+
+			It("rejects REPAIR frames with inconsistent lengths", func() {
+				enableFEC()
+				_, err := conn.handleRepairFrame(&wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: 0}, Payload: []byte{0, 1, 2}})
+				Expect(err).ToNot(HaveOccurred())
+				f := &wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: 0}, Payload: []byte{0, 1, 2, 3}}
+				tracer.EXPECT().RejectedFECFrame(&logging.RepairFrame{BlockID: 0, Length: 4}, logging.FECFrameRejectInvalidSymbol)
+				_, err = conn.handleRepairFrame(f)
+				expectFECError(err)
+			})
+
+			It("rejects SOURCE_SYMBOL frames outside of the decoding window", func() {
+				enableFEC()
+				ssid := protocol.SourceSymbolID(protocol.MaxFECDecodingWindow + 2)
+				tracer.EXPECT().RejectedFECFrame(&logging.SourceSymbolFrame{SID: ssid, Length: 1}, logging.FECFrameRejectOutsideWindow)
+				_, err := conn.handleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: ssid, Payload: []byte{0x1}})
+				expectFECError(err)
+			})
+		})
+
 		It("handles BLOCKED frames", func() {
 			err := conn.handleFrame(&wire.DataBlockedFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})
 			Expect(err).NotTo(HaveOccurred())
@@ -2104,6 +2292,118 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
+		It("enables the ACK frequency extension", func() {
+			conn.config.EnableAckFrequency = true
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			minAckDelay := 2 * time.Millisecond
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				MaxAckDelay:               protocol.DefaultMaxAckDelay,
+				MinAckDelay:               &minAckDelay,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sph.EXPECT().EnableAckFrequency(minAckDelay)
+			conn.handleTransportParameters(params)
+			Expect(conn.ackFrequencyEnabled).To(BeTrue())
+
+			// ACK_FREQUENCY frames are only sent once the handshake is confirmed
+			now := time.Now()
+			conn.queueControlFramesForSending(now)
+			conn.handshakeConfirmed = true
+			f := &wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 3}
+			sph.EXPECT().GetAckFrequencyFrame(now).Return(f)
+			conn.queueControlFramesForSending(now)
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f))
+		})
+
+		It("advertises the FEC decoding window", func() {
+			conn.config.EnableFEC = true
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				EnableFEC:                 0x1,
+				DecoderFECScheme:          protocol.XORFECScheme,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			packer.EXPECT().SetFECScheme(protocol.XORFECScheme, protocol.FECWireFormatLegacy)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(&wire.FECWindowFrame{Size: protocol.MaxFECDecodingWindow}))
+		})
+
+		It("rejects transport parameters with an unsupported FEC scheme", func() {
+			conn.config.EnableFEC = true
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				EnableFEC:                 0x1,
+				DecoderFECScheme:          7,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().SetFECScheme(protocol.DecoderFECScheme(7), protocol.FECWireFormatLegacy).Return(errors.New("unknown FEC scheme"))
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(conn.handleTransportParameters(params)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "unknown FEC scheme",
+			}))
+			Expect(conn.earlyConnReady()).ToNot(BeClosed())
+		})
+
+		It("doesn't advertise the FEC decoding window in the draft wire format", func() {
+			conn.config.EnableFEC = true
+			conn.config.FECWireFormat = protocol.FECWireFormatDraft
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				EnableFEC:                 0x1,
+				DecoderFECScheme:          protocol.XORFECScheme,
+				FECWireFormat:             protocol.FECWireFormatDraft,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			packer.EXPECT().SetFECScheme(protocol.XORFECScheme, protocol.FECWireFormatDraft)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(BeEmpty())
+		})
+
+		It("enables the reliable stream reset extension", func() {
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				EnableResetStreamAt:       true,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.config.EnableStreamResetPartialDelivery = true
+			Expect(conn.supportsResetStreamAt()).To(BeFalse())
+			conn.handleTransportParameters(params)
+			Expect(conn.supportsResetStreamAt()).To(BeTrue())
+		})
+
+		It("doesn't enable the reliable stream reset extension if it's disabled in the config", func() {
+			params := &wire.TransportParameters{
+				MaxIdleTimeout:            90 * time.Second,
+				EnableResetStreamAt:       true,
+				InitialSourceConnectionID: destConnID,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			conn.handleTransportParameters(params)
+			Expect(conn.supportsResetStreamAt()).To(BeFalse())
+		})
 	})
 
 	Context("keep-alives", func() {
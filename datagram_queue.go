@@ -3,110 +3,309 @@ package quic
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 )
 
-const (
-	maxDatagramSendQueueLen = 32
-	maxDatagramRcvQueueLen  = 128
-)
+type queuedDatagram struct {
+	frame  *wire.DatagramFrame
+	expiry time.Time // zero if the datagram doesn't expire
+}
+
+func (d queuedDatagram) expired(now time.Time) bool {
+	return !d.expiry.IsZero() && !now.Before(d.expiry)
+}
+
+type droppedDatagram struct {
+	length protocol.ByteCount
+	reason logging.DatagramDropReason
+}
+
+type datagramSendStats struct {
+	sent, droppedExpired, droppedQueueFull, droppedTooLarge uint64
+}
+
+type datagramReceiveStats struct {
+	received, droppedQueueFull uint64
+}
 
 type datagramQueue struct {
-	sendMx    sync.Mutex
-	sendQueue ringbuffer.RingBuffer[*wire.DatagramFrame]
-	sent      chan struct{} // used to notify Add that a datagram was dequeued
+	sendMx sync.Mutex
+	// one queue per priority, datagrams of a higher priority are sent first
+	sendQueues   [MaxDatagramPriority + 1]ringbuffer.RingBuffer[queuedDatagram]
+	sendQueueLen int
+	maxSendLen   int
+	// next is the datagram returned by Peek.
+	// It is removed from the send queues, so that a datagram of a higher priority queued in the meantime doesn't replace it.
+	next *queuedDatagram
+	sent chan struct{} // used to notify Add that a datagram was dequeued
+	// Datagrams dropped while adding a datagram to the queue.
+	// They are reported to the tracer from the connection's run loop.
+	pendingDrops []droppedDatagram
 
-	rcvMx    sync.Mutex
-	rcvQueue [][]byte
-	rcvd     chan struct{} // used to notify Receive that a new datagram was received
+	sendStats datagramSendStats // protected by the sendMx
+
+	rcvMx     sync.Mutex
+	rcvQueue  [][]byte
+	maxRcvLen int
+	rcvd      chan struct{}        // used to notify Receive that a new datagram was received
+	rcvStats  datagramReceiveStats // protected by the rcvMx
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
+	tracer *logging.ConnectionTracer
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), maxSendLen, maxRcvLen int, tracer *logging.ConnectionTracer, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData: hasData,
-		rcvd:    make(chan struct{}, 1),
-		sent:    make(chan struct{}, 1),
-		closed:  make(chan struct{}),
-		logger:  logger,
+		hasData:    hasData,
+		maxSendLen: maxSendLen,
+		maxRcvLen:  maxRcvLen,
+		rcvd:       make(chan struct{}, 1),
+		sent:       make(chan struct{}, 1),
+		closed:     make(chan struct{}),
+		tracer:     tracer,
+		logger:     logger,
 	}
 }
 
-// Add queues a new DATAGRAM frame for sending.
-// Up to 32 DATAGRAM frames will be queued.
-// Once that limit is reached, Add blocks until the queue size has reduced.
+// Add queues a new DATAGRAM frame for sending, using the default priority.
+// The frame doesn't expire.
+// Once the queue is full, Add blocks until the queue size has reduced.
 func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
-	h.sendMx.Lock()
+	return h.AddWithOptions(f, 0, time.Time{}, false)
+}
+
+// AddWithOptions queues a new DATAGRAM frame for sending.
+// If the queue is full, datagrams of a lower priority are dropped to make room for the new datagram.
+// If that's not possible, AddWithOptions blocks until the queue size has reduced,
+// or returns ErrDatagramQueueFull if nonBlocking is set.
+// If the datagram expires while waiting, it is dropped.
+func (h *datagramQueue) AddWithOptions(f *wire.DatagramFrame, prio DatagramPriority, expiry time.Time, nonBlocking bool) error {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
 
+	h.sendMx.Lock()
 	for {
-		if h.sendQueue.Len() < maxDatagramSendQueueLen {
-			h.sendQueue.PushBack(f)
+		now := time.Now()
+		h.dropExpired(now)
+		if h.sendQueueLen >= h.maxSendLen {
+			h.dropLowerPriority(prio)
+		}
+		if h.sendQueueLen < h.maxSendLen {
+			h.sendQueues[prio].PushBack(queuedDatagram{frame: f, expiry: expiry})
+			h.sendQueueLen++
 			h.sendMx.Unlock()
 			h.hasData()
 			return nil
 		}
+		if !expiry.IsZero() && !now.Before(expiry) {
+			h.drop(f, logging.DatagramDropSendQueueFull)
+			h.sendMx.Unlock()
+			h.hasData() // make sure the drop is reported
+			return nil
+		}
+		if nonBlocking {
+			h.sendMx.Unlock()
+			h.hasData() // make sure drops of expired datagrams are reported
+			return ErrDatagramQueueFull
+		}
 		select {
 		case <-h.sent: // drain the queue so we don't loop immediately
 		default:
 		}
 		h.sendMx.Unlock()
+		var expired <-chan time.Time
+		if !expiry.IsZero() {
+			if timer == nil {
+				timer = time.NewTimer(expiry.Sub(now))
+			}
+			expired = timer.C
+		}
 		select {
 		case <-h.closed:
 			return h.closeErr
 		case <-h.sent:
+		case <-expired:
 		}
 		h.sendMx.Lock()
 	}
 }
 
+// dropExpired drops the expired datagrams at the front of the queues.
+// Datagrams of the same priority are usually queued with similar lifetimes,
+// so it's not necessary to check the datagrams behind a datagram that hasn't expired yet.
+// must be called while holding the sendMx
+func (h *datagramQueue) dropExpired(now time.Time) {
+	for i := range h.sendQueues {
+		q := &h.sendQueues[i]
+		for !q.Empty() && q.PeekFront().expired(now) {
+			h.drop(q.PopFront().frame, logging.DatagramDropExpired)
+			h.sendQueueLen--
+		}
+	}
+}
+
+// dropLowerPriority drops the oldest datagram of the lowest priority, if it has a lower priority than prio.
+// must be called while holding the sendMx
+func (h *datagramQueue) dropLowerPriority(prio DatagramPriority) {
+	for i := DatagramPriority(0); i < prio; i++ {
+		if q := &h.sendQueues[i]; !q.Empty() {
+			h.drop(q.PopFront().frame, logging.DatagramDropSendQueueFull)
+			h.sendQueueLen--
+			return
+		}
+	}
+}
+
+// must be called while holding the sendMx
+func (h *datagramQueue) drop(f *wire.DatagramFrame, reason logging.DatagramDropReason) {
+	switch reason {
+	case logging.DatagramDropExpired:
+		h.sendStats.droppedExpired++
+	case logging.DatagramDropSendQueueFull:
+		h.sendStats.droppedQueueFull++
+	case logging.DatagramDropTooLarge:
+		h.sendStats.droppedTooLarge++
+	}
+	if h.logger.Debug() {
+		h.logger.Debugf("Dropping DATAGRAM frame (%d bytes payload)", len(f.Data))
+	}
+	h.pendingDrops = append(h.pendingDrops, droppedDatagram{length: protocol.ByteCount(len(f.Data)), reason: reason})
+}
+
 // Peek gets the next DATAGRAM frame for sending.
+// Expired DATAGRAM frames are dropped.
 // If actually sent out, Pop needs to be called before the next call to Peek.
+// If it can't be sent, Drop needs to be called.
 func (h *datagramQueue) Peek() *wire.DatagramFrame {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	if h.sendQueue.Empty() {
-		return nil
+	f := h.peek()
+	h.sendMx.Unlock()
+	h.reportDrops()
+	return f
+}
+
+// must be called while holding the sendMx
+func (h *datagramQueue) peek() *wire.DatagramFrame {
+	now := time.Now()
+	if h.next != nil {
+		if !h.next.expired(now) {
+			return h.next.frame
+		}
+		h.drop(h.next.frame, logging.DatagramDropExpired)
+		h.pop()
+	}
+	for i := len(h.sendQueues) - 1; i >= 0; i-- {
+		q := &h.sendQueues[i]
+		for !q.Empty() {
+			d := q.PopFront()
+			h.next = &d
+			if d.expired(now) {
+				h.drop(d.frame, logging.DatagramDropExpired)
+				h.pop()
+				continue
+			}
+			return d.frame
+		}
 	}
-	return h.sendQueue.PeekFront()
+	return nil
 }
 
+// Pop removes the DATAGRAM frame returned by Peek, after it was sent.
 func (h *datagramQueue) Pop() {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	_ = h.sendQueue.PopFront()
+	if h.peek() != nil {
+		h.sendStats.sent++
+		h.pop()
+	}
+	h.sendMx.Unlock()
+	h.reportDrops()
+}
+
+// Drop removes the DATAGRAM frame returned by Peek, if it doesn't fit into a packet.
+func (h *datagramQueue) Drop() {
+	h.sendMx.Lock()
+	if f := h.peek(); f != nil {
+		h.drop(f, logging.DatagramDropTooLarge)
+		h.pop()
+	}
+	h.sendMx.Unlock()
+	h.reportDrops()
+}
+
+// must be called while holding the sendMx
+func (h *datagramQueue) pop() {
+	if h.next == nil {
+		return
+	}
+	h.next = nil
+	h.sendQueueLen--
+	h.signalSent()
+}
+
+func (h *datagramQueue) signalSent() {
 	select {
 	case h.sent <- struct{}{}:
 	default:
 	}
 }
 
+// reportDrops reports the dropped datagrams to the tracer.
+// It is called from the connection's run loop.
+func (h *datagramQueue) reportDrops() {
+	h.sendMx.Lock()
+	drops := h.pendingDrops
+	h.pendingDrops = nil
+	h.sendMx.Unlock()
+	if h.tracer == nil || h.tracer.DroppedDatagram == nil {
+		return
+	}
+	for _, d := range drops {
+		h.tracer.DroppedDatagram(d.length, d.reason)
+	}
+}
+
 // HandleDatagramFrame handles a received DATAGRAM frame.
 func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
 	data := make([]byte, len(f.Data))
 	copy(data, f.Data)
 	var queued bool
 	h.rcvMx.Lock()
-	if len(h.rcvQueue) < maxDatagramRcvQueueLen {
+	if len(h.rcvQueue) < h.maxRcvLen {
 		h.rcvQueue = append(h.rcvQueue, data)
+		h.rcvStats.received++
 		queued = true
 		select {
 		case h.rcvd <- struct{}{}:
 		default:
 		}
+	} else {
+		h.rcvStats.droppedQueueFull++
 	}
 	h.rcvMx.Unlock()
-	if !queued && h.logger.Debug() {
+	if queued {
+		return
+	}
+	if h.logger.Debug() {
 		h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
 	}
+	if h.tracer != nil && h.tracer.DroppedDatagram != nil {
+		h.tracer.DroppedDatagram(protocol.ByteCount(len(f.Data)), logging.DatagramDropReceiveQueueFull)
+	}
 }
 
 // Receive gets a received DATAGRAM frame.
@@ -131,6 +330,24 @@ func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
 	}
 }
 
+// Stats returns the number of datagrams sent, received and dropped.
+func (h *datagramQueue) Stats() DatagramStats {
+	h.sendMx.Lock()
+	sendStats := h.sendStats
+	h.sendMx.Unlock()
+	h.rcvMx.Lock()
+	rcvStats := h.rcvStats
+	h.rcvMx.Unlock()
+	return DatagramStats{
+		Sent:                    sendStats.sent,
+		SendDroppedExpired:      sendStats.droppedExpired,
+		SendDroppedQueueFull:    sendStats.droppedQueueFull,
+		SendDroppedTooLarge:     sendStats.droppedTooLarge,
+		Received:                rcvStats.received,
+		ReceiveDroppedQueueFull: rcvStats.droppedQueueFull,
+	}
+}
+
 func (h *datagramQueue) CloseWithError(e error) {
 	h.closeErr = e
 	close(h.closed)
@@ -5,20 +5,34 @@ import (
 	"errors"
 	"time"
 
+	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
+	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Datagram Queue", func() {
-	var queue *datagramQueue
-	var queued chan struct{}
+	var (
+		queue  *datagramQueue
+		queued chan struct{}
+		tracer *mocklogging.MockConnectionTracer
+	)
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		var tr *logging.ConnectionTracer
+		tr, tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
+		queue = newDatagramQueue(
+			func() { queued <- struct{}{} },
+			protocol.DefaultDatagramSendQueueLen,
+			protocol.DefaultDatagramReceiveQueueLen,
+			tr,
+			utils.DefaultLogger,
+		)
 	})
 
 	Context("sending", func() {
@@ -37,7 +51,7 @@ var _ = Describe("Datagram Queue", func() {
 		})
 
 		It("blocks when the maximum number of datagrams have been queued", func() {
-			for i := 0; i < maxDatagramSendQueueLen; i++ {
+			for i := 0; i < protocol.DefaultDatagramSendQueueLen; i++ {
 				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}})).To(Succeed())
 			}
 			errChan := make(chan error, 1)
@@ -50,7 +64,7 @@ var _ = Describe("Datagram Queue", func() {
 			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
 			queue.Pop()
 			Eventually(errChan).Should(Receive(BeNil()))
-			for i := 1; i < maxDatagramSendQueueLen; i++ {
+			for i := 1; i < protocol.DefaultDatagramSendQueueLen; i++ {
 				queue.Pop()
 			}
 			f := queue.Peek()
@@ -73,8 +87,108 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(f.Data).To(Equal([]byte("bar")))
 		})
 
+		It("sends datagrams of a higher priority first", func() {
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foo")}, 0, time.Time{}, false)).To(Succeed())
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("bar")}, 2, time.Time{}, false)).To(Succeed())
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("baz")}, 2, time.Time{}, false)).To(Succeed())
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("raboof")}, 1, time.Time{}, false)).To(Succeed())
+			var data []string
+			for f := queue.Peek(); f != nil; f = queue.Peek() {
+				data = append(data, string(f.Data))
+				queue.Pop()
+			}
+			Expect(data).To(Equal([]string{"bar", "baz", "raboof", "foo"}))
+			Expect(queue.Stats().Sent).To(BeEquivalentTo(4))
+		})
+
+		It("doesn't replace a peeked datagram with a datagram of a higher priority", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("bar")}, 1, time.Time{}, false)).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
+
+		It("drops expired datagrams", func() {
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foo")}, 0, time.Now().Add(-time.Second), false)).To(Succeed())
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foobar")}, 0, time.Now().Add(time.Hour), false)).To(Succeed())
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(3), logging.DatagramDropExpired)
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(queue.Stats().SendDroppedExpired).To(BeEquivalentTo(1))
+		})
+
+		It("drops a peeked datagram when it expires", func() {
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foo")}, 0, time.Now().Add(scaleDuration(20*time.Millisecond)), false)).To(Succeed())
+			Expect(queue.Peek()).ToNot(BeNil())
+			time.Sleep(scaleDuration(25 * time.Millisecond))
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(3), logging.DatagramDropExpired)
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("drops datagrams that are too large", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")})).To(Succeed())
+			Expect(queue.Peek()).ToNot(BeNil())
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(6), logging.DatagramDropTooLarge)
+			queue.Drop()
+			Expect(queue.Peek()).To(BeNil())
+			Expect(queue.Stats()).To(Equal(DatagramStats{SendDroppedTooLarge: 1}))
+		})
+
+		It("drops datagrams of a lower priority when the queue is full", func() {
+			for i := 0; i < protocol.DefaultDatagramSendQueueLen; i++ {
+				Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte{byte(i)}}, DatagramPriority(i%2), time.Time{}, false)).To(Succeed())
+			}
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foobar")}, 1, time.Time{}, false)).To(Succeed())
+			Expect(queue.Stats().SendDroppedQueueFull).To(BeEquivalentTo(1))
+			// the drop is reported when the next datagram is dequeued
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(1), logging.DatagramDropSendQueueFull)
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte{1}))
+			// the oldest datagram of priority 0 was dropped
+			var data [][]byte
+			for f := queue.Peek(); f != nil; f = queue.Peek() {
+				data = append(data, f.Data)
+				queue.Pop()
+			}
+			Expect(data).To(HaveLen(protocol.DefaultDatagramSendQueueLen))
+			Expect(data[protocol.DefaultDatagramSendQueueLen/2]).To(Equal([]byte("foobar")))
+			Expect(data[protocol.DefaultDatagramSendQueueLen/2+1]).To(Equal([]byte{2}))
+		})
+
+		It("blocks until the datagram expires if the queue is full", func() {
+			for i := 0; i < protocol.DefaultDatagramSendQueueLen; i++ {
+				Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte{0}}, 1, time.Time{}, false)).To(Succeed())
+			}
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foobar")}, 1, time.Now().Add(scaleDuration(50*time.Millisecond)), false)
+			}()
+			Consistently(errChan, scaleDuration(25*time.Millisecond)).ShouldNot(Receive())
+			Eventually(errChan).Should(Receive(BeNil()))
+			Expect(queue.Stats().SendDroppedQueueFull).To(BeEquivalentTo(1))
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(6), logging.DatagramDropSendQueueFull)
+			Expect(queue.Peek().Data).To(Equal([]byte{0}))
+		})
+
+		It("returns an error instead of blocking if the queue is full", func() {
+			for i := 0; i < protocol.DefaultDatagramSendQueueLen; i++ {
+				Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte{0}}, 1, time.Time{}, false)).To(Succeed())
+			}
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foobar")}, 1, time.Time{}, true)).To(MatchError(ErrDatagramQueueFull))
+			Expect(queue.Stats().SendDroppedQueueFull).To(BeZero())
+			// datagrams of a lower priority are still dropped to make room
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(1), logging.DatagramDropSendQueueFull)
+			Expect(queue.AddWithOptions(&wire.DatagramFrame{Data: []byte("foobar")}, 2, time.Time{}, true)).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foobar")))
+		})
+
 		It("closes", func() {
-			for i := 0; i < maxDatagramSendQueueLen; i++ {
+			for i := 0; i < protocol.DefaultDatagramSendQueueLen; i++ {
 				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
 			}
 			errChan := make(chan error, 1)
@@ -101,6 +215,42 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(data).To(Equal([]byte("bar")))
 		})
 
+		It("drops DATAGRAM frames when the receive queue is full", func() {
+			for i := 0; i < protocol.DefaultDatagramReceiveQueueLen; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+			}
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(6), logging.DatagramDropReceiveQueueFull)
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			Expect(queue.Stats()).To(Equal(DatagramStats{
+				Received:                protocol.DefaultDatagramReceiveQueueLen,
+				ReceiveDroppedQueueFull: 1,
+			}))
+			for i := 0; i < protocol.DefaultDatagramReceiveQueueLen; i++ {
+				data, err := queue.Receive(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foo")))
+			}
+		})
+
+		It("counts received and sent DATAGRAM frames concurrently", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				for i := 0; i < protocol.DefaultDatagramReceiveQueueLen; i++ {
+					queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+				}
+			}()
+			for i := 0; i < 10; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+				queue.Peek()
+				queue.Pop()
+				queue.Stats()
+			}
+			Eventually(done).Should(BeClosed())
+			Expect(queue.Stats()).To(Equal(DatagramStats{Sent: 10, Received: protocol.DefaultDatagramReceiveQueueLen}))
+		})
+
 		It("blocks until a frame is received", func() {
 			c := make(chan []byte, 1)
 			go func() {
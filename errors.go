@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -39,6 +40,7 @@ const (
 	KeyUpdateError            = qerr.KeyUpdateError
 	AEADLimitReached          = qerr.AEADLimitReached
 	NoViablePathError         = qerr.NoViablePathError
+	FECError                  = qerr.FECError
 )
 
 // A StreamError is used for Stream.CancelRead and Stream.CancelWrite.
@@ -73,3 +75,7 @@ func (e *DatagramTooLargeError) Is(target error) bool {
 }
 
 func (e *DatagramTooLargeError) Error() string { return "DATAGRAM frame too large" }
+
+// ErrDatagramQueueFull is returned from Connection.SendDatagramWithOptions if the send queue is full,
+// and DatagramOptions.NonBlocking is set.
+var ErrDatagramQueueFull = errors.New("datagram send queue full")
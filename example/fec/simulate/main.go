@@ -0,0 +1,207 @@
+// Command simulate runs FEC schemes over packet loss traces, without running QUIC connections.
+//
+// Synthetic source symbols are passed through the FEC encoder and decoder of the fec package,
+// which implement the FEC schemes that connections use,
+// so block geometries can be evaluated offline, e.g. on a trace captured on a lossy link.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"slices"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/fec"
+)
+
+var (
+	traceSpec  string
+	numPackets int
+	seed       int64
+	schemeName string
+	className  string
+	numSource  int
+	numRepair  int
+	symbolSize int
+	jsonOutput bool
+)
+
+func init() {
+	flag.StringVar(&traceSpec, "trace", "ge:p=0.01,r=0.3", "loss trace: bitmap:FILE, seq:FILE or ge:p=P,r=R,h=H,k=K")
+	flag.IntVar(&numPackets, "n", 100000, "number of packets generated by a Gilbert-Elliott model")
+	flag.Int64Var(&seed, "seed", 1, "seed of the Gilbert-Elliott model and the symbol payloads")
+	flag.StringVar(&schemeName, "scheme", "rs", "FEC scheme: xor, rs or rs-large")
+	flag.StringVar(&className, "class", "default", "use the block geometry connections use for a protection class: default, high or low")
+	flag.IntVar(&numSource, "k", 0, "number of source symbols per block, overrides -class")
+	flag.IntVar(&numRepair, "r", 0, "number of repair symbols per block, overrides -class")
+	flag.IntVar(&symbolSize, "size", 1200, "size of the source symbols in bytes")
+	flag.BoolVar(&jsonOutput, "json", false, "print the results as JSON")
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	var scheme fec.Scheme
+	switch schemeName {
+	case "xor":
+		scheme = fec.XOR
+	case "rs":
+		scheme = fec.ReedSolomon
+	case "rs-large":
+		scheme = fec.LargeBlockReedSolomon
+	default:
+		return fmt.Errorf("invalid FEC scheme %q", schemeName)
+	}
+	maxSymbolSize := fec.MaxSymbolSize
+	if scheme == fec.LargeBlockReedSolomon {
+		maxSymbolSize = fec.MaxLargeBlockSymbolSize
+	}
+	if symbolSize < 1 || symbolSize > maxSymbolSize {
+		return fmt.Errorf("invalid symbol size %d", symbolSize)
+	}
+	trace, err := loadTrace(traceSpec, numPackets, seed)
+	if err != nil {
+		return err
+	}
+	if len(trace) == 0 {
+		return errors.New("empty trace")
+	}
+
+	k, r := numSource, numRepair
+	if k == 0 && r == 0 {
+		class, err := parseClass(className)
+		if err != nil {
+			return err
+		}
+		if k, r, err = fec.ClassGeometry(scheme, class); err != nil {
+			return err
+		}
+	}
+	enc, err := fec.NewEncoder(scheme, k, r)
+	if err != nil {
+		return err
+	}
+	dec, err := fec.NewDecoder(scheme, k, r)
+	if err != nil {
+		return err
+	}
+	res, err := simulate(enc, dec, trace, symbolSize, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	if res.Blocks == 0 {
+		return errors.New("the trace is too short for a single block")
+	}
+	if jsonOutput {
+		return printJSON(res)
+	}
+	printResult(res)
+	return nil
+}
+
+func parseClass(s string) (quic.FECProtectionClass, error) {
+	for _, c := range []quic.FECProtectionClass{quic.FECProtectionDefault, quic.FECProtectionHigh, quic.FECProtectionLow} {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid protection class %q", s)
+}
+
+func geometry() string {
+	if numSource > 0 || numRepair > 0 {
+		return fmt.Sprintf("%d source and %d repair symbols per block", numSource, numRepair)
+	}
+	return fmt.Sprintf("protection class %s", className)
+}
+
+type delayStats struct {
+	Mean float64 `json:"mean"`
+	P50  int     `json:"p50"`
+	P95  int     `json:"p95"`
+	P99  int     `json:"p99"`
+	Max  int     `json:"max"`
+}
+
+func recoveryDelay(delays []int) delayStats {
+	if len(delays) == 0 {
+		return delayStats{}
+	}
+	d := slices.Clone(delays)
+	slices.Sort(d)
+	var sum int
+	for _, v := range d {
+		sum += v
+	}
+	percentile := func(p float64) int { return d[int(p*float64(len(d)-1))] }
+	return delayStats{
+		Mean: float64(sum) / float64(len(d)),
+		P50:  percentile(0.5),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  d[len(d)-1],
+	}
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func printResult(res *result) {
+	fmt.Printf("scheme:             %s, %s, %d byte symbols\n", schemeName, geometry(), symbolSize)
+	fmt.Printf("trace:              %s, %d packets simulated\n", traceSpec, res.Packets)
+	fmt.Printf("packet loss rate:   %.4f%% (%d packets)\n", 100*ratio(res.LostPackets, res.Packets), res.LostPackets)
+	fmt.Printf("source symbols:     %d sent, %d lost (%.4f%%)\n", res.SourceSymbols, res.LostSourceSymbols, 100*ratio(res.LostSourceSymbols, res.SourceSymbols))
+	fmt.Printf("repair symbols:     %d sent, %d lost\n", res.RepairSymbols, res.LostRepairSymbols)
+	fmt.Printf("overhead:           %.2f%% of the symbols, %.2f%% of the bytes\n", 100*ratio(res.RepairSymbols, res.SourceSymbols), 100*ratio(res.RepairBytes, res.SourceBytes))
+	fmt.Printf("recovered symbols:  %d (%.2f%% of the lost source symbols)\n", res.RecoveredSymbols, 100*ratio(res.RecoveredSymbols, res.LostSourceSymbols))
+	fmt.Printf("residual loss rate: %.4f%% (%d source symbols)\n", 100*ratio(res.ResidualSymbols, res.SourceSymbols), res.ResidualSymbols)
+	fmt.Printf("blocks:             %d, %d with losses, %d not recoverable\n", res.Blocks, res.BlocksWithLoss, res.UnrecoverableBlocks)
+	if d := recoveryDelay(res.RecoveryDelays); res.RecoveredSymbols > 0 {
+		fmt.Printf("recovery delay:     mean %.1f, p50 %d, p95 %d, p99 %d, max %d symbols\n", d.Mean, d.P50, d.P95, d.P99, d.Max)
+	}
+	if res.CorruptedSymbols > 0 {
+		fmt.Printf("CORRUPTED:          %d recovered source symbols don't match the sent source symbols\n", res.CorruptedSymbols)
+	}
+}
+
+func printJSON(res *result) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Scheme     string `json:"scheme"`
+		Geometry   string `json:"geometry"`
+		SymbolSize int    `json:"symbol_size"`
+		Trace      string `json:"trace"`
+		*result
+		PacketLossRate   float64    `json:"packet_loss_rate"`
+		ResidualLossRate float64    `json:"residual_loss_rate"`
+		SymbolOverhead   float64    `json:"symbol_overhead"`
+		ByteOverhead     float64    `json:"byte_overhead"`
+		RecoveryDelay    delayStats `json:"recovery_delay"`
+	}{
+		Scheme:           schemeName,
+		Geometry:         geometry(),
+		SymbolSize:       symbolSize,
+		Trace:            traceSpec,
+		result:           res,
+		PacketLossRate:   ratio(res.LostPackets, res.Packets),
+		ResidualLossRate: ratio(res.ResidualSymbols, res.SourceSymbols),
+		SymbolOverhead:   ratio(res.RepairSymbols, res.SourceSymbols),
+		ByteOverhead:     ratio(res.RepairBytes, res.SourceBytes),
+		RecoveryDelay:    recoveryDelay(res.RecoveryDelays),
+	})
+}
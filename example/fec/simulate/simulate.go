@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/quic-go/quic-go/fec"
+)
+
+type result struct {
+	Packets     int `json:"packets"`
+	LostPackets int `json:"lost_packets"`
+
+	SourceSymbols     int `json:"source_symbols"`
+	LostSourceSymbols int `json:"lost_source_symbols"`
+	SourceBytes       int `json:"source_bytes"`
+	RepairSymbols     int `json:"repair_symbols"`
+	LostRepairSymbols int `json:"lost_repair_symbols"`
+	RepairBytes       int `json:"repair_bytes"`
+
+	RecoveredSymbols int `json:"recovered_symbols"`
+	// ResidualSymbols is the number of source symbols that were lost and couldn't be recovered.
+	ResidualSymbols int `json:"residual_symbols"`
+	// CorruptedSymbols is the number of recovered source symbols that don't match the source symbol that was sent.
+	// It is always 0, unless the FEC scheme is broken.
+	CorruptedSymbols int `json:"corrupted_symbols"`
+
+	Blocks              int `json:"blocks"`
+	BlocksWithLoss      int `json:"blocks_with_loss"`
+	UnrecoverableBlocks int `json:"unrecoverable_blocks"`
+
+	// RecoveryDelays is the number of symbols sent between a lost source symbol and the repair symbol that recovered it.
+	RecoveryDelays []int `json:"-"`
+}
+
+type packet struct {
+	source *fec.SourceSymbol
+	repair *fec.RepairSymbol
+}
+
+// simulate generates source symbols using the encoder, and passes the symbols that are not lost according to the trace
+// to the decoder. Every symbol is sent in its own packet, in the order in which the encoder generates them.
+// The simulation stops when the trace doesn't cover all symbols of the next block.
+func simulate(enc *fec.Encoder, dec *fec.Decoder, trace []bool, symbolSize int, rng *rand.Rand) (*result, error) {
+	var res result
+	var pos int
+	for {
+		block, payloads, err := nextBlock(enc, symbolSize, rng)
+		if err != nil {
+			return nil, err
+		}
+		if pos+len(block) > len(trace) {
+			return &res, nil
+		}
+		if err := transmitBlock(&res, dec, block, payloads, trace[pos:pos+len(block)]); err != nil {
+			return nil, err
+		}
+		pos += len(block)
+	}
+}
+
+// nextBlock generates the source symbols of the next block, followed by its repair symbols.
+func nextBlock(enc *fec.Encoder, symbolSize int, rng *rand.Rand) ([]packet, map[uint64][]byte, error) {
+	var block []packet
+	payloads := make(map[uint64][]byte)
+	for {
+		payload := make([]byte, symbolSize)
+		rng.Read(payload)
+		// the encoder copies the payload
+		s, repairs, err := enc.AddSourceSymbol(payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		payloads[s.ID] = payload
+		block = append(block, packet{source: &s})
+		for i := range repairs {
+			block = append(block, packet{repair: &repairs[i]})
+		}
+		if len(repairs) > 0 {
+			return block, payloads, nil
+		}
+	}
+}
+
+func transmitBlock(res *result, dec *fec.Decoder, block []packet, payloads map[uint64][]byte, lost []bool) error {
+	sentAt := make(map[uint64]int, len(payloads))
+	delivered := make(map[uint64]bool, len(payloads))
+	var numLostSourceSymbols int
+	for i, p := range block {
+		res.Packets++
+		if p.source != nil {
+			sentAt[p.source.ID] = i
+			res.SourceSymbols++
+			res.SourceBytes += len(p.source.Data)
+		} else {
+			res.RepairSymbols++
+			res.RepairBytes += len(p.repair.Data)
+		}
+		if lost[i] {
+			res.LostPackets++
+			if p.source != nil {
+				res.LostSourceSymbols++
+				numLostSourceSymbols++
+			} else {
+				res.LostRepairSymbols++
+			}
+			continue
+		}
+
+		var symbols []fec.SourceSymbol
+		var err error
+		if p.source != nil {
+			symbols, err = dec.AddSourceSymbol(*p.source)
+		} else {
+			symbols, err = dec.AddRepairSymbol(*p.repair)
+		}
+		if err != nil {
+			return err
+		}
+		for _, s := range symbols {
+			delivered[s.ID] = true
+			if p.source != nil && s.ID == p.source.ID {
+				continue
+			}
+			res.RecoveredSymbols++
+			res.RecoveryDelays = append(res.RecoveryDelays, i-sentAt[s.ID])
+			if !bytes.Equal(s.Data, payloads[s.ID]) {
+				res.CorruptedSymbols++
+			}
+		}
+	}
+
+	res.Blocks++
+	if numLostSourceSymbols > 0 {
+		res.BlocksWithLoss++
+	}
+	var residual int
+	for ssid := range payloads {
+		if !delivered[ssid] {
+			residual++
+		}
+	}
+	res.ResidualSymbols += residual
+	if residual > 0 {
+		res.UnrecoverableBlocks++
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/quic-go/quic-go/fec"
+)
+
+func TestSimulate(t *testing.T) {
+	const k, r = 10, 2
+	for _, scheme := range []fec.Scheme{fec.XOR, fec.ReedSolomon} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			numRepair := r
+			if scheme == fec.XOR {
+				numRepair = 1
+			}
+			enc, err := fec.NewEncoder(scheme, k, numRepair)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := fec.NewDecoder(scheme, k, numRepair)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// lose the first source symbol of every block
+			blockLen := k + numRepair
+			trace := make([]bool, 100*blockLen)
+			for i := 0; i < len(trace); i += blockLen {
+				trace[i] = true
+			}
+			res, err := simulate(enc, dec, trace, 100, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Blocks != 100 || res.SourceSymbols != 100*k {
+				t.Fatalf("expected 100 blocks with %d source symbols, got %d blocks with %d source symbols", 100*k, res.Blocks, res.SourceSymbols)
+			}
+			if res.LostSourceSymbols != 100 || res.RecoveredSymbols != 100 {
+				t.Fatalf("expected 100 lost and recovered symbols, got %d lost and %d recovered", res.LostSourceSymbols, res.RecoveredSymbols)
+			}
+			if res.ResidualSymbols != 0 || res.CorruptedSymbols != 0 || res.UnrecoverableBlocks != 0 {
+				t.Fatalf("unexpected unrecovered or corrupted symbols: %+v", res)
+			}
+		})
+	}
+}
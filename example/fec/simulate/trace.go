@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// loadTrace returns the loss trace described by spec, true meaning that the packet was lost.
+//
+//	bitmap:FILE              a file of 0 (received) and 1 (lost) characters, all other characters are ignored
+//	seq:FILE                 a file with the sequence numbers of the received packets, one per line
+//	ge:p=P,r=R,h=H,k=K       a Gilbert-Elliott model, generating numPackets packets
+func loadTrace(spec string, numPackets int, seed int64) ([]bool, error) {
+	kind, arg, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid trace %q, expected kind:argument", spec)
+	}
+	switch kind {
+	case "bitmap":
+		return readBitmap(arg)
+	case "seq":
+		return readSequenceNumbers(arg)
+	case "ge":
+		m, err := parseGilbertElliott(arg)
+		if err != nil {
+			return nil, err
+		}
+		return m.generate(rand.New(rand.NewSource(seed)), numPackets), nil
+	default:
+		return nil, fmt.Errorf("unknown trace kind %q", kind)
+	}
+}
+
+func readBitmap(path string) ([]bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trace := make([]bool, 0, len(b))
+	for _, c := range b {
+		switch c {
+		case '0':
+			trace = append(trace, false)
+		case '1':
+			trace = append(trace, true)
+		}
+	}
+	return trace, nil
+}
+
+// readSequenceNumbers reads the sequence numbers of the received packets, e.g. extracted from a packet capture.
+// Packets between the lowest and the highest sequence number that were not received are lost.
+// Lines that are empty or start with # are ignored.
+func readSequenceNumbers(path string) ([]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var seqs []uint64
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimFunc(scanner.Text(), unicode.IsSpace)
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		seq, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		seqs = append(seqs, seq)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		return nil, nil
+	}
+	// Packets might have been reordered or duplicated.
+	slices.Sort(seqs)
+	seqs = slices.Compact(seqs)
+	first, last := seqs[0], seqs[len(seqs)-1]
+	if last-first >= 1<<30 {
+		return nil, fmt.Errorf("sequence numbers span too large: %d to %d", first, last)
+	}
+	trace := make([]bool, last-first+1)
+	for i := range trace {
+		trace[i] = true
+	}
+	for _, seq := range seqs {
+		trace[seq-first] = false
+	}
+	return trace, nil
+}
+
+// gilbertElliott is a two-state Markov model of packet loss.
+// The parameters have the same meaning as in netem's gemodel and in quic.LossEstimate:
+// p is the probability of moving from the good to the bad state, r the probability of moving back,
+// h is the probability that a packet is delivered in the bad state, and k in the good state.
+type gilbertElliott struct {
+	p, r, h, k float64
+}
+
+func parseGilbertElliott(s string) (*gilbertElliott, error) {
+	// defaults to the simple Gilbert model: all packets are lost in the bad state, and delivered in the good state
+	m := &gilbertElliott{r: 1, h: 0, k: 1}
+	for _, param := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid Gilbert-Elliott parameter %q", param)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 || v > 1 {
+			return nil, fmt.Errorf("invalid Gilbert-Elliott parameter %q: must be a probability", param)
+		}
+		switch name {
+		case "p":
+			m.p = v
+		case "r":
+			m.r = v
+		case "h":
+			m.h = v
+		case "k":
+			m.k = v
+		default:
+			return nil, fmt.Errorf("unknown Gilbert-Elliott parameter %q", name)
+		}
+	}
+	return m, nil
+}
+
+func (m *gilbertElliott) generate(rng *rand.Rand, n int) []bool {
+	trace := make([]bool, n)
+	bad := false
+	for i := range trace {
+		if bad {
+			bad = rng.Float64() >= m.r
+		} else {
+			bad = rng.Float64() < m.p
+		}
+		deliveryProb := m.k
+		if bad {
+			deliveryProb = m.h
+		}
+		trace[i] = rng.Float64() >= deliveryProb
+	}
+	return trace
+}
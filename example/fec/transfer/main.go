@@ -0,0 +1,182 @@
+// Command transfer moves files over a FEC protected QUIC connection.
+//
+// The sender uploads files to the receiver, one FEC protected stream per file.
+// Interrupted transfers are resumed where they stopped, and every file is verified using its SHA-256 hash.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/example/fec/internal/certs"
+	"github.com/quic-go/quic-go/fec"
+	"github.com/quic-go/quic-go/logging"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+var (
+	mode      string
+	fecScheme string
+	fecFormat string
+	useQlog   bool
+
+	// receiver
+	listenAddr string
+	dir        string
+	certFile   string
+	keyFile    string
+
+	// sender
+	serverAddr string
+	parallel   int
+	attempts   int
+	progress   time.Duration
+	caFile     string
+	serverName string
+	insecure   bool
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %s -mode receive [flags]\n  %s -mode send [flags] file|directory...\n\nFlags:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&mode, "mode", "send", "send or receive")
+	flag.StringVar(&fecScheme, "fec", "rs", "FEC scheme: none, xor, rs or rs-large")
+	flag.StringVar(&fecFormat, "fec-format", "legacy", "encoding of the FEC frames: legacy or draft")
+	flag.BoolVar(&useQlog, "qlog", false, "write qlog files to the directory set in the QLOGDIR environment variable")
+
+	flag.StringVar(&listenAddr, "listen", "0.0.0.0:4243", "receive: address to listen on")
+	flag.StringVar(&dir, "dir", ".", "receive: directory the received files are stored in")
+	flag.StringVar(&certFile, "cert", "", "receive: certificate file (PEM). A self-signed certificate is generated if not set")
+	flag.StringVar(&keyFile, "key", "", "receive: private key file (PEM)")
+
+	flag.StringVar(&serverAddr, "server", "localhost:4243", "send: address of the receiver")
+	flag.IntVar(&parallel, "parallel", 4, "send: number of files transferred in parallel, each on its own stream")
+	flag.IntVar(&attempts, "attempts", 10, "send: number of attempts per file, the transfer is resumed after a connection loss")
+	flag.DurationVar(&progress, "progress", time.Second, "send: interval of the progress report, 0 disables it")
+	flag.StringVar(&caFile, "ca", "", "send: CA certificate file (PEM) used to verify the receiver's certificate")
+	flag.StringVar(&serverName, "sni", "", "send: server name used to verify the receiver's certificate, defaults to the host of -server")
+	flag.BoolVar(&insecure, "insecure", false, "send: don't verify the receiver's certificate")
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	conf, err := quicConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch mode {
+	case "receive":
+		err = runReceiver(ctx, conf)
+	case "send":
+		err = runSender(ctx, conf, flag.Args())
+	default:
+		err = fmt.Errorf("invalid mode %q", mode)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
+
+func quicConfig() (*quic.Config, error) {
+	conf := &quic.Config{
+		MaxIdleTimeout:  30 * time.Second,
+		KeepAlivePeriod: 5 * time.Second,
+	}
+	switch fecScheme {
+	case "none":
+	case "xor":
+		conf.EnableFEC = true
+		conf.DecoderFECScheme = fec.XOR
+	case "rs":
+		conf.EnableFEC = true
+		conf.DecoderFECScheme = fec.ReedSolomon
+	case "rs-large":
+		conf.EnableFEC = true
+		conf.DecoderFECScheme = fec.LargeBlockReedSolomon
+	default:
+		return nil, fmt.Errorf("invalid FEC scheme %q", fecScheme)
+	}
+	switch fecFormat {
+	case "legacy":
+		conf.FECWireFormat = quic.FECWireFormatLegacy
+	case "draft":
+		conf.FECWireFormat = quic.FECWireFormatDraft
+	default:
+		return nil, fmt.Errorf("invalid FEC format %q", fecFormat)
+	}
+	return conf, nil
+}
+
+// withTracer sets a tracer that records the FEC statistics, and writes qlogs if enabled.
+func withTracer(conf *quic.Config, stats func(context.Context) *fecStats) *quic.Config {
+	conf = conf.Clone()
+	conf.Tracer = func(ctx context.Context, p logging.Perspective, connID quic.ConnectionID) *logging.ConnectionTracer {
+		t := stats(ctx).Tracer()
+		if useQlog {
+			return logging.NewMultiplexedConnectionTracer(t, qlog.DefaultTracer(ctx, p, connID))
+		}
+		return t
+	}
+	return conf
+}
+
+func serverTLSConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if certFile == "" && keyFile == "" {
+		log.Print("no certificate configured, using a self-signed certificate")
+		cert, err = certs.SelfSigned()
+	} else {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{alpn},
+	}, nil
+}
+
+func clientTLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: insecure,
+		NextProtos:         []string{alpn},
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+	}
+	return conf, nil
+}
+
+// openStream opens a bidirectional stream, FEC protected unless FEC is disabled.
+// Only the data sent on the stream is FEC protected, the responses of the receiver are not.
+func openStream(ctx context.Context, conn quic.Connection) (quic.Stream, error) {
+	if fecScheme == "none" {
+		return conn.OpenStreamSync(ctx)
+	}
+	return conn.OpenStreamSyncWithFEC(ctx)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+const alpn = "quic-fec-transfer"
+
+// Every request is sent on its own stream, and starts with the request type.
+const (
+	// requestPut uploads a file.
+	// The receiver responds with the offset to continue from, the sender then sends the rest of the file
+	// and closes the stream. The receiver verifies the SHA-256 hash of the file and sends a second response.
+	requestPut = 0x0
+	// requestStats asks the receiver for the FEC statistics of the connection.
+	requestStats = 0x1
+)
+
+const (
+	// statusOK is sent in response to a PUT request with the offset to continue from,
+	// and after the file was stored successfully.
+	statusOK = 0x0
+	// statusComplete is sent in response to a PUT request if the file was already received.
+	statusComplete = 0x1
+	// statusHashMismatch is sent if the received file doesn't match the hash. The partial file is deleted.
+	statusHashMismatch = 0x2
+	statusError        = 0x3
+)
+
+const (
+	maxNameLen    = 4096
+	maxMessageLen = 1024
+)
+
+var errHashMismatch = errors.New("SHA-256 mismatch")
+
+type putRequest struct {
+	Name string
+	Size uint64
+	Hash [32]byte
+}
+
+func (r *putRequest) Append(b []byte) []byte {
+	b = quicvarint.Append(b, requestPut)
+	b = appendString(b, r.Name)
+	b = quicvarint.Append(b, r.Size)
+	return append(b, r.Hash[:]...)
+}
+
+// readPutRequest reads a PUT request. The request type was already read.
+func readPutRequest(r quicvarint.Reader) (*putRequest, error) {
+	var req putRequest
+	var err error
+	if req.Name, err = readString(r, maxNameLen); err != nil {
+		return nil, err
+	}
+	if req.Size, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, req.Hash[:]); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+type response struct {
+	Status uint64
+	// Offset is the offset the sender continues from. Only set in the first response to a PUT request.
+	Offset  uint64
+	Message string
+}
+
+func (r *response) Append(b []byte) []byte {
+	b = quicvarint.Append(b, r.Status)
+	b = quicvarint.Append(b, r.Offset)
+	return appendString(b, r.Message)
+}
+
+func readResponse(r quicvarint.Reader) (*response, error) {
+	var resp response
+	var err error
+	if resp.Status, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	if resp.Offset, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	if resp.Message, err = readString(r, maxMessageLen); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// The statsResponse contains the receiver's view of the FEC statistics.
+type statsResponse struct {
+	RepairSymbolsReceived uint64
+	RecoveredSymbols      uint64
+	RecoveredBytes        uint64
+}
+
+func (r *statsResponse) Append(b []byte) []byte {
+	b = quicvarint.Append(b, r.RepairSymbolsReceived)
+	b = quicvarint.Append(b, r.RecoveredSymbols)
+	return quicvarint.Append(b, r.RecoveredBytes)
+}
+
+func readStatsResponse(r quicvarint.Reader) (*statsResponse, error) {
+	var resp statsResponse
+	var err error
+	if resp.RepairSymbolsReceived, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	if resp.RecoveredSymbols, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	if resp.RecoveredBytes, err = quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = quicvarint.Append(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func readString(r quicvarint.Reader, maxLen uint64) (string, error) {
+	l, err := quicvarint.Read(r)
+	if err != nil {
+		return "", err
+	}
+	if l > maxLen {
+		return "", fmt.Errorf("length %d exceeds the maximum of %d", l, maxLen)
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// Files are received into <name>.part. The hash and size of the file are stored in <name>.part.meta,
+// so that a transfer is only resumed if the sender sends the same file.
+const (
+	partSuffix = ".part"
+	metaSuffix = ".part.meta"
+)
+
+type receiver struct {
+	dir string
+
+	mutex    sync.Mutex
+	stats    map[uint64]*fecStats // by connection tracing ID
+	inFlight map[string]struct{}  // names of the files currently being received
+}
+
+func runReceiver(ctx context.Context, conf *quic.Config) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tlsConf, err := serverTLSConfig()
+	if err != nil {
+		return err
+	}
+	r := &receiver{
+		dir:      dir,
+		stats:    make(map[uint64]*fecStats),
+		inFlight: make(map[string]struct{}),
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		return err
+	}
+	udpConn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return err
+	}
+	tr := &quic.Transport{Conn: udpConn}
+	defer tr.Close()
+	ln, err := tr.Listen(tlsConf, withTracer(conf, r.newStats))
+	if err != nil {
+		return err
+	}
+	log.Printf("receiving files into %s on %s (FEC: %s, format: %s)", dir, ln.Addr(), fecScheme, fecFormat)
+	for {
+		conn, err := ln.Accept(ctx)
+		if err != nil {
+			return err
+		}
+		go r.handleConn(conn)
+	}
+}
+
+func (r *receiver) newStats(ctx context.Context) *fecStats {
+	s := &fecStats{}
+	if id, ok := ctx.Value(quic.ConnectionTracingKey).(uint64); ok {
+		r.mutex.Lock()
+		r.stats[id] = s
+		r.mutex.Unlock()
+	}
+	return s
+}
+
+func (r *receiver) connStats(conn quic.Connection) *fecStats {
+	id, _ := conn.Context().Value(quic.ConnectionTracingKey).(uint64)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if s, ok := r.stats[id]; ok {
+		return s
+	}
+	return &fecStats{}
+}
+
+func (r *receiver) handleConn(conn quic.Connection) {
+	log.Printf("%s connected", conn.RemoteAddr())
+	for {
+		str, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			break
+		}
+		go r.handleStream(conn, str)
+	}
+	s := r.connStats(conn)
+	log.Printf("%s disconnected: %s (recovered %d source symbols using %d repair symbols)",
+		conn.RemoteAddr(), context.Cause(conn.Context()), s.RecoveredSymbols.Load(), s.RepairSymbolsReceived.Load())
+	id, _ := conn.Context().Value(quic.ConnectionTracingKey).(uint64)
+	r.mutex.Lock()
+	delete(r.stats, id)
+	r.mutex.Unlock()
+}
+
+func (r *receiver) handleStream(conn quic.Connection, str quic.Stream) {
+	br := quicvarint.NewReader(str)
+	typ, err := quicvarint.Read(br)
+	if err != nil {
+		str.CancelRead(0)
+		str.CancelWrite(0)
+		return
+	}
+	switch typ {
+	case requestPut:
+		req, err := readPutRequest(br)
+		if err != nil {
+			str.CancelRead(0)
+			str.CancelWrite(0)
+			return
+		}
+		if err := r.handlePut(str, req); err != nil {
+			log.Printf("%s: receiving %s failed: %s", conn.RemoteAddr(), req.Name, err)
+		}
+	case requestStats:
+		s := r.connStats(conn)
+		resp := &statsResponse{
+			RepairSymbolsReceived: s.RepairSymbolsReceived.Load(),
+			RecoveredSymbols:      s.RecoveredSymbols.Load(),
+			RecoveredBytes:        s.RecoveredBytes.Load(),
+		}
+		str.Write(resp.Append(nil))
+		str.Close()
+	default:
+		str.CancelRead(0)
+		str.CancelWrite(0)
+	}
+}
+
+func (r *receiver) handlePut(str quic.Stream, req *putRequest) error {
+	path, err := r.path(req.Name)
+	if err != nil {
+		return sendError(str, err)
+	}
+	r.mutex.Lock()
+	if _, ok := r.inFlight[path]; ok {
+		r.mutex.Unlock()
+		return sendError(str, errors.New("file is already being received"))
+	}
+	r.inFlight[path] = struct{}{}
+	r.mutex.Unlock()
+	defer func() {
+		r.mutex.Lock()
+		delete(r.inFlight, path)
+		r.mutex.Unlock()
+	}()
+
+	if complete, err := alreadyReceived(path, req); err != nil {
+		return sendError(str, err)
+	} else if complete {
+		str.Write((&response{Status: statusComplete}).Append(nil))
+		str.Close()
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return sendError(str, err)
+	}
+	offset, err := resumeOffset(path, req)
+	if err != nil {
+		return sendError(str, err)
+	}
+	f, err := os.OpenFile(path+partSuffix, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return sendError(str, err)
+	}
+	defer f.Close()
+	if err := f.Truncate(int64(offset)); err != nil {
+		return sendError(str, err)
+	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		return sendError(str, err)
+	}
+	if _, err := str.Write((&response{Status: statusOK, Offset: offset}).Append(nil)); err != nil {
+		return err
+	}
+	if offset > 0 {
+		log.Printf("resuming %s at %d of %d bytes", req.Name, offset, req.Size)
+	}
+
+	// Everything received is written to the partial file, so that the transfer can be resumed
+	// if the stream is reset or the connection is lost.
+	n, err := io.Copy(f, io.LimitReader(str, int64(req.Size-offset)+1))
+	if syncErr := f.Sync(); err == nil {
+		err = syncErr
+	}
+	if err != nil {
+		str.CancelRead(0)
+		str.CancelWrite(0)
+		return err
+	}
+	if offset+uint64(n) != req.Size {
+		os.Remove(path + partSuffix)
+		os.Remove(path + metaSuffix)
+		return sendError(str, fmt.Errorf("received %d bytes, expected %d", offset+uint64(n), req.Size))
+	}
+	f.Close()
+
+	if hash, err := hashFile(path + partSuffix); err != nil {
+		return sendError(str, err)
+	} else if hash != req.Hash {
+		os.Remove(path + partSuffix)
+		os.Remove(path + metaSuffix)
+		str.Write((&response{Status: statusHashMismatch}).Append(nil))
+		str.Close()
+		return errHashMismatch
+	}
+	if err := os.Rename(path+partSuffix, path); err != nil {
+		return sendError(str, err)
+	}
+	os.Remove(path + metaSuffix)
+	str.Write((&response{Status: statusOK}).Append(nil))
+	str.Close()
+	log.Printf("received %s (%d bytes)", req.Name, req.Size)
+	return nil
+}
+
+// path returns the path of the file, making sure that it is inside the directory.
+func (r *receiver) path(name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if !filepath.IsLocal(name) || strings.HasSuffix(name, partSuffix) || strings.HasSuffix(name, metaSuffix) {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return filepath.Join(r.dir, name), nil
+}
+
+func alreadyReceived(path string, req *putRequest) (bool, error) {
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if uint64(fi.Size()) != req.Size {
+		return false, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return false, err
+	}
+	return hash == req.Hash, nil
+}
+
+// resumeOffset returns the size of the partial file, if it belongs to the same file.
+// Otherwise, it starts a new partial file.
+func resumeOffset(path string, req *putRequest) (uint64, error) {
+	meta := []byte(hex.EncodeToString(req.Hash[:]) + " " + strconv.FormatUint(req.Size, 10) + "\n")
+	if b, err := os.ReadFile(path + metaSuffix); err == nil && bytes.Equal(b, meta) {
+		if fi, err := os.Stat(path + partSuffix); err == nil {
+			return min(uint64(fi.Size()), req.Size), nil
+		}
+	}
+	if err := os.WriteFile(path+metaSuffix, meta, 0o644); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+func sendError(str quic.Stream, err error) error {
+	msg := err.Error()
+	if len(msg) > maxMessageLen {
+		msg = msg[:maxMessageLen]
+	}
+	str.Write((&response{Status: statusError, Message: msg}).Append(nil))
+	str.Close()
+	str.CancelRead(0)
+	return err
+}
+
+func hashFile(path string) ([32]byte, error) {
+	var hash [32]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return hash, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return hash, err
+	}
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+const (
+	connectTimeout = 10 * time.Second
+	minBackoff     = 100 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+type file struct {
+	Path string
+	// Name is the name the file is stored under by the receiver, using forward slashes.
+	Name string
+	Size uint64
+
+	sent atomic.Uint64 // bytes sent or skipped, since the receiver already had them
+	err  error
+}
+
+type sender struct {
+	tr      *quic.Transport
+	tlsConf *tls.Config
+	conf    *quic.Config
+	stats   fecStats
+
+	mutex   sync.Mutex
+	conn    quic.Connection
+	backoff time.Duration
+
+	transferred atomic.Uint64 // bytes written to streams
+	resumed     atomic.Uint64 // bytes of partially received files that didn't need to be sent again
+	skipped     atomic.Uint64 // bytes of files the receiver already had
+	done        atomic.Int64  // number of files done
+}
+
+func runSender(ctx context.Context, conf *quic.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no files to send")
+	}
+	files, err := findFiles(args)
+	if err != nil {
+		return err
+	}
+	tlsConf, err := clientTLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConf.ServerName == "" {
+		host, _, err := net.SplitHostPort(serverAddr)
+		if err != nil {
+			return err
+		}
+		tlsConf.ServerName = host
+	}
+	udpConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return err
+	}
+	s := &sender{
+		tr:      &quic.Transport{Conn: udpConn},
+		tlsConf: tlsConf,
+	}
+	s.conf = withTracer(conf, func(context.Context) *fecStats { return &s.stats })
+	defer s.tr.Close()
+
+	start := time.Now()
+	stopProgress := s.reportProgress(files)
+	queue := make(chan *file)
+	var wg sync.WaitGroup
+	for i := 0; i < max(parallel, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				f.err = s.send(ctx, f)
+				if f.err != nil {
+					log.Printf("sending %s failed: %s", f.Path, f.err)
+				}
+				s.done.Add(1)
+			}
+		}()
+	}
+	for _, f := range files {
+		queue <- f
+	}
+	close(queue)
+	wg.Wait()
+	stopProgress()
+	return s.printSummary(ctx, files, time.Since(start))
+}
+
+// findFiles returns the files to send. Directories are sent recursively.
+func findFiles(args []string) ([]*file, error) {
+	var files []*file
+	for _, arg := range args {
+		arg = filepath.Clean(arg)
+		base := filepath.Dir(arg)
+		err := filepath.WalkDir(arg, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(base, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, &file{Path: p, Name: path.Clean(filepath.ToSlash(name)), Size: uint64(info.Size())})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// connection returns the current connection, (re-)connecting with exponential backoff if necessary.
+func (s *sender) connection(ctx context.Context) (quic.Connection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn != nil {
+		if s.conn.Context().Err() == nil {
+			return s.conn, nil
+		}
+		log.Printf("connection to %s lost: %s", serverAddr, context.Cause(s.conn.Context()))
+		s.conn = nil
+	}
+	for {
+		if s.backoff > 0 {
+			select {
+			case <-time.After(s.backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		conn, err := s.dial(ctx)
+		if err == nil {
+			log.Printf("connected to %s (FEC: %s, format: %s)", conn.RemoteAddr(), fecScheme, fecFormat)
+			s.conn = conn
+			s.backoff = 0
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.backoff = min(max(2*s.backoff, minBackoff), maxBackoff)
+		log.Printf("connecting to %s failed: %s, retrying in %s", serverAddr, err, s.backoff)
+	}
+}
+
+func (s *sender) dial(ctx context.Context) (quic.Connection, error) {
+	addr, err := net.ResolveUDPAddr("udp", serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	return s.tr.Dial(ctx, addr, s.tlsConf, s.conf)
+}
+
+// send sends a file, resuming the transfer after a connection loss and retrying it if the hash doesn't match.
+func (s *sender) send(ctx context.Context, f *file) error {
+	hash, err := hashFile(f.Path)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		conn, err := s.connection(ctx)
+		if err != nil {
+			return err
+		}
+		err = s.sendOnce(ctx, conn, f, hash)
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		if !errors.Is(err, errHashMismatch) && conn.Context().Err() == nil {
+			return err
+		}
+		log.Printf("sending %s failed: %s, retrying (attempt %d of %d)", f.Path, err, attempt+1, attempts)
+	}
+}
+
+func (s *sender) sendOnce(ctx context.Context, conn quic.Connection, f *file, hash [32]byte) error {
+	str, err := openStream(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer str.CancelRead(0)
+	if _, err := str.Write((&putRequest{Name: f.Name, Size: f.Size, Hash: hash}).Append(nil)); err != nil {
+		return err
+	}
+	br := quicvarint.NewReader(str)
+	resp, err := readResponse(br)
+	if err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	switch resp.Status {
+	case statusOK:
+	case statusComplete:
+		f.sent.Store(f.Size)
+		s.skipped.Add(f.Size)
+		return str.Close()
+	case statusError:
+		str.CancelWrite(0)
+		return errors.New(resp.Message)
+	default:
+		str.CancelWrite(0)
+		return fmt.Errorf("unexpected status %d", resp.Status)
+	}
+	if resp.Offset > f.Size {
+		str.CancelWrite(0)
+		return fmt.Errorf("invalid offset %d", resp.Offset)
+	}
+
+	fd, err := os.Open(f.Path)
+	if err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	defer fd.Close()
+	if _, err := fd.Seek(int64(resp.Offset), io.SeekStart); err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	f.sent.Store(resp.Offset)
+	s.resumed.Add(resp.Offset)
+	w := &progressWriter{w: str, f: f, transferred: &s.transferred}
+	if _, err := io.Copy(w, io.LimitReader(fd, int64(f.Size-resp.Offset))); err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	if err := str.Close(); err != nil {
+		return err
+	}
+	if resp, err = readResponse(br); err != nil {
+		return err
+	}
+	switch resp.Status {
+	case statusOK:
+		return nil
+	case statusHashMismatch:
+		f.sent.Store(0)
+		return errHashMismatch
+	case statusError:
+		return errors.New(resp.Message)
+	default:
+		return fmt.Errorf("unexpected status %d", resp.Status)
+	}
+}
+
+type progressWriter struct {
+	w           io.Writer
+	f           *file
+	transferred *atomic.Uint64
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.f.sent.Add(uint64(n))
+	w.transferred.Add(uint64(n))
+	return n, err
+}
+
+// reportProgress periodically prints the progress. It returns a function that stops the report.
+func (s *sender) reportProgress(files []*file) func() {
+	if progress <= 0 {
+		return func() {}
+	}
+	var total uint64
+	for _, f := range files {
+		total += f.Size
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progress)
+		defer ticker.Stop()
+		var lastTransferred uint64
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			var sent uint64
+			for _, f := range files {
+				sent += f.sent.Load()
+			}
+			transferred := s.transferred.Load()
+			rate := float64(transferred-lastTransferred) / progress.Seconds()
+			lastTransferred = transferred
+			eta := "-"
+			if rate > 0 {
+				eta = (time.Duration(float64(total-min(sent, total))/rate) * time.Second).Round(time.Second).String()
+			}
+			log.Printf("%s / %s (%s), %d / %d files, %s/s, ETA %s",
+				formatBytes(sent), formatBytes(total), percent(sent, total), s.done.Load(), len(files), formatBytes(uint64(rate)), eta)
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *sender) printSummary(ctx context.Context, files []*file, duration time.Duration) error {
+	var numFailed int
+	var total uint64
+	for _, f := range files {
+		if f.err != nil {
+			numFailed++
+		} else {
+			total += f.Size
+		}
+	}
+	transferred := s.transferred.Load()
+	out := os.Stdout
+	fmt.Fprintf(out, "\nSent %d of %d files (%s) in %s\n", len(files)-numFailed, len(files), formatBytes(total), duration.Round(time.Millisecond))
+	fmt.Fprintf(out, "  transferred:              %s (%s/s)\n", formatBytes(transferred), formatBytes(uint64(float64(transferred)/duration.Seconds())))
+	fmt.Fprintf(out, "  resumed:                  %s\n", formatBytes(s.resumed.Load()))
+	fmt.Fprintf(out, "  already received:         %s\n", formatBytes(s.skipped.Load()))
+	for _, f := range files {
+		if f.err != nil {
+			fmt.Fprintf(out, "  failed:                   %s: %s\n", f.Path, f.err)
+		}
+	}
+
+	// The receiver's statistics and the loss estimate are only available for the current connection.
+	var peer *statsResponse
+	var estimate *quic.LossEstimate
+	s.mutex.Lock()
+	conn := s.conn
+	s.mutex.Unlock()
+	if conn != nil && conn.Context().Err() == nil {
+		peer = s.peerStats(ctx, conn)
+		e := conn.LossEstimate()
+		estimate = &e
+		conn.CloseWithError(0, "")
+	}
+	s.stats.printSummary(out, peer, estimate)
+	if numFailed > 0 {
+		return fmt.Errorf("%d files failed", numFailed)
+	}
+	return nil
+}
+
+func (s *sender) peerStats(ctx context.Context, conn quic.Connection) *statsResponse {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	str, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil
+	}
+	str.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := str.Write(quicvarint.Append(nil, requestStats)); err != nil {
+		return nil
+	}
+	str.Close()
+	resp, err := readStatsResponse(quicvarint.NewReader(str))
+	if err != nil {
+		return nil
+	}
+	return resp
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go/logging"
+)
+
+// fecStats counts the FEC related events of one or more connections.
+type fecStats struct {
+	PacketsSent           atomic.Uint64
+	PacketsLost           atomic.Uint64
+	SourceSymbolsSent     atomic.Uint64
+	SourceBytesSent       atomic.Uint64
+	RepairSymbolsSent     atomic.Uint64
+	RepairBytesSent       atomic.Uint64
+	RepairSymbolsReceived atomic.Uint64
+	RecoveredSymbols      atomic.Uint64
+	RecoveredBytes        atomic.Uint64
+}
+
+func (s *fecStats) Tracer() *logging.ConnectionTracer {
+	return &logging.ConnectionTracer{
+		SentLongHeaderPacket: func(_ *logging.ExtendedHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, frames []logging.Frame) {
+			s.sentPacket(frames)
+		},
+		SentShortHeaderPacket: func(_ *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, frames []logging.Frame) {
+			s.sentPacket(frames)
+		},
+		ReceivedLongHeaderPacket: func(_ *logging.ExtendedHeader, _ logging.ByteCount, _ logging.ECN, frames []logging.Frame) {
+			s.receivedPacket(frames)
+		},
+		ReceivedShortHeaderPacket: func(_ *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, frames []logging.Frame) {
+			s.receivedPacket(frames)
+		},
+		LostPacket: func(logging.EncryptionLevel, logging.PacketNumber, logging.PacketLossReason) {
+			s.PacketsLost.Add(1)
+		},
+		RecoveredSourceSymbols: func(_ logging.BlockID, length logging.ByteCount) {
+			s.RecoveredSymbols.Add(1)
+			s.RecoveredBytes.Add(uint64(length))
+		},
+	}
+}
+
+func (s *fecStats) sentPacket(frames []logging.Frame) {
+	s.PacketsSent.Add(1)
+	for _, f := range frames {
+		switch f := f.(type) {
+		case *logging.SourceSymbolFrame:
+			s.SourceSymbolsSent.Add(1)
+			s.SourceBytesSent.Add(uint64(f.Length))
+		case *logging.RepairFrame:
+			s.RepairSymbolsSent.Add(1)
+			s.RepairBytesSent.Add(uint64(f.Length))
+		}
+	}
+}
+
+func (s *fecStats) receivedPacket(frames []logging.Frame) {
+	for _, f := range frames {
+		if _, ok := f.(*logging.RepairFrame); ok {
+			s.RepairSymbolsReceived.Add(1)
+		}
+	}
+}
+
+// printSummary prints the sender's FEC statistics,
+// together with the receiver's statistics and the loss estimate, if available.
+func (s *fecStats) printSummary(w io.Writer, peer *statsResponse, estimate *logging.LossEstimate) {
+	fmt.Fprintf(w, "FEC (scheme: %s, format: %s):\n", fecScheme, fecFormat)
+	sent, lost := s.PacketsSent.Load(), s.PacketsLost.Load()
+	fmt.Fprintf(w, "  packets sent:             %d, lost: %d (%s)\n", sent, lost, percent(lost, sent))
+	sourceBytes, repairBytes := s.SourceBytesSent.Load(), s.RepairBytesSent.Load()
+	fmt.Fprintf(w, "  source symbols sent:      %d (%s)\n", s.SourceSymbolsSent.Load(), formatBytes(sourceBytes))
+	fmt.Fprintf(w, "  repair symbols sent:      %d (%s, %s overhead)\n", s.RepairSymbolsSent.Load(), formatBytes(repairBytes), percent(repairBytes, sourceBytes))
+	if peer != nil {
+		fmt.Fprintf(w, "  repair symbols received:  %d (by the receiver)\n", peer.RepairSymbolsReceived)
+		fmt.Fprintf(w, "  recovered source symbols: %d (%s, by the receiver)\n", peer.RecoveredSymbols, formatBytes(peer.RecoveredBytes))
+	}
+	if estimate != nil && estimate.NumPackets > 0 {
+		fmt.Fprintf(w, "  loss estimate:            p=%.4f r=%.4f h=%.4f k=%.4f (%d packets, %d lost)\n",
+			estimate.P, estimate.R, estimate.H, estimate.K, estimate.NumPackets, estimate.NumLost)
+	}
+}
+
+func percent(a, b uint64) string {
+	if b == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", 100*float64(a)/float64(b))
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
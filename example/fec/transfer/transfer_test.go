@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransfer(t *testing.T) {
+	for _, scheme := range []string{"none", "xor", "rs"} {
+		t.Run(scheme, func(t *testing.T) {
+			testTransfer(t, scheme)
+		})
+	}
+}
+
+func testTransfer(t *testing.T, scheme string) {
+	src := filepath.Join(t.TempDir(), "files")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	contents := map[string][]byte{
+		"files/a":     make([]byte, 200000),
+		"files/sub/b": make([]byte, 1000),
+	}
+	for name, data := range contents {
+		rng.Read(data)
+		if err := os.WriteFile(filepath.Join(filepath.Dir(src), filepath.FromSlash(name)), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenAddr = c.LocalAddr().String()
+	c.Close()
+	fecScheme = scheme
+	fecFormat = "legacy"
+	dir = t.TempDir()
+	serverAddr = listenAddr
+	insecure = true
+	parallel = 2
+	attempts = 3
+	progress = 0
+
+	conf, err := quicConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	receiverErr := make(chan error, 1)
+	go func() { receiverErr <- runReceiver(ctx, conf) }()
+	defer func() {
+		cancel()
+		select {
+		case <-receiverErr:
+		case <-time.After(5 * time.Second):
+			t.Error("timeout waiting for the receiver to shut down")
+		}
+	}()
+
+	// the sender retries if the receiver is not listening yet
+	sendCtx, sendCancel := context.WithTimeout(ctx, 20*time.Second)
+	defer sendCancel()
+	if err := runSender(sendCtx, conf, []string{src}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range contents {
+		received, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(received, data) {
+			t.Fatalf("%s was corrupted", name)
+		}
+	}
+}
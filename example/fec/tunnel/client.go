@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// connectTimeout is the time a new TCP connection waits for the tunnel to be (re-)established
+	// and for the server to connect to the target.
+	connectTimeout = 10 * time.Second
+	minBackoff     = 100 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+	// udpIdleTimeout is the time after which a UDP flow without any traffic is closed.
+	udpIdleTimeout = time.Minute
+)
+
+func runClient(ctx context.Context, conf *quic.Config) error {
+	if len(tcpForwards) == 0 && len(udpForwards) == 0 {
+		return errors.New("nothing to forward, set -tcp or -udp")
+	}
+	tlsConf, err := clientTLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConf.ServerName == "" {
+		host, _, err := net.SplitHostPort(serverAddr)
+		if err != nil {
+			return err
+		}
+		tlsConf.ServerName = host
+	}
+	udpConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return err
+	}
+	c := &client{
+		tr:          &quic.Transport{Conn: udpConn},
+		tlsConf:     tlsConf,
+		conf:        conf,
+		connChanged: make(chan struct{}),
+	}
+	defer c.tr.Close()
+
+	for _, f := range tcpForwards {
+		ln, err := net.Listen("tcp", f.Local)
+		if err != nil {
+			return err
+		}
+		defer ln.Close()
+		log.Printf("forwarding TCP %s to %s", ln.Addr(), f.Target)
+		go c.serveTCP(ln.(*net.TCPListener), f.Target)
+	}
+	for _, f := range udpForwards {
+		addr, err := net.ResolveUDPAddr("udp", f.Local)
+		if err != nil {
+			return err
+		}
+		pc, err := net.ListenUDP("udp", addr)
+		if err != nil {
+			return err
+		}
+		defer pc.Close()
+		log.Printf("forwarding UDP %s to %s", pc.LocalAddr(), f.Target)
+		u := &udpForwarder{
+			client: c,
+			pc:     pc,
+			target: f.Target,
+			flows:  make(map[string]*udpFlow),
+		}
+		go u.run()
+	}
+	return c.run(ctx)
+}
+
+// The client maintains the connection to the server, reconnecting with exponential backoff when it is lost.
+type client struct {
+	tr      *quic.Transport
+	tlsConf *tls.Config
+	conf    *quic.Config
+
+	mutex       sync.Mutex
+	conn        *clientConn   // nil while not connected
+	connChanged chan struct{} // closed when conn changes
+}
+
+func (c *client) run(ctx context.Context) error {
+	backoff := minBackoff
+	for {
+		conn, err := c.dial(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("connecting to %s failed: %s, retrying in %s", serverAddr, err, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff = min(2*backoff, maxBackoff)
+			continue
+		}
+		backoff = minBackoff
+		log.Printf("connected to %s (FEC: %s, format: %s)", conn.RemoteAddr(), fecScheme, fecFormat)
+		cc := &clientConn{
+			conn:          conn,
+			returnStreams: make(map[uint64]chan quic.ReceiveStream),
+			flows:         make(map[uint64]*udpFlow),
+		}
+		go cc.run()
+		c.setConn(cc)
+		select {
+		case <-conn.Context().Done():
+			c.setConn(nil)
+			log.Printf("connection to %s lost: %s", serverAddr, context.Cause(conn.Context()))
+		case <-ctx.Done():
+			c.setConn(nil)
+			conn.CloseWithError(errorCodeNoError, "")
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *client) dial(ctx context.Context) (quic.Connection, error) {
+	addr, err := net.ResolveUDPAddr("udp", serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	return c.tr.Dial(ctx, addr, c.tlsConf, c.conf)
+}
+
+func (c *client) setConn(cc *clientConn) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.conn = cc
+	close(c.connChanged)
+	c.connChanged = make(chan struct{})
+}
+
+// currentConn returns the current connection, or nil if the client is not connected.
+func (c *client) currentConn() *clientConn {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn
+}
+
+// waitForConn waits until the client is connected.
+func (c *client) waitForConn(ctx context.Context) (*clientConn, error) {
+	for {
+		c.mutex.Lock()
+		cc, changed := c.conn, c.connChanged
+		c.mutex.Unlock()
+		if cc != nil && cc.conn.Context().Err() == nil {
+			return cc, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *client) serveTCP(ln *net.TCPListener, target string) {
+	for {
+		conn, err := ln.AcceptTCP()
+		if err != nil {
+			return
+		}
+		go c.handleTCP(conn, target)
+	}
+}
+
+func (c *client) handleTCP(local *net.TCPConn, target string) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	var id uint64
+	var send quic.SendStream
+	var recv quic.ReceiveStream
+	for {
+		cc, err := c.waitForConn(ctx)
+		if err != nil {
+			log.Printf("dropping TCP connection from %s: not connected to %s", local.RemoteAddr(), serverAddr)
+			local.Close()
+			return
+		}
+		id, send, recv, err = cc.openTCPConn(ctx, target)
+		if err == nil {
+			break
+		}
+		// No data was sent yet, so the TCP connection can be moved to a new QUIC connection
+		// if the QUIC connection was lost in the meantime.
+		if cc.conn.Context().Err() == nil || ctx.Err() != nil {
+			log.Printf("forwarding TCP connection from %s to %s failed: %s", local.RemoteAddr(), target, err)
+			local.Close()
+			return
+		}
+	}
+	if verbose {
+		log.Printf("forwarding TCP connection %d from %s to %s", id, local.RemoteAddr(), target)
+	}
+	proxy(local, send, recv)
+	if verbose {
+		log.Printf("TCP connection %d closed", id)
+	}
+}
+
+// A clientConn is a single connection to the server.
+// TCP connections and UDP flows are bound to the connection they were created on.
+type clientConn struct {
+	conn quic.Connection
+
+	mutex         sync.Mutex
+	nextID        uint64
+	returnStreams map[uint64]chan quic.ReceiveStream // TCP connections waiting for their return stream
+	flows         map[uint64]*udpFlow
+}
+
+func (c *clientConn) run() {
+	go c.receiveDatagrams()
+	for {
+		str, err := c.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			return
+		}
+		go c.handleReturnStream(str)
+	}
+}
+
+func (c *clientConn) newTCPConn() (uint64, <-chan quic.ReceiveStream) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	id := c.nextID
+	c.nextID++
+	ch := make(chan quic.ReceiveStream, 1)
+	c.returnStreams[id] = ch
+	return id, ch
+}
+
+func (c *clientConn) removeTCPConn(id uint64) {
+	c.mutex.Lock()
+	delete(c.returnStreams, id)
+	c.mutex.Unlock()
+}
+
+// openTCPConn asks the server to connect to the target, and waits for the return stream.
+func (c *clientConn) openTCPConn(ctx context.Context, target string) (uint64, quic.SendStream, quic.ReceiveStream, error) {
+	id, returnStream := c.newTCPConn()
+	defer c.removeTCPConn(id)
+	send, err := openStream(ctx, c.conn)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if _, err := send.Write((&header{Type: streamTypeTCP, ID: id, Target: target}).Append(nil)); err != nil {
+		send.CancelWrite(errorCodeAborted)
+		return 0, nil, nil, err
+	}
+	select {
+	case recv := <-returnStream:
+		return id, send, recv, nil
+	case <-send.Context().Done():
+		// the server couldn't connect to the target, or the connection was lost
+		return 0, nil, nil, context.Cause(send.Context())
+	case <-ctx.Done():
+		send.CancelWrite(errorCodeAborted)
+		return 0, nil, nil, ctx.Err()
+	}
+}
+
+func (c *clientConn) handleReturnStream(str quic.ReceiveStream) {
+	h, err := readHeader(str)
+	if err != nil || h.Type != streamTypeTCPReturn {
+		str.CancelRead(errorCodeAborted)
+		return
+	}
+	c.mutex.Lock()
+	ch, ok := c.returnStreams[h.ID]
+	delete(c.returnStreams, h.ID)
+	c.mutex.Unlock()
+	if !ok {
+		// the TCP connection already timed out
+		str.CancelRead(errorCodeAborted)
+		return
+	}
+	ch <- str
+}
+
+func (c *clientConn) addFlow(f *udpFlow) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	id := c.nextID
+	c.nextID++
+	c.flows[id] = f
+	return id
+}
+
+func (c *clientConn) removeFlow(id uint64) {
+	c.mutex.Lock()
+	delete(c.flows, id)
+	c.mutex.Unlock()
+}
+
+func (c *clientConn) receiveDatagrams() {
+	for {
+		b, err := c.conn.ReceiveDatagram(context.Background())
+		if err != nil {
+			return
+		}
+		flowID, payload, err := parseDatagram(b)
+		if err != nil {
+			continue
+		}
+		c.mutex.Lock()
+		f, ok := c.flows[flowID]
+		c.mutex.Unlock()
+		if ok {
+			f.lastActive.Store(time.Now().UnixNano())
+			f.forwarder.pc.WriteToUDP(payload, f.peer)
+		}
+	}
+}
+
+// A udpFlow is the traffic between a local UDP peer and the target.
+type udpFlow struct {
+	forwarder  *udpForwarder
+	peer       *net.UDPAddr
+	cc         *clientConn
+	id         uint64
+	stream     quic.SendStream // nil while the stream is being opened, protected by the forwarder's mutex
+	lastActive atomic.Int64
+}
+
+// The udpForwarder forwards the datagrams received on a local UDP socket.
+// Every local peer is assigned its own flow.
+type udpForwarder struct {
+	client *client
+	pc     *net.UDPConn
+	target string
+
+	mutex sync.Mutex
+	flows map[string]*udpFlow // by the address of the local peer
+}
+
+func (u *udpForwarder) run() {
+	go u.expireFlows()
+	b := make([]byte, maxPayloadLen)
+	for {
+		n, peer, err := u.pc.ReadFromUDP(b)
+		if err != nil {
+			return
+		}
+		u.handlePacket(peer, b[:n])
+	}
+}
+
+func (u *udpForwarder) handlePacket(peer *net.UDPAddr, data []byte) {
+	key := peer.String()
+	u.mutex.Lock()
+	f, ok := u.flows[key]
+	if ok && f.cc.conn.Context().Err() != nil {
+		// The connection was lost. Start a new flow on the current connection.
+		delete(u.flows, key)
+		ok = false
+	}
+	opening := ok && f.stream == nil
+	u.mutex.Unlock()
+	if opening {
+		// The server doesn't know the flow yet. UDP applications retransmit on their own.
+		if verbose {
+			log.Printf("dropping UDP datagram from %s: flow %d not established yet", peer, f.id)
+		}
+		return
+	}
+	if ok {
+		f.lastActive.Store(time.Now().UnixNano())
+		sendDatagram(f.cc.conn, f.id, data)
+		return
+	}
+
+	// Don't block the socket while reconnecting, UDP applications retransmit on their own.
+	cc := u.client.currentConn()
+	if cc == nil {
+		if verbose {
+			log.Printf("dropping UDP datagram from %s: not connected to %s", peer, serverAddr)
+		}
+		return
+	}
+	f = &udpFlow{forwarder: u, peer: peer, cc: cc}
+	f.lastActive.Store(time.Now().UnixNano())
+	f.id = cc.addFlow(f)
+	u.mutex.Lock()
+	u.flows[key] = f
+	u.mutex.Unlock()
+	// Opening the stream blocks if the server's stream limit is reached.
+	// Don't block the socket in the meantime, the datagrams of the other flows still need to be forwarded.
+	go u.openFlow(key, f, bytes.Clone(data))
+}
+
+// openFlow opens the stream of a new flow, and sends the first datagram on it.
+func (u *udpForwarder) openFlow(key string, f *udpFlow, data []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	str, err := openStream(ctx, f.cc.conn)
+	if err == nil {
+		if _, err = str.Write((&header{Type: streamTypeUDP, ID: f.id, Target: u.target, Payload: data}).Append(nil)); err != nil {
+			str.CancelWrite(errorCodeAborted)
+		}
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if err != nil {
+		f.cc.removeFlow(f.id)
+		if u.flows[key] == f {
+			delete(u.flows, key)
+		}
+		return
+	}
+	if u.flows[key] != f {
+		// the flow expired while the stream was being opened
+		str.Close()
+		return
+	}
+	f.stream = str
+	if verbose {
+		log.Printf("forwarding UDP flow %d from %s to %s", f.id, f.peer, u.target)
+	}
+}
+
+func (u *udpForwarder) expireFlows() {
+	ticker := time.NewTicker(udpIdleTimeout / 4)
+	defer ticker.Stop()
+	for range ticker.C {
+		u.mutex.Lock()
+		for key, f := range u.flows {
+			if time.Since(time.Unix(0, f.lastActive.Load())) < udpIdleTimeout && f.cc.conn.Context().Err() == nil {
+				continue
+			}
+			delete(u.flows, key)
+			f.cc.removeFlow(f.id)
+			if f.stream != nil {
+				f.stream.Close()
+			}
+			if verbose {
+				log.Printf("UDP flow %d closed", f.id)
+			}
+		}
+		u.mutex.Unlock()
+	}
+}
@@ -0,0 +1,193 @@
+// Command tunnel forwards local TCP connections and UDP datagrams over a FEC protected QUIC connection.
+//
+// The client listens on local TCP and UDP ports and forwards everything it receives to the server,
+// which connects to the configured targets. TCP connections are carried on FEC protected streams,
+// UDP datagrams in FEC protected QUIC datagrams. Applications don't need to know anything about QUIC.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/example/fec/internal/certs"
+	"github.com/quic-go/quic-go/fec"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+var (
+	mode      string
+	fecScheme string
+	fecFormat string
+	useQlog   bool
+	verbose   bool
+
+	// server
+	listenAddr string
+	certFile   string
+	keyFile    string
+	allow      string
+	resetKey   string
+
+	// client
+	serverAddr  string
+	tcpForwards forwardList
+	udpForwards forwardList
+	caFile      string
+	serverName  string
+	insecure    bool
+)
+
+func init() {
+	flag.StringVar(&mode, "mode", "client", "client or server")
+	flag.StringVar(&fecScheme, "fec", "rs", "FEC scheme: none, xor or rs")
+	flag.StringVar(&fecFormat, "fec-format", "legacy", "encoding of the FEC frames: legacy or draft")
+	flag.BoolVar(&useQlog, "qlog", false, "write qlog files to the directory set in the QLOGDIR environment variable")
+	flag.BoolVar(&verbose, "v", false, "log every forwarded connection and flow")
+
+	flag.StringVar(&listenAddr, "listen", "0.0.0.0:4242", "server: address to listen on")
+	flag.StringVar(&certFile, "cert", "", "server: certificate file (PEM). A self-signed certificate is generated if not set")
+	flag.StringVar(&keyFile, "key", "", "server: private key file (PEM)")
+	flag.StringVar(&resetKey, "reset-key", "", "server: file containing a 32 byte stateless reset key. Clients of a restarted server then reconnect immediately")
+	flag.StringVar(&allow, "allow", "", "server: comma-separated list of targets clients may connect to, * allows all targets")
+
+	flag.StringVar(&serverAddr, "server", "localhost:4242", "client: address of the tunnel server")
+	flag.Var(&tcpForwards, "tcp", "client: forward a local TCP port to a target, as local=target, e.g. 127.0.0.1:8080=10.0.0.1:80 (repeatable)")
+	flag.Var(&udpForwards, "udp", "client: forward a local UDP port to a target, as local=target, e.g. 127.0.0.1:5353=10.0.0.1:53 (repeatable)")
+	flag.StringVar(&caFile, "ca", "", "client: CA certificate file (PEM) used to verify the server certificate")
+	flag.StringVar(&serverName, "sni", "", "client: server name used to verify the server certificate, defaults to the host of -server")
+	flag.BoolVar(&insecure, "insecure", false, "client: don't verify the server certificate")
+}
+
+type forward struct {
+	Local, Target string
+}
+
+type forwardList []forward
+
+func (l *forwardList) String() string {
+	s := make([]string, 0, len(*l))
+	for _, f := range *l {
+		s = append(s, f.Local+"="+f.Target)
+	}
+	return strings.Join(s, ",")
+}
+
+func (l *forwardList) Set(v string) error {
+	local, target, ok := strings.Cut(v, "=")
+	if !ok || local == "" || target == "" {
+		return fmt.Errorf("invalid forward %q, expected local=target", v)
+	}
+	*l = append(*l, forward{Local: local, Target: target})
+	return nil
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	conf, err := quicConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch mode {
+	case "server":
+		err = runServer(ctx, conf)
+	case "client":
+		err = runClient(ctx, conf)
+	default:
+		err = fmt.Errorf("invalid mode %q", mode)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
+
+func quicConfig() (*quic.Config, error) {
+	conf := &quic.Config{
+		EnableDatagrams:       true,
+		MaxIdleTimeout:        30 * time.Second,
+		KeepAlivePeriod:       5 * time.Second,
+		MaxIncomingStreams:    1000,
+		MaxIncomingUniStreams: 1000,
+	}
+	switch fecScheme {
+	case "none":
+	case "xor":
+		conf.EnableFEC = true
+		conf.DecoderFECScheme = fec.XOR
+	case "rs":
+		conf.EnableFEC = true
+		conf.DecoderFECScheme = fec.ReedSolomon
+	default:
+		return nil, fmt.Errorf("invalid FEC scheme %q", fecScheme)
+	}
+	switch fecFormat {
+	case "legacy":
+		conf.FECWireFormat = quic.FECWireFormatLegacy
+	case "draft":
+		conf.FECWireFormat = quic.FECWireFormatDraft
+	default:
+		return nil, fmt.Errorf("invalid FEC format %q", fecFormat)
+	}
+	if useQlog {
+		conf.Tracer = qlog.DefaultTracer
+	}
+	return conf, nil
+}
+
+func serverTLSConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if certFile == "" && keyFile == "" {
+		log.Print("no certificate configured, using a self-signed certificate")
+		cert, err = certs.SelfSigned()
+	} else {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{alpn},
+	}, nil
+}
+
+func clientTLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: insecure,
+		NextProtos:         []string{alpn},
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+	}
+	return conf, nil
+}
+
+// openStream opens a unidirectional stream, FEC protected unless FEC is disabled.
+func openStream(ctx context.Context, conn quic.Connection) (quic.SendStream, error) {
+	if fecScheme == "none" {
+		return conn.OpenUniStreamSync(ctx)
+	}
+	return conn.OpenUniStreamSyncWithFEC(ctx)
+}
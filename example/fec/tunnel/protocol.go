@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// Every unidirectional stream starts with a header.
+// Streams are unidirectional, since the data of an incoming stream is never FEC protected.
+// Both directions of a tunneled TCP connection therefore use their own FEC protected stream.
+const (
+	// streamTypeTCP is opened by the client for every accepted TCP connection.
+	// The server connects to the target and sends the data of the stream to it.
+	streamTypeTCP = 0x0
+	// streamTypeTCPReturn is opened by the server in response to a streamTypeTCP stream.
+	// It carries the data the target sends back to the client.
+	streamTypeTCPReturn = 0x1
+	// streamTypeUDP is opened by the client for every UDP flow and stays open as long as the flow is active.
+	// The header contains the first datagram of the flow, all other datagrams are sent in QUIC datagrams.
+	streamTypeUDP = 0x2
+)
+
+const (
+	errorCodeNoError    quic.ApplicationErrorCode = 0x0
+	errorCodeAborted    quic.StreamErrorCode      = 0x1
+	errorCodeDialFailed quic.StreamErrorCode      = 0x2
+	errorCodeForbidden  quic.StreamErrorCode      = 0x3
+)
+
+const (
+	maxTargetLen  = 1024
+	maxPayloadLen = 1 << 16
+)
+
+const alpn = "quic-fec-tunnel"
+
+type header struct {
+	Type uint64
+	// ID identifies a TCP connection or a UDP flow.
+	ID uint64
+	// Target is the address the server connects to. Not set for streamTypeTCPReturn.
+	Target string
+	// Payload is the first datagram of a UDP flow. Only set for streamTypeUDP.
+	Payload []byte
+}
+
+func (h *header) Append(b []byte) []byte {
+	b = quicvarint.Append(b, h.Type)
+	b = quicvarint.Append(b, h.ID)
+	if h.Type == streamTypeTCPReturn {
+		return b
+	}
+	b = quicvarint.Append(b, uint64(len(h.Target)))
+	b = append(b, h.Target...)
+	if h.Type == streamTypeUDP {
+		b = quicvarint.Append(b, uint64(len(h.Payload)))
+		b = append(b, h.Payload...)
+	}
+	return b
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	br := quicvarint.NewReader(r)
+	var h header
+	var err error
+	if h.Type, err = quicvarint.Read(br); err != nil {
+		return nil, err
+	}
+	if h.ID, err = quicvarint.Read(br); err != nil {
+		return nil, err
+	}
+	switch h.Type {
+	case streamTypeTCPReturn:
+		return &h, nil
+	case streamTypeTCP, streamTypeUDP:
+	default:
+		return nil, fmt.Errorf("unknown stream type %d", h.Type)
+	}
+	target, err := readBytes(br, maxTargetLen)
+	if err != nil {
+		return nil, err
+	}
+	h.Target = string(target)
+	if h.Type == streamTypeUDP {
+		if h.Payload, err = readBytes(br, maxPayloadLen); err != nil {
+			return nil, err
+		}
+	}
+	return &h, nil
+}
+
+func readBytes(r quicvarint.Reader, maxLen uint64) ([]byte, error) {
+	l, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > maxLen {
+		return nil, fmt.Errorf("length %d exceeds the maximum of %d", l, maxLen)
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// appendDatagram prepends the flow ID to the payload of a UDP datagram.
+func appendDatagram(b []byte, flowID uint64, payload []byte) []byte {
+	b = quicvarint.Append(b, flowID)
+	return append(b, payload...)
+}
+
+func parseDatagram(b []byte) (uint64, []byte, error) {
+	r := bytes.NewReader(b)
+	flowID, err := quicvarint.Read(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return flowID, b[len(b)-r.Len():], nil
+}
+
+// sendDatagram sends a UDP datagram of a flow, FEC protected unless FEC is disabled.
+// Datagrams that are too large to be sent in a single QUIC packet are dropped, just like on a real network.
+func sendDatagram(conn quic.Connection, flowID uint64, payload []byte) error {
+	var err error
+	if fecScheme == "none" {
+		err = conn.SendDatagram(appendDatagram(nil, flowID, payload))
+	} else {
+		err = conn.SendDatagramWithFEC(appendDatagram(nil, flowID, payload))
+	}
+	var tooLarge *quic.DatagramTooLargeError
+	if errors.As(err, &tooLarge) {
+		if verbose {
+			log.Printf("dropping UDP datagram of flow %d: %s", flowID, err)
+		}
+		return nil
+	}
+	return err
+}
+
+// proxy copies data between a TCP connection and a pair of QUIC streams, until both directions are closed.
+func proxy(conn *net.TCPConn, send quic.SendStream, recv quic.ReceiveStream) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := io.Copy(send, conn); err != nil {
+			send.CancelWrite(errorCodeAborted)
+			return
+		}
+		send.Close()
+	}()
+	if _, err := io.Copy(conn, recv); err != nil {
+		recv.CancelRead(errorCodeAborted)
+		// unblock the goroutine reading from the TCP connection
+		conn.Close()
+	} else {
+		conn.CloseWrite()
+	}
+	<-done
+	conn.Close()
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+const dialTimeout = 10 * time.Second
+
+// allowList contains the targets clients may connect to.
+type allowList map[string]struct{}
+
+func parseAllowList(s string) allowList {
+	l := make(allowList)
+	for _, target := range strings.Split(s, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			l[target] = struct{}{}
+		}
+	}
+	return l
+}
+
+func (l allowList) Allowed(target string) bool {
+	if _, ok := l["*"]; ok {
+		return true
+	}
+	_, ok := l[target]
+	return ok
+}
+
+func runServer(ctx context.Context, conf *quic.Config) error {
+	allowed := parseAllowList(allow)
+	if len(allowed) == 0 {
+		return errors.New("no targets allowed, set -allow")
+	}
+	tlsConf, err := serverTLSConfig()
+	if err != nil {
+		return err
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		return err
+	}
+	udpConn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return err
+	}
+	tr := &quic.Transport{Conn: udpConn}
+	if resetKey != "" {
+		key, err := os.ReadFile(resetKey)
+		if err != nil {
+			return err
+		}
+		if len(key) != len(quic.StatelessResetKey{}) {
+			return fmt.Errorf("stateless reset key must be %d bytes long", len(quic.StatelessResetKey{}))
+		}
+		tr.StatelessResetKey = (*quic.StatelessResetKey)(key)
+	}
+	defer tr.Close()
+	ln, err := tr.Listen(tlsConf, conf)
+	if err != nil {
+		return err
+	}
+	log.Printf("listening on %s (FEC: %s, format: %s)", ln.Addr(), fecScheme, fecFormat)
+
+	for {
+		conn, err := ln.Accept(ctx)
+		if err != nil {
+			return err
+		}
+		s := &serverConn{
+			conn:    conn,
+			allowed: allowed,
+			flows:   make(map[uint64]net.Conn),
+		}
+		go s.run()
+	}
+}
+
+// A serverConn handles the streams and datagrams of a single client.
+type serverConn struct {
+	conn    quic.Connection
+	allowed allowList
+
+	mutex sync.Mutex
+	flows map[uint64]net.Conn // UDP flows, by flow ID
+}
+
+func (s *serverConn) run() {
+	log.Printf("%s connected", s.conn.RemoteAddr())
+	go s.receiveDatagrams()
+	for {
+		str, err := s.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			log.Printf("%s disconnected: %s", s.conn.RemoteAddr(), err)
+			return
+		}
+		go s.handleStream(str)
+	}
+}
+
+func (s *serverConn) handleStream(str quic.ReceiveStream) {
+	h, err := readHeader(str)
+	if err != nil {
+		log.Printf("reading stream header failed: %s", err)
+		str.CancelRead(errorCodeAborted)
+		return
+	}
+	switch h.Type {
+	case streamTypeTCP:
+		s.handleTCP(str, h)
+	case streamTypeUDP:
+		s.handleUDP(str, h)
+	default:
+		str.CancelRead(errorCodeAborted)
+	}
+}
+
+// handleTCP connects to the target and opens the return stream.
+// If the connection fails, the client learns about it when the read side of its stream is canceled.
+func (s *serverConn) handleTCP(recv quic.ReceiveStream, h *header) {
+	if !s.allowed.Allowed(h.Target) {
+		log.Printf("%s: forwarding to %s not allowed", s.conn.RemoteAddr(), h.Target)
+		recv.CancelRead(errorCodeForbidden)
+		return
+	}
+	c, err := net.DialTimeout("tcp", h.Target, dialTimeout)
+	if err != nil {
+		log.Printf("%s: connecting to %s failed: %s", s.conn.RemoteAddr(), h.Target, err)
+		recv.CancelRead(errorCodeDialFailed)
+		return
+	}
+	send, err := openStream(s.conn.Context(), s.conn)
+	if err != nil {
+		c.Close()
+		recv.CancelRead(errorCodeAborted)
+		return
+	}
+	if _, err := send.Write((&header{Type: streamTypeTCPReturn, ID: h.ID}).Append(nil)); err != nil {
+		c.Close()
+		send.CancelWrite(errorCodeAborted)
+		recv.CancelRead(errorCodeAborted)
+		return
+	}
+	if verbose {
+		log.Printf("%s: forwarding TCP connection %d to %s", s.conn.RemoteAddr(), h.ID, h.Target)
+	}
+	proxy(c.(*net.TCPConn), send, recv)
+	if verbose {
+		log.Printf("%s: TCP connection %d closed", s.conn.RemoteAddr(), h.ID)
+	}
+}
+
+// handleUDP forwards the datagrams of a flow to the target.
+// The flow ends when the client closes the stream.
+func (s *serverConn) handleUDP(str quic.ReceiveStream, h *header) {
+	if !s.allowed.Allowed(h.Target) {
+		log.Printf("%s: forwarding to %s not allowed", s.conn.RemoteAddr(), h.Target)
+		str.CancelRead(errorCodeForbidden)
+		return
+	}
+	c, err := net.Dial("udp", h.Target)
+	if err != nil {
+		log.Printf("%s: connecting to %s failed: %s", s.conn.RemoteAddr(), h.Target, err)
+		str.CancelRead(errorCodeDialFailed)
+		return
+	}
+	defer c.Close()
+
+	s.mutex.Lock()
+	if _, ok := s.flows[h.ID]; ok {
+		s.mutex.Unlock()
+		str.CancelRead(errorCodeAborted)
+		return
+	}
+	s.flows[h.ID] = c
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.flows, h.ID)
+		s.mutex.Unlock()
+	}()
+
+	if verbose {
+		log.Printf("%s: forwarding UDP flow %d to %s", s.conn.RemoteAddr(), h.ID, h.Target)
+	}
+	c.Write(h.Payload)
+	go func() {
+		b := make([]byte, maxPayloadLen)
+		for {
+			n, err := c.Read(b)
+			if err != nil {
+				return
+			}
+			if err := sendDatagram(s.conn, h.ID, b[:n]); err != nil {
+				return
+			}
+		}
+	}()
+	io.Copy(io.Discard, str)
+	if verbose {
+		log.Printf("%s: UDP flow %d closed", s.conn.RemoteAddr(), h.ID)
+	}
+}
+
+func (s *serverConn) receiveDatagrams() {
+	for {
+		b, err := s.conn.ReceiveDatagram(context.Background())
+		if err != nil {
+			return
+		}
+		flowID, payload, err := parseDatagram(b)
+		if err != nil {
+			continue
+		}
+		s.mutex.Lock()
+		c, ok := s.flows[flowID]
+		s.mutex.Unlock()
+		// The flow might already be closed.
+		if ok {
+			c.Write(payload)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// freeUDPAddr returns a local address that no UDP socket is bound to.
+func freeUDPAddr(t *testing.T) string {
+	t.Helper()
+	c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	return c.LocalAddr().String()
+}
+
+func freeTCPAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func startUDPEcho(t *testing.T) string {
+	t.Helper()
+	c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	go func() {
+		b := make([]byte, 1500)
+		for {
+			n, addr, err := c.ReadFromUDP(b)
+			if err != nil {
+				return
+			}
+			c.WriteToUDP(b[:n], addr)
+		}
+	}()
+	return c.LocalAddr().String()
+}
+
+func startTCPEcho(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestTunnel(t *testing.T) {
+	for _, scheme := range []string{"none", "xor", "rs"} {
+		t.Run(scheme, func(t *testing.T) {
+			testTunnel(t, scheme)
+		})
+	}
+}
+
+func testTunnel(t *testing.T, scheme string) {
+	udpTarget := startUDPEcho(t)
+	tcpTarget := startTCPEcho(t)
+	udpLocal := freeUDPAddr(t)
+	tcpLocal := freeTCPAddr(t)
+
+	fecScheme = scheme
+	fecFormat = "legacy"
+	listenAddr = freeUDPAddr(t)
+	allow = udpTarget + "," + tcpTarget
+	serverAddr = listenAddr
+	insecure = true
+	tcpForwards = forwardList{{Local: tcpLocal, Target: tcpTarget}}
+	udpForwards = forwardList{{Local: udpLocal, Target: udpTarget}}
+
+	conf, err := quicConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	serverErr := make(chan error, 1)
+	clientErr := make(chan error, 1)
+	go func() { serverErr <- runServer(ctx, conf) }()
+	go func() { clientErr <- runClient(ctx, conf) }()
+	defer func() {
+		cancel()
+		for _, c := range []chan error{serverErr, clientErr} {
+			select {
+			case <-c:
+			case <-time.After(5 * time.Second):
+				t.Error("timeout waiting for the tunnel to shut down")
+			}
+		}
+	}()
+
+	// The client's UDP socket is opened asynchronously, and the tunnel is established in the background.
+	// Datagrams sent in the meantime are dropped, so keep sending until the echo is received.
+	pc, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	local, err := net.ResolveUDPAddr("udp", udpLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	b := make([]byte, 1500)
+	for {
+		if time.Now().After(deadline) {
+			t.Fatal("no UDP echo received")
+		}
+		if _, err := pc.WriteToUDP([]byte("foobar"), local); err != nil {
+			t.Fatal(err)
+		}
+		pc.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, _, err := pc.ReadFromUDP(b)
+		if err == nil {
+			if string(b[:n]) != "foobar" {
+				t.Fatalf("unexpected UDP echo: %q", b[:n])
+			}
+			break
+		}
+	}
+
+	c, err := net.Dial("tcp", tcpLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	go c.Write(data)
+	c.SetReadDeadline(time.Now().Add(10 * time.Second))
+	echo := make([]byte, len(data))
+	if _, err := io.ReadFull(c, echo); err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		if echo[i] != data[i] {
+			t.Fatalf("TCP echo differs at byte %d", i)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package fec
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/quic-go/quic-go/internal/fec"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// An Encoder generates repair symbols for blocks of source symbols.
+// It is not safe for concurrent use.
+type Encoder struct {
+	codec         fec.Codec
+	maxSymbolSize int
+}
+
+// NewEncoder creates an encoder for blocks of numSourceSymbols source symbols,
+// that are each protected by numRepairSymbols repair symbols.
+// The XOR scheme only supports a single repair symbol.
+func NewEncoder(scheme Scheme, numSourceSymbols, numRepairSymbols int) (*Encoder, error) {
+	codec, err := newCodec(scheme, numSourceSymbols, numRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{codec: codec, maxSymbolSize: maxSymbolSize(scheme)}, nil
+}
+
+func newCodec(scheme Scheme, numSourceSymbols, numRepairSymbols int) (fec.Codec, error) {
+	if numRepairSymbols > math.MaxUint16+1 {
+		return nil, fmt.Errorf("too many repair symbols: %d", numRepairSymbols)
+	}
+	return fec.NewCodec(scheme, numSourceSymbols, numRepairSymbols)
+}
+
+// maxSymbolSize is the maximum size of the data of a source symbol of the scheme.
+func maxSymbolSize(scheme Scheme) int {
+	if scheme == LargeBlockReedSolomon {
+		return MaxLargeBlockSymbolSize
+	}
+	return MaxSymbolSize
+}
+
+// AddSourceSymbol adds the data as the next source symbol.
+// It returns the source symbol, and the repair symbols if the source symbol completes a block.
+// The data is copied, so it can be reused by the caller.
+// The data of the returned symbols must not be modified.
+func (e *Encoder) AddSourceSymbol(data []byte) (SourceSymbol, []RepairSymbol, error) {
+	if len(data) > e.maxSymbolSize {
+		return SourceSymbol{}, nil, fmt.Errorf("source symbol too large: %d bytes, max %d", len(data), e.maxSymbolSize)
+	}
+	f := &wire.SourceSymbolFrame{SSID: e.codec.NextSSID(), Payload: copySymbolData(data)}
+	repairFrames, err := e.codec.AddSourceSymbolFrame(f)
+	if err != nil {
+		return SourceSymbol{}, nil, err
+	}
+	var repairSymbols []RepairSymbol
+	if len(repairFrames) > 0 {
+		repairSymbols = make([]RepairSymbol, 0, len(repairFrames))
+		for _, rf := range repairFrames {
+			repairSymbols = append(repairSymbols, RepairSymbol{
+				BlockID: uint64(rf.Metadata.BlockID),
+				Index:   uint16(rf.Metadata.ParityID),
+				Data:    rf.Payload,
+			})
+		}
+	}
+	return SourceSymbol{ID: uint64(f.SSID), Data: f.Payload}, repairSymbols, nil
+}
+
+// A Decoder recovers lost source symbols using the repair symbols.
+// It is not safe for concurrent use.
+type Decoder struct {
+	codec fec.Codec
+}
+
+// NewDecoder creates a decoder. The block geometry and the scheme must match those of the Encoder.
+func NewDecoder(scheme Scheme, numSourceSymbols, numRepairSymbols int) (*Decoder, error) {
+	codec, err := newCodec(scheme, numSourceSymbols, numRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{codec: codec}, nil
+}
+
+// AddSourceSymbol adds a received source symbol.
+// It returns the source symbol itself, followed by the source symbols that were recovered using it.
+// No source symbols are returned if the source symbol was already recovered.
+// The data is copied, so it can be reused by the caller.
+func (d *Decoder) AddSourceSymbol(s SourceSymbol) ([]SourceSymbol, error) {
+	if len(s.Data) > MaxSymbolSize {
+		return nil, fmt.Errorf("source symbol too large: %d bytes, max %d", len(s.Data), MaxSymbolSize)
+	}
+	symbols, err := d.codec.HandleSourceSymbol(&wire.SourceSymbolFrame{
+		SSID:    protocol.SourceSymbolID(s.ID),
+		Payload: copySymbolData(s.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSourceSymbols(symbols), nil
+}
+
+// AddRepairSymbol adds a received repair symbol.
+// It returns the source symbols that were recovered using it.
+// The data is copied, so it can be reused by the caller.
+func (d *Decoder) AddRepairSymbol(s RepairSymbol) ([]SourceSymbol, error) {
+	symbols, err := d.codec.HandleRepairSymbol(&wire.RepairFrame{
+		Metadata: protocol.BlockMetadata{
+			BlockID:  protocol.BlockID(s.BlockID),
+			ParityID: protocol.ParityID(s.Index),
+		},
+		Payload: copySymbolData(s.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSourceSymbols(symbols), nil
+}
+
+// copySymbolData copies the data of a symbol.
+// The Reed-Solomon scheme appends the length to the source symbols, so the buffer needs to be large enough.
+func copySymbolData(data []byte) []byte {
+	b := make([]byte, len(data), max(len(data), protocol.MaxPacketBufferSize))
+	copy(b, data)
+	return b
+}
+
+func toSourceSymbols(symbols []fec.SourceSymbol) []SourceSymbol {
+	if len(symbols) == 0 {
+		return nil
+	}
+	s := make([]SourceSymbol, 0, len(symbols))
+	for _, sym := range symbols {
+		s = append(s, SourceSymbol{ID: uint64(sym.SSID), Data: sym.Payload})
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package fec
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoder and Decoder", func() {
+	encode := func(enc *Encoder, num int) ([]SourceSymbol, []RepairSymbol) {
+		var sourceSymbols []SourceSymbol
+		var repairSymbols []RepairSymbol
+		for i := 0; i < num; i++ {
+			data := make([]byte, 1+rand.Intn(MaxSymbolSize))
+			rand.Read(data)
+			s, repairs, err := enc.AddSourceSymbol(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.ID).To(BeEquivalentTo(i))
+			Expect(s.Data).To(Equal(data))
+			sourceSymbols = append(sourceSymbols, s)
+			repairSymbols = append(repairSymbols, repairs...)
+		}
+		return sourceSymbols, repairSymbols
+	}
+
+	// transmit serializes the symbols, and passes them to the decoder.
+	transmit := func(dec *Decoder, s Symbol) []SourceSymbol {
+		parsed, err := ParseSymbol(s.Append(nil))
+		Expect(err).ToNot(HaveOccurred())
+		var symbols []SourceSymbol
+		switch s := parsed.(type) {
+		case SourceSymbol:
+			symbols, err = dec.AddSourceSymbol(s)
+		case RepairSymbol:
+			symbols, err = dec.AddRepairSymbol(s)
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return symbols
+	}
+
+	It("recovers a lost source symbol using XOR", func() {
+		enc, err := NewEncoder(XOR, 4, 1)
+		Expect(err).ToNot(HaveOccurred())
+		sourceSymbols, repairSymbols := encode(enc, 8)
+		Expect(repairSymbols).To(HaveLen(2))
+		Expect(repairSymbols[0].BlockID).To(BeZero())
+		Expect(repairSymbols[1].BlockID).To(BeEquivalentTo(1))
+
+		dec, err := NewDecoder(XOR, 4, 1)
+		Expect(err).ToNot(HaveOccurred())
+		// the source symbol with ID 2 is lost
+		for _, s := range append(sourceSymbols[:2:2], sourceSymbols[3:4]...) {
+			Expect(transmit(dec, s)).To(Equal([]SourceSymbol{s}))
+		}
+		Expect(transmit(dec, repairSymbols[0])).To(Equal([]SourceSymbol{sourceSymbols[2]}))
+		// the repair symbol of the second block arrives before the source symbols, and the source symbol with ID 5 is lost
+		Expect(transmit(dec, repairSymbols[1])).To(BeEmpty())
+		Expect(transmit(dec, sourceSymbols[4])).To(Equal(sourceSymbols[4:5]))
+		Expect(transmit(dec, sourceSymbols[6])).To(Equal(sourceSymbols[6:7]))
+		Expect(transmit(dec, sourceSymbols[7])).To(Equal([]SourceSymbol{sourceSymbols[7], sourceSymbols[5]}))
+		// the block was already recovered
+		Expect(transmit(dec, sourceSymbols[5])).To(BeEmpty())
+	})
+
+	It("recovers lost source symbols using Reed-Solomon", func() {
+		enc, err := NewEncoder(ReedSolomon, 10, 4)
+		Expect(err).ToNot(HaveOccurred())
+		sourceSymbols, repairSymbols := encode(enc, 10)
+		Expect(repairSymbols).To(HaveLen(4))
+		for i, r := range repairSymbols {
+			Expect(r.BlockID).To(BeZero())
+			Expect(r.Index).To(BeEquivalentTo(i))
+		}
+
+		dec, err := NewDecoder(ReedSolomon, 10, 4)
+		Expect(err).ToNot(HaveOccurred())
+		lost := map[int]bool{0: true, 3: true, 4: true, 9: true}
+		for i, s := range sourceSymbols {
+			if !lost[i] {
+				Expect(transmit(dec, s)).To(Equal([]SourceSymbol{s}))
+			}
+		}
+		for _, r := range repairSymbols[:3] {
+			Expect(transmit(dec, r)).To(BeEmpty())
+		}
+		recovered := transmit(dec, repairSymbols[3])
+		Expect(recovered).To(Equal([]SourceSymbol{sourceSymbols[0], sourceSymbols[3], sourceSymbols[4], sourceSymbols[9]}))
+	})
+
+	It("copies the data", func() {
+		enc, err := NewEncoder(XOR, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		data := []byte("foobar")
+		s, _, err := enc.AddSourceSymbol(data)
+		Expect(err).ToNot(HaveOccurred())
+		data[0] = 'x'
+		Expect(s.Data).To(Equal([]byte("foobar")))
+		_, repairs, err := enc.AddSourceSymbol([]byte("raboof"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repairs).To(HaveLen(1))
+
+		dec, err := NewDecoder(XOR, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		received := bytes.Clone(s.Data)
+		_, err = dec.AddSourceSymbol(SourceSymbol{ID: 0, Data: received})
+		Expect(err).ToNot(HaveOccurred())
+		received[0] = 'x'
+		recovered, err := dec.AddRepairSymbol(repairs[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recovered).To(Equal([]SourceSymbol{{ID: 1, Data: []byte("raboof")}}))
+	})
+
+	It("rejects invalid block geometries", func() {
+		_, err := NewEncoder(XOR, 4, 2)
+		Expect(err).To(MatchError("xor only supports 1 repair symbol, got 2"))
+		_, err = NewDecoder(ReedSolomon, 0, 1)
+		Expect(err).To(MatchError("a block needs at least one source symbol and one repair symbol, got 0 and 1"))
+		_, err = NewDecoder(ReedSolomon, 1<<16, 1)
+		Expect(err).To(HaveOccurred())
+		_, err = NewEncoder(42, 1, 1)
+		Expect(err).To(MatchError("unknown FEC scheme: 42"))
+	})
+
+	It("rejects source symbols that are too large", func() {
+		enc, err := NewEncoder(ReedSolomon, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = enc.AddSourceSymbol(make([]byte, MaxSymbolSize+1))
+		Expect(err).To(MatchError(ContainSubstring("source symbol too large")))
+		dec, err := NewDecoder(ReedSolomon, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dec.AddSourceSymbol(SourceSymbol{Data: make([]byte, MaxSymbolSize+1)})
+		Expect(err).To(MatchError(ContainSubstring("source symbol too large")))
+	})
+
+	It("rejects invalid repair symbols", func() {
+		dec, err := NewDecoder(ReedSolomon, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dec.AddRepairSymbol(RepairSymbol{Index: 1, Data: make([]byte, 10)})
+		Expect(err).To(MatchError(ContainSubstring("invalid parity ID")))
+	})
+
+	It("rejects symbols outside of the decoding window", func() {
+		dec, err := NewDecoder(XOR, 2, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dec.AddSourceSymbol(SourceSymbol{ID: 1 << 40, Data: []byte("foobar")})
+		Expect(err).To(MatchError(ErrSymbolOutsideWindow))
+	})
+
+	It("returns the block geometry of a protection class", func() {
+		numSource, numRepair, err := ClassGeometry(ReedSolomon, protocol.FECProtectionHigh)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(numSource).To(Equal(10))
+		Expect(numRepair).To(Equal(10))
+		numSource, numRepair, err = ClassGeometry(XOR, protocol.FECProtectionLow)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(numSource).To(Equal(4))
+		Expect(numRepair).To(Equal(1))
+		_, _, err = ClassGeometry(XOR, protocol.MaxFECProtectionClass+1)
+		Expect(err).To(HaveOccurred())
+	})
+})
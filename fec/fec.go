@@ -0,0 +1,48 @@
+// Package fec implements the forward error correction used by quic-go, independent of QUIC connections.
+//
+// An Encoder groups source symbols into blocks, and generates repair symbols for every complete block.
+// A Decoder uses the repair symbols to recover lost source symbols of a block.
+// Symbols can be serialized using Append, and parsed using ParseSymbol,
+// such that they can be sent over any transport.
+package fec
+
+import (
+	"github.com/quic-go/quic-go/internal/fec"
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A Scheme is an FEC scheme.
+type Scheme = protocol.DecoderFECScheme
+
+const (
+	// XOR protects a block by a single repair symbol, which is the XOR of the source symbols.
+	// It recovers a single lost source symbol per block.
+	XOR Scheme = protocol.XORFECScheme
+	// ReedSolomon protects a block by a configurable number of repair symbols.
+	// It recovers as many lost source symbols per block as there are repair symbols.
+	ReedSolomon Scheme = protocol.ReedSolomonFECScheme
+	// LargeBlockReedSolomon is a Reed-Solomon scheme that supports blocks of thousands of symbols,
+	// at most MaxBlockSymbols source and repair symbols in total.
+	// Its source symbols are limited to MaxLargeBlockSymbolSize.
+	LargeBlockReedSolomon Scheme = protocol.LargeBlockReedSolomonFECScheme
+)
+
+// MaxSymbolSize is the maximum size of the data of a source symbol.
+const MaxSymbolSize = protocol.MaxFECPacketBufferSize
+
+// MaxLargeBlockSymbolSize is the maximum size of the data of a source symbol of the LargeBlockReedSolomon scheme.
+const MaxLargeBlockSymbolSize = fec.MaxLargeBlockSourceSymbolLen
+
+// MaxBlockSymbols is the maximum number of source and repair symbols of a block.
+const MaxBlockSymbols = protocol.MaxFECBlockSymbols
+
+// ClassGeometry returns the block geometry that QUIC connections use for a protection class:
+// the number of source symbols per block, and the number of repair symbols generated for every block.
+// It can be used to create an Encoder and a Decoder that protect data like a connection does.
+func ClassGeometry(scheme Scheme, class protocol.FECProtectionClass) (numSourceSymbols, numRepairSymbols int, err error) {
+	return fec.BlockGeometry(scheme, class)
+}
+
+// ErrSymbolOutsideWindow is returned by the Decoder when a symbol belongs to a block that is too far
+// ahead of the highest block that a symbol was received for.
+var ErrSymbolOutsideWindow = fec.ErrSymbolOutsideWindow
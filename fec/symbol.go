@@ -0,0 +1,95 @@
+package fec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	sourceSymbolType byte = 0x0
+	repairSymbolType byte = 0x1
+)
+
+const (
+	sourceSymbolHeaderLen = 1 + 8
+	repairSymbolHeaderLen = 1 + 8 + 2
+)
+
+// A Symbol is a SourceSymbol or a RepairSymbol.
+type Symbol interface {
+	// Append appends the serialized symbol.
+	Append(b []byte) []byte
+	isSymbol()
+}
+
+// A SourceSymbol is a unit of data protected by FEC.
+type SourceSymbol struct {
+	// ID is the ID of the source symbol. IDs are assigned consecutively by the Encoder, starting at 0.
+	ID   uint64
+	Data []byte
+}
+
+var _ Symbol = SourceSymbol{}
+
+// Append appends the serialized source symbol.
+// It consists of the symbol type (0x0), the 8 byte ID in network byte order, followed by the data.
+func (s SourceSymbol) Append(b []byte) []byte {
+	b = append(b, sourceSymbolType)
+	b = binary.BigEndian.AppendUint64(b, s.ID)
+	return append(b, s.Data...)
+}
+
+func (SourceSymbol) isSymbol() {}
+
+// A RepairSymbol is generated by the Encoder for a complete block of source symbols.
+type RepairSymbol struct {
+	// BlockID is the ID of the block. The block with ID n protects the source symbols with IDs n*k to (n+1)*k-1,
+	// where k is the number of source symbols in a block.
+	BlockID uint64
+	// Index is the index of the repair symbol within its block.
+	Index uint16
+	Data  []byte
+}
+
+var _ Symbol = RepairSymbol{}
+
+// Append appends the serialized repair symbol.
+// It consists of the symbol type (0x1), the 8 byte block ID and the 2 byte index in network byte order, followed by the data.
+func (s RepairSymbol) Append(b []byte) []byte {
+	b = append(b, repairSymbolType)
+	b = binary.BigEndian.AppendUint64(b, s.BlockID)
+	b = binary.BigEndian.AppendUint16(b, s.Index)
+	return append(b, s.Data...)
+}
+
+func (RepairSymbol) isSymbol() {}
+
+// ParseSymbol parses a symbol serialized using Append.
+// The data of the symbol references b.
+func ParseSymbol(b []byte) (Symbol, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty symbol")
+	}
+	switch b[0] {
+	case sourceSymbolType:
+		if len(b) < sourceSymbolHeaderLen {
+			return nil, fmt.Errorf("source symbol too short: %d bytes", len(b))
+		}
+		return SourceSymbol{
+			ID:   binary.BigEndian.Uint64(b[1:9]),
+			Data: b[sourceSymbolHeaderLen:],
+		}, nil
+	case repairSymbolType:
+		if len(b) < repairSymbolHeaderLen {
+			return nil, fmt.Errorf("repair symbol too short: %d bytes", len(b))
+		}
+		return RepairSymbol{
+			BlockID: binary.BigEndian.Uint64(b[1:9]),
+			Index:   binary.BigEndian.Uint16(b[9:11]),
+			Data:    b[repairSymbolHeaderLen:],
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown symbol type: %#x", b[0])
+	}
+}
@@ -0,0 +1,42 @@
+package fec
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Symbols", func() {
+	It("serializes source symbols", func() {
+		b := SourceSymbol{ID: 0x0102030405060708, Data: []byte("foobar")}.Append([]byte{0x42})
+		Expect(b).To(Equal(append([]byte{0x42, 0x0, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}, []byte("foobar")...)))
+		s, err := ParseSymbol(b[1:])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s).To(Equal(SourceSymbol{ID: 0x0102030405060708, Data: []byte("foobar")}))
+	})
+
+	It("serializes repair symbols", func() {
+		b := RepairSymbol{BlockID: 0x1337, Index: 0xbeef, Data: []byte("foobar")}.Append(nil)
+		Expect(b).To(Equal(append([]byte{0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x13, 0x37, 0xbe, 0xef}, []byte("foobar")...)))
+		s, err := ParseSymbol(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s).To(Equal(RepairSymbol{BlockID: 0x1337, Index: 0xbeef, Data: []byte("foobar")}))
+	})
+
+	It("serializes symbols without data", func() {
+		s, err := ParseSymbol(SourceSymbol{ID: 1}.Append(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.(SourceSymbol).ID).To(BeEquivalentTo(1))
+		Expect(s.(SourceSymbol).Data).To(BeEmpty())
+	})
+
+	It("errors on invalid symbols", func() {
+		_, err := ParseSymbol(nil)
+		Expect(err).To(MatchError("empty symbol"))
+		_, err = ParseSymbol([]byte{0x0, 0x1, 0x2})
+		Expect(err).To(MatchError("source symbol too short: 3 bytes"))
+		_, err = ParseSymbol(RepairSymbol{}.Append(nil)[:10])
+		Expect(err).To(MatchError("repair symbol too short: 10 bytes"))
+		_, err = ParseSymbol([]byte{0x2, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0})
+		Expect(err).To(MatchError("unknown symbol type: 0x2"))
+	})
+})
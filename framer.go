@@ -2,6 +2,8 @@ package quic
 
 import (
 	"errors"
+	"math/bits"
+	"slices"
 	"sync"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
@@ -18,7 +20,18 @@ type framer interface {
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount, protocol.Version) ([]ackhandler.Frame, protocol.ByteCount)
 
 	AddActiveStream(protocol.StreamID)
+	// SetStreamPriority sets the priority of a stream.
+	// It applies immediately if the stream is active, and whenever the stream becomes active later.
+	// Priority changes of streams that were already deleted are ignored.
+	SetStreamPriority(protocol.StreamID, protocol.StreamPriority)
+	// RemoveStream forgets the priority of a stream that completed.
+	RemoveStream(protocol.StreamID)
 	AppendStreamFrames([]ackhandler.StreamFrame, protocol.ByteCount, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
+	// AppendFECStreamFrames appends STREAM frames like AppendStreamFrames,
+	// but all FEC protected STREAM frames belong to the same protection class.
+	// If hasClass is set, FEC protected streams of other classes are skipped.
+	// Otherwise, the class of the first FEC protected STREAM frame is used.
+	AppendFECStreamFrames(frames []ackhandler.StreamFrame, maxLen protocol.ByteCount, class protocol.FECProtectionClass, hasClass bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
 
@@ -35,13 +48,58 @@ const (
 	maxControlFrames = 16 << 10
 )
 
+// The active streams of one urgency level.
+type urgencyLevel struct {
+	// non-incremental streams, sorted by stream ID
+	sequential []protocol.StreamID
+	// incremental streams, scheduled round-robin
+	incremental ringbuffer.RingBuffer[protocol.StreamID]
+	// the number of STREAM frames the stream at the front of incremental sent in its current turn
+	frontTurns int
+}
+
+func (l *urgencyLevel) empty() bool {
+	return len(l.sequential) == 0 && l.incremental.Empty()
+}
+
+func (l *urgencyLevel) add(id protocol.StreamID, prio protocol.StreamPriority) {
+	if prio.Incremental {
+		l.incremental.PushBack(id)
+		return
+	}
+	i, _ := slices.BinarySearch(l.sequential, id)
+	l.sequential = slices.Insert(l.sequential, i, id)
+}
+
+func (l *urgencyLevel) remove(id protocol.StreamID, prio protocol.StreamPriority) {
+	if !prio.Incremental {
+		if i, ok := slices.BinarySearch(l.sequential, id); ok {
+			l.sequential = slices.Delete(l.sequential, i, i+1)
+		}
+		return
+	}
+	if l.incremental.PeekFront() == id {
+		l.frontTurns = 0
+	}
+	for n := l.incremental.Len(); n > 0; n-- {
+		if other := l.incremental.PopFront(); other != id {
+			l.incremental.PushBack(other)
+		}
+	}
+}
+
 type framerI struct {
 	mutex sync.Mutex
 
 	streamGetter streamGetter
 
-	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   ringbuffer.RingBuffer[protocol.StreamID]
+	// the active streams, and the priority they were queued with
+	activeStreams map[protocol.StreamID]protocol.StreamPriority
+	// the priorities of the streams that don't use the default priority
+	priorities map[protocol.StreamID]protocol.StreamPriority
+	levels     [protocol.MaxStreamUrgency + 1]urgencyLevel
+	// bit i is set if level i has active streams
+	nonEmptyLevels uint8
 
 	controlFrameMutex          sync.Mutex
 	controlFrames              []wire.Frame
@@ -54,13 +112,14 @@ var _ framer = &framerI{}
 func newFramer(streamGetter streamGetter) framer {
 	return &framerI{
 		streamGetter:  streamGetter,
-		activeStreams: make(map[protocol.StreamID]struct{}),
+		activeStreams: make(map[protocol.StreamID]protocol.StreamPriority),
+		priorities:    make(map[protocol.StreamID]protocol.StreamPriority),
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := !f.streamQueue.Empty()
+	hasData := f.nonEmptyLevels != 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -128,41 +187,137 @@ func (f *framerI) QueuedTooManyControlFrames() bool {
 func (f *framerI) AddActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue.PushBack(id)
-		f.activeStreams[id] = struct{}{}
+		f.addActiveStream(id, f.priority(id))
 	}
 	f.mutex.Unlock()
 }
 
+func (f *framerI) priority(id protocol.StreamID) protocol.StreamPriority {
+	if len(f.priorities) > 0 {
+		if prio, ok := f.priorities[id]; ok {
+			return prio
+		}
+	}
+	return protocol.DefaultStreamPriority()
+}
+
+func (f *framerI) addActiveStream(id protocol.StreamID, prio protocol.StreamPriority) {
+	f.activeStreams[id] = prio
+	f.levels[prio.Urgency].add(id, prio)
+	f.nonEmptyLevels |= 1 << prio.Urgency
+}
+
+func (f *framerI) updateLevel(urgency uint8) {
+	if f.levels[urgency].empty() {
+		f.nonEmptyLevels &^= 1 << urgency
+	} else {
+		f.nonEmptyLevels |= 1 << urgency
+	}
+}
+
+func (f *framerI) SetStreamPriority(id protocol.StreamID, prio protocol.StreamPriority) {
+	prio = prio.Normalize()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// The stream might have completed after its priority was changed.
+	// Streams are deleted from the streams map before RemoveStream is called.
+	if str, err := f.streamGetter.GetOrOpenSendStream(id); str == nil || err != nil {
+		return
+	}
+	if prio == protocol.DefaultStreamPriority() {
+		delete(f.priorities, id)
+	} else {
+		f.priorities[id] = prio
+	}
+	old, ok := f.activeStreams[id]
+	if !ok || old == prio {
+		return
+	}
+	f.levels[old.Urgency].remove(id, old)
+	f.updateLevel(old.Urgency)
+	f.addActiveStream(id, prio)
+}
+
+func (f *framerI) RemoveStream(id protocol.StreamID) {
+	f.mutex.Lock()
+	delete(f.priorities, id)
+	f.mutex.Unlock()
+}
+
 func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen protocol.ByteCount, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+	return f.appendStreamFrames(frames, maxLen, false, 0, false, v)
+}
+
+func (f *framerI) AppendFECStreamFrames(frames []ackhandler.StreamFrame, maxLen protocol.ByteCount, class protocol.FECProtectionClass, hasClass bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+	return f.appendStreamFrames(frames, maxLen, true, class, hasClass, v)
+}
+
+func (f *framerI) appendStreamFrames(
+	frames []ackhandler.StreamFrame,
+	maxLen protocol.ByteCount,
+	singleFECClass bool,
+	fecClass protocol.FECProtectionClass,
+	hasFECClass bool,
+	v protocol.Version,
+) ([]ackhandler.StreamFrame, protocol.ByteCount) {
 	startLen := len(frames)
 	var length protocol.ByteCount
+	var skipped []protocol.StreamID
 	f.mutex.Lock()
 	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := f.streamQueue.Len()
-	for i := 0; i < numActiveStreams; i++ {
+	numActiveStreams := len(f.activeStreams)
+	for i := 0; i < numActiveStreams && f.nonEmptyLevels != 0; i++ {
 		if protocol.MinStreamFrameSize+length > maxLen {
 			break
 		}
-		id := f.streamQueue.PopFront()
+		urgency := uint8(bits.TrailingZeros8(f.nonEmptyLevels))
+		level := &f.levels[urgency]
+		var id protocol.StreamID
+		sequential := len(level.sequential) > 0
+		if sequential {
+			id = level.sequential[0]
+		} else {
+			id = level.incremental.PeekFront()
+		}
 		// This should never return an error. Better check it anyway.
-		// The stream will only be in the streamQueue, if it enqueued itself there.
+		// The stream will only be in the queue, if it enqueued itself there.
 		str, err := f.streamGetter.GetOrOpenSendStream(id)
 		// The stream can be nil if it completed after it said it had data.
 		if str == nil || err != nil {
+			f.removeFront(level, sequential)
 			delete(f.activeStreams, id)
+			f.updateLevel(urgency)
 			continue
 		}
+		if singleFECClass && hasFECClass {
+			// The STREAM frames of this stream need to go into a different SOURCE_SYMBOL frame.
+			if class, protected := str.fecProtectionClass(); protected && class != fecClass {
+				f.removeFront(level, sequential)
+				skipped = append(skipped, id)
+				f.updateLevel(urgency)
+				continue
+			}
+		}
 		remainingLen := maxLen - length
 		// For the last STREAM frame, we'll remove the DataLen field later.
 		// Therefore, we can pretend to have more bytes available when popping
 		// the STREAM frame (which will always have the DataLen set).
 		remainingLen += quicvarint.Len(uint64(remainingLen))
 		frame, ok, hasMoreData := str.popStreamFrame(remainingLen, v)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue.PushBack(id)
-		} else { // no more data to send. Stream is not active
+		if !hasMoreData { // no more data to send. Stream is not active
+			f.removeFront(level, sequential)
 			delete(f.activeStreams, id)
+			f.updateLevel(urgency)
+		} else if !sequential {
+			// Incremental streams send up to Weight STREAM frames before the next stream's turn.
+			// Sequential streams keep sending until they're done.
+			// If no stream has a custom priority, all streams have a Weight of 1.
+			level.frontTurns++
+			if len(f.priorities) == 0 || level.frontTurns >= int(f.activeStreams[id].Weight) {
+				level.incremental.PushBack(level.incremental.PopFront())
+				level.frontTurns = 0
+			}
 		}
 		// The frame can be "nil"
 		// * if the receiveStream was canceled after it said it had data
@@ -170,9 +325,22 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen pro
 		if !ok {
 			continue
 		}
+		if singleFECClass && frame.Frame.FECProtected {
+			// The class might have been changed since it was checked above.
+			// In that case, the change applies to the next STREAM frame.
+			if !hasFECClass {
+				fecClass = frame.Frame.FECProtectionClass
+				hasFECClass = true
+			}
+			frame.Frame.FECProtectionClass = fecClass
+		}
 		frames = append(frames, frame)
 		length += frame.Frame.Length(v)
 	}
+	// Skipped streams keep their position at the front of their queue.
+	for i := len(skipped) - 1; i >= 0; i-- {
+		f.requeueAtFront(skipped[i])
+	}
 	f.mutex.Unlock()
 	if len(frames) > startLen {
 		l := frames[len(frames)-1].Frame.Length(v)
@@ -183,15 +351,44 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen pro
 	return frames, length
 }
 
+// removeFront removes the stream at the front of the level.
+func (f *framerI) removeFront(level *urgencyLevel, sequential bool) {
+	if sequential {
+		level.sequential = level.sequential[1:]
+		return
+	}
+	level.incremental.PopFront()
+	level.frontTurns = 0
+}
+
+// requeueAtFront queues an active stream at the front of its level.
+func (f *framerI) requeueAtFront(id protocol.StreamID) {
+	prio := f.activeStreams[id]
+	level := &f.levels[prio.Urgency]
+	if prio.Incremental {
+		n := level.incremental.Len()
+		level.incremental.PushBack(id)
+		for i := 0; i < n; i++ {
+			level.incremental.PushBack(level.incremental.PopFront())
+		}
+		level.frontTurns = 0
+	} else {
+		level.add(id, prio)
+	}
+	f.nonEmptyLevels |= 1 << prio.Urgency
+}
+
 func (f *framerI) Handle0RTTRejection() error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue.Clear()
-	for id := range f.activeStreams {
-		delete(f.activeStreams, id)
+	for i := range f.levels {
+		f.levels[i] = urgencyLevel{}
 	}
+	f.nonEmptyLevels = 0
+	clear(f.activeStreams)
+	clear(f.priorities)
 	var j int
 	for i, frame := range f.controlFrames {
 		switch frame.(type) {
@@ -451,4 +451,151 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(BeZero())
 		})
 	})
+
+	Context("scheduling streams by priority", func() {
+		// addStream adds an active stream that has numFrames STREAM frames to send, each filling a packet
+		addStream := func(id protocol.StreamID, numFrames int) {
+			str := NewMockSendStreamI(mockCtrl)
+			streamGetter.EXPECT().GetOrOpenSendStream(id).Return(str, nil).AnyTimes()
+			str.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).DoAndReturn(func(size protocol.ByteCount, v protocol.Version) (ackhandler.StreamFrame, bool, bool) {
+				numFrames--
+				f := &wire.StreamFrame{StreamID: id, DataLenPresent: true}
+				f.Data = make([]byte, f.MaxDataLen(size, v))
+				return ackhandler.StreamFrame{Frame: f}, true, numFrames > 0
+			}).Times(numFrames)
+			framer.AddActiveStream(id)
+		}
+
+		// setPriority sets the priority of a stream that exists
+		setPriority := func(id protocol.StreamID, prio protocol.StreamPriority) {
+			streamGetter.EXPECT().GetOrOpenSendStream(id).Return(NewMockSendStreamI(mockCtrl), nil)
+			framer.SetStreamPriority(id, prio)
+		}
+
+		// popStreams returns the stream IDs of the STREAM frames sent in the next packets
+		popStreams := func() []protocol.StreamID {
+			var ids []protocol.StreamID
+			for framer.HasData() {
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				ids = append(ids, frames[0].Frame.StreamID)
+			}
+			return ids
+		}
+
+		It("sends data of more urgent streams first", func() {
+			setPriority(8, protocol.StreamPriority{Urgency: 5, Incremental: true})
+			setPriority(4, protocol.StreamPriority{Urgency: 1, Incremental: true})
+			addStream(0, 2)
+			addStream(8, 2)
+			addStream(4, 2)
+			Expect(popStreams()).To(Equal([]protocol.StreamID{4, 4, 0, 0, 8, 8}))
+		})
+
+		It("sends non-incremental streams one after the other, in the order of their stream IDs", func() {
+			setPriority(8, protocol.StreamPriority{Urgency: 3})
+			setPriority(4, protocol.StreamPriority{Urgency: 3})
+			addStream(8, 2)
+			addStream(0, 2)
+			addStream(4, 2)
+			Expect(popStreams()).To(Equal([]protocol.StreamID{4, 4, 8, 8, 0, 0}))
+		})
+
+		It("shares the bandwidth between incremental streams according to their weights", func() {
+			setPriority(0, protocol.StreamPriority{Urgency: 3, Incremental: true, Weight: 3})
+			addStream(0, 6)
+			addStream(4, 3)
+			Expect(popStreams()).To(Equal([]protocol.StreamID{0, 0, 0, 4, 0, 0, 0, 4, 4}))
+		})
+
+		It("changes the priority of an active stream", func() {
+			addStream(0, 3)
+			addStream(4, 2)
+			frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame.StreamID).To(Equal(protocol.StreamID(0)))
+			framer.SetStreamPriority(0, protocol.StreamPriority{Urgency: 0})
+			Expect(popStreams()).To(Equal([]protocol.StreamID{0, 0, 4, 4}))
+		})
+
+		It("forgets the priority of completed streams", func() {
+			setPriority(4, protocol.StreamPriority{Urgency: 0})
+			framer.RemoveStream(4)
+			addStream(0, 1)
+			addStream(4, 1)
+			Expect(popStreams()).To(Equal([]protocol.StreamID{0, 4}))
+		})
+
+		It("ignores priority changes of deleted streams", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(protocol.StreamID(4)).Return(nil, nil)
+			framer.SetStreamPriority(4, protocol.StreamPriority{Urgency: 0})
+			Expect(framer.(*framerI).priorities).To(BeEmpty())
+		})
+
+		It("packs frames of multiple streams into one packet, in the order of their priorities", func() {
+			setPriority(4, protocol.StreamPriority{Urgency: 0})
+			for _, id := range []protocol.StreamID{0, 4} {
+				str := NewMockSendStreamI(mockCtrl)
+				streamGetter.EXPECT().GetOrOpenSendStream(id).Return(str, nil)
+				str.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(
+					ackhandler.StreamFrame{Frame: &wire.StreamFrame{StreamID: id, Data: []byte("foobar"), DataLenPresent: true}}, true, false,
+				)
+				framer.AddActiveStream(id)
+			}
+			frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame.StreamID).To(Equal(protocol.StreamID(4)))
+			Expect(frames[1].Frame.StreamID).To(Equal(protocol.StreamID(0)))
+			Expect(framer.HasData()).To(BeFalse())
+		})
+	})
+
+	Context("popping FEC protected STREAM frames", func() {
+		It("uses the protection class of the first FEC protected STREAM frame", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f1 := &wire.StreamFrame{Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+			f2 := &wire.StreamFrame{Data: []byte("foobaz"), FECProtected: true, FECProtectionClass: protocol.FECProtectionLow}
+			stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f1}, true, true)
+			stream2.EXPECT().fecProtectionClass().Return(protocol.FECProtectionLow, true)
+			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id2)
+			frames, _ := framer.AppendFECStreamFrames(nil, 1000, protocol.FECProtectionDefault, false, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f1))
+			// stream 2 was skipped, so it's now the first one in the queue
+			stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f2}, true, false)
+			stream1.EXPECT().fecProtectionClass().Return(protocol.FECProtectionHigh, true)
+			frames, _ = framer.AppendFECStreamFrames(nil, 1000, protocol.FECProtectionDefault, false, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(f2.FECProtectionClass).To(Equal(protocol.FECProtectionLow))
+		})
+
+		It("only pops FEC protected STREAM frames of the given protection class", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			f := &wire.StreamFrame{Data: []byte("foobar")}
+			stream1.EXPECT().fecProtectionClass().Return(protocol.FECProtectionHigh, true)
+			stream2.EXPECT().fecProtectionClass().Return(protocol.FECProtectionDefault, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f}, true, false)
+			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id2)
+			frames, _ := framer.AppendFECStreamFrames(nil, 1000, protocol.FECProtectionLow, true, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f))
+			Expect(framer.HasData()).To(BeTrue())
+		})
+
+		It("applies a protection class changed after the class check to the next STREAM frame", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			f := &wire.StreamFrame{Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+			stream1.EXPECT().fecProtectionClass().Return(protocol.FECProtectionLow, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f}, true, false)
+			framer.AddActiveStream(id1)
+			frames, _ := framer.AppendFECStreamFrames(nil, 1000, protocol.FECProtectionLow, true, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(f.FECProtectionClass).To(Equal(protocol.FECProtectionLow))
+		})
+	})
 })
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/binary"
+	"log"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/quic-go/quic-go/fuzzing/fecdecoder"
+	"github.com/quic-go/quic-go/fuzzing/internal/helper"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const version = protocol.Version1
+
+func getRandomData(l int) []byte {
+	b := make([]byte, l)
+	rand.Read(b)
+	return b
+}
+
+// getSourceSymbols returns the input for the recovery mode of the fuzzer.
+func getSourceSymbols(num int, maxLen int) []byte {
+	var b []byte
+	for i := 0; i < num; i++ {
+		l := rand.Intn(maxLen) + 1
+		b = binary.BigEndian.AppendUint16(b, uint16(l-1))
+		b = append(b, getRandomData(l)...)
+	}
+	return b
+}
+
+// getFrames returns the input for the frame mode of the fuzzer.
+func getFrames(num int) []byte {
+	var b []byte
+	for i := 0; i < num; i++ {
+		var f wire.Frame
+		if rand.Intn(3) == 0 {
+			f = &wire.RepairFrame{
+				Metadata: protocol.BlockMetadata{
+					BlockID:  protocol.BlockID(rand.Intn(3)),
+					ParityID: protocol.ParityID(rand.Intn(10)),
+				},
+				Payload: getRandomData(rand.Intn(100) + protocol.RepairPayloadMetadataLen),
+			}
+		} else {
+			f = &wire.SourceSymbolFrame{
+				SSID:    protocol.SourceSymbolID(rand.Intn(60)),
+				Payload: getRandomData(rand.Intn(100) + 1),
+			}
+		}
+		var err error
+		b, err = f.Append(b, version)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	return b
+}
+
+func main() {
+	for prefix := byte(0); prefix < 2; prefix++ { // XOR and Reed-Solomon
+		for i := 0; i < 10; i++ {
+			lossMask := make([]byte, fecdecoder.LossMaskLen)
+			// lose about every 8th symbol
+			for j := range lossMask {
+				lossMask[j] = 1 << rand.Intn(8)
+			}
+			data := append([]byte{prefix}, lossMask...)
+			data = append(data, getSourceSymbols(rand.Intn(60)+1, protocol.MaxFECPacketBufferSize)...)
+			if err := helper.WriteCorpusFile("corpus", data); err != nil {
+				log.Fatal(err)
+			}
+		}
+		for i := 0; i < 10; i++ {
+			data := append([]byte{prefix | 0x2}, getFrames(rand.Intn(40)+1)...)
+			if err := helper.WriteCorpusFile("corpus", data); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package fecdecoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/quic-go/quic-go/fuzzing/internal/helper"
+	"github.com/quic-go/quic-go/internal/fec"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const version = protocol.Version1
+
+// PrefixLen is the number of bytes used for configuration
+const PrefixLen = 1
+
+// LossMaskLen is the number of bytes used to decide which symbols are lost.
+const LossMaskLen = 16
+
+// Fuzz fuzzes the FEC decoder.
+//
+// The first bit of the prefix selects the FEC scheme.
+// If the second bit is set, the remaining data is parsed as a sequence of REPAIR and SOURCE_SYMBOL frames,
+// which are passed to the decoder. Otherwise, the data is used to encode source symbols,
+// some of which are lost, and the decoder needs to recover the lost source symbols of every recoverable block.
+//
+//go:generate go run ./cmd/corpus.go
+func Fuzz(data []byte) int {
+	if len(data) < PrefixLen {
+		return 0
+	}
+	scheme := protocol.XORFECScheme
+	if helper.NthBit(data[0], 0) {
+		scheme = protocol.ReedSolomonFECScheme
+	}
+	if helper.NthBit(data[0], 1) {
+		return fuzzFrames(scheme, data[PrefixLen:])
+	}
+	return fuzzRecovery(scheme, data[PrefixLen:])
+}
+
+// fuzzFrames passes arbitrary FEC frames to the decoder.
+// The decoder may reject them, but it must not panic.
+func fuzzFrames(scheme protocol.DecoderFECScheme, data []byte) int {
+	receiver, err := fec.NewReceiver(scheme)
+	if err != nil {
+		panic(err)
+	}
+	parser := wire.NewFrameParser(false)
+
+	var numFrames int
+	for len(data) > 0 {
+		l, f, err := parser.ParseNext(data, protocol.Encryption1RTT, version)
+		if err != nil {
+			break
+		}
+		data = data[l:]
+		switch frame := f.(type) {
+		case *wire.RepairFrame:
+			numFrames++
+			receiver.HandleRepairFrame(frame)
+		case *wire.SourceSymbolFrame:
+			numFrames++
+			receiver.HandleSourceSymbolFrame(frame)
+		case *wire.StreamFrame:
+			frame.PutBack()
+		}
+	}
+	if numFrames == 0 {
+		return 0
+	}
+	return 1
+}
+
+// fuzzRecovery encodes source symbols, drops some of the source and repair symbols,
+// and checks that the decoder recovers exactly the lost source symbols of every recoverable block.
+func fuzzRecovery(scheme protocol.DecoderFECScheme, data []byte) int {
+	if len(data) < LossMaskLen {
+		return 0
+	}
+	lossMask := data[:LossMaskLen]
+	data = data[LossMaskLen:]
+	isLost := func(i int) bool {
+		return helper.NthBit(lossMask[(i/8)%LossMaskLen], i%8)
+	}
+
+	sender, err := fec.NewSender(scheme)
+	if err != nil {
+		panic(err)
+	}
+	receiver, err := fec.NewReceiver(scheme)
+	if err != nil {
+		panic(err)
+	}
+	numSourceSymbols, numRepairSymbols := blockSize(scheme)
+
+	// The symbols are received in the order they're sent:
+	// all source symbols of a block, followed by its repair symbols.
+	type block struct {
+		lostSources [][]byte
+		numRepairs  int
+	}
+	var blocks []*block
+	var numSymbols int
+	for len(data) >= 2 {
+		// The packet packer never sends empty SOURCE_SYMBOL frames.
+		l := int(binary.BigEndian.Uint16(data))%protocol.MaxFECPacketBufferSize + 1
+		data = data[2:]
+		if l > len(data) {
+			break
+		}
+		payload := make([]byte, l, protocol.MaxPacketBufferSize)
+		copy(payload, data[:l])
+		data = data[l:]
+
+		ssf := &wire.SourceSymbolFrame{SSID: sender.NextSSID(), Payload: payload}
+		if int(ssf.SSID)%numSourceSymbols == 0 {
+			blocks = append(blocks, &block{})
+		}
+		b := blocks[len(blocks)-1]
+		repairs, err := sender.AddSourceSymbolFrame(ssf)
+		if err != nil {
+			panic(fmt.Sprintf("error adding source symbol: %s", err))
+		}
+		if len(repairs) > 0 && len(repairs) != numRepairSymbols {
+			panic(fmt.Sprintf("expected %d repair symbols, got %d", numRepairSymbols, len(repairs)))
+		}
+
+		lost := isLost(numSymbols)
+		numSymbols++
+		if lost {
+			b.lostSources = append(b.lostSources, payload)
+		} else {
+			received, err := receiver.HandleSourceSymbolFrame(reparse(ssf).(*wire.SourceSymbolFrame))
+			if err != nil {
+				panic(fmt.Sprintf("error handling source symbol: %s", err))
+			}
+			if !bytes.Equal(received, payload) {
+				panic(fmt.Sprintf("source symbol %d: expected payload %x, got %x", ssf.SSID, payload, received))
+			}
+		}
+
+		var recovered []byte
+		for _, r := range repairs {
+			lost := isLost(numSymbols)
+			numSymbols++
+			if lost {
+				continue
+			}
+			b.numRepairs++
+			rec, err := receiver.HandleRepairFrame(reparse(r).(*wire.RepairFrame))
+			if err != nil {
+				panic(fmt.Sprintf("error handling repair symbol: %s", err))
+			}
+			if len(rec) > 0 {
+				if len(recovered) > 0 {
+					panic("recovered source symbols of the same block twice")
+				}
+				recovered = rec
+			}
+		}
+		if len(repairs) == 0 {
+			continue
+		}
+		var expected []byte
+		if b.numRepairs >= len(b.lostSources) {
+			expected = bytes.Join(b.lostSources, nil)
+		}
+		if !bytes.Equal(recovered, expected) {
+			panic(fmt.Sprintf("lost %d source symbols, received %d repair symbols: expected to recover %x, got %x", len(b.lostSources), b.numRepairs, expected, recovered))
+		}
+	}
+	if len(blocks) == 0 {
+		return 0
+	}
+	return 1
+}
+
+// reparse serializes and parses a frame, so that the decoder doesn't share any memory with the encoder.
+func reparse(f wire.Frame) wire.Frame {
+	b, err := f.Append(nil, version)
+	if err != nil {
+		panic(err)
+	}
+	_, parsed, err := wire.NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, version)
+	if err != nil {
+		panic(fmt.Sprintf("error parsing %#v: %s", f, err))
+	}
+	return parsed
+}
+
+func blockSize(scheme protocol.DecoderFECScheme) (numSourceSymbols, numRepairSymbols int) {
+	switch scheme {
+	case protocol.XORFECScheme:
+		return 2, 1
+	case protocol.ReedSolomonFECScheme:
+		return 20, 10
+	default:
+		panic(fmt.Sprintf("unexpected FEC scheme: %s", scheme))
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+
+	"golang.org/x/exp/rand"
+
+	"github.com/quic-go/quic-go/fuzzing/internal/helper"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+const version = protocol.Version1
+
+func getRandomData(l int) []byte {
+	b := make([]byte, l)
+	rand.Read(b)
+	return b
+}
+
+func getFrames() []wire.Frame {
+	return []wire.Frame{
+		&wire.RepairFrame{
+			Metadata: protocol.BlockMetadata{BlockID: protocol.BlockID(rand.Int63n(1000))},
+			Payload:  getRandomData(100),
+		},
+		&wire.RepairFrame{
+			Metadata: protocol.BlockMetadata{
+				BlockID:  protocol.BlockID(rand.Int63n(quicvarint.Max)),
+				ParityID: protocol.ParityID(rand.Intn(10)),
+			},
+			Payload: getRandomData(protocol.MaxFECPacketBufferSize + protocol.RepairPayloadMetadataLen),
+		},
+		&wire.RepairFrame{
+			Class:    protocol.FECProtectionHigh,
+			Metadata: protocol.BlockMetadata{BlockID: protocol.BlockID(rand.Int63n(1000))},
+			Payload:  getRandomData(100),
+		},
+		&wire.SourceSymbolFrame{
+			SSID:    protocol.SourceSymbolID(rand.Int63n(1000)),
+			Payload: getRandomData(100),
+		},
+		&wire.SourceSymbolFrame{
+			SSID:    protocol.SourceSymbolID(rand.Int63n(quicvarint.Max)),
+			Payload: getRandomData(protocol.MaxFECPacketBufferSize),
+		},
+		&wire.SourceSymbolFrame{
+			SSID: protocol.SourceSymbolID(rand.Int63n(1000)),
+		},
+		&wire.SourceSymbolFrame{
+			Class:   protocol.FECProtectionLow,
+			SSID:    protocol.SourceSymbolID(rand.Int63n(1000)),
+			Payload: getRandomData(100),
+		},
+		&wire.RepairFrame{
+			Format: protocol.FECWireFormatDraft,
+			Metadata: protocol.BlockMetadata{
+				BlockID:  protocol.BlockID(rand.Uint32()),
+				ParityID: protocol.ParityID(rand.Intn(10)),
+			},
+			Payload: getRandomData(100),
+		},
+		&wire.SourceSymbolFrame{
+			Format:  protocol.FECWireFormatDraft,
+			SSID:    protocol.SourceSymbolID(rand.Uint32()),
+			Payload: getRandomData(100),
+		},
+		&wire.FECWindowFrame{
+			Epoch: protocol.FECWindowEpoch(rand.Uint32()),
+			Size:  protocol.FECWindowSize(rand.Uint32()),
+		},
+	}
+}
+
+func main() {
+	for _, f := range getFrames() {
+		b, err := f.Append(nil, version)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := helper.WriteCorpusFile("corpus", b); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 30; i++ {
+		frames := getFrames()
+
+		var b []byte
+		for j := 0; j < rand.Intn(10)+2; j++ {
+			if rand.Intn(10) == 0 { // write a PADDING frame
+				b = append(b, 0)
+			}
+			var err error
+			b, err = frames[rand.Intn(len(frames))].Append(b, version)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := helper.WriteCorpusFile("corpus", b); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package fecframes
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const version = protocol.Version1
+
+// Fuzz fuzzes the REPAIR, SOURCE_SYMBOL and FEC_WINDOW frames.
+// Every FEC frame that is parsed is serialized and parsed again, and needs to result in the same frame.
+//
+//go:generate go run ./cmd/corpus.go
+func Fuzz(data []byte) int {
+	parser := wire.NewFrameParser(true)
+
+	var numFrames int
+	for len(data) > 0 {
+		l, f, err := parser.ParseNext(data, protocol.Encryption1RTT, version)
+		if err != nil {
+			break
+		}
+		data = data[l:]
+		switch frame := f.(type) {
+		case *wire.RepairFrame, *wire.FECWindowFrame:
+		case *wire.SourceSymbolFrame:
+			if frame.HeaderLen()+protocol.ByteCount(len(frame.Payload)) != frame.Length(version) {
+				panic(fmt.Sprintf("inconsistent SOURCE_SYMBOL header length for %#v", frame))
+			}
+		case *wire.StreamFrame:
+			frame.PutBack()
+			continue
+		default:
+			continue
+		}
+		numFrames++
+
+		b, err := f.Append(nil, version)
+		if err != nil {
+			panic(fmt.Sprintf("error writing frame %#v: %s", f, err))
+		}
+		if f.Length(version) != protocol.ByteCount(len(b)) {
+			panic(fmt.Sprintf("inconsistent frame length for %#v: expected %d, got %d", f, len(b), f.Length(version)))
+		}
+		if len(b) > l {
+			panic(fmt.Sprintf("serialized length (%d) is longer than parsed length (%d)", len(b), l))
+		}
+		parsedLen, parsed, err := parser.ParseNext(b, protocol.Encryption1RTT, version)
+		if err != nil {
+			panic(fmt.Sprintf("error parsing serialized frame %#v: %s", f, err))
+		}
+		if parsedLen != len(b) {
+			panic(fmt.Sprintf("parsed %d of %d bytes of serialized frame %#v", parsedLen, len(b), f))
+		}
+		if !reflect.DeepEqual(f, parsed) {
+			panic(fmt.Sprintf("frame changed after round trip: %#v vs. %#v", f, parsed))
+		}
+	}
+
+	if numFrames == 0 {
+		return 0
+	}
+	return 1
+}
@@ -2,6 +2,7 @@ package http3
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net"
 
@@ -81,11 +82,15 @@ type hijackableBody struct {
 	// either when Read() errors, or when Close() is called.
 	reqDone       chan<- struct{}
 	reqDoneClosed bool
+
+	// only set for the http.Response, used to implement the PriorityUpdater
+	updatePriority func(Priority) error
 }
 
 var (
-	_ Hijacker     = &hijackableBody{}
-	_ HTTPStreamer = &hijackableBody{}
+	_ Hijacker        = &hijackableBody{}
+	_ HTTPStreamer    = &hijackableBody{}
+	_ PriorityUpdater = &hijackableBody{}
 )
 
 func newResponseBody(str Stream, conn quic.Connection, done chan<- struct{}) *hijackableBody {
@@ -134,3 +139,10 @@ func (r *hijackableBody) Close() error {
 func (r *hijackableBody) HTTPStream() Stream {
 	return r.str
 }
+
+func (r *hijackableBody) UpdatePriority(p Priority) error {
+	if r.updatePriority == nil {
+		return errors.New("http3: cannot update the priority of this request")
+	}
+	return r.updatePriority(p)
+}
@@ -61,6 +61,10 @@ type client struct {
 	receivedSettings chan struct{} // closed once the server's SETTINGS frame was processed
 	settings         *Settings     // set once receivedSettings is closed
 
+	controlStrOpened chan struct{}   // closed once our SETTINGS frame was sent on the control stream
+	controlStr       quic.SendStream // set once controlStrOpened is closed
+	controlStrMutex  sync.Mutex      // serializes writes of PRIORITY_UPDATE frames
+
 	requestWriter *requestWriter
 
 	decoder *qpack.Decoder
@@ -114,6 +118,7 @@ func newClient(hostname string, tlsConf *tls.Config, opts *roundTripperOpts, con
 		tlsConf:          tlsConf,
 		requestWriter:    newRequestWriter(logger),
 		receivedSettings: make(chan struct{}),
+		controlStrOpened: make(chan struct{}),
 		decoder:          qpack.NewDecoder(func(hf qpack.HeaderField) {}),
 		config:           conf,
 		opts:             opts,
@@ -160,7 +165,24 @@ func (c *client) setupConn(conn quic.EarlyConnection) error {
 	b = quicvarint.Append(b, streamTypeControlStream)
 	// send the SETTINGS frame
 	b = (&settingsFrame{Datagram: c.opts.EnableDatagram, Other: c.opts.AdditionalSettings}).Append(b)
-	_, err = str.Write(b)
+	if _, err := str.Write(b); err != nil {
+		return err
+	}
+	c.controlStr = str
+	close(c.controlStrOpened)
+	return nil
+}
+
+// sendPriorityUpdate sends a PRIORITY_UPDATE frame for a request stream on the control stream.
+func (c *client) sendPriorityUpdate(conn quic.EarlyConnection, id quic.StreamID, p Priority) error {
+	select {
+	case <-c.controlStrOpened:
+	case <-conn.Context().Done():
+		return context.Cause(conn.Context())
+	}
+	c.controlStrMutex.Lock()
+	defer c.controlStrMutex.Unlock()
+	_, err := c.controlStr.Write((&priorityUpdateFrame{StreamID: id, Priority: p.String()}).Append(nil))
 	return err
 }
 
@@ -421,6 +443,9 @@ func (c *client) doRequest(req *http.Request, conn quic.EarlyConnection, str qui
 	if !c.opts.DisableCompression && req.Method != "HEAD" && req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
 		requestGzip = true
 	}
+	if prio, ok := priorityFromHeader(req.Header); ok {
+		str.SetPriority(prio.streamPriority())
+	}
 	if err := c.requestWriter.WriteRequestHeader(str, req, requestGzip); err != nil {
 		return nil, newStreamError(ErrCodeInternalError, err)
 	}
@@ -485,6 +510,10 @@ func (c *client) doRequest(req *http.Request, conn quic.EarlyConnection, str qui
 		httpStr = hstr
 	}
 	respBody := newResponseBody(httpStr, conn, reqDone)
+	respBody.updatePriority = func(p Priority) error {
+		str.SetPriority(p.streamPriority())
+		return c.sendPriorityUpdate(conn, str.StreamID(), p)
+	}
 
 	// Rules for when to set Content-Length are defined in https://tools.ietf.org/html/rfc7230#section-3.3.2.
 	_, hasTransferEncoding := res.Header["Transfer-Encoding"]
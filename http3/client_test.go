@@ -733,6 +733,7 @@ var _ = Describe("Client", func() {
 			req                  *http.Request
 			str                  *mockquic.MockStream
 			conn                 *mockquic.MockEarlyConnection
+			controlStr           *mockquic.MockStream
 			settingsFrameWritten chan struct{}
 		)
 		testDone := make(chan struct{})
@@ -768,7 +769,7 @@ var _ = Describe("Client", func() {
 
 		BeforeEach(func() {
 			settingsFrameWritten = make(chan struct{})
-			controlStr := mockquic.NewMockStream(mockCtrl)
+			controlStr = mockquic.NewMockStream(mockCtrl)
 			controlStr.EXPECT().Write(gomock.Any()).Do(func(b []byte) (int, error) {
 				defer GinkgoRecover()
 				r := bytes.NewReader(b)
@@ -842,6 +843,36 @@ var _ = Describe("Client", func() {
 			Expect(rsp.Request).ToNot(BeNil())
 		})
 
+		It("sends priority signals", func() {
+			req.Header.Set("Priority", "u=1")
+			rspBuf := bytes.NewBuffer(getResponse(200))
+			gomock.InOrder(
+				conn.EXPECT().HandshakeComplete().Return(handshakeChan),
+				conn.EXPECT().OpenStreamSync(context.Background()).Return(str, nil),
+				conn.EXPECT().ConnectionState().Return(quic.ConnectionState{}),
+			)
+			conn.EXPECT().Context().Return(context.Background()).AnyTimes()
+			str.EXPECT().StreamID().Return(quic.StreamID(8)).AnyTimes()
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 1, Weight: 1})
+			str.EXPECT().Write(gomock.Any()).AnyTimes().DoAndReturn(func(p []byte) (int, error) { return len(p), nil })
+			str.EXPECT().Close()
+			str.EXPECT().Read(gomock.Any()).DoAndReturn(rspBuf.Read).AnyTimes()
+			rsp, err := cl.RoundTripOpt(req, RoundTripOpt{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rsp.Body).To(BeAssignableToTypeOf(&hijackableBody{}))
+
+			Eventually(settingsFrameWritten).Should(BeClosed())
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 5, Incremental: true, Weight: 1})
+			controlStr.EXPECT().Write(gomock.Any()).DoAndReturn(func(b []byte) (int, error) {
+				defer GinkgoRecover()
+				frame, err := parseNextFrame(bytes.NewReader(b), nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(frame).To(Equal(&priorityUpdateFrame{StreamID: 8, Priority: "u=5, i"}))
+				return len(b), nil
+			})
+			Expect(rsp.Body.(PriorityUpdater).UpdatePriority(Priority{Urgency: 5, Incremental: true})).To(Succeed())
+		})
+
 		It("doesn't close the request stream, with DontCloseRequestStream set", func() {
 			rspBuf := bytes.NewBuffer(getResponse(418))
 			gomock.InOrder(
@@ -62,6 +62,9 @@ func parseNextFrame(r io.Reader, unknownFrameHandler unknownFrameHandlerFunc) (f
 		case 0x5: // PUSH_PROMISE
 		case 0x7: // GOAWAY
 		case 0xd: // MAX_PUSH_ID
+		case frameTypePriorityUpdateRequest:
+			return parsePriorityUpdateFrame(r, l)
+		case frameTypePriorityUpdatePush: // we don't support server push
 		}
 		// skip over unknown frames
 		if _, err := io.CopyN(io.Discard, qr, int64(l)); err != nil {
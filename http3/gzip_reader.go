@@ -6,6 +6,7 @@ package http3
 // call gzip.NewReader on the first call to Read
 import (
 	"compress/gzip"
+	"errors"
 	"io"
 )
 
@@ -37,3 +38,12 @@ func (gz *gzipReader) Read(p []byte) (n int, err error) {
 func (gz *gzipReader) Close() error {
 	return gz.body.Close()
 }
+
+// UpdatePriority implements the PriorityUpdater, if the underlying body does.
+func (gz *gzipReader) UpdatePriority(p Priority) error {
+	pu, ok := gz.body.(PriorityUpdater)
+	if !ok {
+		return errors.New("http3: cannot update the priority of this request")
+	}
+	return pu.UpdatePriority(p)
+}
@@ -0,0 +1,230 @@
+package http3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A Priority is the priority of a HTTP request, as defined by the Extensible Prioritization Scheme (RFC 9218).
+// It is carried in the Priority request header field and in PRIORITY_UPDATE frames.
+type Priority struct {
+	// Urgency is the urgency of the response, from 0 (most urgent) to 7 (least urgent).
+	Urgency uint8
+	// Incremental says if the client can process the response incrementally, i.e. before it was received completely.
+	// Incremental responses of the same urgency share the bandwidth,
+	// non-incremental responses are sent one after the other.
+	Incremental bool
+}
+
+// DefaultPriority is the priority of a request that doesn't carry any priority signal.
+// Unlike the quic.DefaultStreamPriority, responses are not incremental by default (see section 4 of RFC 9218).
+var DefaultPriority = Priority{Urgency: 3, Incremental: false}
+
+const maxUrgency = 7
+
+// ParsePriority parses the value of a Priority header field, or of a PRIORITY_UPDATE frame.
+// Parameters that are missing or invalid take their default value, and unknown parameters are ignored.
+func ParsePriority(s string) Priority {
+	p := DefaultPriority
+	for _, member := range strings.Split(s, ",") {
+		member = strings.Trim(member, " \t")
+		// ignore the parameters of the dictionary member
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		key, val, hasVal := strings.Cut(member, "=")
+		switch key {
+		case "u":
+			if u, err := strconv.ParseUint(val, 10, 8); err == nil && u <= maxUrgency {
+				p.Urgency = uint8(u)
+			}
+		case "i":
+			switch {
+			case !hasVal || val == "?1":
+				p.Incremental = true
+			case val == "?0":
+				p.Incremental = false
+			}
+		}
+	}
+	return p
+}
+
+// String returns the value of the Priority header field.
+// Parameters that have their default value are omitted, so the string is empty for the DefaultPriority.
+func (p Priority) String() string {
+	var params []string
+	if p.Urgency != DefaultPriority.Urgency {
+		params = append(params, "u="+strconv.Itoa(int(p.Urgency)))
+	}
+	if p.Incremental {
+		params = append(params, "i")
+	}
+	return strings.Join(params, ", ")
+}
+
+func (p Priority) streamPriority() quic.StreamPriority {
+	return quic.StreamPriority{Urgency: p.Urgency, Incremental: p.Incremental, Weight: 1}
+}
+
+// priorityFromHeader returns the priority signaled in the Priority header field, if any.
+// The field value might be split across multiple header lines.
+func priorityFromHeader(h http.Header) (Priority, bool) {
+	values := h.Values("Priority")
+	if len(values) == 0 {
+		return Priority{}, false
+	}
+	return ParsePriority(strings.Join(values, ",")), true
+}
+
+// A PriorityUpdater allows changing the priority of a request after it was sent.
+// It is implemented by the http.Response.Body returned by the RoundTripper.
+// UpdatePriority sends a PRIORITY_UPDATE frame to the server.
+type PriorityUpdater interface {
+	UpdatePriority(Priority) error
+}
+
+const (
+	frameTypePriorityUpdateRequest = 0xf0700
+	frameTypePriorityUpdatePush    = 0xf0701
+)
+
+// The priority field value is a short string. Anything larger than this is most likely an attack.
+const maxPriorityUpdateFrameSize = 1 << 10
+
+// A PRIORITY_UPDATE frame, as defined in section 7.1 of RFC 9218.
+// We don't support server push, so it always references a request stream.
+type priorityUpdateFrame struct {
+	StreamID quic.StreamID
+	Priority string
+}
+
+func parsePriorityUpdateFrame(r io.Reader, l uint64) (*priorityUpdateFrame, error) {
+	if l > maxPriorityUpdateFrameSize {
+		return nil, fmt.Errorf("unexpected size for PRIORITY_UPDATE frame: %d", l)
+	}
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	b := bytes.NewReader(buf)
+	id, err := quicvarint.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	return &priorityUpdateFrame{StreamID: quic.StreamID(id), Priority: string(buf[len(buf)-b.Len():])}, nil
+}
+
+func (f *priorityUpdateFrame) Append(b []byte) []byte {
+	b = quicvarint.Append(b, frameTypePriorityUpdateRequest)
+	b = quicvarint.Append(b, uint64(quicvarint.Len(uint64(f.StreamID)))+uint64(len(f.Priority)))
+	b = quicvarint.Append(b, uint64(f.StreamID))
+	return append(b, f.Priority...)
+}
+
+var errInvalidPriorityUpdate = errors.New("PRIORITY_UPDATE for a stream that is not a client-initiated bidirectional stream")
+
+// PRIORITY_UPDATE frames can arrive before the request stream.
+// We remember a limited number of them, and ignore the rest.
+const maxPendingPriorityUpdates = 64
+
+// requestPriorities tracks the request streams of a connection,
+// so that PRIORITY_UPDATE frames received on the control stream can be applied to them.
+type requestPriorities struct {
+	mutex sync.Mutex
+
+	streams map[quic.StreamID]quic.Stream
+	// the stream ID of the highest request stream that was accepted
+	highestStreamID quic.StreamID
+	// Priorities received in PRIORITY_UPDATE frames.
+	// For accepted streams, they take precedence over the Priority header field.
+	updates map[quic.StreamID]Priority
+}
+
+func newRequestPriorities() *requestPriorities {
+	return &requestPriorities{
+		streams:         make(map[quic.StreamID]quic.Stream),
+		highestStreamID: -1,
+		updates:         make(map[quic.StreamID]Priority),
+	}
+}
+
+// AddStream is called when a request stream is accepted.
+// The stream IDs must be passed in increasing order.
+func (p *requestPriorities) AddStream(str quic.Stream) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.streams[str.StreamID()] = str
+	p.highestStreamID = str.StreamID()
+	if prio, ok := p.updates[str.StreamID()]; ok {
+		str.SetPriority(prio.streamPriority())
+	}
+}
+
+// RemoveStream is called when the request was handled.
+func (p *requestPriorities) RemoveStream(id quic.StreamID) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.streams, id)
+	delete(p.updates, id)
+}
+
+// SetFromHeader applies the priority signaled in the request's header fields,
+// unless a PRIORITY_UPDATE frame was received for the stream.
+// Requests without a priority signal use the DefaultPriority,
+// which differs from the default priority of QUIC streams.
+func (p *requestPriorities) SetFromHeader(str quic.Stream, h http.Header) {
+	prio, ok := priorityFromHeader(h)
+	if !ok {
+		prio = DefaultPriority
+	}
+	if p != nil {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		if _, ok := p.updates[str.StreamID()]; ok {
+			return
+		}
+	}
+	str.SetPriority(prio.streamPriority())
+}
+
+// HandlePriorityUpdate applies the priority received in a PRIORITY_UPDATE frame.
+func (p *requestPriorities) HandlePriorityUpdate(f *priorityUpdateFrame) error {
+	if f.StreamID.InitiatedBy() != protocol.PerspectiveClient || f.StreamID.Type() != protocol.StreamTypeBidi {
+		return errInvalidPriorityUpdate
+	}
+	prio := ParsePriority(f.Priority)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if str, ok := p.streams[f.StreamID]; ok {
+		p.updates[f.StreamID] = prio
+		str.SetPriority(prio.streamPriority())
+		return nil
+	}
+	// The request was already handled.
+	if f.StreamID <= p.highestStreamID {
+		return nil
+	}
+	if _, ok := p.updates[f.StreamID]; ok || len(p.updates) < maxPendingPriorityUpdates {
+		p.updates[f.StreamID] = prio
+	}
+	return nil
+}
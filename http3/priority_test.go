@@ -0,0 +1,180 @@
+package http3
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/quic-go/quic-go"
+	mockquic "github.com/quic-go/quic-go/internal/mocks/quic"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priorities", func() {
+	Context("parsing", func() {
+		It("uses the default priority for an empty value", func() {
+			Expect(ParsePriority("")).To(Equal(DefaultPriority))
+		})
+
+		It("parses the urgency and the incremental flag", func() {
+			Expect(ParsePriority("u=5")).To(Equal(Priority{Urgency: 5}))
+			Expect(ParsePriority("i")).To(Equal(Priority{Urgency: 3, Incremental: true}))
+			Expect(ParsePriority("u=0, i")).To(Equal(Priority{Urgency: 0, Incremental: true}))
+			Expect(ParsePriority("i=?1,u=7")).To(Equal(Priority{Urgency: 7, Incremental: true}))
+			Expect(ParsePriority("u=1, i=?0")).To(Equal(Priority{Urgency: 1}))
+		})
+
+		It("uses the last value of a parameter", func() {
+			Expect(ParsePriority("u=1, i, u=2, i=?0")).To(Equal(Priority{Urgency: 2}))
+		})
+
+		It("ignores invalid and unknown parameters", func() {
+			Expect(ParsePriority("u=8, i=1")).To(Equal(DefaultPriority))
+			Expect(ParsePriority("u=-1")).To(Equal(DefaultPriority))
+			Expect(ParsePriority("u=foo, foo=bar, i")).To(Equal(Priority{Urgency: 3, Incremental: true}))
+		})
+
+		It("ignores the parameters of dictionary members", func() {
+			Expect(ParsePriority("u=4;foo=bar, i;baz")).To(Equal(Priority{Urgency: 4, Incremental: true}))
+		})
+
+		It("combines header fields split across multiple lines", func() {
+			h := http.Header{}
+			_, ok := priorityFromHeader(h)
+			Expect(ok).To(BeFalse())
+			h.Add("Priority", "u=1")
+			h.Add("Priority", "i")
+			prio, ok := priorityFromHeader(h)
+			Expect(ok).To(BeTrue())
+			Expect(prio).To(Equal(Priority{Urgency: 1, Incremental: true}))
+		})
+	})
+
+	It("serializes", func() {
+		Expect(DefaultPriority.String()).To(BeEmpty())
+		Expect(Priority{Urgency: 1}.String()).To(Equal("u=1"))
+		Expect(Priority{Urgency: 3, Incremental: true}.String()).To(Equal("i"))
+		Expect(Priority{Urgency: 6, Incremental: true}.String()).To(Equal("u=6, i"))
+		for u := uint8(0); u <= maxUrgency; u++ {
+			for _, inc := range []bool{true, false} {
+				p := Priority{Urgency: u, Incremental: inc}
+				Expect(ParsePriority(p.String())).To(Equal(p))
+			}
+		}
+	})
+
+	Context("PRIORITY_UPDATE frames", func() {
+		It("writes and parses", func() {
+			b := (&priorityUpdateFrame{StreamID: 1337, Priority: "u=2, i"}).Append(nil)
+			frame, err := parseNextFrame(bytes.NewReader(b), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(&priorityUpdateFrame{StreamID: 1337, Priority: "u=2, i"}))
+		})
+
+		It("skips PRIORITY_UPDATE frames for push streams", func() {
+			b := quicvarint.Append(nil, frameTypePriorityUpdatePush)
+			b = quicvarint.Append(b, 3)
+			b = append(b, 0x1, 'u', '=')
+			b = (&dataFrame{Length: 0x42}).Append(b)
+			frame, err := parseNextFrame(bytes.NewReader(b), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(&dataFrame{Length: 0x42}))
+		})
+
+		It("rejects frames that are too large", func() {
+			b := (&priorityUpdateFrame{StreamID: 4, Priority: string(bytes.Repeat([]byte{' '}, maxPriorityUpdateFrameSize))}).Append(nil)
+			_, err := parseNextFrame(bytes.NewReader(b), nil)
+			Expect(err).To(MatchError("unexpected size for PRIORITY_UPDATE frame: 1025"))
+		})
+
+		It("errors on EOF", func() {
+			b := (&priorityUpdateFrame{StreamID: 4, Priority: "u=1"}).Append(nil)
+			for i := range b {
+				_, err := parseNextFrame(bytes.NewReader(b[:i]), nil)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("tracking request priorities", func() {
+		var priorities *requestPriorities
+
+		newStream := func(id quic.StreamID) *mockquic.MockStream {
+			str := mockquic.NewMockStream(mockCtrl)
+			str.EXPECT().StreamID().Return(id).AnyTimes()
+			return str
+		}
+
+		BeforeEach(func() {
+			priorities = newRequestPriorities()
+		})
+
+		It("applies the priority from the header", func() {
+			str := newStream(4)
+			priorities.AddStream(str)
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 1, Incremental: true, Weight: 1})
+			priorities.SetFromHeader(str, http.Header{"Priority": []string{"u=1, i"}})
+		})
+
+		It("applies the default priority to requests without a Priority header", func() {
+			str := newStream(4)
+			priorities.AddStream(str)
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 3, Weight: 1})
+			priorities.SetFromHeader(str, http.Header{})
+		})
+
+		It("applies PRIORITY_UPDATE frames to accepted streams", func() {
+			str := newStream(4)
+			priorities.AddStream(str)
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 6, Weight: 1})
+			Expect(priorities.HandlePriorityUpdate(&priorityUpdateFrame{StreamID: 4, Priority: "u=6"})).To(Succeed())
+			// the PRIORITY_UPDATE takes precedence over the header
+			priorities.SetFromHeader(str, http.Header{"Priority": []string{"u=1"}})
+		})
+
+		It("applies PRIORITY_UPDATE frames received before the stream was accepted", func() {
+			Expect(priorities.HandlePriorityUpdate(&priorityUpdateFrame{StreamID: 8, Priority: "u=0"})).To(Succeed())
+			priorities.AddStream(newStream(4))
+			str := newStream(8)
+			str.EXPECT().SetPriority(quic.StreamPriority{Urgency: 0, Weight: 1})
+			priorities.AddStream(str)
+		})
+
+		It("ignores PRIORITY_UPDATE frames for requests that were already handled", func() {
+			str := newStream(4)
+			priorities.AddStream(str)
+			priorities.RemoveStream(4)
+			Expect(priorities.HandlePriorityUpdate(&priorityUpdateFrame{StreamID: 4, Priority: "u=0"})).To(Succeed())
+			Expect(priorities.updates).To(BeEmpty())
+		})
+
+		It("limits the number of PRIORITY_UPDATE frames for streams that weren't accepted yet", func() {
+			for i := 0; i < 2*maxPendingPriorityUpdates; i++ {
+				Expect(priorities.HandlePriorityUpdate(&priorityUpdateFrame{StreamID: quic.StreamID(4 * i), Priority: "u=0"})).To(Succeed())
+			}
+			Expect(priorities.updates).To(HaveLen(maxPendingPriorityUpdates))
+		})
+
+		It("rejects PRIORITY_UPDATE frames for streams that are not client-initiated bidirectional streams", func() {
+			for _, id := range []quic.StreamID{1, 2, 3} {
+				Expect(priorities.HandlePriorityUpdate(&priorityUpdateFrame{StreamID: id, Priority: "u=0"})).To(MatchError(errInvalidPriorityUpdate))
+			}
+		})
+	})
+
+	It("updates the priority of a response body", func() {
+		var prio Priority
+		rb := newResponseBody(nil, nil, nil)
+		rb.updatePriority = func(p Priority) error {
+			prio = p
+			return nil
+		}
+		var body io.ReadCloser = newGzipReader(rb)
+		Expect(body).To(BeAssignableToTypeOf(&gzipReader{}))
+		Expect(body.(PriorityUpdater).UpdatePriority(Priority{Urgency: 1})).To(Succeed())
+		Expect(prio).To(Equal(Priority{Urgency: 1}))
+	})
+})
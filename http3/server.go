@@ -459,7 +459,8 @@ func (s *Server) handleConn(conn quic.Connection) error {
 	}).Append(b)
 	str.Write(b)
 
-	go s.handleUnidirectionalStreams(conn)
+	priorities := newRequestPriorities()
+	go s.handleUnidirectionalStreams(conn, priorities)
 
 	// Process all requests immediately.
 	// It's the client's responsibility to decide which requests are eligible for 0-RTT.
@@ -472,8 +473,10 @@ func (s *Server) handleConn(conn quic.Connection) error {
 			}
 			return fmt.Errorf("accepting stream failed: %w", err)
 		}
+		priorities.AddStream(str)
 		go func() {
-			rerr := s.handleRequest(conn, str, decoder, func() {
+			defer priorities.RemoveStream(str.StreamID())
+			rerr := s.handleRequest(conn, str, decoder, priorities, func() {
 				conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeFrameUnexpected), "")
 			})
 			if rerr.err == errHijacked {
@@ -498,7 +501,7 @@ func (s *Server) handleConn(conn quic.Connection) error {
 	}
 }
 
-func (s *Server) handleUnidirectionalStreams(conn quic.Connection) {
+func (s *Server) handleUnidirectionalStreams(conn quic.Connection, priorities *requestPriorities) {
 	var rcvdControlStream atomic.Bool
 
 	for {
@@ -549,16 +552,42 @@ func (s *Server) handleUnidirectionalStreams(conn quic.Connection) {
 				conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeMissingSettings), "")
 				return
 			}
-			if !sf.Datagram {
+			if sf.Datagram {
+				// If datagram support was enabled on our side as well as on the client side,
+				// we can expect it to have been negotiated both on the transport and on the HTTP/3 layer.
+				// Note: ConnectionState() will block until the handshake is complete (relevant when using 0-RTT).
+				if s.EnableDatagrams && !conn.ConnectionState().SupportsDatagrams {
+					conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeSettingsError), "missing QUIC Datagram support")
+					return
+				}
+			}
+			s.handleControlStream(conn, str, priorities)
+		}(str)
+	}
+}
+
+// handleControlStream processes the frames following the SETTINGS frame on the client's control stream.
+func (s *Server) handleControlStream(conn quic.Connection, str quic.ReceiveStream, priorities *requestPriorities) {
+	for {
+		f, err := parseNextFrame(str, nil)
+		if err != nil {
+			if err == io.EOF || conn.Context().Err() != nil {
 				return
 			}
-			// If datagram support was enabled on our side as well as on the client side,
-			// we can expect it to have been negotiated both on the transport and on the HTTP/3 layer.
-			// Note: ConnectionState() will block until the handshake is complete (relevant when using 0-RTT).
-			if s.EnableDatagrams && !conn.ConnectionState().SupportsDatagrams {
-				conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeSettingsError), "missing QUIC Datagram support")
+			s.logger.Debugf("parsing frame on control stream failed: %s", err)
+			conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeFrameError), "")
+			return
+		}
+		switch f := f.(type) {
+		case *priorityUpdateFrame:
+			if err := priorities.HandlePriorityUpdate(f); err != nil {
+				conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeIDError), err.Error())
+				return
 			}
-		}(str)
+		default:
+			conn.CloseWithError(quic.ApplicationErrorCode(ErrCodeFrameUnexpected), fmt.Sprintf("unexpected %T on control stream", f))
+			return
+		}
 	}
 }
 
@@ -569,7 +598,7 @@ func (s *Server) maxHeaderBytes() uint64 {
 	return uint64(s.MaxHeaderBytes)
 }
 
-func (s *Server) handleRequest(conn quic.Connection, str quic.Stream, decoder *qpack.Decoder, onFrameError func()) requestError {
+func (s *Server) handleRequest(conn quic.Connection, str quic.Stream, decoder *qpack.Decoder, priorities *requestPriorities, onFrameError func()) requestError {
 	var ufh unknownFrameHandlerFunc
 	if s.StreamHijacker != nil {
 		ufh = func(ft FrameType, e error) (processed bool, err error) { return s.StreamHijacker(ft, conn, str, e) }
@@ -601,6 +630,7 @@ func (s *Server) handleRequest(conn quic.Connection, str quic.Stream, decoder *q
 	if err != nil {
 		return newStreamError(ErrCodeMessageError, err)
 	}
+	priorities.SetFromHeader(str, req.Header)
 
 	connState := conn.ConnectionState().TLS
 	req.TLS = &connState
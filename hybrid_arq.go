@@ -0,0 +1,472 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/fec"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+type repairSymbolState uint8
+
+const (
+	// the repair symbol was never sent, or it was lost
+	repairSymbolNotSent repairSymbolState = iota
+	repairSymbolInFlight
+	repairSymbolAcked
+)
+
+// sentFECBlock tracks the source and repair symbols of a block on the sender side.
+type sentFECBlock struct {
+	id protocol.BlockID
+
+	numSentSources int
+	// sourcePaths are the paths of a multipath connection that source symbols of the block were sent on
+	sourcePaths     pathSet
+	numAckedSources int
+	numLostSources  int
+	repairs         []repairSymbolState
+	// numExtraRepairs is the number of repair symbols sent in response to losses.
+	// It is limited, such that repeatedly lost repair symbols don't lead to an endless loop.
+	numExtraRepairs int
+
+	// lost are the lost source symbols that the peer is expected to recover using repair symbols.
+	// If the block is incomplete, they wait for the repair symbols that are generated once the block is complete.
+	lost []*sentSourceSymbol
+	// retransmit is set once the block can't be recovered by sending more repair symbols.
+	// From then on, the frames of lost source symbols are retransmitted.
+	retransmit bool
+	// closed is set if the sender started the next block before all source symbols of this block were sent.
+	// This happens when the maximum packet size changes.
+	closed bool
+}
+
+func (b *sentFECBlock) numAckedSymbols() int {
+	n := b.numAckedSources
+	for _, s := range b.repairs {
+		if s == repairSymbolAcked {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *sentFECBlock) numInFlightSymbols() int {
+	n := b.numSentSources - b.numAckedSources - b.numLostSources
+	for _, s := range b.repairs {
+		if s == repairSymbolInFlight {
+			n++
+		}
+	}
+	return n
+}
+
+// The hybridARQ decides how the frames of a lost SOURCE_SYMBOL frame are recovered.
+// As long as the peer can recover the lost source symbols of a block from additional repair symbols,
+// it sends repair symbols instead of retransmitting the frames.
+// The frames are only retransmitted if the block lost more source symbols than its repair symbols can recover,
+// or if no repair symbols can be sent for the block.
+type hybridARQ struct {
+	sender      fec.Sender
+	repairQueue *repairQueue
+
+	numSourceSymbols int
+	numRepairSymbols int
+
+	blocks map[protocol.BlockID]*sentFECBlock
+	// lastBlockID is the block that the last source symbol was added to
+	lastBlockID protocol.BlockID
+
+	// peerWindow is the size of the peer's decoding window, in source symbols.
+	peerWindow protocol.FECWindowSize
+	// highestAckedBlock is the highest block that a source or repair symbol was acknowledged for.
+	// The peer received a symbol of this block, so its decoding window extends at least up to this block.
+	highestAckedBlock protocol.BlockID
+}
+
+var _ ackhandler.FrameHandler = &hybridARQ{}
+
+func newHybridARQ(sender fec.Sender, repairQueue *repairQueue) *hybridARQ {
+	numSourceSymbols, numRepairSymbols := sender.BlockSize()
+	// All repair symbols of a block might be in the queue at the same time.
+	repairQueue.Reserve(numRepairSymbols)
+	return &hybridARQ{
+		sender:           sender,
+		repairQueue:      repairQueue,
+		numSourceSymbols: numSourceSymbols,
+		numRepairSymbols: numRepairSymbols,
+		blocks:           make(map[protocol.BlockID]*sentFECBlock),
+		peerWindow:       protocol.MaxFECDecodingWindow,
+	}
+}
+
+// SetPeerWindow sets the size of the peer's decoding window, as advertised in a FEC_WINDOW frame.
+func (h *hybridARQ) SetPeerWindow(size protocol.FECWindowSize) {
+	h.peerWindow = size
+}
+
+// CanSendSourceSymbol says if the next source symbol lies within the peer's decoding window.
+// The peer accepts symbols of blocks that are at most the size of its decoding window ahead of the highest block it received a symbol for.
+// Since the sender only knows about the symbols that were acknowledged, this is a conservative estimate.
+func (h *hybridARQ) CanSendSourceSymbol() bool {
+	windowBlocks := protocol.BlockID(max(int(h.peerWindow)/h.numSourceSymbols, 1))
+	return h.blockID(h.sender.PeekSSID()) <= h.highestAckedBlock+windowBlocks
+}
+
+func (h *hybridARQ) blockID(ssid protocol.SourceSymbolID) protocol.BlockID {
+	return protocol.BlockID(uint64(ssid) / uint64(h.numSourceSymbols))
+}
+
+// AddSourceSymbol passes a SOURCE_SYMBOL frame to the FEC sender, and queues the resulting REPAIR frames.
+func (h *hybridARQ) AddSourceSymbol(f *wire.SourceSymbolFrame) error {
+	repairFrames, err := h.sender.AddSourceSymbolFrame(f)
+	if err != nil {
+		return err
+	}
+	id := h.blockID(f.SSID)
+	b, ok := h.blocks[id]
+	if !ok {
+		// SSIDs are assigned in order, so no more source symbols are added to the previous block.
+		if prev, ok := h.blocks[h.lastBlockID]; ok && prev.id < id && prev.numSentSources < h.numSourceSymbols {
+			prev.closed = true
+			h.maybeRepair(prev)
+		}
+		b = &sentFECBlock{id: id, repairs: make([]repairSymbolState, h.numRepairSymbols)}
+		h.blocks[id] = b
+		h.lastBlockID = id
+	}
+	b.numSentSources++
+	// If the queue is full, the remaining repair symbols aren't sent.
+	// They can still be sent later, if source symbols of the block are lost.
+	repairFrames = repairFrames[:min(len(repairFrames), h.repairQueue.Available())]
+	for _, rf := range repairFrames {
+		if err := h.repairQueue.Add(rf); err != nil {
+			return err
+		}
+		b.repairs[rf.Metadata.ParityID] = repairSymbolInFlight
+	}
+	// The source symbols that were lost while the block was incomplete are recovered using the repair symbols.
+	if b.numSentSources == h.numSourceSymbols {
+		h.maybeRepair(b)
+	}
+	return nil
+}
+
+// RetransmitIncompleteBlock retransmits the frames of the source symbols that were lost from the block that is currently being sent.
+// It is called when no source symbol is added to the block, e.g. because there's no more FEC protected data to send.
+// The block might not be completed for a long time, and repair symbols are only generated for complete blocks.
+// It returns true if frames were retransmitted.
+func (h *hybridARQ) RetransmitIncompleteBlock() bool {
+	b, ok := h.blocks[h.lastBlockID]
+	if !ok || len(b.lost) == 0 || b.numSentSources >= h.numSourceSymbols {
+		return false
+	}
+	h.retransmit(b)
+	h.maybeDeleteBlock(b)
+	return true
+}
+
+// SentSourceSymbolOnPath records the path of a multipath connection that a source symbol is sent on.
+func (h *hybridARQ) SentSourceSymbolOnPath(ssid protocol.SourceSymbolID, pathID protocol.PathID) {
+	if b, ok := h.blocks[h.blockID(ssid)]; ok {
+		b.sourcePaths = b.sourcePaths.Add(pathID)
+	}
+}
+
+// SourcePaths returns the paths that source symbols of a block were sent on.
+func (h *hybridARQ) SourcePaths(id protocol.BlockID) pathSet {
+	if b, ok := h.blocks[id]; ok {
+		return b.sourcePaths
+	}
+	return 0
+}
+
+// SentSourceSymbol is called when a SOURCE_SYMBOL frame is packed.
+// It returns the frames that need to be tracked by the sent packet handler.
+// The handlers of the FEC protected frames are replaced, such that their loss can be handled by sending repair symbols.
+func (h *hybridARQ) SentSourceSymbol(
+	f *wire.SourceSymbolFrame,
+	frames []ackhandler.Frame,
+	streamFrames []ackhandler.StreamFrame,
+) ([]ackhandler.Frame, []ackhandler.StreamFrame) {
+	s := &sentSourceSymbol{
+		arq:          h,
+		ssid:         f.SSID,
+		frames:       frames,
+		streamFrames: streamFrames,
+	}
+	wrappedFrames := make([]ackhandler.Frame, 0, len(frames)+1)
+	wrappedFrames = append(wrappedFrames, ackhandler.Frame{Frame: f, Handler: s})
+	for _, f := range frames {
+		wrappedFrames = append(wrappedFrames, ackhandler.Frame{Frame: f.Frame, Handler: s})
+	}
+	wrappedStreamFrames := make([]ackhandler.StreamFrame, 0, len(streamFrames))
+	for _, f := range streamFrames {
+		wrappedStreamFrames = append(wrappedStreamFrames, ackhandler.StreamFrame{Frame: f.Frame, Handler: s})
+	}
+	return wrappedFrames, wrappedStreamFrames
+}
+
+// OnAcked is called when a REPAIR frame is acknowledged.
+func (h *hybridARQ) OnAcked(f wire.Frame) {
+	rf := f.(*wire.RepairFrame)
+	h.highestAckedBlock = max(h.highestAckedBlock, rf.Metadata.BlockID)
+	b, ok := h.blocks[rf.Metadata.BlockID]
+	if !ok {
+		return
+	}
+	b.repairs[rf.Metadata.ParityID] = repairSymbolAcked
+	h.maybeRepair(b)
+}
+
+// OnLost is called when a REPAIR frame is lost.
+func (h *hybridARQ) OnLost(f wire.Frame) {
+	rf := f.(*wire.RepairFrame)
+	b, ok := h.blocks[rf.Metadata.BlockID]
+	if !ok {
+		return
+	}
+	if b.repairs[rf.Metadata.ParityID] == repairSymbolInFlight {
+		b.repairs[rf.Metadata.ParityID] = repairSymbolNotSent
+	}
+	h.maybeRepair(b)
+}
+
+// DropRepairSymbol is called when a queued REPAIR frame doesn't fit into a packet.
+// This happens if the maximum packet size was reduced after the repair symbols of the block were generated,
+// so no more repair symbols can be sent for the block, and its lost source symbols are retransmitted.
+func (h *hybridARQ) DropRepairSymbol(rf *wire.RepairFrame) {
+	b, ok := h.blocks[rf.Metadata.BlockID]
+	if !ok {
+		return
+	}
+	if b.repairs[rf.Metadata.ParityID] == repairSymbolInFlight {
+		b.repairs[rf.Metadata.ParityID] = repairSymbolNotSent
+	}
+	h.retransmit(b)
+	h.maybeDeleteBlock(b)
+}
+
+func (h *hybridARQ) onSourceSymbolAcked(s *sentSourceSymbol) {
+	id := h.blockID(s.ssid)
+	h.highestAckedBlock = max(h.highestAckedBlock, id)
+	b, ok := h.blocks[id]
+	if !ok {
+		return
+	}
+	b.numAckedSources++
+	h.maybeRepair(b)
+}
+
+// onSourceSymbolLost decides if the frames of a lost source symbol are recovered by repair symbols.
+func (h *hybridARQ) onSourceSymbolLost(s *sentSourceSymbol) {
+	b, ok := h.blocks[h.blockID(s.ssid)]
+	if !ok {
+		return
+	}
+	b.numLostSources++
+	if b.retransmit {
+		h.maybeDeleteBlock(b)
+		return
+	}
+	s.recoverWithRepair = true
+	b.lost = append(b.lost, s)
+	h.maybeRepair(b)
+}
+
+// maybeRepair makes sure that enough symbols of the block are in flight for the peer to recover the lost source symbols.
+// If that's not possible, the frames of the lost source symbols are retransmitted.
+func (h *hybridARQ) maybeRepair(b *sentFECBlock) {
+	defer h.maybeDeleteBlock(b)
+
+	if len(b.lost) == 0 {
+		return
+	}
+	// The peer received enough symbols to recover the block.
+	if b.numAckedSymbols() >= h.numSourceSymbols {
+		for _, s := range b.lost {
+			s.recovered()
+		}
+		b.lost = nil
+		return
+	}
+	// Every repair symbol recovers one lost source symbol.
+	// If the block lost more source symbols than it has repair symbols, it can't be recovered.
+	if b.numLostSources > h.numRepairSymbols {
+		h.retransmit(b)
+		return
+	}
+	// Repair symbols are only generated once all source symbols of the block were sent.
+	if b.numSentSources < h.numSourceSymbols {
+		// A block that was closed early doesn't get any repair symbols.
+		if b.closed {
+			h.retransmit(b)
+		}
+		return
+	}
+	numMissing := h.numSourceSymbols - b.numAckedSymbols() - b.numInFlightSymbols()
+	if numMissing <= 0 {
+		return
+	}
+	if b.numExtraRepairs+numMissing > h.numRepairSymbols || numMissing > h.repairQueue.Available() {
+		h.retransmit(b)
+		return
+	}
+	var parityIDs []protocol.ParityID
+	for i, s := range b.repairs {
+		if s == repairSymbolNotSent && len(parityIDs) < numMissing {
+			parityIDs = append(parityIDs, protocol.ParityID(i))
+		}
+	}
+	if len(parityIDs) < numMissing {
+		h.retransmit(b)
+		return
+	}
+	repairFrames := make([]*wire.RepairFrame, 0, len(parityIDs))
+	for _, id := range parityIDs {
+		f, ok := h.sender.RepairSymbol(b.id, id)
+		if !ok {
+			h.retransmit(b)
+			return
+		}
+		repairFrames = append(repairFrames, f)
+	}
+	for _, f := range repairFrames {
+		if err := h.repairQueue.Add(f); err != nil {
+			h.retransmit(b)
+			return
+		}
+		b.repairs[f.Metadata.ParityID] = repairSymbolInFlight
+		b.numExtraRepairs++
+	}
+}
+
+func (h *hybridARQ) retransmit(b *sentFECBlock) {
+	b.retransmit = true
+	for _, s := range b.lost {
+		s.retransmit()
+	}
+	b.lost = nil
+}
+
+// maybeDeleteBlock stops tracking a block once no more symbols can be lost.
+func (h *hybridARQ) maybeDeleteBlock(b *sentFECBlock) {
+	if len(b.lost) > 0 || (b.numSentSources < h.numSourceSymbols && !b.closed) {
+		return
+	}
+	if b.numAckedSources+b.numLostSources == b.numSentSources {
+		delete(h.blocks, b.id)
+	}
+}
+
+// sentSourceSymbol handles the acknowledgement and the loss of a SOURCE_SYMBOL frame,
+// and of the FEC protected frames it contains.
+type sentSourceSymbol struct {
+	arq  *hybridARQ
+	ssid protocol.SourceSymbolID
+
+	// the FEC protected frames, with their original handlers
+	frames       []ackhandler.Frame
+	streamFrames []ackhandler.StreamFrame
+
+	lost bool
+	// recoverWithRepair is set if the peer is expected to recover the lost frames using repair symbols.
+	recoverWithRepair bool
+	// wasRecovered is set once the peer received enough symbols to recover the lost frames.
+	// This can already be the case when the first frame of the packet is declared lost,
+	// if the remaining symbols of the block were acknowledged by the same ACK frame.
+	wasRecovered bool
+	lostFrames   []wire.Frame
+	// called once it is known whether the peer recovered the lost frames
+	onLossResolved func(recovered bool)
+}
+
+var _ ackhandler.RecoverableFrameHandler = &sentSourceSymbol{}
+
+func (s *sentSourceSymbol) OnAcked(f wire.Frame) {
+	if _, ok := f.(*wire.SourceSymbolFrame); ok {
+		s.arq.onSourceSymbolAcked(s)
+		return
+	}
+	if h := s.handler(f); h != nil {
+		h.OnAcked(f)
+	}
+}
+
+func (s *sentSourceSymbol) OnLost(f wire.Frame) {
+	// All frames of a packet are declared lost at the same time,
+	// so the decision is made when the first of them is declared lost.
+	if !s.lost {
+		s.lost = true
+		s.arq.onSourceSymbolLost(s)
+		if !s.recoverWithRepair {
+			s.lossResolved(false)
+		}
+	}
+	if _, ok := f.(*wire.SourceSymbolFrame); ok {
+		return
+	}
+	if s.recoverWithRepair {
+		s.lostFrames = append(s.lostFrames, f)
+		return
+	}
+	h := s.handler(f)
+	if h == nil {
+		return
+	}
+	if s.wasRecovered {
+		h.OnAcked(f)
+		return
+	}
+	h.OnLost(f)
+}
+
+func (s *sentSourceSymbol) OnLossResolved(f func(recovered bool)) {
+	s.onLossResolved = f
+}
+
+func (s *sentSourceSymbol) lossResolved(recovered bool) {
+	if s.onLossResolved != nil {
+		s.onLossResolved(recovered)
+		s.onLossResolved = nil
+	}
+}
+
+// recovered is called once the peer received enough symbols to recover the lost frames.
+func (s *sentSourceSymbol) recovered() {
+	s.recoverWithRepair = false
+	s.wasRecovered = true
+	s.lossResolved(true)
+	for _, f := range s.lostFrames {
+		if h := s.handler(f); h != nil {
+			h.OnAcked(f)
+		}
+	}
+	s.lostFrames = nil
+}
+
+// retransmit is called if the peer can't recover the lost frames.
+func (s *sentSourceSymbol) retransmit() {
+	s.recoverWithRepair = false
+	s.lossResolved(false)
+	for _, f := range s.lostFrames {
+		if h := s.handler(f); h != nil {
+			h.OnLost(f)
+		}
+	}
+	s.lostFrames = nil
+}
+
+func (s *sentSourceSymbol) handler(f wire.Frame) ackhandler.FrameHandler {
+	for _, frame := range s.frames {
+		if frame.Frame == f {
+			return frame.Handler
+		}
+	}
+	for _, frame := range s.streamFrames {
+		if sf, ok := f.(*wire.StreamFrame); ok && frame.Frame == sf {
+			return frame.Handler
+		}
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/fec"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type recordingFrameHandler struct {
+	acked, lost []wire.Frame
+}
+
+func (h *recordingFrameHandler) OnAcked(f wire.Frame) { h.acked = append(h.acked, f) }
+func (h *recordingFrameHandler) OnLost(f wire.Frame)  { h.lost = append(h.lost, f) }
+
+var _ = Describe("Hybrid ARQ", func() {
+	var (
+		arq     *hybridARQ
+		queue   *repairQueue
+		handler *recordingFrameHandler
+	)
+
+	type sentSymbol struct {
+		ssf          ackhandler.Frame
+		frames       []ackhandler.Frame
+		streamFrames []ackhandler.StreamFrame
+	}
+
+	setup := func(scheme protocol.DecoderFECScheme) {
+		sender, err := fec.NewSender(scheme)
+		Expect(err).ToNot(HaveOccurred())
+		queue = newRepairQueue(func() {})
+		arq = newHybridARQ(sender, queue)
+		handler = &recordingFrameHandler{}
+	}
+
+	// sendSymbol packs a SOURCE_SYMBOL frame containing a STREAM frame
+	sendSymbol := func(ssid protocol.SourceSymbolID) sentSymbol {
+		sf := &wire.StreamFrame{StreamID: 4, Offset: protocol.ByteCount(ssid) * 100, Data: make([]byte, 100), DataLenPresent: true}
+		payload := make([]byte, 0, protocol.MaxPacketBufferSize)
+		payload, err := sf.Append(payload, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		ssf := &wire.SourceSymbolFrame{SSID: ssid, Payload: payload}
+		Expect(arq.AddSourceSymbol(ssf)).To(Succeed())
+		frames, streamFrames := arq.SentSourceSymbol(ssf, nil, []ackhandler.StreamFrame{{Frame: sf, Handler: handler}})
+		Expect(frames).To(HaveLen(1))
+		Expect(streamFrames).To(HaveLen(1))
+		return sentSymbol{ssf: frames[0], streamFrames: streamFrames}
+	}
+
+	ack := func(s sentSymbol) {
+		s.ssf.Handler.OnAcked(s.ssf.Frame)
+		for _, f := range s.streamFrames {
+			f.Handler.OnAcked(f.Frame)
+		}
+	}
+
+	lose := func(s sentSymbol) {
+		s.ssf.Handler.OnLost(s.ssf.Frame)
+		for _, f := range s.streamFrames {
+			f.Handler.OnLost(f.Frame)
+		}
+	}
+
+	dequeueRepairFrames := func() []*wire.RepairFrame {
+		var frames []*wire.RepairFrame
+		for f := queue.Peek(); f != nil; f = queue.Peek() {
+			frames = append(frames, f)
+			queue.Pop()
+		}
+		return frames
+	}
+
+	Context("Reed-Solomon", func() {
+		BeforeEach(func() { setup(protocol.ReedSolomonFECScheme) })
+
+		It("queues the repair symbols once a block is complete", func() {
+			for i := 0; i < 19; i++ {
+				sendSymbol(protocol.SourceSymbolID(i))
+			}
+			Expect(dequeueRepairFrames()).To(BeEmpty())
+			sendSymbol(19)
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(10))
+			for i, f := range repairs {
+				Expect(f.Metadata.BlockID).To(BeZero())
+				Expect(f.Metadata.ParityID).To(BeEquivalentTo(i))
+			}
+		})
+
+		It("doesn't retransmit frames if the repair symbols in flight suffice", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			lose(symbols[3])
+			lose(symbols[7])
+			Expect(handler.lost).To(BeEmpty())
+			Expect(dequeueRepairFrames()).To(BeEmpty())
+			// the peer has received 18 source symbols and 2 repair symbols
+			for i, s := range symbols {
+				if i != 3 && i != 7 {
+					ack(s)
+				}
+			}
+			Expect(handler.acked).To(HaveLen(18))
+			arq.OnAcked(repairs[0])
+			Expect(handler.acked).To(HaveLen(18))
+			arq.OnAcked(repairs[5])
+			Expect(handler.acked).To(HaveLen(20))
+			Expect(handler.acked).To(ContainElements(symbols[3].streamFrames[0].Frame, symbols[7].streamFrames[0].Frame))
+			Expect(handler.lost).To(BeEmpty())
+			Expect(arq.blocks).To(BeEmpty())
+		})
+
+		It("sends additional repair symbols when repair symbols are lost", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			lose(symbols[0])
+			for i := 1; i < 20; i++ {
+				ack(symbols[i])
+			}
+			for _, f := range repairs[:9] {
+				arq.OnLost(f)
+			}
+			Expect(dequeueRepairFrames()).To(BeEmpty())
+			arq.OnLost(repairs[9])
+			extra := dequeueRepairFrames()
+			Expect(extra).To(HaveLen(1))
+			Expect(extra[0].Metadata.ParityID).To(BeZero())
+			Expect(extra[0].Payload).To(Equal(repairs[0].Payload))
+
+			// the additional repair symbol is lost as well
+			arq.OnLost(extra[0])
+			extra = dequeueRepairFrames()
+			Expect(extra).To(HaveLen(1))
+			Expect(handler.lost).To(BeEmpty())
+			arq.OnAcked(extra[0])
+			Expect(handler.acked).To(ContainElement(symbols[0].streamFrames[0].Frame))
+			Expect(handler.lost).To(BeEmpty())
+		})
+
+		It("uses parity IDs beyond the ones sent for every block", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			for _, f := range repairs {
+				arq.OnAcked(f)
+			}
+			// 11 lost source symbols can't be recovered from the 10 repair symbols that were sent
+			for i := 0; i < 11; i++ {
+				lose(symbols[i])
+			}
+			extra := dequeueRepairFrames()
+			Expect(extra).To(HaveLen(1))
+			Expect(extra[0].Metadata.ParityID).To(BeEquivalentTo(10))
+			Expect(handler.lost).To(BeEmpty())
+		})
+
+		It("retransmits frames if too many source symbols are lost", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			// all repair symbols are lost
+			loseRepairFrames := func() {
+				for repairs := dequeueRepairFrames(); len(repairs) > 0; repairs = dequeueRepairFrames() {
+					for _, f := range repairs {
+						arq.OnLost(f)
+					}
+				}
+			}
+			loseRepairFrames()
+			for _, s := range symbols {
+				lose(s)
+				loseRepairFrames()
+			}
+			Expect(handler.lost).To(HaveLen(20))
+			Expect(handler.acked).To(BeEmpty())
+			Expect(arq.blocks).To(BeEmpty())
+		})
+
+		It("recovers source symbols lost from an incomplete block using repair symbols", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := 0; i < 5; i++ {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			lose(symbols[1])
+			lose(symbols[3])
+			Expect(handler.lost).To(BeEmpty())
+			Expect(dequeueRepairFrames()).To(BeEmpty())
+			for i := 5; i < 20; i++ {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(10))
+			for i, s := range symbols {
+				if i != 1 && i != 3 {
+					ack(s)
+				}
+			}
+			arq.OnAcked(repairs[0])
+			arq.OnAcked(repairs[1])
+			Expect(handler.acked).To(ContainElements(symbols[1].streamFrames[0].Frame, symbols[3].streamFrames[0].Frame))
+			Expect(handler.lost).To(BeEmpty())
+			Expect(arq.blocks).To(BeEmpty())
+		})
+
+		It("retransmits frames of an incomplete block if no more source symbols are added to it", func() {
+			Expect(arq.RetransmitIncompleteBlock()).To(BeFalse())
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			Expect(arq.RetransmitIncompleteBlock()).To(BeFalse())
+			lose(s1)
+			Expect(handler.lost).To(BeEmpty())
+			Expect(arq.RetransmitIncompleteBlock()).To(BeTrue())
+			Expect(handler.lost).To(Equal([]wire.Frame{s1.streamFrames[0].Frame}))
+			Expect(arq.RetransmitIncompleteBlock()).To(BeFalse())
+			// later losses are retransmitted right away
+			lose(s2)
+			Expect(handler.lost).To(HaveLen(2))
+		})
+
+		It("retransmits frames if a repair symbol can't be sent", func() {
+			symbols := make([]sentSymbol, 20)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			lose(symbols[0])
+			Expect(handler.lost).To(BeEmpty())
+			arq.DropRepairSymbol(repairs[0])
+			Expect(handler.lost).To(Equal([]wire.Frame{symbols[0].streamFrames[0].Frame}))
+			// later losses are retransmitted right away
+			lose(symbols[1])
+			Expect(handler.lost).To(HaveLen(2))
+			Expect(dequeueRepairFrames()).To(BeEmpty())
+		})
+	})
+
+	Context("XOR", func() {
+		BeforeEach(func() { setup(protocol.XORFECScheme) })
+
+		It("resends the repair symbol", func() {
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(1))
+			lose(s1)
+			ack(s2)
+			arq.OnLost(repairs[0])
+			extra := dequeueRepairFrames()
+			Expect(extra).To(HaveLen(1))
+			Expect(extra[0].Metadata).To(Equal(repairs[0].Metadata))
+			arq.OnAcked(extra[0])
+			Expect(handler.acked).To(ContainElement(s1.streamFrames[0].Frame))
+			Expect(handler.lost).To(BeEmpty())
+		})
+
+		It("doesn't retransmit frames if the block is recovered when the loss is detected", func() {
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(1))
+			// The ACK acknowledging the second source symbol and the repair symbol
+			// is processed before the first source symbol is declared lost.
+			ack(s2)
+			arq.OnAcked(repairs[0])
+			lose(s1)
+			Expect(handler.lost).To(BeEmpty())
+			Expect(handler.acked).To(ConsistOf(s1.streamFrames[0].Frame, s2.streamFrames[0].Frame))
+			Expect(arq.blocks).To(BeEmpty())
+		})
+
+		It("retransmits frames if both source symbols are lost", func() {
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			dequeueRepairFrames()
+			lose(s1)
+			Expect(handler.lost).To(BeEmpty())
+			lose(s2)
+			Expect(handler.lost).To(ConsistOf(s1.streamFrames[0].Frame, s2.streamFrames[0].Frame))
+		})
+
+		It("retransmits frames of blocks that were closed early", func() {
+			s1 := sendSymbol(0)
+			// the sender closed block 0, and started block 1
+			s2 := sendSymbol(2)
+			lose(s1)
+			Expect(handler.lost).To(Equal([]wire.Frame{s1.streamFrames[0].Frame}))
+			Expect(arq.blocks).ToNot(HaveKey(protocol.BlockID(0)))
+			ack(s2)
+			Expect(arq.blocks).To(HaveKey(protocol.BlockID(1)))
+		})
+
+		It("retransmits frames lost before the block was closed early", func() {
+			s1 := sendSymbol(0)
+			lose(s1)
+			Expect(handler.lost).To(BeEmpty())
+			// the sender closed block 0, and started block 1
+			sendSymbol(2)
+			Expect(handler.lost).To(Equal([]wire.Frame{s1.streamFrames[0].Frame}))
+			Expect(arq.blocks).ToNot(HaveKey(protocol.BlockID(0)))
+		})
+
+		It("only sends source symbols within the peer's decoding window", func() {
+			// The window covers 2 blocks beyond the highest block the peer received a symbol of.
+			arq.SetPeerWindow(4)
+			symbols := make([]sentSymbol, 6)
+			for i := range symbols {
+				Expect(arq.CanSendSourceSymbol()).To(BeTrue())
+				symbols[i] = sendSymbol(arq.sender.NextSSID())
+			}
+			Expect(arq.CanSendSourceSymbol()).To(BeFalse())
+			ack(symbols[2])
+			Expect(arq.CanSendSourceSymbol()).To(BeTrue())
+			sendSymbol(arq.sender.NextSSID())
+			sendSymbol(arq.sender.NextSSID())
+			Expect(arq.CanSendSourceSymbol()).To(BeFalse())
+			// acknowledging a repair symbol also moves the window
+			repairs := dequeueRepairFrames()
+			Expect(repairs).ToNot(BeEmpty())
+			arq.OnAcked(repairs[len(repairs)-1])
+			Expect(arq.CanSendSourceSymbol()).To(BeTrue())
+		})
+	})
+
+	Context("large-block Reed-Solomon", func() {
+		BeforeEach(func() { setup(protocol.LargeBlockReedSolomonFECScheme) })
+
+		It("queues all repair symbols of a block", func() {
+			numSourceSymbols, _ := arq.sender.BlockSize()
+			for i := 0; i < numSourceSymbols; i++ {
+				sendSymbol(protocol.SourceSymbolID(i))
+			}
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(200))
+			Expect(repairs[199].Metadata.ParityID).To(BeEquivalentTo(199))
+		})
+
+		It("only queues as many repair symbols as fit into the queue", func() {
+			numSourceSymbols, _ := arq.sender.BlockSize()
+			for queue.Available() > 50 {
+				Expect(queue.Add(&wire.RepairFrame{})).To(Succeed())
+			}
+			symbols := make([]sentSymbol, numSourceSymbols)
+			for i := range symbols {
+				symbols[i] = sendSymbol(protocol.SourceSymbolID(i))
+			}
+			Expect(queue.Available()).To(BeZero())
+			Expect(arq.blocks[0].numInFlightSymbols()).To(Equal(numSourceSymbols + 50))
+			// the repair symbols that didn't fit are sent once source symbols are lost
+			dequeueRepairFrames()
+			for _, s := range symbols[:60] {
+				lose(s)
+			}
+			for _, s := range symbols[60:] {
+				ack(s)
+			}
+			repairs := dequeueRepairFrames()
+			Expect(repairs).To(HaveLen(10))
+			Expect(repairs[0].Metadata.ParityID).To(BeEquivalentTo(50))
+			Expect(handler.lost).To(BeEmpty())
+		})
+	})
+
+	Context("resolving losses", func() {
+		BeforeEach(func() { setup(protocol.XORFECScheme) })
+
+		loseAndResolve := func(s sentSymbol) *[]bool {
+			var resolved []bool
+			h, ok := s.ssf.Handler.(ackhandler.RecoverableFrameHandler)
+			Expect(ok).To(BeTrue())
+			h.OnLossResolved(func(recovered bool) { resolved = append(resolved, recovered) })
+			lose(s)
+			return &resolved
+		}
+
+		It("reports losses that the peer recovered", func() {
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			repairs := dequeueRepairFrames()
+			resolved := loseAndResolve(s1)
+			Expect(*resolved).To(BeEmpty())
+			ack(s2)
+			arq.OnAcked(repairs[0])
+			Expect(*resolved).To(Equal([]bool{true}))
+		})
+
+		It("reports losses that the peer couldn't recover", func() {
+			s1 := sendSymbol(0)
+			s2 := sendSymbol(1)
+			dequeueRepairFrames()
+			resolved1 := loseAndResolve(s1)
+			resolved2 := loseAndResolve(s2)
+			Expect(*resolved1).To(Equal([]bool{false}))
+			Expect(*resolved2).To(Equal([]bool{false}))
+		})
+
+		It("records the paths that the source symbols of a block were sent on", func() {
+			Expect(arq.SourcePaths(0)).To(BeZero())
+			sendSymbol(0)
+			arq.SentSourceSymbolOnPath(0, 0)
+			sendSymbol(1)
+			arq.SentSourceSymbolOnPath(1, 2)
+			Expect(arq.SourcePaths(0).Contains(0)).To(BeTrue())
+			Expect(arq.SourcePaths(0).Contains(1)).To(BeFalse())
+			Expect(arq.SourcePaths(0).Contains(2)).To(BeTrue())
+			Expect(arq.SourcePaths(1)).To(BeZero())
+		})
+
+		It("reports losses of incomplete blocks once the frames are retransmitted", func() {
+			resolved := loseAndResolve(sendSymbol(0))
+			Expect(*resolved).To(BeEmpty())
+			Expect(arq.RetransmitIncompleteBlock()).To(BeTrue())
+			Expect(*resolved).To(Equal([]bool{false}))
+		})
+	})
+
+	It("passes on the acknowledgement of FEC protected frames without a handler", func() {
+		setup(protocol.XORFECScheme)
+		df := &wire.DatagramFrame{Data: []byte("foobar")}
+		ssf := &wire.SourceSymbolFrame{SSID: 0, Payload: make([]byte, 0, protocol.MaxPacketBufferSize)}
+		Expect(arq.AddSourceSymbol(ssf)).To(Succeed())
+		frames, _ := arq.SentSourceSymbol(ssf, []ackhandler.Frame{{Frame: df}}, nil)
+		Expect(frames).To(HaveLen(2))
+		frames[1].Handler.OnLost(df)
+		frames[1].Handler.OnAcked(df)
+	})
+})
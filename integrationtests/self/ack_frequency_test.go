@@ -0,0 +1,93 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency", func() {
+	// download transfers PRData from the server to the client,
+	// and returns the number of packets sent by the server and the number of ACKs it received.
+	download := func(enableAckFrequency bool) (numSent, numAcks, numAckFrequency int) {
+		serverCounter, serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: enableAckFrequency,
+				Tracer:             newTracer(serverTracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return 10 * time.Millisecond },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: enableAckFrequency}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+		for _, p := range serverCounter.getSentShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrequencyFrame); ok {
+					numAckFrequency++
+				}
+			}
+		}
+		for _, p := range serverCounter.getRcvdShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrame); ok {
+					numAcks++
+				}
+			}
+		}
+		return len(serverCounter.getSentShortHeaderPackets()), numAcks, numAckFrequency
+	}
+
+	It("reduces the number of ACKs", func() {
+		numSent, numAcks, numAckFrequency := download(false)
+		fmt.Fprintf(GinkgoWriter, "without ACK frequency: sent %d packets, received %d ACKs\n", numSent, numAcks)
+		Expect(numAckFrequency).To(BeZero())
+		numSentAckFrequency, numAcksAckFrequency, numAckFrequency := download(true)
+		fmt.Fprintf(GinkgoWriter, "with ACK frequency: sent %d packets, received %d ACKs, sent %d ACK_FREQUENCY frames\n", numSentAckFrequency, numAcksAckFrequency, numAckFrequency)
+		Expect(numAckFrequency).ToNot(BeZero())
+		// On the loopback interface, packets arrive in bursts, and the receiver often acknowledges multiple packets at once anyway.
+		Expect(float64(numAcksAckFrequency) / float64(numSentAckFrequency)).To(BeNumerically("<", float64(numAcks)/float64(numSent)))
+	})
+})
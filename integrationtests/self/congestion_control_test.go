@@ -0,0 +1,75 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/congestion"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A countingController is a congestion controller that counts the rate samples it receives.
+type countingController struct {
+	congestion.Controller
+
+	numSamples *atomic.Int64
+}
+
+func (c *countingController) OnPacketAcked(p congestion.AckedPacket) {
+	if p.HasRateSample && p.RateSample.DeliveryRate > 0 {
+		c.numSamples.Add(1)
+	}
+	c.Controller.OnPacketAcked(p)
+}
+
+var _ = Describe("Congestion Control", func() {
+	download := func(serverConf *quic.Config) {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(serverConf))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(context.Background(), server.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+	}
+
+	for _, cc := range []quic.CongestionControlAlgorithm{quic.CongestionControlReno, quic.CongestionControlCubic, quic.CongestionControlBBR} {
+		cc := cc
+
+		It("transfers data using "+cc.String(), func() {
+			download(&quic.Config{CongestionControl: cc})
+		})
+	}
+
+	It("uses a custom congestion controller", func() {
+		var numControllers, numSamples atomic.Int64
+		download(&quic.Config{
+			NewCongestionController: func(c congestion.Config) congestion.Controller {
+				numControllers.Add(1)
+				return &countingController{Controller: congestion.NewCubic(c), numSamples: &numSamples}
+			},
+		})
+		Expect(numControllers.Load()).To(BeEquivalentTo(1))
+		Expect(numSamples.Load()).To(BeNumerically(">", 10))
+	})
+})
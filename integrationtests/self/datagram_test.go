@@ -184,4 +184,50 @@ var _ = Describe("Datagram test", func() {
 		close()
 		conn.CloseWithError(0, "")
 	})
+
+	It("drops expired datagrams and datagrams that don't fit into the receive queue", func() {
+		const numDatagrams = 50
+		const rcvQueueLen = 10
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableDatagrams: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverStats := make(chan quic.DatagramStats, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.SendDatagramWithOptions([]byte("expired"), quic.DatagramOptions{Expiry: time.Now().Add(-time.Second)})).To(Succeed())
+			for i := 0; i < numDatagrams; i++ {
+				Expect(conn.SendDatagramWithOptions([]byte{byte(i)}, quic.DatagramOptions{Priority: 1})).To(Succeed())
+			}
+			Eventually(func() uint64 { return conn.DatagramStats().Sent }).Should(BeEquivalentTo(numDatagrams))
+			serverStats <- conn.DatagramStats()
+		}()
+
+		conn, err := quic.Dial(
+			context.Background(),
+			clientConn,
+			ln.Addr(),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true, DatagramReceiveQueueLen: rcvQueueLen}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		var stats quic.DatagramStats
+		Eventually(serverStats).Should(Receive(&stats))
+		Expect(stats.SendDroppedExpired).To(BeEquivalentTo(1))
+		// all datagrams are sent before the client starts reading
+		Eventually(func() uint64 {
+			s := conn.DatagramStats()
+			return s.Received + s.ReceiveDroppedQueueFull
+		}).Should(BeEquivalentTo(numDatagrams))
+		Expect(conn.DatagramStats().Received).To(BeEquivalentTo(rcvQueueLen))
+		for i := 0; i < rcvQueueLen; i++ {
+			data, err := conn.ReceiveDatagram(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte{byte(i)}))
+		}
+	})
 })
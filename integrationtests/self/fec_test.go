@@ -0,0 +1,726 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qtls"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fecLossInjector drops the 1-RTT packets sent by the server, if their frames match shouldDrop.
+// The proxy can't decrypt the packets, so the frames are taken from the server's tracer.
+// To match the packets seen by the proxy with the packets logged by the tracer, the proxy removes
+// the header protection using the server's 1-RTT traffic secret, and looks up the packet number.
+type fecLossInjector struct {
+	shouldDrop func([]logging.Frame) bool
+
+	mutex     sync.Mutex
+	hpKey     cipher.Block
+	connIDLen int
+	sent      map[logging.PacketNumber][]logging.Frame
+	largestPN logging.PacketNumber
+	dropped   map[logging.PacketNumber]struct{}
+	numLost   int
+}
+
+func newFECLossInjector(shouldDrop func([]logging.Frame) bool) *fecLossInjector {
+	return &fecLossInjector{
+		shouldDrop: shouldDrop,
+		sent:       make(map[logging.PacketNumber][]logging.Frame),
+		dropped:    make(map[logging.PacketNumber]struct{}),
+	}
+}
+
+func (l *fecLossInjector) tracer() *logging.ConnectionTracer {
+	return &logging.ConnectionTracer{
+		SentShortHeaderPacket: func(hdr *logging.ShortHeader, _ logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, frames []logging.Frame) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.connIDLen = hdr.DestConnectionID.Len()
+			l.sent[hdr.PacketNumber] = frames
+		},
+		LostPacket: func(logging.EncryptionLevel, logging.PacketNumber, logging.PacketLossReason) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.numLost++
+		},
+	}
+}
+
+// retransmitsDroppedStreamData says if a packet contains STREAM frames that overlap with STREAM frames of a dropped packet.
+// must be called with the mutex held
+func (l *fecLossInjector) retransmitsDroppedStreamData(pn logging.PacketNumber, frames []logging.Frame) bool {
+	for droppedPN := range l.dropped {
+		if droppedPN >= pn {
+			continue
+		}
+		for _, df := range l.sent[droppedPN] {
+			dsf, ok := df.(*logging.StreamFrame)
+			if !ok {
+				continue
+			}
+			for _, f := range frames {
+				sf, ok := f.(*logging.StreamFrame)
+				if !ok || sf.StreamID != dsf.StreamID {
+					continue
+				}
+				if sf.Offset < dsf.Offset+dsf.Length && dsf.Offset < sf.Offset+sf.Length {
+					return true
+				}
+				if sf.Fin && dsf.Fin {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// keyLogWriter is used as the server's tls.Config.KeyLogWriter.
+// It derives the header protection key of the 1-RTT packets sent by the server.
+// The cipher suite is set to TLS_AES_128_GCM_SHA256.
+func (l *fecLossInjector) keyLogWriter() io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		fields := strings.Fields(string(b))
+		if len(fields) != 3 || fields[0] != "SERVER_TRAFFIC_SECRET_0" {
+			return len(b), nil
+		}
+		secret, err := hex.DecodeString(fields[2])
+		if err != nil {
+			return 0, err
+		}
+		label := "quic hp"
+		if version == quic.Version2 {
+			label = "quicv2 hp"
+		}
+		block, err := aes.NewCipher(hkdfExpandLabel(secret, label, 16))
+		if err != nil {
+			return 0, err
+		}
+		l.mutex.Lock()
+		l.hpKey = block
+		l.mutex.Unlock()
+		return len(b), nil
+	})
+}
+
+func (l *fecLossInjector) dropPacket(dir quicproxy.Direction, data []byte) bool {
+	if dir != quicproxy.DirectionOutgoing {
+		return false
+	}
+	data = oneRTTPacket(data)
+	if data == nil {
+		return false
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	pn, ok := l.decodePacketNumber(data)
+	if !ok {
+		return false
+	}
+	frames, ok := l.sent[pn]
+	if !ok || !l.shouldDrop(frames) {
+		return false
+	}
+	l.dropped[pn] = struct{}{}
+	return true
+}
+
+// decodePacketNumber removes the header protection of a 1-RTT packet, and decodes the packet number.
+// must be called with the mutex held
+func (l *fecLossInjector) decodePacketNumber(data []byte) (logging.PacketNumber, bool) {
+	if l.hpKey == nil {
+		return 0, false
+	}
+	pnOffset := 1 + l.connIDLen
+	if len(data) < pnOffset+4+aes.BlockSize {
+		return 0, false
+	}
+	mask := make([]byte, aes.BlockSize)
+	l.hpKey.Encrypt(mask, data[pnOffset+4:pnOffset+4+aes.BlockSize])
+	pnLen := int((data[0]^mask[0])&0x3) + 1
+	var truncated logging.PacketNumber
+	for i := 0; i < pnLen; i++ {
+		truncated = truncated<<8 | logging.PacketNumber(data[pnOffset+i]^mask[1+i])
+	}
+	pn := protocol.DecodePacketNumber(protocol.PacketNumberLen(pnLen), l.largestPN, truncated)
+	if pn > l.largestPN {
+		l.largestPN = pn
+	}
+	return pn, true
+}
+
+func (l *fecLossInjector) counts() (dropped, lost int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.dropped), l.numLost
+}
+
+// numRetransmitted is the number of packets that retransmitted STREAM frames of dropped packets.
+func (l *fecLossInjector) numRetransmitted() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var n int
+	for pn, frames := range l.sent {
+		if l.retransmitsDroppedStreamData(pn, frames) {
+			n++
+		}
+	}
+	return n
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) { return f(b) }
+
+// hkdfExpandLabel HKDF expands a label with an empty context, as defined in RFC 8446, section 7.1.
+func hkdfExpandLabel(secret []byte, label string, length int) []byte {
+	info := make([]byte, 0, 4+len("tls13 ")+len(label))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, uint8(len("tls13 ")+len(label)))
+	info = append(info, "tls13 "...)
+	info = append(info, label...)
+	info = append(info, 0)
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, secret, info), out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// oneRTTPacket returns the 1-RTT packet contained in a UDP datagram.
+// A 1-RTT packet can only be the last packet in a coalesced datagram.
+func oneRTTPacket(data []byte) []byte {
+	for len(data) > 0 {
+		if !wire.IsLongHeaderPacket(data[0]) {
+			return data
+		}
+		_, _, rest, err := wire.ParsePacket(data)
+		if err != nil {
+			return nil
+		}
+		data = rest
+	}
+	return nil
+}
+
+func dropSourceSymbols(ids ...logging.SID) func([]logging.Frame) bool {
+	return func(frames []logging.Frame) bool {
+		for _, f := range frames {
+			if ssf, ok := f.(*logging.SourceSymbolFrame); ok {
+				for _, id := range ids {
+					if ssf.SID == id {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
+
+func dropRepairSymbols(blockID logging.BlockID, parityIDs ...logging.ParityID) func([]logging.Frame) bool {
+	return func(frames []logging.Frame) bool {
+		for _, f := range frames {
+			if rf, ok := f.(*logging.RepairFrame); ok && rf.BlockID == blockID {
+				for _, id := range parityIDs {
+					if rf.ParityID == id {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
+
+// dropFin drops the packet carrying the last STREAM frame.
+// Unless the last source symbol happens to complete a block, it is retransmitted.
+func dropFin() func([]logging.Frame) bool {
+	var dropped bool
+	return func(frames []logging.Frame) bool {
+		if dropped {
+			return false
+		}
+		for _, f := range frames {
+			if sf, ok := f.(*logging.StreamFrame); ok && sf.Fin {
+				dropped = true
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func dropAnyOf(fns ...func([]logging.Frame) bool) func([]logging.Frame) bool {
+	return func(frames []logging.Frame) bool {
+		for _, fn := range fns {
+			if fn(frames) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var _ = Describe("FEC", func() {
+	type lossPattern struct {
+		name       string
+		shouldDrop func([]logging.Frame) bool
+		// class is the protection class of the stream
+		class quic.FECProtectionClass
+		// recovered are the blocks that need to be recovered by the receiver
+		recovered []logging.BlockID
+		// retransmits is set if some of the dropped STREAM frames might not be recoverable, and need to be retransmitted
+		retransmits bool
+	}
+
+	var (
+		proxy    *quicproxy.QuicProxy
+		ln       *quic.Listener
+		injector *fecLossInjector
+		// resets the cipher suite, which is set such that the proxy can remove the header protection
+		resetCipherSuite func()
+		// the wire format used by both endpoints
+		wireFormat quic.FECWireFormat
+		// the control frames protected by the server
+		controlFrames quic.FECControlFrames
+		// blocks recovered by the client
+		recoveredMutex sync.Mutex
+		recovered      map[logging.BlockID]struct{}
+	)
+
+	recoveredBlocks := func() []logging.BlockID {
+		recoveredMutex.Lock()
+		defer recoveredMutex.Unlock()
+		blocks := make([]logging.BlockID, 0, len(recovered))
+		for id := range recovered {
+			blocks = append(blocks, id)
+		}
+		return blocks
+	}
+
+	startListenerAndProxy := func(scheme protocol.DecoderFECScheme, shouldDrop func([]logging.Frame) bool) {
+		injector = newFECLossInjector(shouldDrop)
+		resetCipherSuite = qtls.SetCipherSuite(tls.TLS_AES_128_GCM_SHA256)
+		tlsConf := getTLSConfig()
+		tlsConf.KeyLogWriter = injector.keyLogWriter()
+		var err error
+		ln, err = quic.ListenAddr(
+			"localhost:0",
+			tlsConf,
+			getQuicConfig(&quic.Config{
+				EnableFEC:               true,
+				DecoderFECScheme:        scheme,
+				FECWireFormat:           wireFormat,
+				FECControlFrames:        controlFrames,
+				FECControlFrameClass:    quic.FECProtectionHigh,
+				EnableDatagrams:         true,
+				DisablePathMTUDiscovery: true,
+				Tracer:                  newTracer(injector.tracer()),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		serverPort := ln.Addr().(*net.UDPAddr).Port
+		proxy, err = quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", serverPort),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return 5 * time.Millisecond },
+			DropPacket:  injector.dropPacket,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	dialProxy := func(scheme protocol.DecoderFECScheme) quic.Connection {
+		recovered = make(map[logging.BlockID]struct{})
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{
+				EnableFEC:               true,
+				DecoderFECScheme:        scheme,
+				FECWireFormat:           wireFormat,
+				EnableDatagrams:         true,
+				DisablePathMTUDiscovery: true,
+				Tracer: newTracer(&logging.ConnectionTracer{
+					RecoveredSourceSymbols: func(id logging.BlockID, _ logging.ByteCount) {
+						recoveredMutex.Lock()
+						defer recoveredMutex.Unlock()
+						recovered[id] = struct{}{}
+					},
+				}),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		return conn
+	}
+
+	BeforeEach(func() {
+		wireFormat = quic.FECWireFormatLegacy
+		controlFrames = 0
+	})
+
+	AfterEach(func() {
+		Expect(proxy.Close()).To(Succeed())
+		Expect(ln.Close()).To(Succeed())
+		resetCipherSuite()
+	})
+
+	runStreamTest := func(scheme protocol.DecoderFECScheme, p lossPattern) {
+		startListenerAndProxy(scheme, p.shouldDrop)
+
+		serverErrChan := make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+			str, err := conn.OpenUniStreamSyncWithFEC(context.Background())
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+			if err := str.SetFECProtectionClass(p.class); err != nil {
+				serverErrChan <- err
+				return
+			}
+			if _, err := str.Write(PRData); err != nil {
+				serverErrChan <- err
+				return
+			}
+			serverErrChan <- str.Close()
+		}()
+
+		conn := dialProxy(scheme)
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Eventually(serverErrChan).Should(Receive(BeNil()))
+		conn.CloseWithError(0, "")
+
+		dropped, lost := injector.counts()
+		Expect(dropped).ToNot(BeZero())
+		// Every lost packet must have been dropped by the proxy.
+		Expect(lost).To(BeNumerically("<=", dropped))
+		for _, id := range p.recovered {
+			Expect(recoveredBlocks()).To(ContainElement(id))
+		}
+		// Stream data recovered using FEC must not be retransmitted.
+		// Otherwise, a retransmitted STREAM frame might be split across two packets.
+		if p.retransmits {
+			Expect(injector.numRetransmitted()).To(BeNumerically("<=", 2*dropped))
+		} else {
+			Expect(injector.numRetransmitted()).To(BeZero())
+		}
+	}
+
+	runDatagramTest := func(scheme protocol.DecoderFECScheme, num int, p lossPattern) {
+		startListenerAndProxy(scheme, p.shouldDrop)
+
+		serverErrChan := make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+			for i := 0; i < num; i++ {
+				b := make([]byte, 100)
+				binary.BigEndian.PutUint64(b, uint64(i))
+				if err := conn.SendDatagramWithFEC(b); err != nil {
+					serverErrChan <- err
+					return
+				}
+				// give the connection time to send the DATAGRAM frame in its own packet
+				time.Sleep(time.Millisecond)
+			}
+			serverErrChan <- nil
+			<-conn.Context().Done()
+		}()
+
+		conn := dialProxy(scheme)
+		Eventually(serverErrChan).Should(Receive(BeNil()))
+		// DATAGRAM frames are never retransmitted, so every datagram has to be either received or recovered.
+		received := make(map[uint64]struct{})
+		for len(received) < num {
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(2*time.Second))
+			b, err := conn.ReceiveDatagram(ctx)
+			cancel()
+			Expect(err).ToNot(HaveOccurred(), fmt.Sprintf("received %d of %d datagrams", len(received), num))
+			received[binary.BigEndian.Uint64(b)] = struct{}{}
+		}
+		conn.CloseWithError(0, "")
+
+		dropped, _ := injector.counts()
+		Expect(dropped).ToNot(BeZero())
+		for _, id := range p.recovered {
+			Expect(recoveredBlocks()).To(ContainElement(id))
+		}
+	}
+
+	Context("using XOR", func() {
+		// XOR protects blocks of 2 source symbols with 1 repair symbol.
+		const scheme = protocol.XORFECScheme
+
+		streamPatterns := []lossPattern{
+			{name: "a single source symbol", shouldDrop: dropSourceSymbols(10), recovered: []logging.BlockID{5}},
+			{name: "a burst of source symbols spanning two blocks", shouldDrop: dropSourceSymbols(11, 12), recovered: []logging.BlockID{5, 6}},
+			{name: "a repair symbol", shouldDrop: dropRepairSymbols(3, 0)},
+			{
+				name:       "a repair symbol and a source symbol of the next block",
+				shouldDrop: dropAnyOf(dropRepairSymbols(7, 0), dropSourceSymbols(17)),
+				recovered:  []logging.BlockID{8},
+			},
+			{name: "an entire block", shouldDrop: dropSourceSymbols(20, 21), retransmits: true},
+			{name: "the last packet", shouldDrop: dropFin(), retransmits: true},
+			// The high protection class protects every source symbol with its own repair symbol.
+			{
+				name:       "consecutive source symbols of a highly protected stream",
+				shouldDrop: dropSourceSymbols(10, 11),
+				class:      quic.FECProtectionHigh,
+				recovered:  []logging.BlockID{10, 11},
+			},
+		}
+		for _, p := range streamPatterns {
+			pattern := p
+
+			It(fmt.Sprintf("transfers stream data when losing %s", pattern.name), func() {
+				runStreamTest(scheme, pattern)
+			})
+		}
+
+		datagramPatterns := []lossPattern{
+			{name: "a single source symbol", shouldDrop: dropSourceSymbols(3), recovered: []logging.BlockID{1}},
+			{name: "a burst of source symbols spanning two blocks", shouldDrop: dropSourceSymbols(5, 6), recovered: []logging.BlockID{2, 3}},
+			{
+				name:       "a repair symbol and a source symbol of the next block",
+				shouldDrop: dropAnyOf(dropRepairSymbols(1, 0), dropSourceSymbols(4)),
+				recovered:  []logging.BlockID{2},
+			},
+		}
+		for _, p := range datagramPatterns {
+			pattern := p
+
+			It(fmt.Sprintf("recovers datagrams when losing %s", pattern.name), func() {
+				runDatagramTest(scheme, 10, pattern)
+			})
+		}
+	})
+
+	Context("using Reed-Solomon", func() {
+		// Reed-Solomon protects blocks of 20 source symbols with 10 repair symbols.
+		const scheme = protocol.ReedSolomonFECScheme
+
+		streamPatterns := []lossPattern{
+			{name: "a single source symbol", shouldDrop: dropSourceSymbols(25), recovered: []logging.BlockID{1}},
+			{
+				name:       "a burst of source symbols",
+				shouldDrop: dropSourceSymbols(40, 41, 42, 43, 44, 45, 46, 47, 48, 49),
+				recovered:  []logging.BlockID{2},
+			},
+			{
+				name:       "repair symbols and source symbols of the same block",
+				shouldDrop: dropAnyOf(dropRepairSymbols(3, 0, 2, 4, 6, 8), dropSourceSymbols(60, 65, 79)),
+				recovered:  []logging.BlockID{3},
+			},
+			// The sender sends additional repair symbols, using parity IDs beyond the ones sent for every block.
+			{
+				name:       "more source symbols than there are repair symbols",
+				shouldDrop: dropSourceSymbols(80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90),
+				recovered:  []logging.BlockID{4},
+			},
+			{name: "the last packet", shouldDrop: dropFin(), retransmits: true},
+		}
+		for _, p := range streamPatterns {
+			pattern := p
+
+			It(fmt.Sprintf("transfers stream data when losing %s", pattern.name), func() {
+				runStreamTest(scheme, pattern)
+			})
+		}
+
+		datagramPatterns := []lossPattern{
+			{name: "a single source symbol", shouldDrop: dropSourceSymbols(7), recovered: []logging.BlockID{0}},
+			{
+				name:       "a burst of source symbols",
+				shouldDrop: dropSourceSymbols(25, 26, 27, 28, 29, 30, 31, 32, 33, 34),
+				recovered:  []logging.BlockID{1},
+			},
+			{
+				name:       "repair symbols and source symbols of the same block",
+				shouldDrop: dropAnyOf(dropRepairSymbols(2, 1, 3, 5), dropSourceSymbols(40, 50, 59)),
+				recovered:  []logging.BlockID{2},
+			},
+		}
+		for _, p := range datagramPatterns {
+			pattern := p
+
+			It(fmt.Sprintf("recovers datagrams when losing %s", pattern.name), func() {
+				runDatagramTest(scheme, 60, pattern)
+			})
+		}
+	})
+
+	Context("using large-block Reed-Solomon", func() {
+		// Large-block Reed-Solomon protects blocks of 2000 source symbols with 200 repair symbols.
+		// The transfer doesn't complete a block, so lost source symbols are retransmitted.
+		const scheme = protocol.LargeBlockReedSolomonFECScheme
+
+		burst := make([]logging.SID, 0, 100)
+		for i := 100; i < 200; i++ {
+			burst = append(burst, logging.SID(i))
+		}
+		It("transfers stream data when losing a burst of source symbols", func() {
+			runStreamTest(scheme, lossPattern{name: "a burst of source symbols", shouldDrop: dropSourceSymbols(burst...), retransmits: true})
+		})
+	})
+
+	Context("protecting control frames", func() {
+		// dropFlowControlUpdates drops the first n packets carrying a SOURCE_SYMBOL frame with a flow control update.
+		dropFlowControlUpdates := func(n int) func([]logging.Frame) bool {
+			var dropped int
+			return func(frames []logging.Frame) bool {
+				if dropped >= n {
+					return false
+				}
+				var hasSourceSymbol, hasUpdate bool
+				for _, f := range frames {
+					switch f.(type) {
+					case *logging.SourceSymbolFrame:
+						hasSourceSymbol = true
+					case *logging.MaxDataFrame, *logging.MaxStreamDataFrame:
+						hasUpdate = true
+					}
+				}
+				if hasSourceSymbol && hasUpdate {
+					dropped++
+					return true
+				}
+				return false
+			}
+		}
+
+		// XOR protects every source symbol of the high protection class with its own repair symbol,
+		// so the lost updates are recovered.
+		// Reed-Solomon uses blocks of 10 source symbols, which aren't completed during the transfer,
+		// so the lost updates are retransmitted.
+		for _, t := range []struct {
+			scheme      protocol.DecoderFECScheme
+			recoverable bool
+		}{{protocol.XORFECScheme, true}, {protocol.ReedSolomonFECScheme, false}} {
+			scheme := t.scheme
+			recoverable := t.recoverable
+
+			It(fmt.Sprintf("transfers data when losing flow control updates, using %s", scheme), func() {
+				controlFrames = quic.FECControlFramesFlowControl
+				startListenerAndProxy(scheme, dropFlowControlUpdates(3))
+
+				serverErrChan := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					conn, err := ln.Accept(context.Background())
+					if err != nil {
+						serverErrChan <- err
+						return
+					}
+					str, err := conn.AcceptUniStream(context.Background())
+					if err != nil {
+						serverErrChan <- err
+						return
+					}
+					data, err := io.ReadAll(str)
+					if err != nil {
+						serverErrChan <- err
+						return
+					}
+					if !bytes.Equal(data, PRData) {
+						serverErrChan <- errors.New("received data doesn't match")
+						return
+					}
+					serverErrChan <- nil
+					<-conn.Context().Done()
+				}()
+
+				conn := dialProxy(scheme)
+				str, err := conn.OpenUniStreamSync(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(PRData)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+				Eventually(serverErrChan, scaleDuration(5*time.Second)).Should(Receive(BeNil()))
+				conn.CloseWithError(0, "")
+
+				dropped, _ := injector.counts()
+				Expect(dropped).To(Equal(3))
+				if recoverable {
+					Expect(recoveredBlocks()).ToNot(BeEmpty())
+				}
+			})
+		}
+	})
+
+	Context("using the draft wire format", func() {
+		BeforeEach(func() {
+			wireFormat = quic.FECWireFormatDraft
+		})
+
+		// The third source symbol belongs to the second XOR block, and to the first Reed-Solomon block.
+		for _, t := range []struct {
+			scheme  protocol.DecoderFECScheme
+			blockID logging.BlockID
+		}{{protocol.XORFECScheme, 1}, {protocol.ReedSolomonFECScheme, 0}} {
+			pattern := lossPattern{shouldDrop: dropSourceSymbols(3), recovered: []logging.BlockID{t.blockID}}
+			scheme := t.scheme
+
+			It(fmt.Sprintf("transfers stream data when losing a source symbol, using %s", scheme), func() {
+				runStreamTest(scheme, pattern)
+			})
+
+			It(fmt.Sprintf("recovers datagrams when losing a source symbol, using %s", scheme), func() {
+				runDatagramTest(scheme, 60, pattern)
+			})
+		}
+
+		It("doesn't use FEC if the peer uses a different wire format", func() {
+			startListenerAndProxy(protocol.XORFECScheme, func([]logging.Frame) bool { return false })
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{EnableFEC: true, DecoderFECScheme: protocol.XORFECScheme, FECWireFormat: quic.FECWireFormatLegacy}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			_, err = conn.OpenUniStreamSyncWithFEC(context.Background())
+			Expect(err).To(MatchError("FEC not enabled"))
+		})
+	})
+})
@@ -319,6 +319,27 @@ var _ = Describe("HTTP tests", func() {
 		Expect(string(body)).To(Equal("Hello, World!\n"))
 	})
 
+	It("sends priority signals", func() {
+		mux.HandleFunc("/priority", func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Header.Get("Priority")).To(Equal("u=1, i"))
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			w.Write(PRDataLong) // don't check the error here. Stream may be reset.
+		})
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://localhost:%d/priority", port), nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Priority", http3.Priority{Urgency: 1, Incremental: true}.String())
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(200))
+		Expect(resp.Body.(http3.PriorityUpdater).UpdatePriority(http3.Priority{Urgency: 6})).To(Succeed())
+		body, err := io.ReadAll(gbytes.TimeoutReader(resp.Body, 20*time.Second))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body).To(Equal(PRDataLong))
+	})
+
 	It("handles context cancellations", func() {
 		mux.HandleFunc("/cancel", func(w http.ResponseWriter, r *http.Request) {
 			<-r.Context().Done()
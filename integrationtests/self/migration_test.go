@@ -0,0 +1,116 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Migration", func() {
+	It("migrates the connection to a new path", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			for {
+				str, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					data, err := io.ReadAll(str)
+					Expect(err).ToNot(HaveOccurred())
+					_, err = str.Write(data)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str.Close()).To(Succeed())
+				}()
+			}
+		}()
+
+		udpConn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr1 := &quic.Transport{Conn: udpConn1}
+		defer tr1.Close()
+		udpConn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr2 := &quic.Transport{Conn: udpConn2}
+		defer tr2.Close()
+
+		conn, err := tr1.Dial(
+			context.Background(),
+			server.Addr(),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+
+		echo := func() {
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+		}
+
+		echo()
+		Expect(serverConn.RemoteAddr().String()).To(Equal(udpConn1.LocalAddr().String()))
+
+		path, err := conn.AddPath(tr2)
+		Expect(err).ToNot(HaveOccurred())
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		Expect(path.Probe(ctx)).To(Succeed())
+		Expect(path.Switch()).To(Succeed())
+		echo()
+		Eventually(func() string { return serverConn.RemoteAddr().String() }).Should(Equal(udpConn2.LocalAddr().String()))
+		Expect(conn.LocalAddr().String()).To(Equal(udpConn2.LocalAddr().String()))
+
+		// The old transport can be closed without affecting the connection.
+		Expect(tr1.Close()).To(Succeed())
+		echo()
+		Consistently(conn.Context().Done()).ShouldNot(BeClosed())
+	})
+
+	It("doesn't switch to a path that wasn't validated", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		udpConn1, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr1 := &quic.Transport{Conn: udpConn1}
+		defer tr1.Close()
+		udpConn2, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		tr := &quic.Transport{Conn: udpConn2}
+		defer tr.Close()
+		conn, err := tr1.Dial(context.Background(), server.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+
+		path, err := conn.AddPath(tr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path.Switch()).To(MatchError(quic.ErrPathNotValidated))
+		Expect(path.Close()).To(Succeed())
+		Expect(path.Switch()).To(MatchError(quic.ErrPathClosed))
+	})
+})
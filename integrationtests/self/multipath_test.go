@@ -0,0 +1,169 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// packetCountingConn counts the packets sent and received on a net.PacketConn.
+type packetCountingConn struct {
+	net.PacketConn
+
+	numSent, numRcvd atomic.Int64
+}
+
+func (c *packetCountingConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	if err == nil {
+		c.numRcvd.Add(1)
+	}
+	return n, addr, err
+}
+
+func (c *packetCountingConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.numSent.Add(1)
+	return c.PacketConn.WriteTo(b, addr)
+}
+
+var _ = Describe("Multipath", func() {
+	runEchoServer := func(conf *quic.Config) *quic.Listener {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(conf))
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			for {
+				conn, err := server.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					for {
+						str, err := conn.AcceptStream(context.Background())
+						if err != nil {
+							return
+						}
+						go func() {
+							defer GinkgoRecover()
+							data, err := io.ReadAll(str)
+							Expect(err).ToNot(HaveOccurred())
+							_, err = str.Write(data)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(str.Close()).To(Succeed())
+						}()
+					}
+				}()
+			}
+		}()
+		return server
+	}
+
+	newTransport := func() (*quic.Transport, *packetCountingConn) {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		conn := &packetCountingConn{PacketConn: udpConn}
+		return &quic.Transport{Conn: conn}, conn
+	}
+
+	echo := func(conn quic.Connection, openStream func() (quic.Stream, error)) {
+		str, err := openStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+	}
+
+	// dialWithSecondPath dials a connection using tr1, and validates a second path using tr2.
+	dialWithSecondPath := func(serverAddr net.Addr, tr1, tr2 *quic.Transport, conf *quic.Config) (quic.Connection, *quic.Path) {
+		conn, err := tr1.Dial(context.Background(), serverAddr, getTLSClientConfig(), getQuicConfig(conf))
+		Expect(err).ToNot(HaveOccurred())
+
+		path, err := conn.AddPath(tr2)
+		Expect(err).ToNot(HaveOccurred())
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		Expect(path.Probe(ctx)).To(Succeed())
+		return conn, path
+	}
+
+	It("sends data on multiple paths at the same time", func() {
+		server := runEchoServer(&quic.Config{EnableMultipath: true, MultipathScheduler: quic.MultipathSchedulerRoundRobin})
+		defer server.Close()
+
+		tr1, conn1 := newTransport()
+		defer tr1.Close()
+		tr2, conn2 := newTransport()
+		defer tr2.Close()
+		conn, path := dialWithSecondPath(server.Addr(), tr1, tr2, &quic.Config{
+			EnableMultipath:    true,
+			MultipathScheduler: quic.MultipathSchedulerRoundRobin,
+		})
+		defer conn.CloseWithError(0, "")
+		Expect(path.Switch()).ToNot(Succeed())
+		sent1, sent2 := conn1.numSent.Load(), conn2.numSent.Load()
+		rcvd1, rcvd2 := conn1.numRcvd.Load(), conn2.numRcvd.Load()
+		echo(conn, conn.OpenStream)
+		// PRData is large enough to use both paths in both directions.
+		Expect(conn1.numSent.Load() - sent1).To(BeNumerically(">", 10))
+		Expect(conn2.numSent.Load() - sent2).To(BeNumerically(">", 10))
+		Expect(conn1.numRcvd.Load() - rcvd1).To(BeNumerically(">", 10))
+		Expect(conn2.numRcvd.Load() - rcvd2).To(BeNumerically(">", 10))
+
+		// Once the second path is closed, the connection continues on the initial path.
+		Expect(path.Close()).To(Succeed())
+		time.Sleep(scaleDuration(20 * time.Millisecond))
+		sent2 = conn2.numSent.Load()
+		echo(conn, conn.OpenStream)
+		Expect(conn2.numSent.Load()).To(Equal(sent2))
+		Consistently(conn.Context().Done()).ShouldNot(BeClosed())
+	})
+
+	It("sends REPAIR frames on a different path than their source symbols", func() {
+		conf := &quic.Config{
+			EnableMultipath:        true,
+			EnableFEC:              true,
+			DecoderFECScheme:       protocol.ReedSolomonFECScheme,
+			FECRepairPathDiversity: true,
+		}
+		server := runEchoServer(conf)
+		defer server.Close()
+
+		tr1, _ := newTransport()
+		defer tr1.Close()
+		tr2, _ := newTransport()
+		defer tr2.Close()
+		conn, _ := dialWithSecondPath(server.Addr(), tr1, tr2, conf)
+		defer conn.CloseWithError(0, "")
+		echo(conn, func() (quic.Stream, error) { return conn.OpenStreamSyncWithFEC(context.Background()) })
+	})
+
+	It("doesn't use multiple paths if the server doesn't support multipath", func() {
+		server := runEchoServer(nil)
+		defer server.Close()
+
+		tr1, _ := newTransport()
+		defer tr1.Close()
+		tr2, conn2 := newTransport()
+		defer tr2.Close()
+		conn, path := dialWithSecondPath(server.Addr(), tr1, tr2, &quic.Config{EnableMultipath: true})
+		defer conn.CloseWithError(0, "")
+		sent2 := conn2.numSent.Load()
+		echo(conn, conn.OpenStream)
+		Expect(conn2.numSent.Load()).To(Equal(sent2))
+		Expect(path.Switch()).To(Succeed())
+		echo(conn, conn.OpenStream)
+		Eventually(func() int64 { return conn2.numSent.Load() }).Should(BeNumerically(">", sent2))
+	})
+})
@@ -0,0 +1,90 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reliable Stream Reset", func() {
+	const reliableSize = 100_000
+
+	It("delivers data up to the reliable size", func() {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableStreamResetPartialDelivery: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var num atomic.Int64
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return 5 * time.Millisecond },
+			// drop every 10th packet sent by the server, so some of the data needs to be retransmitted
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				return dir == quicproxy.DirectionOutgoing && num.Add(1)%10 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData[:2*reliableSize])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.CancelWriteAt(42, reliableSize)).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableStreamResetPartialDelivery: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).To(MatchError(&quic.StreamError{StreamID: str.StreamID(), ErrorCode: 42, Remote: true}))
+		// Data beyond the reliable size might have been received before the RESET_STREAM_AT frame.
+		Expect(len(data)).To(BeNumerically(">=", reliableSize))
+		Expect(data).To(Equal(PRData[:len(data)]))
+	})
+
+	It("doesn't allow resetting at a reliable size if the peer doesn't support it", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableStreamResetPartialDelivery: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.CancelWriteAt(42, 3)).To(MatchError("peer doesn't support RESET_STREAM_AT"))
+		str.CancelWrite(42)
+	})
+})
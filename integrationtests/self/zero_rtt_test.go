@@ -1111,4 +1111,116 @@ var _ = Describe("0-RTT", func() {
 		Expect(num0RTT).ToNot(BeZero())
 		Expect(get0RTTPackets(counter.getRcvdLongHeaderPackets())).To(BeEmpty())
 	})
+
+	It("sends FEC protected 0-RTT data", func() {
+		fecConf := func(conf *quic.Config) *quic.Config {
+			conf.EnableFEC = true
+			conf.DecoderFECScheme = protocol.ReedSolomonFECScheme
+			conf.EnableDatagrams = true
+			return getQuicConfig(conf)
+		}
+		tlsConf := getTLSConfig()
+		clientTLSConf := getTLSClientConfig()
+		dialAndReceiveSessionTicket(tlsConf, fecConf(&quic.Config{}), clientTLSConf)
+
+		counter, tracer := newPacketTracer()
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			tlsConf,
+			fecConf(&quic.Config{
+				Allow0RTT: true,
+				Tracer:    newTracer(tracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+		defer proxy.Close()
+
+		// second connection
+		sentData := GeneratePRData(1000)
+		sentMessage := GeneratePRData(100)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			conn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(sentData))
+			Expect(str.Close()).To(Succeed())
+			msg, err := conn.ReceiveDatagram(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(msg).To(Equal(sentMessage))
+			Expect(conn.ConnectionState().Used0RTT).To(BeTrue())
+			<-conn.Context().Done()
+		}()
+		conn, err := quic.DialAddrEarly(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			clientTLSConf,
+			fecConf(&quic.Config{}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.OpenStreamSyncWithFEC(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(sentData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		Expect(conn.SendDatagramWithFEC(sentMessage)).To(Succeed())
+		<-conn.HandshakeComplete()
+		Expect(conn.ConnectionState().Used0RTT).To(BeTrue())
+		io.ReadAll(str) // wait for the EOF from the server to arrive before closing the conn
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+		Eventually(done).Should(BeClosed())
+
+		num0RTT := num0RTTPackets.Load()
+		fmt.Fprintf(GinkgoWriter, "Sent %d 0-RTT packets.", num0RTT)
+		Expect(num0RTT).ToNot(BeZero())
+		var numSourceSymbols int
+		for _, p := range counter.getRcvdLongHeaderPackets() {
+			if p.hdr.Type != protocol.PacketType0RTT {
+				continue
+			}
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.SourceSymbolFrame); ok {
+					numSourceSymbols++
+				}
+			}
+		}
+		Expect(numSourceSymbols).ToNot(BeZero())
+	})
+
+	It("rejects 0-RTT when the server's FEC scheme changed", func() {
+		tlsConf := getTLSConfig()
+		clientTLSConf := getTLSClientConfig()
+		dialAndReceiveSessionTicket(tlsConf, getQuicConfig(&quic.Config{
+			EnableFEC:        true,
+			DecoderFECScheme: protocol.ReedSolomonFECScheme,
+		}), clientTLSConf)
+
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			tlsConf,
+			getQuicConfig(&quic.Config{
+				Allow0RTT:        true,
+				EnableFEC:        true,
+				DecoderFECScheme: protocol.XORFECScheme,
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+		defer proxy.Close()
+
+		check0RTTRejected(ln, proxy.LocalPort(), clientTLSConf)
+
+		// The client should send 0-RTT packets, but the server doesn't process them.
+		num0RTT := num0RTTPackets.Load()
+		fmt.Fprintf(GinkgoWriter, "Sent %d 0-RTT packets.", num0RTT)
+		Expect(num0RTT).ToNot(BeZero())
+	})
 })
@@ -8,6 +8,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/logging"
@@ -30,6 +31,123 @@ const (
 	Version2 = protocol.Version2
 )
 
+// A FECProtectionClass determines how strongly the FEC protected data of a stream or a datagram is protected.
+// Every class uses its own block geometry and code rate.
+type FECProtectionClass = protocol.FECProtectionClass
+
+const (
+	// FECProtectionDefault is the protection class used unless a different class is assigned.
+	FECProtectionDefault = protocol.FECProtectionDefault
+	// FECProtectionHigh protects data more heavily than the default class, e.g. for keyframes or control messages.
+	FECProtectionHigh = protocol.FECProtectionHigh
+	// FECProtectionLow protects data less heavily than the default class, e.g. for bulk data.
+	FECProtectionLow = protocol.FECProtectionLow
+)
+
+// A StreamPriority is the scheduling priority of a stream, modeled after RFC 9218.
+type StreamPriority = protocol.StreamPriority
+
+// MaxStreamUrgency is the lowest urgency level of a stream.
+const MaxStreamUrgency = protocol.MaxStreamUrgency
+
+// DefaultStreamPriority returns the priority of a stream unless a different priority is set.
+// All streams with the default priority share the bandwidth equally.
+func DefaultStreamPriority() StreamPriority { return protocol.DefaultStreamPriority() }
+
+// A DatagramPriority is the sending priority of a datagram.
+// Datagrams of a higher priority are sent first, datagrams of the same priority are sent in order.
+type DatagramPriority uint8
+
+// MaxDatagramPriority is the highest priority of a datagram.
+const MaxDatagramPriority DatagramPriority = 7
+
+// DatagramOptions are the options used when sending a datagram.
+type DatagramOptions struct {
+	// Priority is the priority of the datagram.
+	// If the send queue is full, datagrams of a lower priority are dropped to make room for it.
+	Priority DatagramPriority
+	// Expiry is the time after which the datagram is dropped instead of being sent.
+	// The zero value means that the datagram never expires.
+	Expiry time.Time
+	// FECProtected says if the datagram is protected by FEC.
+	FECProtected bool
+	// FECProtectionClass is the protection class used if the datagram is protected by FEC.
+	FECProtectionClass FECProtectionClass
+	// NonBlocking makes SendDatagramWithOptions return ErrDatagramQueueFull instead of blocking,
+	// if the send queue is full and no datagram of a lower priority can be dropped.
+	NonBlocking bool
+}
+
+// DatagramStats are counters of the datagrams sent, received and dropped on a connection.
+type DatagramStats struct {
+	// Sent is the number of datagrams sent.
+	Sent uint64
+	// SendDroppedExpired is the number of datagrams dropped because they expired before they could be sent.
+	SendDroppedExpired uint64
+	// SendDroppedQueueFull is the number of datagrams dropped because the send queue was full.
+	SendDroppedQueueFull uint64
+	// SendDroppedTooLarge is the number of datagrams dropped because they didn't fit into a packet.
+	SendDroppedTooLarge uint64
+	// Received is the number of datagrams received.
+	Received uint64
+	// ReceiveDroppedQueueFull is the number of received datagrams dropped because the receive queue was full.
+	ReceiveDroppedQueueFull uint64
+}
+
+// A FECWireFormat is the encoding of the FEC frames and transport parameters.
+type FECWireFormat = protocol.FECWireFormat
+
+const (
+	// FECWireFormatLegacy is the encoding used by earlier versions of quic-go.
+	FECWireFormatLegacy = protocol.FECWireFormatLegacy
+	// FECWireFormatDraft is the encoding of draft-michel-quic-fec.
+	// FEC protection classes can't be expressed in this encoding, so all data is protected using the default class.
+	FECWireFormatDraft = protocol.FECWireFormatDraft
+)
+
+// A MultipathScheduler decides which path of a multipath connection a packet is sent on, see Config.MultipathScheduler.
+type MultipathScheduler = protocol.MultipathScheduler
+
+const (
+	// MultipathSchedulerMinRTT sends packets on the path with the smallest RTT that isn't limited by congestion control.
+	MultipathSchedulerMinRTT = protocol.MultipathSchedulerMinRTT
+	// MultipathSchedulerRoundRobin alternates between all paths that aren't limited by congestion control.
+	MultipathSchedulerRoundRobin = protocol.MultipathSchedulerRoundRobin
+)
+
+// A CongestionControlAlgorithm is a congestion control algorithm, see Config.CongestionControl.
+type CongestionControlAlgorithm = protocol.CongestionControlAlgorithm
+
+const (
+	// CongestionControlReno is TCP NewReno (RFC 6582).
+	CongestionControlReno = protocol.CongestionControlReno
+	// CongestionControlCubic is Cubic (RFC 9438).
+	CongestionControlCubic = protocol.CongestionControlCubic
+	// CongestionControlBBR is BBR (draft-ietf-ccwg-bbr).
+	// Unlike Reno and Cubic, it doesn't use packet loss as its primary congestion signal,
+	// which makes it a better fit for paths with a high bandwidth-delay product and random loss.
+	CongestionControlBBR = protocol.CongestionControlBBR
+)
+
+// FECControlFrames is a set of control frame types, see Config.FECControlFrames.
+type FECControlFrames = protocol.FECControlFrames
+
+const (
+	FECControlFrameResetStream        = protocol.FECControlFrameResetStream
+	FECControlFrameStopSending        = protocol.FECControlFrameStopSending
+	FECControlFrameMaxData            = protocol.FECControlFrameMaxData
+	FECControlFrameMaxStreamData      = protocol.FECControlFrameMaxStreamData
+	FECControlFrameMaxStreams         = protocol.FECControlFrameMaxStreams
+	FECControlFrameDataBlocked        = protocol.FECControlFrameDataBlocked
+	FECControlFrameStreamDataBlocked  = protocol.FECControlFrameStreamDataBlocked
+	FECControlFrameStreamsBlocked     = protocol.FECControlFrameStreamsBlocked
+	FECControlFrameNewConnectionID    = protocol.FECControlFrameNewConnectionID
+	FECControlFrameRetireConnectionID = protocol.FECControlFrameRetireConnectionID
+	FECControlFrameNewToken           = protocol.FECControlFrameNewToken
+	// FECControlFramesFlowControl are the MAX_DATA, MAX_STREAM_DATA, MAX_STREAMS and the corresponding BLOCKED frames.
+	FECControlFramesFlowControl = protocol.FECControlFramesFlowControl
+)
+
 // A ClientToken is a token received by the client.
 // It can be used to skip address validation on future connection attempts.
 type ClientToken struct {
@@ -123,8 +241,17 @@ type SendStream interface {
 	// Write will unblock immediately, and future calls to Write will fail.
 	// When called multiple times or after closing the stream it is a no-op.
 	CancelWrite(StreamErrorCode)
+	// CancelWriteAt aborts sending on this stream, but still delivers the first reliableSize bytes
+	// of the stream reliably (using the RESET_STREAM_AT frame of draft-ietf-quic-reliable-stream-reset).
+	// This is useful for protocols that need a stream header to arrive, even if the rest of the stream is abandoned.
+	// The reliable size can't be larger than the amount of data written to the stream.
+	// The peer might still receive data beyond the reliable size, if it arrived before the reset.
+	// It returns an error if the peer doesn't support the extension (see Config.EnableStreamResetPartialDelivery).
+	// A reliable size of 0 is equivalent to calling CancelWrite.
+	// When called after the stream was already canceled, it is a no-op.
+	CancelWriteAt(errorCode StreamErrorCode, reliableSize uint64) error
 	// The Context is canceled as soon as the write-side of the stream is closed.
-	// This happens when Close() or CancelWrite() is called, or when the peer
+	// This happens when Close(), CancelWrite() or CancelWriteAt() is called, or when the peer
 	// cancels the read-side of their stream.
 	// The cancellation cause is set to the error that caused the stream to
 	// close, or `context.Canceled` in case the stream is closed without error.
@@ -135,6 +262,15 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetFECProtectionClass sets the protection class of the data sent on a FEC protected stream.
+	// It applies to all STREAM frames sent after the call, including retransmissions.
+	// It has no effect on streams that are not FEC protected.
+	// It returns an error if the class is not one of the defined protection classes.
+	SetFECProtectionClass(FECProtectionClass) error
+	// SetPriority sets the priority of the stream.
+	// Data of more urgent streams is sent first, and incremental streams of the same urgency share the bandwidth
+	// according to their weights. By default, all streams use DefaultStreamPriority.
+	SetPriority(StreamPriority)
 }
 
 // FECConnection is a QUIC connection between two peers using FEC.
@@ -145,6 +281,9 @@ type FECConnection interface {
 	// In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 	// If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
 	SendDatagramWithFEC(payload []byte) error
+	// SendDatagramWithFECClass is like SendDatagramWithFEC, but protects the datagram with the given protection class.
+	// Datagrams of different classes are encoded in separate sequences of FEC blocks.
+	SendDatagramWithFECClass(payload []byte, class FECProtectionClass) error
 
 	// OpenStreamSyncWithFEC opens a new bidirectional QUIC stream using FEC.
 	// It blocks until a new stream can be opened.
@@ -209,6 +348,10 @@ type Connection interface {
 	LocalAddr() net.Addr
 	// RemoteAddr returns the address of the peer.
 	RemoteAddr() net.Addr
+	// AddPath creates a new path that sends and receives packets using the given Transport.
+	// The path needs to be probed before the connection can switch to it.
+	// It can only be used by the client, after the handshake has completed.
+	AddPath(*Transport) (*Path, error)
 	// CloseWithError closes the connection with an error.
 	// The error string will be sent to the peer.
 	CloseWithError(ApplicationErrorCode, string) error
@@ -219,6 +362,10 @@ type Connection interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// LossEstimate returns an estimate of the loss pattern on the path to the peer,
+	// based on the packets that were acknowledged or declared lost.
+	// Warning: This API should not be considered stable and might change soon.
+	LossEstimate() LossEstimate
 
 	// SendDatagram sends a message using a QUIC datagram, as specified in RFC 9221.
 	// There is no delivery guarantee for DATAGRAM frames, they are not retransmitted if lost.
@@ -226,8 +373,16 @@ type Connection interface {
 	// In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 	// If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
 	SendDatagram(payload []byte) error
+	// SendDatagramWithOptions is like SendDatagram, but allows setting the priority and the expiry of the datagram,
+	// and protecting it with FEC.
+	// If the send queue is full and no datagram of a lower priority can be dropped, it blocks
+	// until there is space in the queue or the datagram expires,
+	// unless DatagramOptions.NonBlocking is set.
+	SendDatagramWithOptions(payload []byte, opts DatagramOptions) error
 	// ReceiveDatagram gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveDatagram(context.Context) ([]byte, error)
+	// DatagramStats returns the number of datagrams sent, received and dropped.
+	DatagramStats() DatagramStats
 }
 
 // An EarlyConnection is a connection that is handshaking.
@@ -356,11 +511,75 @@ type Config struct {
 	Allow0RTT bool
 	// Enable QUIC datagram support (RFC 9221).
 	EnableDatagrams bool
+	// DatagramSendQueueLen is the maximum number of datagrams queued for sending.
+	// If zero, the default value of 32 is used.
+	DatagramSendQueueLen int
+	// DatagramReceiveQueueLen is the maximum number of received datagrams queued until they are read by the application.
+	// Datagrams received while the queue is full are dropped.
+	// If zero, the default value of 128 is used.
+	DatagramReceiveQueueLen int
 	// EnableFEC identifies whether FEC should be enabled.
 	EnableFEC bool
 	// DecoderFECScheme identifies the used FEC Scheme.
 	DecoderFECScheme protocol.DecoderFECScheme
-	Tracer           func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+	// FECWireFormat is the encoding of the FEC frames and transport parameters.
+	// FEC is only used if both endpoints use the same encoding.
+	// If unset, the legacy encoding is used.
+	FECWireFormat FECWireFormat
+	// FECRepairWindowShare is the share of the congestion window reserved for packets carrying REPAIR frames.
+	// Repair packets within this share don't count against the congestion window used for other data.
+	// It must be between 0 and 1. If 0, repair packets consume congestion window like any other packet.
+	FECRepairWindowShare float64
+	// FECRecoveredLossBackoff is the factor by which the congestion window is reduced when the peer
+	// recovered a lost packet using FEC. A value of 1 keeps the congestion window unchanged.
+	// It must be between 0 and 1. If 0, such losses are treated like any other loss.
+	FECRecoveredLossBackoff float64
+	// FECControlFrames are the types of control frames that are sent inside SOURCE_SYMBOL frames.
+	// Losing a control frame like MAX_DATA can stall the connection until the frame is retransmitted,
+	// whereas a FEC protected control frame can be recovered by the peer using repair symbols.
+	// If unset, control frames are never FEC protected.
+	FECControlFrames FECControlFrames
+	// FECControlFrameClass is the protection class used for the control frames in FECControlFrames.
+	// Repair symbols are only sent once a block is complete, so a class with small blocks recovers control frames faster.
+	// A packet only carries FEC protected frames of a single class,
+	// so control frames are sent unprotected if the packet already carries FEC protected data of a different class.
+	FECControlFrameClass FECProtectionClass
+	// EnableMultipath enables the multipath extension (draft-ietf-quic-multipath).
+	// Multipath is only used if both endpoints enable it and both use connection IDs that are not zero-length.
+	// Additional paths are opened by the client using Connection.AddPath.
+	// This is experimental and the wire format might change.
+	EnableMultipath bool
+	// MaxPaths is the maximum number of paths that can be opened on a multipath connection, including the initial path.
+	// If unset, a default value of 4 is used. Values larger than 64 are invalid.
+	MaxPaths int
+	// MultipathScheduler decides which path a packet is sent on.
+	// If unset, the path with the smallest RTT is used.
+	MultipathScheduler MultipathScheduler
+	// FECRepairPathDiversity sends REPAIR frames on a different path than the source symbols of their block,
+	// if more than one path is available.
+	// This allows recovering from outages affecting all packets sent on one path during a period of time.
+	FECRepairPathDiversity bool
+	// CongestionControl is the congestion control algorithm.
+	// If unset, NewReno is used.
+	// Every path of a multipath connection uses its own instance of the congestion controller.
+	// It is ignored if NewCongestionController is set.
+	CongestionControl CongestionControlAlgorithm
+	// NewCongestionController creates the congestion controller of a path.
+	// It allows using a congestion controller that is not built into quic-go.
+	// It is called for every path, and again when the connection migrates to a new path.
+	NewCongestionController func(congestion.Config) congestion.Controller
+	// EnableAckFrequency enables the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// If both endpoints enable it, the peer is asked to acknowledge packets less frequently once the congestion window is large.
+	// This reduces the number of ACKs sent on the return path, which is useful on asymmetric links.
+	// The ACK frequency of additional paths of a multipath connection is not changed.
+	// This is experimental and the wire format might change.
+	EnableAckFrequency bool
+	// EnableStreamResetPartialDelivery enables the reliable stream reset extension (draft-ietf-quic-reliable-stream-reset).
+	// If both endpoints enable it, SendStream.CancelWriteAt can be used to reset a stream while still
+	// delivering the beginning of the stream reliably.
+	// This is experimental and the wire format might change.
+	EnableStreamResetPartialDelivery bool
+	Tracer                           func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
 }
 
 // ClientHelloInfo contains information about an incoming connection attempt.
@@ -374,6 +593,10 @@ type ClientHelloInfo struct {
 	AddrVerified bool
 }
 
+// A LossEstimate describes the loss pattern on a connection, using a Gilbert-Elliott loss model
+// and a histogram of the lengths of loss bursts.
+type LossEstimate = logging.LossEstimate
+
 // ConnectionState records basic details about a QUIC connection
 type ConnectionState struct {
 	// TLS contains information about the TLS connection state, incl. the tls.ConnectionState.
@@ -0,0 +1,95 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const (
+	// ackFrequencyCwndFraction is the fraction of the congestion window after which we ask the peer to send an ACK.
+	// Receiving 4 ACKs per congestion window is enough to keep the congestion window growing and the pacer busy.
+	ackFrequencyCwndFraction = 4
+	// maxAckElicitingThreshold is the largest Ack-Eliciting Threshold we request.
+	// The number of packets acknowledged by a single ACK shouldn't be too large, as this makes sending bursty.
+	maxAckElicitingThreshold = 64
+)
+
+// The ackFrequencyPolicy decides when to send ACK_FREQUENCY frames (draft-ietf-quic-ack-frequency).
+// As long as the congestion window is small, the peer acknowledges every other packet.
+// Once the congestion window grows, we ask the peer to acknowledge packets less frequently,
+// which saves a lot of bandwidth on asymmetric links.
+// A new value is only requested when the threshold changed by at least a factor of 2,
+// and at most once per RTT.
+type ackFrequencyPolicy struct {
+	enabled         bool
+	peerMinAckDelay time.Duration
+
+	maxDatagramSize protocol.ByteCount
+
+	nextSequenceNumber    uint64
+	ackElicitingThreshold uint64
+	lastUpdate            time.Time
+}
+
+func newAckFrequencyPolicy(maxDatagramSize protocol.ByteCount) *ackFrequencyPolicy {
+	return &ackFrequencyPolicy{
+		maxDatagramSize:       maxDatagramSize,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+	}
+}
+
+// Enable is called when the peer supports the ACK frequency extension.
+func (p *ackFrequencyPolicy) Enable(peerMinAckDelay time.Duration) {
+	p.enabled = true
+	p.peerMinAckDelay = peerMinAckDelay
+}
+
+func (p *ackFrequencyPolicy) SetMaxDatagramSize(s protocol.ByteCount) {
+	p.maxDatagramSize = s
+}
+
+// ackElicitingThresholdFor returns the threshold for a congestion window.
+func (p *ackFrequencyPolicy) ackElicitingThresholdFor(cwnd protocol.ByteCount) uint64 {
+	packets := uint64(cwnd / p.maxDatagramSize)
+	if packets/ackFrequencyCwndFraction <= defaultAckElicitingThreshold+1 {
+		return defaultAckElicitingThreshold
+	}
+	return min(packets/ackFrequencyCwndFraction-1, maxAckElicitingThreshold)
+}
+
+// GetFrame returns an ACK_FREQUENCY frame, if the peer should change its ACK frequency.
+func (p *ackFrequencyPolicy) GetFrame(now time.Time, cwnd protocol.ByteCount, rttStats *utils.RTTStats) *wire.AckFrequencyFrame {
+	if !p.enabled {
+		return nil
+	}
+	threshold := p.ackElicitingThresholdFor(cwnd)
+	if threshold < 2*p.ackElicitingThreshold && 2*threshold > p.ackElicitingThreshold {
+		return nil
+	}
+	srtt := rttStats.SmoothedRTT()
+	if !p.lastUpdate.IsZero() && now.Sub(p.lastUpdate) < srtt {
+		return nil
+	}
+	// The peer's max_ack_delay is used to calculate the PTO.
+	// We never request a larger value, so we don't need to take the requested value into account.
+	maxAckDelay := rttStats.MaxAckDelay()
+	if srtt > 0 {
+		maxAckDelay = min(maxAckDelay, srtt/4)
+	}
+	maxAckDelay = max(maxAckDelay, p.peerMinAckDelay)
+
+	p.ackElicitingThreshold = threshold
+	p.lastUpdate = now
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        p.nextSequenceNumber,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    maxAckDelay,
+		// The peer sends an ACK as soon as reordering would cause us to declare a packet lost.
+		ReorderingThreshold: packetThreshold,
+	}
+	p.nextSequenceNumber++
+	return f
+}
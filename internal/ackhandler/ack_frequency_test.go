@@ -0,0 +1,114 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency policy", func() {
+	const maxDatagramSize = 1000
+
+	var (
+		policy   *ackFrequencyPolicy
+		rttStats *utils.RTTStats
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		policy = newAckFrequencyPolicy(maxDatagramSize)
+		rttStats = utils.NewRTTStats()
+		rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		rttStats.UpdateRTT(200*time.Millisecond, 0, time.Now())
+		now = time.Now()
+	})
+
+	It("doesn't send ACK_FREQUENCY frames unless enabled", func() {
+		Expect(policy.GetFrame(now, 1000*maxDatagramSize, rttStats)).To(BeNil())
+	})
+
+	It("doesn't change the ACK frequency while the congestion window is small", func() {
+		policy.Enable(time.Millisecond)
+		Expect(policy.GetFrame(now, 10*maxDatagramSize, rttStats)).To(BeNil())
+	})
+
+	It("requests less frequent ACKs once the congestion window is large", func() {
+		policy.Enable(time.Millisecond)
+		Expect(policy.GetFrame(now, 100*maxDatagramSize, rttStats)).To(Equal(&wire.AckFrequencyFrame{
+			SequenceNumber:        0,
+			AckElicitingThreshold: 24,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   packetThreshold,
+		}))
+	})
+
+	It("limits the Ack-Eliciting Threshold", func() {
+		policy.Enable(time.Millisecond)
+		f := policy.GetFrame(now, 10000*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+	})
+
+	It("requests a max_ack_delay of a quarter of the RTT", func() {
+		rttStats = utils.NewRTTStats()
+		rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		rttStats.UpdateRTT(40*time.Millisecond, 0, time.Now())
+		policy.Enable(time.Millisecond)
+		f := policy.GetFrame(now, 100*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.RequestMaxAckDelay).To(Equal(10 * time.Millisecond))
+	})
+
+	It("doesn't request a max_ack_delay smaller than the peer's min_ack_delay", func() {
+		rttStats = utils.NewRTTStats()
+		rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		rttStats.UpdateRTT(4*time.Millisecond, 0, time.Now())
+		policy.Enable(5 * time.Millisecond)
+		f := policy.GetFrame(now, 100*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.RequestMaxAckDelay).To(Equal(5 * time.Millisecond))
+	})
+
+	It("only updates the ACK frequency when the threshold changes significantly, and at most once per RTT", func() {
+		policy.Enable(time.Millisecond)
+		f := policy.GetFrame(now, 100*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(24))
+		// the threshold changed significantly, but the last update was less than an RTT ago
+		Expect(policy.GetFrame(now.Add(100*time.Millisecond), 400*maxDatagramSize, rttStats)).To(BeNil())
+		// the threshold didn't change significantly
+		Expect(policy.GetFrame(now.Add(300*time.Millisecond), 150*maxDatagramSize, rttStats)).To(BeNil())
+		f = policy.GetFrame(now.Add(300*time.Millisecond), 50*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(11))
+		// when the congestion window collapses, the peer goes back to acknowledging every other packet
+		f = policy.GetFrame(now.Add(600*time.Millisecond), 2*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(2))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(defaultAckElicitingThreshold))
+	})
+
+	It("takes the maximum datagram size into account", func() {
+		policy.Enable(time.Millisecond)
+		policy.SetMaxDatagramSize(2 * maxDatagramSize)
+		f := policy.GetFrame(now, 100*maxDatagramSize, rttStats)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(11))
+	})
+
+	It("is used by the sent packet handler", func() {
+		handler := newSentPacketHandler(0, protocol.InitialPacketSizeIPv4, rttStats, false, false, 0, 0, nil, protocol.PerspectiveServer, nil, utils.DefaultLogger)
+		Expect(handler.GetAckFrequencyFrame(now)).To(BeNil())
+		handler.EnableAckFrequency(time.Millisecond)
+		// the initial congestion window is 32 packets
+		f := handler.GetAckFrequencyFrame(now)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(7))
+	})
+})
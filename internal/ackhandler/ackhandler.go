@@ -1,6 +1,7 @@
 package ackhandler
 
 import (
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
@@ -9,6 +10,9 @@ import (
 // NewAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler.
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // clientAddressValidated has no effect for a client.
+// fecRepairWindowShare is the share of the congestion window reserved for repair packets,
+// and fecRecoveredLossBackoff the congestion window reduction for losses that the peer recovered using FEC.
+// newCongestionController creates the congestion controller. If nil, NewReno is used.
 func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
@@ -16,10 +20,38 @@ func NewAckHandler(
 	rttStats *utils.RTTStats,
 	clientAddressValidated bool,
 	enableECN bool,
+	fecRepairWindowShare float64,
+	fecRecoveredLossBackoff float64,
+	newCongestionController func(congestion.ControllerConfig) congestion.Controller,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, pers, tracer, logger)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, fecRepairWindowShare, fecRecoveredLossBackoff, newCongestionController, pers, tracer, logger)
 	return sph, newReceivedPacketHandler(sph, logger)
 }
+
+// NewPathAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler for an additional path of a multipath connection.
+// Additional paths are only opened after the handshake is confirmed, so they only use the application data packet number space.
+// The path is validated using PATH_CHALLENGE and PATH_RESPONSE frames, so no amplification limit is enforced.
+// Events are not traced, since the tracer doesn't distinguish between different paths.
+func NewPathAckHandler(
+	initialMaxDatagramSize protocol.ByteCount,
+	rttStats *utils.RTTStats,
+	enableECN bool,
+	fecRepairWindowShare float64,
+	fecRecoveredLossBackoff float64,
+	newCongestionController func(congestion.ControllerConfig) congestion.Controller,
+	pers protocol.Perspective,
+	logger utils.Logger,
+) (SentPacketHandler, ReceivedPacketHandler) {
+	sph := newSentPacketHandler(0, initialMaxDatagramSize, rttStats, true, enableECN, fecRepairWindowShare, fecRecoveredLossBackoff, newCongestionController, pers, nil, logger)
+	sph.peerCompletedAddressValidation = true
+	sph.initialPackets = nil
+	sph.handshakePackets = nil
+	sph.handshakeConfirmed = true
+	rph := newReceivedPacketHandler(sph, logger)
+	rph.DropPackets(protocol.EncryptionInitial)
+	rph.DropPackets(protocol.EncryptionHandshake)
+	return sph, rph
+}
@@ -0,0 +1,29 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ack Handler for additional paths", func() {
+	It("only uses the application data packet number space", func() {
+		sph, rph := NewPathAckHandler(1200, utils.NewRTTStats(), false, 0, 0, nil, protocol.PerspectiveServer, utils.DefaultLogger)
+		// the path isn't subject to the amplification limit
+		Expect(sph.SendMode(time.Now())).To(Equal(SendAny))
+		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+		now := time.Now()
+		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false)
+		// the handshake is confirmed, so the PTO timer is armed
+		Expect(sph.GetLossDetectionTimeout()).ToNot(BeZero())
+		Expect(rph.GetAckFrame(protocol.EncryptionInitial, false)).To(BeNil())
+		Expect(rph.GetAckFrame(protocol.EncryptionHandshake, false)).To(BeNil())
+		Expect(rph.ReceivedPacket(0, protocol.ECNNon, protocol.Encryption1RTT, now, true)).To(Succeed())
+		Expect(rph.GetAckFrame(protocol.Encryption1RTT, false)).ToNot(BeNil())
+	})
+})
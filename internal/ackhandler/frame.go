@@ -10,6 +10,14 @@ type FrameHandler interface {
 	OnLost(wire.Frame)
 }
 
+// RecoverableFrameHandler handles frames that the peer might recover using FEC if they are lost.
+type RecoverableFrameHandler interface {
+	FrameHandler
+	// OnLossResolved sets a callback that is called once it is known whether the peer recovered the lost frame.
+	// It is called before OnLost.
+	OnLossResolved(func(recovered bool))
+}
+
 type Frame struct {
 	Frame   wire.Frame // nil if the frame has already been acknowledged in another packet
 	Handler FrameHandler
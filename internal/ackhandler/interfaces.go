@@ -5,6 +5,7 @@ import (
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 )
 
 // SentPacketHandler handles ACKs received for outgoing packets
@@ -25,6 +26,14 @@ type SentPacketHandler interface {
 	// It is used for pacing packets.
 	TimeUntilSend() time.Time
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// MigratedPath resets the congestion state when the connection starts using a new path.
+	MigratedPath(initialMaxDatagramSize protocol.ByteCount)
+
+	// EnableAckFrequency is called when the peer supports the ACK frequency extension.
+	EnableAckFrequency(peerMinAckDelay time.Duration)
+	// GetAckFrequencyFrame returns an ACK_FREQUENCY frame if the peer should change how often it sends ACKs.
+	// Only to be called once the handshake is confirmed.
+	GetAckFrequencyFrame(now time.Time) *wire.AckFrequencyFrame
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -36,6 +45,10 @@ type SentPacketHandler interface {
 	GetLossDetectionTimeout() time.Time
 	OnLossDetectionTimeout() error
 
+	// LossEstimate returns the current estimate of the loss pattern of 0-RTT and 1-RTT packets.
+	// It is safe to call it concurrently with the other methods.
+	LossEstimate() logging.LossEstimate
+
 	// TODO (ddritzenhoff) it could be that I need something like PacketRecovered(packetNumbers []protocol.PacketNumber) error here. I'm not surey yet.
 }
 
@@ -49,6 +62,9 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, ackEliciting bool) error
 	DropPackets(protocol.EncryptionLevel)
+	// ACK frequency frames are only sent in 1-RTT packets, so they apply to the Application Data packet number space
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	ReceivedImmediateAck()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
@@ -0,0 +1,311 @@
+package ackhandler
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+const (
+	// lossEstimatorWindow is the number of packets the Gilbert-Elliott model is fitted to.
+	lossEstimatorWindow = 2048
+	// lossEstimatorRefitInterval is the number of packets after which the model is fitted again.
+	lossEstimatorRefitInterval = 256
+	// lossEstimatorIterations is the number of Baum-Welch iterations per fit.
+	lossEstimatorIterations = 16
+	// lossEstimatorMinProbability keeps the probabilities away from 0 and 1 while fitting.
+	// The Baum-Welch algorithm can't move a probability away from 0 or 1 once it reached that value.
+	lossEstimatorMinProbability = 1e-4
+	// maxBurstLength is the number of entries of the burst length histogram.
+	maxBurstLength = 16
+)
+
+type packetOutcome struct {
+	pn   protocol.PacketNumber
+	lost bool
+}
+
+// gilbertElliottModel is a two-state Markov model of packet loss.
+// The parameters have the same meaning as in logging.LossEstimate.
+type gilbertElliottModel struct {
+	p, r, h, k float64
+}
+
+// noLossModel is the model reported as long as no packet was lost.
+var noLossModel = gilbertElliottModel{p: 0, r: 1, h: 0, k: 1}
+
+// The lossEstimator fits a Gilbert-Elliott loss model to the sequence of acknowledged and lost packets.
+// Packets are processed in packet number order, so the outcome of a packet is buffered
+// until the outcome of all packets sent before it is known.
+// The model is fitted using the Baum-Welch algorithm to the most recent lossEstimatorWindow packets.
+type lossEstimator struct {
+	pending []packetOutcome
+
+	window          [lossEstimatorWindow]bool // ring buffer, true if the packet was lost
+	windowStart     int
+	windowLen       int
+	numLostInWindow int
+	sinceFit        int
+
+	numPackets, numLost uint64
+	burstLen            int
+	burstLengths        [maxBurstLength]uint64
+
+	model  gilbertElliottModel
+	fitted bool
+	// scratch space for the forward pass
+	alpha [][2]float64
+	scale []float64
+
+	onUpdate func(logging.LossEstimate)
+
+	// The estimate is read by the application, concurrently with the connection's run loop.
+	mutex                sync.Mutex
+	estimate             logging.LossEstimate
+	estimateBurstLengths [maxBurstLength]uint64
+}
+
+func newLossEstimator(onUpdate func(logging.LossEstimate)) *lossEstimator {
+	e := &lossEstimator{model: noLossModel, onUpdate: onUpdate}
+	e.estimate = e.currentEstimate(e.estimateBurstLengths[:0])
+	return e
+}
+
+// ReceivedOutcome records that a packet was acknowledged or declared lost.
+func (e *lossEstimator) ReceivedOutcome(pn protocol.PacketNumber, lost bool) {
+	e.pending = append(e.pending, packetOutcome{pn: pn, lost: lost})
+}
+
+// Flush processes the outcomes of all packets with a packet number smaller than firstOutstanding.
+// If firstOutstanding is protocol.InvalidPacketNumber, no packets are outstanding.
+func (e *lossEstimator) Flush(firstOutstanding protocol.PacketNumber) {
+	if len(e.pending) == 0 {
+		return
+	}
+	slices.SortFunc(e.pending, func(a, b packetOutcome) int { return int(a.pn - b.pn) })
+	var n int
+	for _, o := range e.pending {
+		if firstOutstanding != protocol.InvalidPacketNumber && o.pn >= firstOutstanding {
+			break
+		}
+		e.add(o.lost)
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	e.pending = slices.Delete(e.pending, 0, n)
+
+	var updated bool
+	if e.sinceFit >= lossEstimatorRefitInterval {
+		e.sinceFit = 0
+		e.fit()
+		updated = true
+	}
+	e.mutex.Lock()
+	e.estimate = e.currentEstimate(e.estimateBurstLengths[:0])
+	e.mutex.Unlock()
+	if updated && e.onUpdate != nil {
+		e.onUpdate(e.currentEstimate(nil))
+	}
+}
+
+// Reset discards all outcomes and the fitted model.
+// It is called when the connection migrates to a new path, since the loss pattern of the old path doesn't apply to the new one.
+func (e *lossEstimator) Reset() {
+	e.pending = e.pending[:0]
+	e.windowStart, e.windowLen, e.numLostInWindow, e.sinceFit = 0, 0, 0, 0
+	e.numPackets, e.numLost, e.burstLen = 0, 0, 0
+	e.burstLengths = [maxBurstLength]uint64{}
+	e.model = noLossModel
+	e.fitted = false
+
+	e.mutex.Lock()
+	e.estimate = e.currentEstimate(e.estimateBurstLengths[:0])
+	e.mutex.Unlock()
+}
+
+// Estimate returns the current estimate.
+// It is safe to call it concurrently with the other methods.
+func (e *lossEstimator) Estimate() logging.LossEstimate {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	estimate := e.estimate
+	estimate.BurstLengths = slices.Clone(e.estimate.BurstLengths)
+	return estimate
+}
+
+// currentEstimate returns the current estimate, appending the burst length histogram to burstLengths.
+func (e *lossEstimator) currentEstimate(burstLengths []uint64) logging.LossEstimate {
+	return logging.LossEstimate{
+		P:            e.model.p,
+		R:            e.model.r,
+		H:            e.model.h,
+		K:            e.model.k,
+		NumPackets:   e.numPackets,
+		NumLost:      e.numLost,
+		BurstLengths: append(burstLengths, e.burstLengths[:]...),
+	}
+}
+
+func (e *lossEstimator) add(lost bool) {
+	e.numPackets++
+	if lost {
+		e.numLost++
+		e.burstLen++
+	} else if e.burstLen > 0 {
+		e.burstLengths[min(e.burstLen, maxBurstLength)-1]++
+		e.burstLen = 0
+	}
+
+	if e.windowLen == lossEstimatorWindow {
+		if e.window[e.windowStart] {
+			e.numLostInWindow--
+		}
+		e.window[e.windowStart] = lost
+		e.windowStart = (e.windowStart + 1) % lossEstimatorWindow
+	} else {
+		e.window[(e.windowStart+e.windowLen)%lossEstimatorWindow] = lost
+		e.windowLen++
+	}
+	if lost {
+		e.numLostInWindow++
+	}
+	e.sinceFit++
+}
+
+func (e *lossEstimator) lostAt(t int) bool {
+	return e.window[(e.windowStart+t)%lossEstimatorWindow]
+}
+
+func (e *lossEstimator) fit() {
+	if e.numLostInWindow == 0 {
+		e.model = noLossModel
+		e.fitted = false
+		return
+	}
+	if !e.fitted {
+		e.model = e.initialModel()
+		e.fitted = true
+	}
+	if e.alpha == nil {
+		e.alpha = make([][2]float64, lossEstimatorWindow)
+		e.scale = make([]float64, lossEstimatorWindow)
+	}
+	m := e.model
+	for i := 0; i < lossEstimatorIterations; i++ {
+		m = e.baumWelch(clampModel(m))
+	}
+	// By convention, packets are more likely to be delivered in the good state.
+	if m.h > m.k {
+		m = gilbertElliottModel{p: m.r, r: m.p, h: m.k, k: m.h}
+	}
+	e.model = m
+}
+
+// initialModel derives the starting point of the Baum-Welch algorithm from the simple Gilbert model,
+// in which all packets are delivered in the good state and all packets are lost in the bad state.
+func (e *lossEstimator) initialModel() gilbertElliottModel {
+	var numDelivered, numLost, numDeliveredToLost, numLostToDelivered int
+	for t := 0; t < e.windowLen-1; t++ {
+		cur, next := e.lostAt(t), e.lostAt(t+1)
+		switch {
+		case !cur:
+			numDelivered++
+			if next {
+				numDeliveredToLost++
+			}
+		default:
+			numLost++
+			if !next {
+				numLostToDelivered++
+			}
+		}
+	}
+	m := gilbertElliottModel{p: 0.5, r: 0.5, h: 0.05, k: 0.995}
+	if numDelivered > 0 {
+		m.p = float64(numDeliveredToLost) / float64(numDelivered)
+	}
+	if numLost > 0 {
+		m.r = float64(numLostToDelivered) / float64(numLost)
+	}
+	return m
+}
+
+func clampModel(m gilbertElliottModel) gilbertElliottModel {
+	clamp := func(v float64) float64 {
+		return min(max(v, lossEstimatorMinProbability), 1-lossEstimatorMinProbability)
+	}
+	return gilbertElliottModel{p: clamp(m.p), r: clamp(m.r), h: clamp(m.h), k: clamp(m.k)}
+}
+
+// baumWelch performs a single iteration of the Baum-Welch algorithm, using scaled forward and backward variables.
+// State 0 is the good state, state 1 the bad state.
+func (e *lossEstimator) baumWelch(m gilbertElliottModel) gilbertElliottModel {
+	a := [2][2]float64{{1 - m.p, m.p}, {m.r, 1 - m.r}}
+	deliveryProb := [2]float64{m.k, m.h}
+	b := func(state, t int) float64 {
+		if e.lostAt(t) {
+			return 1 - deliveryProb[state]
+		}
+		return deliveryProb[state]
+	}
+
+	// forward pass, starting from the stationary distribution
+	T := e.windowLen
+	alpha, scale := e.alpha[:T], e.scale[:T]
+	alpha[0] = [2]float64{m.r / (m.p + m.r) * b(0, 0), m.p / (m.p + m.r) * b(1, 0)}
+	scale[0] = alpha[0][0] + alpha[0][1]
+	alpha[0][0] /= scale[0]
+	alpha[0][1] /= scale[0]
+	for t := 1; t < T; t++ {
+		for j := 0; j < 2; j++ {
+			alpha[t][j] = b(j, t) * (alpha[t-1][0]*a[0][j] + alpha[t-1][1]*a[1][j])
+		}
+		scale[t] = alpha[t][0] + alpha[t][1]
+		alpha[t][0] /= scale[t]
+		alpha[t][1] /= scale[t]
+	}
+
+	// backward pass, accumulating the expected number of transitions and state occupancies
+	var transitions [2][2]float64
+	var occupancy, delivered [2]float64
+	beta := [2]float64{1, 1}
+	for t := T - 1; t >= 0; t-- {
+		if t < T-1 {
+			var next [2]float64
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					v := a[i][j] * b(j, t+1) * beta[j] / scale[t+1]
+					transitions[i][j] += alpha[t][i] * v
+					next[i] += v
+				}
+			}
+			beta = next
+		}
+		g0, g1 := alpha[t][0]*beta[0], alpha[t][1]*beta[1]
+		sum := g0 + g1
+		occupancy[0] += g0 / sum
+		occupancy[1] += g1 / sum
+		if !e.lostAt(t) {
+			delivered[0] += g0 / sum
+			delivered[1] += g1 / sum
+		}
+	}
+
+	if s := transitions[0][0] + transitions[0][1]; s > 0 {
+		m.p = transitions[0][1] / s
+	}
+	if s := transitions[1][0] + transitions[1][1]; s > 0 {
+		m.r = transitions[1][0] / s
+	}
+	if occupancy[0] > 0 {
+		m.k = delivered[0] / occupancy[0]
+	}
+	if occupancy[1] > 0 {
+		m.h = delivered[1] / occupancy[1]
+	}
+	return m
+}
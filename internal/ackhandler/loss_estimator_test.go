@@ -0,0 +1,136 @@
+package ackhandler
+
+import (
+	"math/rand"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Loss Estimator", func() {
+	var (
+		estimator *lossEstimator
+		updates   []logging.LossEstimate
+	)
+
+	BeforeEach(func() {
+		updates = nil
+		estimator = newLossEstimator(func(e logging.LossEstimate) { updates = append(updates, e) })
+	})
+
+	// simulate runs a Gilbert-Elliott channel and passes the outcome of n packets to the estimator
+	simulate := func(r *rand.Rand, n int, m gilbertElliottModel) {
+		bad := false
+		var pn protocol.PacketNumber
+		for i := 0; i < n; i++ {
+			if bad {
+				bad = r.Float64() >= m.r
+			} else {
+				bad = r.Float64() < m.p
+			}
+			deliveryProb := m.k
+			if bad {
+				deliveryProb = m.h
+			}
+			estimator.ReceivedOutcome(pn, r.Float64() >= deliveryProb)
+			estimator.Flush(protocol.InvalidPacketNumber)
+			pn++
+		}
+	}
+
+	It("reports a loss-free path", func() {
+		for pn := protocol.PacketNumber(0); pn < 1000; pn++ {
+			estimator.ReceivedOutcome(pn, false)
+			estimator.Flush(protocol.InvalidPacketNumber)
+		}
+		e := estimator.Estimate()
+		Expect(e.P).To(BeZero())
+		Expect(e.K).To(Equal(1.0))
+		Expect(e.NumPackets).To(BeEquivalentTo(1000))
+		Expect(e.NumLost).To(BeZero())
+		Expect(e.BurstLengths).To(HaveLen(maxBurstLength))
+		Expect(updates).To(HaveLen(1000 / lossEstimatorRefitInterval))
+	})
+
+	It("processes outcomes in packet number order", func() {
+		// packet 2 is declared lost after 4 and 5 were acknowledged, packet 3 was never acknowledged nor lost
+		estimator.ReceivedOutcome(1, false)
+		estimator.ReceivedOutcome(4, false)
+		estimator.ReceivedOutcome(5, false)
+		estimator.ReceivedOutcome(2, true)
+		estimator.ReceivedOutcome(7, true)
+		estimator.Flush(6)
+		e := estimator.Estimate()
+		Expect(e.NumPackets).To(BeEquivalentTo(4))
+		Expect(e.NumLost).To(BeEquivalentTo(1))
+		Expect(e.BurstLengths[0]).To(BeEquivalentTo(1))
+		// packet 7 is only processed once packet 6 is acknowledged or lost
+		estimator.ReceivedOutcome(6, true)
+		estimator.ReceivedOutcome(8, false)
+		estimator.Flush(protocol.InvalidPacketNumber)
+		e = estimator.Estimate()
+		Expect(e.NumPackets).To(BeEquivalentTo(7))
+		Expect(e.NumLost).To(BeEquivalentTo(3))
+		Expect(e.BurstLengths[0]).To(BeEquivalentTo(1))
+		Expect(e.BurstLengths[1]).To(BeEquivalentTo(1))
+	})
+
+	It("counts long bursts in the last bucket of the histogram", func() {
+		var pn protocol.PacketNumber
+		for i := 0; i < 2*maxBurstLength; i++ {
+			estimator.ReceivedOutcome(pn, true)
+			pn++
+		}
+		estimator.ReceivedOutcome(pn, false)
+		estimator.Flush(protocol.InvalidPacketNumber)
+		Expect(estimator.Estimate().BurstLengths[maxBurstLength-1]).To(BeEquivalentTo(1))
+	})
+
+	It("returns a copy of the burst length histogram", func() {
+		estimator.ReceivedOutcome(0, true)
+		estimator.ReceivedOutcome(1, false)
+		estimator.Flush(protocol.InvalidPacketNumber)
+		e := estimator.Estimate()
+		e.BurstLengths[0] = 42
+		Expect(estimator.Estimate().BurstLengths[0]).To(BeEquivalentTo(1))
+	})
+
+	It("estimates the parameters of random loss", func() {
+		simulate(rand.New(rand.NewSource(1)), 4*lossEstimatorWindow, gilbertElliottModel{p: 0.5, r: 0.5, h: 0.9, k: 0.9})
+		e := estimator.Estimate()
+		// The states can't be told apart, but the loss rate of the model matches the observed loss rate.
+		lossRate := (e.R*(1-e.K) + e.P*(1-e.H)) / (e.P + e.R)
+		Expect(lossRate).To(BeNumerically("~", 0.1, 0.02))
+		Expect(e.BurstLengths[3]).To(BeNumerically("<", e.BurstLengths[0]/100))
+	})
+
+	It("estimates the parameters of bursty loss", func() {
+		simulate(rand.New(rand.NewSource(2)), 4*lossEstimatorWindow, gilbertElliottModel{p: 0.03, r: 0.3, h: 0.05, k: 0.995})
+		Expect(updates).ToNot(BeEmpty())
+		e := estimator.Estimate()
+		Expect(e.P).To(BeNumerically("~", 0.03, 0.015))
+		Expect(e.R).To(BeNumerically("~", 0.3, 0.1))
+		Expect(e.H).To(BeNumerically("<", 0.2))
+		Expect(e.K).To(BeNumerically(">", 0.98))
+		Expect(e.BurstLengths[2]).ToNot(BeZero())
+	})
+
+	It("resets", func() {
+		simulate(rand.New(rand.NewSource(3)), lossEstimatorWindow, gilbertElliottModel{p: 0.03, r: 0.3, h: 0.05, k: 0.995})
+		estimator.ReceivedOutcome(protocol.PacketNumber(lossEstimatorWindow+1), true)
+		estimator.Reset()
+		e := estimator.Estimate()
+		Expect(e.NumPackets).To(BeZero())
+		Expect(e.NumLost).To(BeZero())
+		Expect(e.P).To(BeZero())
+		Expect(e.K).To(Equal(1.0))
+		Expect(e.BurstLengths).To(Equal(make([]uint64, maxBurstLength)))
+		// outcomes buffered before the reset are discarded
+		estimator.ReceivedOutcome(protocol.PacketNumber(lossEstimatorWindow+2), false)
+		estimator.Flush(protocol.InvalidPacketNumber)
+		Expect(estimator.Estimate().NumPackets).To(BeEquivalentTo(1))
+	})
+})
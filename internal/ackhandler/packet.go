@@ -18,6 +18,7 @@ type packet struct {
 	EncryptionLevel protocol.EncryptionLevel
 
 	IsPathMTUProbePacket bool // We don't report the loss of Path MTU probe packets to the congestion controller.
+	isRepairPacket       bool // The packet only contains REPAIR frames.
 
 	includedInBytesInFlight bool
 	declaredLost            bool
@@ -40,6 +41,7 @@ func getPacket() *packet {
 	p.EncryptionLevel = protocol.EncryptionLevel(0)
 	p.SendTime = time.Time{}
 	p.IsPathMTUProbePacket = false
+	p.isRepairPacket = false
 	p.includedInBytesInFlight = false
 	p.declaredLost = false
 	p.skippedPacket = false
@@ -135,6 +135,22 @@ func (h *receivedPacketHistory) GetHighestAckRange() wire.AckRange {
 	return ackRange
 }
 
+// SmallestMissingAbove returns the smallest packet number larger than p that wasn't received,
+// if a larger packet number was received.
+func (h *receivedPacketHistory) SmallestMissingAbove(p protocol.PacketNumber) (protocol.PacketNumber, bool) {
+	candidate := p + 1
+	for el := h.ranges.Front(); el != nil; el = el.Next() {
+		if el.Value.End < candidate {
+			continue
+		}
+		if el.Value.Start > candidate {
+			return candidate, true
+		}
+		candidate = el.Value.End + 1
+	}
+	return 0, false
+}
+
 func (h *receivedPacketHistory) IsPotentiallyDuplicate(p protocol.PacketNumber) bool {
 	if p < h.deletedBelow {
 		return true
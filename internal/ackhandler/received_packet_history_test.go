@@ -259,6 +259,30 @@ var _ = Describe("receivedPacketHistory", func() {
 		})
 	})
 
+	Context("finding missing packets", func() {
+		It("doesn't find missing packets if there are no ranges", func() {
+			_, ok := hist.SmallestMissingAbove(0)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("finds the smallest missing packet", func() {
+			for _, pn := range []protocol.PacketNumber{1, 2, 3, 5, 6, 9} {
+				Expect(hist.ReceivedPacket(pn)).To(BeTrue())
+			}
+			pn, ok := hist.SmallestMissingAbove(0)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(4)))
+			pn, ok = hist.SmallestMissingAbove(4)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(7)))
+			pn, ok = hist.SmallestMissingAbove(7)
+			Expect(ok).To(BeTrue())
+			Expect(pn).To(Equal(protocol.PacketNumber(8)))
+			_, ok = hist.SmallestMissingAbove(8)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	Context("duplicate detection", func() {
 		It("doesn't declare the first packet a duplicate", func() {
 			Expect(hist.IsPotentiallyDuplicate(5)).To(BeFalse())
@@ -70,11 +70,16 @@ func (h *receivedPacketTracker) IsPotentiallyDuplicate(pn protocol.PacketNumber)
 	return h.packetHistory.IsPotentiallyDuplicate(pn)
 }
 
-// number of ack-eliciting packets received before sending an ACK
-const packetsBeforeAck = 2
+// number of ack-eliciting packets received without sending an ACK, before an ACK is sent,
+// unless the peer requests a different threshold in an ACK_FREQUENCY frame
+const defaultAckElicitingThreshold = 1
+
+// By default, an ACK is sent immediately when a packet is received out of order (RFC 9000, section 13.2.1).
+const defaultReorderingThreshold = 1
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
 // It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// Using the ACK frequency extension, the peer can change both values.
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -89,6 +94,12 @@ type appDataReceivedPacketTracker struct {
 	ackElicitingPacketsReceivedSinceLastAck int
 	ackAlarm                                time.Time
 
+	// values requested by the peer in ACK_FREQUENCY frames
+	ackElicitingThreshold uint64
+	reorderingThreshold   uint64
+	// the sequence number expected for the next ACK_FREQUENCY frame
+	nextAckFrequencySeq uint64
+
 	logger utils.Logger
 }
 
@@ -96,6 +107,8 @@ func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacket
 	h := &appDataReceivedPacketTracker{
 		receivedPacketTracker: *newReceivedPacketTracker(),
 		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		reorderingThreshold:   defaultReorderingThreshold,
 		logger:                logger,
 	}
 	return h
@@ -157,6 +170,43 @@ func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
 	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
 }
 
+// ReceivedAckFrequencyFrame applies the values requested by the peer.
+// ACK_FREQUENCY frames that arrive out of order are ignored.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if f.SequenceNumber < h.nextAckFrequencySeq {
+		return
+	}
+	h.nextAckFrequencySeq = f.SequenceNumber + 1
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.reorderingThreshold = f.ReorderingThreshold
+	// the requested max_ack_delay includes the timer granularity
+	h.maxAckDelay = max(f.RequestMaxAckDelay-protocol.TimerGranularity, 0)
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ACK frequency: Ack-Eliciting Threshold: %d, Max Ack Delay: %s, Reordering Threshold: %d", h.ackElicitingThreshold, h.maxAckDelay, h.reorderingThreshold)
+	}
+}
+
+// ReceivedImmediateAck queues an ACK.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAck() {
+	h.logger.Debugf("\tQueueing ACK because an IMMEDIATE_ACK frame was received.")
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
+// hasReorderingExceededThreshold says if the smallest missing packet that wasn't reported in an ACK yet
+// is more than reordering threshold packets below the largest packet received,
+// as described in section 6.2 of draft-ietf-quic-ack-frequency.
+func (h *appDataReceivedPacketTracker) hasReorderingExceededThreshold() bool {
+	if h.lastAck == nil {
+		return false
+	}
+	missing, ok := h.packetHistory.SmallestMissingAbove(h.lastAck.LargestAcked())
+	if !ok {
+		return false
+	}
+	return uint64(h.largestObserved-missing) >= h.reorderingThreshold
+}
+
 func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber, ecn protocol.ECN, wasMissing bool) bool {
 	// always acknowledge the first packet
 	if h.lastAck == nil {
@@ -167,25 +217,36 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	// With a reordering threshold larger than 1, only the reordering threshold is taken into account.
+	if wasMissing && h.reorderingThreshold == 1 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once more than ack-eliciting threshold ack-eliciting packets were received
+	if uint64(h.ackElicitingPacketsReceivedSinceLastAck) > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
 
 	// queue an ACK if there are new missing packets to report
-	if h.hasNewMissingPackets() {
-		h.logger.Debugf("\tQueuing ACK because there's a new missing packet to report.")
-		return true
+	switch h.reorderingThreshold {
+	case 0:
+		// the peer asked us to not send ACKs because of reordering
+	case 1:
+		if h.hasNewMissingPackets() {
+			h.logger.Debugf("\tQueuing ACK because there's a new missing packet to report.")
+			return true
+		}
+	default:
+		if h.hasReorderingExceededThreshold() {
+			h.logger.Debugf("\tQueuing ACK because the reordering threshold was exceeded.")
+			return true
+		}
 	}
 
 	// queue an ACK if the packet was ECN-CE marked
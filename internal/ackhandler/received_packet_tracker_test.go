@@ -236,6 +236,85 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
+
+			Context("ACK frequency", func() {
+				It("uses the Ack-Eliciting Threshold", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 4, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 1})
+					p := protocol.PacketNumber(11)
+					for i := 0; i < 3; i++ {
+						for j := 0; j < 4; j++ {
+							Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+							Expect(tracker.ackQueued).To(BeFalse())
+							p++
+						}
+						Expect(tracker.ReceivedPacket(p, protocol.ECNNon, time.Time{}, true)).To(Succeed())
+						Expect(tracker.ackQueued).To(BeTrue())
+						p++
+						Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+					}
+				})
+
+				It("uses the requested max_ack_delay", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 1})
+					rcvTime := time.Now()
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(100*time.Millisecond - protocol.TimerGranularity)))
+				})
+
+				It("ignores reordered ACK_FREQUENCY frames", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 5, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 1})
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 1, RequestMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 1})
+					Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(5))
+					Expect(tracker.maxAckDelay).To(Equal(100*time.Millisecond - protocol.TimerGranularity))
+				})
+
+				It("doesn't queue ACKs for reordered packets if the Reordering Threshold is 0", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 0})
+					Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAckFrame(false)).ToNot(BeNil())
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+				})
+
+				It("queues an ACK when the Reordering Threshold is exceeded", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 3})
+					// 11 is missing
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+					ack := tracker.GetAckFrame(true)
+					Expect(ack.AckRanges).To(Equal([]wire.AckRange{{Smallest: 12, Largest: 14}, {Smallest: 1, Largest: 10}}))
+					// 11 was reported missing, 15 is missing now
+					Expect(tracker.ReceivedPacket(16, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ReceivedPacket(17, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.ReceivedPacket(18, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeTrue())
+				})
+
+				It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+					receiveAndAck10Packets()
+					tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestMaxAckDelay: 100 * time.Millisecond, ReorderingThreshold: 1})
+					Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					tracker.ReceivedImmediateAck()
+					Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+					Expect(tracker.GetAlarmTimeout()).To(BeZero())
+					ack := tracker.GetAckFrame(true)
+					Expect(ack).ToNot(BeNil())
+					Expect(ack.LargestAcked()).To(Equal(protocol.PacketNumber(12)))
+				})
+			})
 		})
 
 		Context("ACK generation", func() {
@@ -25,8 +25,20 @@ const (
 	minRTTAfterRetry = 5 * time.Millisecond
 	// The PTO duration uses exponential backoff, but is truncated to a maximum value, as allowed by RFC 8961, section 4.4.
 	maxPTODuration = 60 * time.Second
+	// If it's not known whether the peer recovered a lost FEC protected packet after this many PTOs,
+	// the loss is treated like the loss of an unprotected packet.
+	deferredLossTimeoutPTOs = 3
 )
 
+// A deferredLoss is the loss of a FEC protected packet.
+// The congestion response is deferred until it's known whether the peer recovered the packet.
+type deferredLoss struct {
+	pn       protocol.PacketNumber
+	length   protocol.ByteCount
+	deadline time.Time
+	resolved bool
+}
+
 type packetNumberSpace struct {
 	history *sentPacketHistory
 	pns     packetNumberGenerator
@@ -78,9 +90,23 @@ type sentPacketHandler struct {
 	ackedPackets []*packet // to avoid allocations in detectAndRemoveAckedPackets
 
 	bytesInFlight protocol.ByteCount
-
-	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	// bytes in flight in packets that only contain REPAIR frames, included in bytesInFlight
+	repairBytesInFlight protocol.ByteCount
+	// the share of the congestion window that repair packets can use without counting against the congestion window
+	fecRepairWindowShare float64
+	// Is the congestion response to the loss of a FEC protected packet deferred until it's known whether the peer recovered it?
+	deferRecoverableLosses bool
+	// losses of FEC protected packets that the congestion controller wasn't notified of yet
+	deferredLosses []*deferredLoss
+	// the factor by which the congestion window is reduced when the peer recovered a lost packet, used when resetting the congestion controller
+	fecRecoveredLossBackoff float64
+
+	congestion              congestion.SendAlgorithmWithDebugInfos
+	newCongestionController func(congestion.ControllerConfig) congestion.Controller
+	rttStats                *utils.RTTStats
+
+	lossEstimator *lossEstimator
+	ackFrequency  *ackFrequencyPolicy
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -114,18 +140,13 @@ func newSentPacketHandler(
 	rttStats *utils.RTTStats,
 	clientAddressValidated bool,
 	enableECN bool,
+	fecRepairWindowShare float64,
+	fecRecoveredLossBackoff float64,
+	newCongestionController func(congestion.ControllerConfig) congestion.Controller,
 	pers protocol.Perspective,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
-
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
 		peerAddressValidated:           pers == protocol.PerspectiveClient || clientAddressValidated,
@@ -133,11 +154,21 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false),
 		appDataPackets:                 newPacketNumberSpace(0, true),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		ackFrequency:                   newAckFrequencyPolicy(initialMaxDatagramSize),
+		congestion:                     newSendAlgorithm(newCongestionController, rttStats, initialMaxDatagramSize, fecRecoveredLossBackoff, tracer),
+		newCongestionController:        newCongestionController,
+		fecRepairWindowShare:           fecRepairWindowShare,
+		deferRecoverableLosses:         fecRecoveredLossBackoff > 0,
+		fecRecoveredLossBackoff:        fecRecoveredLossBackoff,
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
+	h.lossEstimator = newLossEstimator(func(estimate logging.LossEstimate) {
+		if h.tracer != nil && h.tracer.UpdatedLossEstimate != nil {
+			h.tracer.UpdatedLossEstimate(estimate)
+		}
+	})
 	if enableECN {
 		h.enableECN = true
 		h.ecnTracker = newECNTracker(logger, tracer)
@@ -145,16 +176,48 @@ func newSentPacketHandler(
 	return h
 }
 
+func newSendAlgorithm(
+	newController func(congestion.ControllerConfig) congestion.Controller,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	recoveredLossBackoff float64,
+	tracer *logging.ConnectionTracer,
+) congestion.SendAlgorithmWithDebugInfos {
+	if newController == nil {
+		newController = congestion.NewRenoController
+	}
+	controller := newController(congestion.ControllerConfig{
+		RTTStats:                rttStats,
+		InitialMaxDatagramSize:  initialMaxDatagramSize,
+		FECRecoveredLossBackoff: recoveredLossBackoff,
+		Tracer:                  tracer,
+	})
+	return congestion.NewControllerSender(controller, rttStats)
+}
+
 func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 	if p.includedInBytesInFlight {
 		if p.Length > h.bytesInFlight {
 			panic("negative bytes_in_flight")
 		}
 		h.bytesInFlight -= p.Length
+		if p.isRepairPacket {
+			h.repairBytesInFlight -= p.Length
+		}
 		p.includedInBytesInFlight = false
 	}
 }
 
+// congestionBytesInFlight returns the bytes in flight that count against the congestion window.
+// Repair packets don't count against the congestion window, as long as they fit into their share of it.
+func (h *sentPacketHandler) congestionBytesInFlight() protocol.ByteCount {
+	if h.fecRepairWindowShare == 0 {
+		return h.bytesInFlight
+	}
+	repairBudget := protocol.ByteCount(h.fecRepairWindowShare * float64(h.congestion.GetCongestionWindow()))
+	return h.bytesInFlight - min(h.repairBytesInFlight, repairBudget)
+}
+
 func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	// The server won't await address validation after the handshake is confirmed.
 	// This applies even if we didn't receive an ACK for a Handshake packet.
@@ -252,15 +315,19 @@ func (h *sentPacketHandler) SentPacket(
 
 	pnSpace.largestSent = pn
 	isAckEliciting := len(streamFrames) > 0 || len(frames) > 0
+	isRepairPacket := isAckEliciting && isRepairPacket(streamFrames, frames)
 
 	if isAckEliciting {
 		pnSpace.lastAckElicitingPacketTime = t
 		h.bytesInFlight += size
+		if isRepairPacket {
+			h.repairBytesInFlight += size
+		}
 		if h.numProbesToSend > 0 {
 			h.numProbesToSend--
 		}
 	}
-	h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
+	h.congestion.OnPacketSent(t, h.congestionBytesInFlight(), pn, size, isAckEliciting)
 
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 		h.ecnTracker.SentPacket(pn, ecn)
@@ -283,6 +350,7 @@ func (h *sentPacketHandler) SentPacket(
 	p.StreamFrames = streamFrames
 	p.Frames = frames
 	p.IsPathMTUProbePacket = isPathMTUProbePacket
+	p.isRepairPacket = isRepairPacket
 	p.includedInBytesInFlight = true
 
 	pnSpace.history.SentAckElicitingPacket(p)
@@ -292,6 +360,18 @@ func (h *sentPacketHandler) SentPacket(
 	h.setLossDetectionTimer()
 }
 
+func isRepairPacket(streamFrames []StreamFrame, frames []Frame) bool {
+	if len(streamFrames) > 0 {
+		return false
+	}
+	for _, f := range frames {
+		if _, ok := f.Frame.(*wire.RepairFrame); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *sentPacketHandler) getPacketNumberSpace(encLevel protocol.EncryptionLevel) *packetNumberSpace {
 	switch encLevel {
 	case protocol.EncryptionInitial:
@@ -325,7 +405,7 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		h.setLossDetectionTimer()
 	}
 
-	priorInFlight := h.bytesInFlight
+	priorInFlight := h.congestionBytesInFlight()
 	ackedPackets, err := h.detectAndRemoveAckedPackets(ack, encLevel)
 	if err != nil || len(ackedPackets) == 0 {
 		return false, err
@@ -367,9 +447,15 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		if p.EncryptionLevel == protocol.Encryption1RTT {
 			acked1RTTPacket = true
 		}
+		if pnSpace == h.appDataPackets && !p.declaredLost && !p.IsPathMTUProbePacket {
+			h.lossEstimator.ReceivedOutcome(p.PacketNumber, false)
+		}
 		h.removeFromBytesInFlight(p)
 		putPacket(p)
 	}
+	if pnSpace == h.appDataPackets {
+		h.updateLossEstimate()
+	}
 	// After this point, we must not use ackedPackets any longer!
 	// We've already returned the buffers.
 	ackedPackets = nil //nolint:ineffassign // This is just to be on the safe side.
@@ -616,7 +702,8 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 	// Packets sent before this time are deemed lost.
 	lostSendTime := now.Add(-lossDelay)
 
-	priorInFlight := h.bytesInFlight
+	h.expireDeferredLosses(now)
+	priorInFlight := h.congestionBytesInFlight()
 	return pnSpace.history.Iterate(func(p *packet) (bool, error) {
 		if p.PacketNumber > pnSpace.largestAcked {
 			return false, nil
@@ -656,19 +743,93 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 			if !p.skippedPacket {
 				// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 				h.removeFromBytesInFlight(p)
+				// If the peer might recover the packet using FEC, the congestion controller is notified once that's known.
+				// This needs to happen before the frames are declared lost.
+				congestionEvent := !p.IsPathMTUProbePacket && !h.deferCongestionEvent(p, now)
 				h.queueFramesForRetransmission(p)
-				if !p.IsPathMTUProbePacket {
+				if congestionEvent {
 					h.congestion.OnCongestionEvent(p.PacketNumber, p.Length, priorInFlight)
 				}
 				if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 					h.ecnTracker.LostPacket(p.PacketNumber)
 				}
+				if pnSpace == h.appDataPackets && !p.IsPathMTUProbePacket {
+					h.lossEstimator.ReceivedOutcome(p.PacketNumber, true)
+				}
 			}
 		}
 		return true, nil
 	})
 }
 
+// updateLossEstimate passes the outcome of all packets sent before the first outstanding packet to the loss estimator.
+func (h *sentPacketHandler) updateLossEstimate() {
+	firstOutstanding := protocol.InvalidPacketNumber
+	if p := h.appDataPackets.history.FirstOutstanding(); p != nil {
+		firstOutstanding = p.PacketNumber
+	}
+	h.lossEstimator.Flush(firstOutstanding)
+}
+
+func (h *sentPacketHandler) LossEstimate() logging.LossEstimate {
+	return h.lossEstimator.Estimate()
+}
+
+// deferCongestionEvent defers the congestion response to the loss of a FEC protected packet
+// until it's known whether the peer recovered the packet.
+func (h *sentPacketHandler) deferCongestionEvent(p *packet, now time.Time) bool {
+	if !h.deferRecoverableLosses {
+		return false
+	}
+	for _, f := range p.Frames {
+		handler, ok := f.Handler.(RecoverableFrameHandler)
+		if !ok {
+			continue
+		}
+		l := &deferredLoss{
+			pn:       p.PacketNumber,
+			length:   p.Length,
+			deadline: now.Add(deferredLossTimeoutPTOs * h.rttStats.PTO(true)),
+		}
+		h.deferredLosses = append(h.deferredLosses, l)
+		handler.OnLossResolved(func(recovered bool) { h.resolveDeferredLoss(l, recovered) })
+		return true
+	}
+	return false
+}
+
+// resolveDeferredLoss notifies the congestion controller of a deferred loss.
+// The loss might be resolved after other packets were acknowledged or lost,
+// so the congestion controller uses the current bytes in flight.
+func (h *sentPacketHandler) resolveDeferredLoss(l *deferredLoss, recovered bool) {
+	if l.resolved {
+		return
+	}
+	l.resolved = true
+	if recovered {
+		h.congestion.OnRecoveredLoss(l.pn, l.length, h.congestionBytesInFlight())
+	} else {
+		h.congestion.OnCongestionEvent(l.pn, l.length, h.congestionBytesInFlight())
+	}
+}
+
+// expireDeferredLosses applies the normal congestion response to deferred losses that weren't resolved in time.
+// This happens if the state of the FEC protected packets is lost, e.g. when the FEC scheme changes after 0-RTT is rejected.
+func (h *sentPacketHandler) expireDeferredLosses(now time.Time) {
+	var n int
+	for _, l := range h.deferredLosses {
+		if !l.resolved && !now.Before(l.deadline) {
+			h.resolveDeferredLoss(l, false)
+		}
+		if !l.resolved {
+			h.deferredLosses[n] = l
+			n++
+		}
+	}
+	clear(h.deferredLosses[n:])
+	h.deferredLosses = h.deferredLosses[:n]
+}
+
 func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 	defer h.setLossDetectionTimer()
 	earliestLossTime, encLevel := h.getLossTimeAndSpace()
@@ -680,10 +841,17 @@ func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 			h.tracer.LossTimerExpired(logging.TimerTypeACK, encLevel)
 		}
 		// Early retransmit or time loss detection
-		return h.detectLostPackets(time.Now(), encLevel)
+		if err := h.detectLostPackets(time.Now(), encLevel); err != nil {
+			return err
+		}
+		if encLevel == protocol.Encryption1RTT {
+			h.updateLossEstimate()
+		}
+		return nil
 	}
 
 	// PTO
+	h.expireDeferredLosses(time.Now())
 	// When all outstanding are acknowledged, the alarm is canceled in
 	// setLossDetectionTimer. This doesn't reset the timer in the session though.
 	// When OnAlarm is called, we therefore need to make sure that there are
@@ -799,9 +967,9 @@ func (h *sentPacketHandler) SendMode(now time.Time) SendMode {
 		return h.ptoMode
 	}
 	// Only send ACKs if we're congestion limited.
-	if !h.congestion.CanSend(h.bytesInFlight) {
+	if !h.congestion.CanSend(h.congestionBytesInFlight()) {
 		if h.logger.Debug() {
-			h.logger.Debugf("Congestion limited: bytes in flight %d, window %d", h.bytesInFlight, h.congestion.GetCongestionWindow())
+			h.logger.Debugf("Congestion limited: bytes in flight %d, window %d", h.congestionBytesInFlight(), h.congestion.GetCongestionWindow())
 		}
 		return SendAck
 	}
@@ -818,11 +986,20 @@ func (h *sentPacketHandler) SendMode(now time.Time) SendMode {
 }
 
 func (h *sentPacketHandler) TimeUntilSend() time.Time {
-	return h.congestion.TimeUntilSend(h.bytesInFlight)
+	return h.congestion.TimeUntilSend(h.congestionBytesInFlight())
 }
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
 	h.congestion.SetMaxDatagramSize(s)
+	h.ackFrequency.SetMaxDatagramSize(s)
+}
+
+func (h *sentPacketHandler) EnableAckFrequency(peerMinAckDelay time.Duration) {
+	h.ackFrequency.Enable(peerMinAckDelay)
+}
+
+func (h *sentPacketHandler) GetAckFrequencyFrame(now time.Time) *wire.AckFrequencyFrame {
+	return h.ackFrequency.GetFrame(now, h.congestion.GetCongestionWindow(), h.rttStats)
 }
 
 func (h *sentPacketHandler) isAmplificationLimited() bool {
@@ -866,6 +1043,7 @@ func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 
 func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	h.bytesInFlight = 0
+	h.repairBytesInFlight = 0
 	var firstPacketSendTime time.Time
 	h.initialPackets.history.Iterate(func(p *packet) (bool, error) {
 		if firstPacketSendTime.IsZero() {
@@ -914,6 +1092,37 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	return nil
 }
 
+// MigratedPath is called when the connection starts using a new path.
+// The packets sent on the old path are declared lost, and their frames are retransmitted on the new path.
+// The congestion controller, the RTT estimate and the loss estimate are reset, since they only apply to the old path.
+func (h *sentPacketHandler) MigratedPath(initialMaxDatagramSize protocol.ByteCount) {
+	h.appDataPackets.history.Iterate(func(p *packet) (bool, error) {
+		if p.declaredLost || p.skippedPacket {
+			return true, nil
+		}
+		h.appDataPackets.history.DeclareLost(p.PacketNumber)
+		h.removeFromBytesInFlight(p)
+		h.queueFramesForRetransmission(p)
+		return true, nil
+	})
+	h.appDataPackets.lossTime = time.Time{}
+
+	h.rttStats.OnConnectionMigration()
+	h.congestion = newSendAlgorithm(h.newCongestionController, h.rttStats, initialMaxDatagramSize, h.fecRecoveredLossBackoff, h.tracer)
+	h.ackFrequency.SetMaxDatagramSize(initialMaxDatagramSize)
+	h.lossEstimator.Reset()
+	if h.enableECN {
+		h.ecnTracker = newECNTracker(h.logger, h.tracer)
+	}
+	if h.tracer != nil && h.tracer.UpdatedPTOCount != nil && h.ptoCount != 0 {
+		h.tracer.UpdatedPTOCount(0)
+	}
+	h.ptoCount = 0
+	h.numProbesToSend = 0
+	h.ptoMode = SendNone
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	if h.initialPackets != nil {
 		panic("didn't drop initial correctly")
@@ -31,6 +31,13 @@ func (h *customFrameHandler) OnAcked(f wire.Frame) {
 	}
 }
 
+type recoverableFrameHandler struct {
+	customFrameHandler
+	onLossResolved func(recovered bool)
+}
+
+func (h *recoverableFrameHandler) OnLossResolved(f func(recovered bool)) { h.onLossResolved = f }
+
 var _ = Describe("SentPacketHandler", func() {
 	var (
 		handler     *sentPacketHandler
@@ -44,7 +51,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, 0, 0, nil, perspective, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -677,6 +684,130 @@ var _ = Describe("SentPacketHandler", func() {
 			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(t)
 			Expect(handler.TimeUntilSend()).To(Equal(t))
 		})
+
+		Context("FEC", func() {
+			repairPacket := func(pn protocol.PacketNumber, length protocol.ByteCount) *packet {
+				return &packet{
+					PacketNumber:    pn,
+					Length:          length,
+					EncryptionLevel: protocol.Encryption1RTT,
+					SendTime:        time.Now(),
+					Frames:          []Frame{{Frame: &wire.RepairFrame{}}},
+				}
+			}
+
+			It("counts repair packets against the congestion window by default", func() {
+				handler.ReceivedPacket(protocol.EncryptionHandshake)
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 100}))
+				sentPacket(repairPacket(2, 200))
+				Expect(handler.repairBytesInFlight).To(BeEquivalentTo(200))
+				cong.EXPECT().CanSend(protocol.ByteCount(300)).Return(true)
+				cong.EXPECT().HasPacingBudget(gomock.Any()).Return(true)
+				handler.SendMode(time.Now())
+			})
+
+			It("doesn't count repair packets within their share of the congestion window", func() {
+				handler.ReceivedPacket(protocol.EncryptionHandshake)
+				handler.fecRepairWindowShare = 0.1
+				cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000)).AnyTimes()
+				gomock.InOrder(
+					cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(100), gomock.Any(), gomock.Any(), gomock.Any()),
+					cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(100), gomock.Any(), gomock.Any(), gomock.Any()),
+					// the second repair packet exceeds the share
+					cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(190), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 100}))
+				sentPacket(repairPacket(2, 90))
+				sentPacket(repairPacket(3, 100))
+				cong.EXPECT().CanSend(protocol.ByteCount(190)).Return(true)
+				cong.EXPECT().HasPacingBudget(gomock.Any()).Return(true)
+				handler.SendMode(time.Now())
+				// acknowledge the repair packets
+				cong.EXPECT().MaybeExitSlowStart()
+				cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), protocol.ByteCount(190), gomock.Any()).Times(2)
+				_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.repairBytesInFlight).To(BeZero())
+				Expect(handler.bytesInFlight).To(BeEquivalentTo(100))
+			})
+
+			It("defers the congestion response until it's known whether the peer recovered the packet", func() {
+				handler.deferRecoverableLosses = true
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+				fh1 := &recoverableFrameHandler{}
+				fh2 := &recoverableFrameHandler{}
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour), Frames: []Frame{{Frame: &wire.PingFrame{}, Handler: fh1}}}))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, SendTime: time.Now().Add(-time.Hour), Frames: []Frame{{Frame: &wire.PingFrame{}, Handler: fh2}}}))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 3}))
+				cong.EXPECT().MaybeExitSlowStart()
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(3), gomock.Any(), gomock.Any(), gomock.Any())
+				_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 3, Largest: 3}}}, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fh1.onLossResolved).ToNot(BeNil())
+				Expect(fh2.onLossResolved).ToNot(BeNil())
+
+				// the congestion controller is notified with the bytes in flight at the time the loss is resolved
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 4, Length: 100}))
+				cong.EXPECT().OnRecoveredLoss(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(100))
+				fh1.onLossResolved(true)
+				cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(100))
+				fh2.onLossResolved(false)
+				Expect(handler.deferredLosses).ToNot(BeEmpty())
+				// resolving a loss twice has no effect
+				fh1.onLossResolved(false)
+				// resolved losses are removed when the next ACK is processed
+				cong.EXPECT().MaybeExitSlowStart()
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(4), gomock.Any(), gomock.Any(), gomock.Any())
+				_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 3, Largest: 4}}}, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.deferredLosses).To(BeEmpty())
+			})
+
+			It("applies the congestion response if it's not known in time whether the peer recovered the packet", func() {
+				handler.deferRecoverableLosses = true
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+				fh := &recoverableFrameHandler{}
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour), Frames: []Frame{{Frame: &wire.PingFrame{}, Handler: fh}}}))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 3}))
+				cong.EXPECT().MaybeExitSlowStart()
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), gomock.Any(), gomock.Any(), gomock.Any())
+				_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fh.onLossResolved).ToNot(BeNil())
+				Expect(handler.deferredLosses).To(HaveLen(1))
+
+				// The FEC state was discarded, so the loss is never resolved.
+				timeout := deferredLossTimeoutPTOs * handler.rttStats.PTO(true)
+				gomock.InOrder(
+					cong.EXPECT().MaybeExitSlowStart(),
+					cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(1)),
+					cong.EXPECT().OnPacketAcked(protocol.PacketNumber(3), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}, protocol.Encryption1RTT, time.Now().Add(timeout))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(handler.deferredLosses).To(BeEmpty())
+				// a late resolution is ignored
+				fh.onLossResolved(true)
+			})
+
+			It("doesn't defer the congestion response if the recovered loss backoff isn't set", func() {
+				cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+				fh := &recoverableFrameHandler{}
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour), Frames: []Frame{{Frame: &wire.PingFrame{}, Handler: fh}}}))
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+				gomock.InOrder(
+					cong.EXPECT().MaybeExitSlowStart(),
+					cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(2)),
+					cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), gomock.Any(), gomock.Any(), gomock.Any()),
+				)
+				_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, time.Now())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fh.onLossResolved).To(BeNil())
+			})
+		})
 	})
 
 	It("doesn't set an alarm if there are no outstanding packets", func() {
@@ -984,7 +1115,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			rttStats := utils.NewRTTStats()
-			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, false, 0, 0, nil, perspective, nil, utils.DefaultLogger)
 		})
 
 		It("do not limits the window", func() {
@@ -1120,6 +1251,69 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("loss estimation", func() {
+		It("estimates the loss pattern from acknowledged and lost packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i}))
+			}
+			// packets 1 and 2 are declared lost, packets 4 and 5 are still outstanding
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}, {Smallest: 3, Largest: 3}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2}))
+			estimate := handler.LossEstimate()
+			Expect(estimate.NumPackets).To(BeEquivalentTo(3))
+			Expect(estimate.NumLost).To(BeEquivalentTo(2))
+			Expect(estimate.BurstLengths[1]).To(BeEquivalentTo(1))
+			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 3, Largest: 6}}}
+			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			estimate = handler.LossEstimate()
+			Expect(estimate.NumPackets).To(BeEquivalentTo(6))
+			Expect(estimate.NumLost).To(BeEquivalentTo(2))
+		})
+
+		It("ignores Initial and Handshake packets", func() {
+			sentPacket(initialPacket(&packet{PacketNumber: 1}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+			_, err := handler.ReceivedAck(ack, protocol.EncryptionInitial, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.LossEstimate().NumPackets).To(BeZero())
+		})
+	})
+
+	Context("connection migration", func() {
+		It("retransmits outstanding packets and resets the congestion state", func() {
+			updateRTT(time.Second)
+			for i := protocol.PacketNumber(1); i <= 3; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 500}))
+			}
+			cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.congestion = cong
+			handler.ptoCount = 2
+			// the packets are not lost due to congestion, so cong doesn't EXPECT any calls
+			handler.MigratedPath(1200)
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			Expect(handler.appDataPackets.history.HasOutstandingPackets()).To(BeFalse())
+			Expect(handler.congestion).ToNot(Equal(cong))
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			Expect(handler.ptoCount).To(BeZero())
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+		})
+
+		It("resets the loss estimate", func() {
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.LossEstimate().NumPackets).To(BeEquivalentTo(1))
+			handler.MigratedPath(1200)
+			Expect(handler.LossEstimate().NumPackets).To(BeZero())
+		})
+	})
+
 	Context("Delay-based loss detection", func() {
 		It("immediately detects old packets as lost when receiving an ACK", func() {
 			now := time.Now()
@@ -1443,7 +1637,7 @@ var _ = Describe("SentPacketHandler", func() {
 			lostPackets = nil
 			rttStats := utils.NewRTTStats()
 			rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, 0, 0, nil, perspective, nil, utils.DefaultLogger)
 			handler.ecnTracker = ecnHandler
 			handler.congestion = cong
 		})
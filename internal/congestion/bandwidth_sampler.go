@@ -0,0 +1,189 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// The state of the connection at the time a packet was sent.
+type sentPacketState struct {
+	packetNumber protocol.PacketNumber
+	sentTime     time.Time
+	size         protocol.ByteCount
+	// the number of bytes delivered when the packet was sent
+	delivered protocol.ByteCount
+	// the time when delivered was last updated
+	deliveredTime time.Time
+	// the send time of the packet that was most recently acknowledged when the packet was sent
+	firstSentTime time.Time
+	// the number of bytes lost when the packet was sent
+	lost protocol.ByteCount
+	// the number of bytes in flight when the packet was sent, including the packet itself
+	txInFlight   protocol.ByteCount
+	isAppLimited bool
+	resolved     bool
+}
+
+// A rateSample is the delivery rate measured when a packet is acknowledged.
+type rateSample struct {
+	// The delivery rate. 0 if the sample is invalid.
+	deliveryRate Bandwidth
+	isAppLimited bool
+	// the number of bytes delivered between sending and acknowledging the packet
+	delivered protocol.ByteCount
+	// the number of bytes delivered when the packet was sent
+	priorDelivered protocol.ByteCount
+	interval       time.Duration
+	rtt            time.Duration
+	// the number of bytes in flight when the packet was sent
+	txInFlight protocol.ByteCount
+	// the number of bytes lost between sending and acknowledging the packet
+	lost protocol.ByteCount
+}
+
+// The bandwidthSampler estimates the delivery rate of the connection,
+// as described in draft-cheng-iccrg-delivery-rate-estimation.
+// It remembers the state of the connection when a packet is sent,
+// and calculates the rate at which data was delivered while the packet was in flight when it is acknowledged.
+type bandwidthSampler struct {
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	lost          protocol.ByteCount
+	// If non-zero, the connection is application limited until this number of bytes is delivered.
+	appLimitedUntil protocol.ByteCount
+
+	packets map[protocol.PacketNumber]*sentPacketState
+	// all packets in packets, in the order they were sent, starting at queueStart
+	queue      []*sentPacketState
+	queueStart int
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{packets: make(map[protocol.PacketNumber]*sentPacketState)}
+}
+
+// OnPacketSent is called when an ack-eliciting packet is sent.
+// bytesInFlight is the number of bytes in flight, including this packet.
+func (s *bandwidthSampler) OnPacketSent(sentTime time.Time, pn protocol.PacketNumber, size, bytesInFlight protocol.ByteCount) {
+	if bytesInFlight <= size {
+		// Start the interval at the first packet sent after an idle period.
+		s.firstSentTime = sentTime
+		s.deliveredTime = sentTime
+	}
+	p := &sentPacketState{
+		packetNumber:  pn,
+		sentTime:      sentTime,
+		size:          size,
+		delivered:     s.delivered,
+		deliveredTime: s.deliveredTime,
+		firstSentTime: s.firstSentTime,
+		lost:          s.lost,
+		txInFlight:    bytesInFlight,
+		isAppLimited:  s.appLimitedUntil != 0,
+	}
+	// Packet numbers are reused in the Initial, Handshake and application data packet number spaces.
+	// Overwriting the state of a packet from a different packet number space only loses a single sample.
+	if old, ok := s.packets[pn]; ok {
+		old.resolved = true
+	}
+	s.packets[pn] = p
+	s.queue = append(s.queue, p)
+}
+
+// OnAppLimited is called when the sender doesn't use the available congestion window.
+// Samples taken until the data that is currently in flight is acknowledged are marked as application limited.
+func (s *bandwidthSampler) OnAppLimited(bytesInFlight protocol.ByteCount) {
+	s.appLimitedUntil = max(s.delivered+bytesInFlight, 1)
+}
+
+// OnPacketAcked is called when a packet is acknowledged.
+// It returns false if no sample could be taken, e.g. because the packet was sent before the sampler was created.
+func (s *bandwidthSampler) OnPacketAcked(pn protocol.PacketNumber, size protocol.ByteCount, ackTime time.Time) (rateSample, bool) {
+	s.delivered += size
+	s.deliveredTime = ackTime
+	if s.appLimitedUntil != 0 && s.delivered > s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+	p := s.remove(pn)
+	if p == nil {
+		return rateSample{}, false
+	}
+	s.firstSentTime = p.sentTime
+	rs := rateSample{
+		isAppLimited:   p.isAppLimited,
+		delivered:      s.delivered - p.delivered,
+		priorDelivered: p.delivered,
+		rtt:            ackTime.Sub(p.sentTime),
+		txInFlight:     p.txInFlight,
+		lost:           s.lost - p.lost,
+	}
+	// Use the longer of the send and the ack interval.
+	// This prevents overestimating the rate if acknowledgements are compressed.
+	rs.interval = max(p.sentTime.Sub(p.firstSentTime), ackTime.Sub(p.deliveredTime))
+	if rs.interval > 0 {
+		rs.deliveryRate = BandwidthFromDelta(rs.delivered, rs.interval)
+	}
+	return rs, true
+}
+
+// OnPacketLost is called when a packet is declared lost.
+// It returns the sample that would have been taken if the packet was acknowledged now,
+// and false if the state of the packet is unknown.
+func (s *bandwidthSampler) OnPacketLost(pn protocol.PacketNumber, size protocol.ByteCount) (rateSample, bool) {
+	s.lost += size
+	p := s.remove(pn)
+	if p == nil {
+		return rateSample{}, false
+	}
+	return rateSample{
+		isAppLimited:   p.isAppLimited,
+		priorDelivered: p.delivered,
+		txInFlight:     p.txInFlight,
+		lost:           s.lost - p.lost,
+	}, true
+}
+
+func (s *bandwidthSampler) remove(pn protocol.PacketNumber) *sentPacketState {
+	p, ok := s.packets[pn]
+	if !ok {
+		return nil
+	}
+	delete(s.packets, pn)
+	p.resolved = true
+	return p
+}
+
+// RemoveOlderThan drops the state of packets sent before t.
+// Packets aren't necessarily acknowledged or declared lost:
+// the packets of a dropped packet number space are not, and neither are lost Path MTU probe packets.
+func (s *bandwidthSampler) RemoveOlderThan(t time.Time) {
+	for ; s.queueStart < len(s.queue); s.queueStart++ {
+		p := s.queue[s.queueStart]
+		if !p.resolved && !p.sentTime.Before(t) {
+			break
+		}
+		if !p.resolved {
+			delete(s.packets, p.packetNumber)
+		}
+		s.queue[s.queueStart] = nil
+	}
+	// Only move the remaining packets to the front of the queue once most of it is unused.
+	if s.queueStart > 64 && s.queueStart > len(s.queue)/2 {
+		n := copy(s.queue, s.queue[s.queueStart:])
+		clear(s.queue[n:])
+		s.queue = s.queue[:n]
+		s.queueStart = 0
+	}
+}
+
+// Delivered returns the number of bytes delivered so far.
+func (s *bandwidthSampler) Delivered() protocol.ByteCount {
+	return s.delivered
+}
+
+// Lost returns the number of bytes lost so far.
+func (s *bandwidthSampler) Lost() protocol.ByteCount {
+	return s.lost
+}
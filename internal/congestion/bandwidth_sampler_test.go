@@ -0,0 +1,145 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth Sampler", func() {
+	const packetSize = 1000
+
+	var (
+		s             *bandwidthSampler
+		now           time.Time
+		bytesInFlight protocol.ByteCount
+	)
+
+	BeforeEach(func() {
+		s = newBandwidthSampler()
+		now = time.Now()
+		bytesInFlight = 0
+	})
+
+	send := func(pn protocol.PacketNumber) {
+		bytesInFlight += packetSize
+		s.OnPacketSent(now, pn, packetSize, bytesInFlight)
+	}
+
+	ack := func(pn protocol.PacketNumber) rateSample {
+		bytesInFlight -= packetSize
+		rs, ok := s.OnPacketAcked(pn, packetSize, now)
+		Expect(ok).To(BeTrue())
+		return rs
+	}
+
+	It("measures the delivery rate", func() {
+		// send one packet every millisecond, i.e. 1 MB/s
+		for pn := protocol.PacketNumber(0); pn < 20; pn++ {
+			send(pn)
+			now = now.Add(time.Millisecond)
+		}
+		// the first packet is acknowledged after 50ms
+		now = now.Add(30 * time.Millisecond)
+		rs := ack(0)
+		Expect(rs.rtt).To(Equal(50 * time.Millisecond))
+		Expect(rs.delivered).To(BeEquivalentTo(packetSize))
+		Expect(rs.txInFlight).To(BeEquivalentTo(packetSize))
+		for pn := protocol.PacketNumber(1); pn < 20; pn++ {
+			now = now.Add(time.Millisecond)
+			rs = ack(pn)
+			Expect(rs.rtt).To(Equal(50 * time.Millisecond))
+			Expect(rs.priorDelivered).To(BeEquivalentTo(0))
+			Expect(rs.delivered).To(BeEquivalentTo((pn + 1) * packetSize))
+		}
+		// the interval starts when the first packet was sent
+		Expect(rs.interval).To(Equal(69 * time.Millisecond))
+		Expect(s.Delivered()).To(BeEquivalentTo(20 * packetSize))
+
+		// The next flight is sent after the first one was delivered.
+		for pn := protocol.PacketNumber(20); pn < 40; pn++ {
+			send(pn)
+			now = now.Add(time.Millisecond)
+		}
+		now = now.Add(30 * time.Millisecond)
+		for pn := protocol.PacketNumber(20); pn < 40; pn++ {
+			now = now.Add(time.Millisecond)
+			rs = ack(pn)
+		}
+		Expect(rs.priorDelivered).To(BeEquivalentTo(20 * packetSize))
+		Expect(rs.delivered).To(BeEquivalentTo(20 * packetSize))
+		Expect(rs.interval).To(Equal(70 * time.Millisecond))
+		Expect(rs.deliveryRate).To(Equal(BandwidthFromDelta(20*packetSize, 70*time.Millisecond)))
+		Expect(rs.isAppLimited).To(BeFalse())
+	})
+
+	It("uses the ack interval if acknowledgements are spread out", func() {
+		// send a burst of 10 packets
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			send(pn)
+		}
+		var rs rateSample
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			now = now.Add(10 * time.Millisecond)
+			rs = ack(pn)
+		}
+		Expect(rs.interval).To(Equal(100 * time.Millisecond))
+		Expect(rs.deliveryRate).To(Equal(BandwidthFromDelta(10*packetSize, 100*time.Millisecond)))
+	})
+
+	It("marks samples as application limited", func() {
+		send(0)
+		s.OnAppLimited(bytesInFlight)
+		send(1)
+		send(2)
+		now = now.Add(10 * time.Millisecond)
+		Expect(ack(0).isAppLimited).To(BeFalse())
+		Expect(ack(1).isAppLimited).To(BeTrue())
+		send(3)
+		Expect(ack(2).isAppLimited).To(BeTrue())
+		// the connection is no longer application limited once the data in flight was delivered
+		Expect(ack(3).isAppLimited).To(BeFalse())
+	})
+
+	It("counts lost bytes", func() {
+		send(0)
+		send(1)
+		send(2)
+		rs, ok := s.OnPacketLost(0, packetSize)
+		Expect(ok).To(BeTrue())
+		Expect(rs.lost).To(BeEquivalentTo(packetSize))
+		Expect(rs.txInFlight).To(BeEquivalentTo(packetSize))
+		now = now.Add(10 * time.Millisecond)
+		Expect(ack(2).lost).To(BeEquivalentTo(packetSize))
+		Expect(s.Lost()).To(BeEquivalentTo(packetSize))
+	})
+
+	It("doesn't take samples for unknown packets", func() {
+		_, ok := s.OnPacketAcked(42, packetSize, now)
+		Expect(ok).To(BeFalse())
+		Expect(s.Delivered()).To(BeEquivalentTo(packetSize))
+		_, ok = s.OnPacketLost(43, packetSize)
+		Expect(ok).To(BeFalse())
+		Expect(s.Lost()).To(BeEquivalentTo(packetSize))
+	})
+
+	It("removes old packets", func() {
+		for pn := protocol.PacketNumber(0); pn < 100; pn++ {
+			send(pn)
+			now = now.Add(time.Millisecond)
+		}
+		ack(50)
+		s.RemoveOlderThan(now.Add(-10 * time.Millisecond))
+		Expect(s.packets).To(HaveLen(10))
+		for pn := protocol.PacketNumber(0); pn < 90; pn++ {
+			Expect(s.packets).ToNot(HaveKey(pn))
+		}
+		Expect(s.queue[s.queueStart:]).To(HaveLen(10))
+		s.RemoveOlderThan(now)
+		Expect(s.packets).To(BeEmpty())
+		Expect(s.queue[s.queueStart:]).To(BeEmpty())
+	})
+})
@@ -0,0 +1,999 @@
+package congestion
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// The BBR implementation follows draft-ietf-ccwg-bbr (BBRv3).
+
+const (
+	// the pacing gain during Startup, 4*ln(2), which allows doubling the sending rate every round trip
+	bbrStartupPacingGain = 2.77
+	bbrStartupCwndGain   = 2.0
+	// the pacing gain during Drain, which drains the queue created during Startup in about one round trip
+	bbrDrainPacingGain = 0.35
+	bbrDefaultCwndGain = 2.0
+	// the cwnd gain while probing for more bandwidth in ProbeBW_UP
+	bbrProbeUpCwndGain   = 2.25
+	bbrProbeRTTCwndGain  = 0.5
+	bbrPacingMarginRatio = 0.01
+
+	// the maximum tolerated rate of lost bytes per round trip
+	bbrLossThreshold = 0.02
+	// the multiplicative decrease of the bandwidth and inflight bounds in response to loss
+	bbrBeta = 0.7
+	// the share of inflight_hi that is left unused to leave room for other flows
+	bbrHeadroom = 0.15
+
+	// Startup ends once the bandwidth didn't grow by at least 25% for 3 round trips.
+	bbrFullBandwidthThreshold = 1.25
+	bbrFullBandwidthCount     = 3
+	// Startup ends if a round trip has too many loss events.
+	bbrStartupFullLossCount = 6
+
+	bbrMinPipeCwndPackets = 4
+	// the window of the filter that measures the degree of ACK aggregation, in round trips
+	bbrExtraAckedFilterLength = 10
+	bbrMinRTTFilterLength     = 10 * time.Second
+	bbrProbeRTTInterval       = 5 * time.Second
+	bbrProbeRTTDuration       = 200 * time.Millisecond
+	// the maximum number of round trips between two attempts to probe for more bandwidth
+	bbrMaxRenoCoexistenceRounds = 63
+)
+
+type bbrMode uint8
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBW
+	bbrModeProbeRTT
+)
+
+// The phases of the ProbeBW mode.
+type bbrProbeBWPhase uint8
+
+const (
+	// decelerate to drain the queue created while probing for more bandwidth
+	bbrProbeBWDown bbrProbeBWPhase = iota
+	// cruise at the estimated bandwidth, with a bit of headroom for other flows
+	bbrProbeBWCruise
+	// refill the pipe before probing for more bandwidth
+	bbrProbeBWRefill
+	// probe for more bandwidth
+	bbrProbeBWUp
+)
+
+type bbrAckPhase uint8
+
+const (
+	bbrAcksInit bbrAckPhase = iota
+	bbrAcksRefilling
+	bbrAcksProbeStarting
+	bbrAcksProbeFeedback
+	bbrAcksProbeStopping
+)
+
+type bbrSender struct {
+	rttStats RTTStats
+	pacer    *pacer
+	clock    Clock
+	sampler  *bandwidthSampler
+	rand     utils.Rand
+
+	maxDatagramSize         protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	congestionWindow        protocol.ByteCount
+	// the congestion window saved before entering recovery or ProbeRTT
+	priorCongestionWindow protocol.ByteCount
+	pacingRate            Bandwidth
+	// Is the pacing rate still based on a nominal RTT, since no RTT was measured when the sender was created?
+	nominalPacingRate bool
+	pacingGain        float64
+	cwndGain          float64
+	// the bytes in flight, as reported when the last packet was sent, reduced by the bytes acknowledged and lost since then
+	bytesInFlight protocol.ByteCount
+	// the bytes in flight before the ACK that is currently being processed
+	priorInFlight protocol.ByteCount
+	// Was the sender blocked by the congestion window since the last packet was sent?
+	cwndLimited bool
+
+	// The factor by which the inflight bounds are reduced when the peer recovered a lost packet using FEC.
+	// If 0, recovered losses are treated like any other loss.
+	recoveredLossBackoff float64
+
+	mode         bbrMode
+	probeBWPhase bbrProbeBWPhase
+	ackPhase     bbrAckPhase
+
+	// round trip counting
+	nextRoundDelivered protocol.ByteCount
+	roundCount         uint64
+	roundStart         bool
+
+	// The maximum bandwidth of the current and the previous ProbeBW cycle.
+	maxBwFilter [2]Bandwidth
+	cycleCount  uint64
+	maxBw       Bandwidth
+	// the short-term lower bound of the bandwidth, infBandwidth if unset
+	bwLo Bandwidth
+	// the bandwidth used by the model, the minimum of maxBw and bwLo
+	bw Bandwidth
+	// the maximum delivery rate and the maximum volume of delivered data in the current loss round
+	bwLatest       Bandwidth
+	inflightLatest protocol.ByteCount
+	// the long-term upper bound of the bytes in flight, protocol.MaxByteCount if unset
+	inflightHi protocol.ByteCount
+	// the short-term lower bound of the bytes in flight, protocol.MaxByteCount if unset
+	inflightLo protocol.ByteCount
+
+	extraAckedFilter        *windowedMaxFilter
+	extraAckedIntervalStart time.Time
+	extraAckedDelivered     protocol.ByteCount
+
+	minRTT            time.Duration
+	minRTTStamp       time.Time
+	probeRTTMinDelay  time.Duration
+	probeRTTMinStamp  time.Time
+	probeRTTExpired   bool
+	probeRTTDoneStamp time.Time
+	probeRTTRoundDone bool
+	idleRestart       bool
+
+	// Startup
+	filledPipe  bool
+	fullBw      Bandwidth
+	fullBwCount int
+
+	// loss rounds
+	lossInRound       bool
+	lossEventsInRound int
+	lossRoundStart    bool
+	// the bytes delivered when the current loss round ends
+	lossRoundDelivered protocol.ByteCount
+	// the bytes delivered and lost when the current loss round started
+	lossRoundStartDelivered protocol.ByteCount
+	lossRoundStartLost      protocol.ByteCount
+
+	// ProbeBW
+	cycleStamp         time.Time
+	roundsSinceBwProbe uint64
+	bwProbeWait        time.Duration
+	bwProbeUpRounds    uint
+	bwProbeUpAcks      protocol.ByteCount
+	probeUpCount       protocol.ByteCount
+	bwProbeSamples     bool
+
+	// Recovery
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+	// the largest packet number sent when recovery was entered
+	recoveryStartPacketNumber protocol.PacketNumber
+	inRecovery                bool
+	packetConservation        bool
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	recoveredLossBackoff float64,
+	tracer *logging.ConnectionTracer,
+) *bbrSender {
+	return newBBRSender(clock, rttStats, initialMaxDatagramSize, initialCongestionWindow*initialMaxDatagramSize, recoveredLossBackoff, tracer)
+}
+
+func newBBRSender(
+	clock Clock,
+	rttStats RTTStats,
+	initialMaxDatagramSize,
+	initialCongestionWindow protocol.ByteCount,
+	recoveredLossBackoff float64,
+	tracer *logging.ConnectionTracer,
+) *bbrSender {
+	b := &bbrSender{
+		rttStats:                  rttStats,
+		clock:                     clock,
+		sampler:                   newBandwidthSampler(),
+		maxDatagramSize:           initialMaxDatagramSize,
+		initialCongestionWindow:   initialCongestionWindow,
+		congestionWindow:          initialCongestionWindow,
+		recoveredLossBackoff:      recoveredLossBackoff,
+		bwLo:                      infBandwidth,
+		inflightHi:                protocol.MaxByteCount,
+		inflightLo:                protocol.MaxByteCount,
+		extraAckedFilter:          newWindowedMaxFilter(bbrExtraAckedFilterLength),
+		minRTT:                    math.MaxInt64,
+		probeRTTMinDelay:          math.MaxInt64,
+		probeUpCount:              protocol.MaxByteCount,
+		largestSentPacketNumber:   protocol.InvalidPacketNumber,
+		largestAckedPacketNumber:  protocol.InvalidPacketNumber,
+		recoveryStartPacketNumber: protocol.InvalidPacketNumber,
+		tracer:                    tracer,
+	}
+	now := clock.Now()
+	b.minRTTStamp = now
+	b.probeRTTMinStamp = now
+	b.cycleStamp = now
+	b.extraAckedIntervalStart = now
+	b.initPacingRate()
+	// The pacer paces at 5/4 of the bandwidth it is given. BBR controls its sending rate precisely using the pacing gain.
+	b.pacer = newPacer(func() Bandwidth { return max(b.pacingRate/5*4, BytesPerSecond) })
+	b.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	b.enterStartup()
+	return b
+}
+
+func (b *bbrSender) initPacingRate() {
+	// Before the first RTT sample, assume a nominal RTT of 1ms.
+	rtt := b.rttStats.SmoothedRTT()
+	b.nominalPacingRate = rtt == 0
+	if rtt == 0 {
+		rtt = time.Millisecond
+	}
+	b.pacingRate = Bandwidth(bbrStartupPacingGain * float64(BandwidthFromDelta(b.initialCongestionWindow, rtt)))
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (b *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbrSender) HasPacingBudget(now time.Time) bool {
+	return b.pacer.Budget(now) >= b.maxDatagramSize
+}
+
+func (b *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	// If the pacer accumulated the budget for a full burst while the congestion window wasn't used up,
+	// the sender didn't send as much as it could have.
+	appLimited := !b.cwndLimited && b.pacer.Budget(sentTime) >= b.pacer.maxBurstSize()
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	b.largestSentPacketNumber = packetNumber
+	b.bytesInFlight = bytesInFlight
+	b.cwndLimited = bytesInFlight+b.maxDatagramSize > b.GetCongestionWindow()
+	if bytesInFlight <= bytes && appLimited {
+		// Restarting after an idle period.
+		b.idleRestart = true
+		b.extraAckedIntervalStart = sentTime
+		b.extraAckedDelivered = 0
+	}
+	if appLimited {
+		b.sampler.OnAppLimited(bytesInFlight - bytes)
+	}
+	b.sampler.OnPacketSent(sentTime, packetNumber, bytes, bytesInFlight)
+}
+
+func (b *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	if bytesInFlight >= b.GetCongestionWindow() {
+		b.cwndLimited = true
+		return false
+	}
+	return true
+}
+
+func (b *bbrSender) InRecovery() bool {
+	return b.inRecovery
+}
+
+// InSlowStart returns true in the Startup mode, BBR's equivalent of slow start.
+func (b *bbrSender) InSlowStart() bool {
+	return b.mode == bbrModeStartup
+}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return b.congestionWindow
+}
+
+// MaybeExitSlowStart is a no-op. BBR leaves Startup once the bandwidth stops growing.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+func (b *bbrSender) OnPacketAcked(
+	number protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.largestAckedPacketNumber = max(number, b.largestAckedPacketNumber)
+	b.priorInFlight = priorInFlight
+	b.bytesInFlight = max(0, b.bytesInFlight-ackedBytes)
+	rs, ok := b.sampler.OnPacketAcked(number, ackedBytes, eventTime)
+	// Packets sent this long before an acknowledged packet were either acknowledged or declared lost by now.
+	b.sampler.RemoveOlderThan(eventTime.Add(-2 * max(b.rttStats.PTO(true), time.Second)))
+	if !ok {
+		// The packet wasn't sent by this sender (e.g. it was sent before a migration), but it was delivered.
+		rs = rateSample{delivered: ackedBytes, priorDelivered: b.sampler.Delivered() - ackedBytes, isAppLimited: true}
+	}
+	if rs.interval < b.rttStats.MinRTT() {
+		// The interval is too short to measure the delivery rate accurately.
+		rs.deliveryRate = 0
+	}
+
+	if b.nominalPacingRate && b.rttStats.SmoothedRTT() > 0 {
+		b.initPacingRate()
+	}
+	b.updateModelAndState(&rs, ackedBytes, eventTime)
+	b.updateControlParameters(ackedBytes)
+	b.maybeExitRecovery()
+}
+
+func (b *bbrSender) maybeExitRecovery() {
+	if b.inRecovery && b.largestAckedPacketNumber > b.recoveryStartPacketNumber {
+		b.inRecovery = false
+		b.packetConservation = false
+		b.restoreCwnd()
+	}
+}
+
+func (b *bbrSender) OnCongestionEvent(number protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	if lostBytes == 0 {
+		// An ECN-CE mark. BBRv3 only reacts to ECN marks in data centers, where the marking threshold is known.
+		return
+	}
+	b.onPacketLost(number, lostBytes, 1)
+}
+
+// OnRecoveredLoss is called when the peer recovered the content of a lost packet using FEC.
+// Such a loss is most likely caused by random loss on the path rather than by congestion.
+// If recoveredLossBackoff is 1, the loss doesn't affect the bandwidth and inflight estimates.
+// Otherwise, the loss is only counted as partially lost, weighted by 1 - recoveredLossBackoff.
+func (b *bbrSender) OnRecoveredLoss(number protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	if b.recoveredLossBackoff == 0 {
+		b.OnCongestionEvent(number, lostBytes, priorInFlight)
+		return
+	}
+	b.onPacketLost(number, lostBytes, 1-b.recoveredLossBackoff)
+}
+
+// onPacketLost handles a lost packet. weight is the share of the lost bytes that is counted as a loss.
+func (b *bbrSender) onPacketLost(number protocol.PacketNumber, lostBytes protocol.ByteCount, weight float64) {
+	b.bytesInFlight = max(0, b.bytesInFlight-lostBytes)
+	counted := protocol.ByteCount(weight * float64(lostBytes))
+	rs, ok := b.sampler.OnPacketLost(number, counted)
+	if counted == 0 {
+		return
+	}
+	if !b.inRecovery {
+		b.enterRecovery()
+	}
+	// Reduce the congestion window by the number of lost bytes.
+	b.congestionWindow = max(b.congestionWindow-lostBytes, b.maxDatagramSize)
+	if b.packetConservation {
+		b.congestionWindow = max(b.congestionWindow, b.bytesInFlight+b.maxDatagramSize)
+	}
+
+	b.lossInRound = true
+	b.lossEventsInRound++
+	if !ok || !b.bwProbeSamples {
+		return
+	}
+	if b.isInflightTooHigh(&rs) {
+		rs.txInFlight = b.inflightHiFromLostPacket(&rs, counted)
+		b.handleInflightTooHigh(&rs)
+	}
+}
+
+func (b *bbrSender) enterRecovery() {
+	b.saveCwnd()
+	b.inRecovery = true
+	b.packetConservation = true
+	b.recoveryStartPacketNumber = b.largestSentPacketNumber
+	// Start a new round trip. Packet conservation ends when the round trip is over.
+	b.startRound()
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (b *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	b.saveCwnd()
+	b.congestionWindow = b.bytesInFlight + b.maxDatagramSize
+}
+
+func (b *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := b.congestionWindow == b.minPipeCwnd()
+	b.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		b.congestionWindow = b.minPipeCwnd()
+	}
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (b *bbrSender) BandwidthEstimate() Bandwidth {
+	return b.bw
+}
+
+func (b *bbrSender) minPipeCwnd() protocol.ByteCount {
+	return bbrMinPipeCwndPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) updateModelAndState(rs *rateSample, ackedBytes protocol.ByteCount, now time.Time) {
+	b.updateLatestDeliverySignals(rs)
+	b.updateCongestionSignals(rs)
+	b.updateAckAggregation(ackedBytes, now)
+	b.checkStartupDone(rs)
+	b.checkDrainDone(now)
+	b.updateProbeBWCyclePhase(rs, ackedBytes, now)
+	b.updateMinRTT(rs, now)
+	b.checkProbeRTT(rs, now)
+	b.advanceLatestDeliverySignals(rs)
+	b.boundBwForModel()
+}
+
+func (b *bbrSender) updateControlParameters(ackedBytes protocol.ByteCount) {
+	b.setPacingRateWithGain(b.pacingGain)
+	b.setCwnd(ackedBytes)
+}
+
+func (b *bbrSender) updateRound(rs *rateSample) {
+	if rs.priorDelivered >= b.nextRoundDelivered {
+		b.startRound()
+		b.roundCount++
+		b.roundsSinceBwProbe++
+		b.roundStart = true
+		// Packet conservation only applies to the first round trip of the recovery period.
+		b.packetConservation = false
+	} else {
+		b.roundStart = false
+	}
+}
+
+func (b *bbrSender) startRound() {
+	b.nextRoundDelivered = b.sampler.Delivered()
+}
+
+func (b *bbrSender) updateLatestDeliverySignals(rs *rateSample) {
+	b.lossRoundStart = false
+	b.bwLatest = max(b.bwLatest, rs.deliveryRate)
+	b.inflightLatest = max(b.inflightLatest, rs.delivered)
+	if rs.priorDelivered >= b.lossRoundDelivered {
+		b.lossRoundDelivered = b.sampler.Delivered()
+		b.lossRoundStart = true
+	}
+}
+
+func (b *bbrSender) advanceLatestDeliverySignals(rs *rateSample) {
+	if b.lossRoundStart {
+		b.bwLatest = rs.deliveryRate
+		b.inflightLatest = rs.delivered
+		b.lossEventsInRound = 0
+		b.lossRoundStartLost = b.sampler.Lost()
+		b.lossRoundStartDelivered = b.sampler.Delivered()
+	}
+}
+
+func (b *bbrSender) updateCongestionSignals(rs *rateSample) {
+	b.updateMaxBw(rs)
+	if !b.lossRoundStart {
+		return
+	}
+	b.adaptLowerBoundsFromCongestion()
+	b.lossInRound = false
+}
+
+func (b *bbrSender) updateMaxBw(rs *rateSample) {
+	b.updateRound(rs)
+	if rs.deliveryRate > 0 && (rs.deliveryRate >= b.maxBw || !rs.isAppLimited) {
+		idx := b.cycleCount % 2
+		b.maxBwFilter[idx] = max(b.maxBwFilter[idx], rs.deliveryRate)
+		b.maxBw = max(b.maxBwFilter[0], b.maxBwFilter[1])
+	}
+}
+
+// advanceMaxBwFilter starts a new ProbeBW cycle.
+// The maximum bandwidth is the maximum of the current and the previous cycle.
+func (b *bbrSender) advanceMaxBwFilter() {
+	b.cycleCount++
+	b.maxBwFilter[b.cycleCount%2] = 0
+	b.maxBw = max(b.maxBwFilter[0], b.maxBwFilter[1])
+}
+
+func (b *bbrSender) adaptLowerBoundsFromCongestion() {
+	if b.isProbingBw() || !b.lossInRound {
+		return
+	}
+	// init the lower bounds
+	if b.bwLo == infBandwidth {
+		b.bwLo = b.maxBw
+	}
+	if b.inflightLo == protocol.MaxByteCount {
+		b.inflightLo = b.congestionWindow
+	}
+	b.bwLo = max(b.bwLatest, Bandwidth(bbrBeta*float64(b.bwLo)))
+	b.inflightLo = max(b.inflightLatest, protocol.ByteCount(bbrBeta*float64(b.inflightLo)))
+}
+
+func (b *bbrSender) resetLowerBounds() {
+	b.bwLo = infBandwidth
+	b.inflightLo = protocol.MaxByteCount
+}
+
+func (b *bbrSender) resetCongestionSignals() {
+	b.lossInRound = false
+	b.bwLatest = 0
+	b.inflightLatest = 0
+}
+
+func (b *bbrSender) boundBwForModel() {
+	b.bw = min(b.maxBw, b.bwLo)
+}
+
+func (b *bbrSender) isProbingBw() bool {
+	return b.mode == bbrModeStartup ||
+		(b.mode == bbrModeProbeBW && (b.probeBWPhase == bbrProbeBWRefill || b.probeBWPhase == bbrProbeBWUp))
+}
+
+func (b *bbrSender) updateAckAggregation(ackedBytes protocol.ByteCount, now time.Time) {
+	interval := now.Sub(b.extraAckedIntervalStart)
+	expectedDelivered := b.bytesForInterval(b.bw, interval)
+	// Start a new aggregation epoch if the ACK rate is below the expected rate.
+	if b.extraAckedDelivered <= expectedDelivered {
+		b.extraAckedDelivered = 0
+		b.extraAckedIntervalStart = now
+		expectedDelivered = 0
+	}
+	b.extraAckedDelivered += ackedBytes
+	extra := min(b.extraAckedDelivered-expectedDelivered, b.congestionWindow)
+	b.extraAckedFilter.Update(extra, b.roundCount)
+}
+
+func (b *bbrSender) bytesForInterval(bw Bandwidth, interval time.Duration) protocol.ByteCount {
+	if interval <= 0 || bw == infBandwidth {
+		return 0
+	}
+	return protocol.ByteCount(float64(bw/BytesPerSecond) * interval.Seconds())
+}
+
+func (b *bbrSender) enterStartup() {
+	b.mode = bbrModeStartup
+	b.pacingGain = bbrStartupPacingGain
+	b.cwndGain = bbrStartupCwndGain
+	b.maybeTraceStateChange()
+}
+
+func (b *bbrSender) checkStartupDone(rs *rateSample) {
+	b.checkStartupFullBandwidth(rs)
+	b.checkStartupHighLoss()
+	if b.mode == bbrModeStartup && b.filledPipe {
+		b.enterDrain()
+	}
+}
+
+func (b *bbrSender) checkStartupFullBandwidth(rs *rateSample) {
+	if b.filledPipe || !b.roundStart || rs.isAppLimited {
+		return
+	}
+	if float64(b.maxBw) >= float64(b.fullBw)*bbrFullBandwidthThreshold {
+		b.fullBw = b.maxBw
+		b.fullBwCount = 0
+		return
+	}
+	b.fullBwCount++
+	b.filledPipe = b.fullBwCount >= bbrFullBandwidthCount
+}
+
+// checkStartupHighLoss leaves Startup if a round trip had a high loss rate.
+// It is called at the end of the loss round, before the round's loss counters are reset.
+func (b *bbrSender) checkStartupHighLoss() {
+	if b.filledPipe || b.mode != bbrModeStartup || !b.lossRoundStart || b.lossEventsInRound < bbrStartupFullLossCount {
+		return
+	}
+	lost := b.sampler.Lost() - b.lossRoundStartLost
+	delivered := b.sampler.Delivered() - b.lossRoundStartDelivered
+	if float64(lost) <= bbrLossThreshold*float64(lost+delivered) {
+		return
+	}
+	b.filledPipe = true
+	b.inflightHi = max(b.bdpWithGain(b.maxBw, 1), b.inflightLatest)
+}
+
+func (b *bbrSender) enterDrain() {
+	b.mode = bbrModeDrain
+	b.pacingGain = bbrDrainPacingGain
+	b.cwndGain = bbrStartupCwndGain
+	b.maybeTraceStateChange()
+}
+
+func (b *bbrSender) checkDrainDone(now time.Time) {
+	if b.mode == bbrModeDrain && b.bytesInFlight <= b.inflight(b.maxBw, 1) {
+		b.enterProbeBW(now)
+	}
+}
+
+func (b *bbrSender) enterProbeBW(now time.Time) {
+	b.mode = bbrModeProbeBW
+	b.cwndGain = bbrDefaultCwndGain
+	b.startProbeBWDown(now)
+	b.maybeTraceStateChange()
+}
+
+func (b *bbrSender) startProbeBWDown(now time.Time) {
+	b.resetCongestionSignals()
+	b.probeUpCount = protocol.MaxByteCount
+	// Wait between 2 and 3 seconds before probing for more bandwidth again.
+	b.roundsSinceBwProbe = uint64(b.rand.Int31n(2))
+	b.bwProbeWait = 2*time.Second + time.Duration(b.rand.Int31n(1000))*time.Millisecond
+	b.cycleStamp = now
+	b.ackPhase = bbrAcksProbeStopping
+	b.startRound()
+	b.probeBWPhase = bbrProbeBWDown
+	b.pacingGain = 0.9
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWCruise() {
+	b.probeBWPhase = bbrProbeBWCruise
+	b.pacingGain = 1
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWRefill() {
+	b.resetLowerBounds()
+	b.bwProbeUpRounds = 0
+	b.bwProbeUpAcks = 0
+	b.ackPhase = bbrAcksRefilling
+	b.startRound()
+	b.probeBWPhase = bbrProbeBWRefill
+	b.pacingGain = 1
+	b.cwndGain = bbrDefaultCwndGain
+}
+
+func (b *bbrSender) startProbeBWUp(now time.Time) {
+	b.ackPhase = bbrAcksProbeStarting
+	b.startRound()
+	b.cycleStamp = now
+	b.probeBWPhase = bbrProbeBWUp
+	b.pacingGain = 1.25
+	b.cwndGain = bbrProbeUpCwndGain
+	b.raiseInflightHiSlope()
+}
+
+func (b *bbrSender) updateProbeBWCyclePhase(rs *rateSample, ackedBytes protocol.ByteCount, now time.Time) {
+	if !b.filledPipe {
+		return
+	}
+	b.adaptUpperBounds(rs, ackedBytes, now)
+	if b.mode != bbrModeProbeBW {
+		return
+	}
+	switch b.probeBWPhase {
+	case bbrProbeBWDown:
+		if b.checkTimeToProbeBW(now) {
+			return
+		}
+		if b.checkTimeToCruise() {
+			b.startProbeBWCruise()
+		}
+	case bbrProbeBWCruise:
+		b.checkTimeToProbeBW(now)
+	case bbrProbeBWRefill:
+		// After one round trip of refilling the pipe, start probing.
+		if b.roundStart {
+			b.bwProbeSamples = true
+			b.startProbeBWUp(now)
+		}
+	case bbrProbeBWUp:
+		if now.Sub(b.cycleStamp) > b.minRTT && b.bytesInFlight > b.inflight(b.maxBw, 1.25) {
+			b.startProbeBWDown(now)
+		}
+	}
+}
+
+func (b *bbrSender) adaptUpperBounds(rs *rateSample, ackedBytes protocol.ByteCount, now time.Time) {
+	if b.ackPhase == bbrAcksProbeStarting && b.roundStart {
+		// starting to get feedback about the probe
+		b.ackPhase = bbrAcksProbeFeedback
+	}
+	if b.ackPhase == bbrAcksProbeStopping && b.roundStart {
+		// end of the samples from the bandwidth probe
+		if b.mode == bbrModeProbeBW && !rs.isAppLimited {
+			b.advanceMaxBwFilter()
+		}
+	}
+	if b.isInflightTooHigh(rs) {
+		if b.bwProbeSamples {
+			b.handleInflightTooHigh(rs)
+		}
+		return
+	}
+	if b.inflightHi == protocol.MaxByteCount {
+		return
+	}
+	if rs.txInFlight > b.inflightHi {
+		b.inflightHi = rs.txInFlight
+	}
+	if b.mode == bbrModeProbeBW && b.probeBWPhase == bbrProbeBWUp {
+		b.probeInflightHiUpward(ackedBytes)
+	}
+}
+
+func (b *bbrSender) isInflightTooHigh(rs *rateSample) bool {
+	return float64(rs.lost) > float64(rs.txInFlight)*bbrLossThreshold
+}
+
+// inflightHiFromLostPacket estimates the bytes in flight at the point where the loss rate crossed the loss threshold.
+func (b *bbrSender) inflightHiFromLostPacket(rs *rateSample, size protocol.ByteCount) protocol.ByteCount {
+	inflightPrev := float64(rs.txInFlight) - float64(size)
+	lostPrev := float64(rs.lost) - float64(size)
+	lostPrefix := (bbrLossThreshold*inflightPrev - lostPrev) / (1 - bbrLossThreshold)
+	return protocol.ByteCount(max(0, inflightPrev+lostPrefix))
+}
+
+func (b *bbrSender) handleInflightTooHigh(rs *rateSample) {
+	b.bwProbeSamples = false
+	if !rs.isAppLimited {
+		b.inflightHi = max(rs.txInFlight, protocol.ByteCount(float64(b.targetInflight())*bbrBeta))
+	}
+	if b.mode == bbrModeProbeBW && b.probeBWPhase == bbrProbeBWUp {
+		b.startProbeBWDown(b.clock.Now())
+	}
+}
+
+func (b *bbrSender) probeInflightHiUpward(ackedBytes protocol.ByteCount) {
+	if !b.isCwndLimited() || b.congestionWindow < b.inflightHi {
+		return
+	}
+	b.bwProbeUpAcks += ackedBytes
+	if b.bwProbeUpAcks >= b.probeUpCount {
+		delta := b.bwProbeUpAcks / b.probeUpCount
+		b.bwProbeUpAcks -= delta * b.probeUpCount
+		b.inflightHi += delta * b.maxDatagramSize
+	}
+	if b.roundStart {
+		b.raiseInflightHiSlope()
+	}
+}
+
+// raiseInflightHiSlope grows inflight_hi exponentially: by 1, 2, 4, ... packets per round trip.
+func (b *bbrSender) raiseInflightHiSlope() {
+	growthThisRound := b.maxDatagramSize << b.bwProbeUpRounds
+	b.bwProbeUpRounds = min(b.bwProbeUpRounds+1, 30)
+	b.probeUpCount = max(b.congestionWindow/growthThisRound, 1) * b.maxDatagramSize
+}
+
+func (b *bbrSender) isCwndLimited() bool {
+	return b.priorInFlight+maxBurstPackets*b.maxDatagramSize >= b.congestionWindow
+}
+
+func (b *bbrSender) checkTimeToProbeBW(now time.Time) bool {
+	if now.Sub(b.cycleStamp) > b.bwProbeWait || b.isRenoCoexistenceProbeTime() {
+		b.startProbeBWRefill()
+		return true
+	}
+	return false
+}
+
+// isRenoCoexistenceProbeTime returns true if a Reno flow sharing the bottleneck would have grown
+// its congestion window by the current bandwidth-delay product since the last probe.
+func (b *bbrSender) isRenoCoexistenceProbeTime() bool {
+	renoRounds := uint64(b.targetInflight() / b.maxDatagramSize)
+	return b.roundsSinceBwProbe >= min(renoRounds, bbrMaxRenoCoexistenceRounds)
+}
+
+func (b *bbrSender) checkTimeToCruise() bool {
+	if b.bytesInFlight > b.inflightWithHeadroom() {
+		return false // not enough headroom
+	}
+	return b.bytesInFlight <= b.inflight(b.maxBw, 1)
+}
+
+func (b *bbrSender) inflightWithHeadroom() protocol.ByteCount {
+	if b.inflightHi == protocol.MaxByteCount {
+		return protocol.MaxByteCount
+	}
+	headroom := max(b.maxDatagramSize, protocol.ByteCount(bbrHeadroom*float64(b.inflightHi)))
+	if headroom >= b.inflightHi {
+		return b.minPipeCwnd()
+	}
+	return max(b.inflightHi-headroom, b.minPipeCwnd())
+}
+
+func (b *bbrSender) updateMinRTT(rs *rateSample, now time.Time) {
+	b.probeRTTExpired = now.Sub(b.probeRTTMinStamp) > bbrProbeRTTInterval
+	if rs.rtt > 0 && (rs.rtt < b.probeRTTMinDelay || b.probeRTTExpired) {
+		b.probeRTTMinDelay = rs.rtt
+		b.probeRTTMinStamp = now
+	}
+	minRTTExpired := now.Sub(b.minRTTStamp) > bbrMinRTTFilterLength
+	if b.probeRTTMinDelay < b.minRTT || minRTTExpired {
+		b.minRTT = b.probeRTTMinDelay
+		b.minRTTStamp = b.probeRTTMinStamp
+	}
+}
+
+func (b *bbrSender) checkProbeRTT(rs *rateSample, now time.Time) {
+	if b.mode != bbrModeProbeRTT && b.probeRTTExpired && !b.idleRestart {
+		b.enterProbeRTT()
+		b.saveCwnd()
+		b.probeRTTDoneStamp = time.Time{}
+		b.ackPhase = bbrAcksProbeStopping
+		b.startRound()
+	}
+	if b.mode == bbrModeProbeRTT {
+		b.handleProbeRTT(now)
+	}
+	if rs.delivered > 0 {
+		b.idleRestart = false
+	}
+}
+
+func (b *bbrSender) enterProbeRTT() {
+	b.mode = bbrModeProbeRTT
+	b.pacingGain = 1
+	b.cwndGain = bbrProbeRTTCwndGain
+	b.maybeTraceStateChange()
+}
+
+func (b *bbrSender) handleProbeRTT(now time.Time) {
+	// Ignore low rate samples during ProbeRTT.
+	b.sampler.OnAppLimited(b.bytesInFlight)
+	if b.probeRTTDoneStamp.IsZero() && b.bytesInFlight <= b.probeRTTCwnd() {
+		// Wait for at least ProbeRTTDuration and at least one round trip.
+		b.probeRTTDoneStamp = now.Add(bbrProbeRTTDuration)
+		b.probeRTTRoundDone = false
+		b.startRound()
+	} else if !b.probeRTTDoneStamp.IsZero() {
+		if b.roundStart {
+			b.probeRTTRoundDone = true
+		}
+		if b.probeRTTRoundDone && now.After(b.probeRTTDoneStamp) {
+			b.probeRTTMinStamp = now
+			b.restoreCwnd()
+			b.exitProbeRTT(now)
+		}
+	}
+}
+
+func (b *bbrSender) exitProbeRTT(now time.Time) {
+	b.resetLowerBounds()
+	if b.filledPipe {
+		b.mode = bbrModeProbeBW
+		b.startProbeBWDown(now)
+		b.startProbeBWCruise()
+		b.maybeTraceStateChange()
+	} else {
+		b.enterStartup()
+	}
+}
+
+func (b *bbrSender) probeRTTCwnd() protocol.ByteCount {
+	return max(b.bdpWithGain(b.bw, bbrProbeRTTCwndGain), b.minPipeCwnd())
+}
+
+func (b *bbrSender) setPacingRateWithGain(gain float64) {
+	if b.bw == 0 {
+		return
+	}
+	rate := Bandwidth(gain * float64(b.bw) * (1 - bbrPacingMarginRatio))
+	if b.filledPipe || rate > b.pacingRate {
+		b.pacingRate = rate
+	}
+}
+
+// bdpWithGain calculates the bandwidth-delay product, multiplied by gain.
+func (b *bbrSender) bdpWithGain(bw Bandwidth, gain float64) protocol.ByteCount {
+	if b.minRTT == math.MaxInt64 {
+		return b.initialCongestionWindow
+	}
+	return protocol.ByteCount(gain * float64(b.bytesForInterval(bw, b.minRTT)))
+}
+
+// quantizationBudget adds some allowance to the bytes in flight,
+// so that the sender can still send full bursts of packets if the bandwidth-delay product is small.
+func (b *bbrSender) quantizationBudget(inflight protocol.ByteCount) protocol.ByteCount {
+	inflight = max(inflight, 3*b.sendQuantum(), b.minPipeCwnd())
+	if b.mode == bbrModeProbeBW && b.probeBWPhase == bbrProbeBWUp {
+		inflight += 2 * b.maxDatagramSize
+	}
+	return inflight
+}
+
+// sendQuantum is the amount of data sent in a single burst: 1ms of data at the pacing rate,
+// but at least two and at most 64 KB.
+func (b *bbrSender) sendQuantum() protocol.ByteCount {
+	return min(max(b.bytesForInterval(b.pacingRate, time.Millisecond), 2*b.maxDatagramSize), 64*1024)
+}
+
+// inflight calculates the bytes in flight needed to achieve a bandwidth, multiplied by gain.
+func (b *bbrSender) inflight(bw Bandwidth, gain float64) protocol.ByteCount {
+	return b.quantizationBudget(b.bdpWithGain(bw, gain))
+}
+
+func (b *bbrSender) maxInflight() protocol.ByteCount {
+	return b.quantizationBudget(b.bdpWithGain(b.bw, b.cwndGain) + b.extraAckedFilter.Get())
+}
+
+// targetInflight is the number of bytes in flight that fully utilizes the path.
+func (b *bbrSender) targetInflight() protocol.ByteCount {
+	return min(b.bdpWithGain(b.bw, 1), b.congestionWindow)
+}
+
+func (b *bbrSender) setCwnd(ackedBytes protocol.ByteCount) {
+	maxInflight := b.maxInflight()
+	if b.packetConservation {
+		b.congestionWindow = max(b.congestionWindow, b.bytesInFlight+ackedBytes)
+	} else if b.filledPipe {
+		b.congestionWindow = min(b.congestionWindow+ackedBytes, maxInflight)
+	} else if b.congestionWindow < maxInflight || b.sampler.Delivered() < b.initialCongestionWindow {
+		b.congestionWindow += ackedBytes
+	}
+	b.congestionWindow = max(b.congestionWindow, b.minPipeCwnd())
+	b.congestionWindow = min(b.congestionWindow, b.maxDatagramSize*protocol.MaxCongestionWindowPackets)
+	if b.mode == bbrModeProbeRTT {
+		b.congestionWindow = min(b.congestionWindow, b.probeRTTCwnd())
+	}
+	b.boundCwndForModel()
+}
+
+func (b *bbrSender) boundCwndForModel() {
+	capacity := protocol.MaxByteCount
+	if b.mode == bbrModeProbeBW && b.probeBWPhase != bbrProbeBWCruise {
+		capacity = b.inflightHi
+	} else if b.mode == bbrModeProbeRTT || (b.mode == bbrModeProbeBW && b.probeBWPhase == bbrProbeBWCruise) {
+		capacity = b.inflightWithHeadroom()
+	}
+	capacity = max(min(capacity, b.inflightLo), b.minPipeCwnd())
+	b.congestionWindow = min(b.congestionWindow, capacity)
+}
+
+func (b *bbrSender) saveCwnd() {
+	if !b.inRecovery && b.mode != bbrModeProbeRTT {
+		b.priorCongestionWindow = b.congestionWindow
+	} else {
+		b.priorCongestionWindow = max(b.priorCongestionWindow, b.congestionWindow)
+	}
+}
+
+func (b *bbrSender) restoreCwnd() {
+	b.congestionWindow = max(b.congestionWindow, b.priorCongestionWindow)
+}
+
+func (b *bbrSender) maybeTraceStateChange() {
+	if b.tracer == nil || b.tracer.UpdatedCongestionState == nil {
+		return
+	}
+	var state logging.CongestionState
+	switch b.mode {
+	case bbrModeStartup:
+		state = logging.CongestionStateBBRStartup
+	case bbrModeDrain:
+		state = logging.CongestionStateBBRDrain
+	case bbrModeProbeBW:
+		state = logging.CongestionStateBBRProbeBW
+	case bbrModeProbeRTT:
+		state = logging.CongestionStateBBRProbeRTT
+	}
+	if state == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(state)
+	b.lastState = state
+}
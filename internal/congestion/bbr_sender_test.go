@@ -0,0 +1,229 @@
+package congestion
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A simulatedLink is a bottleneck link with a FIFO queue and a fixed propagation delay.
+// Every packet is acknowledged individually, after the propagation delay.
+type simulatedLink struct {
+	bandwidth Bandwidth
+	rtt       time.Duration
+	// the maximum queueing delay, packets are dropped if the queue is full
+	maxQueueDelay time.Duration
+	lossRate      float64
+	rand          *rand.Rand
+
+	lastDeparture time.Time
+	inFlight      []simulatedPacket
+	bytesInFlight protocol.ByteCount
+	nextPN        protocol.PacketNumber
+}
+
+type simulatedPacket struct {
+	pn       protocol.PacketNumber
+	sentTime time.Time
+	arrival  time.Time // when the ACK (or the loss notification) arrives at the sender
+	lost     bool
+}
+
+// run runs a sender over the link, and returns the number of bytes delivered.
+func (l *simulatedLink) run(sender SendAlgorithmWithDebugInfos, rttStats *utils.RTTStats, clock *mockClock, duration time.Duration) protocol.ByteCount {
+	const tick = 500 * time.Microsecond
+	var delivered protocol.ByteCount
+	end := clock.Now().Add(duration)
+	for now := clock.Now(); now.Before(end); now = clock.Now() {
+		priorInFlight := l.bytesInFlight
+		var acked []simulatedPacket
+		for len(l.inFlight) > 0 && !l.inFlight[0].arrival.After(now) {
+			p := l.inFlight[0]
+			l.inFlight = l.inFlight[1:]
+			l.bytesInFlight -= maxDatagramSize
+			if p.lost {
+				sender.OnCongestionEvent(p.pn, maxDatagramSize, priorInFlight)
+				continue
+			}
+			acked = append(acked, p)
+		}
+		if len(acked) > 0 {
+			largest := acked[len(acked)-1]
+			rttStats.UpdateRTT(now.Sub(largest.sentTime), 0, now)
+			sender.MaybeExitSlowStart()
+			for _, p := range acked {
+				sender.OnPacketAcked(p.pn, maxDatagramSize, priorInFlight, now)
+				delivered += maxDatagramSize
+			}
+		}
+		for sender.CanSend(l.bytesInFlight) && sender.HasPacingBudget(now) {
+			l.bytesInFlight += maxDatagramSize
+			sender.OnPacketSent(now, l.bytesInFlight, l.nextPN, maxDatagramSize, true)
+			l.inFlight = append(l.inFlight, l.send(l.nextPN, now))
+			l.nextPN++
+		}
+		clock.Advance(tick)
+	}
+	return delivered
+}
+
+func (l *simulatedLink) send(pn protocol.PacketNumber, now time.Time) simulatedPacket {
+	transmissionTime := time.Duration(uint64(maxDatagramSize) * uint64(time.Second) / uint64(l.bandwidth/BytesPerSecond))
+	departure := now
+	if l.lastDeparture.After(now) {
+		departure = l.lastDeparture
+	}
+	p := simulatedPacket{pn: pn, sentTime: now}
+	if departure.Sub(now) > l.maxQueueDelay {
+		// The queue is full. Packet loss is detected roughly one RTT later.
+		p.lost = true
+		p.arrival = departure.Add(l.rtt)
+		return p
+	}
+	l.lastDeparture = departure.Add(transmissionTime)
+	p.arrival = l.lastDeparture.Add(l.rtt)
+	if l.rand.Float64() < l.lossRate {
+		p.lost = true
+	}
+	return p
+}
+
+var _ = Describe("BBR Sender", func() {
+	var (
+		sender   *bbrSender
+		clock    mockClock
+		rttStats *utils.RTTStats
+		states   []logging.CongestionState
+	)
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		states = nil
+		tracer := &logging.ConnectionTracer{
+			UpdatedCongestionState: func(s logging.CongestionState) { states = append(states, s) },
+		}
+		sender = newBBRSender(&clock, rttStats, maxDatagramSize, initialCongestionWindowPackets*maxDatagramSize, 0, tracer)
+	})
+
+	newLink := func(bandwidth Bandwidth, rtt time.Duration, lossRate float64) *simulatedLink {
+		return &simulatedLink{
+			bandwidth:     bandwidth,
+			rtt:           rtt,
+			maxQueueDelay: rtt,
+			lossRate:      lossRate,
+			rand:          rand.New(rand.NewSource(42)),
+		}
+	}
+
+	It("starts in Startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindowPackets * maxDatagramSize))
+		Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateBBRStartup}))
+		Expect(sender.TimeUntilSend(0)).To(BeZero())
+		Expect(sender.HasPacingBudget(clock.Now())).To(BeTrue())
+	})
+
+	It("estimates the bandwidth of the bottleneck", func() {
+		const bandwidth = 10 * 1000 * 1000 * BitsPerSecond
+		link := newLink(bandwidth, 40*time.Millisecond, 0)
+		link.run(sender, rttStats, &clock, 3*time.Second)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(states).To(HaveLen(3))
+		Expect(states[:3]).To(Equal([]logging.CongestionState{
+			logging.CongestionStateBBRStartup,
+			logging.CongestionStateBBRDrain,
+			logging.CongestionStateBBRProbeBW,
+		}))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", bandwidth, bandwidth/10))
+		Expect(sender.minRTT).To(BeNumerically("~", 40*time.Millisecond, 2*time.Millisecond))
+		// The congestion window is about twice the bandwidth-delay product.
+		bdp := protocol.ByteCount(bandwidth / BytesPerSecond / 25)
+		Expect(sender.GetCongestionWindow()).To(And(
+			BeNumerically(">", bdp),
+			BeNumerically("<", 3*bdp),
+		))
+		// Measure the throughput once the pipe is filled.
+		delivered := link.run(sender, rttStats, &clock, 4*time.Second)
+		Expect(BandwidthFromDelta(delivered, 4*time.Second)).To(BeNumerically(">", bandwidth*9/10))
+	})
+
+	It("probes the RTT", func() {
+		const bandwidth = 10 * 1000 * 1000 * BitsPerSecond
+		link := newLink(bandwidth, 40*time.Millisecond, 0)
+		link.run(sender, rttStats, &clock, 12*time.Second)
+		Expect(states).To(ContainElement(logging.CongestionStateBBRProbeRTT))
+		Expect(states[len(states)-1]).To(Equal(logging.CongestionStateBBRProbeBW))
+		Expect(sender.minRTT).To(BeNumerically("~", 40*time.Millisecond, 2*time.Millisecond))
+	})
+
+	It("achieves a high throughput on a long-RTT link with random loss", func() {
+		const bandwidth = 20 * 1000 * 1000 * BitsPerSecond
+		const rtt = 600 * time.Millisecond
+		link := newLink(bandwidth, rtt, 0.005)
+		// With an RTT of 600ms, it takes a few seconds to fill the pipe.
+		link.run(sender, rttStats, &clock, 20*time.Second)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		delivered := link.run(sender, rttStats, &clock, 10*time.Second)
+		Expect(BandwidthFromDelta(delivered, 10*time.Second)).To(BeNumerically(">", bandwidth*7/10))
+
+		// Reno only reaches a fraction of the bandwidth.
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		reno := NewCubicSender(&clock, rttStats, maxDatagramSize, true, 0, nil)
+		link = newLink(bandwidth, rtt, 0.005)
+		link.run(reno, rttStats, &clock, 20*time.Second)
+		delivered = link.run(reno, rttStats, &clock, 10*time.Second)
+		Expect(BandwidthFromDelta(delivered, 10*time.Second)).To(BeNumerically("<", bandwidth/2))
+	})
+
+	It("enters recovery when packets are lost", func() {
+		const bandwidth = 10 * 1000 * 1000 * BitsPerSecond
+		link := newLink(bandwidth, 40*time.Millisecond, 0)
+		link.run(sender, rttStats, &clock, time.Second)
+		cwnd := sender.GetCongestionWindow()
+		bytesInFlight := cwnd / 2
+		sender.OnPacketSent(clock.Now(), bytesInFlight, 100000, maxDatagramSize, true)
+		sender.OnCongestionEvent(99999, maxDatagramSize, bytesInFlight)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		// Recovery ends when a packet sent after the loss is acknowledged.
+		sender.OnPacketSent(clock.Now(), bytesInFlight, 100001, maxDatagramSize, true)
+		sender.OnPacketAcked(100001, maxDatagramSize, bytesInFlight, clock.Now().Add(40*time.Millisecond))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", cwnd))
+	})
+
+	It("ignores losses that the peer recovered using FEC, if configured", func() {
+		sender = newBBRSender(&clock, rttStats, maxDatagramSize, initialCongestionWindowPackets*maxDatagramSize, 1, nil)
+		sender.OnPacketSent(clock.Now(), maxDatagramSize, 1, maxDatagramSize, true)
+		sender.OnRecoveredLoss(1, maxDatagramSize, maxDatagramSize)
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindowPackets * maxDatagramSize))
+		Expect(sender.lossInRound).To(BeFalse())
+	})
+
+	It("treats losses that the peer recovered using FEC like other losses, if configured", func() {
+		sender.OnPacketSent(clock.Now(), maxDatagramSize, 1, maxDatagramSize, true)
+		sender.OnRecoveredLoss(1, maxDatagramSize, maxDatagramSize)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.lossInRound).To(BeTrue())
+	})
+
+	It("doesn't allow reductions of the maximum packet size", func() {
+		Expect(func() { sender.SetMaxDatagramSize(maxDatagramSize - 1) }).To(Panic())
+	})
+
+	It("keeps the minimum congestion window when the maximum packet size is increased", func() {
+		sender.congestionWindow = sender.minPipeCwnd()
+		sender.SetMaxDatagramSize(maxDatagramSize + 100)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinPipeCwndPackets * (maxDatagramSize + 100)))
+	})
+})
@@ -0,0 +1,308 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// A Controller is a congestion controller that can be implemented outside of quic-go.
+// Every path of a connection uses its own Controller.
+// Its methods are called from the connection's run loop, and don't need to be safe for concurrent use.
+type Controller interface {
+	// OnPacketSent is called when a packet is sent.
+	// Only ack-eliciting packets count towards the bytes in flight, and bytesInFlight includes the packet.
+	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, pn protocol.PacketNumber, size protocol.ByteCount, isAckEliciting bool)
+	// OnRTTUpdated is called when an ACK frame produced a new RTT sample,
+	// before the packets it acknowledges and the packets it causes to be declared lost are reported.
+	OnRTTUpdated()
+	// OnPacketAcked is called for every acknowledged packet that counted towards the bytes in flight.
+	OnPacketAcked(AckedPacket)
+	// OnPacketLost is called for every lost packet that counted towards the bytes in flight.
+	// Lost Path MTU probe packets are not reported.
+	OnPacketLost(LostPacket)
+	// OnCongestionExperienced is called when the peer reports that packets were marked ECN-CE.
+	OnCongestionExperienced(largestAcked protocol.PacketNumber, priorInFlight protocol.ByteCount)
+	// CanSend says if another packet can be sent, given the number of bytes in flight.
+	CanSend(bytesInFlight protocol.ByteCount) bool
+	// HasPacingBudget says if the pacer allows sending a packet now.
+	HasPacingBudget(now time.Time) bool
+	// TimeUntilSend returns when the pacer allows sending the next packet.
+	TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time
+	// SetMaxDatagramSize is called when Path MTU Discovery increases the maximum packet size.
+	SetMaxDatagramSize(protocol.ByteCount)
+	CongestionWindow() protocol.ByteCount
+	InSlowStart() bool
+	InRecovery() bool
+}
+
+// RTTStats are the RTT statistics of a path.
+type RTTStats interface {
+	// SmoothedRTT is the exponentially weighted moving average of the RTT samples (RFC 9002, Section 5.3).
+	SmoothedRTT() time.Duration
+	// MinRTT is the minimum RTT sample, adjusted for the peer's ACK delay.
+	MinRTT() time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT() time.Duration
+	// PTO is the probe timeout (RFC 9002, Section 6.2.1).
+	PTO(includeMaxAckDelay bool) time.Duration
+}
+
+var _ RTTStats = &utils.RTTStats{}
+
+// A ControllerConfig is passed to the constructor of a Controller.
+type ControllerConfig struct {
+	// The RTT statistics of the path. They are updated before the Controller is notified of an ACK.
+	RTTStats RTTStats
+	// The maximum packet size when the connection is established.
+	InitialMaxDatagramSize protocol.ByteCount
+	// The value of quic.Config.FECRecoveredLossBackoff.
+	FECRecoveredLossBackoff float64
+	// The tracer of the connection. May be nil.
+	Tracer *logging.ConnectionTracer
+}
+
+// A RateSample is the delivery rate measured when a packet is acknowledged or declared lost,
+// as described in draft-cheng-iccrg-delivery-rate-estimation.
+type RateSample struct {
+	// The delivery rate. 0 if no rate could be measured, e.g. for lost packets.
+	DeliveryRate Bandwidth
+	// IsAppLimited is set if the sender didn't use the whole congestion window while the packet was in flight.
+	// The delivery rate of such a sample underestimates the available bandwidth.
+	IsAppLimited bool
+	// The number of bytes delivered while the packet was in flight.
+	Delivered protocol.ByteCount
+	// The number of bytes lost while the packet was in flight.
+	Lost protocol.ByteCount
+	// The duration over which the delivery rate was measured.
+	Interval time.Duration
+	// The time between sending and acknowledging the packet. 0 for lost packets.
+	RTT time.Duration
+	// The number of bytes in flight when the packet was sent, including the packet itself.
+	BytesInFlight protocol.ByteCount
+}
+
+// An AckedPacket is a packet that was acknowledged.
+type AckedPacket struct {
+	PacketNumber protocol.PacketNumber
+	Size         protocol.ByteCount
+	// The bytes in flight before the ACK frame was processed.
+	PriorInFlight protocol.ByteCount
+	// The time the ACK frame was received.
+	EventTime time.Time
+	// Only valid if HasRateSample is set.
+	RateSample    RateSample
+	HasRateSample bool
+}
+
+// A LostPacket is a packet that was declared lost.
+type LostPacket struct {
+	PacketNumber protocol.PacketNumber
+	Size         protocol.ByteCount
+	// The bytes in flight before the packet was declared lost.
+	PriorInFlight protocol.ByteCount
+	// RecoveredByFEC is set if the peer recovered the content of the packet using FEC.
+	RecoveredByFEC bool
+	// Only valid if HasRateSample is set.
+	RateSample    RateSample
+	HasRateSample bool
+}
+
+// NewRenoController creates a Controller that uses TCP NewReno.
+func NewRenoController(c ControllerConfig) Controller {
+	return &sendAlgorithmController{sender: NewCubicSender(DefaultClock{}, c.RTTStats, c.InitialMaxDatagramSize, true, c.FECRecoveredLossBackoff, c.Tracer)}
+}
+
+// NewCubicController creates a Controller that uses Cubic.
+func NewCubicController(c ControllerConfig) Controller {
+	return &sendAlgorithmController{sender: NewCubicSender(DefaultClock{}, c.RTTStats, c.InitialMaxDatagramSize, false, c.FECRecoveredLossBackoff, c.Tracer)}
+}
+
+// NewBBRController creates a Controller that uses BBR.
+func NewBBRController(c ControllerConfig) Controller {
+	return &sendAlgorithmController{sender: NewBBRSender(DefaultClock{}, c.RTTStats, c.InitialMaxDatagramSize, c.FECRecoveredLossBackoff, c.Tracer)}
+}
+
+// The sendAlgorithmController exposes a built-in SendAlgorithm as a Controller.
+type sendAlgorithmController struct {
+	sender SendAlgorithmWithDebugInfos
+}
+
+var _ Controller = &sendAlgorithmController{}
+
+func (c *sendAlgorithmController) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, pn protocol.PacketNumber, size protocol.ByteCount, isAckEliciting bool) {
+	c.sender.OnPacketSent(sentTime, bytesInFlight, pn, size, isAckEliciting)
+}
+
+func (c *sendAlgorithmController) OnRTTUpdated() { c.sender.MaybeExitSlowStart() }
+
+func (c *sendAlgorithmController) OnPacketAcked(p AckedPacket) {
+	c.sender.OnPacketAcked(p.PacketNumber, p.Size, p.PriorInFlight, p.EventTime)
+}
+
+func (c *sendAlgorithmController) OnPacketLost(p LostPacket) {
+	if p.RecoveredByFEC {
+		c.sender.OnRecoveredLoss(p.PacketNumber, p.Size, p.PriorInFlight)
+		return
+	}
+	c.sender.OnCongestionEvent(p.PacketNumber, p.Size, p.PriorInFlight)
+}
+
+func (c *sendAlgorithmController) OnCongestionExperienced(largestAcked protocol.PacketNumber, priorInFlight protocol.ByteCount) {
+	c.sender.OnCongestionEvent(largestAcked, 0, priorInFlight)
+}
+
+func (c *sendAlgorithmController) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return c.sender.CanSend(bytesInFlight)
+}
+
+func (c *sendAlgorithmController) HasPacingBudget(now time.Time) bool {
+	return c.sender.HasPacingBudget(now)
+}
+
+func (c *sendAlgorithmController) TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time {
+	return c.sender.TimeUntilSend(bytesInFlight)
+}
+
+func (c *sendAlgorithmController) SetMaxDatagramSize(s protocol.ByteCount) {
+	c.sender.SetMaxDatagramSize(s)
+}
+func (c *sendAlgorithmController) CongestionWindow() protocol.ByteCount {
+	return c.sender.GetCongestionWindow()
+}
+func (c *sendAlgorithmController) InSlowStart() bool { return c.sender.InSlowStart() }
+func (c *sendAlgorithmController) InRecovery() bool  { return c.sender.InRecovery() }
+
+// NewControllerSender creates a SendAlgorithm that passes all events to a Controller,
+// together with the delivery rate samples.
+func NewControllerSender(c Controller, rttStats *utils.RTTStats) SendAlgorithmWithDebugInfos {
+	// Built-in controllers don't use the rate samples.
+	if c, ok := c.(*sendAlgorithmController); ok {
+		return c.sender
+	}
+	return &controllerSender{
+		controller: c,
+		rttStats:   rttStats,
+		sampler:    newBandwidthSampler(),
+	}
+}
+
+// The controllerSender passes the events of a SendAlgorithm to a Controller.
+type controllerSender struct {
+	controller Controller
+	rttStats   *utils.RTTStats
+	sampler    *bandwidthSampler
+
+	// Was sending blocked by the congestion window or the pacer since the last packet was sent?
+	blocked      bool
+	lastSentTime time.Time
+	nextSendTime time.Time
+}
+
+var _ SendAlgorithmWithDebugInfos = &controllerSender{}
+
+func (s *controllerSender) OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, pn protocol.PacketNumber, size protocol.ByteCount, isAckEliciting bool) {
+	s.controller.OnPacketSent(sentTime, bytesInFlight, pn, size, isAckEliciting)
+	if !isAckEliciting {
+		return
+	}
+	// If neither the congestion window nor the pacer prevented sending a packet earlier,
+	// there was no data to send.
+	earliest := s.lastSentTime
+	if s.nextSendTime.After(earliest) {
+		earliest = s.nextSendTime
+	}
+	if !s.blocked && !s.lastSentTime.IsZero() && sentTime.Sub(earliest) > protocol.TimerGranularity {
+		s.sampler.OnAppLimited(bytesInFlight - size)
+	}
+	s.sampler.OnPacketSent(sentTime, pn, size, bytesInFlight)
+	s.blocked = false
+	s.lastSentTime = sentTime
+	s.nextSendTime = s.controller.TimeUntilSend(bytesInFlight)
+}
+
+func (s *controllerSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	if !s.controller.CanSend(bytesInFlight) {
+		s.blocked = true
+		return false
+	}
+	return true
+}
+
+func (s *controllerSender) HasPacingBudget(now time.Time) bool {
+	if !s.controller.HasPacingBudget(now) {
+		s.blocked = true
+		return false
+	}
+	return true
+}
+
+func (s *controllerSender) TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time {
+	return s.controller.TimeUntilSend(bytesInFlight)
+}
+
+func (s *controllerSender) MaybeExitSlowStart() { s.controller.OnRTTUpdated() }
+
+func (s *controllerSender) OnPacketAcked(pn protocol.PacketNumber, ackedBytes, priorInFlight protocol.ByteCount, eventTime time.Time) {
+	rs, ok := s.sampler.OnPacketAcked(pn, ackedBytes, eventTime)
+	s.sampler.RemoveOlderThan(eventTime.Add(-2 * max(s.rttStats.PTO(true), time.Second)))
+	s.controller.OnPacketAcked(AckedPacket{
+		PacketNumber:  pn,
+		Size:          ackedBytes,
+		PriorInFlight: priorInFlight,
+		EventTime:     eventTime,
+		RateSample:    toRateSample(rs),
+		HasRateSample: ok,
+	})
+}
+
+func (s *controllerSender) OnCongestionEvent(pn protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	// ECN-CE is reported with lostBytes = 0.
+	if lostBytes == 0 {
+		s.controller.OnCongestionExperienced(pn, priorInFlight)
+		return
+	}
+	s.onPacketLost(pn, lostBytes, priorInFlight, false)
+}
+
+func (s *controllerSender) OnRecoveredLoss(pn protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	s.onPacketLost(pn, lostBytes, priorInFlight, true)
+}
+
+func (s *controllerSender) onPacketLost(pn protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount, recovered bool) {
+	rs, ok := s.sampler.OnPacketLost(pn, lostBytes)
+	s.controller.OnPacketLost(LostPacket{
+		PacketNumber:   pn,
+		Size:           lostBytes,
+		PriorInFlight:  priorInFlight,
+		RecoveredByFEC: recovered,
+		RateSample:     toRateSample(rs),
+		HasRateSample:  ok,
+	})
+}
+
+func toRateSample(rs rateSample) RateSample {
+	return RateSample{
+		DeliveryRate:  rs.deliveryRate,
+		IsAppLimited:  rs.isAppLimited,
+		Delivered:     rs.delivered,
+		Lost:          rs.lost,
+		Interval:      rs.interval,
+		RTT:           rs.rtt,
+		BytesInFlight: rs.txInFlight,
+	}
+}
+
+func (s *controllerSender) OnRetransmissionTimeout(bool) {}
+
+func (s *controllerSender) SetMaxDatagramSize(size protocol.ByteCount) {
+	s.controller.SetMaxDatagramSize(size)
+}
+
+func (s *controllerSender) InSlowStart() bool { return s.controller.InSlowStart() }
+func (s *controllerSender) InRecovery() bool  { return s.controller.InRecovery() }
+func (s *controllerSender) GetCongestionWindow() protocol.ByteCount {
+	return s.controller.CongestionWindow()
+}
@@ -0,0 +1,156 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A recordingController records the events it is notified of.
+// It allows sending a fixed number of bytes, without pacing.
+type recordingController struct {
+	cwnd protocol.ByteCount
+
+	rttUpdates int
+	acked      []AckedPacket
+	lost       []LostPacket
+	congested  []protocol.PacketNumber
+}
+
+var _ Controller = &recordingController{}
+
+func (c *recordingController) OnPacketSent(time.Time, protocol.ByteCount, protocol.PacketNumber, protocol.ByteCount, bool) {
+}
+func (c *recordingController) OnRTTUpdated()               { c.rttUpdates++ }
+func (c *recordingController) OnPacketAcked(p AckedPacket) { c.acked = append(c.acked, p) }
+func (c *recordingController) OnPacketLost(p LostPacket)   { c.lost = append(c.lost, p) }
+func (c *recordingController) OnCongestionExperienced(pn protocol.PacketNumber, _ protocol.ByteCount) {
+	c.congested = append(c.congested, pn)
+}
+func (c *recordingController) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < c.cwnd
+}
+func (c *recordingController) HasPacingBudget(time.Time) bool             { return true }
+func (c *recordingController) TimeUntilSend(protocol.ByteCount) time.Time { return time.Time{} }
+func (c *recordingController) SetMaxDatagramSize(protocol.ByteCount)      {}
+func (c *recordingController) CongestionWindow() protocol.ByteCount       { return c.cwnd }
+func (c *recordingController) InSlowStart() bool                          { return false }
+func (c *recordingController) InRecovery() bool                           { return false }
+
+// fixedRTTStats are RTT statistics that don't change.
+type fixedRTTStats struct{ rtt time.Duration }
+
+var _ RTTStats = fixedRTTStats{}
+
+func (s fixedRTTStats) SmoothedRTT() time.Duration { return s.rtt }
+func (s fixedRTTStats) MinRTT() time.Duration      { return s.rtt }
+func (s fixedRTTStats) LatestRTT() time.Duration   { return s.rtt }
+func (s fixedRTTStats) PTO(bool) time.Duration     { return 3 * s.rtt }
+
+var _ = Describe("Controller", func() {
+	var (
+		controller *recordingController
+		sender     SendAlgorithmWithDebugInfos
+		rttStats   *utils.RTTStats
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		controller = &recordingController{cwnd: 10 * maxDatagramSize}
+		rttStats = utils.NewRTTStats()
+		sender = NewControllerSender(controller, rttStats)
+		now = time.Now()
+	})
+
+	It("uses the built-in send algorithms directly", func() {
+		c := NewCubicController(ControllerConfig{RTTStats: rttStats, InitialMaxDatagramSize: maxDatagramSize})
+		Expect(NewControllerSender(c, rttStats)).To(BeAssignableToTypeOf(&cubicSender{}))
+		c = NewBBRController(ControllerConfig{RTTStats: rttStats, InitialMaxDatagramSize: maxDatagramSize})
+		Expect(NewControllerSender(c, rttStats)).To(BeAssignableToTypeOf(&bbrSender{}))
+	})
+
+	It("uses RTT statistics that are not maintained by quic-go", func() {
+		c := NewRenoController(ControllerConfig{RTTStats: fixedRTTStats{rtt: 100 * time.Millisecond}, InitialMaxDatagramSize: maxDatagramSize})
+		Expect(c.(*sendAlgorithmController).sender.(*cubicSender).BandwidthEstimate()).To(Equal(BandwidthFromDelta(c.CongestionWindow(), 100*time.Millisecond)))
+	})
+
+	It("passes the delivery rate of acknowledged packets", func() {
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			Expect(sender.CanSend(protocol.ByteCount(pn) * maxDatagramSize)).To(BeTrue())
+			sender.OnPacketSent(now, protocol.ByteCount(pn+1)*maxDatagramSize, pn, maxDatagramSize, true)
+		}
+		Expect(sender.CanSend(10 * maxDatagramSize)).To(BeFalse())
+		now = now.Add(100 * time.Millisecond)
+		sender.MaybeExitSlowStart()
+		Expect(controller.rttUpdates).To(Equal(1))
+		for pn := protocol.PacketNumber(0); pn < 10; pn++ {
+			sender.OnPacketAcked(pn, maxDatagramSize, 10*maxDatagramSize, now)
+		}
+		Expect(controller.acked).To(HaveLen(10))
+		p := controller.acked[9]
+		Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(9)))
+		Expect(p.Size).To(Equal(maxDatagramSize))
+		Expect(p.PriorInFlight).To(Equal(10 * maxDatagramSize))
+		Expect(p.EventTime).To(Equal(now))
+		Expect(p.HasRateSample).To(BeTrue())
+		Expect(p.RateSample.Delivered).To(Equal(10 * maxDatagramSize))
+		Expect(p.RateSample.Interval).To(Equal(100 * time.Millisecond))
+		Expect(p.RateSample.RTT).To(Equal(100 * time.Millisecond))
+		Expect(p.RateSample.BytesInFlight).To(Equal(10 * maxDatagramSize))
+		Expect(p.RateSample.DeliveryRate).To(Equal(BandwidthFromDelta(10*maxDatagramSize, 100*time.Millisecond)))
+		Expect(p.RateSample.IsAppLimited).To(BeFalse())
+	})
+
+	It("reports losses", func() {
+		sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+		sender.OnPacketSent(now, 2*maxDatagramSize, 2, maxDatagramSize, true)
+		sender.OnCongestionEvent(1, maxDatagramSize, 2*maxDatagramSize)
+		sender.OnRecoveredLoss(2, maxDatagramSize, maxDatagramSize)
+		Expect(controller.lost).To(HaveLen(2))
+		Expect(controller.lost[0].PacketNumber).To(Equal(protocol.PacketNumber(1)))
+		Expect(controller.lost[0].PriorInFlight).To(Equal(2 * maxDatagramSize))
+		Expect(controller.lost[0].RecoveredByFEC).To(BeFalse())
+		Expect(controller.lost[0].HasRateSample).To(BeTrue())
+		Expect(controller.lost[0].RateSample.Lost).To(Equal(maxDatagramSize))
+		Expect(controller.lost[1].PacketNumber).To(Equal(protocol.PacketNumber(2)))
+		Expect(controller.lost[1].RecoveredByFEC).To(BeTrue())
+		Expect(controller.lost[1].RateSample.Lost).To(Equal(2 * maxDatagramSize))
+	})
+
+	It("reports ECN-CE marks", func() {
+		sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+		sender.OnCongestionEvent(1, 0, maxDatagramSize)
+		Expect(controller.congested).To(Equal([]protocol.PacketNumber{1}))
+		Expect(controller.lost).To(BeEmpty())
+	})
+
+	It("marks samples as application limited if the sender didn't send when allowed to", func() {
+		sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+		// Neither the congestion window nor the pacer prevent sending, but the next packet is sent 10ms later.
+		now = now.Add(10 * time.Millisecond)
+		sender.OnPacketSent(now, 2*maxDatagramSize, 2, maxDatagramSize, true)
+		now = now.Add(50 * time.Millisecond)
+		sender.OnPacketAcked(1, maxDatagramSize, 2*maxDatagramSize, now)
+		sender.OnPacketAcked(2, maxDatagramSize, 2*maxDatagramSize, now)
+		Expect(controller.acked).To(HaveLen(2))
+		Expect(controller.acked[0].RateSample.IsAppLimited).To(BeFalse())
+		Expect(controller.acked[1].RateSample.IsAppLimited).To(BeTrue())
+	})
+
+	It("doesn't mark samples as application limited if the congestion window prevented sending", func() {
+		controller.cwnd = maxDatagramSize
+		sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+		Expect(sender.CanSend(maxDatagramSize)).To(BeFalse())
+		now = now.Add(50 * time.Millisecond)
+		sender.OnPacketAcked(1, maxDatagramSize, maxDatagramSize, now)
+		sender.OnPacketSent(now, maxDatagramSize, 2, maxDatagramSize, true)
+		now = now.Add(50 * time.Millisecond)
+		sender.OnPacketAcked(2, maxDatagramSize, maxDatagramSize, now)
+		Expect(controller.acked).To(HaveLen(2))
+		Expect(controller.acked[1].RateSample.IsAppLimited).To(BeFalse())
+	})
+})
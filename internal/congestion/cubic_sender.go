@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
-	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 )
 
@@ -21,7 +20,7 @@ const (
 
 type cubicSender struct {
 	hybridSlowStart HybridSlowStart
-	rttStats        *utils.RTTStats
+	rttStats        RTTStats
 	cubic           *Cubic
 	pacer           *pacer
 	clock           Clock
@@ -55,6 +54,10 @@ type cubicSender struct {
 
 	maxDatagramSize protocol.ByteCount
 
+	// The factor by which the congestion window is reduced when the peer recovered a lost packet using FEC.
+	// If 0, recovered losses are treated like any other loss.
+	recoveredLossBackoff float64
+
 	lastState logging.CongestionState
 	tracer    *logging.ConnectionTracer
 }
@@ -67,9 +70,10 @@ var (
 // NewCubicSender makes a new cubic sender
 func NewCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	initialMaxDatagramSize protocol.ByteCount,
 	reno bool,
+	recoveredLossBackoff float64,
 	tracer *logging.ConnectionTracer,
 ) *cubicSender {
 	return newCubicSender(
@@ -79,17 +83,19 @@ func NewCubicSender(
 		initialMaxDatagramSize,
 		initialCongestionWindow*initialMaxDatagramSize,
 		protocol.MaxCongestionWindowPackets*initialMaxDatagramSize,
+		recoveredLossBackoff,
 		tracer,
 	)
 }
 
 func newCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	reno bool,
 	initialMaxDatagramSize,
 	initialCongestionWindow,
 	initialMaxCongestionWindow protocol.ByteCount,
+	recoveredLossBackoff float64,
 	tracer *logging.ConnectionTracer,
 ) *cubicSender {
 	c := &cubicSender{
@@ -106,6 +112,7 @@ func newCubicSender(
 		reno:                       reno,
 		tracer:                     tracer,
 		maxDatagramSize:            initialMaxDatagramSize,
+		recoveredLossBackoff:       recoveredLossBackoff,
 	}
 	c.pacer = newPacer(c.BandwidthEstimate)
 	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
@@ -212,6 +219,29 @@ func (c *cubicSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lost
 	c.numAckedPackets = 0
 }
 
+// OnRecoveredLoss is called when the peer recovered the content of a lost packet using FEC.
+// Such a loss is most likely caused by random loss on the path rather than by congestion,
+// so the congestion window is reduced by recoveredLossBackoff instead of the regular backoff factor.
+func (c *cubicSender) OnRecoveredLoss(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	if c.recoveredLossBackoff == 0 {
+		c.OnCongestionEvent(packetNumber, lostBytes, priorInFlight)
+		return
+	}
+	if c.recoveredLossBackoff >= 1 || packetNumber <= c.largestSentAtLastCutback {
+		return
+	}
+	c.lastCutbackExitedSlowstart = c.InSlowStart()
+	c.maybeTraceStateChange(logging.CongestionStateRecovery)
+
+	c.congestionWindow = protocol.ByteCount(float64(c.congestionWindow) * c.recoveredLossBackoff)
+	if minCwnd := c.minCongestionWindow(); c.congestionWindow < minCwnd {
+		c.congestionWindow = minCwnd
+	}
+	c.slowStartThreshold = c.congestionWindow
+	c.largestSentAtLastCutback = c.largestSentPacketNumber
+	c.numAckedPackets = 0
+}
+
 // Called when we receive an ack. Normal TCP tracks how many packets one ack
 // represents, but quic has a separate ack for each packet.
 func (c *cubicSender) maybeIncreaseCwnd(
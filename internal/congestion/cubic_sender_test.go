@@ -50,6 +50,7 @@ var _ = Describe("Cubic Sender", func() {
 			protocol.InitialPacketSizeIPv4,
 			initialCongestionWindowPackets*maxDatagramSize,
 			MaxCongestionWindow,
+			0,
 			nil,
 		)
 	})
@@ -319,7 +320,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp cubic reset epoch on quiescence", func() {
 		const maxCongestionWindow = 50
 		const maxCongestionWindowBytes = maxCongestionWindow * maxDatagramSize
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, nil)
+		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, 0, nil)
 
 		numSent := SendAvailableSendWindow()
 
@@ -460,7 +461,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("slow starts up to the maximum congestion window", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, 0, nil)
 
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
 			sender.MaybeExitSlowStart()
@@ -475,7 +476,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("slow starts up to maximum congestion window, if larger packets are sent", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, 0, nil)
 		const packetSize = initialMaxDatagramSize + 100
 		sender.SetMaxDatagramSize(packetSize)
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
@@ -490,7 +491,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("limit cwnd increase in congestion avoidance", func() {
 		// Enable Cubic.
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, 0, nil)
 		numSent := SendAvailableSendWindow()
 
 		// Make sure we fall out of slow start.
@@ -524,4 +525,39 @@ var _ = Describe("Cubic Sender", func() {
 		AckNPackets(2)
 		Expect(sender.GetCongestionWindow()).To(Equal(savedCwnd + maxDatagramSize))
 	})
+
+	Context("losses recovered using FEC", func() {
+		It("treats recovered losses like other losses by default", func() {
+			SendAvailableSendWindow()
+			AckNPackets(2)
+			cwnd := sender.GetCongestionWindow()
+			sender.OnRecoveredLoss(ackedPacketNumber+1, maxDatagramSize, bytesInFlight)
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * renoBeta)))
+			Expect(sender.InRecovery()).To(BeTrue())
+		})
+
+		It("uses the recovered loss backoff", func() {
+			sender.recoveredLossBackoff = 0.9
+			SendAvailableSendWindow()
+			AckNPackets(2)
+			cwnd := sender.GetCongestionWindow()
+			sender.OnRecoveredLoss(ackedPacketNumber+1, maxDatagramSize, bytesInFlight)
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * 0.9)))
+			Expect(sender.InRecovery()).To(BeTrue())
+			// further losses in the same window don't reduce the congestion window again
+			sender.OnRecoveredLoss(ackedPacketNumber+3, maxDatagramSize, bytesInFlight)
+			Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(float64(cwnd) * 0.9)))
+		})
+
+		It("doesn't reduce the congestion window if the backoff is 1", func() {
+			sender.recoveredLossBackoff = 1
+			SendAvailableSendWindow()
+			AckNPackets(2)
+			cwnd := sender.GetCongestionWindow()
+			sender.OnRecoveredLoss(ackedPacketNumber+1, maxDatagramSize, bytesInFlight)
+			Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+			Expect(sender.InRecovery()).To(BeFalse())
+			Expect(sender.InSlowStart()).To(BeTrue())
+		})
+	})
 })
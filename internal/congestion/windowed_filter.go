@@ -0,0 +1,68 @@
+package congestion
+
+import "github.com/quic-go/quic-go/internal/protocol"
+
+type windowedSample struct {
+	value protocol.ByteCount
+	round uint64
+}
+
+// A windowedMaxFilter tracks the maximum of a value over a window of round trips.
+// It uses the algorithm by Kathleen Nichols that is also used by the Linux kernel (lib/win_minmax.c):
+// Besides the maximum, it keeps the best values of the second and third quarter of the window,
+// so that it doesn't need to store all samples to find a new maximum when the current one expires.
+type windowedMaxFilter struct {
+	windowLength uint64
+	samples      [3]windowedSample
+}
+
+func newWindowedMaxFilter(windowLength uint64) *windowedMaxFilter {
+	return &windowedMaxFilter{windowLength: windowLength}
+}
+
+// Get returns the maximum over the window.
+func (f *windowedMaxFilter) Get() protocol.ByteCount {
+	return f.samples[0].value
+}
+
+// Update adds a sample taken in a round.
+// The round must be larger than or equal to the round of all previous samples.
+func (f *windowedMaxFilter) Update(value protocol.ByteCount, round uint64) {
+	sample := windowedSample{value: value, round: round}
+	if value >= f.samples[0].value || round-f.samples[2].round > f.windowLength {
+		f.Reset(value, round)
+		return
+	}
+	if value >= f.samples[1].value {
+		f.samples[1] = sample
+		f.samples[2] = sample
+	} else if value >= f.samples[2].value {
+		f.samples[2] = sample
+	}
+
+	elapsed := round - f.samples[0].round
+	switch {
+	case elapsed > f.windowLength:
+		// The maximum expired. The best value of the second quarter becomes the maximum.
+		f.samples[0] = f.samples[1]
+		f.samples[1] = f.samples[2]
+		f.samples[2] = sample
+		if round-f.samples[0].round > f.windowLength {
+			f.samples[0] = f.samples[1]
+			f.samples[1] = f.samples[2]
+		}
+	case f.samples[1].round == f.samples[0].round && elapsed > f.windowLength/4:
+		// A quarter of the window passed without a value that's as large as the maximum.
+		f.samples[1] = sample
+		f.samples[2] = sample
+	case f.samples[2].round == f.samples[1].round && elapsed > f.windowLength/2:
+		// Half of the window passed without a value that's as large as the second best one.
+		f.samples[2] = sample
+	}
+}
+
+// Reset drops all previous samples.
+func (f *windowedMaxFilter) Reset(value protocol.ByteCount, round uint64) {
+	sample := windowedSample{value: value, round: round}
+	f.samples = [3]windowedSample{sample, sample, sample}
+}
@@ -0,0 +1,48 @@
+package congestion
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Windowed Max Filter", func() {
+	It("tracks the maximum", func() {
+		f := newWindowedMaxFilter(10)
+		f.Update(100, 1)
+		Expect(f.Get()).To(BeEquivalentTo(100))
+		f.Update(50, 2)
+		Expect(f.Get()).To(BeEquivalentTo(100))
+		f.Update(200, 3)
+		Expect(f.Get()).To(BeEquivalentTo(200))
+	})
+
+	It("expires the maximum after the window", func() {
+		f := newWindowedMaxFilter(10)
+		f.Update(300, 0)
+		for round := uint64(1); round <= 10; round++ {
+			f.Update(100, round)
+			Expect(f.Get()).To(BeEquivalentTo(300))
+		}
+		f.Update(100, 11)
+		Expect(f.Get()).To(BeEquivalentTo(100))
+	})
+
+	It("uses the best value of the rest of the window once the maximum expires", func() {
+		f := newWindowedMaxFilter(8)
+		f.Update(300, 0)
+		f.Update(250, 3) // second quarter
+		f.Update(200, 6) // second half
+		Expect(f.Get()).To(BeEquivalentTo(300))
+		f.Update(10, 9)
+		Expect(f.Get()).To(BeEquivalentTo(250))
+		f.Update(10, 12)
+		Expect(f.Get()).To(BeEquivalentTo(200))
+	})
+
+	It("resets", func() {
+		f := newWindowedMaxFilter(10)
+		f.Update(300, 0)
+		f.Reset(42, 1)
+		Expect(f.Get()).To(BeEquivalentTo(42))
+	})
+})
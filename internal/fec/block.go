@@ -31,6 +31,9 @@ type block struct {
 	// totNumRepairSymbols represents the total number of repair symbols in this block.
 	totNumRepairSymbols         int
 	biggestSourceSymbolLenSoFar int
+	// repairSymbols are the first repair symbols of a complete block on the sender side.
+	// The remaining repair symbols are generated when they are needed, using the source symbols kept in the block.
+	repairSymbols []*wire.RepairFrame
 }
 
 func newBlock(id protocol.BlockID, totNumSourceSymbols int, totNumRepairSymbols int) *block {
@@ -60,6 +63,14 @@ func (b *block) addSourceSymbol(f *wire.SourceSymbolFrame) error {
 
 	// at this point, we know the source symbol belongs to the block.
 
+	if len(f.Payload) > protocol.MaxFECPacketBufferSize {
+		return fmt.Errorf("source symbol payload too large. Max %d and got %d", protocol.MaxFECPacketBufferSize, len(f.Payload))
+	}
+	// The repair symbols are as large as the biggest source symbol, so no source symbol may be larger.
+	if len(b.pidToRepairPayload) > 0 && len(f.Payload) > b.biggestSourceSymbolLenSoFar {
+		return fmt.Errorf("source symbol payload (%d bytes) is larger than the repair symbols (%d bytes)", len(f.Payload), b.biggestSourceSymbolLenSoFar)
+	}
+
 	if _, exists := b.ssidToSourcePayload[f.SSID]; !exists {
 		b.ssidToSourcePayload[f.SSID] = f.Payload
 		if b.biggestSourceSymbolLenSoFar < len(f.Payload) {
@@ -77,9 +88,23 @@ func (b *block) addRepairSymbol(f *wire.RepairFrame) error {
 
 	// at this point, we know the repair symbol belongs to the block
 
+	if f.Metadata.ParityID >= protocol.ParityID(b.totNumRepairSymbols) {
+		return fmt.Errorf("invalid parity ID. Expecting a parity ID smaller than %d and got %d", b.totNumRepairSymbols, f.Metadata.ParityID)
+	}
+	if len(f.Payload) < protocol.RepairPayloadMetadataLen || len(f.Payload) > protocol.MaxFECPacketBufferSize+protocol.RepairPayloadMetadataLen {
+		return fmt.Errorf("invalid repair symbol payload length: %d", len(f.Payload))
+	}
+	symbolLen := len(f.Payload) - protocol.RepairPayloadMetadataLen
+	if len(b.pidToRepairPayload) > 0 && symbolLen != b.biggestSourceSymbolLenSoFar {
+		return fmt.Errorf("repair symbols of different lengths: expected %d and got %d", b.biggestSourceSymbolLenSoFar, symbolLen)
+	}
+	if symbolLen < b.biggestSourceSymbolLenSoFar {
+		return fmt.Errorf("repair symbol (%d bytes) is smaller than a source symbol of the block (%d bytes)", symbolLen, b.biggestSourceSymbolLenSoFar)
+	}
+
 	if _, exists := b.pidToRepairPayload[f.Metadata.ParityID]; !exists {
 		b.pidToRepairPayload[f.Metadata.ParityID] = f.Payload
-		b.biggestSourceSymbolLenSoFar = len(f.Payload) - protocol.RepairPayloadMetadataLen
+		b.biggestSourceSymbolLenSoFar = symbolLen
 	}
 	return nil
 }
@@ -0,0 +1,545 @@
+package fec
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+func TestBlock_addSourceSymbol(t *testing.T) {
+	tests := []struct {
+		name    string
+		repair  *wire.RepairFrame
+		source  *wire.SourceSymbolFrame
+		wantErr bool
+	}{
+		{
+			name:   "valid source symbol",
+			source: &wire.SourceSymbolFrame{SSID: 1, Payload: []byte{1, 2, 3}},
+		},
+		{
+			name:    "SSID outside of the block",
+			source:  &wire.SourceSymbolFrame{SSID: 2, Payload: []byte{1, 2, 3}},
+			wantErr: true,
+		},
+		{
+			name:    "payload too large",
+			source:  &wire.SourceSymbolFrame{SSID: 0, Payload: make([]byte, protocol.MaxFECPacketBufferSize+1)},
+			wantErr: true,
+		},
+		{
+			name:   "payload as large as the repair symbol",
+			repair: &wire.RepairFrame{Payload: []byte{0, 0, 0, 0, 0}},
+			source: &wire.SourceSymbolFrame{SSID: 0, Payload: []byte{1, 2, 3}},
+		},
+		{
+			name:    "payload larger than the repair symbol",
+			repair:  &wire.RepairFrame{Payload: []byte{0, 0, 0, 0}},
+			source:  &wire.SourceSymbolFrame{SSID: 0, Payload: []byte{1, 2, 3}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBlock(0, 2, 1)
+			if tt.repair != nil {
+				if err := b.addRepairSymbol(tt.repair); err != nil {
+					t.Fatalf("addRepairSymbol() error = %v", err)
+				}
+			}
+			if err := b.addSourceSymbol(tt.source); (err != nil) != tt.wantErr {
+				t.Errorf("addSourceSymbol() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBlock_addRepairSymbol(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  *wire.SourceSymbolFrame
+		repairs []*wire.RepairFrame
+		wantErr bool
+	}{
+		{
+			name: "valid repair symbols",
+			repairs: []*wire.RepairFrame{
+				{Metadata: protocol.BlockMetadata{ParityID: 0}, Payload: []byte{1, 2, 3, 4}},
+				{Metadata: protocol.BlockMetadata{ParityID: 1}, Payload: []byte{5, 6, 7, 8}},
+			},
+		},
+		{
+			name:    "wrong block",
+			repairs: []*wire.RepairFrame{{Metadata: protocol.BlockMetadata{BlockID: 1}, Payload: []byte{1, 2, 3}}},
+			wantErr: true,
+		},
+		{
+			name:    "parity ID too large",
+			repairs: []*wire.RepairFrame{{Metadata: protocol.BlockMetadata{ParityID: 2}, Payload: []byte{1, 2, 3}}},
+			wantErr: true,
+		},
+		{
+			name:    "payload shorter than the metadata",
+			repairs: []*wire.RepairFrame{{Payload: []byte{1}}},
+			wantErr: true,
+		},
+		{
+			name:    "payload too large",
+			repairs: []*wire.RepairFrame{{Payload: make([]byte, protocol.MaxFECPacketBufferSize+protocol.RepairPayloadMetadataLen+1)}},
+			wantErr: true,
+		},
+		{
+			name: "repair symbols of different lengths",
+			repairs: []*wire.RepairFrame{
+				{Metadata: protocol.BlockMetadata{ParityID: 0}, Payload: []byte{1, 2, 3, 4}},
+				{Metadata: protocol.BlockMetadata{ParityID: 1}, Payload: []byte{5, 6, 7}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "repair symbol smaller than a source symbol",
+			source:  &wire.SourceSymbolFrame{SSID: 0, Payload: []byte{1, 2, 3}},
+			repairs: []*wire.RepairFrame{{Payload: []byte{1, 2, 3, 4}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBlock(0, 4, 2)
+			if tt.source != nil {
+				if err := b.addSourceSymbol(tt.source); err != nil {
+					t.Fatalf("addSourceSymbol() error = %v", err)
+				}
+			}
+			var err error
+			for _, f := range tt.repairs {
+				if err = b.addRepairSymbol(f); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("addRepairSymbol() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManager_HandleRepairFrameInvalidBlockID(t *testing.T) {
+	m, err := NewReceiver(protocol.ReedSolomonFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the largest block that only contains valid SSIDs
+	maxBlockID := protocol.BlockID((quicvarint.Max+1)/20 - 1)
+	// move the decoding window, such that both block IDs lie within it
+	m.(*manager).highestBlockID = maxBlockID - 1
+	m.(*manager).lowestBlockID = maxBlockID - 1
+	if _, err := m.HandleRepairFrame(&wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: maxBlockID}, Payload: []byte{1, 2, 3}}); err != nil {
+		t.Errorf("HandleRepairFrame() error = %v", err)
+	}
+	if _, err := m.HandleRepairFrame(&wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: maxBlockID + 1}, Payload: []byte{1, 2, 3}}); err == nil {
+		t.Error("HandleRepairFrame() expected an error for a block ID outside of the SSID space")
+	}
+}
+
+func TestManager_DecodingWindow(t *testing.T) {
+	m, err := NewManager(&xorScheme{}, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := protocol.BlockID(protocol.MaxFECDecodingWindow / 2)
+
+	// the first block that lies beyond the window
+	_, err = m.HandleRepairFrame(&wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: window + 1}, Payload: []byte{0, 1, 2}})
+	if !errors.Is(err, ErrSymbolOutsideWindow) {
+		t.Fatalf("HandleRepairFrame() error = %v, want %v", err, ErrSymbolOutsideWindow)
+	}
+	_, err = m.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(2 * (window + 1)), Payload: []byte{1}})
+	if !errors.Is(err, ErrSymbolOutsideWindow) {
+		t.Fatalf("HandleSourceSymbolFrame() error = %v, want %v", err, ErrSymbolOutsideWindow)
+	}
+
+	// block 0 is incomplete, and is tracked
+	if _, err := m.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 0, Payload: []byte{1}}); err != nil {
+		t.Fatalf("HandleSourceSymbolFrame() error = %v", err)
+	}
+	// the last block within the window
+	if _, err := m.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(2 * window), Payload: []byte{1}}); err != nil {
+		t.Fatalf("HandleSourceSymbolFrame() error = %v", err)
+	}
+	if _, ok := m.blockStatuses[0]; !ok {
+		t.Fatal("expected block 0 to still be tracked")
+	}
+	// moving the window evicts block 0
+	if _, err := m.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(2 * (window + 1)), Payload: []byte{1}}); err != nil {
+		t.Fatalf("HandleSourceSymbolFrame() error = %v", err)
+	}
+	if _, ok := m.blockStatuses[0]; ok {
+		t.Fatal("expected block 0 to be evicted")
+	}
+	// source symbols of evicted blocks are still passed up
+	payload, err := m.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 1, Payload: []byte{42}})
+	if err != nil {
+		t.Fatalf("HandleSourceSymbolFrame() error = %v", err)
+	}
+	if !bytes.Equal(payload, []byte{42}) {
+		t.Fatalf("HandleSourceSymbolFrame() = %x, want %x", payload, []byte{42})
+	}
+	// repair symbols of evicted blocks are ignored
+	recovered, err := m.HandleRepairFrame(&wire.RepairFrame{Metadata: protocol.BlockMetadata{BlockID: 0}, Payload: []byte{0, 1, 1}})
+	if err != nil || recovered != nil {
+		t.Fatalf("HandleRepairFrame() = %x, %v, want nil, nil", recovered, err)
+	}
+	if _, ok := m.blockStatuses[0]; ok {
+		t.Fatal("expected block 0 to not be tracked again")
+	}
+}
+
+func TestManager_RepairSymbol(t *testing.T) {
+	sender, err := NewSender(protocol.ReedSolomonFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewReceiver(protocol.ReedSolomonFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numSourceSymbols, numRepairSymbols := sender.BlockSize()
+	if numSourceSymbols != 20 || numRepairSymbols != 20 {
+		t.Fatalf("BlockSize() = %d, %d, want 20, 20", numSourceSymbols, numRepairSymbols)
+	}
+
+	payloads := make([][]byte, numSourceSymbols)
+	var repairs []*wire.RepairFrame
+	for i := range payloads {
+		payloads[i] = make([]byte, 10+i, protocol.MaxPacketBufferSize)
+		for j := range payloads[i] {
+			payloads[i][j] = byte(i*j + 1)
+		}
+		repairs, err = sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i), Payload: payloads[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(repairs) != 10 {
+		t.Fatalf("expected 10 repair symbols, got %d", len(repairs))
+	}
+	// only the repair symbols that are sent for every block are generated when the block is complete
+	if n := len(sender.(*manager).sentBlocks[0].repairSymbols); n != 10 {
+		t.Fatalf("expected 10 repair symbols to be generated, got %d", n)
+	}
+	if _, ok := sender.RepairSymbol(0, 20); ok {
+		t.Fatal("expected no repair symbol for parity ID 20")
+	}
+	if _, ok := sender.RepairSymbol(1, 0); ok {
+		t.Fatal("expected no repair symbol for an incomplete block")
+	}
+	f, ok := sender.RepairSymbol(0, 3)
+	if !ok {
+		t.Fatal("expected a repair symbol for parity ID 3")
+	}
+	if !reflect.DeepEqual(f, repairs[3]) {
+		t.Fatalf("RepairSymbol() = %v, want %v", f, repairs[3])
+	}
+
+	// lose the first 11 source symbols, and recover them using 11 repair symbols, including 1 additional one
+	for i := 11; i < numSourceSymbols; i++ {
+		payload := make([]byte, len(payloads[i]), protocol.MaxPacketBufferSize)
+		copy(payload, payloads[i])
+		if _, err := receiver.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i), Payload: payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range repairs {
+		if recovered, err := receiver.HandleRepairFrame(r); err != nil || recovered != nil {
+			t.Fatalf("HandleRepairFrame() = %x, %v, want nil, nil", recovered, err)
+		}
+	}
+	if n := len(sender.(*manager).sentBlocks[0].repairSymbols); n != 10 {
+		t.Fatalf("expected 10 repair symbols to be generated, got %d", n)
+	}
+	extra, ok := sender.RepairSymbol(0, 15)
+	if !ok {
+		t.Fatal("expected a repair symbol for parity ID 15")
+	}
+	if n := len(sender.(*manager).sentBlocks[0].repairSymbols); n != 20 {
+		t.Fatalf("expected 20 repair symbols to be generated, got %d", n)
+	}
+	recovered, err := receiver.HandleRepairFrame(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := bytes.Join(payloads[:11], nil); !bytes.Equal(recovered, want) {
+		t.Fatalf("recovered %x, want %x", recovered, want)
+	}
+}
+
+func TestManager_DecodingWindowMemory(t *testing.T) {
+	scheme, err := NewReedSolomonScheme(2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(scheme, 2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The block has more repair symbols per source symbol than the blocks of the protection classes,
+	// so the window is limited by the decoder's memory.
+	window := m.windowBlocks()
+	if want := protocol.BlockID(protocol.MaxFECDecoderMemory / (102 * protocol.MaxFECPacketBufferSize)); window != want {
+		t.Fatalf("windowBlocks() = %d, want %d", window, want)
+	}
+	if protocol.MaxFECDecodingWindow/2 <= int(window) {
+		t.Fatal("expected the window to be smaller than the advertised window")
+	}
+}
+
+func TestManager_SentBlockHistory(t *testing.T) {
+	sender, err := NewSender(protocol.XORFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := sender.(*manager)
+	// a block consists of 2 source symbols and a repair symbol
+	maxSentBlocks := protocol.MaxFECSenderMemory / (3 * protocol.MaxFECPacketBufferSize)
+	for i := 0; i < 2*(maxSentBlocks+1); i++ {
+		if _, err := m.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: m.NextSSID(), Payload: make([]byte, 1, protocol.MaxPacketBufferSize)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(m.blockStatuses) != 0 {
+		t.Fatalf("expected completed blocks to be removed from the block statuses, got %d", len(m.blockStatuses))
+	}
+	if len(m.sentBlocks) != maxSentBlocks {
+		t.Fatalf("expected %d blocks to be kept, got %d", maxSentBlocks, len(m.sentBlocks))
+	}
+	if _, ok := m.RepairSymbol(0, 0); ok {
+		t.Fatal("expected the oldest block to be evicted")
+	}
+	if _, ok := m.RepairSymbol(protocol.BlockID(maxSentBlocks), 0); !ok {
+		t.Fatal("expected the newest block to be kept")
+	}
+}
+
+func TestManager_RecoverOnSourceSymbol(t *testing.T) {
+	sender, err := NewSender(protocol.XORFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewReceiver(protocol.XORFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloads := [][]byte{{1, 2, 3}, {4, 5}}
+	var repairs []*wire.RepairFrame
+	for i, p := range payloads {
+		payload := make([]byte, len(p), protocol.MaxPacketBufferSize)
+		copy(payload, p)
+		repairs, err = sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i), Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(repairs) != 1 {
+		t.Fatalf("expected 1 repair symbol, got %d", len(repairs))
+	}
+	// The repair symbol arrives before the second source symbol, and the first source symbol is lost.
+	if recovered, err := receiver.HandleRepairFrame(repairs[0]); err != nil || recovered != nil {
+		t.Fatalf("HandleRepairFrame() = %x, %v, want nil, nil", recovered, err)
+	}
+	blockData, err := receiver.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: 1, Payload: payloads[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{4, 5, 1, 2, 3}; !bytes.Equal(blockData, want) {
+		t.Fatalf("HandleSourceSymbolFrame() = %x, want %x", blockData, want)
+	}
+}
+
+func TestManager_MaxSourceSymbolLen(t *testing.T) {
+	for _, format := range []protocol.FECWireFormat{protocol.FECWireFormatLegacy, protocol.FECWireFormatDraft} {
+		t.Run(format.String(), func(t *testing.T) {
+			sender, err := NewSenderWithClass(protocol.ReedSolomonFECScheme, protocol.FECProtectionHigh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			numSourceSymbols, _ := sender.BlockSize()
+			addBlock := func(symbolLen protocol.ByteCount) []*wire.RepairFrame {
+				var repairs []*wire.RepairFrame
+				for i := 0; i < numSourceSymbols; i++ {
+					ssf := &wire.SourceSymbolFrame{Format: format, SSID: sender.NextSSID(), Payload: make([]byte, symbolLen, protocol.MaxPacketBufferSize)}
+					if l := ssf.Length(protocol.Version1); l > 1200 {
+						t.Fatalf("SOURCE_SYMBOL frame too large: %d bytes", l)
+					}
+					r, err := sender.AddSourceSymbolFrame(ssf)
+					if err != nil {
+						t.Fatal(err)
+					}
+					repairs = append(repairs, r...)
+				}
+				return repairs
+			}
+
+			symbolLen := sender.MaxSourceSymbolLen(1200, 1200, format, protocol.Version1)
+			repairs := addBlock(symbolLen)
+			var largestRepair protocol.ByteCount
+			for _, f := range repairs {
+				f.Format = format
+				largestRepair = max(largestRepair, f.Length(protocol.Version1))
+			}
+			// The size of the REPAIR frames is computed exactly.
+			// There's one pathological case, where the payload length can't be increased without increasing the length of its varint.
+			if largestRepair != 1200 && largestRepair != 1199 {
+				t.Fatalf("expected the largest REPAIR frame to fill the packet, got %d bytes", largestRepair)
+			}
+
+			// The new maximum packet size applies from the first source symbol of the next block on.
+			if l := sender.MaxSourceSymbolLen(1200, 1200, format, protocol.Version1); l != symbolLen {
+				t.Fatalf("expected the same length for the next block, got %d bytes", l)
+			}
+			if _, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: sender.NextSSID(), Payload: make([]byte, symbolLen, protocol.MaxPacketBufferSize)}); err != nil {
+				t.Fatal(err)
+			}
+			// The SOURCE_SYMBOL frame still has to fit into the packet.
+			if l := sender.MaxSourceSymbolLen(100, 1200, format, protocol.Version1); l >= 100 {
+				t.Fatalf("expected the length to be limited by the frame size, got %d bytes", l)
+			}
+			if l := sender.MaxSourceSymbolLen(1200, 1200, format, protocol.Version1); l != symbolLen {
+				t.Fatalf("expected the length to stay at %d bytes within a block, got %d", symbolLen, l)
+			}
+
+			// The maximum packet size changes in the middle of a block.
+			// The block is closed, and the new length applies to the next block.
+			nextBlock := protocol.SourceSymbolID(2 * numSourceSymbols)
+			if ssid := sender.PeekSSID(); ssid != nextBlock-protocol.SourceSymbolID(numSourceSymbols)+1 {
+				t.Fatalf("expected the block to be open, next SSID is %d", ssid)
+			}
+			largerLen := sender.MaxSourceSymbolLen(1400, 1400, format, protocol.Version1)
+			if largerLen <= symbolLen {
+				t.Fatalf("expected the length to grow, got %d bytes", largerLen)
+			}
+			if ssid := sender.PeekSSID(); ssid != nextBlock {
+				t.Fatalf("expected the next block to start at SSID %d, got %d", nextBlock, ssid)
+			}
+			for i := 0; i < numSourceSymbols-1; i++ {
+				if _, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: sender.NextSSID(), Payload: make([]byte, largerLen, protocol.MaxPacketBufferSize)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// The closed block doesn't produce any repair symbols.
+			if _, ok := sender.RepairSymbol(1, 0); ok {
+				t.Fatal("expected no repair symbols for the closed block")
+			}
+
+			// The maximum packet size decreases in the middle of the next block.
+			smallerLen := sender.MaxSourceSymbolLen(1100, 1100, format, protocol.Version1)
+			if smallerLen >= symbolLen {
+				t.Fatalf("expected the length to shrink, got %d bytes", smallerLen)
+			}
+			if ssid := sender.PeekSSID(); ssid != nextBlock+protocol.SourceSymbolID(numSourceSymbols) {
+				t.Fatalf("expected the next block to start at SSID %d, got %d", nextBlock+protocol.SourceSymbolID(numSourceSymbols), ssid)
+			}
+			repairs = nil
+			for i := 0; i < numSourceSymbols; i++ {
+				r, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{Format: format, SSID: sender.NextSSID(), Payload: make([]byte, smallerLen, protocol.MaxPacketBufferSize)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				repairs = append(repairs, r...)
+			}
+			if len(repairs) == 0 {
+				t.Fatal("expected repair symbols for the complete block")
+			}
+			for _, f := range repairs {
+				f.Format = format
+				if l := f.Length(protocol.Version1); l > 1100 {
+					t.Fatalf("REPAIR frame too large: %d bytes", l)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeDraftID(t *testing.T) {
+	tests := []struct {
+		name               string
+		received, expected uint64
+		want               uint64
+	}{
+		{"first epoch", 0x1337, 0x1000, 0x1337},
+		{"behind the expected ID", 0x1000, 0x1337, 0x1000},
+		{"rollover to the next epoch", 0x2, 0xfffffff0, 1<<32 + 0x2},
+		{"behind the expected ID, in the previous epoch", 0xfffffff0, 1<<32 + 0x2, 0xfffffff0},
+		{"later epoch", 0x42, 5<<32 + 0x40, 5<<32 + 0x42},
+		{"no epoch before the first one", 0xfffffff0, 0x2, 0xfffffff0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeDraftID(tt.received, tt.expected); got != tt.want {
+				t.Errorf("decodeDraftID(%#x, %#x) = %#x, want %#x", tt.received, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_DraftIDRollover(t *testing.T) {
+	sender, err := NewManager(&xorScheme{}, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewManager(&xorScheme{}, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The SSIDs of the block don't fit into the 32 bits of the draft wire format.
+	sender.nextSID = 1 << 32
+	receiver.highestBlockID = 1<<31 - 1
+	receiver.lowestBlockID = 1<<31 - 1
+
+	// roundtrip sends a frame in the draft wire format
+	roundtrip := func(f wire.Frame) wire.Frame {
+		b, err := f.Append(nil, protocol.Version1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, parsed, err := wire.NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	s1 := &wire.SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: sender.NextSSID(), Payload: []byte{1, 2, 3}}
+	if _, err := sender.AddSourceSymbolFrame(s1); err != nil {
+		t.Fatal(err)
+	}
+	s2 := &wire.SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: sender.NextSSID(), Payload: []byte{4, 5}}
+	repairs, err := sender.AddSourceSymbolFrame(s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repairs) != 1 || repairs[0].Metadata.BlockID != 1<<31 {
+		t.Fatalf("expected a repair symbol for block %#x, got %v", uint64(1<<31), repairs)
+	}
+	repairs[0].Format = protocol.FECWireFormatDraft
+
+	symbols, err := receiver.HandleSourceSymbol(roundtrip(s1).(*wire.SourceSymbolFrame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 1 || symbols[0].SSID != 1<<32 {
+		t.Fatalf("HandleSourceSymbol() = %v, want the source symbol %#x", symbols, uint64(1<<32))
+	}
+	recovered, err := receiver.HandleRepairSymbol(roundtrip(repairs[0]).(*wire.RepairFrame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 1 || recovered[0].SSID != 1<<32+1 || !bytes.Equal(recovered[0].Payload, []byte{4, 5}) {
+		t.Fatalf("HandleRepairSymbol() = %v, want the source symbol %#x", recovered, uint64(1<<32+1))
+	}
+}
@@ -0,0 +1,175 @@
+package fec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// geometry is the block geometry used for a protection class.
+type geometry struct {
+	numSourceSymbols int
+	// numRepairSymbols is the number of repair symbols sent for every block.
+	numRepairSymbols int
+	// maxNumRepairSymbols is the number of distinct repair symbols that can be generated for a block.
+	maxNumRepairSymbols int
+}
+
+// blockGeometry returns the block geometry of a protection class.
+// Higher protection classes use smaller blocks with the same number of repair symbols, resulting in a lower code rate.
+func blockGeometry(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (geometry, error) {
+	switch id {
+	case protocol.XORFECScheme:
+		// XOR only supports a single repair symbol, so the class only determines the number of source symbols.
+		switch class {
+		case protocol.FECProtectionDefault:
+			return geometry{numSourceSymbols: 2, numRepairSymbols: 1, maxNumRepairSymbols: 1}, nil
+		case protocol.FECProtectionHigh:
+			return geometry{numSourceSymbols: 1, numRepairSymbols: 1, maxNumRepairSymbols: 1}, nil
+		case protocol.FECProtectionLow:
+			return geometry{numSourceSymbols: 4, numRepairSymbols: 1, maxNumRepairSymbols: 1}, nil
+		}
+	case protocol.ReedSolomonFECScheme:
+		// The first numRepairSymbols parity shards don't depend on the total number of parity shards,
+		// so the additional repair symbols are compatible with the ones sent for every block.
+		switch class {
+		case protocol.FECProtectionDefault:
+			return geometry{numSourceSymbols: 20, numRepairSymbols: 10, maxNumRepairSymbols: 20}, nil
+		case protocol.FECProtectionHigh:
+			return geometry{numSourceSymbols: 10, numRepairSymbols: 10, maxNumRepairSymbols: 20}, nil
+		case protocol.FECProtectionLow:
+			return geometry{numSourceSymbols: 40, numRepairSymbols: 10, maxNumRepairSymbols: 20}, nil
+		}
+	case protocol.LargeBlockReedSolomonFECScheme:
+		// Large blocks absorb long bursts of losses, e.g. the outages during satellite handovers.
+		// Additional repair symbols are sent when symbols are lost, so a block can be recovered from a burst of up to maxNumRepairSymbols losses.
+		// maxNumRepairSymbols is a power of two, so the repair symbols sent for every block
+		// only require encoding half of the parity shards (see numParityShards).
+		switch class {
+		case protocol.FECProtectionDefault:
+			return geometry{numSourceSymbols: 2000, numRepairSymbols: 200, maxNumRepairSymbols: 512}, nil
+		case protocol.FECProtectionHigh:
+			return geometry{numSourceSymbols: 1000, numRepairSymbols: 200, maxNumRepairSymbols: 512}, nil
+		case protocol.FECProtectionLow:
+			return geometry{numSourceSymbols: 4000, numRepairSymbols: 200, maxNumRepairSymbols: 512}, nil
+		}
+	default:
+		return geometry{}, fmt.Errorf("unknown FEC scheme: %d", id)
+	}
+	return geometry{}, fmt.Errorf("unknown FEC protection class: %d", class)
+}
+
+// BlockGeometry returns the number of source symbols per block of a protection class,
+// and the number of repair symbols sent for every block.
+func BlockGeometry(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (numSourceSymbols, numRepairSymbols int, err error) {
+	g, err := blockGeometry(id, class)
+	if err != nil {
+		return 0, 0, err
+	}
+	return g.numSourceSymbols, g.numRepairSymbols, nil
+}
+
+func newClassManager(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (*manager, error) {
+	g, err := blockGeometry(id, class)
+	if err != nil {
+		return nil, err
+	}
+	var scheme BlockFECScheme
+	switch id {
+	case protocol.XORFECScheme:
+		scheme = &xorScheme{}
+	case protocol.ReedSolomonFECScheme:
+		enc, err := reedsolomon.New(g.numSourceSymbols, g.maxNumRepairSymbols)
+		if err != nil {
+			return nil, err
+		}
+		scheme = &reedSolomonScheme{enc: enc}
+	case protocol.LargeBlockReedSolomonFECScheme:
+		s, err := NewLargeBlockReedSolomonScheme(g.numSourceSymbols, g.maxNumRepairSymbols)
+		if err != nil {
+			return nil, err
+		}
+		scheme = s
+	}
+	m, err := newManager(scheme, g.numSourceSymbols, g.numRepairSymbols, g.maxNumRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	m.class = class
+	return m, nil
+}
+
+// NewSenderWithClass creates the sender for the source symbols of a protection class.
+func NewSenderWithClass(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (Sender, error) {
+	if id == protocol.FECDisabled {
+		return nil, nil
+	}
+	return newClassManager(id, class)
+}
+
+// NewReceiverWithClass creates the receiver for the symbols of a single protection class.
+func NewReceiverWithClass(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (Receiver, error) {
+	if id == protocol.FECDisabled {
+		return nil, nil
+	}
+	return newClassManager(id, class)
+}
+
+// classReceiver maintains a separate block sequence for every protection class.
+// The receiver of a class is created when the first symbol of that class is received.
+type classReceiver struct {
+	scheme protocol.DecoderFECScheme
+
+	mutex     sync.Mutex
+	receivers [protocol.MaxFECProtectionClass + 1]Receiver
+}
+
+var _ Receiver = &classReceiver{}
+
+// NewClassReceiver creates a receiver for the symbols of all protection classes.
+func NewClassReceiver(id protocol.DecoderFECScheme) (Receiver, error) {
+	if id == protocol.FECDisabled {
+		return nil, nil
+	}
+	// make sure that the scheme is valid
+	if _, err := blockGeometry(id, protocol.FECProtectionDefault); err != nil {
+		return nil, err
+	}
+	return &classReceiver{scheme: id}, nil
+}
+
+func (r *classReceiver) receiver(class protocol.FECProtectionClass) (Receiver, error) {
+	if class > protocol.MaxFECProtectionClass {
+		return nil, fmt.Errorf("invalid FEC protection class: %d", class)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.receivers[class] == nil {
+		rec, err := NewReceiverWithClass(r.scheme, class)
+		if err != nil {
+			return nil, err
+		}
+		r.receivers[class] = rec
+	}
+	return r.receivers[class], nil
+}
+
+func (r *classReceiver) HandleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
+	rec, err := r.receiver(f.Class)
+	if err != nil {
+		return nil, err
+	}
+	return rec.HandleRepairFrame(f)
+}
+
+func (r *classReceiver) HandleSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]byte, error) {
+	rec, err := r.receiver(f.Class)
+	if err != nil {
+		return nil, err
+	}
+	return rec.HandleSourceSymbolFrame(f)
+}
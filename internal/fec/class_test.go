@@ -0,0 +1,179 @@
+package fec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+func TestBlockGeometry(t *testing.T) {
+	tests := []struct {
+		scheme                             protocol.DecoderFECScheme
+		class                              protocol.FECProtectionClass
+		numSourceSymbols, numRepairSymbols int
+	}{
+		{protocol.XORFECScheme, protocol.FECProtectionHigh, 1, 1},
+		{protocol.XORFECScheme, protocol.FECProtectionDefault, 2, 1},
+		{protocol.XORFECScheme, protocol.FECProtectionLow, 4, 1},
+		{protocol.ReedSolomonFECScheme, protocol.FECProtectionHigh, 10, 20},
+		{protocol.ReedSolomonFECScheme, protocol.FECProtectionDefault, 20, 20},
+		{protocol.ReedSolomonFECScheme, protocol.FECProtectionLow, 40, 20},
+		{protocol.LargeBlockReedSolomonFECScheme, protocol.FECProtectionHigh, 1000, 512},
+		{protocol.LargeBlockReedSolomonFECScheme, protocol.FECProtectionDefault, 2000, 512},
+		{protocol.LargeBlockReedSolomonFECScheme, protocol.FECProtectionLow, 4000, 512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme.String()+"/"+tt.class.String(), func(t *testing.T) {
+			sender, err := NewSenderWithClass(tt.scheme, tt.class)
+			if err != nil {
+				t.Fatal(err)
+			}
+			numSourceSymbols, numRepairSymbols := sender.BlockSize()
+			if numSourceSymbols != tt.numSourceSymbols || numRepairSymbols != tt.numRepairSymbols {
+				t.Fatalf("BlockSize() = %d, %d, want %d, %d", numSourceSymbols, numRepairSymbols, tt.numSourceSymbols, tt.numRepairSymbols)
+			}
+			// The decoding window is sized for blocks with at most MaxFECRepairRatio repair symbols per source symbol.
+			if numRepairSymbols > protocol.MaxFECRepairRatio*numSourceSymbols {
+				t.Fatalf("block has more than %d repair symbols per source symbol", protocol.MaxFECRepairRatio)
+			}
+			if window := sender.(*manager).windowBlocks(); int(window) != max(protocol.MaxFECDecodingWindow/numSourceSymbols, 1) {
+				t.Fatalf("windowBlocks() = %d, want the advertised window", window)
+			}
+		})
+	}
+
+	if _, err := NewSenderWithClass(protocol.XORFECScheme, protocol.MaxFECProtectionClass+1); err == nil {
+		t.Fatal("expected an error for an unknown protection class")
+	}
+	if _, err := NewClassReceiver(42); err == nil {
+		t.Fatal("expected an error for an unknown FEC scheme")
+	}
+}
+
+func TestCodecWithClass(t *testing.T) {
+	codec, err := NewCodecWithClass(protocol.ReedSolomonFECScheme, protocol.FECProtectionHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numSourceSymbols, numRepairSymbols := codec.BlockSize()
+	if numSourceSymbols != 10 || numRepairSymbols != 20 {
+		t.Fatalf("BlockSize() = %d, %d, want 10, 20", numSourceSymbols, numRepairSymbols)
+	}
+	var payloads [][]byte
+	var repairs []*wire.RepairFrame
+	for i := 0; i < numSourceSymbols; i++ {
+		payload := make([]byte, 10, protocol.MaxPacketBufferSize)
+		for j := range payload {
+			payload[j] = byte(i)
+		}
+		payloads = append(payloads, payload)
+		r, err := codec.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: codec.NextSSID(), Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		repairs = append(repairs, r...)
+	}
+	if len(repairs) != 10 {
+		t.Fatalf("expected 10 repair symbols, got %d", len(repairs))
+	}
+
+	// The first two source symbols are lost.
+	receiver, err := NewCodecWithClass(protocol.ReedSolomonFECScheme, protocol.FECProtectionHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range payloads[2:] {
+		payload := make([]byte, len(p), protocol.MaxPacketBufferSize)
+		copy(payload, p)
+		symbols, err := receiver.HandleSourceSymbol(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i + 2), Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(symbols) != 1 || symbols[0].SSID != protocol.SourceSymbolID(i+2) {
+			t.Fatalf("expected source symbol %d to be passed up, got %v", i+2, symbols)
+		}
+	}
+	if recovered, err := receiver.HandleRepairSymbol(repairs[0]); err != nil || len(recovered) != 0 {
+		t.Fatalf("unexpected recovery: %v, %v", recovered, err)
+	}
+	recovered, err := receiver.HandleRepairSymbol(repairs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 2 || recovered[0].SSID != 0 || recovered[1].SSID != 1 ||
+		!bytes.Equal(recovered[0].Payload, payloads[0]) || !bytes.Equal(recovered[1].Payload, payloads[1]) {
+		t.Fatalf("unexpected recovered source symbols: %v", recovered)
+	}
+
+	if _, err := NewCodecWithClass(protocol.FECDisabled, protocol.FECProtectionDefault); err == nil {
+		t.Fatal("expected an error for a disabled FEC scheme")
+	}
+}
+
+func TestClassReceiver(t *testing.T) {
+	receiver, err := NewClassReceiver(protocol.ReedSolomonFECScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both classes use the same SSIDs and block IDs, but their blocks are separate.
+	type class struct {
+		class    protocol.FECProtectionClass
+		sender   Sender
+		payloads [][]byte
+		repairs  []*wire.RepairFrame
+	}
+	classes := []*class{{class: protocol.FECProtectionHigh}, {class: protocol.FECProtectionLow}}
+	for _, c := range classes {
+		c.sender, err = NewSenderWithClass(protocol.ReedSolomonFECScheme, c.class)
+		if err != nil {
+			t.Fatal(err)
+		}
+		numSourceSymbols, _ := c.sender.BlockSize()
+		for i := 0; i < numSourceSymbols; i++ {
+			payload := make([]byte, 10, protocol.MaxPacketBufferSize)
+			for j := range payload {
+				payload[j] = byte(int(c.class)*100 + i)
+			}
+			c.payloads = append(c.payloads, payload)
+			repairs, err := c.sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{Class: c.class, SSID: c.sender.NextSSID(), Payload: payload})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.repairs = append(c.repairs, repairs...)
+		}
+		if len(c.repairs) != 10 {
+			t.Fatalf("expected 10 repair symbols, got %d", len(c.repairs))
+		}
+		for _, r := range c.repairs {
+			if r.Class != c.class {
+				t.Fatalf("expected repair symbols of class %s, got %s", c.class, r.Class)
+			}
+		}
+	}
+
+	// The first source symbol of every class is lost.
+	for _, c := range classes {
+		for i, p := range c.payloads[1:] {
+			payload := make([]byte, len(p), protocol.MaxPacketBufferSize)
+			copy(payload, p)
+			if _, err := receiver.HandleSourceSymbolFrame(&wire.SourceSymbolFrame{Class: c.class, SSID: protocol.SourceSymbolID(i + 1), Payload: payload}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, c := range classes {
+		recovered, err := receiver.HandleRepairFrame(c.repairs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, c.payloads[0]) {
+			t.Fatalf("class %s: recovered %x, want %x", c.class, recovered, c.payloads[0])
+		}
+	}
+
+	if _, err := receiver.HandleRepairFrame(&wire.RepairFrame{Class: protocol.MaxFECProtectionClass + 1}); err == nil {
+		t.Fatal("expected an error for an unknown protection class")
+	}
+}
@@ -0,0 +1,56 @@
+package fec
+
+import (
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// Codec encodes and decodes source symbols with a custom block geometry.
+// Unlike the Receiver, it returns the individual source symbols, such that it can be used outside of a QUIC connection.
+type Codec interface {
+	Sender
+	// HandleSourceSymbol handles a source symbol, and returns the source symbols that are passed up to the application.
+	HandleSourceSymbol(f *wire.SourceSymbolFrame) ([]SourceSymbol, error)
+	// HandleRepairSymbol handles a repair symbol, and returns the source symbols that were recovered using it.
+	HandleRepairSymbol(f *wire.RepairFrame) ([]SourceSymbol, error)
+}
+
+var _ Codec = &manager{}
+
+// NewCodec creates a codec for blocks of numSourceSymbols source symbols, that are protected by numRepairSymbols repair symbols.
+func NewCodec(id protocol.DecoderFECScheme, numSourceSymbols, numRepairSymbols int) (Codec, error) {
+	if numSourceSymbols < 1 || numRepairSymbols < 1 {
+		return nil, fmt.Errorf("a block needs at least one source symbol and one repair symbol, got %d and %d", numSourceSymbols, numRepairSymbols)
+	}
+	var scheme BlockFECScheme
+	switch id {
+	case protocol.XORFECScheme:
+		if numRepairSymbols != 1 {
+			return nil, fmt.Errorf("xor only supports 1 repair symbol, got %d", numRepairSymbols)
+		}
+		scheme = &xorScheme{}
+	case protocol.ReedSolomonFECScheme:
+		s, err := NewReedSolomonScheme(numSourceSymbols, numRepairSymbols)
+		if err != nil {
+			return nil, err
+		}
+		scheme = s
+	case protocol.LargeBlockReedSolomonFECScheme:
+		s, err := NewLargeBlockReedSolomonScheme(numSourceSymbols, numRepairSymbols)
+		if err != nil {
+			return nil, err
+		}
+		scheme = s
+	default:
+		return nil, fmt.Errorf("unknown FEC scheme: %d", id)
+	}
+	return NewManager(scheme, numSourceSymbols, numRepairSymbols)
+}
+
+// NewCodecWithClass creates a codec using the block geometry of a protection class,
+// i.e. the geometry that connections use for the source symbols of that class.
+func NewCodecWithClass(id protocol.DecoderFECScheme, class protocol.FECProtectionClass) (Codec, error) {
+	return newClassManager(id, class)
+}
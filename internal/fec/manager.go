@@ -1,18 +1,32 @@
 package fec
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
-	"github.com/klauspost/reedsolomon"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/quicvarint"
 )
 
 // Sender represents sender-side functions.
 type Sender interface {
 	AddSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]*wire.RepairFrame, error)
 	NextSSID() protocol.SourceSymbolID
+	// PeekSSID returns the SSID that the next call to NextSSID will return.
+	PeekSSID() protocol.SourceSymbolID
+	// RepairSymbol generates the repair symbol with the given parity ID for a recently completed block.
+	// It returns false if the source symbols of the block are not kept anymore, or if the scheme doesn't support the parity ID.
+	RepairSymbol(id protocol.BlockID, parityID protocol.ParityID) (*wire.RepairFrame, bool)
+	// BlockSize returns the number of source symbols in a block,
+	// and the number of distinct repair symbols that can be generated for a block.
+	BlockSize() (numSourceSymbols, numRepairSymbols int)
+	// MaxSourceSymbolLen returns the maximum payload length of the next source symbol,
+	// such that its SOURCE_SYMBOL frame is not larger than maxFrameSize,
+	// and the REPAIR frames of its block are not larger than maxRepairFrameSize.
+	MaxSourceSymbolLen(maxFrameSize, maxRepairFrameSize protocol.ByteCount, format protocol.FECWireFormat, v protocol.Version) protocol.ByteCount
 }
 
 // Receiver represents receiver-side functions.
@@ -24,14 +38,11 @@ type Receiver interface {
 type Manager interface {
 	Sender
 	Receiver
-
-	// TODO (ddritzenhoff) implement at a later time.
-	// HandleSymbolAckFrame()
-	// HandleFECWindowFrame()
-	// UpdateWindowSize(newSize protocol.FECWindowSize, epoch protocol.FECWindowEpoch) error
-	// SetInitialCodingWindow(ws protocol.FECWindowSize)
 }
 
+// ErrSymbolOutsideWindow is returned when a source or repair symbol lies beyond the decoding window.
+var ErrSymbolOutsideWindow = errors.New("symbol outside of the decoding window")
+
 type blockStatus struct {
 	block *block
 	// isProcessed represents whether all the source symbols within the block have been passed up to the application.
@@ -39,75 +50,77 @@ type blockStatus struct {
 }
 
 type manager struct {
+	// class is the protection class of the blocks, it is set on all generated repair symbols.
+	class               protocol.FECProtectionClass
 	scheme              BlockFECScheme
 	nextSIDMutex        sync.Mutex
 	nextSID             protocol.SourceSymbolID
 	numTotSourceSymbols int
+	// numTotRepairSymbols is the number of repair symbols that are sent for every block.
 	numTotRepairSymbols int
+	// maxNumRepairSymbols is the number of distinct repair symbols that the scheme can generate for a block.
+	// Repair symbols beyond numTotRepairSymbols are only sent when source symbols are lost.
+	maxNumRepairSymbols int
 	blockStatuses       map[protocol.BlockID]blockStatus
+
+	// sentBlocks keeps the completed blocks on the sender side, such that additional repair symbols can be generated.
+	sentBlocks     map[protocol.BlockID]*block
+	sentBlockQueue ringbuffer.RingBuffer[protocol.BlockID]
+
+	// symbolLenBlockID is the block that maxSymbolLen was determined for.
+	symbolLenBlockID protocol.BlockID
+	// maxSymbolLen is the maximum length of the source symbols of the block that is currently being sent.
+	maxSymbolLen protocol.ByteCount
+	// maxRepairFrameSize is the maximum size of the REPAIR frames that maxSymbolLen was determined for.
+	maxRepairFrameSize protocol.ByteCount
+
+	// highestBlockID is the highest block ID that a source or repair symbol was received for.
+	highestBlockID protocol.BlockID
+	// lowestBlockID is the lowest block ID that might still be tracked in blockStatuses.
+	lowestBlockID protocol.BlockID
 }
 
+// NewSender creates the sender for the source symbols of the default protection class.
 func NewSender(id protocol.DecoderFECScheme) (Sender, error) {
-	switch id {
-	case protocol.FECDisabled:
-		return nil, nil
-	case protocol.XORFECScheme:
-		xorScheme := xorScheme{}
-		return NewManager(&xorScheme, 2, 1)
-	case protocol.ReedSolomonFECScheme:
-		numTotSourceSymbols := 20
-		numTotRepairSymbols := 10
-		reedSolomonEncoder, err := reedsolomon.New(numTotSourceSymbols, numTotRepairSymbols)
-		if err != nil {
-			return nil, err
-		}
-		reedSolomonScheme := reedSolomonScheme{
-			enc: reedSolomonEncoder,
-		}
-		return NewManager(&reedSolomonScheme, numTotSourceSymbols, numTotRepairSymbols)
-	default:
-		return nil, fmt.Errorf("unknown FEC scheme: %d", id)
-	}
+	return NewSenderWithClass(id, protocol.FECProtectionDefault)
 }
 
+// NewReceiver creates the receiver for the symbols of the default protection class.
 func NewReceiver(id protocol.DecoderFECScheme) (Receiver, error) {
-	switch id {
-	case protocol.FECDisabled:
-		return nil, nil
-	case protocol.XORFECScheme:
-		xorScheme := xorScheme{}
-		return NewManager(&xorScheme, 2, 1)
-	case protocol.ReedSolomonFECScheme:
-		numTotSourceSymbols := 20
-		numTotRepairSymbols := 10
-		reedSolomonEncoder, err := reedsolomon.New(numTotSourceSymbols, numTotRepairSymbols)
-		if err != nil {
-			return nil, err
-		}
-		reedSolomonScheme := reedSolomonScheme{
-			enc: reedSolomonEncoder,
-		}
-		return NewManager(&reedSolomonScheme, numTotSourceSymbols, numTotRepairSymbols)
-	default:
-		return nil, fmt.Errorf("unknown FEC scheme: %d", id)
-	}
+	return NewReceiverWithClass(id, protocol.FECProtectionDefault)
 }
 
 func NewManager(scheme BlockFECScheme, numTotSourceSymbols int, numTotRepairSymbols int) (*manager, error) {
+	return newManager(scheme, numTotSourceSymbols, numTotRepairSymbols, numTotRepairSymbols)
+}
+
+func newManager(scheme BlockFECScheme, numTotSourceSymbols, numTotRepairSymbols, maxNumRepairSymbols int) (*manager, error) {
 	if numTotSourceSymbols < 0 || numTotRepairSymbols < 0 {
 		return nil, fmt.Errorf("numTotSourceSymbols (%d) and numTotRepairSymbols (%d) may not be negative", numTotSourceSymbols, numTotRepairSymbols)
 	}
+	if maxNumRepairSymbols < numTotRepairSymbols {
+		return nil, fmt.Errorf("maxNumRepairSymbols (%d) may not be smaller than numTotRepairSymbols (%d)", maxNumRepairSymbols, numTotRepairSymbols)
+	}
+	if numTotSourceSymbols+maxNumRepairSymbols > protocol.MaxFECBlockSymbols {
+		return nil, fmt.Errorf("a block may not have more than %d symbols, got %d source and %d repair symbols", protocol.MaxFECBlockSymbols, numTotSourceSymbols, maxNumRepairSymbols)
+	}
 
 	return &manager{
 		nextSID:             0,
 		numTotSourceSymbols: numTotSourceSymbols,
 		numTotRepairSymbols: numTotRepairSymbols,
+		maxNumRepairSymbols: maxNumRepairSymbols,
 		scheme:              scheme,
 
 		blockStatuses: make(map[protocol.BlockID]blockStatus),
+		sentBlocks:    make(map[protocol.BlockID]*block),
 	}, nil
 }
 
+func (m *manager) BlockSize() (numSourceSymbols, numRepairSymbols int) {
+	return m.numTotSourceSymbols, m.maxNumRepairSymbols
+}
+
 func (m *manager) NextSSID() protocol.SourceSymbolID {
 	m.nextSIDMutex.Lock()
 	ret := m.nextSID
@@ -116,15 +129,114 @@ func (m *manager) NextSSID() protocol.SourceSymbolID {
 	return ret
 }
 
+func (m *manager) PeekSSID() protocol.SourceSymbolID {
+	m.nextSIDMutex.Lock()
+	defer m.nextSIDMutex.Unlock()
+	return m.nextSID
+}
+
+func (m *manager) MaxSourceSymbolLen(maxFrameSize, maxRepairFrameSize protocol.ByteCount, format protocol.FECWireFormat, v protocol.Version) protocol.ByteCount {
+	ssid := m.PeekSSID()
+
+	blockID := m.sidToBlockID(ssid)
+	// The maximum length of the source symbols is determined when the first source symbol of a block is sent.
+	// Otherwise, a block would contain source symbols of different sizes, and its repair symbols would grow to the largest one.
+	_, started := m.blockStatuses[blockID]
+	if started && m.symbolLenBlockID == blockID && maxRepairFrameSize != m.maxRepairFrameSize {
+		// The maximum packet size changed while the block was being sent.
+		// Its repair symbols might not fit into a packet anymore, so the block is closed, and a new block is started.
+		ssid = m.closeBlock(blockID)
+		blockID++
+		started = false
+	}
+	if !started || m.symbolLenBlockID != blockID {
+		// The REPAIR frame with the largest parity ID has the largest header.
+		rf := &wire.RepairFrame{
+			Format:   format,
+			Class:    m.class,
+			Metadata: protocol.BlockMetadata{BlockID: blockID, ParityID: protocol.ParityID(max(m.maxNumRepairSymbols-1, 0))},
+		}
+		maxRepairLen := rf.MaxPayloadLen(maxRepairFrameSize, v)
+		if s, ok := m.scheme.(paddingScheme); ok {
+			// The source symbol is padded, together with its length, to the next multiple of the shard size.
+			maxRepairLen -= maxRepairLen % protocol.ByteCount(s.shardSizeMultiple())
+		}
+		m.maxSymbolLen = min(max(maxRepairLen-protocol.RepairPayloadMetadataLen, 0), protocol.MaxFECPacketBufferSize)
+		m.symbolLenBlockID = blockID
+		m.maxRepairFrameSize = maxRepairFrameSize
+	}
+	sf := &wire.SourceSymbolFrame{Format: format, Class: m.class, SSID: ssid}
+	return min(m.maxSymbolLen, sf.MaxPayloadLen(maxFrameSize, v))
+}
+
+// closeBlock closes a block before all of its source symbols were sent.
+// No repair symbols are generated for the block, the frames of its lost source symbols are retransmitted.
+// It returns the first SSID of the next block.
+func (m *manager) closeBlock(id protocol.BlockID) protocol.SourceSymbolID {
+	delete(m.blockStatuses, id)
+	m.nextSIDMutex.Lock()
+	defer m.nextSIDMutex.Unlock()
+	m.nextSID = protocol.SourceSymbolID(uint64(id+1) * uint64(m.numTotSourceSymbols))
+	return m.nextSID
+}
+
+// draftIDEpoch is the number of distinct SSIDs and block IDs in the draft wire format, which only carries their lower 32 bits.
+const draftIDEpoch = 1 << 32
+
+// decodeDraftID reconstructs a SSID or block ID received in the draft wire format.
+// Similar to packet numbers, it returns the ID with the received lower 32 bits that is closest to the expected ID.
+func decodeDraftID(received, expected uint64) uint64 {
+	id := expected&^(draftIDEpoch-1) | received&(draftIDEpoch-1)
+	if id > expected && id-expected > draftIDEpoch/2 && id >= draftIDEpoch {
+		return id - draftIDEpoch
+	}
+	if id < expected && expected-id > draftIDEpoch/2 && id+draftIDEpoch <= quicvarint.Max {
+		return id + draftIDEpoch
+	}
+	return id
+}
+
 func (m *manager) sidToBlockID(sid protocol.SourceSymbolID) protocol.BlockID {
 	return protocol.BlockID(uint64(sid) / uint64(m.numTotSourceSymbols))
 }
 
+// blockMemory is the maximum number of bytes of the source and repair symbols of a block.
+func (m *manager) blockMemory() int {
+	return (m.numTotSourceSymbols + m.maxNumRepairSymbols) * protocol.MaxFECPacketBufferSize
+}
+
+// windowBlocks is the size of the decoding window, in blocks.
+// The blocks of the protection classes always fit into the decoder's memory,
+// but codecs with more repair symbols per source symbol get a smaller window.
+func (m *manager) windowBlocks() protocol.BlockID {
+	return protocol.BlockID(max(min(protocol.MaxFECDecodingWindow/m.numTotSourceSymbols, protocol.MaxFECDecoderMemory/m.blockMemory()), 1))
+}
+
+// checkWindow checks if a symbol of the given block lies within the decoding window.
+// It returns false if the block is too old to still be tracked.
+func (m *manager) checkWindow(blockID protocol.BlockID) (bool, error) {
+	if blockID > m.highestBlockID+m.windowBlocks() {
+		return false, fmt.Errorf("%w: block %d, highest received block %d", ErrSymbolOutsideWindow, blockID, m.highestBlockID)
+	}
+	if blockID < m.lowestBlockID {
+		return false, nil
+	}
+	if blockID > m.highestBlockID {
+		m.highestBlockID = blockID
+		// Advancing the window by n blocks evicts at most n blocks.
+		for m.highestBlockID-m.lowestBlockID > m.windowBlocks() {
+			delete(m.blockStatuses, m.lowestBlockID)
+			m.lowestBlockID++
+		}
+	}
+	return true, nil
+}
+
 func (m *manager) AddSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]*wire.RepairFrame, error) {
 	blockID := m.sidToBlockID(f.SSID)
 	if _, exists := m.blockStatuses[blockID]; !exists {
 		m.blockStatuses[blockID] = blockStatus{
-			block:       newBlock(blockID, m.numTotSourceSymbols, m.numTotRepairSymbols),
+			block:       newBlock(blockID, m.numTotSourceSymbols, m.maxNumRepairSymbols),
 			isProcessed: false}
 	}
 
@@ -142,27 +254,90 @@ func (m *manager) AddSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]*wire.Repai
 
 	// check if the block is complete, so we can generate repair frames.
 	if bS.block.isComplete() {
-		repairSymbols, err := m.scheme.repairSymbols(bS.block)
+		repairSymbols, err := m.repairSymbols(bS.block, m.numTotRepairSymbols)
 		if err != nil {
 			return nil, err
 		}
 
-		// drop the block as you don't need it anymore
-		bS.block = nil
-		bS.isProcessed = true
-		m.blockStatuses[blockID] = bS
+		// Keep the block, including its source symbols, such that additional repair symbols can be generated if symbols are lost.
+		// No more source symbols are added to a complete block, so its status isn't needed anymore.
+		m.keepSentBlock(bS.block)
+		delete(m.blockStatuses, blockID)
 		return repairSymbols, nil
 	}
 	m.blockStatuses[blockID] = bS
 	return nil, nil
 }
 
+func (m *manager) keepSentBlock(b *block) {
+	maxSentBlocks := max(protocol.MaxFECSenderMemory/m.blockMemory(), 1)
+	for m.sentBlockQueue.Len() >= maxSentBlocks {
+		delete(m.sentBlocks, m.sentBlockQueue.PopFront())
+	}
+	m.sentBlocks[b.id] = b
+	m.sentBlockQueue.PushBack(b.id)
+}
+
+// repairSymbols returns the first n repair symbols of a complete block, generating the ones that weren't generated yet.
+func (m *manager) repairSymbols(b *block, n int) ([]*wire.RepairFrame, error) {
+	if n > len(b.repairSymbols) {
+		repairSymbols, err := m.scheme.repairSymbols(b, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range repairSymbols[len(b.repairSymbols):] {
+			f.Class = m.class
+			b.repairSymbols = append(b.repairSymbols, f)
+		}
+	}
+	return b.repairSymbols[:n], nil
+}
+
+func (m *manager) RepairSymbol(id protocol.BlockID, parityID protocol.ParityID) (*wire.RepairFrame, bool) {
+	b, ok := m.sentBlocks[id]
+	if !ok || int(parityID) >= m.maxNumRepairSymbols {
+		return nil, false
+	}
+	if int(parityID) >= len(b.repairSymbols) {
+		// If one more repair symbol is needed, more are likely to follow,
+		// so at least as many repair symbols as were generated so far are added.
+		n := min(max(int(parityID)+1, 2*len(b.repairSymbols)), m.maxNumRepairSymbols)
+		if _, err := m.repairSymbols(b, n); err != nil {
+			return nil, false
+		}
+	}
+	f := *b.repairSymbols[parityID]
+	return &f, true
+}
+
 func (m *manager) HandleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
+	recovered, err := m.HandleRepairSymbol(f)
+	if err != nil {
+		return nil, err
+	}
+	return concatPayloads(recovered), nil
+}
+
+// HandleRepairSymbol handles a repair symbol, and returns the source symbols that were recovered using it.
+func (m *manager) HandleRepairSymbol(f *wire.RepairFrame) ([]SourceSymbol, error) {
+	if f.Format == protocol.FECWireFormatDraft {
+		rf := *f
+		rf.Metadata.BlockID = protocol.BlockID(decodeDraftID(uint64(f.Metadata.BlockID), uint64(m.highestBlockID)))
+		f = &rf
+	}
+	// The block must only protect source symbols with valid SSIDs.
+	if uint64(f.Metadata.BlockID) > (quicvarint.Max+1)/uint64(m.numTotSourceSymbols)-1 {
+		return nil, fmt.Errorf("invalid block ID: %d", f.Metadata.BlockID)
+	}
+	if tracked, err := m.checkWindow(f.Metadata.BlockID); err != nil || !tracked {
+		// the block is too old to be recovered, so we can ignore this repair symbol
+		return nil, err
+	}
 
 	// It's possible a repair frame arrives before any of its associated source symbol frames in the case they were dropped.
 	if _, exists := m.blockStatuses[f.Metadata.BlockID]; !exists {
 		m.blockStatuses[f.Metadata.BlockID] = blockStatus{
-			block:       newBlock(f.Metadata.BlockID, m.numTotSourceSymbols, m.numTotRepairSymbols),
+			block:       newBlock(f.Metadata.BlockID, m.numTotSourceSymbols, m.maxNumRepairSymbols),
 			isProcessed: false,
 		}
 	}
@@ -179,7 +354,7 @@ func (m *manager) HandleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
 	}
 
 	if bS.block.isRecoverable() {
-		sourceSymbolBytes, err := m.scheme.recoverSymbolPayloads(bS.block)
+		recovered, err := m.scheme.recoverSourceSymbols(bS.block)
 		if err != nil {
 			return nil, err
 		}
@@ -190,7 +365,7 @@ func (m *manager) HandleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
 		bS.isProcessed = true
 		m.blockStatuses[f.Metadata.BlockID] = bS
 
-		return sourceSymbolBytes, nil
+		return recovered, nil
 	}
 	// the block is still not recoverable, so we wait
 	m.blockStatuses[f.Metadata.BlockID] = bS
@@ -198,11 +373,39 @@ func (m *manager) HandleRepairFrame(f *wire.RepairFrame) ([]byte, error) {
 }
 
 func (m *manager) HandleSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]byte, error) {
+	symbols, err := m.HandleSourceSymbol(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) == 1 {
+		return symbols[0].Payload, nil
+	}
+	// The payload might be backed by the packet buffer, so it must not be appended to.
+	return concatPayloads(symbols), nil
+}
+
+// HandleSourceSymbol handles a source symbol, and returns the source symbols that are passed up to the application:
+// the source symbol itself, unless it was already recovered, followed by the source symbols that were recovered using it.
+func (m *manager) HandleSourceSymbol(f *wire.SourceSymbolFrame) ([]SourceSymbol, error) {
+	if f.Format == protocol.FECWireFormatDraft {
+		sf := *f
+		sf.SSID = protocol.SourceSymbolID(decodeDraftID(uint64(f.SSID), uint64(m.highestBlockID)*uint64(m.numTotSourceSymbols)))
+		f = &sf
+	}
 	blockID := m.sidToBlockID(f.SSID)
+	tracked, err := m.checkWindow(blockID)
+	if err != nil {
+		return nil, err
+	}
+	received := SourceSymbol{SSID: f.SSID, Payload: f.Payload}
+	if !tracked {
+		// the block is too old to be tracked, but the source symbol still needs to be passed up
+		return []SourceSymbol{received}, nil
+	}
 	if _, exists := m.blockStatuses[blockID]; !exists {
 		// create a new block if it doesn't exist
 		m.blockStatuses[blockID] = blockStatus{
-			block:       newBlock(blockID, m.numTotSourceSymbols, m.numTotRepairSymbols),
+			block:       newBlock(blockID, m.numTotSourceSymbols, m.maxNumRepairSymbols),
 			isProcessed: false,
 		}
 	}
@@ -213,17 +416,27 @@ func (m *manager) HandleSourceSymbolFrame(f *wire.SourceSymbolFrame) ([]byte, er
 		return nil, nil
 	}
 
-	err := bS.block.addSourceSymbol(f)
-	if err != nil {
+	if err := bS.block.addSourceSymbol(f); err != nil {
 		return nil, err
 	}
 
 	if bS.block.isComplete() {
 		bS.block = nil
 		bS.isProcessed = true
+	} else if len(bS.block.pidToRepairPayload) > 0 && bS.block.isRecoverable() {
+		// The repair symbols arrived before this source symbol.
+		// Recover the missing source symbols now, since no other symbol of the block might arrive.
+		recovered, err := m.scheme.recoverSourceSymbols(bS.block)
+		if err != nil {
+			return nil, err
+		}
+		bS.block = nil
+		bS.isProcessed = true
+		m.blockStatuses[blockID] = bS
+		return append([]SourceSymbol{received}, recovered...), nil
 	}
 	m.blockStatuses[blockID] = bS
-	return f.Payload, nil
+	return []SourceSymbol{received}, nil
 }
 
 // TODO (ddritzenhoff) repair symbols are always created here, which should make it possible to allocate a set of repair symbols using sync.pool and always fetch new ones from there.
@@ -10,6 +10,10 @@ import (
 
 type reedSolomonScheme struct {
 	enc reedsolomon.Encoder
+	// prefixEncoders generate the first repair symbols of a block, keyed by the number of repair symbols.
+	// The parity shards of a Reed-Solomon code don't depend on the total number of parity shards,
+	// so they are the same as the first repair symbols generated by enc.
+	prefixEncoders map[int]reedsolomon.Encoder
 }
 
 func NewReedSolomonScheme(numTotSourceSymbols int, numTotRepairSymbols int) (*reedSolomonScheme, error) {
@@ -22,17 +26,43 @@ func NewReedSolomonScheme(numTotSourceSymbols int, numTotRepairSymbols int) (*re
 	}, nil
 }
 
+// encoder returns an encoder that generates the first numRepairSymbols repair symbols of a block.
+func (s *reedSolomonScheme) encoder(b *block, numRepairSymbols int) (reedsolomon.Encoder, error) {
+	if numRepairSymbols == b.totNumRepairSymbols {
+		return s.enc, nil
+	}
+	if enc, ok := s.prefixEncoders[numRepairSymbols]; ok {
+		return enc, nil
+	}
+	enc, err := reedsolomon.New(b.totNumSourceSymbols, numRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	if s.prefixEncoders == nil {
+		s.prefixEncoders = make(map[int]reedsolomon.Encoder)
+	}
+	s.prefixEncoders[numRepairSymbols] = enc
+	return enc, nil
+}
+
 // repairSymbols generates repair symbols for the block. An error is returned if the block is not full with source symbols.
-func (s *reedSolomonScheme) repairSymbols(b *block) ([]*wire.RepairFrame, error) {
+func (s *reedSolomonScheme) repairSymbols(b *block, numRepairSymbols int) ([]*wire.RepairFrame, error) {
 	if !b.isComplete() {
 		return nil, fmt.Errorf("block does not have enough source symbols to generate repair symbols")
 	}
+	if numRepairSymbols < 1 || numRepairSymbols > b.totNumRepairSymbols {
+		return nil, fmt.Errorf("invalid number of repair symbols: %d (maximum %d)", numRepairSymbols, b.totNumRepairSymbols)
+	}
 
 	if b.biggestSourceSymbolLenSoFar > protocol.MaxFECPacketBufferSize {
 		return nil, fmt.Errorf("source symbol payload len is greater is too big for FEC headers. Max %d and got %d", protocol.MaxFECPacketBufferSize, b.biggestSourceSymbolLenSoFar)
 	}
 
-	shards := make([][]byte, b.totNumSourceSymbols+b.totNumRepairSymbols)
+	enc, err := s.encoder(b, numRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	shards := make([][]byte, b.totNumSourceSymbols+numRepairSymbols)
 	for i := 0; i < b.totNumSourceSymbols; i++ {
 		shardPayload, err := s.addLengthToSourceSymbolPayload(b, b.smallestSSID+protocol.SourceSymbolID(i))
 		if err != nil {
@@ -41,19 +71,18 @@ func (s *reedSolomonScheme) repairSymbols(b *block) ([]*wire.RepairFrame, error)
 		shards[i] = shardPayload
 	}
 
-	for i := 0; i < b.totNumRepairSymbols; i++ {
+	for i := 0; i < numRepairSymbols; i++ {
 		// TODO: you may not need the protocol.MaxPacketBufferSize capacity on the sending side (which this is).
 		repairShard := make([]byte, 0, protocol.MaxPacketBufferSize)
 		repairShard = repairShard[:protocol.RepairPayloadMetadataLen+b.biggestSourceSymbolLenSoFar]
 		shards[i+b.totNumSourceSymbols] = repairShard
 	}
 
-	err := s.enc.Encode(shards)
-	if err != nil {
+	if err := enc.Encode(shards); err != nil {
 		return nil, fmt.Errorf("unable to make parity shards: %w", err)
 	}
 
-	repairSymbols := make([]*wire.RepairFrame, b.totNumRepairSymbols)
+	repairSymbols := make([]*wire.RepairFrame, numRepairSymbols)
 	for i := range repairSymbols {
 		repairSymbols[i] = &wire.RepairFrame{
 			Metadata: protocol.BlockMetadata{
@@ -88,8 +117,17 @@ func (s *reedSolomonScheme) addLengthToSourceSymbolPayload(b *block, ssid protoc
 	return shardPayload, nil
 }
 
-// recoverSymbols reconstructs the missing source symbols of the block and returns them as a slice. An error is returned if there aren't enough present symbols to repair the missing ones.
+// recoverSymbolPayloads reconstructs the missing source symbols of the block and returns their concatenated payloads. An error is returned if there aren't enough present symbols to repair the missing ones.
 func (s *reedSolomonScheme) recoverSymbolPayloads(b *block) ([]byte, error) {
+	symbols, err := s.recoverSourceSymbols(b)
+	if err != nil {
+		return nil, err
+	}
+	return concatPayloads(symbols), nil
+}
+
+// recoverSourceSymbols reconstructs the missing source symbols of the block. An error is returned if there aren't enough present symbols to repair the missing ones.
+func (s *reedSolomonScheme) recoverSourceSymbols(b *block) ([]SourceSymbol, error) {
 	if !b.isRecoverable() {
 		return nil, fmt.Errorf("not enough present symbols to repair the missing ones")
 	}
@@ -125,12 +163,18 @@ func (s *reedSolomonScheme) recoverSymbolPayloads(b *block) ([]byte, error) {
 		return nil, err
 	}
 
-	recoveredSymbolPayloads := make([]byte, 0, numMissingSourceSymbols*b.biggestSourceSymbolLenSoFar)
+	recovered := make([]SourceSymbol, 0, numMissingSourceSymbols)
 	for _, i := range missingSourceShardIndices {
 		missingSourceShard := shards[i]
 		payloadLen := uint16(missingSourceShard[b.biggestSourceSymbolLenSoFar])<<8 | uint16(missingSourceShard[b.biggestSourceSymbolLenSoFar+1])
-		recoveredSymbolPayloads = append(recoveredSymbolPayloads, missingSourceShard[:payloadLen]...)
+		if int(payloadLen) > b.biggestSourceSymbolLenSoFar {
+			return nil, fmt.Errorf("recovered source symbol length (%d) is larger than the repair symbols (%d)", payloadLen, b.biggestSourceSymbolLenSoFar)
+		}
+		recovered = append(recovered, SourceSymbol{
+			SSID:    b.smallestSSID + protocol.SourceSymbolID(i),
+			Payload: missingSourceShard[:payloadLen],
+		})
 	}
 
-	return recoveredSymbolPayloads, nil
+	return recovered, nil
 }
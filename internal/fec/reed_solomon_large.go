@@ -0,0 +1,238 @@
+package fec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// largeBlockShardSizeMultiple is the size that the Leopard GF(2^16) codec requires all shards to be a multiple of.
+const largeBlockShardSizeMultiple = 64
+
+// MaxLargeBlockSourceSymbolLen is the maximum length of a source symbol of the large-block Reed-Solomon scheme,
+// such that the padded repair symbols are not larger than the repair symbols of the other schemes.
+const MaxLargeBlockSourceSymbolLen = (protocol.MaxFECPacketBufferSize+protocol.RepairPayloadMetadataLen)/largeBlockShardSizeMultiple*largeBlockShardSizeMultiple - protocol.RepairPayloadMetadataLen
+
+// maxCachedLargeBlockEncoders is the number of block geometries that encoders are cached for.
+const maxCachedLargeBlockEncoders = 16
+
+type largeBlockGeometry struct {
+	numSourceSymbols, numRepairSymbols int
+}
+
+// largeBlockEncoders caches the encoders by block geometry.
+// The encoders are safe for concurrent use, so all connections using the same block geometry share an encoder,
+// and with it the buffers used to decode blocks.
+// The memory used to decode a block therefore depends on the number of blocks being decoded concurrently,
+// not on the number of connections.
+var largeBlockEncoders struct {
+	mutex    sync.Mutex
+	encoders map[largeBlockGeometry]reedsolomon.Encoder
+}
+
+func largeBlockEncoder(numSourceSymbols, numRepairSymbols int) (reedsolomon.Encoder, error) {
+	g := largeBlockGeometry{numSourceSymbols: numSourceSymbols, numRepairSymbols: numRepairSymbols}
+	largeBlockEncoders.mutex.Lock()
+	defer largeBlockEncoders.mutex.Unlock()
+
+	if enc, ok := largeBlockEncoders.encoders[g]; ok {
+		return enc, nil
+	}
+	enc, err := reedsolomon.New(numSourceSymbols, numRepairSymbols, reedsolomon.WithLeopardGF16(true))
+	if err != nil {
+		return nil, err
+	}
+	if largeBlockEncoders.encoders == nil {
+		largeBlockEncoders.encoders = make(map[largeBlockGeometry]reedsolomon.Encoder)
+	}
+	// Custom geometries can be used by codecs, so the cache must not grow indefinitely.
+	if len(largeBlockEncoders.encoders) < maxCachedLargeBlockEncoders {
+		largeBlockEncoders.encoders[g] = enc
+	}
+	return enc, nil
+}
+
+// largeBlockReedSolomonScheme is a Reed-Solomon scheme over GF(2^16), using the Leopard codec of klauspost/reedsolomon.
+// Unlike the reedSolomonScheme, which is limited to 256 symbols per block, it supports blocks of thousands of symbols.
+// Decoding a block takes O(n log n) time in the number of symbols n, and doesn't need a decoding matrix.
+//
+// The codec encodes 64 byte chunks of the symbols, so the source symbols of a block, including their length,
+// are padded to the next multiple of 64 bytes. The length is encoded in the last 2 bytes of the padded source symbols,
+// such that the repair symbols end with the length, just like the repair symbols of the other schemes.
+type largeBlockReedSolomonScheme struct {
+	enc reedsolomon.Encoder
+}
+
+var _ paddingScheme = &largeBlockReedSolomonScheme{}
+
+func NewLargeBlockReedSolomonScheme(numTotSourceSymbols int, numTotRepairSymbols int) (*largeBlockReedSolomonScheme, error) {
+	enc, err := largeBlockEncoder(numTotSourceSymbols, numTotRepairSymbols)
+	if err != nil {
+		return nil, err
+	}
+	return &largeBlockReedSolomonScheme{enc: enc}, nil
+}
+
+// numParityShards is the number of parity shards encoded to generate the first numRepairSymbols repair symbols of a block.
+// The Leopard codec computes the parity shards over the next power of two of the number of parity shards,
+// so the first parity shards only match the ones generated for the whole block
+// if the number of parity shards rounds up to the same power of two.
+func numParityShards(numRepairSymbols, totNumRepairSymbols int) int {
+	return max(numRepairSymbols, ceilPow2(totNumRepairSymbols)/2+1)
+}
+
+func ceilPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// encoder returns an encoder that generates the first numParityShards repair symbols of a block.
+func (s *largeBlockReedSolomonScheme) encoder(b *block, numParityShards int) (reedsolomon.Encoder, error) {
+	if numParityShards == b.totNumRepairSymbols {
+		return s.enc, nil
+	}
+	return largeBlockEncoder(b.totNumSourceSymbols, numParityShards)
+}
+
+func (s *largeBlockReedSolomonScheme) shardSizeMultiple() int {
+	return largeBlockShardSizeMultiple
+}
+
+// shardLen is the length of the padded source and repair symbols of the block.
+func (s *largeBlockReedSolomonScheme) shardLen(b *block) int {
+	l := b.biggestSourceSymbolLenSoFar + protocol.RepairPayloadMetadataLen
+	return (l + largeBlockShardSizeMultiple - 1) / largeBlockShardSizeMultiple * largeBlockShardSizeMultiple
+}
+
+// repairSymbols generates repair symbols for the block. An error is returned if the block is not full with source symbols.
+func (s *largeBlockReedSolomonScheme) repairSymbols(b *block, numRepairSymbols int) ([]*wire.RepairFrame, error) {
+	if !b.isComplete() {
+		return nil, fmt.Errorf("block does not have enough source symbols to generate repair symbols")
+	}
+	if numRepairSymbols < 1 || numRepairSymbols > b.totNumRepairSymbols {
+		return nil, fmt.Errorf("invalid number of repair symbols: %d (maximum %d)", numRepairSymbols, b.totNumRepairSymbols)
+	}
+	if b.biggestSourceSymbolLenSoFar > MaxLargeBlockSourceSymbolLen {
+		return nil, fmt.Errorf("source symbol payload len is too big for FEC headers. Max %d and got %d", MaxLargeBlockSourceSymbolLen, b.biggestSourceSymbolLenSoFar)
+	}
+
+	// Only the parity shards needed for the requested repair symbols are encoded.
+	numParityShards := numParityShards(numRepairSymbols, b.totNumRepairSymbols)
+	enc, err := s.encoder(b, numParityShards)
+	if err != nil {
+		return nil, err
+	}
+	shardLen := s.shardLen(b)
+	shards := make([][]byte, b.totNumSourceSymbols+numParityShards)
+	// The repair symbols are kept by the sender, so they don't share a buffer with the padded source symbols.
+	sourceBuf := make([]byte, b.totNumSourceSymbols*shardLen)
+	repairBuf := make([]byte, numParityShards*shardLen)
+	for i := range shards {
+		buf, j := sourceBuf, i
+		if i >= b.totNumSourceSymbols {
+			buf, j = repairBuf, i-b.totNumSourceSymbols
+		}
+		shards[i] = buf[j*shardLen : (j+1)*shardLen : (j+1)*shardLen]
+	}
+	for i := 0; i < b.totNumSourceSymbols; i++ {
+		if err := s.padSourceSymbol(b, b.smallestSSID+protocol.SourceSymbolID(i), shards[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Encode(shards); err != nil {
+		return nil, fmt.Errorf("unable to make parity shards: %w", err)
+	}
+
+	repairSymbols := make([]*wire.RepairFrame, numRepairSymbols)
+	for i := range repairSymbols {
+		repairSymbols[i] = &wire.RepairFrame{
+			Metadata: protocol.BlockMetadata{
+				BlockID:  b.id,
+				ParityID: protocol.ParityID(i),
+			},
+			Payload: shards[b.totNumSourceSymbols+i],
+		}
+	}
+	return repairSymbols, nil
+}
+
+// padSourceSymbol copies a source symbol into its shard, and encodes its length in the last 2 bytes of the shard.
+func (s *largeBlockReedSolomonScheme) padSourceSymbol(b *block, ssid protocol.SourceSymbolID, shard []byte) error {
+	payload, exists := b.ssidToSourcePayload[ssid]
+	if !exists {
+		// this should never happen
+		return fmt.Errorf("block [%d, %d] is complete but SID %d does not exist", b.smallestSSID, b.largestSSID, ssid)
+	}
+	if len(payload) > len(shard)-protocol.RepairPayloadMetadataLen {
+		// this should never happen
+		return fmt.Errorf("source symbol (%d bytes) doesn't fit into the shard (%d bytes)", len(payload), len(shard))
+	}
+	n := copy(shard, payload)
+	clear(shard[n:])
+	shard[len(shard)-2] = byte(len(payload) >> 8)
+	shard[len(shard)-1] = byte(len(payload))
+	return nil
+}
+
+// recoverSourceSymbols reconstructs the missing source symbols of the block. An error is returned if there aren't enough present symbols to repair the missing ones.
+func (s *largeBlockReedSolomonScheme) recoverSourceSymbols(b *block) ([]SourceSymbol, error) {
+	if !b.isRecoverable() {
+		return nil, fmt.Errorf("not enough present symbols to repair the missing ones")
+	}
+	if b.isComplete() {
+		// The block is complete, so there's nothing to be recovered
+		return nil, nil
+	}
+
+	// The source symbols are only recovered using repair symbols, which have the padded length.
+	shardLen := s.shardLen(b)
+	if shardLen != b.biggestSourceSymbolLenSoFar+protocol.RepairPayloadMetadataLen {
+		return nil, fmt.Errorf("repair symbol length (%d) is not a multiple of %d", b.biggestSourceSymbolLenSoFar+protocol.RepairPayloadMetadataLen, largeBlockShardSizeMultiple)
+	}
+
+	shards := make([][]byte, b.totNumSourceSymbols+b.totNumRepairSymbols)
+	numMissingSourceSymbols := b.totNumSourceSymbols - len(b.ssidToSourcePayload)
+	missingSourceShardIndices := make([]int, 0, numMissingSourceSymbols)
+	// The received source symbols might be backed by the packet buffer, so they are copied instead of padded in place.
+	buf := make([]byte, len(b.ssidToSourcePayload)*shardLen)
+	for i := 0; i < b.totNumSourceSymbols; i++ {
+		ssid := b.smallestSSID + protocol.SourceSymbolID(i)
+		if _, exists := b.ssidToSourcePayload[ssid]; !exists {
+			missingSourceShardIndices = append(missingSourceShardIndices, i)
+			continue
+		}
+		shard := buf[:shardLen:shardLen]
+		buf = buf[shardLen:]
+		if err := s.padSourceSymbol(b, ssid, shard); err != nil {
+			return nil, err
+		}
+		shards[i] = shard
+	}
+	for parityID, payload := range b.pidToRepairPayload {
+		shards[b.totNumSourceSymbols+int(parityID)] = payload
+	}
+
+	if err := s.enc.ReconstructData(shards); err != nil {
+		return nil, err
+	}
+
+	recovered := make([]SourceSymbol, 0, numMissingSourceSymbols)
+	for _, i := range missingSourceShardIndices {
+		shard := shards[i]
+		payloadLen := int(shard[shardLen-2])<<8 | int(shard[shardLen-1])
+		if payloadLen > shardLen-protocol.RepairPayloadMetadataLen {
+			return nil, fmt.Errorf("recovered source symbol length (%d) is larger than the repair symbols (%d)", payloadLen, shardLen-protocol.RepairPayloadMetadataLen)
+		}
+		recovered = append(recovered, SourceSymbol{
+			SSID:    b.smallestSSID + protocol.SourceSymbolID(i),
+			Payload: shard[:payloadLen],
+		})
+	}
+	return recovered, nil
+}
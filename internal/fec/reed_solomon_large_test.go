@@ -0,0 +1,208 @@
+package fec
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+func TestLargeBlockReedSolomonScheme_Recovery(t *testing.T) {
+	const numSourceSymbols, numRepairSymbols = 2000, 600
+	sender, err := NewCodec(protocol.LargeBlockReedSolomonFECScheme, numSourceSymbols, numRepairSymbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewCodec(protocol.LargeBlockReedSolomonFECScheme, numSourceSymbols, numRepairSymbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(42))
+	payloads := make([][]byte, 2*numSourceSymbols)
+	var repairs []*wire.RepairFrame
+	for i := range payloads {
+		payloads[i] = make([]byte, 1+r.Intn(1000))
+		r.Read(payloads[i])
+		rf, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: sender.NextSSID(), Payload: payloads[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		repairs = append(repairs, rf...)
+	}
+	if len(repairs) != 2*numRepairSymbols {
+		t.Fatalf("expected %d repair symbols, got %d", 2*numRepairSymbols, len(repairs))
+	}
+	for _, rf := range repairs {
+		if len(rf.Payload)%largeBlockShardSizeMultiple != 0 {
+			t.Fatalf("repair symbol length (%d) is not a multiple of %d", len(rf.Payload), largeBlockShardSizeMultiple)
+		}
+	}
+
+	// The second block loses a burst of as many source symbols as there are repair symbols.
+	for i, p := range payloads {
+		if i >= numSourceSymbols+500 && i < numSourceSymbols+500+numRepairSymbols {
+			continue
+		}
+		payload := make([]byte, len(p))
+		copy(payload, p)
+		if _, err := receiver.HandleSourceSymbol(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i), Payload: payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var recovered []SourceSymbol
+	for _, rf := range repairs {
+		// The repair symbols are sent in the draft wire format, which moves the length in front of the repair symbol.
+		rf.Format = protocol.FECWireFormatDraft
+		b, err := rf.Append(nil, protocol.Version1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, parsed, err := wire.NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.(*wire.RepairFrame).Metadata != rf.Metadata {
+			t.Fatalf("parsed FEC payload ID %+v, want %+v", parsed.(*wire.RepairFrame).Metadata, rf.Metadata)
+		}
+		symbols, err := receiver.HandleRepairSymbol(parsed.(*wire.RepairFrame))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered = append(recovered, symbols...)
+	}
+	if len(recovered) != numRepairSymbols {
+		t.Fatalf("expected %d recovered source symbols, got %d", numRepairSymbols, len(recovered))
+	}
+	for i, s := range recovered {
+		ssid := protocol.SourceSymbolID(numSourceSymbols + 500 + i)
+		if s.SSID != ssid || !bytes.Equal(s.Payload, payloads[ssid]) {
+			t.Fatalf("recovered source symbol %d doesn't match source symbol %d", s.SSID, ssid)
+		}
+	}
+}
+
+func TestLargeBlockReedSolomonScheme_RepairSymbolsOnDemand(t *testing.T) {
+	tests := []struct {
+		numSourceSymbols, numRepairSymbols int
+		requested                          []int
+	}{
+		{numSourceSymbols: 2000, numRepairSymbols: 512, requested: []int{1, 200, 257, 300}},
+		{numSourceSymbols: 1000, numRepairSymbols: 600, requested: []int{200, 513, 599}},
+		{numSourceSymbols: 10, numRepairSymbols: 3, requested: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		s, err := NewLargeBlockReedSolomonScheme(tt.numSourceSymbols, tt.numRepairSymbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := newBlock(0, tt.numSourceSymbols, tt.numRepairSymbols)
+		r := rand.New(rand.NewSource(42))
+		for i := 0; i < tt.numSourceSymbols; i++ {
+			payload := make([]byte, 1+r.Intn(100))
+			r.Read(payload)
+			if err := b.addSourceSymbol(&wire.SourceSymbolFrame{SSID: protocol.SourceSymbolID(i), Payload: payload}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		all, err := s.repairSymbols(b, tt.numRepairSymbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range tt.requested {
+			repairSymbols, err := s.repairSymbols(b, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repairSymbols) != n {
+				t.Fatalf("expected %d repair symbols, got %d", n, len(repairSymbols))
+			}
+			for i, rf := range repairSymbols {
+				if !bytes.Equal(rf.Payload, all[i].Payload) {
+					t.Fatalf("%d/%d: repair symbol %d of the first %d doesn't match the one generated for the whole block", tt.numSourceSymbols, tt.numRepairSymbols, i, n)
+				}
+			}
+		}
+	}
+	if n := numParityShards(200, 512); n != 257 {
+		t.Fatalf("expected 257 parity shards to be encoded, got %d", n)
+	}
+}
+
+func TestLargeBlockReedSolomonScheme_InvalidRepairSymbolLength(t *testing.T) {
+	receiver, err := NewCodec(protocol.LargeBlockReedSolomonFECScheme, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receiver.HandleSourceSymbol(&wire.SourceSymbolFrame{SSID: 0, Payload: []byte{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receiver.HandleRepairSymbol(&wire.RepairFrame{Payload: make([]byte, 63)}); err == nil {
+		t.Fatal("expected an error for a repair symbol that is not a multiple of 64 bytes")
+	}
+}
+
+func TestLargeBlockReedSolomonScheme_MaxSourceSymbolLen(t *testing.T) {
+	for _, format := range []protocol.FECWireFormat{protocol.FECWireFormatLegacy, protocol.FECWireFormatDraft} {
+		t.Run(format.String(), func(t *testing.T) {
+			sender, err := NewSender(protocol.LargeBlockReedSolomonFECScheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			numSourceSymbols, numRepairSymbols := sender.BlockSize()
+			symbolLen := sender.MaxSourceSymbolLen(1200, 1200, format, protocol.Version1)
+			if symbolLen+protocol.RepairPayloadMetadataLen != 1152 {
+				t.Fatalf("expected the padded source symbols to be 1152 bytes, got %d", symbolLen+protocol.RepairPayloadMetadataLen)
+			}
+			for i := 0; i < numSourceSymbols; i++ {
+				if _, err := sender.AddSourceSymbolFrame(&wire.SourceSymbolFrame{SSID: sender.NextSSID(), Payload: make([]byte, symbolLen)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// the repair symbol with the largest parity ID has the largest header
+			f, ok := sender.RepairSymbol(0, protocol.ParityID(numRepairSymbols-1))
+			if !ok {
+				t.Fatal("expected the repair symbol to be kept")
+			}
+			f.Format = format
+			if l := f.Length(protocol.Version1); l > 1200 {
+				t.Fatalf("REPAIR frame too large: %d bytes", l)
+			}
+		})
+	}
+	if sizeLimit := MaxLargeBlockSourceSymbolLen + protocol.RepairPayloadMetadataLen; sizeLimit%largeBlockShardSizeMultiple != 0 || sizeLimit > protocol.MaxFECPacketBufferSize+protocol.RepairPayloadMetadataLen {
+		t.Fatalf("invalid maximum source symbol length: %d", MaxLargeBlockSourceSymbolLen)
+	}
+}
+
+func TestLargeBlockReedSolomonScheme_EncoderCache(t *testing.T) {
+	s1, err := NewLargeBlockReedSolomonScheme(1000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := NewLargeBlockReedSolomonScheme(1000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1.enc != s2.enc {
+		t.Fatal("expected schemes with the same geometry to share the encoder")
+	}
+	s3, err := NewLargeBlockReedSolomonScheme(1000, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1.enc == s3.enc {
+		t.Fatal("expected schemes with different geometries to use different encoders")
+	}
+}
+
+func TestManager_MaxBlockSymbols(t *testing.T) {
+	if _, err := NewCodec(protocol.LargeBlockReedSolomonFECScheme, protocol.MaxFECBlockSymbols-100, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCodec(protocol.LargeBlockReedSolomonFECScheme, protocol.MaxFECBlockSymbols-100, 101); err == nil {
+		t.Fatalf("expected an error for a block of more than %d symbols", protocol.MaxFECBlockSymbols)
+	}
+}
@@ -203,7 +203,7 @@ func TestReedSolomonScheme_repairSymbols(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.scheme.repairSymbols(tt.block)
+			got, err := tt.scheme.repairSymbols(tt.block, tt.block.totNumRepairSymbols)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("repairSymbols() error = %v, wantErr %v", err, tt.wantErr)
 				return
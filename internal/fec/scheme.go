@@ -1,10 +1,43 @@
 package fec
 
-import "github.com/quic-go/quic-go/internal/wire"
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
 
 type BlockFECScheme interface {
-	// repairSymbols generates repair symbols for the block. An error is returned if the block is not complete.
-	repairSymbols(b *block) ([]*wire.RepairFrame, error)
-	// recoverSymbols reconstructs the missing source symbols of the block and returns them as a slice. An error is returned if there aren't enough present symbols to repair the missing ones.
-	recoverSymbolPayloads(b *block) ([]byte, error)
+	// repairSymbols generates the first numRepairSymbols repair symbols of the block. An error is returned if the block is not complete.
+	repairSymbols(b *block, numRepairSymbols int) ([]*wire.RepairFrame, error)
+	// recoverSourceSymbols reconstructs the missing source symbols of the block, ordered by their SSID. An error is returned if there aren't enough present symbols to repair the missing ones.
+	recoverSourceSymbols(b *block) ([]SourceSymbol, error)
+}
+
+// A paddingScheme is a BlockFECScheme that pads the source symbols of a block, including their length,
+// to a multiple of a fixed shard size. Its repair symbols have the padded length.
+type paddingScheme interface {
+	BlockFECScheme
+	shardSizeMultiple() int
+}
+
+// A SourceSymbol is a source symbol that is passed up to the application, either because it was received or because it was recovered.
+type SourceSymbol struct {
+	SSID    protocol.SourceSymbolID
+	Payload []byte
+}
+
+// concatPayloads concatenates the payloads of the source symbols.
+// The frames in the payload of a SOURCE_SYMBOL frame are self-delimiting, so they can be parsed from the concatenated payloads.
+func concatPayloads(symbols []SourceSymbol) []byte {
+	if symbols == nil {
+		return nil
+	}
+	var l int
+	for _, s := range symbols {
+		l += len(s.Payload)
+	}
+	payloads := make([]byte, 0, l)
+	for _, s := range symbols {
+		payloads = append(payloads, s.Payload...)
+	}
+	return payloads
 }
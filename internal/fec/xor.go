@@ -11,13 +11,13 @@ type xorScheme struct {
 }
 
 // repairSymbols generates repair symbols for the block. An error is returned if the block is not full with source symbols.
-func (s *xorScheme) repairSymbols(b *block) ([]*wire.RepairFrame, error) {
+func (s *xorScheme) repairSymbols(b *block, numRepairSymbols int) ([]*wire.RepairFrame, error) {
 	if !b.isComplete() {
 		return nil, fmt.Errorf("block does not have enough source symbols to generate repair symbols")
 	}
 
-	if b.totNumRepairSymbols != 1 {
-		return nil, fmt.Errorf("xor only supports 1 repair symbol. Expected 1, received %d", b.totNumRepairSymbols)
+	if b.totNumRepairSymbols != 1 || numRepairSymbols != 1 {
+		return nil, fmt.Errorf("xor only supports 1 repair symbol. Expected 1, received %d", max(b.totNumRepairSymbols, numRepairSymbols))
 	}
 
 	if b.biggestSourceSymbolLenSoFar > protocol.MaxFECPacketBufferSize {
@@ -62,8 +62,17 @@ func (s *xorScheme) xorRepair(xorSoFar []byte, payload []byte) []byte {
 	return xorSoFar
 }
 
-// recoverSymbols reconstructs the missing source symbols of the block and returns them as a slice. An error is returned if there aren't enough present symbols to repair the missing ones.
+// recoverSymbolPayloads reconstructs the missing source symbols of the block and returns their concatenated payloads. An error is returned if there aren't enough present symbols to repair the missing ones.
 func (s *xorScheme) recoverSymbolPayloads(b *block) ([]byte, error) {
+	symbols, err := s.recoverSourceSymbols(b)
+	if err != nil {
+		return nil, err
+	}
+	return concatPayloads(symbols), nil
+}
+
+// recoverSourceSymbols reconstructs the missing source symbol of the block. An error is returned if more than one source symbol is missing.
+func (s *xorScheme) recoverSourceSymbols(b *block) ([]SourceSymbol, error) {
 	if !b.isRecoverable() {
 		return nil, fmt.Errorf("not enough present symbols to repair the missing ones")
 	}
@@ -86,19 +95,23 @@ func (s *xorScheme) recoverSymbolPayloads(b *block) ([]byte, error) {
 	// at this point, the symbol should be recovered. We just have to trim the extra zeros that may be hanging at the end. The first two bytes of the recovered symbol indicate the length.
 
 	payloadLen := uint16(recoveredSymbol[b.biggestSourceSymbolLenSoFar])<<8 | uint16(recoveredSymbol[b.biggestSourceSymbolLenSoFar+1])
+	if int(payloadLen) > b.biggestSourceSymbolLenSoFar {
+		return nil, fmt.Errorf("recovered source symbol length (%d) is larger than the repair symbol (%d)", payloadLen, b.biggestSourceSymbolLenSoFar)
+	}
 	recoveredPayload := recoveredSymbol[:payloadLen]
 
+	var recovered []SourceSymbol
 	for ssid := b.smallestSSID; ssid <= b.largestSSID; ssid++ {
-		// because XOR can only handle one loss, we can stop at the first missing SSID.
 		if _, exists := b.ssidToSourcePayload[ssid]; !exists {
 			b.ssidToSourcePayload[ssid] = recoveredPayload
+			recovered = append(recovered, SourceSymbol{SSID: ssid, Payload: recoveredPayload})
 		}
 	}
 
-	if !b.isComplete() {
+	if !b.isComplete() || len(recovered) != 1 {
 		// this is a sanity check, which should never happen
 		return nil, fmt.Errorf("block is not complete after recovery")
 	}
 
-	return recoveredPayload, nil
+	return recovered, nil
 }
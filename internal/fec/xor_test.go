@@ -145,7 +145,7 @@ func TestXorScheme_RepairSymbols(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := scheme.repairSymbols(tt.block)
+			got, err := scheme.repairSymbols(tt.block, tt.block.totNumRepairSymbols)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("repairSymbols() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -264,6 +264,24 @@ func TestXorScheme_recoverSymbolPayloads(t *testing.T) {
 			want:    generateLargePayload(1315, 0x2),
 			wantErr: false,
 		},
+		{
+			name: "recovered length larger than the repair symbol",
+			block: &block{
+				ssidToSourcePayload: map[protocol.SourceSymbolID][]byte{
+					0: {0, 0},
+				},
+				pidToRepairPayload: map[protocol.ParityID][]byte{
+					0: {0, 0, 0xff, 0xff},
+				},
+				totNumSourceSymbols:         2,
+				totNumRepairSymbols:         1,
+				biggestSourceSymbolLenSoFar: 2,
+				smallestSSID:                0,
+				largestSSID:                 1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	scheme := &xorScheme{}
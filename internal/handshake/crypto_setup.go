@@ -647,6 +647,13 @@ func (h *cryptoSetup) Get1RTTOpener() (ShortHeaderOpener, error) {
 	return h.aead, nil
 }
 
+func (h *cryptoSetup) Get1RTTPathAEAD(id protocol.PathID) (PathAEAD, error) {
+	if !h.has1RTTSealer || !h.has1RTTOpener {
+		return nil, ErrKeysNotYetAvailable
+	}
+	return newPathAEAD(h.aead, id), nil
+}
+
 func (h *cryptoSetup) ConnectionState() ConnectionState {
 	return ConnectionState{
 		ConnectionState: h.conn.ConnectionState(),
@@ -105,6 +105,26 @@ var _ = Describe("Crypto Setup TLS", func() {
 		Expect(err.Error()).To(ContainSubstring("tls: handshake data received at wrong level"))
 	})
 
+	It("doesn't use 0-RTT if the session ticket contains an unsupported FEC scheme", func() {
+		cs := &cryptoSetup{
+			rttStats:  &utils.RTTStats{},
+			allow0RTT: true,
+			logger:    utils.DefaultLogger,
+		}
+		cs.peerParams = &wire.TransportParameters{ActiveConnectionIDLimit: 2, EnableFEC: 0x1, DecoderFECScheme: protocol.ReedSolomonFECScheme}
+		Expect(cs.handleDataFromSessionState(cs.marshalDataForSessionState(true), true)).To(BeTrue())
+		Expect(cs.zeroRTTParameters.DecoderFECScheme).To(Equal(protocol.ReedSolomonFECScheme))
+
+		cs = &cryptoSetup{
+			rttStats:  &utils.RTTStats{},
+			allow0RTT: true,
+			logger:    utils.DefaultLogger,
+		}
+		cs.peerParams = &wire.TransportParameters{ActiveConnectionIDLimit: 2, EnableFEC: 0x1, DecoderFECScheme: 7}
+		Expect(cs.handleDataFromSessionState(cs.marshalDataForSessionState(true), true)).To(BeFalse())
+		Expect(cs.zeroRTTParameters).To(BeNil())
+	})
+
 	Context("filling in a net.Conn in tls.ClientHelloInfo", func() {
 		var (
 			local  = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 42}
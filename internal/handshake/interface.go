@@ -113,4 +113,7 @@ type CryptoSetup interface {
 	GetHandshakeSealer() (LongHeaderSealer, error)
 	Get0RTTSealer() (LongHeaderSealer, error)
 	Get1RTTSealer() (ShortHeaderSealer, error)
+
+	// Get1RTTPathAEAD returns the AEAD used for the packets sent and received on a path of a multipath connection.
+	Get1RTTPathAEAD(protocol.PathID) (PathAEAD, error)
 }
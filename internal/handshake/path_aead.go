@@ -0,0 +1,157 @@
+package handshake
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+)
+
+// A PathAEAD seals and opens the 1-RTT packets sent on a path of a multipath connection.
+type PathAEAD interface {
+	ShortHeaderSealer
+	ShortHeaderOpener
+	// SetLargestAcked is called with the largest packet number acknowledged on the path.
+	SetLargestAcked(protocol.PacketNumber) error
+}
+
+// The pathAEAD uses the keys of the updatableAEAD, but has its own packet number space.
+// As defined in draft-ietf-quic-multipath, the path ID is encoded into the nonce,
+// in front of the packet number.
+//
+// Key updates apply to all paths of the connection.
+// Since packet numbers can't be compared across packet number spaces,
+// every path keeps track of the first packet it sent with the current key phase.
+// A new key update can be initiated once a packet sent with the current key phase
+// was acknowledged on any of the paths.
+type pathAEAD struct {
+	aead   *updatableAEAD
+	pathID protocol.PathID
+
+	// the first packet number sent with the key phase sentKeyPhase
+	firstSentWithCurrentKey protocol.PacketNumber
+	sentKeyPhase            protocol.KeyPhase
+
+	highestRcvdPN protocol.PacketNumber
+	nonceBuf      []byte
+}
+
+var _ PathAEAD = &pathAEAD{}
+
+func newPathAEAD(aead *updatableAEAD, pathID protocol.PathID) *pathAEAD {
+	nonceBuf := make([]byte, aeadNonceLength)
+	binary.BigEndian.PutUint32(nonceBuf, uint32(pathID))
+	return &pathAEAD{
+		aead:                    aead,
+		pathID:                  pathID,
+		firstSentWithCurrentKey: protocol.InvalidPacketNumber,
+		nonceBuf:                nonceBuf,
+	}
+}
+
+func (a *pathAEAD) setPacketNumber(pn protocol.PacketNumber) {
+	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+}
+
+func (a *pathAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byte) []byte {
+	if a.firstSentWithCurrentKey == protocol.InvalidPacketNumber || a.sentKeyPhase != a.aead.keyPhase {
+		a.firstSentWithCurrentKey = pn
+		a.sentKeyPhase = a.aead.keyPhase
+	}
+	a.aead.numSentWithCurrentKey++
+	a.setPacketNumber(pn)
+	return a.aead.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
+}
+
+func (a *pathAEAD) DecodePacketNumber(wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber {
+	return protocol.DecodePacketNumber(wirePNLen, a.highestRcvdPN, wirePN)
+}
+
+func (a *pathAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	dec, err := a.open(dst, src, rcvTime, pn, kp, ad)
+	if err == ErrDecryptionFailed {
+		a.aead.invalidPacketCount++
+		if a.aead.invalidPacketCount >= a.aead.invalidPacketLimit {
+			return nil, &qerr.TransportError{ErrorCode: qerr.AEADLimitReached}
+		}
+	}
+	if err == nil {
+		a.highestRcvdPN = max(a.highestRcvdPN, pn)
+	}
+	return dec, err
+}
+
+func (a *pathAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.aead.maybeDropPrevKeys(rcvTime)
+	a.setPacketNumber(pn)
+	if kp == a.aead.keyPhase.Bit() {
+		dec, err := a.aead.rcvAEAD.Open(dst, a.nonceBuf, src, ad)
+		if err != nil {
+			return nil, ErrDecryptionFailed
+		}
+		if a.aead.prevRcvAEAD != nil && a.aead.prevRcvAEADExpiry.IsZero() {
+			// We initiated the key update, and now we received the first packet protected with the new key phase.
+			a.aead.logger.Debugf("Peer confirmed key update to phase %d (on path %d)", a.aead.keyPhase, a.pathID)
+			a.aead.startKeyDropTimer(rcvTime)
+		}
+		a.aead.numRcvdWithCurrentKey++
+		return dec, nil
+	}
+	// The packet was either sent before the last key update, or the peer initiated a key update.
+	if a.aead.prevRcvAEAD != nil {
+		if dec, err := a.aead.prevRcvAEAD.Open(dst, a.nonceBuf, src, ad); err == nil {
+			return dec, nil
+		}
+	}
+	dec, err := a.aead.nextRcvAEAD.Open(dst, a.nonceBuf, src, ad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	// Opening succeeded. Check if the peer was allowed to update.
+	if a.aead.keyPhase > 0 && a.aead.numSentWithCurrentKey == 0 {
+		return nil, &qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: "keys updated too quickly",
+		}
+	}
+	a.aead.rollKeys()
+	a.aead.logger.Debugf("Peer updated keys to %d (on path %d)", a.aead.keyPhase, a.pathID)
+	a.aead.startKeyDropTimer(rcvTime)
+	if a.aead.tracer != nil && a.aead.tracer.UpdatedKey != nil {
+		a.aead.tracer.UpdatedKey(a.aead.keyPhase, true)
+	}
+	a.aead.numRcvdWithCurrentKey++
+	return dec, nil
+}
+
+func (a *pathAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
+	if a.firstSentWithCurrentKey == protocol.InvalidPacketNumber || a.sentKeyPhase != a.aead.keyPhase || pn < a.firstSentWithCurrentKey {
+		return nil
+	}
+	if a.aead.numRcvdWithCurrentKey == 0 {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: fmt.Sprintf("received ACK for key phase %d on path %d, but peer didn't update keys", a.aead.keyPhase, a.pathID),
+		}
+	}
+	a.aead.ackedWithCurrentKey = true
+	return nil
+}
+
+func (a *pathAEAD) KeyPhase() protocol.KeyPhaseBit {
+	return a.aead.KeyPhase()
+}
+
+func (a *pathAEAD) Overhead() int {
+	return a.aead.aeadOverhead
+}
+
+func (a *pathAEAD) EncryptHeader(sample []byte, firstByte *byte, hdrBytes []byte) {
+	a.aead.headerEncrypter.EncryptHeader(sample, firstByte, hdrBytes)
+}
+
+func (a *pathAEAD) DecryptHeader(sample []byte, firstByte *byte, hdrBytes []byte) {
+	a.aead.headerDecrypter.DecryptHeader(sample, firstByte, hdrBytes)
+}
@@ -0,0 +1,164 @@
+package handshake
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path AEAD", func() {
+	var client, server *updatableAEAD
+	msg := []byte("Lorem ipsum dolor sit amet.")
+	ad := []byte("Donec in velit neque.")
+
+	BeforeEach(func() {
+		trafficSecret1 := make([]byte, 16)
+		trafficSecret2 := make([]byte, 16)
+		rand.Read(trafficSecret1)
+		rand.Read(trafficSecret2)
+		rttStats := utils.NewRTTStats()
+		client = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger, protocol.Version1)
+		server = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger, protocol.Version1)
+		client.SetReadKey(cipherSuites[0], trafficSecret2)
+		client.SetWriteKey(cipherSuites[0], trafficSecret1)
+		server.SetReadKey(cipherSuites[0], trafficSecret1)
+		server.SetWriteKey(cipherSuites[0], trafficSecret2)
+	})
+
+	It("uses the same nonce as the updatable AEAD for path 0", func() {
+		encrypted := newPathAEAD(server, 0).Seal(nil, msg, 0x1337, ad)
+		opened, err := client.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+	})
+
+	It("encodes the path ID into the nonce", func() {
+		encrypted := newPathAEAD(server, 1).Seal(nil, msg, 0x1337, ad)
+		_, err := client.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+		Expect(err).To(MatchError(ErrDecryptionFailed))
+		_, err = newPathAEAD(client, 2).Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+		Expect(err).To(MatchError(ErrDecryptionFailed))
+		opened, err := newPathAEAD(client, 1).Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+	})
+
+	It("uses a separate packet number space for decoding packet numbers", func() {
+		path := newPathAEAD(client, 1)
+		encrypted := newPathAEAD(server, 1).Seal(nil, msg, 0x1337, ad)
+		_, err := path.Open(nil, encrypted, time.Now(), 0x1337, protocol.KeyPhaseZero, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path.DecodePacketNumber(0x38, protocol.PacketNumberLen1)).To(Equal(protocol.PacketNumber(0x1338)))
+		Expect(client.DecodePacketNumber(0x38, protocol.PacketNumberLen1)).To(Equal(protocol.PacketNumber(0x38)))
+	})
+
+	It("initiates key updates", func() {
+		serverPath := newPathAEAD(server, 1)
+		clientPath := newPathAEAD(client, 1)
+		server.SetHandshakeConfirmed()
+		client.SetHandshakeConfirmed()
+		for i := uint64(0); i < FirstKeyUpdateInterval; i++ {
+			Expect(serverPath.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+			serverPath.Seal(nil, msg, protocol.PacketNumber(i), ad)
+		}
+		Expect(serverPath.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		encrypted := serverPath.Seal(nil, msg, 100, ad)
+		// the peer follows the key update
+		opened, err := clientPath.Open(nil, encrypted, time.Now(), 100, protocol.KeyPhaseOne, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+		Expect(client.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		// the server receives a packet with the new key phase, confirming the key update
+		encrypted = clientPath.Seal(nil, msg, 200, ad)
+		_, err = serverPath.Open(nil, encrypted, time.Now(), 200, protocol.KeyPhaseOne, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.prevRcvAEADExpiry).ToNot(BeZero())
+	})
+
+	It("initiates the next key update once a packet sent with the current key phase was acknowledged on any path", func() {
+		server.SetHandshakeConfirmed()
+		server.rollKeys()
+		Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		server.numRcvdWithCurrentKey = 1
+		path1 := newPathAEAD(server, 1)
+		path2 := newPathAEAD(server, 2)
+		for i := uint64(0); i < KeyUpdateInterval; i++ {
+			path1.Seal(nil, msg, protocol.PacketNumber(10+i), ad)
+		}
+		Expect(path1.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		// packet numbers of other paths are not compared
+		Expect(path2.SetLargestAcked(100)).To(Succeed())
+		Expect(path1.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		Expect(path1.SetLargestAcked(9)).To(Succeed())
+		Expect(path1.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		Expect(path1.SetLargestAcked(10)).To(Succeed())
+		Expect(path2.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+		Expect(server.keyPhase).To(Equal(protocol.KeyPhase(2)))
+	})
+
+	It("errors when a packet sent with the current key phase is acknowledged, but the peer didn't update keys", func() {
+		server.rollKeys()
+		path := newPathAEAD(server, 1)
+		path.Seal(nil, msg, 5, ad)
+		Expect(path.SetLargestAcked(5)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: "received ACK for key phase 1 on path 1, but peer didn't update keys",
+		}))
+	})
+
+	It("follows key updates initiated by the peer", func() {
+		serverPath := newPathAEAD(server, 1)
+		clientPath := newPathAEAD(client, 1)
+		oldEncrypted := clientPath.Seal(nil, msg, 9, ad)
+		// the peer updates its keys
+		client.rollKeys()
+		encrypted := clientPath.Seal(nil, msg, 10, ad)
+		opened, err := serverPath.Open(nil, encrypted, time.Now(), 10, protocol.KeyPhaseOne, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+		Expect(serverPath.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+		// packets sent before the key update can still be opened
+		opened, err = serverPath.Open(nil, oldEncrypted, time.Now(), 9, protocol.KeyPhaseZero, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+	})
+
+	It("errors when the peer updates keys too quickly", func() {
+		serverPath := newPathAEAD(server, 1)
+		clientPath := newPathAEAD(client, 1)
+		server.rollKeys()
+		client.rollKeys()
+		client.rollKeys()
+		encrypted := clientPath.Seal(nil, msg, 10, ad)
+		_, err := serverPath.Open(nil, encrypted, time.Now(), 10, protocol.KeyPhaseZero, ad)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.KeyUpdateError,
+			ErrorMessage: "keys updated too quickly",
+		}))
+	})
+
+	It("follows key updates on the initial path after receiving packets only on other paths", func() {
+		clientPath := newPathAEAD(client, 1)
+		serverPath := newPathAEAD(server, 1)
+		// the server initiates a key update, and the client confirms it on path 1
+		server.rollKeys()
+		client.rollKeys()
+		_, err := serverPath.Open(nil, clientPath.Seal(nil, msg, 1, ad), time.Now(), 1, protocol.KeyPhaseOne, ad)
+		Expect(err).ToNot(HaveOccurred())
+		server.Seal(nil, msg, 1, ad)
+		// the client initiates the next key update, and sends a packet on the initial path
+		client.rollKeys()
+		opened, err := server.Open(nil, client.Seal(nil, msg, 2, ad), time.Now(), 2, protocol.KeyPhaseZero, ad)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal(msg))
+		Expect(server.keyPhase).To(Equal(protocol.KeyPhase(2)))
+	})
+})
@@ -29,6 +29,9 @@ type updatableAEAD struct {
 	largestAcked       protocol.PacketNumber
 	firstPacketNumber  protocol.PacketNumber
 	handshakeConfirmed bool
+	// ackedWithCurrentKey is set when a packet sent with the current key phase
+	// on one of the additional paths of a multipath connection is acknowledged.
+	ackedWithCurrentKey bool
 
 	invalidPacketLimit uint64
 	invalidPacketCount uint64
@@ -97,6 +100,7 @@ func (a *updatableAEAD) rollKeys() {
 	a.firstSentWithCurrentKey = protocol.InvalidPacketNumber
 	a.numRcvdWithCurrentKey = 0
 	a.numSentWithCurrentKey = 0
+	a.ackedWithCurrentKey = false
 	a.prevRcvAEAD = a.rcvAEAD
 	a.rcvAEAD = a.nextRcvAEAD
 	a.sendAEAD = a.nextSendAEAD
@@ -177,7 +181,7 @@ func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 	return dec, err
 }
 
-func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+func (a *updatableAEAD) maybeDropPrevKeys(rcvTime time.Time) {
 	if a.prevRcvAEAD != nil && !a.prevRcvAEADExpiry.IsZero() && rcvTime.After(a.prevRcvAEADExpiry) {
 		a.prevRcvAEAD = nil
 		a.logger.Debugf("Dropping key phase %d", a.keyPhase-1)
@@ -186,9 +190,14 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 			a.tracer.DroppedKey(a.keyPhase - 1)
 		}
 	}
+}
+
+func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	a.maybeDropPrevKeys(rcvTime)
 	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
 	if kp != a.keyPhase.Bit() {
-		if a.keyPhase > 0 && a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber || pn < a.firstRcvdWithCurrentKey {
+		// On a multipath connection, packets with the current key phase might only have been received on other paths.
+		if a.keyPhase > 0 && a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber && a.numRcvdWithCurrentKey == 0 || pn < a.firstRcvdWithCurrentKey {
 			if a.prevRcvAEAD == nil {
 				return nil, ErrKeysDropped
 			}
@@ -199,6 +208,13 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 			}
 			return dec, err
 		}
+		// Packet numbers can't be compared to packets received on other paths.
+		// The packet might have been sent before the last key update.
+		if a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber && a.prevRcvAEAD != nil {
+			if dec, err := a.prevRcvAEAD.Open(dst, a.nonceBuf, src, ad); err == nil {
+				return dec, nil
+			}
+		}
 		// try opening the packet with the next key phase
 		dec, err := a.nextRcvAEAD.Open(dst, a.nonceBuf, src, ad)
 		if err != nil {
@@ -278,6 +294,7 @@ func (a *updatableAEAD) updateAllowed() bool {
 	// the first key update is allowed as soon as the handshake is confirmed
 	return a.keyPhase == 0 ||
 		// subsequent key updates as soon as a packet sent with that key phase has been acknowledged
+		a.ackedWithCurrentKey ||
 		(a.firstSentWithCurrentKey != protocol.InvalidPacketNumber &&
 			a.largestAcked != protocol.InvalidPacketNumber &&
 			a.largestAcked >= a.firstSentWithCurrentKey)
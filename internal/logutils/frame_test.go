@@ -42,6 +42,16 @@ var _ = Describe("CRYPTO frame", func() {
 		Expect(df.Length).To(Equal(logging.ByteCount(6)))
 	})
 
+	It("copies the ACK ranges of PATH_ACK frames", func() {
+		ack := &wire.PathAckFrame{PathID: 3, AckFrame: wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}}}
+		f := ConvertFrame(ack)
+		Expect(f).To(BeAssignableToTypeOf(&logging.PathAckFrame{}))
+		pf := f.(*logging.PathAckFrame)
+		Expect(pf.PathID).To(BeEquivalentTo(3))
+		ack.AckRanges[0].Largest = 20
+		Expect(pf.AckRanges).To(Equal([]wire.AckRange{{Smallest: 1, Largest: 10}}))
+	})
+
 	It("converts other frames", func() {
 		f := ConvertFrame(&wire.MaxDataFrame{MaximumData: 1234})
 		Expect(f).To(BeAssignableToTypeOf(&logging.MaxDataFrame{}))
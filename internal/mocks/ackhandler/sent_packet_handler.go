@@ -16,6 +16,7 @@ import (
 	ackhandler "github.com/quic-go/quic-go/internal/ackhandler"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	wire "github.com/quic-go/quic-go/internal/wire"
+	logging "github.com/quic-go/quic-go/logging"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -116,6 +117,80 @@ func (c *MockSentPacketHandlerECNModeCall) DoAndReturn(f func(bool) protocol.ECN
 	return c
 }
 
+// EnableAckFrequency mocks base method.
+func (m *MockSentPacketHandler) EnableAckFrequency(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableAckFrequency", arg0)
+}
+
+// EnableAckFrequency indicates an expected call of EnableAckFrequency.
+func (mr *MockSentPacketHandlerMockRecorder) EnableAckFrequency(arg0 any) *MockSentPacketHandlerEnableAckFrequencyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAckFrequency", reflect.TypeOf((*MockSentPacketHandler)(nil).EnableAckFrequency), arg0)
+	return &MockSentPacketHandlerEnableAckFrequencyCall{Call: call}
+}
+
+// MockSentPacketHandlerEnableAckFrequencyCall wrap *gomock.Call
+type MockSentPacketHandlerEnableAckFrequencyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) Return() *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) Do(f func(time.Duration)) *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) DoAndReturn(f func(time.Duration)) *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAckFrequencyFrame mocks base method.
+func (m *MockSentPacketHandler) GetAckFrequencyFrame(arg0 time.Time) *wire.AckFrequencyFrame {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAckFrequencyFrame", arg0)
+	ret0, _ := ret[0].(*wire.AckFrequencyFrame)
+	return ret0
+}
+
+// GetAckFrequencyFrame indicates an expected call of GetAckFrequencyFrame.
+func (mr *MockSentPacketHandlerMockRecorder) GetAckFrequencyFrame(arg0 any) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAckFrequencyFrame", reflect.TypeOf((*MockSentPacketHandler)(nil).GetAckFrequencyFrame), arg0)
+	return &MockSentPacketHandlerGetAckFrequencyFrameCall{Call: call}
+}
+
+// MockSentPacketHandlerGetAckFrequencyFrameCall wrap *gomock.Call
+type MockSentPacketHandlerGetAckFrequencyFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) Return(arg0 *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) Do(f func(time.Time) *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) DoAndReturn(f func(time.Time) *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) GetLossDetectionTimeout() time.Time {
 	m.ctrl.T.Helper()
@@ -154,6 +229,80 @@ func (c *MockSentPacketHandlerGetLossDetectionTimeoutCall) DoAndReturn(f func()
 	return c
 }
 
+// LossEstimate mocks base method.
+func (m *MockSentPacketHandler) LossEstimate() logging.LossEstimate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LossEstimate")
+	ret0, _ := ret[0].(logging.LossEstimate)
+	return ret0
+}
+
+// LossEstimate indicates an expected call of LossEstimate.
+func (mr *MockSentPacketHandlerMockRecorder) LossEstimate() *MockSentPacketHandlerLossEstimateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LossEstimate", reflect.TypeOf((*MockSentPacketHandler)(nil).LossEstimate))
+	return &MockSentPacketHandlerLossEstimateCall{Call: call}
+}
+
+// MockSentPacketHandlerLossEstimateCall wrap *gomock.Call
+type MockSentPacketHandlerLossEstimateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerLossEstimateCall) Return(arg0 logging.LossEstimate) *MockSentPacketHandlerLossEstimateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerLossEstimateCall) Do(f func() logging.LossEstimate) *MockSentPacketHandlerLossEstimateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerLossEstimateCall) DoAndReturn(f func() logging.LossEstimate) *MockSentPacketHandlerLossEstimateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigratedPath mocks base method.
+func (m *MockSentPacketHandler) MigratedPath(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath", arg0)
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockSentPacketHandlerMockRecorder) MigratedPath(arg0 any) *MockSentPacketHandlerMigratedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).MigratedPath), arg0)
+	return &MockSentPacketHandlerMigratedPathCall{Call: call}
+}
+
+// MockSentPacketHandlerMigratedPathCall wrap *gomock.Call
+type MockSentPacketHandlerMigratedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerMigratedPathCall) Return() *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerMigratedPathCall) Do(f func(protocol.ByteCount)) *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerMigratedPathCall) DoAndReturn(f func(protocol.ByteCount)) *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OnLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) OnLossDetectionTimeout() error {
 	m.ctrl.T.Helper()
@@ -374,6 +374,42 @@ func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) DoAndReturn(f func(tim
 	return c
 }
 
+// OnRecoveredLoss mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnRecoveredLoss(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRecoveredLoss", arg0, arg1, arg2)
+}
+
+// OnRecoveredLoss indicates an expected call of OnRecoveredLoss.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnRecoveredLoss(arg0, arg1, arg2 any) *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRecoveredLoss", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnRecoveredLoss), arg0, arg1, arg2)
+	return &MockSendAlgorithmWithDebugInfosOnRecoveredLossCall{Call: call}
+}
+
+// MockSendAlgorithmWithDebugInfosOnRecoveredLossCall wrap *gomock.Call
+type MockSendAlgorithmWithDebugInfosOnRecoveredLossCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall) Return() *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall) Do(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall) DoAndReturn(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnRecoveredLossCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OnRetransmissionTimeout mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) OnRetransmissionTimeout(arg0 bool) {
 	m.ctrl.T.Helper()
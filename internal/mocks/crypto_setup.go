@@ -305,6 +305,45 @@ func (c *MockCryptoSetupGet1RTTOpenerCall) DoAndReturn(f func() (handshake.Short
 	return c
 }
 
+// Get1RTTPathAEAD mocks base method.
+func (m *MockCryptoSetup) Get1RTTPathAEAD(arg0 protocol.PathID) (handshake.PathAEAD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get1RTTPathAEAD", arg0)
+	ret0, _ := ret[0].(handshake.PathAEAD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get1RTTPathAEAD indicates an expected call of Get1RTTPathAEAD.
+func (mr *MockCryptoSetupMockRecorder) Get1RTTPathAEAD(arg0 any) *MockCryptoSetupGet1RTTPathAEADCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get1RTTPathAEAD", reflect.TypeOf((*MockCryptoSetup)(nil).Get1RTTPathAEAD), arg0)
+	return &MockCryptoSetupGet1RTTPathAEADCall{Call: call}
+}
+
+// MockCryptoSetupGet1RTTPathAEADCall wrap *gomock.Call
+type MockCryptoSetupGet1RTTPathAEADCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCryptoSetupGet1RTTPathAEADCall) Return(arg0 handshake.PathAEAD, arg1 error) *MockCryptoSetupGet1RTTPathAEADCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCryptoSetupGet1RTTPathAEADCall) Do(f func(protocol.PathID) (handshake.PathAEAD, error)) *MockCryptoSetupGet1RTTPathAEADCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCryptoSetupGet1RTTPathAEADCall) DoAndReturn(f func(protocol.PathID) (handshake.PathAEAD, error)) *MockCryptoSetupGet1RTTPathAEADCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Get1RTTSealer mocks base method.
 func (m *MockCryptoSetup) Get1RTTSealer() (handshake.ShortHeaderSealer, error) {
 	m.ctrl.T.Helper()
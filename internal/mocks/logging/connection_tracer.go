@@ -101,6 +101,18 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		ChoseALPN: func(protocol string) {
 			t.ChoseALPN(protocol)
 		},
+		RecoveredSourceSymbols: func(blockID logging.BlockID, length logging.ByteCount) {
+			t.RecoveredSourceSymbols(blockID, length)
+		},
+		RejectedFECFrame: func(frame logging.Frame, reason logging.FECFrameRejectReason) {
+			t.RejectedFECFrame(frame, reason)
+		},
+		UpdatedLossEstimate: func(estimate logging.LossEstimate) {
+			t.UpdatedLossEstimate(estimate)
+		},
+		DroppedDatagram: func(length logging.ByteCount, reason logging.DatagramDropReason) {
+			t.DroppedDatagram(length, reason)
+		},
 		Close: func() {
 			t.Close()
 		},
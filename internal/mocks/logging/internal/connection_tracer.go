@@ -260,6 +260,42 @@ func (c *MockConnectionTracerDebugCall) DoAndReturn(f func(string, string)) *Moc
 	return c
 }
 
+// DroppedDatagram mocks base method.
+func (m *MockConnectionTracer) DroppedDatagram(arg0 protocol.ByteCount, arg1 logging.DatagramDropReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DroppedDatagram", arg0, arg1)
+}
+
+// DroppedDatagram indicates an expected call of DroppedDatagram.
+func (mr *MockConnectionTracerMockRecorder) DroppedDatagram(arg0, arg1 any) *MockConnectionTracerDroppedDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DroppedDatagram", reflect.TypeOf((*MockConnectionTracer)(nil).DroppedDatagram), arg0, arg1)
+	return &MockConnectionTracerDroppedDatagramCall{Call: call}
+}
+
+// MockConnectionTracerDroppedDatagramCall wrap *gomock.Call
+type MockConnectionTracerDroppedDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerDroppedDatagramCall) Return() *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerDroppedDatagramCall) Do(f func(protocol.ByteCount, logging.DatagramDropReason)) *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerDroppedDatagramCall) DoAndReturn(f func(protocol.ByteCount, logging.DatagramDropReason)) *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DroppedEncryptionLevel mocks base method.
 func (m *MockConnectionTracer) DroppedEncryptionLevel(arg0 protocol.EncryptionLevel) {
 	m.ctrl.T.Helper()
@@ -728,6 +764,78 @@ func (c *MockConnectionTracerReceivedVersionNegotiationPacketCall) DoAndReturn(f
 	return c
 }
 
+// RecoveredSourceSymbols mocks base method.
+func (m *MockConnectionTracer) RecoveredSourceSymbols(arg0 protocol.BlockID, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecoveredSourceSymbols", arg0, arg1)
+}
+
+// RecoveredSourceSymbols indicates an expected call of RecoveredSourceSymbols.
+func (mr *MockConnectionTracerMockRecorder) RecoveredSourceSymbols(arg0, arg1 any) *MockConnectionTracerRecoveredSourceSymbolsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoveredSourceSymbols", reflect.TypeOf((*MockConnectionTracer)(nil).RecoveredSourceSymbols), arg0, arg1)
+	return &MockConnectionTracerRecoveredSourceSymbolsCall{Call: call}
+}
+
+// MockConnectionTracerRecoveredSourceSymbolsCall wrap *gomock.Call
+type MockConnectionTracerRecoveredSourceSymbolsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerRecoveredSourceSymbolsCall) Return() *MockConnectionTracerRecoveredSourceSymbolsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerRecoveredSourceSymbolsCall) Do(f func(protocol.BlockID, protocol.ByteCount)) *MockConnectionTracerRecoveredSourceSymbolsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerRecoveredSourceSymbolsCall) DoAndReturn(f func(protocol.BlockID, protocol.ByteCount)) *MockConnectionTracerRecoveredSourceSymbolsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RejectedFECFrame mocks base method.
+func (m *MockConnectionTracer) RejectedFECFrame(arg0 logging.Frame, arg1 logging.FECFrameRejectReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RejectedFECFrame", arg0, arg1)
+}
+
+// RejectedFECFrame indicates an expected call of RejectedFECFrame.
+func (mr *MockConnectionTracerMockRecorder) RejectedFECFrame(arg0, arg1 any) *MockConnectionTracerRejectedFECFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectedFECFrame", reflect.TypeOf((*MockConnectionTracer)(nil).RejectedFECFrame), arg0, arg1)
+	return &MockConnectionTracerRejectedFECFrameCall{Call: call}
+}
+
+// MockConnectionTracerRejectedFECFrameCall wrap *gomock.Call
+type MockConnectionTracerRejectedFECFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerRejectedFECFrameCall) Return() *MockConnectionTracerRejectedFECFrameCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerRejectedFECFrameCall) Do(f func(logging.Frame, logging.FECFrameRejectReason)) *MockConnectionTracerRejectedFECFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerRejectedFECFrameCall) DoAndReturn(f func(logging.Frame, logging.FECFrameRejectReason)) *MockConnectionTracerRejectedFECFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RestoredTransportParameters mocks base method.
 func (m *MockConnectionTracer) RestoredTransportParameters(arg0 *wire.TransportParameters) {
 	m.ctrl.T.Helper()
@@ -1052,6 +1160,42 @@ func (c *MockConnectionTracerUpdatedKeyFromTLSCall) DoAndReturn(f func(protocol.
 	return c
 }
 
+// UpdatedLossEstimate mocks base method.
+func (m *MockConnectionTracer) UpdatedLossEstimate(arg0 logging.LossEstimate) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedLossEstimate", arg0)
+}
+
+// UpdatedLossEstimate indicates an expected call of UpdatedLossEstimate.
+func (mr *MockConnectionTracerMockRecorder) UpdatedLossEstimate(arg0 any) *MockConnectionTracerUpdatedLossEstimateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedLossEstimate", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedLossEstimate), arg0)
+	return &MockConnectionTracerUpdatedLossEstimateCall{Call: call}
+}
+
+// MockConnectionTracerUpdatedLossEstimateCall wrap *gomock.Call
+type MockConnectionTracerUpdatedLossEstimateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerUpdatedLossEstimateCall) Return() *MockConnectionTracerUpdatedLossEstimateCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerUpdatedLossEstimateCall) Do(f func(logging.LossEstimate)) *MockConnectionTracerUpdatedLossEstimateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerUpdatedLossEstimateCall) DoAndReturn(f func(logging.LossEstimate)) *MockConnectionTracerUpdatedLossEstimateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdatedMetrics mocks base method.
 func (m *MockConnectionTracer) UpdatedMetrics(arg0 *utils.RTTStats, arg1, arg2 protocol.ByteCount, arg3 int) {
 	m.ctrl.T.Helper()
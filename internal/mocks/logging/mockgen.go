@@ -48,6 +48,10 @@ type ConnectionTracer interface {
 	LossTimerCanceled()
 	ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger)
 	ChoseALPN(protocol string)
+	RecoveredSourceSymbols(logging.BlockID, logging.ByteCount)
+	RejectedFECFrame(logging.Frame, logging.FECFrameRejectReason)
+	UpdatedLossEstimate(logging.LossEstimate)
+	DroppedDatagram(logging.ByteCount, logging.DatagramDropReason)
 	// Close is called when the connection is closed.
 	Close()
 	Debug(name, msg string)
@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -typed -build_flags=-tags=gomock -package mockquic -destination internal/mocks/quic/early_conn.go github.com/quic-go/quic-go EarlyConnection
+//	mockgen -typed -build_flags=-tags=gomock -package mockquic -destination quic/early_conn_tmp.go github.com/quic-go/quic-go EarlyConnection
 //
 
 // Package mockquic is a generated GoMock package.
@@ -15,7 +15,9 @@ import (
 	reflect "reflect"
 
 	quic "github.com/quic-go/quic-go"
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
+	logging "github.com/quic-go/quic-go/logging"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -120,6 +122,45 @@ func (c *MockEarlyConnectionAcceptUniStreamCall) DoAndReturn(f func(context.Cont
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockEarlyConnection) AddPath(arg0 *quic.Transport) (*quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlyConnectionMockRecorder) AddPath(arg0 any) *MockEarlyConnectionAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlyConnection)(nil).AddPath), arg0)
+	return &MockEarlyConnectionAddPathCall{Call: call}
+}
+
+// MockEarlyConnectionAddPathCall wrap *gomock.Call
+type MockEarlyConnectionAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionAddPathCall) Return(arg0 *quic.Path, arg1 error) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionAddPathCall) Do(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionAddPathCall) DoAndReturn(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockEarlyConnection) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -234,6 +275,44 @@ func (c *MockEarlyConnectionContextCall) DoAndReturn(f func() context.Context) *
 	return c
 }
 
+// DatagramStats mocks base method.
+func (m *MockEarlyConnection) DatagramStats() quic.DatagramStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DatagramStats")
+	ret0, _ := ret[0].(quic.DatagramStats)
+	return ret0
+}
+
+// DatagramStats indicates an expected call of DatagramStats.
+func (mr *MockEarlyConnectionMockRecorder) DatagramStats() *MockEarlyConnectionDatagramStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatagramStats", reflect.TypeOf((*MockEarlyConnection)(nil).DatagramStats))
+	return &MockEarlyConnectionDatagramStatsCall{Call: call}
+}
+
+// MockEarlyConnectionDatagramStatsCall wrap *gomock.Call
+type MockEarlyConnectionDatagramStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionDatagramStatsCall) Return(arg0 quic.DatagramStats) *MockEarlyConnectionDatagramStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionDatagramStatsCall) Do(f func() quic.DatagramStats) *MockEarlyConnectionDatagramStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionDatagramStatsCall) DoAndReturn(f func() quic.DatagramStats) *MockEarlyConnectionDatagramStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HandshakeComplete mocks base method.
 func (m *MockEarlyConnection) HandshakeComplete() <-chan struct{} {
 	m.ctrl.T.Helper()
@@ -310,6 +389,44 @@ func (c *MockEarlyConnectionLocalAddrCall) DoAndReturn(f func() net.Addr) *MockE
 	return c
 }
 
+// LossEstimate mocks base method.
+func (m *MockEarlyConnection) LossEstimate() logging.LossEstimate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LossEstimate")
+	ret0, _ := ret[0].(logging.LossEstimate)
+	return ret0
+}
+
+// LossEstimate indicates an expected call of LossEstimate.
+func (mr *MockEarlyConnectionMockRecorder) LossEstimate() *MockEarlyConnectionLossEstimateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LossEstimate", reflect.TypeOf((*MockEarlyConnection)(nil).LossEstimate))
+	return &MockEarlyConnectionLossEstimateCall{Call: call}
+}
+
+// MockEarlyConnectionLossEstimateCall wrap *gomock.Call
+type MockEarlyConnectionLossEstimateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionLossEstimateCall) Return(arg0 logging.LossEstimate) *MockEarlyConnectionLossEstimateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionLossEstimateCall) Do(f func() logging.LossEstimate) *MockEarlyConnectionLossEstimateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionLossEstimateCall) DoAndReturn(f func() logging.LossEstimate) *MockEarlyConnectionLossEstimateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NextConnection mocks base method.
 func (m *MockEarlyConnection) NextConnection() quic.Connection {
 	m.ctrl.T.Helper()
@@ -734,3 +851,79 @@ func (c *MockEarlyConnectionSendDatagramWithFECCall) DoAndReturn(f func([]byte)
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SendDatagramWithFECClass mocks base method.
+func (m *MockEarlyConnection) SendDatagramWithFECClass(arg0 []byte, arg1 protocol.FECProtectionClass) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithFECClass", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithFECClass indicates an expected call of SendDatagramWithFECClass.
+func (mr *MockEarlyConnectionMockRecorder) SendDatagramWithFECClass(arg0, arg1 any) *MockEarlyConnectionSendDatagramWithFECClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithFECClass", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagramWithFECClass), arg0, arg1)
+	return &MockEarlyConnectionSendDatagramWithFECClassCall{Call: call}
+}
+
+// MockEarlyConnectionSendDatagramWithFECClassCall wrap *gomock.Call
+type MockEarlyConnectionSendDatagramWithFECClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendDatagramWithFECClassCall) Return(arg0 error) *MockEarlyConnectionSendDatagramWithFECClassCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendDatagramWithFECClassCall) Do(f func([]byte, protocol.FECProtectionClass) error) *MockEarlyConnectionSendDatagramWithFECClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendDatagramWithFECClassCall) DoAndReturn(f func([]byte, protocol.FECProtectionClass) error) *MockEarlyConnectionSendDatagramWithFECClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendDatagramWithOptions mocks base method.
+func (m *MockEarlyConnection) SendDatagramWithOptions(arg0 []byte, arg1 quic.DatagramOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithOptions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithOptions indicates an expected call of SendDatagramWithOptions.
+func (mr *MockEarlyConnectionMockRecorder) SendDatagramWithOptions(arg0, arg1 any) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithOptions", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagramWithOptions), arg0, arg1)
+	return &MockEarlyConnectionSendDatagramWithOptionsCall{Call: call}
+}
+
+// MockEarlyConnectionSendDatagramWithOptionsCall wrap *gomock.Call
+type MockEarlyConnectionSendDatagramWithOptionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) Return(arg0 error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) Do(f func([]byte, quic.DatagramOptions) error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) DoAndReturn(f func([]byte, quic.DatagramOptions) error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
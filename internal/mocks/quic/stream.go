@@ -114,6 +114,44 @@ func (c *MockStreamCancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *M
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStream) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamMockRecorder) CancelWriteAt(arg0, arg1 any) *MockStreamCancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStream)(nil).CancelWriteAt), arg0, arg1)
+	return &MockStreamCancelWriteAtCall{Call: call}
+}
+
+// MockStreamCancelWriteAtCall wrap *gomock.Call
+type MockStreamCancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamCancelWriteAtCall) Return(arg0 error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamCancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64) error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamCancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64) error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStream) Close() error {
 	m.ctrl.T.Helper()
@@ -267,6 +305,80 @@ func (c *MockStreamSetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockSt
 	return c
 }
 
+// SetFECProtectionClass mocks base method.
+func (m *MockStream) SetFECProtectionClass(arg0 protocol.FECProtectionClass) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFECProtectionClass", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFECProtectionClass indicates an expected call of SetFECProtectionClass.
+func (mr *MockStreamMockRecorder) SetFECProtectionClass(arg0 any) *MockStreamSetFECProtectionClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECProtectionClass", reflect.TypeOf((*MockStream)(nil).SetFECProtectionClass), arg0)
+	return &MockStreamSetFECProtectionClassCall{Call: call}
+}
+
+// MockStreamSetFECProtectionClassCall wrap *gomock.Call
+type MockStreamSetFECProtectionClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetFECProtectionClassCall) Return(arg0 error) *MockStreamSetFECProtectionClassCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetFECProtectionClassCall) Do(f func(protocol.FECProtectionClass) error) *MockStreamSetFECProtectionClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetFECProtectionClassCall) DoAndReturn(f func(protocol.FECProtectionClass) error) *MockStreamSetFECProtectionClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockStream) SetPriority(arg0 protocol.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamMockRecorder) SetPriority(arg0 any) *MockStreamSetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStream)(nil).SetPriority), arg0)
+	return &MockStreamSetPriorityCall{Call: call}
+}
+
+// MockStreamSetPriorityCall wrap *gomock.Call
+type MockStreamSetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetPriorityCall) Return() *MockStreamSetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetPriorityCall) Do(f func(protocol.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetPriorityCall) DoAndReturn(f func(protocol.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStream) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
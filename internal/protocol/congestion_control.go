@@ -0,0 +1,26 @@
+package protocol
+
+// A CongestionControlAlgorithm is a congestion control algorithm.
+type CongestionControlAlgorithm uint8
+
+const (
+	// CongestionControlReno is TCP NewReno (RFC 6582).
+	CongestionControlReno CongestionControlAlgorithm = iota
+	// CongestionControlCubic is Cubic (RFC 9438).
+	CongestionControlCubic
+	// CongestionControlBBR is BBR (draft-ietf-ccwg-bbr).
+	CongestionControlBBR
+)
+
+func (a CongestionControlAlgorithm) String() string {
+	switch a {
+	case CongestionControlReno:
+		return "Reno"
+	case CongestionControlCubic:
+		return "Cubic"
+	case CongestionControlBBR:
+		return "BBR"
+	default:
+		return "unknown"
+	}
+}
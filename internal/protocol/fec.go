@@ -24,15 +24,101 @@ const (
 	FECDisabled          DecoderFECScheme = iota // 0x0
 	XORFECScheme                                 // 0x1
 	ReedSolomonFECScheme                         // 0x2
+	// LargeBlockReedSolomonFECScheme is a Reed-Solomon scheme over GF(2^16), using blocks of thousands of symbols.
+	LargeBlockReedSolomonFECScheme // 0x3
 )
 
+// MaxDecoderFECScheme is the largest valid FEC scheme.
+const MaxDecoderFECScheme = LargeBlockReedSolomonFECScheme
+
 func (f DecoderFECScheme) String() string {
 	switch f {
 	case XORFECScheme:
 		return "XOR"
 	case ReedSolomonFECScheme:
 		return "ReedSolomon"
+	case LargeBlockReedSolomonFECScheme:
+		return "LargeBlockReedSolomon"
+	default:
+		return "unknown"
+	}
+}
+
+// FECWireFormat is the encoding of the FEC frames and transport parameters.
+type FECWireFormat uint8
+
+const (
+	// FECWireFormatLegacy is the encoding that was used before the draft encoding was implemented.
+	// It supports FEC protection classes.
+	FECWireFormatLegacy FECWireFormat = iota // 0x0
+	// FECWireFormatDraft is the encoding of draft-michel-quic-fec.
+	// It interoperates with other QUIC-FEC implementations, but doesn't support FEC protection classes.
+	FECWireFormatDraft // 0x1
+)
+
+func (f FECWireFormat) String() string {
+	switch f {
+	case FECWireFormatLegacy:
+		return "legacy"
+	case FECWireFormatDraft:
+		return "draft"
+	default:
+		return "unknown"
+	}
+}
+
+// FECProtectionClass determines how strongly FEC protected data is protected.
+// Every class uses its own block geometry and code rate,
+// and the source symbols of every class are encoded in a separate sequence of blocks.
+type FECProtectionClass uint8
+
+const (
+	// FECProtectionDefault is the protection class of FEC protected data that wasn't assigned a class.
+	FECProtectionDefault FECProtectionClass = iota // 0x0
+	// FECProtectionHigh uses small blocks with a low code rate, e.g. for keyframes or control messages.
+	FECProtectionHigh // 0x1
+	// FECProtectionLow uses large blocks with a high code rate, e.g. for bulk data.
+	FECProtectionLow // 0x2
+)
+
+// MaxFECProtectionClass is the largest valid FEC protection class.
+const MaxFECProtectionClass = FECProtectionLow
+
+func (c FECProtectionClass) String() string {
+	switch c {
+	case FECProtectionDefault:
+		return "default"
+	case FECProtectionHigh:
+		return "high"
+	case FECProtectionLow:
+		return "low"
 	default:
 		return "unknown"
 	}
 }
+
+// FECControlFrames is a set of control frame types.
+type FECControlFrames uint16
+
+const (
+	FECControlFrameResetStream FECControlFrames = 1 << iota
+	FECControlFrameStopSending
+	FECControlFrameMaxData
+	FECControlFrameMaxStreamData
+	FECControlFrameMaxStreams
+	FECControlFrameDataBlocked
+	FECControlFrameStreamDataBlocked
+	FECControlFrameStreamsBlocked
+	FECControlFrameNewConnectionID
+	FECControlFrameRetireConnectionID
+	FECControlFrameNewToken
+)
+
+// FECControlFramesFlowControl are the control frames that grant and request flow control credit.
+const FECControlFramesFlowControl = FECControlFrameMaxData | FECControlFrameMaxStreamData | FECControlFrameMaxStreams |
+	FECControlFrameDataBlocked | FECControlFrameStreamDataBlocked | FECControlFrameStreamsBlocked
+
+// Contains says if all frame types of t are contained in the set.
+func (f FECControlFrames) Contains(t FECControlFrames) bool {
+	return t != 0 && f&t == t
+}
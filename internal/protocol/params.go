@@ -70,6 +70,30 @@ const TokenValidity = 24 * time.Hour
 // Sending ACKs and retransmission is still allowed, but now new regular packets can be sent.
 const MaxOutstandingSentPackets = 2 * MaxCongestionWindowPackets
 
+// MaxFECDecoderMemory is the maximum number of bytes of source and repair symbols
+// that the FEC decoder of a protection class buffers for the blocks within its decoding window.
+const MaxFECDecoderMemory = 32 << 20
+
+// MaxFECRepairRatio is the maximum number of repair symbols per source symbol of the blocks of the protection classes.
+const MaxFECRepairRatio = 2
+
+// MaxFECDecodingWindow is the number of source symbols beyond the highest received source symbol that the FEC decoder accepts.
+// Symbols of blocks that lie more than this many source symbols behind the highest received source symbol are ignored.
+// It is advertised to the peer in a FEC_WINDOW frame, and assumed for the peer if it doesn't advertise its window.
+// It is derived from MaxFECDecoderMemory, such that the blocks within the window fit into the decoder's memory,
+// even if all their repair symbols are received.
+const MaxFECDecodingWindow = MaxFECDecoderMemory / ((1 + MaxFECRepairRatio) * MaxFECPacketBufferSize)
+
+// MaxFECSenderMemory is the maximum number of bytes of source and repair symbols
+// of the completed blocks kept by the FEC sender of a protection class.
+// The sender keeps these blocks, such that additional repair symbols can be sent when symbols are lost.
+const MaxFECSenderMemory = 4 << 20
+
+// MaxFECBlockSymbols is the maximum number of source and repair symbols of a block.
+// The memory used to decode a block is proportional to the number of its symbols,
+// so it bounds the memory used by the FEC decoder if a single block exceeds MaxFECDecoderMemory.
+const MaxFECBlockSymbols = 8192
+
 // MaxTrackedSentPackets is maximum number of sent packets saved for retransmission.
 // When reached, no more packets will be sent.
 // This value *must* be larger than MaxOutstandingSentPackets.
@@ -149,6 +173,21 @@ const MaxActiveConnectionIDs = 4
 // MaxIssuedConnectionIDs is the maximum number of connection IDs that we're issuing at the same time.
 const MaxIssuedConnectionIDs = 6
 
+// DefaultMaxPaths is the default number of paths that can be used on a multipath connection.
+const DefaultMaxPaths = 4
+
+// MaxPaths is the maximum number of paths that can be used on a multipath connection.
+const MaxPaths = 64
+
+// DefaultDatagramSendQueueLen is the default maximum number of datagrams queued for sending.
+const DefaultDatagramSendQueueLen = 32
+
+// DefaultDatagramReceiveQueueLen is the default maximum number of received datagrams queued until they are read by the application.
+const DefaultDatagramReceiveQueueLen = 128
+
+// MaxPathProbes is the number of PATH_CHALLENGE frames that are sent to validate a new path of a multipath connection.
+const MaxPathProbes = 5
+
 // PacketsPerConnectionID is the number of packets we send using one connection ID.
 // If the peer provices us with enough new connection IDs, we switch to a new connection ID.
 const PacketsPerConnectionID = 10000
@@ -163,6 +202,10 @@ const TimerGranularity = time.Millisecond
 // MaxAckDelay is the maximum time by which we delay sending ACKs.
 const MaxAckDelay = 25 * time.Millisecond
 
+// MinAckDelay is the min_ack_delay we advertise when using the ACK frequency extension.
+// We can't delay ACKs by less than the timer granularity.
+const MinAckDelay = TimerGranularity
+
 // MaxAckDelayInclGranularity is the max_ack_delay including the timer granularity.
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
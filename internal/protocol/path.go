@@ -0,0 +1,32 @@
+package protocol
+
+// A PathID identifies a path of a multipath connection.
+// The path that the handshake is performed on has the path ID 0.
+type PathID uint32
+
+// InitialPathID is the ID of the path that the handshake is performed on.
+const InitialPathID PathID = 0
+
+// MaxPathID is the largest path ID that can be encoded.
+const MaxPathID PathID = 1<<32 - 1
+
+// A MultipathScheduler decides which path a packet is sent on.
+type MultipathScheduler uint8
+
+const (
+	// MultipathSchedulerMinRTT sends packets on the path with the smallest RTT that isn't limited by congestion control.
+	MultipathSchedulerMinRTT MultipathScheduler = iota
+	// MultipathSchedulerRoundRobin alternates between all paths that aren't limited by congestion control.
+	MultipathSchedulerRoundRobin
+)
+
+func (s MultipathScheduler) String() string {
+	switch s {
+	case MultipathSchedulerMinRTT:
+		return "MinRTT"
+	case MultipathSchedulerRoundRobin:
+		return "RoundRobin"
+	default:
+		return "unknown"
+	}
+}
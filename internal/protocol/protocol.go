@@ -113,6 +113,8 @@ const MaxPacketBufferSize = 1452
 /*
 MaxFECHeaderOverhead represents the maximum overhead that can come from FEC.
 This affects the maximum buffer size.
+The packet packer doesn't reserve this overhead, but calculates the exact overhead
+for the current maximum packet size.
 
 The largest overhead from FEC comes as a result of the repair frame.
 This is because repair frames contain Source Symbol payloads + the length
@@ -135,6 +137,7 @@ Source Symbol len --> 2 byets
 */
 const MaxFECHeaderOverhead = 18
 
+// MaxFECPacketBufferSize is the maximum size of a source symbol.
 const MaxFECPacketBufferSize = MaxPacketBufferSize - MaxFECHeaderOverhead
 
 const RepairPayloadMetadataLen = 2
@@ -0,0 +1,34 @@
+package protocol
+
+// MaxStreamUrgency is the lowest urgency level of a stream.
+const MaxStreamUrgency = 7
+
+// A StreamPriority is the scheduling priority of a stream, modeled after RFC 9218.
+type StreamPriority struct {
+	// Urgency is the urgency level, from 0 (most urgent) to MaxStreamUrgency (least urgent).
+	// Larger values are treated as MaxStreamUrgency.
+	// Data of more urgent streams is always sent first.
+	Urgency uint8
+	// Incremental says if the data of the stream is useful to the receiver before the whole stream is received.
+	// Incremental streams of the same urgency share the bandwidth.
+	// Non-incremental streams of the same urgency are sent one after the other, in the order of their stream IDs,
+	// before the incremental streams.
+	Incremental bool
+	// Weight is the share of an incremental stream, compared to the other incremental streams of the same urgency.
+	// A stream sends up to Weight STREAM frames (usually one per packet) before the next stream's turn.
+	// A Weight of 0 is treated as 1.
+	Weight uint8
+}
+
+// DefaultStreamPriority returns the priority of a stream unless a different priority is set.
+// All streams with the default priority share the bandwidth equally.
+func DefaultStreamPriority() StreamPriority {
+	return StreamPriority{Urgency: 3, Incremental: true, Weight: 1}
+}
+
+// Normalize returns the priority with the urgency capped at MaxStreamUrgency, and a Weight of at least 1.
+func (p StreamPriority) Normalize() StreamPriority {
+	p.Urgency = min(p.Urgency, MaxStreamUrgency)
+	p.Weight = max(p.Weight, 1)
+	return p
+}
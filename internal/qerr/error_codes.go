@@ -27,6 +27,9 @@ const (
 	KeyUpdateError            TransportErrorCode = 0xe
 	AEADLimitReached          TransportErrorCode = 0xf
 	NoViablePathError         TransportErrorCode = 0x10
+	// FECError is used when a peer sends a FEC frame that wasn't negotiated, or that is invalid.
+	// It is not defined in RFC 9000.
+	FECError TransportErrorCode = 0x32a80fec
 )
 
 func (e TransportErrorCode) IsCryptoError() bool {
@@ -78,6 +81,8 @@ func (e TransportErrorCode) String() string {
 		return "AEAD_LIMIT_REACHED"
 	case NoViablePathError:
 		return "NO_VIABLE_PATH"
+	case FECError:
+		return "FEC_ERROR"
 	default:
 		if e.IsCryptoError() {
 			return fmt.Sprintf("CRYPTO_ERROR %#x", uint16(e))
@@ -30,4 +30,14 @@ var _ = Describe("Min / Max", func() {
 		Expect(MinNonZeroDuration(b, a)).To(Equal(b))
 		Expect(MinNonZeroDuration(time.Minute, time.Hour)).To(Equal(time.Minute))
 	})
+
+	It("returns the earlier non-zero time", func() {
+		a := time.Now()
+		b := a.Add(time.Second)
+		Expect(MinNonZeroTime(time.Time{}, time.Time{})).To(BeZero())
+		Expect(MinNonZeroTime(time.Time{}, b)).To(Equal(b))
+		Expect(MinNonZeroTime(b, time.Time{})).To(Equal(b))
+		Expect(MinNonZeroTime(a, b)).To(Equal(a))
+		Expect(MinNonZeroTime(b, a)).To(Equal(a))
+	})
 })
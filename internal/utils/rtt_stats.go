@@ -116,6 +116,7 @@ func (r *RTTStats) SetInitialRTT(t time.Duration) {
 
 // OnConnectionMigration is called when connection migrates and rtt measurement needs to be reset.
 func (r *RTTStats) OnConnectionMigration() {
+	r.hasMeasurement = false
 	r.latestRTT = 0
 	r.minRTT = 0
 	r.smoothedRTT = 0
@@ -23,7 +23,7 @@ type AckFrame struct {
 
 // parseAckFrame reads an ACK frame
 func parseAckFrame(frame *AckFrame, r *bytes.Reader, typ uint64, ackDelayExponent uint8, _ protocol.Version) error {
-	ecn := typ == ackECNFrameType
+	ecn := typ == ackECNFrameType || typ == pathAckECNFrameType
 
 	la, err := quicvarint.Read(r)
 	if err != nil {
@@ -111,12 +111,17 @@ func parseAckFrame(frame *AckFrame, r *bytes.Reader, typ uint64, ackDelayExponen
 
 // Append appends an ACK frame.
 func (f *AckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
-	if hasECN {
+	if f.hasECN() {
 		b = append(b, ackECNFrameType)
 	} else {
 		b = append(b, ackFrameType)
 	}
+	return f.appendBody(b), nil
+}
+
+// appendBody appends everything following the frame type.
+// It is shared with the PATH_ACK frame.
+func (f *AckFrame) appendBody(b []byte) []byte {
 	b = quicvarint.Append(b, uint64(f.LargestAcked()))
 	b = quicvarint.Append(b, encodeAckDelay(f.DelayTime))
 
@@ -134,20 +139,28 @@ func (f *AckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
 		b = quicvarint.Append(b, len)
 	}
 
-	if hasECN {
+	if f.hasECN() {
 		b = quicvarint.Append(b, f.ECT0)
 		b = quicvarint.Append(b, f.ECT1)
 		b = quicvarint.Append(b, f.ECNCE)
 	}
-	return b, nil
+	return b
+}
+
+func (f *AckFrame) hasECN() bool {
+	return f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
 }
 
 // Length of a written frame
 func (f *AckFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return 1 + f.bodyLength()
+}
+
+func (f *AckFrame) bodyLength() protocol.ByteCount {
 	largestAcked := f.AckRanges[0].Largest
 	numRanges := f.numEncodableAckRanges()
 
-	length := 1 + quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
+	length := quicvarint.Len(uint64(largestAcked)) + quicvarint.Len(encodeAckDelay(f.DelayTime))
 
 	length += quicvarint.Len(uint64(numRanges - 1))
 	lowestInFirstRange := f.AckRanges[0].Smallest
@@ -158,7 +171,7 @@ func (f *AckFrame) Length(_ protocol.Version) protocol.ByteCount {
 		length += quicvarint.Len(gap)
 		length += quicvarint.Len(len)
 	}
-	if f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0 {
+	if f.hasECN() {
 		length += quicvarint.Len(f.ECT0)
 		length += quicvarint.Len(f.ECT1)
 		length += quicvarint.Len(f.ECNCE)
@@ -0,0 +1,63 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	It("writes and parses a sample frame", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        0x1337,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    12345 * time.Microsecond,
+			ReorderingThreshold:   3,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		typ, err := quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(typ).To(BeEquivalentTo(ackFrequencyFrameType))
+		frame, err := parseAckFrequencyFrame(r, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        0x1337,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    12345 * time.Microsecond,
+			ReorderingThreshold:   3,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		typeLen := quicvarint.Len(ackFrequencyFrameType)
+		for i := int(typeLen); i < len(b); i++ {
+			r := bytes.NewReader(b[:i])
+			_, err := quicvarint.Read(r)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = parseAckFrequencyFrame(r, protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		}
+	})
+
+	It("errors if the requested max_ack_delay is too large", func() {
+		b := quicvarint.Append(nil, 1)
+		b = quicvarint.Append(b, 2)
+		b = quicvarint.Append(b, uint64(protocol.MaxMaxAckDelay/time.Microsecond)+1)
+		b = quicvarint.Append(b, 3)
+		_, err := parseAckFrequencyFrame(bytes.NewReader(b), protocol.Version1)
+		Expect(err).To(MatchError("invalid Request Max Ack Delay"))
+	})
+})
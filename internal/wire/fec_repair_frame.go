@@ -2,19 +2,43 @@ package wire
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
 type RepairFrame struct {
+	// Format is the wire format that the frame is encoded in.
+	Format protocol.FECWireFormat
+	// Class is the protection class of the block that the repair symbol belongs to.
+	Class    protocol.FECProtectionClass
 	Metadata protocol.BlockMetadata
-	Payload  []byte
+	// Payload is the repair symbol, followed by the protocol.RepairPayloadMetadataLen bytes encoding the length of the source symbols.
+	// In the draft wire format, the length is sent in front of the repair symbol, see draftRepairPayload.
+	Payload []byte
 }
 
-func parseRepairFrame(r *bytes.Reader, _ protocol.Version) (*RepairFrame, error) {
+// draftRepairFPILen is the length of the Repair FEC Payload ID of the draft wire format:
+// a 32 bit source block number and a 16 bit repair symbol ID.
+// Only the lower 32 bits of the block ID are sent, the receiver reconstructs the full block ID.
+const draftRepairFPILen = 6
+
+var errFECPayloadIDTooLarge = errors.New("repair symbol ID too large for the draft wire format")
+
+func parseRepairFrame(r *bytes.Reader, typ uint64, _ protocol.Version) (*RepairFrame, error) {
 	frame := &RepairFrame{}
+	if typ == repairWithClassFrameType {
+		class, err := parseFECProtectionClass(r)
+		if err != nil {
+			return nil, err
+		}
+		frame.Class = class
+	}
 	blockID, err := quicvarint.Read(r)
 	if err != nil {
 		return nil, err
@@ -42,8 +66,47 @@ func parseRepairFrame(r *bytes.Reader, _ protocol.Version) (*RepairFrame, error)
 	return frame, nil
 }
 
-func (f *RepairFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	b = quicvarint.Append(b, uint64(repairFrameType))
+// parseDraftRepairFrame parses a REPAIR frame in the draft wire format.
+func parseDraftRepairFrame(r *bytes.Reader, _ protocol.Version) (*RepairFrame, error) {
+	var fpi [draftRepairFPILen]byte
+	if _, err := io.ReadFull(r, fpi[:]); err != nil {
+		return nil, io.EOF
+	}
+	frame := &RepairFrame{
+		Format: protocol.FECWireFormatDraft,
+		Metadata: protocol.BlockMetadata{
+			BlockID:  protocol.BlockID(binary.BigEndian.Uint32(fpi[:4])),
+			ParityID: protocol.ParityID(binary.BigEndian.Uint16(fpi[4:])),
+		},
+	}
+	payloadLen, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if payloadLen > uint64(r.Len()) {
+		return nil, io.EOF
+	}
+	if payloadLen != 0 {
+		frame.Payload = make([]byte, payloadLen)
+		if _, err := io.ReadFull(r, frame.Payload); err != nil {
+			// this should never happen since we already checked the dataLen earlier.
+			return nil, err
+		}
+		rotateRepairPayload(frame.Payload, protocol.RepairPayloadMetadataLen)
+	}
+	return frame, nil
+}
+
+func (f *RepairFrame) Append(b []byte, v protocol.Version) ([]byte, error) {
+	if f.Format == protocol.FECWireFormatDraft {
+		return f.appendDraft(b, v)
+	}
+	if f.Class == protocol.FECProtectionDefault {
+		b = quicvarint.Append(b, uint64(repairFrameType))
+	} else {
+		b = quicvarint.Append(b, uint64(repairWithClassFrameType))
+		b = quicvarint.Append(b, uint64(f.Class))
+	}
 	b = quicvarint.Append(b, uint64(f.Metadata.BlockID))
 	b = quicvarint.Append(b, uint64(f.Metadata.ParityID))
 	b = quicvarint.Append(b, uint64(len(f.Payload)))
@@ -51,7 +114,93 @@ func (f *RepairFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
 	return b, nil
 }
 
+func (f *RepairFrame) appendDraft(b []byte, _ protocol.Version) ([]byte, error) {
+	if f.Metadata.ParityID > math.MaxUint16 {
+		return nil, errFECPayloadIDTooLarge
+	}
+	b = quicvarint.Append(b, draftRepairFrameType)
+	b = binary.BigEndian.AppendUint32(b, uint32(f.Metadata.BlockID))
+	b = binary.BigEndian.AppendUint16(b, uint16(f.Metadata.ParityID))
+	b = quicvarint.Append(b, uint64(len(f.Payload)))
+	b = append(b, draftRepairPayload(f.Payload)...)
+	return b, nil
+}
+
+// draftRepairPayload converts the payload to the draft wire format.
+// The draft encodes the length of a source symbol in front of it, not behind it.
+// Both Reed-Solomon and XOR encode every byte position of the source symbols separately,
+// so moving the length of all source symbols to the front moves the length of the repair symbols to the front as well.
+func draftRepairPayload(p []byte) []byte {
+	payload := make([]byte, len(p))
+	copy(payload, p)
+	if len(payload) >= protocol.RepairPayloadMetadataLen {
+		rotateRepairPayload(payload, len(payload)-protocol.RepairPayloadMetadataLen)
+	}
+	return payload
+}
+
+// rotateRepairPayload moves the first n bytes of p to its end.
+func rotateRepairPayload(p []byte, n int) {
+	if len(p) < protocol.RepairPayloadMetadataLen {
+		return
+	}
+	head := make([]byte, n)
+	copy(head, p[:n])
+	copy(p, p[n:])
+	copy(p[len(p)-n:], head)
+}
+
 // Length
 func (f *RepairFrame) Length(_ protocol.Version) protocol.ByteCount {
-	return quicvarint.Len(uint64(repairFrameType)) + quicvarint.Len(uint64(f.Metadata.BlockID)) + quicvarint.Len(uint64(f.Metadata.ParityID)) + quicvarint.Len(uint64(len(f.Payload))) + protocol.ByteCount(len(f.Payload))
+	if f.Format == protocol.FECWireFormatDraft {
+		return quicvarint.Len(draftRepairFrameType) + draftRepairFPILen + quicvarint.Len(uint64(len(f.Payload))) + protocol.ByteCount(len(f.Payload))
+	}
+	return fecFrameTypeLen(repairFrameType, repairWithClassFrameType, f.Class) + quicvarint.Len(uint64(f.Metadata.BlockID)) + quicvarint.Len(uint64(f.Metadata.ParityID)) + quicvarint.Len(uint64(len(f.Payload))) + protocol.ByteCount(len(f.Payload))
+}
+
+// MaxPayloadLen returns the maximum payload length, such that the frame is not larger than maxSize.
+// The payload includes the protocol.RepairPayloadMetadataLen bytes encoding the length of the source symbols.
+func (f *RepairFrame) MaxPayloadLen(maxSize protocol.ByteCount, _ protocol.Version) protocol.ByteCount {
+	var headerLen protocol.ByteCount
+	if f.Format == protocol.FECWireFormatDraft {
+		headerLen = quicvarint.Len(draftRepairFrameType) + draftRepairFPILen
+	} else {
+		headerLen = fecFrameTypeLen(repairFrameType, repairWithClassFrameType, f.Class) + quicvarint.Len(uint64(f.Metadata.BlockID)) + quicvarint.Len(uint64(f.Metadata.ParityID))
+	}
+	return maxLengthPrefixedLen(maxSize, headerLen)
+}
+
+// maxLengthPrefixedLen returns the maximum length of a length-prefixed field,
+// such that the field, its length and headerLen bytes in front of it are not larger than maxSize.
+func maxLengthPrefixedLen(maxSize, headerLen protocol.ByteCount) protocol.ByteCount {
+	// pretend that the length will be 1 byte
+	// if it turns out that varint encoding the length will consume 2 bytes, we need to adjust the length afterwards
+	headerLen++
+	if headerLen > maxSize {
+		return 0
+	}
+	maxLen := maxSize - headerLen
+	if quicvarint.Len(uint64(maxLen)) != 1 {
+		maxLen--
+	}
+	return maxLen
+}
+
+// fecFrameTypeLen is the length of the frame type of a REPAIR or SOURCE_SYMBOL frame, including the protection class.
+func fecFrameTypeLen(typ, typWithClass uint64, class protocol.FECProtectionClass) protocol.ByteCount {
+	if class == protocol.FECProtectionDefault {
+		return quicvarint.Len(typ)
+	}
+	return quicvarint.Len(typWithClass) + quicvarint.Len(uint64(class))
+}
+
+func parseFECProtectionClass(r *bytes.Reader) (protocol.FECProtectionClass, error) {
+	class, err := quicvarint.Read(r)
+	if err != nil {
+		return 0, err
+	}
+	if class > uint64(protocol.MaxFECProtectionClass) {
+		return 0, fmt.Errorf("invalid FEC protection class: %d", class)
+	}
+	return protocol.FECProtectionClass(class), nil
 }
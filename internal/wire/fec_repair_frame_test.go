@@ -0,0 +1,202 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("REPAIR frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x42)              // block ID
+			data = append(data, encodeVarInt(3)...) // parity ID
+			data = append(data, encodeVarInt(6)...) // payload length
+			data = append(data, []byte("foobar")...)
+			r := bytes.NewReader(data)
+			frame, err := parseRepairFrame(r, repairFrameType, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.Class).To(Equal(protocol.FECProtectionDefault))
+			Expect(frame.Metadata).To(Equal(protocol.BlockMetadata{BlockID: 0x42, ParityID: 3}))
+			Expect(frame.Payload).To(Equal([]byte("foobar")))
+			Expect(r.Len()).To(BeZero())
+		})
+
+		It("parses the protection class", func() {
+			data := encodeVarInt(uint64(protocol.FECProtectionLow))
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(6)...)
+			data = append(data, []byte("foobar")...)
+			frame, err := parseRepairFrame(bytes.NewReader(data), repairWithClassFrameType, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.Class).To(Equal(protocol.FECProtectionLow))
+			Expect(frame.Metadata).To(Equal(protocol.BlockMetadata{BlockID: 0x42, ParityID: 3}))
+		})
+
+		It("rejects unknown protection classes", func() {
+			data := encodeVarInt(uint64(protocol.MaxFECProtectionClass) + 1)
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(0)...)
+			_, err := parseRepairFrame(bytes.NewReader(data), repairWithClassFrameType, protocol.Version1)
+			Expect(err).To(MatchError(ContainSubstring("invalid FEC protection class")))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(uint64(protocol.FECProtectionHigh))
+			data = append(data, encodeVarInt(0x42)...)
+			data = append(data, encodeVarInt(3)...)
+			data = append(data, encodeVarInt(6)...)
+			data = append(data, []byte("foobar")...)
+			_, err := parseRepairFrame(bytes.NewReader(data), repairWithClassFrameType, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseRepairFrame(bytes.NewReader(data[:i]), repairWithClassFrameType, protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a frame of the default protection class", func() {
+			f := &RepairFrame{Metadata: protocol.BlockMetadata{BlockID: 0x42, ParityID: 3}, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(repairFrameType)
+			expected = append(expected, encodeVarInt(0x42)...)
+			expected = append(expected, encodeVarInt(3)...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
+
+		It("writes the protection class", func() {
+			f := &RepairFrame{Class: protocol.FECProtectionHigh, Metadata: protocol.BlockMetadata{BlockID: 0x42, ParityID: 3}, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(repairWithClassFrameType)
+			expected = append(expected, encodeVarInt(uint64(protocol.FECProtectionHigh))...)
+			expected = append(expected, encodeVarInt(0x42)...)
+			expected = append(expected, encodeVarInt(3)...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			l, frame, err := NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(frame).To(Equal(f))
+		})
+	})
+
+	Context("in the draft wire format", func() {
+		// The XOR repair symbol of the source symbols "foo" and "ba".
+		// In the legacy wire format, the length of the source symbols follows the repair symbol,
+		// in the draft wire format, it precedes it.
+		legacyPayload := []byte{0x04, 0x0e, 0x6f, 0x00, 0x01}
+		draftPayload := []byte{0x00, 0x01, 0x04, 0x0e, 0x6f}
+		// This frame was encoded by hand from the frame format of draft-michel-quic-fec,
+		// using the provisional frame type 0xfec1. It is not a test vector of the draft.
+		testVector := []byte{
+			0x80, 0x00, 0xfe, 0xc1, // frame type
+			0x00, 0x00, 0x00, 0x42, // source block number
+			0x00, 0x03, // repair symbol ID
+			0x05, // length
+		}
+		testVector = append(testVector, draftPayload...)
+
+		It("writes a frame", func() {
+			f := &RepairFrame{
+				Format:   protocol.FECWireFormatDraft,
+				Metadata: protocol.BlockMetadata{BlockID: 0x42, ParityID: 3},
+				Payload:  append([]byte{}, legacyPayload...),
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(testVector))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			// the payload of the frame is not modified
+			Expect(f.Payload).To(Equal(legacyPayload))
+		})
+
+		It("parses a frame", func() {
+			l, frame, err := NewFrameParser(false).ParseNext(testVector, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(testVector)))
+			Expect(frame).To(Equal(&RepairFrame{
+				Format:   protocol.FECWireFormatDraft,
+				Metadata: protocol.BlockMetadata{BlockID: 0x42, ParityID: 3},
+				Payload:  legacyPayload,
+			}))
+		})
+
+		It("errors on EOFs", func() {
+			data := testVector[4:]
+			for i := range data {
+				_, err := parseDraftRepairFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+
+		It("only writes the lower 32 bits of the block ID", func() {
+			f := &RepairFrame{
+				Format:   protocol.FECWireFormatDraft,
+				Metadata: protocol.BlockMetadata{BlockID: 1<<32 + 0x42, ParityID: 3},
+				Payload:  append([]byte{}, legacyPayload...),
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(testVector))
+		})
+
+		It("rejects repair symbol IDs that don't fit into the Repair FEC Payload ID", func() {
+			f := &RepairFrame{
+				Format:   protocol.FECWireFormatDraft,
+				Metadata: protocol.BlockMetadata{ParityID: 1 << 16},
+				Payload:  []byte{0, 0},
+			}
+			_, err := f.Append(nil, protocol.Version1)
+			Expect(err).To(MatchError(errFECPayloadIDTooLarge))
+		})
+	})
+	Context("max payload length", func() {
+		for _, format := range []protocol.FECWireFormat{protocol.FECWireFormatLegacy, protocol.FECWireFormatDraft} {
+			format := format
+
+			It(fmt.Sprintf("always returns a payload length such that the resulting frame has the right size, in the %s wire format", format), func() {
+				data := make([]byte, 3000)
+				f := &RepairFrame{
+					Format:   format,
+					Class:    protocol.FECProtectionHigh,
+					Metadata: protocol.BlockMetadata{BlockID: 0x1337, ParityID: 19},
+				}
+				var frameOneByteTooSmallCounter int
+				for i := 1; i < 3000; i++ {
+					maxPayloadLen := f.MaxPayloadLen(protocol.ByteCount(i), protocol.Version1)
+					if maxPayloadLen == 0 {
+						f.Payload = nil
+						Expect(f.Length(protocol.Version1)).To(BeNumerically(">=", i))
+						continue
+					}
+					f.Payload = data[:int(maxPayloadLen)]
+					b, err := f.Append(nil, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					// a payload length of x can be encoded into 1 byte, but x+1 needs 2 bytes
+					if len(b) == i-1 {
+						frameOneByteTooSmallCounter++
+						continue
+					}
+					Expect(len(b)).To(Equal(i))
+				}
+				Expect(frameOneByteTooSmallCounter).To(Equal(1))
+			})
+		}
+	})
+})
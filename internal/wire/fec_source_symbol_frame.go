@@ -2,6 +2,8 @@ package wire
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -9,6 +11,12 @@ import (
 )
 
 type SourceSymbolFrame struct {
+	// Format is the wire format that the frame is encoded in.
+	// In the draft wire format, the payload extends to the end of the packet,
+	// so the frame has to be the last frame of the packet.
+	Format protocol.FECWireFormat
+	// Class is the protection class of the block that the source symbol belongs to.
+	Class protocol.FECProtectionClass
 	// SSID represents the source symbol ID. Each source symbol ID is unique.
 	SSID protocol.SourceSymbolID
 	// Payload represents a collection of STREAM and DATAGRAM frames.
@@ -16,6 +24,24 @@ type SourceSymbolFrame struct {
 	Payload []byte
 }
 
+func parseSourceSymbolFrame(r *bytes.Reader, typ uint64, v protocol.Version) (*SourceSymbolFrame, error) {
+	class := protocol.FECProtectionDefault
+	if typ == sourceSymbolWithClassFrameType {
+		var err error
+		class, err = parseFECProtectionClass(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	frame, err := ParseSourceSymbolFrame(r, v)
+	if err != nil {
+		return nil, err
+	}
+	frame.Class = class
+	return frame, nil
+}
+
+// ParseSourceSymbolFrame parses a SOURCE_SYMBOL frame of the default protection class, after its frame type.
 func ParseSourceSymbolFrame(r *bytes.Reader, _ protocol.Version) (*SourceSymbolFrame, error) {
 	frame := &SourceSymbolFrame{}
 	sid, err := quicvarint.Read(r)
@@ -30,6 +56,9 @@ func ParseSourceSymbolFrame(r *bytes.Reader, _ protocol.Version) (*SourceSymbolF
 	if payloadLen > uint64(r.Len()) {
 		return nil, io.EOF
 	}
+	if payloadLen > protocol.MaxFECPacketBufferSize {
+		return nil, fmt.Errorf("SOURCE_SYMBOL payload too large: %d", payloadLen)
+	}
 	if payloadLen != 0 {
 		frame.Payload = make([]byte, payloadLen, protocol.MaxPacketBufferSize)
 		if _, err := io.ReadFull(r, frame.Payload); err != nil {
@@ -40,12 +69,70 @@ func ParseSourceSymbolFrame(r *bytes.Reader, _ protocol.Version) (*SourceSymbolF
 	return frame, nil
 }
 
+// draftSourceFPILen is the length of the Source FEC Payload ID of the draft wire format: a 32 bit source symbol ID.
+// Only the lower 32 bits of the SSID are sent, the receiver reconstructs the full SSID.
+const draftSourceFPILen = 4
+
+// parseDraftSourceSymbolFrame parses a SOURCE_SYMBOL frame in the draft wire format.
+func parseDraftSourceSymbolFrame(r *bytes.Reader, _ protocol.Version) (*SourceSymbolFrame, error) {
+	var fpi [draftSourceFPILen]byte
+	if _, err := io.ReadFull(r, fpi[:]); err != nil {
+		return nil, io.EOF
+	}
+	frame := &SourceSymbolFrame{
+		Format: protocol.FECWireFormatDraft,
+		SSID:   protocol.SourceSymbolID(binary.BigEndian.Uint32(fpi[:])),
+	}
+	payloadLen := r.Len()
+	if payloadLen > protocol.MaxFECPacketBufferSize {
+		return nil, fmt.Errorf("SOURCE_SYMBOL payload too large: %d", payloadLen)
+	}
+	if payloadLen != 0 {
+		frame.Payload = make([]byte, payloadLen, protocol.MaxPacketBufferSize)
+		if _, err := io.ReadFull(r, frame.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
 func (f *SourceSymbolFrame) HeaderLen() protocol.ByteCount {
-	return quicvarint.Len(uint64(sourceSymbolFrameType)) + quicvarint.Len(uint64(f.SSID)) + quicvarint.Len(uint64(len(f.Payload)))
+	return SourceSymbolFrameHeaderLen(f.Format, f.Class, f.SSID, protocol.ByteCount(len(f.Payload)))
+}
+
+// SourceSymbolFrameHeaderLen returns the length of the header of a SOURCE_SYMBOL frame with a payload of payloadLen bytes.
+// It allows calculating the size of the frame before its payload is serialized.
+func SourceSymbolFrameHeaderLen(format protocol.FECWireFormat, class protocol.FECProtectionClass, ssid protocol.SourceSymbolID, payloadLen protocol.ByteCount) protocol.ByteCount {
+	if format == protocol.FECWireFormatDraft {
+		return quicvarint.Len(draftSourceSymbolFrameType) + draftSourceFPILen
+	}
+	return fecFrameTypeLen(sourceSymbolFrameType, sourceSymbolWithClassFrameType, class) + quicvarint.Len(uint64(ssid)) + quicvarint.Len(uint64(payloadLen))
+}
+
+// MaxPayloadLen returns the maximum payload length, such that the frame is not larger than maxSize.
+func (f *SourceSymbolFrame) MaxPayloadLen(maxSize protocol.ByteCount, _ protocol.Version) protocol.ByteCount {
+	if f.Format == protocol.FECWireFormatDraft {
+		headerLen := f.HeaderLen()
+		if headerLen > maxSize {
+			return 0
+		}
+		return maxSize - headerLen
+	}
+	return maxLengthPrefixedLen(maxSize, fecFrameTypeLen(sourceSymbolFrameType, sourceSymbolWithClassFrameType, f.Class)+quicvarint.Len(uint64(f.SSID)))
 }
 
 func (f *SourceSymbolFrame) Append(b []byte, v protocol.Version) ([]byte, error) {
-	b = quicvarint.Append(b, uint64(sourceSymbolFrameType))
+	if f.Format == protocol.FECWireFormatDraft {
+		b = quicvarint.Append(b, draftSourceSymbolFrameType)
+		b = binary.BigEndian.AppendUint32(b, uint32(f.SSID))
+		return append(b, f.Payload...), nil
+	}
+	if f.Class == protocol.FECProtectionDefault {
+		b = quicvarint.Append(b, uint64(sourceSymbolFrameType))
+	} else {
+		b = quicvarint.Append(b, uint64(sourceSymbolWithClassFrameType))
+		b = quicvarint.Append(b, uint64(f.Class))
+	}
 	b = quicvarint.Append(b, uint64(f.SSID))
 	b = quicvarint.Append(b, uint64(len(f.Payload)))
 	b = append(b, f.Payload...)
@@ -54,7 +141,7 @@ func (f *SourceSymbolFrame) Append(b []byte, v protocol.Version) ([]byte, error)
 
 // Length of a written frame
 func (f *SourceSymbolFrame) Length(_ protocol.Version) protocol.ByteCount {
-	return quicvarint.Len(uint64(sourceSymbolFrameType)) + quicvarint.Len(uint64(f.SSID)) + quicvarint.Len(uint64(len(f.Payload))) + protocol.ByteCount(len(f.Payload))
+	return f.HeaderLen() + protocol.ByteCount(len(f.Payload))
 }
 
 /*
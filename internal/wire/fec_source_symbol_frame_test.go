@@ -0,0 +1,194 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SOURCE_SYMBOL frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0xdecafbad)        // SSID
+			data = append(data, encodeVarInt(6)...) // payload length
+			data = append(data, []byte("foobar")...)
+			r := bytes.NewReader(data)
+			frame, err := ParseSourceSymbolFrame(r, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SSID).To(Equal(protocol.SourceSymbolID(0xdecafbad)))
+			Expect(frame.Payload).To(Equal([]byte("foobar")))
+			Expect(r.Len()).To(BeZero())
+		})
+
+		It("rejects payloads that are too large", func() {
+			data := encodeVarInt(1)
+			data = append(data, encodeVarInt(protocol.MaxFECPacketBufferSize+1)...)
+			data = append(data, make([]byte, protocol.MaxFECPacketBufferSize+1)...)
+			_, err := ParseSourceSymbolFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError(fmt.Sprintf("SOURCE_SYMBOL payload too large: %d", protocol.MaxFECPacketBufferSize+1)))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdecafbad)
+			data = append(data, encodeVarInt(6)...)
+			data = append(data, []byte("foobar")...)
+			_, err := ParseSourceSymbolFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := ParseSourceSymbolFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a sample frame", func() {
+			f := &SourceSymbolFrame{SSID: 0x1337, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(sourceSymbolFrameType)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			Expect(f.HeaderLen()).To(BeEquivalentTo(len(b) - 6))
+		})
+
+		It("round-trips through the frame parser", func() {
+			f := &SourceSymbolFrame{SSID: quicvarint.Max, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			l, frame, err := NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(frame).To(Equal(f))
+		})
+
+		It("writes the protection class", func() {
+			f := &SourceSymbolFrame{Class: protocol.FECProtectionHigh, SSID: 0x1337, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(sourceSymbolWithClassFrameType)
+			expected = append(expected, encodeVarInt(uint64(protocol.FECProtectionHigh))...)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(6)...)
+			expected = append(expected, []byte("foobar")...)
+			Expect(b).To(Equal(expected))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			Expect(f.HeaderLen()).To(BeEquivalentTo(len(b) - 6))
+			l, frame, err := NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(frame).To(Equal(f))
+		})
+
+		It("doesn't exceed the FEC header overhead with a protection class", func() {
+			f := &SourceSymbolFrame{Class: protocol.MaxFECProtectionClass, SSID: quicvarint.Max}
+			Expect(f.HeaderLen()).To(BeNumerically("<=", protocol.MaxFECHeaderOverhead))
+		})
+
+		It("rejects unknown protection classes", func() {
+			b := encodeVarInt(sourceSymbolWithClassFrameType)
+			b = append(b, encodeVarInt(uint64(protocol.MaxFECProtectionClass)+1)...)
+			b = append(b, encodeVarInt(0x1337)...)
+			b = append(b, encodeVarInt(0)...)
+			_, _, err := NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).To(MatchError(ContainSubstring("invalid FEC protection class")))
+		})
+	})
+
+	Context("in the draft wire format", func() {
+		// This frame was encoded by hand from the frame format of draft-michel-quic-fec,
+		// using the provisional frame type 0xfec0. It is not a test vector of the draft.
+		testVector := []byte{
+			0x80, 0x00, 0xfe, 0xc0, // frame type
+			0x00, 0x00, 0x13, 0x37, // source symbol ID
+			'f', 'o', 'o', 'b', 'a', 'r', // the payload, up to the end of the packet
+		}
+
+		It("writes a frame", func() {
+			f := &SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: 0x1337, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(testVector))
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			Expect(f.HeaderLen()).To(BeEquivalentTo(len(b) - 6))
+			Expect(f.HeaderLen()).To(BeNumerically("<=", protocol.MaxFECHeaderOverhead))
+		})
+
+		It("parses a frame", func() {
+			l, frame, err := NewFrameParser(false).ParseNext(testVector, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(testVector)))
+			Expect(frame).To(Equal(&SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: 0x1337, Payload: []byte("foobar")}))
+		})
+
+		It("ignores the protection class", func() {
+			f := &SourceSymbolFrame{Format: protocol.FECWireFormatDraft, Class: protocol.FECProtectionHigh, SSID: 0x1337, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(testVector))
+		})
+
+		It("rejects payloads that are too large", func() {
+			data := append([]byte{0x00, 0x00, 0x13, 0x37}, make([]byte, protocol.MaxFECPacketBufferSize+1)...)
+			_, err := parseDraftSourceSymbolFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError(fmt.Sprintf("SOURCE_SYMBOL payload too large: %d", protocol.MaxFECPacketBufferSize+1)))
+		})
+
+		It("errors on EOFs", func() {
+			for i := 0; i < draftSourceFPILen; i++ {
+				_, err := parseDraftSourceSymbolFrame(bytes.NewReader(testVector[4:4+i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+
+		It("only writes the lower 32 bits of the source symbol ID", func() {
+			f := &SourceSymbolFrame{Format: protocol.FECWireFormatDraft, SSID: 1<<32 + 0x1337, Payload: []byte("foobar")}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(testVector))
+		})
+	})
+	Context("max payload length", func() {
+		for _, format := range []protocol.FECWireFormat{protocol.FECWireFormatLegacy, protocol.FECWireFormatDraft} {
+			format := format
+
+			It(fmt.Sprintf("always returns a payload length such that the resulting frame has the right size, in the %s wire format", format), func() {
+				data := make([]byte, 3000)
+				f := &SourceSymbolFrame{Format: format, SSID: 0x1337}
+				var frameOneByteTooSmallCounter int
+				for i := 1; i < 3000; i++ {
+					maxPayloadLen := f.MaxPayloadLen(protocol.ByteCount(i), protocol.Version1)
+					if maxPayloadLen == 0 {
+						f.Payload = nil
+						Expect(f.Length(protocol.Version1)).To(BeNumerically(">=", i))
+						continue
+					}
+					f.Payload = data[:int(maxPayloadLen)]
+					b, err := f.Append(nil, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					// a payload length of x can be encoded into 1 byte, but x+1 needs 2 bytes
+					if len(b) == i-1 {
+						frameOneByteTooSmallCounter++
+						continue
+					}
+					Expect(len(b)).To(Equal(i))
+				}
+				if format == protocol.FECWireFormatDraft {
+					// the payload extends to the end of the packet, so the frame doesn't contain a length
+					Expect(frameOneByteTooSmallCounter).To(BeZero())
+				} else {
+					Expect(frameOneByteTooSmallCounter).To(Equal(1))
+				}
+			})
+		}
+	})
+})
@@ -2,6 +2,8 @@ package wire
 
 import (
 	"bytes"
+	"fmt"
+	"math"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/quicvarint"
@@ -19,11 +21,17 @@ func parseFECWindowFrame(r *bytes.Reader, _ protocol.Version) (*FECWindowFrame,
 	if err != nil {
 		return nil, err
 	}
+	if epoch > math.MaxUint16 {
+		return nil, fmt.Errorf("FEC window epoch too large: %d", epoch)
+	}
 	f.Epoch = protocol.FECWindowEpoch(epoch)
 	size, err := quicvarint.Read(r)
 	if err != nil {
 		return nil, err
 	}
+	if size > math.MaxUint32 {
+		return nil, fmt.Errorf("FEC window size too large: %d", size)
+	}
 	f.Size = protocol.FECWindowSize(size)
 	return f, nil
 }
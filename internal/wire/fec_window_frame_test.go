@@ -0,0 +1,65 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"math"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FEC_WINDOW frame", func() {
+	Context("parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x42)
+			data = append(data, encodeVarInt(0xdecafbad)...)
+			r := bytes.NewReader(data)
+			frame, err := parseFECWindowFrame(r, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.Epoch).To(Equal(protocol.FECWindowEpoch(0x42)))
+			Expect(frame.Size).To(Equal(protocol.FECWindowSize(0xdecafbad)))
+			Expect(r.Len()).To(BeZero())
+		})
+
+		It("rejects a too large epoch", func() {
+			data := encodeVarInt(math.MaxUint16 + 1)
+			data = append(data, encodeVarInt(1)...)
+			_, err := parseFECWindowFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("FEC window epoch too large: 65536"))
+		})
+
+		It("rejects a too large window size", func() {
+			data := encodeVarInt(1)
+			data = append(data, encodeVarInt(math.MaxUint32+1)...)
+			_, err := parseFECWindowFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("FEC window size too large: 4294967296"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0x42)
+			data = append(data, encodeVarInt(0xdecafbad)...)
+			_, err := parseFECWindowFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseFECWindowFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("round-trips through the frame parser", func() {
+			f := &FECWindowFrame{Epoch: math.MaxUint16, Size: math.MaxUint32}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+			l, frame, err := NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(b)))
+			Expect(frame).To(Equal(f))
+		})
+	})
+})
@@ -35,10 +35,37 @@ const (
 	applicationCloseFrameType   = 0x1d
 	handshakeDoneFrameType      = 0x1e
 
+	// frames defined by draft-ietf-quic-ack-frequency
+	immediateAckFrameType = 0x1f
+	ackFrequencyFrameType = 0xaf
+
+	// frame defined by draft-ietf-quic-reliable-stream-reset
+	resetStreamAtFrameType = 0x24
+
 	repairFrameType       = 0x32a80fec
 	sourceSymbolFrameType = 0x32a80fec55
 	symbolACKFrameType    = 0x32a80fecac
 	FECWindowFrameType    = 0x32a80fecc0
+	// REPAIR and SOURCE_SYMBOL frames of a protection class other than the default one carry the class.
+	// Their frame types fit into a 4 byte varint, so the class doesn't increase the FEC header overhead.
+	repairWithClassFrameType       = 0x32a80fed
+	sourceSymbolWithClassFrameType = 0x32a80fee
+	// REPAIR and SOURCE_SYMBOL frames using the wire format of draft-michel-quic-fec.
+	// The frame types are provisional, they might change with future versions of the draft.
+	draftRepairFrameType       = 0xfec1
+	draftSourceSymbolFrameType = 0xfec0
+
+	// frames defined by draft-ietf-quic-multipath
+	pathAckFrameType                = 0x15228c00
+	pathAckECNFrameType             = 0x15228c01
+	pathAbandonFrameType            = 0x15228c05
+	pathStatusBackupFrameType       = 0x15228c07
+	pathStatusAvailableFrameType    = 0x15228c08
+	pathNewConnectionIDFrameType    = 0x15228c09
+	pathRetireConnectionIDFrameType = 0x15228c0a
+	maxPathIDFrameType              = 0x15228c0c
+	pathsBlockedFrameType           = 0x15228c0d
+	pathCIDsBlockedFrameType        = 0x15228c0e
 )
 
 // The FrameParser parses QUIC frames, one by one.
@@ -47,6 +74,11 @@ type FrameParser struct {
 
 	ackDelayExponent  uint8
 	supportsDatagrams bool
+	supportsMultipath bool
+	// set if we advertised the min_ack_delay transport parameter
+	supportsAckFrequency bool
+	// set if we advertised the reset_stream_at transport parameter
+	supportsResetStreamAt bool
 
 	// To avoid allocating when parsing, keep a single ACK frame struct.
 	// It is used over and over again.
@@ -102,7 +134,7 @@ func (p *FrameParser) parseNext(r *bytes.Reader, encLevel protocol.EncryptionLev
 func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.EncryptionLevel, v protocol.Version) (Frame, error) {
 	var frame Frame
 	var err error
-	if typ&0xf8 == 0x8 {
+	if typ&^0x7 == 0x8 {
 		frame, err = parseStreamFrame(r, typ, v)
 	} else {
 		switch typ {
@@ -117,7 +149,13 @@ func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.
 			err = parseAckFrame(p.ackFrame, r, typ, ackDelayExponent, v)
 			frame = p.ackFrame
 		case resetStreamFrameType:
-			frame, err = parseResetStreamFrame(r, v)
+			frame, err = parseResetStreamFrame(r, typ, v)
+		case resetStreamAtFrameType:
+			if p.supportsResetStreamAt {
+				frame, err = parseResetStreamFrame(r, typ, v)
+				break
+			}
+			err = errors.New("unknown frame type")
 		case stopSendingFrameType:
 			frame, err = parseStopSendingFrame(r, v)
 		case cryptoFrameType:
@@ -148,12 +186,26 @@ func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.
 			frame, err = parseConnectionCloseFrame(r, typ, v)
 		case handshakeDoneFrameType:
 			frame = &HandshakeDoneFrame{}
-		case repairFrameType:
-			frame, err = parseRepairFrame(r, v)
-		case sourceSymbolFrameType:
-			frame, err = ParseSourceSymbolFrame(r, v)
+		case repairFrameType, repairWithClassFrameType:
+			frame, err = parseRepairFrame(r, typ, v)
+		case sourceSymbolFrameType, sourceSymbolWithClassFrameType:
+			frame, err = parseSourceSymbolFrame(r, typ, v)
+		case draftRepairFrameType:
+			frame, err = parseDraftRepairFrame(r, v)
+		case draftSourceSymbolFrameType:
+			frame, err = parseDraftSourceSymbolFrame(r, v)
 		case FECWindowFrameType:
 			frame, err = parseFECWindowFrame(r, v)
+		case ackFrequencyFrameType, immediateAckFrameType:
+			if p.supportsAckFrequency {
+				if typ == ackFrequencyFrameType {
+					frame, err = parseAckFrequencyFrame(r, v)
+				} else {
+					frame = &ImmediateAckFrame{}
+				}
+				break
+			}
+			err = errors.New("unknown frame type")
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, err = parseDatagramFrame(r, typ, v)
@@ -161,6 +213,10 @@ func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.
 			}
 			fallthrough
 		default:
+			if p.supportsMultipath {
+				frame, err = p.parseMultipathFrame(r, typ, v)
+				break
+			}
 			err = errors.New("unknown frame type")
 		}
 	}
@@ -173,6 +229,29 @@ func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.
 	return frame, nil
 }
 
+func (p *FrameParser) parseMultipathFrame(r *bytes.Reader, typ uint64, v protocol.Version) (Frame, error) {
+	switch typ {
+	case pathAckFrameType, pathAckECNFrameType:
+		return parsePathAckFrame(r, typ, p.ackDelayExponent, v)
+	case pathAbandonFrameType:
+		return parsePathAbandonFrame(r, v)
+	case pathStatusBackupFrameType, pathStatusAvailableFrameType:
+		return parsePathStatusFrame(r, typ, v)
+	case pathNewConnectionIDFrameType:
+		return parsePathNewConnectionIDFrame(r, v)
+	case pathRetireConnectionIDFrameType:
+		return parsePathRetireConnectionIDFrame(r, v)
+	case maxPathIDFrameType:
+		return parseMaxPathIDFrame(r, v)
+	case pathsBlockedFrameType:
+		return parsePathsBlockedFrame(r, v)
+	case pathCIDsBlockedFrameType:
+		return parsePathCIDsBlockedFrame(r, v)
+	default:
+		return nil, errors.New("unknown frame type")
+	}
+}
+
 func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionLevel) bool {
 	switch encLevel {
 	case protocol.EncryptionInitial, protocol.EncryptionHandshake:
@@ -186,6 +265,13 @@ func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 		switch f.(type) {
 		case *CryptoFrame, *AckFrame, *ConnectionCloseFrame, *NewTokenFrame, *PathResponseFrame, *RetireConnectionIDFrame:
 			return false
+		case *PathAckFrame, *PathAbandonFrame, *PathStatusFrame, *PathNewConnectionIDFrame, *PathRetireConnectionIDFrame,
+			*MaxPathIDFrame, *PathsBlockedFrame, *PathCIDsBlockedFrame:
+			// multipath is only negotiated once the handshake completes
+			return false
+		case *AckFrequencyFrame, *ImmediateAckFrame:
+			// the peer only learns that we support the ACK frequency extension during the handshake
+			return false
 		default:
 			return true
 		}
@@ -196,6 +282,21 @@ func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 	}
 }
 
+// SetSupportsMultipath enables parsing of the frames defined by draft-ietf-quic-multipath.
+func (p *FrameParser) SetSupportsMultipath(b bool) {
+	p.supportsMultipath = b
+}
+
+// SetSupportsAckFrequency enables parsing of the frames defined by draft-ietf-quic-ack-frequency.
+func (p *FrameParser) SetSupportsAckFrequency(b bool) {
+	p.supportsAckFrequency = b
+}
+
+// SetSupportsResetStreamAt enables parsing of the RESET_STREAM_AT frame defined by draft-ietf-quic-reliable-stream-reset.
+func (p *FrameParser) SetSupportsResetStreamAt(b bool) {
+	p.supportsResetStreamAt = b
+}
+
 // SetAckDelayExponent sets the acknowledgment delay exponent (sent in the transport parameters).
 // This value is used to scale the ACK Delay field in the ACK frame.
 func (p *FrameParser) SetAckDelayExponent(exp uint8) {
@@ -327,6 +327,91 @@ var _ = Describe("Frame parsing", func() {
 		}))
 	})
 
+	It("unpacks multipath frames", func() {
+		parser.SetSupportsMultipath(true)
+		for _, f := range []Frame{
+			&PathAckFrame{PathID: 1, AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 42}}}},
+			&PathAbandonFrame{PathID: 2, ErrorCode: 3},
+			&PathStatusFrame{PathID: 3, StatusSequenceNumber: 4, Backup: true},
+			&PathStatusFrame{PathID: 3, StatusSequenceNumber: 5},
+			&PathNewConnectionIDFrame{PathID: 4, NewConnectionIDFrame: NewConnectionIDFrame{ConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4})}},
+			&PathRetireConnectionIDFrame{PathID: 5, SequenceNumber: 6},
+			&MaxPathIDFrame{MaxPathID: 7},
+			&PathsBlockedFrame{MaxPathID: 8},
+			&PathCIDsBlockedFrame{PathID: 9, NextSequenceNumber: 10},
+		} {
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(f))
+			Expect(l).To(Equal(len(b)))
+			// multipath frames are only allowed in 1-RTT packets
+			_, _, err = parser.ParseNext(b, protocol.Encryption0RTT, protocol.Version1)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("errors when multipath is not supported", func() {
+		b, err := (&MaxPathIDFrame{MaxPathID: 7}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    maxPathIDFrameType,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("unpacks ACK frequency frames", func() {
+		parser.SetSupportsAckFrequency(true)
+		for _, f := range []Frame{
+			&AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 2, RequestMaxAckDelay: 3 * time.Millisecond, ReorderingThreshold: 4},
+			&ImmediateAckFrame{},
+		} {
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(f))
+			Expect(l).To(Equal(len(b)))
+			// ACK frequency frames are only allowed in 1-RTT packets
+			_, _, err = parser.ParseNext(b, protocol.Encryption0RTT, protocol.Version1)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("errors when the ACK frequency extension is not supported", func() {
+		_, _, err := parser.ParseNext([]byte{immediateAckFrameType}, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    immediateAckFrameType,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
+	It("unpacks RESET_STREAM_AT frames", func() {
+		parser.SetSupportsResetStreamAt(true)
+		f := &ResetStreamFrame{StreamID: 0x42, ErrorCode: 0x1337, FinalSize: 0xdeadbeef, ReliableSize: 0xcafe}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when the RESET_STREAM_AT extension is not supported", func() {
+		b, err := (&ResetStreamFrame{StreamID: 0x42, FinalSize: 0x1337, ReliableSize: 0x10}).Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    resetStreamAtFrameType,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
 	It("errors on invalid type", func() {
 		_, _, err := parser.ParseNext(encodeVarInt(0x42), protocol.Encryption1RTT, protocol.Version1)
 		Expect(err).To(MatchError(&qerr.TransportError{
@@ -0,0 +1,18 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMMEDIATE_ACK frame", func() {
+	It("writes a sample frame", func() {
+		frame := ImmediateAckFrame{}
+		b, err := frame.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(Equal([]byte{immediateAckFrameType}))
+		Expect(frame.Length(protocol.Version1)).To(Equal(protocol.ByteCount(len(b))))
+	})
+})
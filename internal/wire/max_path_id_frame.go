@@ -0,0 +1,32 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A MaxPathIDFrame is a MAX_PATH_ID frame
+type MaxPathIDFrame struct {
+	MaxPathID protocol.PathID
+}
+
+func parseMaxPathIDFrame(r *bytes.Reader, _ protocol.Version) (*MaxPathIDFrame, error) {
+	id, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxPathIDFrame{MaxPathID: id}, nil
+}
+
+func (f *MaxPathIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, maxPathIDFrameType)
+	b = quicvarint.Append(b, uint64(f.MaxPathID))
+	return b, nil
+}
+
+// Length of a written frame
+func (f *MaxPathIDFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(maxPathIDFrameType) + quicvarint.Len(uint64(f.MaxPathID))
+}
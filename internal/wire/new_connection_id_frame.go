@@ -59,6 +59,12 @@ func parseNewConnectionIDFrame(r *bytes.Reader, _ protocol.Version) (*NewConnect
 
 func (f *NewConnectionIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
 	b = append(b, newConnectionIDFrameType)
+	return f.appendBody(b)
+}
+
+// appendBody appends everything following the frame type.
+// It is shared with the PATH_NEW_CONNECTION_ID frame.
+func (f *NewConnectionIDFrame) appendBody(b []byte) ([]byte, error) {
 	b = quicvarint.Append(b, f.SequenceNumber)
 	b = quicvarint.Append(b, f.RetirePriorTo)
 	connIDLen := f.ConnectionID.Len()
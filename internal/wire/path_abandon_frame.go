@@ -0,0 +1,39 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAbandonFrame is a PATH_ABANDON frame
+type PathAbandonFrame struct {
+	PathID    protocol.PathID
+	ErrorCode qerr.TransportErrorCode
+}
+
+func parsePathAbandonFrame(r *bytes.Reader, _ protocol.Version) (*PathAbandonFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	errorCode, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PathAbandonFrame{PathID: pathID, ErrorCode: qerr.TransportErrorCode(errorCode)}, nil
+}
+
+func (f *PathAbandonFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathAbandonFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, uint64(f.ErrorCode))
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathAbandonFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathAbandonFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(uint64(f.ErrorCode))
+}
@@ -0,0 +1,58 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+var errInvalidPathID = errors.New("invalid path ID")
+
+// A PathAckFrame is a PATH_ACK frame.
+// It acknowledges packets sent on the path with the given path ID.
+type PathAckFrame struct {
+	PathID protocol.PathID
+	AckFrame
+}
+
+func parsePathAckFrame(r *bytes.Reader, typ uint64, ackDelayExponent uint8, v protocol.Version) (*PathAckFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	f := &PathAckFrame{PathID: pathID}
+	if err := parseAckFrame(&f.AckFrame, r, typ, ackDelayExponent, v); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Append appends a PATH_ACK frame.
+func (f *PathAckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	if f.hasECN() {
+		b = quicvarint.Append(b, pathAckECNFrameType)
+	} else {
+		b = quicvarint.Append(b, pathAckFrameType)
+	}
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return f.appendBody(b), nil
+}
+
+// Length of a written frame
+func (f *PathAckFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathAckFrameType) + quicvarint.Len(uint64(f.PathID)) + f.bodyLength()
+}
+
+func readPathID(r *bytes.Reader) (protocol.PathID, error) {
+	id, err := quicvarint.Read(r)
+	if err != nil {
+		return 0, err
+	}
+	if id > math.MaxUint32 {
+		return 0, errInvalidPathID
+	}
+	return protocol.PathID(id), nil
+}
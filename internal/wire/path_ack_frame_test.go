@@ -0,0 +1,56 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_ACK frame", func() {
+	It("writes and parses a sample frame", func() {
+		f := &PathAckFrame{PathID: 3, AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 80, Largest: 100}, {Smallest: 10, Largest: 50}}, ECT0: 1, ECT1: 2, ECNCE: 3}}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		typ, err := quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		frame, err := parsePathAckFrame(r, typ, protocol.AckDelayExponent, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		f := &PathAckFrame{PathID: 3, AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 80, Largest: 100}, {Smallest: 10, Largest: 50}}, ECT0: 1, ECT1: 2, ECNCE: 3}}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		typeLen := quicvarint.Len(pathAckECNFrameType)
+		for i := int(typeLen); i < len(b); i++ {
+			r := bytes.NewReader(b[:i])
+			typ, err := quicvarint.Read(r)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = parsePathAckFrame(r, typ, protocol.AckDelayExponent, protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		}
+	})
+
+	It("uses the frame type without ECN counts", func() {
+		f := &PathAckFrame{PathID: 1, AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}}}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		typ, err := quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(typ).To(BeEquivalentTo(pathAckFrameType))
+		frame, err := parsePathAckFrame(r, typ, protocol.AckDelayExponent, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+})
@@ -0,0 +1,38 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathCIDsBlockedFrame is a PATH_CIDS_BLOCKED frame
+type PathCIDsBlockedFrame struct {
+	PathID             protocol.PathID
+	NextSequenceNumber uint64
+}
+
+func parsePathCIDsBlockedFrame(r *bytes.Reader, _ protocol.Version) (*PathCIDsBlockedFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PathCIDsBlockedFrame{PathID: pathID, NextSequenceNumber: seq}, nil
+}
+
+func (f *PathCIDsBlockedFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathCIDsBlockedFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.NextSequenceNumber)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathCIDsBlockedFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathCIDsBlockedFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.NextSequenceNumber)
+}
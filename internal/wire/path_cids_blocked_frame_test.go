@@ -0,0 +1,42 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_CIDS_BLOCKED frame", func() {
+	It("writes and parses a sample frame", func() {
+		f := &PathCIDsBlockedFrame{PathID: 2, NextSequenceNumber: 1337}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		_, err = quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		frame, err := parsePathCIDsBlockedFrame(r, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		f := &PathCIDsBlockedFrame{PathID: 2, NextSequenceNumber: 1337}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		typeLen := quicvarint.Len(pathCIDsBlockedFrameType)
+		for i := int(typeLen); i < len(b); i++ {
+			r := bytes.NewReader(b[:i])
+			_, err := quicvarint.Read(r)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = parsePathCIDsBlockedFrame(r, protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		}
+	})
+})
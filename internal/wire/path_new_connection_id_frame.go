@@ -0,0 +1,38 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathNewConnectionIDFrame is a PATH_NEW_CONNECTION_ID frame.
+// It provides a connection ID for the path with the given path ID.
+type PathNewConnectionIDFrame struct {
+	PathID protocol.PathID
+	NewConnectionIDFrame
+}
+
+func parsePathNewConnectionIDFrame(r *bytes.Reader, v protocol.Version) (*PathNewConnectionIDFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseNewConnectionIDFrame(r, v)
+	if err != nil {
+		return nil, err
+	}
+	return &PathNewConnectionIDFrame{PathID: pathID, NewConnectionIDFrame: *f}, nil
+}
+
+func (f *PathNewConnectionIDFrame) Append(b []byte, v protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathNewConnectionIDFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return f.NewConnectionIDFrame.appendBody(b)
+}
+
+// Length of a written frame
+func (f *PathNewConnectionIDFrame) Length(v protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathNewConnectionIDFrameType) + quicvarint.Len(uint64(f.PathID)) + f.NewConnectionIDFrame.Length(v) - 1
+}
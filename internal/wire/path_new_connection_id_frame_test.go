@@ -0,0 +1,42 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_NEW_CONNECTION_ID frame", func() {
+	It("writes and parses a sample frame", func() {
+		f := &PathNewConnectionIDFrame{PathID: 5, NewConnectionIDFrame: NewConnectionIDFrame{SequenceNumber: 10, RetirePriorTo: 3, ConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4}), StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		_, err = quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		frame, err := parsePathNewConnectionIDFrame(r, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		f := &PathNewConnectionIDFrame{PathID: 5, NewConnectionIDFrame: NewConnectionIDFrame{SequenceNumber: 10, RetirePriorTo: 3, ConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4}), StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}}}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		typeLen := quicvarint.Len(pathNewConnectionIDFrameType)
+		for i := int(typeLen); i < len(b); i++ {
+			r := bytes.NewReader(b[:i])
+			_, err := quicvarint.Read(r)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = parsePathNewConnectionIDFrame(r, protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		}
+	})
+})
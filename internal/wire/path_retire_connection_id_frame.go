@@ -0,0 +1,38 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathRetireConnectionIDFrame is a PATH_RETIRE_CONNECTION_ID frame
+type PathRetireConnectionIDFrame struct {
+	PathID         protocol.PathID
+	SequenceNumber uint64
+}
+
+func parsePathRetireConnectionIDFrame(r *bytes.Reader, _ protocol.Version) (*PathRetireConnectionIDFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PathRetireConnectionIDFrame{PathID: pathID, SequenceNumber: seq}, nil
+}
+
+func (f *PathRetireConnectionIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathRetireConnectionIDFrameType)
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.SequenceNumber)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathRetireConnectionIDFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathRetireConnectionIDFrameType) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.SequenceNumber)
+}
@@ -0,0 +1,51 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathStatusFrame is a PATH_STATUS_BACKUP or a PATH_STATUS_AVAILABLE frame.
+// Backup paths are only used if no available path can be used.
+type PathStatusFrame struct {
+	PathID               protocol.PathID
+	StatusSequenceNumber uint64
+	Backup               bool
+}
+
+func parsePathStatusFrame(r *bytes.Reader, typ uint64, _ protocol.Version) (*PathStatusFrame, error) {
+	pathID, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PathStatusFrame{
+		PathID:               pathID,
+		StatusSequenceNumber: seq,
+		Backup:               typ == pathStatusBackupFrameType,
+	}, nil
+}
+
+func (f *PathStatusFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, f.frameType())
+	b = quicvarint.Append(b, uint64(f.PathID))
+	b = quicvarint.Append(b, f.StatusSequenceNumber)
+	return b, nil
+}
+
+func (f *PathStatusFrame) frameType() uint64 {
+	if f.Backup {
+		return pathStatusBackupFrameType
+	}
+	return pathStatusAvailableFrameType
+}
+
+// Length of a written frame
+func (f *PathStatusFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(f.frameType()) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.StatusSequenceNumber)
+}
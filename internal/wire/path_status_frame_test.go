@@ -0,0 +1,55 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PATH_STATUS frame", func() {
+	It("writes and parses a sample frame", func() {
+		f := &PathStatusFrame{PathID: 1, StatusSequenceNumber: 9, Backup: true}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		r := bytes.NewReader(b)
+		typ, err := quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		frame, err := parsePathStatusFrame(r, typ, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("errors on EOFs", func() {
+		f := &PathStatusFrame{PathID: 1, StatusSequenceNumber: 9, Backup: true}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		typeLen := quicvarint.Len(pathStatusBackupFrameType)
+		for i := int(typeLen); i < len(b); i++ {
+			r := bytes.NewReader(b[:i])
+			typ, err := quicvarint.Read(r)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = parsePathStatusFrame(r, typ, protocol.Version1)
+			Expect(err).To(MatchError(io.EOF))
+		}
+	})
+
+	It("tells PATH_STATUS_AVAILABLE from PATH_STATUS_BACKUP frames", func() {
+		f := &PathStatusFrame{PathID: 1, StatusSequenceNumber: 10}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		r := bytes.NewReader(b)
+		typ, err := quicvarint.Read(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(typ).To(BeEquivalentTo(pathStatusAvailableFrameType))
+		frame, err := parsePathStatusFrame(r, typ, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame.Backup).To(BeFalse())
+	})
+})
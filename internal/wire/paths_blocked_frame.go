@@ -0,0 +1,32 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathsBlockedFrame is a PATHS_BLOCKED frame
+type PathsBlockedFrame struct {
+	MaxPathID protocol.PathID
+}
+
+func parsePathsBlockedFrame(r *bytes.Reader, _ protocol.Version) (*PathsBlockedFrame, error) {
+	id, err := readPathID(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PathsBlockedFrame{MaxPathID: id}, nil
+}
+
+func (f *PathsBlockedFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, pathsBlockedFrameType)
+	b = quicvarint.Append(b, uint64(f.MaxPathID))
+	return b, nil
+}
+
+// Length of a written frame
+func (f *PathsBlockedFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(pathsBlockedFrameType) + quicvarint.Len(uint64(f.MaxPathID))
+}
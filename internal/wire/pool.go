@@ -21,6 +21,7 @@ func GetStreamFrame() *StreamFrame {
 	f := pool.Get().(*StreamFrame)
 	// This ensures a stream frame will never be retrieved in which FECProtected is set to true.
 	f.FECProtected = false
+	f.FECProtectionClass = protocol.FECProtectionDefault
 	return f
 }
 
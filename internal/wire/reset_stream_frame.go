@@ -2,20 +2,23 @@ package wire
 
 import (
 	"bytes"
+	"errors"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
-// A ResetStreamFrame is a RESET_STREAM frame in QUIC
+// A ResetStreamFrame is a RESET_STREAM frame in QUIC.
+// If ReliableSize is non-zero, it is sent as a RESET_STREAM_AT frame (draft-ietf-quic-reliable-stream-reset).
 type ResetStreamFrame struct {
-	StreamID  protocol.StreamID
-	ErrorCode qerr.StreamErrorCode
-	FinalSize protocol.ByteCount
+	StreamID     protocol.StreamID
+	ErrorCode    qerr.StreamErrorCode
+	FinalSize    protocol.ByteCount
+	ReliableSize protocol.ByteCount
 }
 
-func parseResetStreamFrame(r *bytes.Reader, _ protocol.Version) (*ResetStreamFrame, error) {
+func parseResetStreamFrame(r *bytes.Reader, typ uint64, _ protocol.Version) (*ResetStreamFrame, error) {
 	var streamID protocol.StreamID
 	var byteOffset protocol.ByteCount
 	sid, err := quicvarint.Read(r)
@@ -32,23 +35,46 @@ func parseResetStreamFrame(r *bytes.Reader, _ protocol.Version) (*ResetStreamFra
 		return nil, err
 	}
 	byteOffset = protocol.ByteCount(bo)
+	var reliableSize protocol.ByteCount
+	if typ == resetStreamAtFrameType {
+		rs, err := quicvarint.Read(r)
+		if err != nil {
+			return nil, err
+		}
+		reliableSize = protocol.ByteCount(rs)
+		if reliableSize > byteOffset {
+			return nil, errors.New("RESET_STREAM_AT: reliable size can't be larger than the final size")
+		}
+	}
 
 	return &ResetStreamFrame{
-		StreamID:  streamID,
-		ErrorCode: qerr.StreamErrorCode(errorCode),
-		FinalSize: byteOffset,
+		StreamID:     streamID,
+		ErrorCode:    qerr.StreamErrorCode(errorCode),
+		FinalSize:    byteOffset,
+		ReliableSize: reliableSize,
 	}, nil
 }
 
 func (f *ResetStreamFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	b = append(b, resetStreamFrameType)
+	if f.ReliableSize > 0 {
+		b = append(b, resetStreamAtFrameType)
+	} else {
+		b = append(b, resetStreamFrameType)
+	}
 	b = quicvarint.Append(b, uint64(f.StreamID))
 	b = quicvarint.Append(b, uint64(f.ErrorCode))
 	b = quicvarint.Append(b, uint64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		b = quicvarint.Append(b, uint64(f.ReliableSize))
+	}
 	return b, nil
 }
 
 // Length of a written frame
 func (f *ResetStreamFrame) Length(version protocol.Version) protocol.ByteCount {
-	return 1 + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize))
+	l := 1 + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		l += quicvarint.Len(uint64(f.ReliableSize))
+	}
+	return l
 }
@@ -18,7 +18,7 @@ var _ = Describe("RESET_STREAM frame", func() {
 			data = append(data, encodeVarInt(0x1337)...)      // error code
 			data = append(data, encodeVarInt(0x987654321)...) // byte offset
 			b := bytes.NewReader(data)
-			frame, err := parseResetStreamFrame(b, protocol.Version1)
+			frame, err := parseResetStreamFrame(b, resetStreamFrameType, protocol.Version1)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
 			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
@@ -29,10 +29,45 @@ var _ = Describe("RESET_STREAM frame", func() {
 			data := encodeVarInt(0xdeadbeef)                  // stream ID
 			data = append(data, encodeVarInt(0x1337)...)      // error code
 			data = append(data, encodeVarInt(0x987654321)...) // byte offset
-			_, err := parseResetStreamFrame(bytes.NewReader(data), protocol.Version1)
+			_, err := parseResetStreamFrame(bytes.NewReader(data), resetStreamFrameType, protocol.Version1)
 			Expect(err).NotTo(HaveOccurred())
 			for i := range data {
-				_, err := parseResetStreamFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				_, err := parseResetStreamFrame(bytes.NewReader(data[:i]), resetStreamFrameType, protocol.Version1)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+
+		It("accepts a RESET_STREAM_AT frame", func() {
+			data := encodeVarInt(0xdeadbeef)               // stream ID
+			data = append(data, encodeVarInt(0x1337)...)   // error code
+			data = append(data, encodeVarInt(0x123456)...) // final size
+			data = append(data, encodeVarInt(0x1234)...)   // reliable size
+			frame, err := parseResetStreamFrame(bytes.NewReader(data), resetStreamAtFrameType, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x123456)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x1234)))
+		})
+
+		It("errors on RESET_STREAM_AT frames with a reliable size larger than the final size", func() {
+			data := encodeVarInt(0xdeadbeef)             // stream ID
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(100)...)    // final size
+			data = append(data, encodeVarInt(101)...)    // reliable size
+			_, err := parseResetStreamFrame(bytes.NewReader(data), resetStreamAtFrameType, protocol.Version1)
+			Expect(err).To(MatchError("RESET_STREAM_AT: reliable size can't be larger than the final size"))
+		})
+
+		It("errors on EOFs in RESET_STREAM_AT frames", func() {
+			data := encodeVarInt(0xdeadbeef)             // stream ID
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(1000)...)   // final size
+			data = append(data, encodeVarInt(100)...)    // reliable size
+			_, err := parseResetStreamFrame(bytes.NewReader(data), resetStreamAtFrameType, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseResetStreamFrame(bytes.NewReader(data[:i]), resetStreamAtFrameType, protocol.Version1)
 				Expect(err).To(HaveOccurred())
 			}
 		})
@@ -63,5 +98,23 @@ var _ = Describe("RESET_STREAM frame", func() {
 			expectedLen := 1 + quicvarint.Len(0x1337) + quicvarint.Len(0x1234567) + 2
 			Expect(rst.Length(protocol.Version1)).To(Equal(expectedLen))
 		})
+
+		It("writes a RESET_STREAM_AT frame", func() {
+			frame := ResetStreamFrame{
+				StreamID:     0x1337,
+				FinalSize:    0x123456,
+				ErrorCode:    0xcafe,
+				ReliableSize: 0x42,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{resetStreamAtFrameType}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x123456)...)
+			expected = append(expected, encodeVarInt(0x42)...)
+			Expect(b).To(Equal(expected))
+			Expect(frame.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
 	})
 })
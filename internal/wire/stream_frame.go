@@ -21,6 +21,8 @@ type StreamFrame struct {
 
 	// This value is not sent over the wire. Instead, it is used as a flag during the packet_packer process.
 	FECProtected bool
+	// FECProtectionClass is the protection class of a FEC protected frame. It is not sent over the wire either.
+	FECProtectionClass protocol.FECProtectionClass
 }
 
 func parseStreamFrame(r *bytes.Reader, typ uint64, _ protocol.Version) (*StreamFrame, error) {
@@ -62,7 +62,7 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			MaxDatagramFrameSize:            876,
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, EnableFEC: 1, DecoderFECScheme: XOR, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, EnableFEC: 1, DecoderFECScheme: XOR, FECWireFormat: legacy, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -83,7 +83,7 @@ var _ = Describe("Transport Parameters", func() {
 			DecoderFECScheme:                protocol.XORFECScheme,
 			MaxDatagramFrameSize:            protocol.InvalidByteCount,
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: (empty), InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37s, ActiveConnectionIDLimit: 89, EnableFEC: 1, DecoderFECScheme: XOR}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: (empty), InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37s, ActiveConnectionIDLimit: 89, EnableFEC: 1, DecoderFECScheme: XOR, FECWireFormat: legacy}"))
 	})
 
 	It("marshals and unmarshals", func() {
@@ -424,6 +424,198 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	Context("FEC", func() {
+		It("marshals and unmarshals the FEC parameters in the legacy wire format", func() {
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				EnableFEC:               0x1,
+				DecoderFECScheme:        protocol.ReedSolomonFECScheme,
+			}
+			data := params.Marshal(protocol.PerspectiveServer)
+			// enable_fec (0x238ffece01), with a value of 1
+			Expect(data).To(ContainSubstring(string([]byte{0xc0, 0x00, 0x00, 0x23, 0x8f, 0xfe, 0xce, 0x01, 0x01, 0x01})))
+			// decoder_fec_scheme (0x238ffecd), with a value of 2
+			Expect(data).To(ContainSubstring(string([]byte{0xa3, 0x8f, 0xfe, 0xcd, 0x01, 0x02})))
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+			Expect(p.EnableFEC).To(Equal(uint8(0x1)))
+			Expect(p.DecoderFECScheme).To(Equal(protocol.ReedSolomonFECScheme))
+			Expect(p.FECWireFormat).To(Equal(protocol.FECWireFormatLegacy))
+		})
+
+		It("marshals and unmarshals the FEC parameters in the draft wire format", func() {
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				EnableFEC:               0x1,
+				DecoderFECScheme:        protocol.XORFECScheme,
+				FECWireFormat:           protocol.FECWireFormatDraft,
+			}
+			data := params.Marshal(protocol.PerspectiveServer)
+			// enable_fec (0xfec), with a value of 1
+			Expect(data).To(ContainSubstring(string([]byte{0x4f, 0xec, 0x01, 0x01})))
+			// decoder_fec_scheme (0xfecd), with a value of 1
+			Expect(data).To(ContainSubstring(string([]byte{0x80, 0x00, 0xfe, 0xcd, 0x01, 0x01})))
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+			Expect(p.EnableFEC).To(Equal(uint8(0x1)))
+			Expect(p.DecoderFECScheme).To(Equal(protocol.XORFECScheme))
+			Expect(p.FECWireFormat).To(Equal(protocol.FECWireFormatDraft))
+		})
+
+		It("unmarshals the FEC parameters of draft-michel-quic-fec", func() {
+			// The transport parameters enable_fec and decoder_fec_scheme use the provisional IDs 0xfec and 0xfecd.
+			b := []byte{
+				0x4f, 0xec, // enable_fec
+				0x01,                   // length
+				0x01,                   // FEC enabled
+				0x80, 0x00, 0xfe, 0xcd, // decoder_fec_scheme
+				0x01, // length
+				0x02, // Reed-Solomon
+			}
+			b = appendInitialSourceConnectionID(b)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.EnableFEC).To(Equal(uint8(0x1)))
+			Expect(p.DecoderFECScheme).To(Equal(protocol.ReedSolomonFECScheme))
+			Expect(p.FECWireFormat).To(Equal(protocol.FECWireFormatDraft))
+		})
+
+		It("rejects unknown FEC schemes", func() {
+			for _, id := range []transportParameterID{fecDecoderSchemeParameterID, draftFECDecoderSchemeParameterID} {
+				b := quicvarint.Append(nil, uint64(id))
+				b = quicvarint.Append(b, 1)
+				b = quicvarint.Append(b, 7)
+				b = appendInitialSourceConnectionID(b)
+				Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+					ErrorCode:    qerr.TransportParameterError,
+					ErrorMessage: "invalid value for decoder_fec_scheme: 7",
+				}))
+			}
+		})
+
+		It("rejects FEC parameters of both wire formats", func() {
+			b := quicvarint.Append(nil, uint64(draftFECEnableParameterID))
+			b = quicvarint.Append(b, 1)
+			b = quicvarint.Append(b, 1)
+			b = quicvarint.Append(b, uint64(fecDecoderSchemeParameterID))
+			b = quicvarint.Append(b, 1)
+			b = quicvarint.Append(b, 1)
+			b = appendInitialSourceConnectionID(b)
+			Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "received FEC transport parameters of both wire formats",
+			}))
+		})
+	})
+
+	Context("multipath", func() {
+		It("marshals and unmarshals the initial_max_path_id", func() {
+			maxPathID := protocol.PathID(3)
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				InitialMaxPathID:        &maxPathID,
+			}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveClient), protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.InitialMaxPathID).ToNot(BeNil())
+			Expect(*p.InitialMaxPathID).To(Equal(maxPathID))
+		})
+
+		It("doesn't send the initial_max_path_id if multipath is not supported", func() {
+			params := &TransportParameters{ActiveConnectionIDLimit: 2}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveClient), protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.InitialMaxPathID).To(BeNil())
+		})
+
+		It("errors if the initial_max_path_id is too large", func() {
+			b := quicvarint.Append(nil, uint64(initialMaxPathIDParameterID))
+			b = quicvarint.Append(b, uint64(quicvarint.Len(1<<32)))
+			b = quicvarint.Append(b, 1<<32)
+			b = appendInitialSourceConnectionID(b)
+			Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "invalid value for initial_max_path_id: 4294967296",
+			}))
+		})
+	})
+
+	Context("ACK frequency", func() {
+		It("marshals and unmarshals the min_ack_delay", func() {
+			minAckDelay := 1234 * time.Microsecond
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				MaxAckDelay:             protocol.DefaultMaxAckDelay,
+				MinAckDelay:             &minAckDelay,
+			}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveClient), protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.MinAckDelay).ToNot(BeNil())
+			Expect(*p.MinAckDelay).To(Equal(minAckDelay))
+			Expect(p.String()).To(ContainSubstring("MinAckDelay: 1.234ms"))
+		})
+
+		It("doesn't send the min_ack_delay if the extension is not supported", func() {
+			params := &TransportParameters{ActiveConnectionIDLimit: 2, MaxAckDelay: protocol.DefaultMaxAckDelay}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveClient), protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.MinAckDelay).To(BeNil())
+		})
+
+		It("errors if the min_ack_delay is too large", func() {
+			b := quicvarint.Append(nil, uint64(minAckDelayParameterID))
+			b = quicvarint.Append(b, uint64(quicvarint.Len(1<<24)))
+			b = quicvarint.Append(b, 1<<24)
+			b = appendInitialSourceConnectionID(b)
+			Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "invalid value for min_ack_delay: 16777216us",
+			}))
+		})
+
+		It("errors if the min_ack_delay is larger than the max_ack_delay", func() {
+			minAckDelay := 30 * time.Millisecond
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				MaxAckDelay:             protocol.DefaultMaxAckDelay,
+				MinAckDelay:             &minAckDelay,
+			}
+			Expect((&TransportParameters{}).Unmarshal(params.Marshal(protocol.PerspectiveClient), protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "min_ack_delay (30ms) larger than max_ack_delay (25ms)",
+			}))
+		})
+	})
+
+	Context("reliable stream reset", func() {
+		It("marshals and unmarshals the reset_stream_at transport parameter", func() {
+			params := &TransportParameters{ActiveConnectionIDLimit: 2, EnableResetStreamAt: true}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveServer), protocol.PerspectiveServer)).To(Succeed())
+			Expect(p.EnableResetStreamAt).To(BeTrue())
+			Expect(p.String()).To(ContainSubstring("EnableResetStreamAt: true"))
+		})
+
+		It("doesn't send the reset_stream_at transport parameter if the extension is not supported", func() {
+			params := &TransportParameters{ActiveConnectionIDLimit: 2}
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(params.Marshal(protocol.PerspectiveServer), protocol.PerspectiveServer)).To(Succeed())
+			Expect(p.EnableResetStreamAt).To(BeFalse())
+			Expect(p.String()).ToNot(ContainSubstring("EnableResetStreamAt"))
+		})
+
+		It("errors when reset_stream_at has content", func() {
+			b := quicvarint.Append(nil, uint64(resetStreamAtParameterID))
+			b = quicvarint.Append(b, 1)
+			b = append(b, 0x42)
+			b = appendInitialSourceConnectionID(b)
+			Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "wrong length for reset_stream_at: 1 (expected empty)",
+			}))
+		})
+	})
+
 	Context("preferred address", func() {
 		var pa *PreferredAddress
 
@@ -519,6 +711,39 @@ var _ = Describe("Transport Parameters", func() {
 			Expect(tp.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
 		})
 
+		It("saves and retrieves the FEC parameters", func() {
+			for _, format := range []protocol.FECWireFormat{protocol.FECWireFormatLegacy, protocol.FECWireFormatDraft} {
+				params := &TransportParameters{
+					ActiveConnectionIDLimit: 2,
+					MaxDatagramFrameSize:    protocol.InvalidByteCount,
+					EnableFEC:               0x1,
+					DecoderFECScheme:        protocol.ReedSolomonFECScheme,
+					FECWireFormat:           format,
+				}
+				b := params.MarshalForSessionTicket(nil)
+				var tp TransportParameters
+				Expect(tp.UnmarshalFromSessionTicket(bytes.NewReader(b))).To(Succeed())
+				Expect(tp.EnableFEC).To(Equal(uint8(0x1)))
+				Expect(tp.DecoderFECScheme).To(Equal(protocol.ReedSolomonFECScheme))
+				Expect(tp.FECWireFormat).To(Equal(format))
+				Expect(params.ValidFor0RTT(&tp)).To(BeTrue())
+			}
+		})
+
+		It("doesn't save the FEC parameters if FEC is disabled", func() {
+			params := &TransportParameters{
+				ActiveConnectionIDLimit: 2,
+				MaxDatagramFrameSize:    protocol.InvalidByteCount,
+				DecoderFECScheme:        protocol.ReedSolomonFECScheme,
+			}
+			b := params.MarshalForSessionTicket(nil)
+			var tp TransportParameters
+			Expect(tp.UnmarshalFromSessionTicket(bytes.NewReader(b))).To(Succeed())
+			Expect(tp.EnableFEC).To(BeZero())
+			Expect(tp.DecoderFECScheme).To(Equal(protocol.FECDisabled))
+			Expect(params.ValidFor0RTT(&tp)).To(BeTrue())
+		})
+
 		It("rejects the parameters if it can't parse them", func() {
 			var p TransportParameters
 			Expect(p.UnmarshalFromSessionTicket(bytes.NewReader([]byte("foobar")))).ToNot(Succeed())
@@ -543,6 +768,8 @@ var _ = Describe("Transport Parameters", func() {
 				MaxUniStreamNum:                6,
 				ActiveConnectionIDLimit:        7,
 				MaxDatagramFrameSize:           1000,
+				EnableFEC:                      0x1,
+				DecoderFECScheme:               protocol.ReedSolomonFECScheme,
 			}
 
 			BeforeEach(func() {
@@ -624,6 +851,27 @@ var _ = Describe("Transport Parameters", func() {
 				p.MaxDatagramFrameSize = saved.MaxDatagramFrameSize - 1
 				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
 			})
+
+			It("rejects the parameters if FEC was enabled", func() {
+				saved := *saved
+				saved.EnableFEC = 0
+				Expect(p.ValidFor0RTT(&saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if FEC was disabled", func() {
+				p.EnableFEC = 0
+				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if the DecoderFECScheme changed", func() {
+				p.DecoderFECScheme = protocol.XORFECScheme
+				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if the FECWireFormat changed", func() {
+				p.FECWireFormat = protocol.FECWireFormatDraft
+				Expect(p.ValidFor0RTT(saved)).To(BeFalse())
+			})
 		})
 
 		Context("client checks the parameters after successfully sending 0-RTT data", func() {
@@ -637,6 +885,8 @@ var _ = Describe("Transport Parameters", func() {
 				MaxUniStreamNum:                6,
 				ActiveConnectionIDLimit:        7,
 				MaxDatagramFrameSize:           1000,
+				EnableFEC:                      0x1,
+				DecoderFECScheme:               protocol.ReedSolomonFECScheme,
 			}
 
 			BeforeEach(func() {
@@ -723,6 +973,27 @@ var _ = Describe("Transport Parameters", func() {
 				p.MaxDatagramFrameSize = saved.MaxDatagramFrameSize + 1
 				Expect(p.ValidForUpdate(saved)).To(BeTrue())
 			})
+
+			It("rejects the parameters if FEC was enabled", func() {
+				saved := *saved
+				saved.EnableFEC = 0
+				Expect(p.ValidForUpdate(&saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if FEC was disabled", func() {
+				p.EnableFEC = 0
+				Expect(p.ValidForUpdate(saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if the DecoderFECScheme changed", func() {
+				p.DecoderFECScheme = protocol.XORFECScheme
+				Expect(p.ValidForUpdate(saved)).To(BeFalse())
+			})
+
+			It("rejects the parameters if the FECWireFormat changed", func() {
+				p.FECWireFormat = protocol.FECWireFormatDraft
+				Expect(p.ValidForUpdate(saved)).To(BeFalse())
+			})
 		})
 	})
 })
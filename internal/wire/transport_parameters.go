@@ -23,7 +23,7 @@ import (
 // the usual size of less than 1 MTU.
 var AdditionalTransportParametersClient map[uint64][]byte
 
-const transportParameterMarshalingVersion = 1
+const transportParameterMarshalingVersion = 2
 
 type transportParameterID uint64
 
@@ -50,8 +50,21 @@ const (
 	// FEC
 	fecEnableParameterID        transportParameterID = 0x238ffece01
 	fecDecoderSchemeParameterID transportParameterID = 0x238ffecd
+	// FEC, using the wire format of draft-michel-quic-fec.
+	// The parameter IDs are provisional, they might change with future versions of the draft.
+	draftFECEnableParameterID        transportParameterID = 0xfec
+	draftFECDecoderSchemeParameterID transportParameterID = 0xfecd
+	// draft-ietf-quic-multipath
+	initialMaxPathIDParameterID transportParameterID = 0x0f739bbc1b666d0c
+	// draft-ietf-quic-ack-frequency
+	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// draft-ietf-quic-reliable-stream-reset
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
 )
 
+// values of the min_ack_delay transport parameter of 2^24 microseconds or larger are invalid
+const maxMinAckDelay = (1<<24 - 1) * time.Microsecond
+
 // PreferredAddress is the value encoding in the preferred_address transport parameter
 type PreferredAddress struct {
 	IPv4, IPv6          netip.AddrPort
@@ -92,6 +105,20 @@ type TransportParameters struct {
 	// FEC
 	EnableFEC        uint8
 	DecoderFECScheme protocol.DecoderFECScheme
+	// FECWireFormat is the wire format of the FEC transport parameters,
+	// which is also the wire format of the FEC frames.
+	FECWireFormat protocol.FECWireFormat
+
+	// InitialMaxPathID is the initial_max_path_id of draft-ietf-quic-multipath.
+	// It is nil if multipath is not supported.
+	InitialMaxPathID *protocol.PathID
+
+	// MinAckDelay is the min_ack_delay of draft-ietf-quic-ack-frequency.
+	// It is nil if the ACK frequency extension is not supported.
+	MinAckDelay *time.Duration
+
+	// EnableResetStreamAt is the reset_stream_at transport parameter of draft-ietf-quic-reliable-stream-reset.
+	EnableResetStreamAt bool
 }
 
 // Unmarshal the transport parameters
@@ -113,6 +140,8 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 		readOriginalDestinationConnectionID bool
 		readInitialSourceConnectionID       bool
 		readActiveConnectionIDLimit         bool
+		readLegacyFECParameters             bool
+		readDraftFECParameters              bool
 	)
 
 	p.AckDelayExponent = protocol.DefaultAckDelayExponent
@@ -150,7 +179,19 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			ackDelayExponentParameterID,
 			// FEC
 			fecEnableParameterID,
-			fecDecoderSchemeParameterID:
+			fecDecoderSchemeParameterID,
+			draftFECEnableParameterID,
+			draftFECDecoderSchemeParameterID:
+			switch paramID {
+			case fecEnableParameterID, fecDecoderSchemeParameterID:
+				readLegacyFECParameters = true
+			case draftFECEnableParameterID, draftFECDecoderSchemeParameterID:
+				readDraftFECParameters = true
+				p.FECWireFormat = protocol.FECWireFormatDraft
+			}
+			if readLegacyFECParameters && readDraftFECParameters {
+				return errors.New("received FEC transport parameters of both wire formats")
+			}
 			if err := p.readNumericTransportParameter(r, paramID, int(paramLen)); err != nil {
 				return err
 			}
@@ -166,6 +207,11 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -191,11 +237,42 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			}
 			connID, _ := protocol.ReadConnectionID(r, int(paramLen))
 			p.RetrySourceConnectionID = &connID
+		case initialMaxPathIDParameterID:
+			remainingLen := r.Len()
+			val, err := quicvarint.Read(r)
+			if err != nil {
+				return fmt.Errorf("error while reading transport parameter %d: %s", paramID, err)
+			}
+			if remainingLen-r.Len() != int(paramLen) {
+				return fmt.Errorf("inconsistent transport parameter length for transport parameter %#x", paramID)
+			}
+			if val > uint64(protocol.MaxPathID) {
+				return fmt.Errorf("invalid value for initial_max_path_id: %d", val)
+			}
+			pathID := protocol.PathID(val)
+			p.InitialMaxPathID = &pathID
+		case minAckDelayParameterID:
+			remainingLen := r.Len()
+			val, err := quicvarint.Read(r)
+			if err != nil {
+				return fmt.Errorf("error while reading transport parameter %d: %s", paramID, err)
+			}
+			if remainingLen-r.Len() != int(paramLen) {
+				return fmt.Errorf("inconsistent transport parameter length for transport parameter %#x", paramID)
+			}
+			if val > uint64(maxMinAckDelay/time.Microsecond) {
+				return fmt.Errorf("invalid value for min_ack_delay: %dus", val)
+			}
+			minAckDelay := time.Duration(val) * time.Microsecond
+			p.MinAckDelay = &minAckDelay
 		default:
 			r.Seek(int64(paramLen), io.SeekCurrent)
 		}
 	}
 
+	if p.MinAckDelay != nil && *p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", *p.MinAckDelay, p.MaxAckDelay)
+	}
 	if !readActiveConnectionIDLimit {
 		p.ActiveConnectionIDLimit = protocol.DefaultActiveConnectionIDLimit
 	}
@@ -322,12 +399,15 @@ func (p *TransportParameters) readNumericTransportParameter(
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
-	case fecEnableParameterID:
+	case fecEnableParameterID, draftFECEnableParameterID:
 		if val != 0 && val != 1 {
 			return fmt.Errorf("invalid value for enable_fec: %d (only 0x0 or 0x1 supported)", val)
 		}
 		p.EnableFEC = uint8(val)
-	case fecDecoderSchemeParameterID:
+	case fecDecoderSchemeParameterID, draftFECDecoderSchemeParameterID:
+		if val > uint64(protocol.MaxDecoderFECScheme) {
+			return fmt.Errorf("invalid value for decoder_fec_scheme: %d", val)
+		}
 		p.DecoderFECScheme = protocol.DecoderFECScheme(val)
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
@@ -366,10 +446,14 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	b = p.marshalVarintParam(b, maxIdleTimeoutParameterID, uint64(p.MaxIdleTimeout/time.Millisecond))
 	// max_packet_size
 	b = p.marshalVarintParam(b, maxUDPPayloadSizeParameterID, uint64(protocol.MaxPacketBufferSize))
+	enableFECParameterID, decoderFECSchemeParameterID := fecEnableParameterID, fecDecoderSchemeParameterID
+	if p.FECWireFormat == protocol.FECWireFormatDraft {
+		enableFECParameterID, decoderFECSchemeParameterID = draftFECEnableParameterID, draftFECDecoderSchemeParameterID
+	}
 	// enable_fec
-	b = p.marshalVarintParam(b, fecEnableParameterID, uint64(p.EnableFEC))
+	b = p.marshalVarintParam(b, enableFECParameterID, uint64(p.EnableFEC))
 	// decoder_fec_scheme
-	b = p.marshalVarintParam(b, fecDecoderSchemeParameterID, uint64(p.DecoderFECScheme))
+	b = p.marshalVarintParam(b, decoderFECSchemeParameterID, uint64(p.DecoderFECScheme))
 	// max_ack_delay
 	// Only send it if is different from the default value.
 	if p.MaxAckDelay != protocol.DefaultMaxAckDelay {
@@ -428,6 +512,19 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// initial_max_path_id
+	if p.InitialMaxPathID != nil {
+		b = p.marshalVarintParam(b, initialMaxPathIDParameterID, uint64(*p.InitialMaxPathID))
+	}
+	// min_ack_delay
+	if p.MinAckDelay != nil {
+		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 0)
+	}
 
 	if pers == protocol.PerspectiveClient && len(AdditionalTransportParametersClient) > 0 {
 		for k, v := range AdditionalTransportParametersClient {
@@ -474,7 +571,18 @@ func (p *TransportParameters) MarshalForSessionTicket(b []byte) []byte {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
 	// active_connection_id_limit
-	return p.marshalVarintParam(b, activeConnectionIDLimitParameterID, p.ActiveConnectionIDLimit)
+	b = p.marshalVarintParam(b, activeConnectionIDLimitParameterID, p.ActiveConnectionIDLimit)
+	if p.EnableFEC == 0 {
+		return b
+	}
+	enableFECParameterID, decoderFECSchemeParameterID := fecEnableParameterID, fecDecoderSchemeParameterID
+	if p.FECWireFormat == protocol.FECWireFormatDraft {
+		enableFECParameterID, decoderFECSchemeParameterID = draftFECEnableParameterID, draftFECDecoderSchemeParameterID
+	}
+	// enable_fec
+	b = p.marshalVarintParam(b, enableFECParameterID, uint64(p.EnableFEC))
+	// decoder_fec_scheme
+	return p.marshalVarintParam(b, decoderFECSchemeParameterID, uint64(p.DecoderFECScheme))
 }
 
 // UnmarshalFromSessionTicket unmarshals transport parameters from a session ticket.
@@ -494,6 +602,9 @@ func (p *TransportParameters) ValidFor0RTT(saved *TransportParameters) bool {
 	if saved.MaxDatagramFrameSize != protocol.InvalidByteCount && (p.MaxDatagramFrameSize == protocol.InvalidByteCount || p.MaxDatagramFrameSize < saved.MaxDatagramFrameSize) {
 		return false
 	}
+	if !p.sameFECParameters(saved) {
+		return false
+	}
 	return p.InitialMaxStreamDataBidiLocal >= saved.InitialMaxStreamDataBidiLocal &&
 		p.InitialMaxStreamDataBidiRemote >= saved.InitialMaxStreamDataBidiRemote &&
 		p.InitialMaxStreamDataUni >= saved.InitialMaxStreamDataUni &&
@@ -509,6 +620,10 @@ func (p *TransportParameters) ValidForUpdate(saved *TransportParameters) bool {
 	if saved.MaxDatagramFrameSize != protocol.InvalidByteCount && (p.MaxDatagramFrameSize == protocol.InvalidByteCount || p.MaxDatagramFrameSize < saved.MaxDatagramFrameSize) {
 		return false
 	}
+	// FEC frames sent in 0-RTT packets were encoded using the saved FEC parameters.
+	if !p.sameFECParameters(saved) {
+		return false
+	}
 	return p.ActiveConnectionIDLimit >= saved.ActiveConnectionIDLimit &&
 		p.InitialMaxData >= saved.InitialMaxData &&
 		p.InitialMaxStreamDataBidiLocal >= saved.InitialMaxStreamDataBidiLocal &&
@@ -518,6 +633,18 @@ func (p *TransportParameters) ValidForUpdate(saved *TransportParameters) bool {
 		p.MaxUniStreamNum >= saved.MaxUniStreamNum
 }
 
+// sameFECParameters checks that the FEC parameters didn't change.
+// If FEC is disabled, the FEC scheme and the wire format don't matter.
+func (p *TransportParameters) sameFECParameters(saved *TransportParameters) bool {
+	if p.EnableFEC != saved.EnableFEC {
+		return false
+	}
+	if p.EnableFEC == 0 {
+		return true
+	}
+	return p.DecoderFECScheme == saved.DecoderFECScheme && p.FECWireFormat == saved.FECWireFormat
+}
+
 // String returns a string representation, intended for logging.
 func (p *TransportParameters) String() string {
 	logString := "&wire.TransportParameters{OriginalDestinationConnectionID: %s, InitialSourceConnectionID: %s, "
@@ -526,8 +653,8 @@ func (p *TransportParameters) String() string {
 		logString += "RetrySourceConnectionID: %s, "
 		logParams = append(logParams, p.RetrySourceConnectionID)
 	}
-	logString += "InitialMaxStreamDataBidiLocal: %d, InitialMaxStreamDataBidiRemote: %d, InitialMaxStreamDataUni: %d, InitialMaxData: %d, MaxBidiStreamNum: %d, MaxUniStreamNum: %d, MaxIdleTimeout: %s, AckDelayExponent: %d, MaxAckDelay: %s, ActiveConnectionIDLimit: %d, EnableFEC: %d, DecoderFECScheme: %s"
-	logParams = append(logParams, []interface{}{p.InitialMaxStreamDataBidiLocal, p.InitialMaxStreamDataBidiRemote, p.InitialMaxStreamDataUni, p.InitialMaxData, p.MaxBidiStreamNum, p.MaxUniStreamNum, p.MaxIdleTimeout, p.AckDelayExponent, p.MaxAckDelay, p.ActiveConnectionIDLimit, p.EnableFEC, p.DecoderFECScheme.String()}...)
+	logString += "InitialMaxStreamDataBidiLocal: %d, InitialMaxStreamDataBidiRemote: %d, InitialMaxStreamDataUni: %d, InitialMaxData: %d, MaxBidiStreamNum: %d, MaxUniStreamNum: %d, MaxIdleTimeout: %s, AckDelayExponent: %d, MaxAckDelay: %s, ActiveConnectionIDLimit: %d, EnableFEC: %d, DecoderFECScheme: %s, FECWireFormat: %s"
+	logParams = append(logParams, []interface{}{p.InitialMaxStreamDataBidiLocal, p.InitialMaxStreamDataBidiRemote, p.InitialMaxStreamDataUni, p.InitialMaxData, p.MaxBidiStreamNum, p.MaxUniStreamNum, p.MaxIdleTimeout, p.AckDelayExponent, p.MaxAckDelay, p.ActiveConnectionIDLimit, p.EnableFEC, p.DecoderFECScheme.String(), p.FECWireFormat.String()}...)
 	if p.StatelessResetToken != nil { // the client never sends a stateless reset token
 		logString += ", StatelessResetToken: %#x"
 		logParams = append(logParams, *p.StatelessResetToken)
@@ -536,6 +663,17 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.InitialMaxPathID != nil {
+		logString += ", InitialMaxPathID: %d"
+		logParams = append(logParams, *p.InitialMaxPathID)
+	}
+	if p.MinAckDelay != nil {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, *p.MinAckDelay)
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
@@ -35,6 +35,10 @@ type ConnectionTracer struct {
 	LossTimerCanceled                func()
 	ECNStateUpdated                  func(state ECNState, trigger ECNStateTrigger)
 	ChoseALPN                        func(protocol string)
+	RecoveredSourceSymbols           func(BlockID, ByteCount)
+	RejectedFECFrame                 func(Frame, FECFrameRejectReason)
+	UpdatedLossEstimate              func(LossEstimate)
+	DroppedDatagram                  func(ByteCount, DatagramDropReason)
 	// Close is called when the connection is closed.
 	Close func()
 	Debug func(name, msg string)
@@ -245,6 +249,34 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		RecoveredSourceSymbols: func(blockID BlockID, length ByteCount) {
+			for _, t := range tracers {
+				if t.RecoveredSourceSymbols != nil {
+					t.RecoveredSourceSymbols(blockID, length)
+				}
+			}
+		},
+		RejectedFECFrame: func(frame Frame, reason FECFrameRejectReason) {
+			for _, t := range tracers {
+				if t.RejectedFECFrame != nil {
+					t.RejectedFECFrame(frame, reason)
+				}
+			}
+		},
+		UpdatedLossEstimate: func(estimate LossEstimate) {
+			for _, t := range tracers {
+				if t.UpdatedLossEstimate != nil {
+					t.UpdatedLossEstimate(estimate)
+				}
+			}
+		},
+		DroppedDatagram: func(length ByteCount, reason DatagramDropReason) {
+			for _, t := range tracers {
+				if t.DroppedDatagram != nil {
+					t.DroppedDatagram(length, reason)
+				}
+			}
+		},
 		Close: func() {
 			for _, t := range tracers {
 				if t.Close != nil {
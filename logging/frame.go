@@ -44,6 +44,28 @@ type (
 	StreamsBlockedFrame = wire.StreamsBlockedFrame
 	// A StreamDataBlockedFrame is a STREAM_DATA_BLOCKED frame.
 	StreamDataBlockedFrame = wire.StreamDataBlockedFrame
+	// A FECWindowFrame is a FEC_WINDOW frame.
+	FECWindowFrame = wire.FECWindowFrame
+	// A PathAckFrame is a PATH_ACK frame.
+	PathAckFrame = wire.PathAckFrame
+	// A PathAbandonFrame is a PATH_ABANDON frame.
+	PathAbandonFrame = wire.PathAbandonFrame
+	// A PathStatusFrame is a PATH_STATUS_BACKUP or a PATH_STATUS_AVAILABLE frame.
+	PathStatusFrame = wire.PathStatusFrame
+	// A PathNewConnectionIDFrame is a PATH_NEW_CONNECTION_ID frame.
+	PathNewConnectionIDFrame = wire.PathNewConnectionIDFrame
+	// A PathRetireConnectionIDFrame is a PATH_RETIRE_CONNECTION_ID frame.
+	PathRetireConnectionIDFrame = wire.PathRetireConnectionIDFrame
+	// A MaxPathIDFrame is a MAX_PATH_ID frame.
+	MaxPathIDFrame = wire.MaxPathIDFrame
+	// A PathsBlockedFrame is a PATHS_BLOCKED frame.
+	PathsBlockedFrame = wire.PathsBlockedFrame
+	// A PathCIDsBlockedFrame is a PATH_CIDS_BLOCKED frame.
+	PathCIDsBlockedFrame = wire.PathCIDsBlockedFrame
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame.
+	AckFrequencyFrame = wire.AckFrequencyFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
+	ImmediateAckFrame = wire.ImmediateAckFrame
 )
 
 // A CryptoFrame is a CRYPTO frame.
@@ -67,6 +89,7 @@ type DatagramFrame struct {
 
 // A RepairFrame is a REPAIR frame.
 type RepairFrame struct {
+	Class    FECProtectionClass
 	BlockID  BlockID
 	ParityID ParityID
 	Length   ByteCount
@@ -74,6 +97,7 @@ type RepairFrame struct {
 
 // A SourceSymbolFrame is a SOURCE_SYMBOL frame.
 type SourceSymbolFrame struct {
+	Class  FECProtectionClass
 	SID    SID
 	Length ByteCount
 }
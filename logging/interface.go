@@ -59,6 +59,8 @@ type (
 	BlockID  = protocol.BlockID
 	ParityID = protocol.ParityID
 	SID      = protocol.SourceSymbolID
+	// The FECProtectionClass is the protection class of a REPAIR or SOURCE_SYMBOL frame.
+	FECProtectionClass = protocol.FECProtectionClass
 )
 
 const (
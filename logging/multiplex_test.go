@@ -276,6 +276,32 @@ var _ = Describe("Tracing", func() {
 			tracer.LossTimerCanceled()
 		})
 
+		It("traces the RecoveredSourceSymbols event", func() {
+			tr1.EXPECT().RecoveredSourceSymbols(BlockID(42), ByteCount(1337))
+			tr2.EXPECT().RecoveredSourceSymbols(BlockID(42), ByteCount(1337))
+			tracer.RecoveredSourceSymbols(42, 1337)
+		})
+
+		It("traces the UpdatedLossEstimate event", func() {
+			estimate := LossEstimate{P: 0.01, R: 0.5, H: 0.1, K: 0.99, NumPackets: 1000, NumLost: 20, BurstLengths: []uint64{10, 5}}
+			tr1.EXPECT().UpdatedLossEstimate(estimate)
+			tr2.EXPECT().UpdatedLossEstimate(estimate)
+			tracer.UpdatedLossEstimate(estimate)
+		})
+
+		It("traces the RejectedFECFrame event", func() {
+			f := &RepairFrame{BlockID: 42, Length: 1337}
+			tr1.EXPECT().RejectedFECFrame(f, FECFrameRejectInvalidSymbol)
+			tr2.EXPECT().RejectedFECFrame(f, FECFrameRejectInvalidSymbol)
+			tracer.RejectedFECFrame(f, FECFrameRejectInvalidSymbol)
+		})
+
+		It("traces the DroppedDatagram event", func() {
+			tr1.EXPECT().DroppedDatagram(ByteCount(1337), DatagramDropExpired)
+			tr2.EXPECT().DroppedDatagram(ByteCount(1337), DatagramDropExpired)
+			tracer.DroppedDatagram(1337, DatagramDropExpired)
+		})
+
 		It("traces the Close event", func() {
 			tr1.EXPECT().Close()
 			tr2.EXPECT().Close()
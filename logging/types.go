@@ -58,6 +58,34 @@ const (
 	PacketDropDuplicate
 )
 
+// FECFrameRejectReason is the reason why a FEC frame was rejected
+type FECFrameRejectReason uint8
+
+const (
+	// FECFrameRejectNotNegotiated is used when a FEC frame is received, but FEC wasn't negotiated
+	FECFrameRejectNotNegotiated FECFrameRejectReason = iota
+	// FECFrameRejectInvalidSymbol is used when a source or repair symbol is inconsistent with the rest of its block
+	FECFrameRejectInvalidSymbol
+	// FECFrameRejectOutsideWindow is used when a source or repair symbol is outside of the decoding window
+	FECFrameRejectOutsideWindow
+)
+
+// DatagramDropReason is the reason why a datagram was dropped
+type DatagramDropReason uint8
+
+const (
+	// DatagramDropExpired is used when a datagram expired before it could be sent
+	DatagramDropExpired DatagramDropReason = iota
+	// DatagramDropSendQueueFull is used when a datagram is dropped because the send queue is full.
+	// This happens when it is displaced by a datagram of a higher priority,
+	// or when it expires while waiting for space in the queue.
+	DatagramDropSendQueueFull
+	// DatagramDropTooLarge is used when a datagram is dropped because it doesn't fit into a packet anymore
+	DatagramDropTooLarge
+	// DatagramDropReceiveQueueFull is used when a received datagram is dropped because the receive queue is full
+	DatagramDropReceiveQueueFull
+)
+
 // TimerType is the type of the loss detection timer
 type TimerType uint8
 
@@ -91,6 +119,14 @@ const (
 	CongestionStateRecovery
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited
+	// CongestionStateBBRStartup is the Startup mode of BBR
+	CongestionStateBBRStartup
+	// CongestionStateBBRDrain is the Drain mode of BBR
+	CongestionStateBBRDrain
+	// CongestionStateBBRProbeBW is the ProbeBW mode of BBR
+	CongestionStateBBRProbeBW
+	// CongestionStateBBRProbeRTT is the ProbeRTT mode of BBR
+	CongestionStateBBRProbeRTT
 )
 
 // ECNState is the state of the ECN state machine (see Appendix A.4 of RFC 9000)
@@ -126,3 +162,26 @@ const (
 	// ECNFailedManglingDetected is emitted when the path marks all ECN-marked packets as CE
 	ECNFailedManglingDetected
 )
+
+// A LossEstimate describes the loss pattern observed on a connection.
+// P, R, H and K are the parameters of a Gilbert-Elliott loss model,
+// using the same definitions as the gemodel of netem.
+type LossEstimate struct {
+	// P is the probability of a transition from the good to the bad state.
+	P float64
+	// R is the probability of a transition from the bad to the good state.
+	R float64
+	// H is the probability that a packet is delivered in the bad state.
+	H float64
+	// K is the probability that a packet is delivered in the good state.
+	K float64
+
+	// NumPackets is the number of packets that were either acknowledged or declared lost.
+	NumPackets uint64
+	// NumLost is the number of packets that were declared lost.
+	NumLost uint64
+	// BurstLengths is a histogram of the lengths of loss bursts.
+	// BurstLengths[i] is the number of bursts of i+1 consecutive lost packets.
+	// The last entry also counts all longer bursts.
+	BurstLengths []uint64
+}
@@ -79,6 +79,45 @@ func (c *MockFrameSourceAppendControlFramesCall) DoAndReturn(f func([]ackhandler
 	return c
 }
 
+// AppendFECStreamFrames mocks base method.
+func (m *MockFrameSource) AppendFECStreamFrames(arg0 []ackhandler.StreamFrame, arg1 protocol.ByteCount, arg2 protocol.FECProtectionClass, arg3 bool, arg4 protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendFECStreamFrames", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]ackhandler.StreamFrame)
+	ret1, _ := ret[1].(protocol.ByteCount)
+	return ret0, ret1
+}
+
+// AppendFECStreamFrames indicates an expected call of AppendFECStreamFrames.
+func (mr *MockFrameSourceMockRecorder) AppendFECStreamFrames(arg0, arg1, arg2, arg3, arg4 any) *MockFrameSourceAppendFECStreamFramesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendFECStreamFrames", reflect.TypeOf((*MockFrameSource)(nil).AppendFECStreamFrames), arg0, arg1, arg2, arg3, arg4)
+	return &MockFrameSourceAppendFECStreamFramesCall{Call: call}
+}
+
+// MockFrameSourceAppendFECStreamFramesCall wrap *gomock.Call
+type MockFrameSourceAppendFECStreamFramesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFrameSourceAppendFECStreamFramesCall) Return(arg0 []ackhandler.StreamFrame, arg1 protocol.ByteCount) *MockFrameSourceAppendFECStreamFramesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFrameSourceAppendFECStreamFramesCall) Do(f func([]ackhandler.StreamFrame, protocol.ByteCount, protocol.FECProtectionClass, bool, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)) *MockFrameSourceAppendFECStreamFramesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFrameSourceAppendFECStreamFramesCall) DoAndReturn(f func([]ackhandler.StreamFrame, protocol.ByteCount, protocol.FECProtectionClass, bool, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)) *MockFrameSourceAppendFECStreamFramesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // AppendStreamFrames mocks base method.
 func (m *MockFrameSource) AppendStreamFrames(arg0 []ackhandler.StreamFrame, arg1 protocol.ByteCount, arg2 protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
 	m.ctrl.T.Helper()
@@ -13,7 +13,6 @@ import (
 	reflect "reflect"
 
 	ackhandler "github.com/quic-go/quic-go/internal/ackhandler"
-	fec "github.com/quic-go/quic-go/internal/fec"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
@@ -81,6 +80,45 @@ func (c *MockPackerAppendPacketCall) DoAndReturn(f func(*packetBuffer, protocol.
 	return c
 }
 
+// AppendPathPacket mocks base method.
+func (m *MockPacker) AppendPathPacket(arg0 *packetBuffer, arg1 *packerPath, arg2 protocol.ByteCount, arg3 protocol.Version) (shortHeaderPacket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendPathPacket", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendPathPacket indicates an expected call of AppendPathPacket.
+func (mr *MockPackerMockRecorder) AppendPathPacket(arg0, arg1, arg2, arg3 any) *MockPackerAppendPathPacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendPathPacket", reflect.TypeOf((*MockPacker)(nil).AppendPathPacket), arg0, arg1, arg2, arg3)
+	return &MockPackerAppendPathPacketCall{Call: call}
+}
+
+// MockPackerAppendPathPacketCall wrap *gomock.Call
+type MockPackerAppendPathPacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerAppendPathPacketCall) Return(arg0 shortHeaderPacket, arg1 error) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerAppendPathPacketCall) Do(f func(*packetBuffer, *packerPath, protocol.ByteCount, protocol.Version) (shortHeaderPacket, error)) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerAppendPathPacketCall) DoAndReturn(f func(*packetBuffer, *packerPath, protocol.ByteCount, protocol.Version) (shortHeaderPacket, error)) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MaybePackProbePacket mocks base method.
 func (m *MockPacker) MaybePackProbePacket(arg0 protocol.EncryptionLevel, arg1 protocol.ByteCount, arg2 protocol.Version) (*coalescedPacket, error) {
 	m.ctrl.T.Helper()
@@ -317,38 +355,234 @@ func (c *MockPackerPackMTUProbePacketCall) DoAndReturn(f func(ackhandler.Frame,
 	return c
 }
 
-// SetFECSender mocks base method.
-func (m *MockPacker) SetFECSender(arg0 fec.Sender) {
+// PackMultipathProbePacket mocks base method.
+func (m *MockPacker) PackMultipathProbePacket(arg0 *packerPath, arg1 []ackhandler.Frame, arg2 protocol.ByteCount, arg3 protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetFECSender", arg0)
+	ret := m.ctrl.Call(m, "PackMultipathProbePacket", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(*packetBuffer)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// SetFECSender indicates an expected call of SetFECSender.
-func (mr *MockPackerMockRecorder) SetFECSender(arg0 any) *MockPackerSetFECSenderCall {
+// PackMultipathProbePacket indicates an expected call of PackMultipathProbePacket.
+func (mr *MockPackerMockRecorder) PackMultipathProbePacket(arg0, arg1, arg2, arg3 any) *MockPackerPackMultipathProbePacketCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECSender", reflect.TypeOf((*MockPacker)(nil).SetFECSender), arg0)
-	return &MockPackerSetFECSenderCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackMultipathProbePacket", reflect.TypeOf((*MockPacker)(nil).PackMultipathProbePacket), arg0, arg1, arg2, arg3)
+	return &MockPackerPackMultipathProbePacketCall{Call: call}
 }
 
-// MockPackerSetFECSenderCall wrap *gomock.Call
-type MockPackerSetFECSenderCall struct {
+// MockPackerPackMultipathProbePacketCall wrap *gomock.Call
+type MockPackerPackMultipathProbePacketCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockPackerSetFECSenderCall) Return() *MockPackerSetFECSenderCall {
+func (c *MockPackerPackMultipathProbePacketCall) Return(arg0 shortHeaderPacket, arg1 *packetBuffer, arg2 error) *MockPackerPackMultipathProbePacketCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPackMultipathProbePacketCall) Do(f func(*packerPath, []ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackMultipathProbePacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPackMultipathProbePacketCall) DoAndReturn(f func(*packerPath, []ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackMultipathProbePacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PackPathAckOnlyPacket mocks base method.
+func (m *MockPacker) PackPathAckOnlyPacket(arg0 *packerPath, arg1 protocol.ByteCount, arg2 protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PackPathAckOnlyPacket", arg0, arg1, arg2)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(*packetBuffer)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PackPathAckOnlyPacket indicates an expected call of PackPathAckOnlyPacket.
+func (mr *MockPackerMockRecorder) PackPathAckOnlyPacket(arg0, arg1, arg2 any) *MockPackerPackPathAckOnlyPacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPathAckOnlyPacket", reflect.TypeOf((*MockPacker)(nil).PackPathAckOnlyPacket), arg0, arg1, arg2)
+	return &MockPackerPackPathAckOnlyPacketCall{Call: call}
+}
+
+// MockPackerPackPathAckOnlyPacketCall wrap *gomock.Call
+type MockPackerPackPathAckOnlyPacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerPackPathAckOnlyPacketCall) Return(arg0 shortHeaderPacket, arg1 *packetBuffer, arg2 error) *MockPackerPackPathAckOnlyPacketCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPackPathAckOnlyPacketCall) Do(f func(*packerPath, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathAckOnlyPacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPackPathAckOnlyPacketCall) DoAndReturn(f func(*packerPath, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathAckOnlyPacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PackPathProbePacket mocks base method.
+func (m *MockPacker) PackPathProbePacket(arg0 protocol.ConnectionID, arg1 []ackhandler.Frame, arg2 protocol.ByteCount, arg3 protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PackPathProbePacket", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(*packetBuffer)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PackPathProbePacket indicates an expected call of PackPathProbePacket.
+func (mr *MockPackerMockRecorder) PackPathProbePacket(arg0, arg1, arg2, arg3 any) *MockPackerPackPathProbePacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPathProbePacket", reflect.TypeOf((*MockPacker)(nil).PackPathProbePacket), arg0, arg1, arg2, arg3)
+	return &MockPackerPackPathProbePacketCall{Call: call}
+}
+
+// MockPackerPackPathProbePacketCall wrap *gomock.Call
+type MockPackerPackPathProbePacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerPackPathProbePacketCall) Return(arg0 shortHeaderPacket, arg1 *packetBuffer, arg2 error) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPackPathProbePacketCall) Do(f func(protocol.ConnectionID, []ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPackPathProbePacketCall) DoAndReturn(f func(protocol.ConnectionID, []ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathProbePacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PreferredRepairPaths mocks base method.
+func (m *MockPacker) PreferredRepairPaths(arg0 pathSet) pathSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreferredRepairPaths", arg0)
+	ret0, _ := ret[0].(pathSet)
+	return ret0
+}
+
+// PreferredRepairPaths indicates an expected call of PreferredRepairPaths.
+func (mr *MockPackerMockRecorder) PreferredRepairPaths(arg0 any) *MockPackerPreferredRepairPathsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreferredRepairPaths", reflect.TypeOf((*MockPacker)(nil).PreferredRepairPaths), arg0)
+	return &MockPackerPreferredRepairPathsCall{Call: call}
+}
+
+// MockPackerPreferredRepairPathsCall wrap *gomock.Call
+type MockPackerPreferredRepairPathsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerPreferredRepairPathsCall) Return(arg0 pathSet) *MockPackerPreferredRepairPathsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPreferredRepairPathsCall) Do(f func(pathSet) pathSet) *MockPackerPreferredRepairPathsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPreferredRepairPathsCall) DoAndReturn(f func(pathSet) pathSet) *MockPackerPreferredRepairPathsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetFECPeerWindow mocks base method.
+func (m *MockPacker) SetFECPeerWindow(arg0 protocol.FECWindowSize) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetFECPeerWindow", arg0)
+}
+
+// SetFECPeerWindow indicates an expected call of SetFECPeerWindow.
+func (mr *MockPackerMockRecorder) SetFECPeerWindow(arg0 any) *MockPackerSetFECPeerWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECPeerWindow", reflect.TypeOf((*MockPacker)(nil).SetFECPeerWindow), arg0)
+	return &MockPackerSetFECPeerWindowCall{Call: call}
+}
+
+// MockPackerSetFECPeerWindowCall wrap *gomock.Call
+type MockPackerSetFECPeerWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerSetFECPeerWindowCall) Return() *MockPackerSetFECPeerWindowCall {
 	c.Call = c.Call.Return()
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockPackerSetFECSenderCall) Do(f func(fec.Sender)) *MockPackerSetFECSenderCall {
+func (c *MockPackerSetFECPeerWindowCall) Do(f func(protocol.FECWindowSize)) *MockPackerSetFECPeerWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerSetFECPeerWindowCall) DoAndReturn(f func(protocol.FECWindowSize)) *MockPackerSetFECPeerWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetFECScheme mocks base method.
+func (m *MockPacker) SetFECScheme(arg0 protocol.DecoderFECScheme, arg1 protocol.FECWireFormat) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFECScheme", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFECScheme indicates an expected call of SetFECScheme.
+func (mr *MockPackerMockRecorder) SetFECScheme(arg0, arg1 any) *MockPackerSetFECSchemeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECScheme", reflect.TypeOf((*MockPacker)(nil).SetFECScheme), arg0, arg1)
+	return &MockPackerSetFECSchemeCall{Call: call}
+}
+
+// MockPackerSetFECSchemeCall wrap *gomock.Call
+type MockPackerSetFECSchemeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerSetFECSchemeCall) Return(arg0 error) *MockPackerSetFECSchemeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerSetFECSchemeCall) Do(f func(protocol.DecoderFECScheme, protocol.FECWireFormat) error) *MockPackerSetFECSchemeCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockPackerSetFECSenderCall) DoAndReturn(f func(fec.Sender)) *MockPackerSetFECSenderCall {
+func (c *MockPackerSetFECSchemeCall) DoAndReturn(f func(protocol.DecoderFECScheme, protocol.FECWireFormat) error) *MockPackerSetFECSchemeCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
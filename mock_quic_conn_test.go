@@ -14,7 +14,9 @@ import (
 	net "net"
 	reflect "reflect"
 
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
+	logging "github.com/quic-go/quic-go/logging"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -119,6 +121,45 @@ func (c *MockQUICConnAcceptUniStreamCall) DoAndReturn(f func(context.Context) (R
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockQUICConn) AddPath(arg0 *Transport) (*Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQUICConnMockRecorder) AddPath(arg0 any) *MockQUICConnAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQUICConn)(nil).AddPath), arg0)
+	return &MockQUICConnAddPathCall{Call: call}
+}
+
+// MockQUICConnAddPathCall wrap *gomock.Call
+type MockQUICConnAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnAddPathCall) Return(arg0 *Path, arg1 error) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnAddPathCall) Do(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnAddPathCall) DoAndReturn(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockQUICConn) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -233,6 +274,44 @@ func (c *MockQUICConnContextCall) DoAndReturn(f func() context.Context) *MockQUI
 	return c
 }
 
+// DatagramStats mocks base method.
+func (m *MockQUICConn) DatagramStats() DatagramStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DatagramStats")
+	ret0, _ := ret[0].(DatagramStats)
+	return ret0
+}
+
+// DatagramStats indicates an expected call of DatagramStats.
+func (mr *MockQUICConnMockRecorder) DatagramStats() *MockQUICConnDatagramStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatagramStats", reflect.TypeOf((*MockQUICConn)(nil).DatagramStats))
+	return &MockQUICConnDatagramStatsCall{Call: call}
+}
+
+// MockQUICConnDatagramStatsCall wrap *gomock.Call
+type MockQUICConnDatagramStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnDatagramStatsCall) Return(arg0 DatagramStats) *MockQUICConnDatagramStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnDatagramStatsCall) Do(f func() DatagramStats) *MockQUICConnDatagramStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnDatagramStatsCall) DoAndReturn(f func() DatagramStats) *MockQUICConnDatagramStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HandshakeComplete mocks base method.
 func (m *MockQUICConn) HandshakeComplete() <-chan struct{} {
 	m.ctrl.T.Helper()
@@ -309,6 +388,44 @@ func (c *MockQUICConnLocalAddrCall) DoAndReturn(f func() net.Addr) *MockQUICConn
 	return c
 }
 
+// LossEstimate mocks base method.
+func (m *MockQUICConn) LossEstimate() logging.LossEstimate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LossEstimate")
+	ret0, _ := ret[0].(logging.LossEstimate)
+	return ret0
+}
+
+// LossEstimate indicates an expected call of LossEstimate.
+func (mr *MockQUICConnMockRecorder) LossEstimate() *MockQUICConnLossEstimateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LossEstimate", reflect.TypeOf((*MockQUICConn)(nil).LossEstimate))
+	return &MockQUICConnLossEstimateCall{Call: call}
+}
+
+// MockQUICConnLossEstimateCall wrap *gomock.Call
+type MockQUICConnLossEstimateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnLossEstimateCall) Return(arg0 logging.LossEstimate) *MockQUICConnLossEstimateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnLossEstimateCall) Do(f func() logging.LossEstimate) *MockQUICConnLossEstimateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnLossEstimateCall) DoAndReturn(f func() logging.LossEstimate) *MockQUICConnLossEstimateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NextConnection mocks base method.
 func (m *MockQUICConn) NextConnection() Connection {
 	m.ctrl.T.Helper()
@@ -734,6 +851,82 @@ func (c *MockQUICConnSendDatagramWithFECCall) DoAndReturn(f func([]byte) error)
 	return c
 }
 
+// SendDatagramWithFECClass mocks base method.
+func (m *MockQUICConn) SendDatagramWithFECClass(arg0 []byte, arg1 protocol.FECProtectionClass) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithFECClass", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithFECClass indicates an expected call of SendDatagramWithFECClass.
+func (mr *MockQUICConnMockRecorder) SendDatagramWithFECClass(arg0, arg1 any) *MockQUICConnSendDatagramWithFECClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithFECClass", reflect.TypeOf((*MockQUICConn)(nil).SendDatagramWithFECClass), arg0, arg1)
+	return &MockQUICConnSendDatagramWithFECClassCall{Call: call}
+}
+
+// MockQUICConnSendDatagramWithFECClassCall wrap *gomock.Call
+type MockQUICConnSendDatagramWithFECClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendDatagramWithFECClassCall) Return(arg0 error) *MockQUICConnSendDatagramWithFECClassCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendDatagramWithFECClassCall) Do(f func([]byte, protocol.FECProtectionClass) error) *MockQUICConnSendDatagramWithFECClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendDatagramWithFECClassCall) DoAndReturn(f func([]byte, protocol.FECProtectionClass) error) *MockQUICConnSendDatagramWithFECClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SendDatagramWithOptions mocks base method.
+func (m *MockQUICConn) SendDatagramWithOptions(arg0 []byte, arg1 DatagramOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithOptions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithOptions indicates an expected call of SendDatagramWithOptions.
+func (mr *MockQUICConnMockRecorder) SendDatagramWithOptions(arg0, arg1 any) *MockQUICConnSendDatagramWithOptionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithOptions", reflect.TypeOf((*MockQUICConn)(nil).SendDatagramWithOptions), arg0, arg1)
+	return &MockQUICConnSendDatagramWithOptionsCall{Call: call}
+}
+
+// MockQUICConnSendDatagramWithOptionsCall wrap *gomock.Call
+type MockQUICConnSendDatagramWithOptionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendDatagramWithOptionsCall) Return(arg0 error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendDatagramWithOptionsCall) Do(f func([]byte, DatagramOptions) error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendDatagramWithOptionsCall) DoAndReturn(f func([]byte, DatagramOptions) error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeWithTransportError mocks base method.
 func (m *MockQUICConn) closeWithTransportError(arg0 qerr.TransportErrorCode) {
 	m.ctrl.T.Helper()
@@ -229,3 +229,41 @@ func (c *MockSendConncapabilitiesCall) DoAndReturn(f func() connCapabilities) *M
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// forRemoteAddr mocks base method.
+func (m *MockSendConn) forRemoteAddr(arg0 net.Addr, arg1 packetInfo) sendConn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "forRemoteAddr", arg0, arg1)
+	ret0, _ := ret[0].(sendConn)
+	return ret0
+}
+
+// forRemoteAddr indicates an expected call of forRemoteAddr.
+func (mr *MockSendConnMockRecorder) forRemoteAddr(arg0, arg1 any) *MockSendConnforRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "forRemoteAddr", reflect.TypeOf((*MockSendConn)(nil).forRemoteAddr), arg0, arg1)
+	return &MockSendConnforRemoteAddrCall{Call: call}
+}
+
+// MockSendConnforRemoteAddrCall wrap *gomock.Call
+type MockSendConnforRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendConnforRemoteAddrCall) Return(arg0 sendConn) *MockSendConnforRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendConnforRemoteAddrCall) Do(f func(net.Addr, packetInfo) sendConn) *MockSendConnforRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendConnforRemoteAddrCall) DoAndReturn(f func(net.Addr, packetInfo) sendConn) *MockSendConnforRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
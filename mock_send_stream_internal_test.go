@@ -80,6 +80,44 @@ func (c *MockSendStreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockSendStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockSendStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *MockSendStreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockSendStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &MockSendStreamICancelWriteAtCall{Call: call}
+}
+
+// MockSendStreamICancelWriteAtCall wrap *gomock.Call
+type MockSendStreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamICancelWriteAtCall) Return(arg0 error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64) error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64) error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockSendStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -156,6 +194,80 @@ func (c *MockSendStreamIContextCall) DoAndReturn(f func() context.Context) *Mock
 	return c
 }
 
+// SetFECProtectionClass mocks base method.
+func (m *MockSendStreamI) SetFECProtectionClass(arg0 protocol.FECProtectionClass) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFECProtectionClass", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFECProtectionClass indicates an expected call of SetFECProtectionClass.
+func (mr *MockSendStreamIMockRecorder) SetFECProtectionClass(arg0 any) *MockSendStreamISetFECProtectionClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECProtectionClass", reflect.TypeOf((*MockSendStreamI)(nil).SetFECProtectionClass), arg0)
+	return &MockSendStreamISetFECProtectionClassCall{Call: call}
+}
+
+// MockSendStreamISetFECProtectionClassCall wrap *gomock.Call
+type MockSendStreamISetFECProtectionClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISetFECProtectionClassCall) Return(arg0 error) *MockSendStreamISetFECProtectionClassCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISetFECProtectionClassCall) Do(f func(protocol.FECProtectionClass) error) *MockSendStreamISetFECProtectionClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISetFECProtectionClassCall) DoAndReturn(f func(protocol.FECProtectionClass) error) *MockSendStreamISetFECProtectionClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockSendStreamI) SetPriority(arg0 protocol.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockSendStreamIMockRecorder) SetPriority(arg0 any) *MockSendStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockSendStreamI)(nil).SetPriority), arg0)
+	return &MockSendStreamISetPriorityCall{Call: call}
+}
+
+// MockSendStreamISetPriorityCall wrap *gomock.Call
+type MockSendStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISetPriorityCall) Return() *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISetPriorityCall) Do(f func(protocol.StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISetPriorityCall) DoAndReturn(f func(protocol.StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockSendStreamI) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -307,6 +419,45 @@ func (c *MockSendStreamIcloseForShutdownCall) DoAndReturn(f func(error)) *MockSe
 	return c
 }
 
+// fecProtectionClass mocks base method.
+func (m *MockSendStreamI) fecProtectionClass() (protocol.FECProtectionClass, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "fecProtectionClass")
+	ret0, _ := ret[0].(protocol.FECProtectionClass)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// fecProtectionClass indicates an expected call of fecProtectionClass.
+func (mr *MockSendStreamIMockRecorder) fecProtectionClass() *MockSendStreamIfecProtectionClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "fecProtectionClass", reflect.TypeOf((*MockSendStreamI)(nil).fecProtectionClass))
+	return &MockSendStreamIfecProtectionClassCall{Call: call}
+}
+
+// MockSendStreamIfecProtectionClassCall wrap *gomock.Call
+type MockSendStreamIfecProtectionClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIfecProtectionClassCall) Return(arg0 protocol.FECProtectionClass, arg1 bool) *MockSendStreamIfecProtectionClassCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIfecProtectionClassCall) Do(f func() (protocol.FECProtectionClass, bool)) *MockSendStreamIfecProtectionClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIfecProtectionClassCall) DoAndReturn(f func() (protocol.FECProtectionClass, bool)) *MockSendStreamIfecProtectionClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // handleStopSendingFrame mocks base method.
 func (m *MockSendStreamI) handleStopSendingFrame(arg0 *wire.StopSendingFrame) {
 	m.ctrl.T.Helper()
@@ -116,6 +116,44 @@ func (c *MockStreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *MockStreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &MockStreamICancelWriteAtCall{Call: call}
+}
+
+// MockStreamICancelWriteAtCall wrap *gomock.Call
+type MockStreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamICancelWriteAtCall) Return(arg0 error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64) error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64) error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -269,6 +307,80 @@ func (c *MockStreamISetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockS
 	return c
 }
 
+// SetFECProtectionClass mocks base method.
+func (m *MockStreamI) SetFECProtectionClass(arg0 protocol.FECProtectionClass) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFECProtectionClass", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFECProtectionClass indicates an expected call of SetFECProtectionClass.
+func (mr *MockStreamIMockRecorder) SetFECProtectionClass(arg0 any) *MockStreamISetFECProtectionClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFECProtectionClass", reflect.TypeOf((*MockStreamI)(nil).SetFECProtectionClass), arg0)
+	return &MockStreamISetFECProtectionClassCall{Call: call}
+}
+
+// MockStreamISetFECProtectionClassCall wrap *gomock.Call
+type MockStreamISetFECProtectionClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetFECProtectionClassCall) Return(arg0 error) *MockStreamISetFECProtectionClassCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetFECProtectionClassCall) Do(f func(protocol.FECProtectionClass) error) *MockStreamISetFECProtectionClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetFECProtectionClassCall) DoAndReturn(f func(protocol.FECProtectionClass) error) *MockStreamISetFECProtectionClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockStreamI) SetPriority(arg0 protocol.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamIMockRecorder) SetPriority(arg0 any) *MockStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStreamI)(nil).SetPriority), arg0)
+	return &MockStreamISetPriorityCall{Call: call}
+}
+
+// MockStreamISetPriorityCall wrap *gomock.Call
+type MockStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetPriorityCall) Return() *MockStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetPriorityCall) Do(f func(protocol.StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetPriorityCall) DoAndReturn(f func(protocol.StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -458,6 +570,45 @@ func (c *MockStreamIcloseForShutdownCall) DoAndReturn(f func(error)) *MockStream
 	return c
 }
 
+// fecProtectionClass mocks base method.
+func (m *MockStreamI) fecProtectionClass() (protocol.FECProtectionClass, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "fecProtectionClass")
+	ret0, _ := ret[0].(protocol.FECProtectionClass)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// fecProtectionClass indicates an expected call of fecProtectionClass.
+func (mr *MockStreamIMockRecorder) fecProtectionClass() *MockStreamIfecProtectionClassCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "fecProtectionClass", reflect.TypeOf((*MockStreamI)(nil).fecProtectionClass))
+	return &MockStreamIfecProtectionClassCall{Call: call}
+}
+
+// MockStreamIfecProtectionClassCall wrap *gomock.Call
+type MockStreamIfecProtectionClassCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIfecProtectionClassCall) Return(arg0 protocol.FECProtectionClass, arg1 bool) *MockStreamIfecProtectionClassCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIfecProtectionClassCall) Do(f func() (protocol.FECProtectionClass, bool)) *MockStreamIfecProtectionClassCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIfecProtectionClassCall) DoAndReturn(f func() (protocol.FECProtectionClass, bool)) *MockStreamIfecProtectionClassCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // getWindowUpdate mocks base method.
 func (m *MockStreamI) getWindowUpdate() protocol.ByteCount {
 	m.ctrl.T.Helper()
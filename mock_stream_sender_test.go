@@ -112,6 +112,42 @@ func (c *MockStreamSenderonStreamCompletedCall) DoAndReturn(f func(protocol.Stre
 	return c
 }
 
+// onStreamPriorityChanged mocks base method.
+func (m *MockStreamSender) onStreamPriorityChanged(arg0 protocol.StreamID, arg1 protocol.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "onStreamPriorityChanged", arg0, arg1)
+}
+
+// onStreamPriorityChanged indicates an expected call of onStreamPriorityChanged.
+func (mr *MockStreamSenderMockRecorder) onStreamPriorityChanged(arg0, arg1 any) *MockStreamSenderonStreamPriorityChangedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onStreamPriorityChanged", reflect.TypeOf((*MockStreamSender)(nil).onStreamPriorityChanged), arg0, arg1)
+	return &MockStreamSenderonStreamPriorityChangedCall{Call: call}
+}
+
+// MockStreamSenderonStreamPriorityChangedCall wrap *gomock.Call
+type MockStreamSenderonStreamPriorityChangedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSenderonStreamPriorityChangedCall) Return() *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSenderonStreamPriorityChangedCall) Do(f func(protocol.StreamID, protocol.StreamPriority)) *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSenderonStreamPriorityChangedCall) DoAndReturn(f func(protocol.StreamID, protocol.StreamPriority)) *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // queueControlFrame mocks base method.
 func (m *MockStreamSender) queueControlFrame(arg0 wire.Frame) {
 	m.ctrl.T.Helper()
@@ -147,3 +183,41 @@ func (c *MockStreamSenderqueueControlFrameCall) DoAndReturn(f func(wire.Frame))
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// supportsResetStreamAt mocks base method.
+func (m *MockStreamSender) supportsResetStreamAt() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "supportsResetStreamAt")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// supportsResetStreamAt indicates an expected call of supportsResetStreamAt.
+func (mr *MockStreamSenderMockRecorder) supportsResetStreamAt() *MockStreamSendersupportsResetStreamAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "supportsResetStreamAt", reflect.TypeOf((*MockStreamSender)(nil).supportsResetStreamAt))
+	return &MockStreamSendersupportsResetStreamAtCall{Call: call}
+}
+
+// MockStreamSendersupportsResetStreamAtCall wrap *gomock.Call
+type MockStreamSendersupportsResetStreamAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSendersupportsResetStreamAtCall) Return(arg0 bool) *MockStreamSendersupportsResetStreamAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSendersupportsResetStreamAtCall) Do(f func() bool) *MockStreamSendersupportsResetStreamAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSendersupportsResetStreamAtCall) DoAndReturn(f func() bool) *MockStreamSendersupportsResetStreamAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,336 @@
+package quic
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/handshake"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// maxPathConnIDs is the number of connection IDs issued for every path of a multipath connection,
+// other than the initial path.
+const maxPathConnIDs = 2
+
+// mpPath is a path of a multipath connection, other than the initial path.
+// Every path has its own packet number space, congestion controller and RTT estimate.
+type mpPath struct {
+	id        protocol.PathID
+	conn      sendConn
+	sendQueue sender
+
+	rttStats              *utils.RTTStats
+	sentPacketHandler     ackhandler.SentPacketHandler
+	receivedPacketHandler ackhandler.ReceivedPacketHandler
+	aead                  handshake.PathAEAD
+
+	validated bool
+	abandoned bool
+	// backup is set if the peer asked to only use this path if no other path is available
+	backup    bool
+	statusSeq uint64
+
+	// the PATH_CHALLENGE sent by the server to validate the path
+	pathChallenge     [8]byte
+	pathChallengeSent time.Time
+	// probeFrames are the PATH_CHALLENGE and PATH_RESPONSE frames that need to be sent on this path
+	probeFrames []ackhandler.Frame
+	// used to enforce the amplification limit before the path is validated
+	rcvdBytes, sentBytes protocol.ByteCount
+}
+
+type pathConnID struct {
+	seq        uint64
+	connID     protocol.ConnectionID
+	resetToken protocol.StatelessResetToken
+}
+
+// The multipathManager manages the paths of a multipath connection (see draft-ietf-quic-multipath),
+// and the connection IDs used on these paths.
+// The initial path uses the connIDManager and the connIDGenerator, and is not tracked by the multipathManager.
+// It is only used from the connection's run loop.
+type multipathManager struct {
+	// localMaxPathID is the largest path ID the peer is allowed to use,
+	// peerMaxPathID is the largest path ID we are allowed to use.
+	localMaxPathID, peerMaxPathID protocol.PathID
+
+	paths map[protocol.PathID]*mpPath
+
+	// the connection IDs we issued
+	srcConnIDs   map[protocol.PathID]map[uint64]protocol.ConnectionID
+	nextSrcSeq   map[protocol.PathID]uint64
+	connIDToPath map[protocol.ConnectionID]protocol.PathID
+	// the connection IDs issued by the peer, sorted by sequence number
+	destConnIDs    map[protocol.PathID][]pathConnID
+	retiredPriorTo map[protocol.PathID]uint64
+
+	generator              ConnectionIDGenerator
+	addConnectionID        func(protocol.ConnectionID)
+	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken
+	removeConnectionID     func(protocol.ConnectionID)
+	retireConnectionID     func(protocol.ConnectionID)
+	replaceWithClosed      func([]protocol.ConnectionID, []byte)
+	addResetToken          func(protocol.StatelessResetToken)
+	removeResetToken       func(protocol.StatelessResetToken)
+	queueControlFrame      func(wire.Frame)
+}
+
+func newMultipathManager(
+	localMaxPathID protocol.PathID,
+	generator ConnectionIDGenerator,
+	addConnectionID func(protocol.ConnectionID),
+	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken,
+	removeConnectionID func(protocol.ConnectionID),
+	retireConnectionID func(protocol.ConnectionID),
+	replaceWithClosed func([]protocol.ConnectionID, []byte),
+	addResetToken func(protocol.StatelessResetToken),
+	removeResetToken func(protocol.StatelessResetToken),
+	queueControlFrame func(wire.Frame),
+) *multipathManager {
+	return &multipathManager{
+		localMaxPathID:         localMaxPathID,
+		paths:                  make(map[protocol.PathID]*mpPath),
+		srcConnIDs:             make(map[protocol.PathID]map[uint64]protocol.ConnectionID),
+		nextSrcSeq:             make(map[protocol.PathID]uint64),
+		connIDToPath:           make(map[protocol.ConnectionID]protocol.PathID),
+		destConnIDs:            make(map[protocol.PathID][]pathConnID),
+		retiredPriorTo:         make(map[protocol.PathID]uint64),
+		generator:              generator,
+		addConnectionID:        addConnectionID,
+		getStatelessResetToken: getStatelessResetToken,
+		removeConnectionID:     removeConnectionID,
+		retireConnectionID:     retireConnectionID,
+		replaceWithClosed:      replaceWithClosed,
+		addResetToken:          addResetToken,
+		removeResetToken:       removeResetToken,
+		queueControlFrame:      queueControlFrame,
+	}
+}
+
+// MaxPathID is the largest path ID that can be used by both endpoints.
+func (m *multipathManager) MaxPathID() protocol.PathID {
+	return min(m.localMaxPathID, m.peerMaxPathID)
+}
+
+// SetPeerMaxPathID is called with the initial_max_path_id transport parameter, and for MAX_PATH_ID frames.
+// Connection IDs are issued for all paths that can now be used.
+func (m *multipathManager) SetPeerMaxPathID(id protocol.PathID) error {
+	if id <= m.peerMaxPathID {
+		return nil
+	}
+	m.peerMaxPathID = id
+	for pathID := protocol.InitialPathID + 1; pathID <= m.MaxPathID(); pathID++ {
+		if p, ok := m.paths[pathID]; ok && p.abandoned {
+			continue
+		}
+		for len(m.srcConnIDs[pathID]) < maxPathConnIDs {
+			if err := m.issueConnID(pathID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *multipathManager) issueConnID(pathID protocol.PathID) error {
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return err
+	}
+	seq := m.nextSrcSeq[pathID]
+	m.nextSrcSeq[pathID]++
+	if m.srcConnIDs[pathID] == nil {
+		m.srcConnIDs[pathID] = make(map[uint64]protocol.ConnectionID)
+	}
+	m.srcConnIDs[pathID][seq] = connID
+	m.connIDToPath[connID] = pathID
+	m.addConnectionID(connID)
+	m.queueControlFrame(&wire.PathNewConnectionIDFrame{
+		PathID: pathID,
+		NewConnectionIDFrame: wire.NewConnectionIDFrame{
+			SequenceNumber:      seq,
+			ConnectionID:        connID,
+			StatelessResetToken: m.getStatelessResetToken(connID),
+		},
+	})
+	return nil
+}
+
+// PathForConnID returns the path that a connection ID was issued for.
+// It returns false for the connection IDs of the initial path.
+func (m *multipathManager) PathForConnID(connID protocol.ConnectionID) (protocol.PathID, bool) {
+	id, ok := m.connIDToPath[connID]
+	return id, ok
+}
+
+// HandlePathNewConnectionIDFrame handles a PATH_NEW_CONNECTION_ID frame for a path other than the initial path.
+func (m *multipathManager) HandlePathNewConnectionIDFrame(f *wire.PathNewConnectionIDFrame) error {
+	if f.PathID > m.localMaxPathID {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("received connection ID for path %d (maximum path ID: %d)", f.PathID, m.localMaxPathID),
+		}
+	}
+	if p, ok := m.paths[f.PathID]; ok && p.abandoned {
+		return nil
+	}
+	connIDs := m.destConnIDs[f.PathID]
+	if f.RetirePriorTo > m.retiredPriorTo[f.PathID] {
+		m.retiredPriorTo[f.PathID] = f.RetirePriorTo
+		connIDs = slices.DeleteFunc(connIDs, func(c pathConnID) bool {
+			if c.seq >= f.RetirePriorTo {
+				return false
+			}
+			m.retireDestConnID(f.PathID, c)
+			return true
+		})
+	}
+	if f.SequenceNumber < m.retiredPriorTo[f.PathID] {
+		m.queueControlFrame(&wire.PathRetireConnectionIDFrame{PathID: f.PathID, SequenceNumber: f.SequenceNumber})
+		m.destConnIDs[f.PathID] = connIDs
+		return nil
+	}
+	i, found := slices.BinarySearchFunc(connIDs, f.SequenceNumber, func(c pathConnID, seq uint64) int {
+		switch {
+		case c.seq < seq:
+			return -1
+		case c.seq > seq:
+			return 1
+		}
+		return 0
+	})
+	if found {
+		if connIDs[i].connID != f.ConnectionID {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: fmt.Sprintf("received conflicting connection IDs for path %d, sequence number %d", f.PathID, f.SequenceNumber),
+			}
+		}
+		m.destConnIDs[f.PathID] = connIDs
+		return nil
+	}
+	connIDs = slices.Insert(connIDs, i, pathConnID{seq: f.SequenceNumber, connID: f.ConnectionID, resetToken: f.StatelessResetToken})
+	m.destConnIDs[f.PathID] = connIDs
+	m.addResetToken(f.StatelessResetToken)
+	if len(connIDs) > protocol.MaxActiveConnectionIDs {
+		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
+	}
+	return nil
+}
+
+func (m *multipathManager) retireDestConnID(pathID protocol.PathID, c pathConnID) {
+	m.removeResetToken(c.resetToken)
+	m.queueControlFrame(&wire.PathRetireConnectionIDFrame{PathID: pathID, SequenceNumber: c.seq})
+}
+
+// HandlePathRetireConnectionIDFrame handles a PATH_RETIRE_CONNECTION_ID frame for a path other than the initial path.
+// Unless the path was abandoned, a new connection ID is issued for the path.
+func (m *multipathManager) HandlePathRetireConnectionIDFrame(f *wire.PathRetireConnectionIDFrame, rcvConnID protocol.ConnectionID) error {
+	if f.SequenceNumber >= m.nextSrcSeq[f.PathID] {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %d for path %d, which was never issued", f.SequenceNumber, f.PathID),
+		}
+	}
+	connID, ok := m.srcConnIDs[f.PathID][f.SequenceNumber]
+	// We might already have deleted this connection ID, if this is a duplicate frame.
+	if !ok {
+		return nil
+	}
+	if connID == rcvConnID {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %s, which was used as the Destination Connection ID on this packet", connID),
+		}
+	}
+	delete(m.srcConnIDs[f.PathID], f.SequenceNumber)
+	delete(m.connIDToPath, connID)
+	m.retireConnectionID(connID)
+	if p, ok := m.paths[f.PathID]; ok && p.abandoned {
+		return nil
+	}
+	return m.issueConnID(f.PathID)
+}
+
+// DestConnID returns the connection ID used for sending packets on a path.
+func (m *multipathManager) DestConnID(id protocol.PathID) (protocol.ConnectionID, bool) {
+	connIDs := m.destConnIDs[id]
+	if len(connIDs) == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	return connIDs[0].connID, true
+}
+
+func (m *multipathManager) Path(id protocol.PathID) *mpPath {
+	return m.paths[id]
+}
+
+func (m *multipathManager) AddPath(p *mpPath) {
+	m.paths[p.id] = p
+}
+
+// Paths returns all paths that were not abandoned, sorted by path ID.
+func (m *multipathManager) Paths() []*mpPath {
+	paths := make([]*mpPath, 0, len(m.paths))
+	for _, p := range m.paths {
+		if !p.abandoned {
+			paths = append(paths, p)
+		}
+	}
+	slices.SortFunc(paths, func(a, b *mpPath) int { return int(a.id) - int(b.id) })
+	return paths
+}
+
+// HasActivePaths says if any path other than the initial path can be used to send data.
+func (m *multipathManager) HasActivePaths() bool {
+	for _, p := range m.paths {
+		if p.validated && !p.abandoned {
+			return true
+		}
+	}
+	return false
+}
+
+// AbandonPath stops using a path.
+// All connection IDs that the peer issued for the path are retired.
+func (m *multipathManager) AbandonPath(id protocol.PathID) {
+	if p, ok := m.paths[id]; ok {
+		p.abandoned = true
+	}
+	for _, c := range m.destConnIDs[id] {
+		m.retireDestConnID(id, c)
+	}
+	delete(m.destConnIDs, id)
+}
+
+func (m *multipathManager) connIDs() []protocol.ConnectionID {
+	connIDs := make([]protocol.ConnectionID, 0, len(m.connIDToPath))
+	for connID := range m.connIDToPath {
+		connIDs = append(connIDs, connID)
+	}
+	return connIDs
+}
+
+func (m *multipathManager) RemoveAll() {
+	for _, connID := range m.connIDs() {
+		m.removeConnectionID(connID)
+	}
+}
+
+func (m *multipathManager) ReplaceWithClosed(connClose []byte) {
+	m.replaceWithClosed(m.connIDs(), connClose)
+}
+
+// Close removes the stateless reset tokens of the connection IDs issued by the peer.
+func (m *multipathManager) Close() {
+	for _, connIDs := range m.destConnIDs {
+		for _, c := range connIDs {
+			m.removeResetToken(c.resetToken)
+		}
+	}
+}
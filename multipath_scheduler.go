@@ -0,0 +1,99 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A pathSet is a set of paths of a multipath connection.
+// Each path ID is mapped to one bit of the set.
+// This works since the number of paths is limited to protocol.MaxPaths.
+type pathSet uint64
+
+func (s pathSet) Add(id protocol.PathID) pathSet { return s | 1<<id }
+
+func (s pathSet) Remove(id protocol.PathID) pathSet { return s &^ (1 << id) }
+
+func (s pathSet) Contains(id protocol.PathID) bool { return s&(1<<id) != 0 }
+
+// A schedulerPath is a path that a packet can be sent on.
+type schedulerPath struct {
+	id     protocol.PathID
+	rtt    time.Duration // the smoothed RTT, 0 if no RTT sample was obtained yet
+	backup bool
+}
+
+// The multipathScheduler selects the path that the next packet of a multipath connection is sent on.
+// Backup paths are only used if no other path can be used.
+type multipathScheduler struct {
+	scheduler protocol.MultipathScheduler
+	// the path selected last, used by the round-robin scheduler
+	last protocol.PathID
+}
+
+func newMultipathScheduler(s protocol.MultipathScheduler) *multipathScheduler {
+	return &multipathScheduler{scheduler: s}
+}
+
+// SelectPath selects one of the paths that are currently allowed to send.
+// If preferred is not empty, and contains any of the available paths, one of those paths is selected.
+// It returns false if no path is available.
+func (s *multipathScheduler) SelectPath(paths []schedulerPath, preferred pathSet) (protocol.PathID, bool) {
+	var hasNonBackup, hasPreferred bool
+	for _, p := range paths {
+		if !p.backup {
+			hasNonBackup = true
+		}
+		if preferred.Contains(p.id) {
+			hasPreferred = true
+		}
+	}
+	usable := func(p schedulerPath) bool {
+		if hasNonBackup && p.backup {
+			return false
+		}
+		return !hasPreferred || preferred.Contains(p.id)
+	}
+
+	var selected *schedulerPath
+	for i := range paths {
+		p := &paths[i]
+		if !usable(*p) {
+			continue
+		}
+		if selected == nil {
+			selected = p
+			continue
+		}
+		switch s.scheduler {
+		case protocol.MultipathSchedulerRoundRobin:
+			// select the path with the next higher path ID, wrapping around
+			if (p.id > s.last) != (selected.id > s.last) {
+				if p.id > s.last {
+					selected = p
+				}
+			} else if p.id < selected.id {
+				selected = p
+			}
+		default:
+			if rttLess(p.rtt, selected.rtt) || (p.rtt == selected.rtt && p.id < selected.id) {
+				selected = p
+			}
+		}
+	}
+	if selected == nil {
+		return 0, false
+	}
+	s.last = selected.id
+	return selected.id, true
+}
+
+// rttLess says if a is smaller than b.
+// Paths without an RTT sample are only used once the paths with RTT samples are congestion limited.
+func rttLess(a, b time.Duration) bool {
+	if a == 0 || b == 0 {
+		return a != 0 && b == 0
+	}
+	return a < b
+}
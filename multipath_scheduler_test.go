@@ -0,0 +1,105 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipath Scheduler", func() {
+	It("adds and removes paths from a path set", func() {
+		var s pathSet
+		Expect(s.Contains(0)).To(BeFalse())
+		s = s.Add(0).Add(3)
+		Expect(s.Contains(0)).To(BeTrue())
+		Expect(s.Contains(3)).To(BeTrue())
+		Expect(s.Contains(1)).To(BeFalse())
+		s = s.Remove(0)
+		Expect(s.Contains(0)).To(BeFalse())
+		Expect(s.Contains(3)).To(BeTrue())
+	})
+
+	It("doesn't alias paths in a path set", func() {
+		s := pathSet(0).Add(protocol.MaxPaths - 1)
+		Expect(s.Contains(protocol.MaxPaths - 1)).To(BeTrue())
+		Expect(s.Contains(0)).To(BeFalse())
+		s = s.Add(protocol.MaxPaths)
+		Expect(s.Contains(0)).To(BeFalse())
+		Expect(s.Contains(protocol.MaxPaths)).To(BeFalse())
+	})
+
+	It("doesn't select a path if no path is available", func() {
+		s := newMultipathScheduler(protocol.MultipathSchedulerMinRTT)
+		_, ok := s.SelectPath(nil, 0)
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("minimum RTT", func() {
+		It("selects the path with the smallest RTT", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerMinRTT)
+			paths := []schedulerPath{
+				{id: 0, rtt: 50 * time.Millisecond},
+				{id: 1, rtt: 10 * time.Millisecond},
+				{id: 2, rtt: 20 * time.Millisecond},
+			}
+			id, ok := s.SelectPath(paths, 0)
+			Expect(ok).To(BeTrue())
+			Expect(id).To(BeEquivalentTo(1))
+		})
+
+		It("uses paths without an RTT sample last", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerMinRTT)
+			id, _ := s.SelectPath([]schedulerPath{{id: 1}, {id: 2, rtt: time.Second}}, 0)
+			Expect(id).To(BeEquivalentTo(2))
+			id, _ = s.SelectPath([]schedulerPath{{id: 2}, {id: 1}}, 0)
+			Expect(id).To(BeEquivalentTo(1))
+		})
+
+		It("only uses backup paths if no other path is available", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerMinRTT)
+			id, _ := s.SelectPath([]schedulerPath{{id: 1, rtt: time.Millisecond, backup: true}, {id: 2, rtt: time.Second}}, 0)
+			Expect(id).To(BeEquivalentTo(2))
+			id, _ = s.SelectPath([]schedulerPath{{id: 1, rtt: time.Millisecond, backup: true}}, 0)
+			Expect(id).To(BeEquivalentTo(1))
+		})
+
+		It("selects one of the preferred paths", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerMinRTT)
+			paths := []schedulerPath{
+				{id: 0, rtt: 10 * time.Millisecond},
+				{id: 1, rtt: 20 * time.Millisecond},
+				{id: 2, rtt: 30 * time.Millisecond},
+			}
+			id, _ := s.SelectPath(paths, pathSet(0).Add(1).Add(2))
+			Expect(id).To(BeEquivalentTo(1))
+			// preferred paths that are not available are ignored
+			id, _ = s.SelectPath(paths, pathSet(0).Add(5))
+			Expect(id).To(BeEquivalentTo(0))
+		})
+	})
+
+	Context("round-robin", func() {
+		It("alternates between the paths", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerRoundRobin)
+			paths := []schedulerPath{{id: 0}, {id: 1}, {id: 3}}
+			var ids []protocol.PathID
+			for i := 0; i < 6; i++ {
+				id, ok := s.SelectPath(paths, 0)
+				Expect(ok).To(BeTrue())
+				ids = append(ids, id)
+			}
+			Expect(ids).To(Equal([]protocol.PathID{1, 3, 0, 1, 3, 0}))
+		})
+
+		It("skips paths that are not available", func() {
+			s := newMultipathScheduler(protocol.MultipathSchedulerRoundRobin)
+			id, _ := s.SelectPath([]schedulerPath{{id: 0}, {id: 1}, {id: 2}}, 0)
+			Expect(id).To(BeEquivalentTo(1))
+			id, _ = s.SelectPath([]schedulerPath{{id: 0}, {id: 1}}, 0)
+			Expect(id).To(BeEquivalentTo(0))
+		})
+	})
+})
@@ -0,0 +1,238 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipath Manager", func() {
+	var (
+		addedConnIDs       []protocol.ConnectionID
+		retiredConnIDs     []protocol.ConnectionID
+		removedConnIDs     []protocol.ConnectionID
+		replacedWithClosed []protocol.ConnectionID
+		addedTokens        []protocol.StatelessResetToken
+		removedTokens      []protocol.StatelessResetToken
+		queuedFrames       []wire.Frame
+		m                  *multipathManager
+	)
+
+	connIDToToken := func(c protocol.ConnectionID) protocol.StatelessResetToken {
+		b := c.Bytes()[0]
+		return protocol.StatelessResetToken{b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b}
+	}
+
+	BeforeEach(func() {
+		addedConnIDs = nil
+		retiredConnIDs = nil
+		removedConnIDs = nil
+		replacedWithClosed = nil
+		addedTokens = nil
+		removedTokens = nil
+		queuedFrames = nil
+		m = newMultipathManager(
+			3,
+			&protocol.DefaultConnectionIDGenerator{ConnLen: 8},
+			func(c protocol.ConnectionID) { addedConnIDs = append(addedConnIDs, c) },
+			connIDToToken,
+			func(c protocol.ConnectionID) { removedConnIDs = append(removedConnIDs, c) },
+			func(c protocol.ConnectionID) { retiredConnIDs = append(retiredConnIDs, c) },
+			func(cs []protocol.ConnectionID, _ []byte) { replacedWithClosed = append(replacedWithClosed, cs...) },
+			func(t protocol.StatelessResetToken) { addedTokens = append(addedTokens, t) },
+			func(t protocol.StatelessResetToken) { removedTokens = append(removedTokens, t) },
+			func(f wire.Frame) { queuedFrames = append(queuedFrames, f) },
+		)
+	})
+
+	getPathNewConnIDFrames := func() []*wire.PathNewConnectionIDFrame {
+		var frames []*wire.PathNewConnectionIDFrame
+		for _, f := range queuedFrames {
+			if nf, ok := f.(*wire.PathNewConnectionIDFrame); ok {
+				frames = append(frames, nf)
+			}
+		}
+		queuedFrames = nil
+		return frames
+	}
+
+	It("issues connection IDs for all paths that can be used", func() {
+		Expect(m.SetPeerMaxPathID(2)).To(Succeed())
+		Expect(m.MaxPathID()).To(BeEquivalentTo(2))
+		frames := getPathNewConnIDFrames()
+		Expect(frames).To(HaveLen(2 * maxPathConnIDs))
+		Expect(addedConnIDs).To(HaveLen(2 * maxPathConnIDs))
+		for i, f := range frames {
+			Expect(f.PathID).To(BeEquivalentTo(1 + i/maxPathConnIDs))
+			Expect(f.SequenceNumber).To(BeEquivalentTo(i % maxPathConnIDs))
+			Expect(f.StatelessResetToken).To(Equal(connIDToToken(f.ConnectionID)))
+			id, ok := m.PathForConnID(f.ConnectionID)
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(f.PathID))
+		}
+
+		// the local maximum path ID limits the paths that can be used
+		Expect(m.SetPeerMaxPathID(10)).To(Succeed())
+		Expect(m.MaxPathID()).To(BeEquivalentTo(3))
+		frames = getPathNewConnIDFrames()
+		Expect(frames).To(HaveLen(maxPathConnIDs))
+		Expect(frames[0].PathID).To(BeEquivalentTo(3))
+
+		// a smaller maximum path ID doesn't have any effect
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		Expect(m.MaxPathID()).To(BeEquivalentTo(3))
+		Expect(queuedFrames).To(BeEmpty())
+	})
+
+	It("issues a new connection ID when one is retired", func() {
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		frames := getPathNewConnIDFrames()
+		Expect(frames).To(HaveLen(maxPathConnIDs))
+		Expect(m.HandlePathRetireConnectionIDFrame(&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}, protocol.ConnectionID{})).To(Succeed())
+		Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{frames[0].ConnectionID}))
+		_, ok := m.PathForConnID(frames[0].ConnectionID)
+		Expect(ok).To(BeFalse())
+		newFrames := getPathNewConnIDFrames()
+		Expect(newFrames).To(HaveLen(1))
+		Expect(newFrames[0].PathID).To(BeEquivalentTo(1))
+		Expect(newFrames[0].SequenceNumber).To(BeEquivalentTo(maxPathConnIDs))
+		// duplicate frames are ignored
+		Expect(m.HandlePathRetireConnectionIDFrame(&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}, protocol.ConnectionID{})).To(Succeed())
+		Expect(queuedFrames).To(BeEmpty())
+	})
+
+	It("errors when a connection ID is retired that was never issued", func() {
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		Expect(m.HandlePathRetireConnectionIDFrame(&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 10}, protocol.ConnectionID{})).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "retired connection ID 10 for path 1, which was never issued",
+		}))
+	})
+
+	It("errors when the connection ID the packet was sent to is retired", func() {
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		frames := getPathNewConnIDFrames()
+		err := m.HandlePathRetireConnectionIDFrame(&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}, frames[0].ConnectionID)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+	})
+
+	It("stores the connection IDs issued by the peer", func() {
+		_, ok := m.DestConnID(1)
+		Expect(ok).To(BeFalse())
+		c1 := protocol.ParseConnectionID([]byte{1, 1, 1, 1})
+		c2 := protocol.ParseConnectionID([]byte{2, 2, 2, 2})
+		Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: c2, StatelessResetToken: connIDToToken(c2)},
+		})).To(Succeed())
+		Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 0, ConnectionID: c1, StatelessResetToken: connIDToToken(c1)},
+		})).To(Succeed())
+		Expect(addedTokens).To(Equal([]protocol.StatelessResetToken{connIDToToken(c2), connIDToToken(c1)}))
+		connID, ok := m.DestConnID(1)
+		Expect(ok).To(BeTrue())
+		Expect(connID).To(Equal(c1))
+
+		// retire the first connection ID
+		c3 := protocol.ParseConnectionID([]byte{3, 3, 3, 3})
+		Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 2, RetirePriorTo: 1, ConnectionID: c3, StatelessResetToken: connIDToToken(c3)},
+		})).To(Succeed())
+		Expect(queuedFrames).To(Equal([]wire.Frame{&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}}))
+		Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{connIDToToken(c1)}))
+		connID, _ = m.DestConnID(1)
+		Expect(connID).To(Equal(c2))
+	})
+
+	It("errors when the peer issues conflicting connection IDs", func() {
+		c1 := protocol.ParseConnectionID([]byte{1, 1, 1, 1})
+		c2 := protocol.ParseConnectionID([]byte{2, 2, 2, 2})
+		Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 0, ConnectionID: c1},
+		})).To(Succeed())
+		err := m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 0, ConnectionID: c2},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+	})
+
+	It("errors when the peer issues connection IDs for a path ID that's too large", func() {
+		err := m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               4,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{ConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4})},
+		})
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: "received connection ID for path 4 (maximum path ID: 3)",
+		}))
+	})
+
+	It("errors when the peer issues too many connection IDs for a path", func() {
+		for i := 0; i < protocol.MaxActiveConnectionIDs; i++ {
+			c := protocol.ParseConnectionID([]byte{byte(i), 1, 2, 3})
+			Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+				PathID:               2,
+				NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: uint64(i), ConnectionID: c},
+			})).To(Succeed())
+		}
+		err := m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               2,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 100, ConnectionID: protocol.ParseConnectionID([]byte{0xff, 1, 2, 3})},
+		})
+		Expect(err).To(MatchError(&qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}))
+	})
+
+	It("abandons paths", func() {
+		Expect(m.SetPeerMaxPathID(2)).To(Succeed())
+		queuedFrames = nil
+		c := protocol.ParseConnectionID([]byte{1, 1, 1, 1})
+		Expect(m.HandlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:               1,
+			NewConnectionIDFrame: wire.NewConnectionIDFrame{SequenceNumber: 0, ConnectionID: c, StatelessResetToken: connIDToToken(c)},
+		})).To(Succeed())
+		m.AddPath(&mpPath{id: 2, validated: true})
+		m.AddPath(&mpPath{id: 1, validated: true})
+		Expect(m.HasActivePaths()).To(BeTrue())
+		paths := m.Paths()
+		Expect(paths).To(HaveLen(2))
+		Expect(paths[0].id).To(BeEquivalentTo(1))
+		Expect(paths[1].id).To(BeEquivalentTo(2))
+
+		m.AbandonPath(1)
+		Expect(m.Path(1).abandoned).To(BeTrue())
+		Expect(queuedFrames).To(Equal([]wire.Frame{&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}}))
+		Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{connIDToToken(c)}))
+		_, ok := m.DestConnID(1)
+		Expect(ok).To(BeFalse())
+		Expect(m.Paths()).To(HaveLen(1))
+		m.AbandonPath(2)
+		Expect(m.HasActivePaths()).To(BeFalse())
+
+		// no new connection IDs are issued for abandoned paths
+		queuedFrames = nil
+		Expect(m.HandlePathRetireConnectionIDFrame(&wire.PathRetireConnectionIDFrame{PathID: 1, SequenceNumber: 0}, protocol.ConnectionID{})).To(Succeed())
+		Expect(queuedFrames).To(BeEmpty())
+	})
+
+	It("removes all connection IDs", func() {
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		Expect(addedConnIDs).To(HaveLen(maxPathConnIDs))
+		m.RemoveAll()
+		Expect(removedConnIDs).To(ConsistOf(addedConnIDs))
+	})
+
+	It("replaces all connection IDs with a closed connection", func() {
+		Expect(m.SetPeerMaxPathID(1)).To(Succeed())
+		m.ReplaceWithClosed([]byte("connection close"))
+		Expect(replacedWithClosed).To(ConsistOf(addedConnIDs))
+	})
+})
@@ -22,6 +22,8 @@ compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/header Fuzz header_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/transportparameters Fuzz transportparameter_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/tokens Fuzz token_fuzzer
 compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/handshake Fuzz handshake_fuzzer
+compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/fecframes Fuzz fec_frame_fuzzer
+compile_go_fuzzer github.com/quic-go/quic-go/fuzzing/fecdecoder Fuzz fec_decoder_fuzzer
 
 if [ $SANITIZER == "coverage" ]; then
     # no need for corpora if coverage
@@ -36,6 +38,8 @@ zip --quiet -r $OUT/header_fuzzer_seed_corpus.zip fuzzing/header/corpus
 zip --quiet -r $OUT/frame_fuzzer_seed_corpus.zip fuzzing/frames/corpus
 zip --quiet -r $OUT/transportparameter_fuzzer_seed_corpus.zip fuzzing/transportparameters/corpus
 zip --quiet -r $OUT/handshake_fuzzer_seed_corpus.zip fuzzing/handshake/corpus
+zip --quiet -r $OUT/fec_frame_fuzzer_seed_corpus.zip fuzzing/fecframes/corpus
+zip --quiet -r $OUT/fec_decoder_fuzzer_seed_corpus.zip fuzzing/fecdecoder/corpus
 )
 
 # for debugging
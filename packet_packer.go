@@ -14,6 +14,7 @@ import (
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/quicvarint"
 )
 
 var errNothingToPack = errors.New("nothing to pack")
@@ -26,9 +27,15 @@ type packer interface {
 	PackConnectionClose(*qerr.TransportError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PackPathAckOnlyPacket(path *packerPath, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	AppendPathPacket(buf *packetBuffer, path *packerPath, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, error)
+	PackMultipathProbePacket(path *packerPath, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PreferredRepairPaths(paths pathSet) pathSet
 
 	SetToken([]byte)
-	SetFECSender(fec.Sender)
+	SetFECScheme(protocol.DecoderFECScheme, protocol.FECWireFormat) error
+	SetFECPeerWindow(protocol.FECWindowSize)
 }
 
 type sealer interface {
@@ -41,12 +48,17 @@ type payload struct {
 
 	ack    *wire.AckFrame
 	length protocol.ByteCount
+	// pathID is the path of a multipath connection that the packet is sent on.
+	// On paths other than the initial path, the ACK is sent in a PATH_ACK frame.
+	pathID protocol.PathID
 
 	// fec
 	// fecFrames represents all the frames other than stream frames to go within a SOURCE_SYMBOL frame.
 	fecFrames []ackhandler.Frame
 	// fecStreamFrames represents all the stream frames to go within a SOURCE_SYMBOL frame.
 	fecStreamFrames []ackhandler.StreamFrame
+	// fecClass is the protection class of the SOURCE_SYMBOL frame.
+	fecClass protocol.FECProtectionClass
 }
 
 type longHeaderPacket struct {
@@ -116,6 +128,7 @@ type sealingManager interface {
 type frameSource interface {
 	HasData() bool
 	AppendStreamFrames([]ackhandler.StreamFrame, protocol.ByteCount, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
+	AppendFECStreamFrames(frames []ackhandler.StreamFrame, maxLen protocol.ByteCount, class protocol.FECProtectionClass, hasClass bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount, protocol.Version) ([]ackhandler.Frame, protocol.ByteCount)
 }
 
@@ -123,6 +136,20 @@ type ackFrameSource interface {
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
 }
 
+// A packerPath is a path of a multipath connection that a 1-RTT packet is packed for.
+// Every path has its own packet number space, its own ACK state and its own connection IDs.
+type packerPath struct {
+	id        protocol.PathID
+	connID    protocol.ConnectionID
+	pnManager packetNumberManager
+	acks      ackFrameSource
+	sealer    handshake.ShortHeaderSealer // the 1-RTT sealer is used if nil
+	// otherPaths are the other paths that packets are currently sent on.
+	// If repairPathDiversity is set, REPAIR frames are preferably sent on a different path than their source symbols.
+	otherPaths          pathSet
+	repairPathDiversity bool
+}
+
 type packetPacker struct {
 	srcConnID     protocol.ConnectionID
 	getDestConnID func() protocol.ConnectionID
@@ -144,8 +171,18 @@ type packetPacker struct {
 
 	numNonAckElicitingAcks int
 
-	fecSender   fec.Sender
+	fecScheme     protocol.DecoderFECScheme
+	fecWireFormat protocol.FECWireFormat
+	// Every protection class has its own FEC sender, and therefore its own sequence of blocks.
+	// The hybrid ARQ of a class is created when the first SOURCE_SYMBOL frame of that class is sent.
+	hybridARQs  [protocol.MaxFECProtectionClass + 1]*hybridARQ
 	repairQueue *repairQueue
+	// fecPeerWindow is the size of the peer's decoding window, if the peer advertised it in a FEC_WINDOW frame.
+	fecPeerWindow    protocol.FECWindowSize
+	hasFECPeerWindow bool
+	// fecControlFrames are the types of control frames that are FEC protected, using fecControlFrameClass.
+	fecControlFrames     protocol.FECControlFrames
+	fecControlFrameClass protocol.FECProtectionClass
 }
 
 var _ packer = &packetPacker{}
@@ -193,24 +230,28 @@ func newPacketPackerWithFEC(
 	datagramQueue *datagramQueue,
 	perspective protocol.Perspective,
 	repairQueue *repairQueue,
+	fecControlFrames protocol.FECControlFrames,
+	fecControlFrameClass protocol.FECProtectionClass,
 ) *packetPacker {
 	var b [8]byte
 	_, _ = crand.Read(b[:])
 
 	return &packetPacker{
-		cryptoSetup:         cryptoSetup,
-		getDestConnID:       getDestConnID,
-		srcConnID:           srcConnID,
-		initialStream:       initialStream,
-		handshakeStream:     handshakeStream,
-		retransmissionQueue: retransmissionQueue,
-		datagramQueue:       datagramQueue,
-		perspective:         perspective,
-		framer:              framer,
-		acks:                acks,
-		rand:                *rand.New(rand.NewSource(binary.BigEndian.Uint64(b[:]))),
-		pnManager:           packetNumberManager,
-		repairQueue:         repairQueue,
+		cryptoSetup:          cryptoSetup,
+		getDestConnID:        getDestConnID,
+		srcConnID:            srcConnID,
+		initialStream:        initialStream,
+		handshakeStream:      handshakeStream,
+		retransmissionQueue:  retransmissionQueue,
+		datagramQueue:        datagramQueue,
+		perspective:          perspective,
+		framer:               framer,
+		acks:                 acks,
+		rand:                 *rand.New(rand.NewSource(binary.BigEndian.Uint64(b[:]))),
+		pnManager:            packetNumberManager,
+		repairQueue:          repairQueue,
+		fecControlFrames:     fecControlFrames,
+		fecControlFrameClass: fecControlFrameClass,
 	}
 }
 
@@ -319,7 +360,7 @@ func (p *packetPacker) packConnectionClose(
 			paddingLen = p.initialPaddingLen(payloads[i].frames, size, maxPacketSize)
 		}
 		if encLevel == protocol.Encryption1RTT {
-			shp, err := p.appendShortHeaderPacket(buffer, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, keyPhase, payloads[i], paddingLen, maxPacketSize, sealers[i], false, v)
+			shp, err := p.appendShortHeaderPacket(buffer, p.pnManager, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, keyPhase, payloads[i], paddingLen, maxPacketSize, sealers[i], false, v)
 			if err != nil {
 				return nil, err
 			}
@@ -426,7 +467,7 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxPacketSize protocol.
 			connID = p.getDestConnID()
 			oneRTTPacketNumber, oneRTTPacketNumberLen = p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 			hdrLen := wire.ShortHeaderLen(connID, oneRTTPacketNumberLen)
-			oneRTTPayload = p.maybeGetShortHeaderPacket(oneRTTSealer, hdrLen, maxPacketSize-size, onlyAck, size == 0, v)
+			oneRTTPayload = p.maybeGetShortHeaderPacket(nil, oneRTTSealer, hdrLen, maxPacketSize-size, p.maxRepairFrameSize(maxPacketSize, oneRTTSealer), onlyAck, size == 0, v)
 			if oneRTTPayload.length > 0 {
 				size += p.shortHeaderPacketLength(connID, oneRTTPacketNumberLen, oneRTTPayload) + protocol.ByteCount(oneRTTSealer.Overhead())
 			}
@@ -476,7 +517,7 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxPacketSize protocol.
 		}
 		packet.longHdrPackets = append(packet.longHdrPackets, longHdrPacket)
 	} else if oneRTTPayload.length > 0 {
-		shp, err := p.appendShortHeaderPacket(buffer, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, kp, oneRTTPayload, 0, maxPacketSize, oneRTTSealer, false, v)
+		shp, err := p.appendShortHeaderPacket(buffer, p.pnManager, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, kp, oneRTTPayload, 0, maxPacketSize, oneRTTSealer, false, v)
 		if err != nil {
 			return nil, err
 		}
@@ -489,31 +530,57 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxPacketSize protocol.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) PackAckOnlyPacket(maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
 	buf := getPacketBuffer()
-	packet, err := p.appendPacket(buf, true, maxPacketSize, v)
+	packet, err := p.appendPacket(buf, nil, true, maxPacketSize, v)
 	return packet, buf, err
 }
 
 // AppendPacket packs a packet in the application data packet number space.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) AppendPacket(buf *packetBuffer, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, error) {
-	return p.appendPacket(buf, false, maxPacketSize, v)
+	return p.appendPacket(buf, nil, false, maxPacketSize, v)
 }
 
-func (p *packetPacker) appendPacket(buf *packetBuffer, onlyAck bool, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, error) {
-	sealer, err := p.cryptoSetup.Get1RTTSealer()
-	if err != nil {
-		return shortHeaderPacket{}, err
+// PackPathAckOnlyPacket packs a packet containing only an ACK for a path of a multipath connection.
+func (p *packetPacker) PackPathAckOnlyPacket(path *packerPath, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	buf := getPacketBuffer()
+	packet, err := p.appendPacket(buf, path, true, maxPacketSize, v)
+	return packet, buf, err
+}
+
+// AppendPathPacket packs a packet that is sent on a path of a multipath connection.
+// Packets sent on the initial path can be packed using AppendPathPacket as well.
+func (p *packetPacker) AppendPathPacket(buf *packetBuffer, path *packerPath, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, error) {
+	return p.appendPacket(buf, path, false, maxPacketSize, v)
+}
+
+// appendPacket packs a 1-RTT packet.
+// If path is nil, the packet is packed for the initial path.
+// If the path doesn't have a sealer, the 1-RTT sealer is used.
+func (p *packetPacker) appendPacket(buf *packetBuffer, path *packerPath, onlyAck bool, maxPacketSize protocol.ByteCount, v protocol.Version) (shortHeaderPacket, error) {
+	var sealer handshake.ShortHeaderSealer
+	var connID protocol.ConnectionID
+	pnManager := p.pnManager
+	if path == nil {
+		connID = p.getDestConnID()
+	} else {
+		sealer, connID, pnManager = path.sealer, path.connID, path.pnManager
 	}
-	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
-	connID := p.getDestConnID()
+	if sealer == nil {
+		var err error
+		sealer, err = p.cryptoSetup.Get1RTTSealer()
+		if err != nil {
+			return shortHeaderPacket{}, err
+		}
+	}
+	pn, pnLen := pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	hdrLen := wire.ShortHeaderLen(connID, pnLen)
-	pl := p.maybeGetShortHeaderPacket(sealer, hdrLen, maxPacketSize, onlyAck, true, v)
+	pl := p.maybeGetShortHeaderPacket(path, sealer, hdrLen, maxPacketSize, p.maxRepairFrameSize(maxPacketSize, sealer), onlyAck, true, v)
 	if pl.length == 0 {
 		return shortHeaderPacket{}, errNothingToPack
 	}
 	kp := sealer.KeyPhase()
 
-	return p.appendShortHeaderPacket(buf, connID, pn, pnLen, kp, pl, 0, maxPacketSize, sealer, false, v)
+	return p.appendShortHeaderPacket(buf, pnManager, connID, pn, pnLen, kp, pl, 0, maxPacketSize, sealer, false, v)
 }
 
 func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize protocol.ByteCount, encLevel protocol.EncryptionLevel, onlyAck, ackAllowed bool, v protocol.Version) (*wire.ExtendedHeader, payload) {
@@ -595,16 +662,28 @@ func (p *packetPacker) maybeGetAppDataPacketFor0RTT(sealer sealer, maxPacketSize
 
 	hdr := p.getLongHeader(protocol.Encryption0RTT, v)
 	maxPayloadSize := maxPacketSize - hdr.GetLength(v) - protocol.ByteCount(sealer.Overhead())
-	return hdr, p.maybeGetAppDataPacket(maxPayloadSize, false, false, v)
+	return hdr, p.maybeGetAppDataPacket(nil, maxPayloadSize, p.maxRepairFrameSize(maxPacketSize, sealer), false, false, v)
 }
 
-func (p *packetPacker) maybeGetShortHeaderPacket(sealer handshake.ShortHeaderSealer, hdrLen protocol.ByteCount, maxPacketSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
+func (p *packetPacker) maybeGetShortHeaderPacket(path *packerPath, sealer handshake.ShortHeaderSealer, hdrLen protocol.ByteCount, maxPacketSize, maxRepairFrameSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
 	maxPayloadSize := maxPacketSize - hdrLen - protocol.ByteCount(sealer.Overhead())
-	return p.maybeGetAppDataPacket(maxPayloadSize, onlyAck, ackAllowed, v)
+	return p.maybeGetAppDataPacket(path, maxPayloadSize, maxRepairFrameSize, onlyAck, ackAllowed, v)
+}
+
+// maxRepairFrameSize returns the maximum size of a REPAIR frame, such that it fits into a 1-RTT packet of maxPacketSize bytes.
+// The REPAIR frames of a block might be sent in packets with a longer packet number than its source symbols,
+// so the size is calculated for the longest packet number.
+func (p *packetPacker) maxRepairFrameSize(maxPacketSize protocol.ByteCount, sealer sealer) protocol.ByteCount {
+	return maxPacketSize - wire.ShortHeaderLen(p.getDestConnID(), protocol.PacketNumberLen4) - protocol.ByteCount(sealer.Overhead())
 }
 
-func (p *packetPacker) maybeGetAppDataPacket(maxPayloadSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
-	pl := p.composeNextPacket(maxPayloadSize, onlyAck, ackAllowed, v)
+// maybeGetAppDataPacket composes the payload of an application data packet.
+// If path is nil, the packet is packed for the initial path.
+func (p *packetPacker) maybeGetAppDataPacket(path *packerPath, maxPayloadSize, maxRepairFrameSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
+	pl := p.composeNextPacket(path, maxPayloadSize, maxRepairFrameSize, onlyAck, ackAllowed, v)
+	// The length of a long header packet is encoded in its header,
+	// so the length of the payload needs to include the header of the SOURCE_SYMBOL frame.
+	pl.length += p.sourceSymbolHeaderLen(pl, v)
 
 	// check if we have anything to send
 	if len(pl.frames) == 0 && len(pl.streamFrames) == 0 && len(pl.fecFrames) == 0 && len(pl.fecStreamFrames) == 0 {
@@ -626,49 +705,140 @@ func (p *packetPacker) maybeGetAppDataPacket(maxPayloadSize protocol.ByteCount,
 	return pl
 }
 
-func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
+// ackFrameLength returns the length of the ACK frame of a packet sent on the given path.
+// On paths other than the initial path, it is sent as a PATH_ACK frame.
+func ackFrameLength(pathID protocol.PathID, ack *wire.AckFrame, v protocol.Version) protocol.ByteCount {
+	if pathID == protocol.InitialPathID {
+		return ack.Length(v)
+	}
+	return (&wire.PathAckFrame{PathID: pathID, AckFrame: *ack}).Length(v)
+}
+
+// canSendRepairOnPath says if a REPAIR frame can be sent on a path.
+// If repair path diversity is enabled, REPAIR frames are not sent on a path that carried source symbols of their block,
+// unless all other paths carried source symbols of that block as well.
+func (p *packetPacker) canSendRepairOnPath(path *packerPath, f *wire.RepairFrame) bool {
+	if path == nil || !path.repairPathDiversity || path.otherPaths == 0 {
+		return true
+	}
+	sourcePaths := p.hybridARQs[f.Class].SourcePaths(f.Metadata.BlockID)
+	return !sourcePaths.Contains(path.id) || path.otherPaths&^sourcePaths == 0
+}
+
+// PreferredRepairPaths returns the paths that the next REPAIR frame should be sent on.
+// It returns 0 if no REPAIR frame is queued, or if it can be sent on any of the paths.
+func (p *packetPacker) PreferredRepairPaths(paths pathSet) pathSet {
+	if p.repairQueue == nil {
+		return 0
+	}
+	f := p.repairQueue.Peek()
+	if f == nil || p.hybridARQs[f.Class] == nil {
+		return 0
+	}
+	preferred := paths &^ p.hybridARQs[f.Class].SourcePaths(f.Metadata.BlockID)
+	if preferred == paths {
+		return 0
+	}
+	return preferred
+}
+
+// sourceSymbolHeaderLen returns the length of the header of the SOURCE_SYMBOL frame wrapping the FEC protected frames of the payload.
+// It returns 0 if the payload doesn't contain any FEC protected frames.
+func (p *packetPacker) sourceSymbolHeaderLen(pl payload, v protocol.Version) protocol.ByteCount {
+	if len(pl.fecFrames) == 0 && len(pl.fecStreamFrames) == 0 {
+		return 0
+	}
+	class := pl.fecClass
+	if p.fecWireFormat == protocol.FECWireFormatDraft {
+		class = protocol.FECProtectionDefault
+	}
+	arq, err := p.hybridARQ(class)
+	if err != nil {
+		// The error is returned when the packet is serialized.
+		return 0
+	}
+	var payloadLen protocol.ByteCount
+	for _, f := range pl.fecFrames {
+		payloadLen += f.Frame.Length(v)
+	}
+	for _, f := range pl.fecStreamFrames {
+		payloadLen += f.Frame.Length(v)
+	}
+	return wire.SourceSymbolFrameHeaderLen(p.fecWireFormat, class, arq.sender.PeekSSID(), payloadLen)
+}
+
+func (p *packetPacker) composeNextPacket(path *packerPath, maxFrameSize, maxRepairFrameSize protocol.ByteCount, onlyAck, ackAllowed bool, v protocol.Version) payload {
+	acks := p.acks
+	var pathID protocol.PathID
+	if path != nil {
+		acks = path.acks
+		pathID = path.id
+	}
 	if onlyAck {
-		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, true); ack != nil {
-			return payload{ack: ack, length: ack.Length(v)}
+		if ack := acks.GetAckFrame(protocol.Encryption1RTT, true); ack != nil {
+			return payload{ack: ack, length: ackFrameLength(pathID, ack, v), pathID: pathID}
 		}
 		return payload{}
 	}
 
 	hasData := p.framer.HasData()
 	hasRetransmission := p.retransmissionQueue.HasAppData()
+	// If there's nothing else to send, the blocks that are currently being sent won't be completed any time soon.
+	// Their lost frames are retransmitted, such that they can be sent in this packet.
+	if !hasData && !hasRetransmission && p.retransmitIncompleteBlocks(nil) {
+		hasData = p.framer.HasData()
+		hasRetransmission = p.retransmissionQueue.HasAppData()
+	}
 
 	var hasAck bool
-	var pl payload
+	pl := payload{pathID: pathID}
 	if ackAllowed {
-		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData); ack != nil {
+		if ack := acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData); ack != nil {
 			pl.ack = ack
-			pl.length += ack.Length(v)
+			pl.length += ackFrameLength(pathID, ack, v)
 			hasAck = true
 		}
 	}
 
 	addedRepairFrame := false
 	if p.repairQueue != nil {
-		if f := p.repairQueue.Peek(); f != nil {
+		if f := p.repairQueue.Peek(); f != nil && p.canSendRepairOnPath(path, f) {
+			f.Format = p.fecWireFormat
 			size := f.Length(v)
 			if size <= maxFrameSize-pl.length { // Repair frame fits
-				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
+				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f, Handler: p.hybridARQs[f.Class]})
 				pl.length += size
 				p.repairQueue.Pop()
 				addedRepairFrame = true
-			} else if !hasAck {
-				// this means the repair frame can't fit, which is a serious problem and shouldn't be possible.
-				panic(fmt.Sprintf("no space for repair frame: frame size %d, remaining space %d", size, maxFrameSize-pl.length))
+			} else if !hasAck && (path == nil || path.otherPaths == 0) {
+				// The repair frame was generated for a larger maximum packet size,
+				// and the connection has since migrated to a path with a smaller maximum packet size.
+				// It can never be sent, the lost source symbols of its block are retransmitted instead.
+				// On a multipath connection, it might still fit into a packet sent on another path.
+				p.repairQueue.Pop()
+				p.hybridARQs[f.Class].DropRepairSymbol(f)
 			}
 		}
 	}
 
+	// fecFramesLen is the length of the FEC protected frames other than STREAM frames.
+	// fecHeaderLen is the space reserved for the header of the SOURCE_SYMBOL frame.
+	var fecFramesLen, fecHeaderLen protocol.ByteCount
 	if p.datagramQueue != nil {
 		if f := p.datagramQueue.Peek(); f != nil {
 			size := f.Length(v)
-			if size <= maxFrameSize-pl.length { // DATAGRAM frame fits
-				if f.FECProtected {
+			maxSize := maxFrameSize - pl.length
+			// If the peer's decoding window is exhausted, the DATAGRAM frame is sent without FEC protection.
+			protected := f.FECProtected && p.canFECProtect(f.FECProtectionClass)
+			if protected {
+				maxSize = p.maxSourceSymbolLen(maxSize, maxRepairFrameSize, f.FECProtectionClass, true, v)
+			}
+			if size <= maxSize { // DATAGRAM frame fits
+				if protected {
 					pl.fecFrames = append(pl.fecFrames, ackhandler.Frame{Frame: f})
+					pl.fecClass = f.FECProtectionClass
+					fecFramesLen = size
+					fecHeaderLen = maxFrameSize - pl.length - maxSize
 				} else {
 					pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
 				}
@@ -678,7 +848,7 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 				// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
 				// Discard this frame. There's no point in retrying this in the next packet,
 				// as it's unlikely that the available packet size will increase.
-				p.datagramQueue.Pop()
+				p.datagramQueue.Drop()
 			}
 			// If the DATAGRAM frame was too large and the packet contained an ACK, we'll try to send it out later.
 		}
@@ -688,9 +858,36 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		return pl
 	}
 
+	// Control frames are only FEC protected if the packet doesn't carry FEC protected frames of a different class.
+	protectControlFrames := p.fecControlFrames != 0 && p.canFECProtect(p.fecControlFrameClass) &&
+		(len(pl.fecFrames) == 0 || pl.fecClass == p.fecControlFrameClass || p.fecWireFormat == protocol.FECWireFormatDraft)
+	// maxControlFrameLen is the space available for control frames.
+	// If control frames might be FEC protected, they need to fit into the SOURCE_SYMBOL frame.
+	maxControlFrameLen := func() protocol.ByteCount {
+		maxLen := maxFrameSize - fecHeaderLen - pl.length
+		if protectControlFrames {
+			maxLen = min(maxLen, p.maxSourceSymbolLen(maxFrameSize-(pl.length-fecFramesLen), maxRepairFrameSize, p.fecControlFrameClass, true, v)-fecFramesLen)
+		}
+		return maxLen
+	}
+	// addControlFrame adds a control frame to the payload.
+	// The frame is moved into the SOURCE_SYMBOL frame if its type is FEC protected.
+	// Its handler is kept, such that it is retransmitted if the peer can't recover it.
+	addControlFrame := func(f ackhandler.Frame) {
+		if !protectControlFrames || !p.fecControlFrames.Contains(fecControlFrameType(f.Frame)) {
+			pl.frames = append(pl.frames, f)
+			return
+		}
+		if len(pl.fecFrames) == 0 && len(pl.fecStreamFrames) == 0 {
+			pl.fecClass = p.fecControlFrameClass
+		}
+		pl.fecFrames = append(pl.fecFrames, f)
+		fecFramesLen += f.Frame.Length(v)
+	}
+
 	if hasRetransmission {
 		for {
-			remainingLen := maxFrameSize - pl.length
+			remainingLen := maxControlFrameLen()
 			if remainingLen < protocol.MinStreamFrameSize {
 				break
 			}
@@ -698,53 +895,111 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 			if f == nil {
 				break
 			}
-			pl.frames = append(pl.frames, ackhandler.Frame{Frame: f, Handler: p.retransmissionQueue.AppDataAckHandler()})
+			addControlFrame(ackhandler.Frame{Frame: f, Handler: p.retransmissionQueue.AppDataAckHandler()})
 			pl.length += f.Length(v)
 		}
 	}
 
 	if hasData {
 		var lengthAdded protocol.ByteCount
-		startLen := len(pl.frames)
-		pl.frames, lengthAdded = p.framer.AppendControlFrames(pl.frames, maxFrameSize-pl.length, v)
+		var controlFrames []ackhandler.Frame
+		controlFrames, lengthAdded = p.framer.AppendControlFrames(controlFrames, maxControlFrameLen(), v)
 		pl.length += lengthAdded
-		// add handlers for the control frames that were added
-		for i := startLen; i < len(pl.frames); i++ {
-			switch pl.frames[i].Frame.(type) {
+		for _, f := range controlFrames {
+			// add handlers for the control frames that were added
+			switch f.Frame.(type) {
 			case *wire.PathChallengeFrame, *wire.PathResponseFrame:
-				// Path probing is currently not supported, therefore we don't need to set the OnAcked callback yet.
-				// PATH_CHALLENGE and PATH_RESPONSE are never retransmitted.
+				// PATH_CHALLENGE and PATH_RESPONSE are never retransmitted, so they don't need a handler.
+				// The path managers send a new PATH_CHALLENGE if no PATH_RESPONSE is received in time,
+				// and the peer responds to it with a new PATH_RESPONSE.
 			default:
-				pl.frames[i].Handler = p.retransmissionQueue.AppDataAckHandler()
+				f.Handler = p.retransmissionQueue.AppDataAckHandler()
 			}
+			addControlFrame(f)
 		}
 
-		fecEnabled := p.fecSender != nil
+		fecEnabled := p.fecScheme != protocol.FECDisabled
 		var streamFrames []ackhandler.StreamFrame
 		if fecEnabled {
-			streamFrames, lengthAdded = p.framer.AppendStreamFrames(pl.streamFrames, maxFrameSize-pl.length-protocol.MaxFECHeaderOverhead, v)
-
+			// The FEC protected STREAM frames are added to the payload of the SOURCE_SYMBOL frame.
+			// The unprotected STREAM frames are limited to the same length, since the framer doesn't distinguish them.
+			maxLen := p.maxSourceSymbolLen(maxFrameSize-(pl.length-fecFramesLen), maxRepairFrameSize, pl.fecClass, len(pl.fecFrames) > 0, v) - fecFramesLen
+			// STREAM frames inside a SOURCE_SYMBOL frame always carry a Data Length field (see below),
+			// so we need to reserve the space the framer expects to save on the last STREAM frame.
+			// A packet contains at most one SOURCE_SYMBOL frame, so all FEC protected frames need to be of the same class.
+			streamFrames, lengthAdded = p.framer.AppendFECStreamFrames(pl.streamFrames, maxLen-quicvarint.Len(uint64(max(maxLen, 0))), pl.fecClass, len(pl.fecFrames) > 0, v)
 		} else {
 			streamFrames, lengthAdded = p.framer.AppendStreamFrames(pl.streamFrames, maxFrameSize-pl.length, v)
 		}
 
 		for _, streamFrame := range streamFrames {
+			// If the peer's decoding window is exhausted, the STREAM frame is sent without FEC protection.
+			if streamFrame.Frame.FECProtected && fecEnabled && !p.canFECProtect(streamFrame.Frame.FECProtectionClass) {
+				streamFrame.Frame.FECProtected = false
+			}
 			if streamFrame.Frame.FECProtected {
 				if !fecEnabled {
 					// this should never happen
 					panic(fmt.Sprintf("fec.Sender doesn't exist but there is a FEC protected stream frame: perspective %s", p.perspective.String()))
 				}
+				// The payloads of multiple recovered source symbols are concatenated by the receiver,
+				// so every frame within a source symbol needs to be self-delimiting.
+				if !streamFrame.Frame.DataLenPresent {
+					l := streamFrame.Frame.Length(v)
+					streamFrame.Frame.DataLenPresent = true
+					lengthAdded += streamFrame.Frame.Length(v) - l
+				}
 				pl.fecStreamFrames = append(pl.fecStreamFrames, streamFrame)
+				pl.fecClass = streamFrame.Frame.FECProtectionClass
 			} else {
 				pl.streamFrames = append(pl.streamFrames, streamFrame)
 			}
 		}
+		// In the draft wire format, the SOURCE_SYMBOL frame extends to the end of the packet,
+		// so the STREAM frames written before it need a Data Length field.
+		if p.fecWireFormat == protocol.FECWireFormatDraft && (len(pl.fecStreamFrames) > 0 || len(pl.fecFrames) > 0) {
+			for _, streamFrame := range pl.streamFrames {
+				if !streamFrame.Frame.DataLenPresent {
+					l := streamFrame.Frame.Length(v)
+					streamFrame.Frame.DataLenPresent = true
+					lengthAdded += streamFrame.Frame.Length(v) - l
+				}
+			}
+		}
 
 		pl.length += lengthAdded
 	}
+	// The losses of blocks that this packet doesn't add a source symbol to are retransmitted
+	// instead of waiting for the block to be completed.
+	p.retransmitIncompleteBlocks(&pl)
 	return pl
 }
 
+// retransmitIncompleteBlocks retransmits the lost frames of the blocks that are currently being sent,
+// except for the block that the payload adds a source symbol to.
+// It returns true if any frames were retransmitted.
+func (p *packetPacker) retransmitIncompleteBlocks(pl *payload) bool {
+	extended := protocol.FECProtectionClass(0)
+	extends := pl != nil && (len(pl.fecFrames) > 0 || len(pl.fecStreamFrames) > 0)
+	if extends {
+		extended = pl.fecClass
+		// The draft wire format doesn't support protection classes.
+		if p.fecWireFormat == protocol.FECWireFormatDraft {
+			extended = protocol.FECProtectionDefault
+		}
+	}
+	var retransmitted bool
+	for class, arq := range p.hybridARQs {
+		if arq == nil || (extends && protocol.FECProtectionClass(class) == extended) {
+			continue
+		}
+		if arq.RetransmitIncompleteBlock() {
+			retransmitted = true
+		}
+	}
+	return retransmitted
+}
+
 func (p *packetPacker) MaybePackProbePacket(encLevel protocol.EncryptionLevel, maxPacketSize protocol.ByteCount, v protocol.Version) (*coalescedPacket, error) {
 	if encLevel == protocol.Encryption1RTT {
 		s, err := p.cryptoSetup.Get1RTTSealer()
@@ -755,13 +1010,13 @@ func (p *packetPacker) MaybePackProbePacket(encLevel protocol.EncryptionLevel, m
 		connID := p.getDestConnID()
 		pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 		hdrLen := wire.ShortHeaderLen(connID, pnLen)
-		pl := p.maybeGetAppDataPacket(maxPacketSize-protocol.ByteCount(s.Overhead())-hdrLen, false, true, v)
+		pl := p.maybeGetAppDataPacket(nil, maxPacketSize-protocol.ByteCount(s.Overhead())-hdrLen, p.maxRepairFrameSize(maxPacketSize, s), false, true, v)
 		if pl.length == 0 {
 			return nil, nil
 		}
 		buffer := getPacketBuffer()
 		packet := &coalescedPacket{buffer: buffer}
-		shp, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, 0, maxPacketSize, s, false, v)
+		shp, err := p.appendShortHeaderPacket(buffer, p.pnManager, connID, pn, pnLen, kp, pl, 0, maxPacketSize, s, false, v)
 		if err != nil {
 			return nil, err
 		}
@@ -825,7 +1080,38 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	padding := size - p.shortHeaderPacketLength(connID, pnLen, pl) - protocol.ByteCount(s.Overhead())
 	kp := s.KeyPhase()
-	packet, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, padding, size, s, true, v)
+	packet, err := p.appendShortHeaderPacket(buffer, p.pnManager, connID, pn, pnLen, kp, pl, padding, size, s, true, v)
+	return packet, buffer, err
+}
+
+// PackPathProbePacket packs a packet that is sent on a path other than the active path.
+// It contains the path validation frames, and is padded to size bytes.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	s, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return shortHeaderPacket{}, nil, err
+	}
+	return p.packPathProbePacket(&packerPath{connID: connID, pnManager: p.pnManager, acks: p.acks, sealer: s}, frames, size, v)
+}
+
+// PackMultipathProbePacket packs a packet containing the given frames on a path of a multipath connection.
+// It is used for path validation, and for PTO probe packets. The packet is padded to size bytes.
+func (p *packetPacker) PackMultipathProbePacket(path *packerPath, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	return p.packPathProbePacket(path, frames, size, v)
+}
+
+func (p *packetPacker) packPathProbePacket(path *packerPath, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	pl := payload{frames: frames}
+	for _, f := range frames {
+		pl.length += f.Frame.Length(v)
+	}
+	buffer := getPacketBuffer()
+	pn, pnLen := path.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	var padding protocol.ByteCount
+	if l := p.shortHeaderPacketLength(path.connID, pnLen, pl) + protocol.ByteCount(path.sealer.Overhead()); l < size {
+		padding = size - l
+	}
+	packet, err := p.appendShortHeaderPacket(buffer, path.pnManager, path.connID, pn, pnLen, path.sealer.KeyPhase(), pl, padding, size, path.sealer, false, v)
 	return packet, buffer, err
 }
 
@@ -869,7 +1155,7 @@ func (p *packetPacker) appendLongHeaderPacket(buffer *packetBuffer, header *wire
 	}
 	payloadOffset := protocol.ByteCount(len(raw))
 
-	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
+	raw, ssf, err := p.appendPacketPayload(raw, pl, paddingLen, v)
 	if err != nil {
 		return nil, err
 	}
@@ -879,17 +1165,33 @@ func (p *packetPacker) appendLongHeaderPacket(buffer *packetBuffer, header *wire
 	if pn := p.pnManager.PopPacketNumber(encLevel); pn != header.PacketNumber {
 		return nil, fmt.Errorf("packetPacker BUG: Peeked and Popped packet numbers do not match: expected %d, got %d", pn, header.PacketNumber)
 	}
+	frames, streamFrames := p.sentFrames(ssf, pl)
 	return &longHeaderPacket{
 		header:       header,
 		ack:          pl.ack,
-		frames:       pl.frames,
-		streamFrames: pl.streamFrames,
+		frames:       frames,
+		streamFrames: streamFrames,
 		length:       protocol.ByteCount(len(raw)),
 	}, nil
 }
 
+// sentFrames returns the frames of a sent packet.
+// The frames protected by FEC are acknowledged and lost together with the packet that carried them.
+// The SOURCE_SYMBOL frame itself is never retransmitted.
+// If it is lost, the hybrid ARQ decides whether the protected frames are retransmitted.
+func (p *packetPacker) sentFrames(ssf *wire.SourceSymbolFrame, pl payload) ([]ackhandler.Frame, []ackhandler.StreamFrame) {
+	frames, streamFrames := pl.frames, pl.streamFrames
+	if ssf != nil {
+		fecFrames, fecStreamFrames := p.hybridARQs[ssf.Class].SentSourceSymbol(ssf, pl.fecFrames, pl.fecStreamFrames)
+		frames = append(frames, fecFrames...)
+		streamFrames = append(streamFrames, fecStreamFrames...)
+	}
+	return frames, streamFrames
+}
+
 func (p *packetPacker) appendShortHeaderPacket(
 	buffer *packetBuffer,
+	pnManager packetNumberManager,
 	connID protocol.ConnectionID,
 	pn protocol.PacketNumber,
 	pnLen protocol.PacketNumberLen,
@@ -914,7 +1216,7 @@ func (p *packetPacker) appendShortHeaderPacket(
 	}
 	payloadOffset := protocol.ByteCount(len(raw))
 
-	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
+	raw, ssf, err := p.appendPacketPayload(raw, pl, paddingLen, v)
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
@@ -926,16 +1228,16 @@ func (p *packetPacker) appendShortHeaderPacket(
 	raw = p.encryptPacket(raw, sealer, pn, payloadOffset, protocol.ByteCount(pnLen))
 	buffer.Data = buffer.Data[:len(buffer.Data)+len(raw)]
 
-	if newPN := p.pnManager.PopPacketNumber(protocol.Encryption1RTT); newPN != pn {
+	if newPN := pnManager.PopPacketNumber(protocol.Encryption1RTT); newPN != pn {
 		return shortHeaderPacket{}, fmt.Errorf("packetPacker BUG: Peeked and Popped packet numbers do not match: expected %d, got %d", pn, newPN)
 	}
-	// TODO (ddritzenhoff) should I be doing anything with FEC here?
+	frames, streamFrames := p.sentFrames(ssf, pl)
 	return shortHeaderPacket{
 		PacketNumber:         pn,
 		PacketNumberLen:      pnLen,
 		KeyPhase:             kp,
-		StreamFrames:         pl.streamFrames,
-		Frames:               pl.frames,
+		StreamFrames:         streamFrames,
+		Frames:               frames,
 		Ack:                  pl.ack,
 		Length:               protocol.ByteCount(len(raw)),
 		DestConnID:           connID,
@@ -945,13 +1247,18 @@ func (p *packetPacker) appendShortHeaderPacket(
 
 // appendPacketPayload serializes the payload of a packet into the raw byte slice.
 // It modifies the order of payload.frames.
-func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen protocol.ByteCount, v protocol.Version) ([]byte, error) {
+// If the payload contains FEC protected frames, they are wrapped in a SOURCE_SYMBOL frame, which is returned.
+func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen protocol.ByteCount, v protocol.Version) ([]byte, *wire.SourceSymbolFrame, error) {
 	payloadOffset := len(raw)
 	if pl.ack != nil {
 		var err error
-		raw, err = pl.ack.Append(raw, v)
+		if pl.pathID != protocol.InitialPathID {
+			raw, err = (&wire.PathAckFrame{PathID: pl.pathID, AckFrame: *pl.ack}).Append(raw, v)
+		} else {
+			raw, err = pl.ack.Append(raw, v)
+		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	if paddingLen > 0 {
@@ -966,27 +1273,29 @@ func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen pr
 		var err error
 		raw, err = f.Frame.Append(raw, v)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	for _, f := range pl.streamFrames {
+
+	// The SOURCE_SYMBOL frame is written before the unprotected STREAM frames,
+	// since the last STREAM frame might not have a Data Length field.
+	// In the draft wire format, the SOURCE_SYMBOL frame extends to the end of the packet, so it is written last.
+	draftFormat := p.fecWireFormat == protocol.FECWireFormatDraft
+	if draftFormat {
 		var err error
-		raw, err = f.Frame.Append(raw, v)
+		raw, err = appendStreamFrames(raw, pl.streamFrames, v)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-
-	fecEnabled := false
-	var sourceSymbolHeaderLen protocol.ByteCount
+	var ssf *wire.SourceSymbolFrame
 	if len(pl.fecStreamFrames) > 0 || len(pl.fecFrames) > 0 {
-		fecEnabled = true
 		payload := make([]byte, 0, protocol.MaxPacketBufferSize)
 		for _, f := range pl.fecFrames {
 			var err error
 			payload, err = f.Frame.Append(payload, v)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
@@ -994,34 +1303,54 @@ func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen pr
 			var err error
 			payload, err = f.Frame.Append(payload, v)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
-		ssf := &wire.SourceSymbolFrame{
-			SSID:    p.fecSender.NextSSID(),
-			Payload: payload,
+		// The draft wire format doesn't support protection classes.
+		class := pl.fecClass
+		if draftFormat {
+			class = protocol.FECProtectionDefault
 		}
-		sourceSymbolHeaderLen = ssf.HeaderLen()
-		repairFrames, err := p.fecSender.AddSourceSymbolFrame(ssf)
+		arq, err := p.hybridARQ(class)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		for _, f := range repairFrames {
-			p.repairQueue.Add(f)
+		ssf = &wire.SourceSymbolFrame{
+			Format:  p.fecWireFormat,
+			Class:   class,
+			SSID:    arq.sender.NextSSID(),
+			Payload: payload,
 		}
+		if err := arq.AddSourceSymbol(ssf); err != nil {
+			return nil, nil, err
+		}
+		arq.SentSourceSymbolOnPath(ssf.SSID, pl.pathID)
 		raw, err = ssf.Append(raw, v)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	if fecEnabled {
-		if payloadSize := protocol.ByteCount(len(raw)-payloadOffset) - paddingLen - sourceSymbolHeaderLen; payloadSize != pl.length {
-			return nil, fmt.Errorf("PacketPacker FEC BUG: payload size inconsistent (expected %d, got %d bytes)", pl.length, payloadSize)
+	if !draftFormat {
+		var err error
+		raw, err = appendStreamFrames(raw, pl.streamFrames, v)
+		if err != nil {
+			return nil, nil, err
 		}
-	} else {
-		if payloadSize := protocol.ByteCount(len(raw)-payloadOffset) - paddingLen; payloadSize != pl.length {
-			return nil, fmt.Errorf("PacketPacker BUG: payload size inconsistent (expected %d, got %d bytes)", pl.length, payloadSize)
+	}
+
+	if payloadSize := protocol.ByteCount(len(raw)-payloadOffset) - paddingLen; payloadSize != pl.length {
+		return nil, nil, fmt.Errorf("PacketPacker BUG: payload size inconsistent (expected %d, got %d bytes)", pl.length, payloadSize)
+	}
+	return raw, ssf, nil
+}
+
+func appendStreamFrames(raw []byte, frames []ackhandler.StreamFrame, v protocol.Version) ([]byte, error) {
+	for _, f := range frames {
+		var err error
+		raw, err = f.Frame.Append(raw, v)
+		if err != nil {
+			return nil, err
 		}
 	}
 	return raw, nil
@@ -1040,6 +1369,125 @@ func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
 
-func (p *packetPacker) SetFECSender(s fec.Sender) {
-	p.fecSender = s
+// SetFECScheme enables FEC protection, using the FEC scheme requested by the peer.
+// The FEC frames are encoded in the wire format used by the peer.
+// If the scheme or the wire format changes, all blocks protected with the previous settings are discarded.
+// This happens when 0-RTT is rejected, since the peer never processed the blocks sent in 0-RTT packets.
+func (p *packetPacker) SetFECScheme(scheme protocol.DecoderFECScheme, format protocol.FECWireFormat) error {
+	if scheme != p.fecScheme || format != p.fecWireFormat {
+		p.hybridARQs = [protocol.MaxFECProtectionClass + 1]*hybridARQ{}
+		if p.repairQueue != nil {
+			p.repairQueue.Clear()
+		}
+	}
+	p.fecScheme = scheme
+	p.fecWireFormat = format
+	if scheme == protocol.FECDisabled {
+		return nil
+	}
+	// make sure that the scheme is valid
+	_, err := p.hybridARQ(protocol.FECProtectionDefault)
+	return err
+}
+
+// SetFECPeerWindow sets the size of the peer's decoding window.
+// Frames that would be protected by a source symbol beyond the peer's decoding window are sent without FEC protection.
+func (p *packetPacker) SetFECPeerWindow(size protocol.FECWindowSize) {
+	p.fecPeerWindow = size
+	p.hasFECPeerWindow = true
+	for _, arq := range p.hybridARQs {
+		if arq != nil {
+			arq.SetPeerWindow(size)
+		}
+	}
+}
+
+// canFECProtect says if frames of the given protection class can be FEC protected,
+// i.e. if the next source symbol of that class lies within the peer's decoding window.
+func (p *packetPacker) canFECProtect(class protocol.FECProtectionClass) bool {
+	if p.fecScheme == protocol.FECDisabled {
+		return false
+	}
+	if p.fecWireFormat == protocol.FECWireFormatDraft {
+		class = protocol.FECProtectionDefault
+	}
+	arq, err := p.hybridARQ(class)
+	if err != nil {
+		return false
+	}
+	return arq.CanSendSourceSymbol()
+}
+
+// fecControlFrameType returns the type of a control frame that can be FEC protected.
+// It returns 0 for all other frames.
+func fecControlFrameType(f wire.Frame) protocol.FECControlFrames {
+	switch f.(type) {
+	case *wire.ResetStreamFrame:
+		return protocol.FECControlFrameResetStream
+	case *wire.StopSendingFrame:
+		return protocol.FECControlFrameStopSending
+	case *wire.MaxDataFrame:
+		return protocol.FECControlFrameMaxData
+	case *wire.MaxStreamDataFrame:
+		return protocol.FECControlFrameMaxStreamData
+	case *wire.MaxStreamsFrame:
+		return protocol.FECControlFrameMaxStreams
+	case *wire.DataBlockedFrame:
+		return protocol.FECControlFrameDataBlocked
+	case *wire.StreamDataBlockedFrame:
+		return protocol.FECControlFrameStreamDataBlocked
+	case *wire.StreamsBlockedFrame:
+		return protocol.FECControlFrameStreamsBlocked
+	case *wire.NewConnectionIDFrame:
+		return protocol.FECControlFrameNewConnectionID
+	case *wire.RetireConnectionIDFrame:
+		return protocol.FECControlFrameRetireConnectionID
+	case *wire.NewTokenFrame:
+		return protocol.FECControlFrameNewToken
+	default:
+		return 0
+	}
+}
+
+// maxSourceSymbolLen returns the maximum payload length of the next SOURCE_SYMBOL frame,
+// such that the frame fits into maxFrameSize bytes, and the REPAIR frames of its block fit into maxRepairFrameSize bytes.
+// If hasClass is not set, the protection class is not known yet, so the length needs to be valid for all classes.
+func (p *packetPacker) maxSourceSymbolLen(maxFrameSize, maxRepairFrameSize protocol.ByteCount, class protocol.FECProtectionClass, hasClass bool, v protocol.Version) protocol.ByteCount {
+	minClass, maxClass := class, class
+	if p.fecWireFormat == protocol.FECWireFormatDraft {
+		// The draft wire format doesn't support protection classes.
+		minClass, maxClass = protocol.FECProtectionDefault, protocol.FECProtectionDefault
+	} else if !hasClass {
+		minClass, maxClass = 0, protocol.MaxFECProtectionClass
+	}
+	maxLen := max(maxFrameSize, 0)
+	for c := minClass; c <= maxClass; c++ {
+		arq, err := p.hybridARQ(c)
+		if err != nil {
+			return 0
+		}
+		maxLen = min(maxLen, arq.sender.MaxSourceSymbolLen(maxFrameSize, maxRepairFrameSize, p.fecWireFormat, v))
+	}
+	return maxLen
+}
+
+// hybridARQ returns the hybrid ARQ of a protection class, creating it if necessary.
+func (p *packetPacker) hybridARQ(class protocol.FECProtectionClass) (*hybridARQ, error) {
+	if class > protocol.MaxFECProtectionClass {
+		return nil, fmt.Errorf("invalid FEC protection class: %d", class)
+	}
+	if p.hybridARQs[class] == nil {
+		sender, err := fec.NewSenderWithClass(p.fecScheme, class)
+		if err != nil {
+			return nil, err
+		}
+		if sender == nil {
+			return nil, errors.New("FEC not enabled")
+		}
+		p.hybridARQs[class] = newHybridARQ(sender, p.repairQueue)
+		if p.hasFECPeerWindow {
+			p.hybridARQs[class].SetPeerWindow(p.fecPeerWindow)
+		}
+	}
+	return p.hybridARQs[class], nil
 }
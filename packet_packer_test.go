@@ -95,7 +95,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, protocol.DefaultDatagramSendQueueLen, protocol.DefaultDatagramReceiveQueueLen, nil, utils.DefaultLogger)
 
 		packer = newPacketPacker(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), func() protocol.ConnectionID { return connID }, initialStream, handshakeStream, pnManager, retransmissionQueue, sealingManager, framer, ackFramer, datagramQueue, protocol.PerspectiveServer)
 	})
@@ -320,6 +320,40 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.longHdrPackets[0].frames[0].Handler).ToNot(BeNil())
 			})
 
+			It("packs a FEC protected 0-RTT packet", func() {
+				packer.repairQueue = newRepairQueue(func() {})
+				Expect(packer.SetFECScheme(protocol.ReedSolomonFECScheme, protocol.FECWireFormatLegacy)).To(Succeed())
+				sealingManager.EXPECT().Get0RTTSealer().Return(getSealer(), nil).AnyTimes()
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption0RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption0RTT).Return(protocol.PacketNumber(0x42))
+				framer.EXPECT().HasData().Return(true)
+				expectAppendControlFrames()
+				f := &wire.StreamFrame{StreamID: 4, Data: []byte("foobar"), FECProtected: true}
+				framer.EXPECT().AppendFECStreamFrames(gomock.Any(), gomock.Any(), protocol.FECProtectionDefault, false, protocol.Version1).DoAndReturn(func(frames []ackhandler.StreamFrame, _ protocol.ByteCount, _ protocol.FECProtectionClass, _ bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+					return append(frames, ackhandler.StreamFrame{Frame: f}), f.Length(v)
+				})
+				p, err := packer.PackCoalescedPacket(false, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.longHdrPackets).To(HaveLen(1))
+				Expect(p.longHdrPackets[0].header.Type).To(Equal(protocol.PacketType0RTT))
+				// the protected STREAM frame is acknowledged and lost with the packet
+				Expect(p.longHdrPackets[0].streamFrames).To(HaveLen(1))
+				Expect(p.longHdrPackets[0].streamFrames[0].Frame).To(Equal(f))
+				var ssf *wire.SourceSymbolFrame
+				for _, frame := range p.longHdrPackets[0].frames {
+					if sf, ok := frame.Frame.(*wire.SourceSymbolFrame); ok {
+						ssf = sf
+					}
+				}
+				Expect(ssf).ToNot(BeNil())
+				Expect(ssf.SSID).To(BeZero())
+				// the Length field in the header needs to account for the SOURCE_SYMBOL frame
+				hdrs, more := parsePacket(p.buffer.Data)
+				Expect(hdrs).To(HaveLen(1))
+				Expect(hdrs[0].Type).To(Equal(protocol.PacketType0RTT))
+				Expect(more).To(BeEmpty())
+			})
+
 			It("doesn't add an ACK-only 0-RTT packet", func() { // ACK frames cannot be sent in 0-RTT packets
 				p, err := packer.PackCoalescedPacket(true, protocol.MaxByteCount, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
@@ -674,6 +708,394 @@ var _ = Describe("Packet packer", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			Context("FEC protection classes", func() {
+				expectAppendFECStreamFrames := func(class protocol.FECProtectionClass, hasClass bool, frames ...ackhandler.StreamFrame) {
+					framer.EXPECT().AppendFECStreamFrames(gomock.Any(), gomock.Any(), class, hasClass, gomock.Any()).DoAndReturn(func(fs []ackhandler.StreamFrame, _ protocol.ByteCount, _ protocol.FECProtectionClass, _ bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+						var length protocol.ByteCount
+						for _, f := range frames {
+							length += f.Frame.Length(v)
+						}
+						return append(fs, frames...), length
+					})
+				}
+
+				getSourceSymbolFrame := func(p shortHeaderPacket) *wire.SourceSymbolFrame {
+					for _, f := range p.Frames {
+						if ssf, ok := f.Frame.(*wire.SourceSymbolFrame); ok {
+							return ssf
+						}
+					}
+					return nil
+				}
+
+				BeforeEach(func() {
+					packer.repairQueue = newRepairQueue(func() {})
+					Expect(packer.SetFECScheme(protocol.XORFECScheme, protocol.FECWireFormatLegacy)).To(Succeed())
+				})
+
+				It("maintains separate block sequences for every protection class", func() {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).Times(2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42)).Times(2)
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2)
+					framer.EXPECT().HasData().Return(true).Times(2)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(2)
+					expectAppendControlFrames()
+					expectAppendControlFrames()
+
+					high := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: high})
+					p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					ssf := getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.Class).To(Equal(protocol.FECProtectionHigh))
+					Expect(ssf.SSID).To(BeZero())
+					// blocks of the high protection class consist of a single source symbol
+					repair := packer.repairQueue.Peek()
+					Expect(repair).ToNot(BeNil())
+					Expect(repair.Class).To(Equal(protocol.FECProtectionHigh))
+
+					def := &wire.StreamFrame{StreamID: 9, Data: []byte("foobaz"), FECProtected: true}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: def})
+					p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(repair))))
+					ssf = getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.Class).To(Equal(protocol.FECProtectionDefault))
+					Expect(ssf.SSID).To(BeZero())
+					Expect(packer.repairQueue.Peek()).To(BeNil())
+				})
+
+				It("only packs STREAM frames of the protection class of a FEC protected DATAGRAM frame", func() {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+					framer.EXPECT().HasData().Return(true)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+					f := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionLow}
+					done := make(chan struct{})
+					go func() {
+						defer GinkgoRecover()
+						defer close(done)
+						datagramQueue.Add(f)
+					}()
+					// make sure the DATAGRAM has actually been queued
+					time.Sleep(scaleDuration(20 * time.Millisecond))
+
+					expectAppendControlFrames()
+					sf := &wire.StreamFrame{StreamID: 5, Data: []byte("foobaz"), FECProtected: true, FECProtectionClass: protocol.FECProtectionLow}
+					expectAppendFECStreamFrames(protocol.FECProtectionLow, true, ackhandler.StreamFrame{Frame: sf})
+					p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					ssf := getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.Class).To(Equal(protocol.FECProtectionLow))
+					Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(f))))
+					Expect(p.StreamFrames).To(ContainElement(HaveField("Frame", Equal(sf))))
+					Eventually(done).Should(BeClosed())
+				})
+
+				It("fills source symbols, such that the REPAIR frames fit into a packet", func() {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen1)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2)
+					framer.EXPECT().HasData().Return(true).Times(2)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(2)
+					expectAppendControlFrames()
+					expectAppendControlFrames()
+					sf := &wire.StreamFrame{StreamID: 5, FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+					framer.EXPECT().AppendFECStreamFrames(gomock.Any(), gomock.Any(), protocol.FECProtectionDefault, false, protocol.Version1).DoAndReturn(func(fs []ackhandler.StreamFrame, maxLen protocol.ByteCount, _ protocol.FECProtectionClass, _ bool, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+						sf.Data = make([]byte, sf.MaxDataLen(maxLen, v))
+						return append(fs, ackhandler.StreamFrame{Frame: sf}), sf.Length(v)
+					})
+					p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					ssf := getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.Class).To(Equal(protocol.FECProtectionHigh))
+					// blocks of the high protection class consist of a single source symbol
+					repair := packer.repairQueue.Peek()
+					Expect(repair).ToNot(BeNil())
+					// The REPAIR frame needs to fit into a packet with the longest packet number.
+					maxRepairFrameSize := maxPacketSize - wire.ShortHeaderLen(packer.getDestConnID(), protocol.PacketNumberLen4) - protocol.ByteCount(getSealer().Overhead())
+					Expect(repair.Length(protocol.Version1)).To(BeNumerically("<=", maxRepairFrameSize))
+					Expect(repair.Length(protocol.Version1)).To(BeNumerically(">=", maxRepairFrameSize-1))
+
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen4)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+					framer.EXPECT().AppendFECStreamFrames(gomock.Any(), gomock.Any(), protocol.FECProtectionDefault, false, protocol.Version1).Return(nil, protocol.ByteCount(0))
+					p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(repair))))
+				})
+
+				It("discards the FEC state when the FEC scheme changes", func() {
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+					framer.EXPECT().HasData().Return(true)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+					expectAppendControlFrames()
+					f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: f})
+					_, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(packer.repairQueue.Peek()).ToNot(BeNil())
+					// setting the same scheme again keeps the state
+					Expect(packer.SetFECScheme(protocol.XORFECScheme, protocol.FECWireFormatLegacy)).To(Succeed())
+					Expect(packer.repairQueue.Peek()).ToNot(BeNil())
+					Expect(packer.hybridARQs[protocol.FECProtectionHigh]).ToNot(BeNil())
+					Expect(packer.SetFECScheme(protocol.FECDisabled, protocol.FECWireFormatLegacy)).To(Succeed())
+					Expect(packer.repairQueue.Peek()).To(BeNil())
+					Expect(packer.hybridARQs[protocol.FECProtectionHigh]).To(BeNil())
+				})
+
+				It("doesn't FEC protect frames beyond the peer's decoding window", func() {
+					packer.SetFECPeerWindow(1)
+					arq, err := packer.hybridARQ(protocol.FECProtectionHigh)
+					Expect(err).ToNot(HaveOccurred())
+					// Blocks of the high protection class consist of a single source symbol,
+					// so the peer accepts the source symbols of the first two blocks.
+					Expect(arq.CanSendSourceSymbol()).To(BeTrue())
+					arq.sender.NextSSID()
+					arq.sender.NextSSID()
+					Expect(arq.CanSendSourceSymbol()).To(BeFalse())
+
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+					framer.EXPECT().HasData().Return(true)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+					expectAppendControlFrames()
+					f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: f})
+					p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(getSourceSymbolFrame(p)).To(BeNil())
+					Expect(p.StreamFrames).To(HaveLen(1))
+					Expect(p.StreamFrames[0].Frame).To(Equal(f))
+					Expect(f.FECProtected).To(BeFalse())
+					Expect(packer.repairQueue.Peek()).To(BeNil())
+				})
+
+				It("uses the draft wire format", func() {
+					Expect(packer.SetFECScheme(protocol.XORFECScheme, protocol.FECWireFormatDraft)).To(Succeed())
+					pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).Times(3)
+					pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42)).Times(3)
+					sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(3)
+					framer.EXPECT().HasData().Return(true).Times(3)
+					ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(3)
+					expectAppendControlFrames()
+					expectAppendControlFrames()
+					expectAppendControlFrames()
+
+					unprotected := &wire.StreamFrame{StreamID: 3, Data: []byte("foo")}
+					protected := &wire.StreamFrame{StreamID: 5, Data: []byte("bar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionHigh}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: unprotected}, ackhandler.StreamFrame{Frame: protected})
+					p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					ssf := getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.Format).To(Equal(protocol.FECWireFormatDraft))
+					// the draft wire format doesn't support protection classes
+					Expect(ssf.Class).To(Equal(protocol.FECProtectionDefault))
+					Expect(ssf.SSID).To(BeZero())
+					// the SOURCE_SYMBOL frame is written last, so the unprotected STREAM frame needs a Data Length field
+					Expect(unprotected.DataLenPresent).To(BeTrue())
+					Expect(protected.DataLenPresent).To(BeTrue())
+
+					def := &wire.StreamFrame{StreamID: 9, Data: []byte("foobaz"), FECProtected: true}
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false, ackhandler.StreamFrame{Frame: def})
+					p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					ssf = getSourceSymbolFrame(p)
+					Expect(ssf).ToNot(BeNil())
+					Expect(ssf.SSID).To(Equal(protocol.SourceSymbolID(1)))
+
+					// the block is complete, so the REPAIR frame is sent in the next packet
+					expectAppendFECStreamFrames(protocol.FECProtectionDefault, false)
+					p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(p.Frames).To(HaveLen(1))
+					repair, ok := p.Frames[0].Frame.(*wire.RepairFrame)
+					Expect(ok).To(BeTrue())
+					Expect(repair.Format).To(Equal(protocol.FECWireFormatDraft))
+					Expect(repair.Metadata.BlockID).To(BeZero())
+				})
+
+				Context("FEC protected control frames", func() {
+					getSourceSymbolPayload := func(p shortHeaderPacket) []wire.Frame {
+						ssf := getSourceSymbolFrame(p)
+						Expect(ssf).ToNot(BeNil())
+						var frames []wire.Frame
+						data := ssf.Payload
+						parser := wire.NewFrameParser(true)
+						for len(data) > 0 {
+							l, f, err := parser.ParseNext(data, protocol.Encryption1RTT, protocol.Version1)
+							Expect(err).ToNot(HaveOccurred())
+							frames = append(frames, f)
+							data = data[l:]
+						}
+						return frames
+					}
+
+					BeforeEach(func() {
+						packer.fecControlFrames = protocol.FECControlFramesFlowControl
+						packer.fecControlFrameClass = protocol.FECProtectionHigh
+					})
+
+					It("packs the selected control frames into the SOURCE_SYMBOL frame", func() {
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Return(true)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						maxData := &wire.MaxDataFrame{MaximumData: 0x1337}
+						newConnID := &wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: protocol.ParseConnectionID([]byte{1, 2, 3, 4})}
+						expectAppendControlFrames(ackhandler.Frame{Frame: maxData}, ackhandler.Frame{Frame: newConnID})
+						expectAppendFECStreamFrames(protocol.FECProtectionHigh, true)
+						p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolFrame(p).Class).To(Equal(protocol.FECProtectionHigh))
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxData}))
+						// both frames are acknowledged and lost with the packet
+						Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(maxData))))
+						Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(newConnID))))
+					})
+
+					It("retransmits protected control frames if the block can't be recovered", func() {
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Return(true)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						maxData := &wire.MaxDataFrame{MaximumData: 0x1337}
+						expectAppendControlFrames(ackhandler.Frame{Frame: maxData})
+						expectAppendFECStreamFrames(protocol.FECProtectionHigh, true)
+						p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxData}))
+
+						// The packet is lost. The peer can still recover the frame using the REPAIR frame.
+						for _, f := range p.Frames {
+							f.Handler.OnLost(f.Frame)
+						}
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+						// The first REPAIR frame is lost, so another one is sent.
+						repair := packer.repairQueue.Peek()
+						Expect(repair).ToNot(BeNil())
+						packer.repairQueue.Pop()
+						packer.hybridARQs[protocol.FECProtectionHigh].OnLost(repair)
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+						repair = packer.repairQueue.Peek()
+						Expect(repair).ToNot(BeNil())
+						packer.repairQueue.Pop()
+						// The second REPAIR frame is lost as well, so the MAX_DATA frame is retransmitted.
+						packer.hybridARQs[protocol.FECProtectionHigh].OnLost(repair)
+						Expect(retransmissionQueue.HasAppData()).To(BeTrue())
+
+						// The retransmission is FEC protected as well.
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData()
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxData}))
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+					})
+
+					It("retransmits protected control frames lost from an incomplete block when there's nothing else to send", func() {
+						packer.fecControlFrameClass = protocol.FECProtectionDefault
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Return(true)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						maxData := &wire.MaxDataFrame{MaximumData: 0x1337}
+						expectAppendControlFrames(ackhandler.Frame{Frame: maxData})
+						expectAppendFECStreamFrames(protocol.FECProtectionDefault, true)
+						p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxData}))
+
+						// The block is incomplete, so the loss waits for the REPAIR frame.
+						for _, f := range p.Frames {
+							f.Handler.OnLost(f.Frame)
+						}
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+						Expect(packer.repairQueue.Peek()).To(BeNil())
+
+						// There's no other data to complete the block, so the MAX_DATA frame is retransmitted.
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Times(2)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						p, err = packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxData}))
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+					})
+
+					It("doesn't retransmit protected control frames that the peer recovered", func() {
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Return(true)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						maxStreamData := &wire.MaxStreamDataFrame{StreamID: 4, MaximumStreamData: 0x1337}
+						expectAppendControlFrames(ackhandler.Frame{Frame: maxStreamData})
+						expectAppendFECStreamFrames(protocol.FECProtectionHigh, true)
+						p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolPayload(p)).To(Equal([]wire.Frame{maxStreamData}))
+
+						for _, f := range p.Frames {
+							f.Handler.OnLost(f.Frame)
+						}
+						repair := packer.repairQueue.Peek()
+						Expect(repair).ToNot(BeNil())
+						packer.repairQueue.Pop()
+						packer.hybridARQs[protocol.FECProtectionHigh].OnAcked(repair)
+						Expect(retransmissionQueue.HasAppData()).To(BeFalse())
+						Expect(packer.hybridARQs[protocol.FECProtectionHigh].blocks).To(BeEmpty())
+					})
+
+					It("doesn't protect control frames if the packet carries FEC protected data of a different class", func() {
+						pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+						pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+						sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+						framer.EXPECT().HasData().Return(true)
+						ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+						f := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar"), FECProtected: true, FECProtectionClass: protocol.FECProtectionLow}
+						done := make(chan struct{})
+						go func() {
+							defer GinkgoRecover()
+							defer close(done)
+							datagramQueue.Add(f)
+						}()
+						// make sure the DATAGRAM has actually been queued
+						time.Sleep(scaleDuration(20 * time.Millisecond))
+
+						maxData := &wire.MaxDataFrame{MaximumData: 0x1337}
+						expectAppendControlFrames(ackhandler.Frame{Frame: maxData})
+						expectAppendFECStreamFrames(protocol.FECProtectionLow, true)
+						p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(getSourceSymbolFrame(p).Class).To(Equal(protocol.FECProtectionLow))
+						payload := getSourceSymbolPayload(p)
+						Expect(payload).To(HaveLen(1))
+						Expect(payload[0]).To(BeAssignableToTypeOf(&wire.DatagramFrame{}))
+						Expect(payload[0].(*wire.DatagramFrame).Data).To(Equal([]byte("foobar")))
+						Expect(p.Frames).To(ContainElement(HaveField("Frame", Equal(maxData))))
+						Eventually(done).Should(BeClosed())
+					})
+				})
+			})
+
 			It("accounts for the space consumed by control frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -1533,6 +1955,117 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.IsPathMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
+				frames := []ackhandler.Frame{{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3}}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, frames, 1200, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(1200))
+				Expect(p.DestConnID).To(Equal(connID))
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.Frames).To(Equal(frames))
+				Expect(buffer.Data).To(HaveLen(1200))
+				Expect(buffer.Data[1:5]).To(Equal(connID.Bytes()))
+			})
+		})
+
+		Context("packing packets for multipath connections", func() {
+			var (
+				pathPNManager *mockackhandler.MockSentPacketHandler
+				pathAcks      *MockAckFrameSource
+				path          *packerPath
+			)
+			pathConnID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0xba, 0xbe})
+
+			BeforeEach(func() {
+				pathPNManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				pathAcks = NewMockAckFrameSource(mockCtrl)
+				path = &packerPath{
+					id:        2,
+					connID:    pathConnID,
+					pnManager: pathPNManager,
+					acks:      pathAcks,
+					sealer:    getSealer(),
+				}
+			})
+
+			// parsePathPacket parses the frames of a packet sealed by getSealer
+			parsePathPacket := func(data []byte) []wire.Frame {
+				l, _, _, _, err := wire.ParseShortHeader(data, pathConnID.Len())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data[1 : 1+pathConnID.Len()]).To(Equal(pathConnID.Bytes()))
+				data = data[l : len(data)-7]
+				parser := wire.NewFrameParser(false)
+				parser.SetSupportsMultipath(true)
+				var frames []wire.Frame
+				for len(data) > 0 {
+					n, f, err := parser.ParseNext(data, protocol.Encryption1RTT, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+					data = data[n:]
+					if f == nil {
+						break
+					}
+					frames = append(frames, f)
+				}
+				return frames
+			}
+
+			It("packs an ACK-only packet using a PATH_ACK frame", func() {
+				pathPNManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pathPNManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 42, Smallest: 1}}}
+				pathAcks.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				framer.EXPECT().HasData().AnyTimes()
+				p, buffer, err := packer.PackPathAckOnlyPacket(path, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Ack).To(Equal(ack))
+				Expect(p.DestConnID).To(Equal(pathConnID))
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x42)))
+				Expect(parsePathPacket(buffer.Data)).To(Equal([]wire.Frame{&wire.PathAckFrame{PathID: 2, AckFrame: *ack}}))
+			})
+
+			It("packs stream data using the packet number space of the path", func() {
+				pathPNManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x10), protocol.PacketNumberLen2)
+				pathPNManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x10))
+				pathAcks.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				framer.EXPECT().HasData().Return(true)
+				expectAppendControlFrames()
+				f := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				expectAppendStreamFrames(ackhandler.StreamFrame{Frame: f})
+				buffer := getPacketBuffer()
+				p, err := packer.AppendPathPacket(buffer, path, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x10)))
+				Expect(p.StreamFrames).To(HaveLen(1))
+				Expect(parsePathPacket(buffer.Data)).To(Equal([]wire.Frame{f}))
+			})
+
+			It("uses the 1-RTT sealer if the path doesn't have its own sealer", func() {
+				path.sealer = nil
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pathPNManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pathPNManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				pathAcks.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 1, Smallest: 1}}})
+				framer.EXPECT().HasData().AnyTimes()
+				_, _, err := packer.PackPathAckOnlyPacket(path, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("packs a probe packet for a path", func() {
+				pathPNManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pathPNManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				frames := []ackhandler.Frame{{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3}}}}
+				p, buffer, err := packer.PackMultipathProbePacket(path, frames, 1200, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(1200))
+				Expect(p.DestConnID).To(Equal(pathConnID))
+				Expect(buffer.Data).To(HaveLen(1200))
+				Expect(parsePathPacket(buffer.Data)[0]).To(Equal(frames[0].Frame))
+			})
 		})
 	})
 })
@@ -112,6 +112,12 @@ func (u *packetUnpacker) UnpackShortHeader(rcvTime time.Time, data []byte) (prot
 	if err != nil {
 		return 0, 0, 0, nil, err
 	}
+	return u.UnpackPathShortHeader(opener, rcvTime, data)
+}
+
+// UnpackPathShortHeader unpacks a short header packet received on a path of a multipath connection.
+// Every path uses its own opener, since the path ID is part of the nonce.
+func (u *packetUnpacker) UnpackPathShortHeader(opener handshake.ShortHeaderOpener, rcvTime time.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
 	pn, pnLen, kp, decrypted, err := u.unpackShortHeaderPacket(opener, rcvTime, data)
 	if err != nil {
 		return 0, 0, 0, nil, err
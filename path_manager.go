@@ -0,0 +1,220 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+type pathID int64
+
+// the ID of the path the connection was established on
+const initialPathID pathID = 0
+
+// maxPaths is the maximum number of paths the server tracks in addition to the active path.
+// If the client uses more paths, the least recently used path is abandoned.
+const maxPaths = 3
+
+// amplificationFactor limits the number of bytes sent on a path before it is validated,
+// see section 8 of RFC 9000.
+const amplificationFactor = 3
+
+type serverPath struct {
+	id   pathID
+	addr net.Addr
+	info packetInfo
+
+	pathChallenge     [8]byte
+	pathChallengeSent time.Time
+	validated         bool
+	// set if a non-probing packet was received on this path before it was validated
+	receivedNonProbing bool
+
+	rcvdBytes, sentBytes protocol.ByteCount
+}
+
+// The pathManager is used by the server to handle packets received from a remote address other than the remote address
+// of the active path. Once a path is validated and the client sends a non-probing packet on it,
+// the connection switches to the new path.
+// It is only used from the connection's run loop.
+type pathManager struct {
+	nextPathID pathID
+	// ordered from least to most recently used
+	paths []*serverPath
+
+	getConnID    func(pathID) (protocol.ConnectionID, bool)
+	retireConnID func(pathID)
+	logger       utils.Logger
+}
+
+func newPathManager(
+	getConnID func(pathID) (protocol.ConnectionID, bool),
+	retireConnID func(pathID),
+	logger utils.Logger,
+) *pathManager {
+	return &pathManager{
+		nextPathID:   initialPathID + 1,
+		getConnID:    getConnID,
+		retireConnID: retireConnID,
+		logger:       logger,
+	}
+}
+
+// HandlePacket is called for a 1-RTT packet received from a remote address other than the remote address of the active path.
+// It returns the frames that need to be sent on this path, and the connection ID to use for sending them.
+// If shouldSwitch is true, the connection should switch to this path.
+func (pm *pathManager) HandlePacket(
+	addr net.Addr,
+	info packetInfo,
+	now time.Time,
+	pto time.Duration,
+	pathChallenge *wire.PathChallengeFrame,
+	isNonProbing bool,
+	size protocol.ByteCount,
+) (_ pathID, connID protocol.ConnectionID, frames []ackhandler.Frame, shouldSwitch bool) {
+	p := pm.getPath(addr)
+	if p == nil {
+		if len(pm.paths) >= maxPaths {
+			pm.abandon(pm.paths[0])
+		}
+		p = &serverPath{id: pm.nextPathID, addr: addr}
+		pm.nextPathID++
+		pm.logger.Debugf("enabling new path %d for %s", p.id, addr)
+	} else {
+		pm.remove(p)
+	}
+	// move the path to the end of the slice, since it's the most recently used path
+	pm.paths = append(pm.paths, p)
+	p.info = info
+	p.rcvdBytes += size
+
+	connID, ok := pm.getConnID(p.id)
+	if !ok {
+		// If the client didn't provide an unused connection ID, it's not possible to send on this path.
+		return p.id, connID, nil, false
+	}
+	if pathChallenge != nil {
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}})
+	}
+	if !p.validated && (p.pathChallengeSent.IsZero() || now.Sub(p.pathChallengeSent) >= pto) {
+		_, _ = rand.Read(p.pathChallenge[:])
+		p.pathChallengeSent = now
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: p.pathChallenge}})
+	}
+	if isNonProbing {
+		if p.validated {
+			return p.id, connID, frames, true
+		}
+		p.receivedNonProbing = true
+	}
+	return p.id, connID, frames, false
+}
+
+// AmplificationLimit returns the number of bytes that can be sent on a path.
+func (pm *pathManager) AmplificationLimit(id pathID) protocol.ByteCount {
+	for _, p := range pm.paths {
+		if p.id == id {
+			if p.validated {
+				return protocol.MaxByteCount
+			}
+			return max(0, amplificationFactor*p.rcvdBytes-p.sentBytes)
+		}
+	}
+	return 0
+}
+
+// SentPacket is called when a packet was sent on a path.
+func (pm *pathManager) SentPacket(id pathID, size protocol.ByteCount) {
+	for _, p := range pm.paths {
+		if p.id == id {
+			p.sentBytes += size
+			return
+		}
+	}
+}
+
+// HandlePathResponseFrame is called for every PATH_RESPONSE frame received, no matter which path it was received on.
+// If the path validated by the PATH_RESPONSE already received a non-probing packet, the connection should switch to it.
+func (pm *pathManager) HandlePathResponseFrame(f *wire.PathResponseFrame) (_ pathID, addr net.Addr, info packetInfo, shouldSwitch bool) {
+	for _, p := range pm.paths {
+		if p.validated || p.pathChallengeSent.IsZero() || f.Data != p.pathChallenge {
+			continue
+		}
+		pm.logger.Debugf("path %d for %s validated", p.id, p.addr)
+		p.validated = true
+		return p.id, p.addr, p.info, p.receivedNonProbing
+	}
+	return 0, nil, packetInfo{}, false
+}
+
+// SwitchedPath is called when the connection switched to a path.
+// The path is now the active path, and isn't tracked by the path manager anymore.
+// The previously active path is abandoned.
+func (pm *pathManager) SwitchedPath(id pathID) {
+	for _, p := range pm.paths {
+		if p.id == id {
+			pm.remove(p)
+			return
+		}
+	}
+}
+
+func (pm *pathManager) getPath(addr net.Addr) *serverPath {
+	for _, p := range pm.paths {
+		if addrsEqual(p.addr, addr) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (pm *pathManager) abandon(p *serverPath) {
+	pm.logger.Debugf("abandoning path %d for %s", p.id, p.addr)
+	pm.remove(p)
+	pm.retireConnID(p.id)
+}
+
+func (pm *pathManager) remove(p *serverPath) {
+	for i, path := range pm.paths {
+		if path == p {
+			pm.paths = append(pm.paths[:i], pm.paths[i+1:]...)
+			return
+		}
+	}
+}
+
+// isProbingFrame says if a frame is a probing frame, see section 9.1 of RFC 9000.
+// A packet containing only probing frames is a probing packet.
+func isProbingFrame(f wire.Frame) bool {
+	switch f.(type) {
+	case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
+}
+
+func addrsEqual(a, b net.Addr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ua, ok1 := a.(*net.UDPAddr)
+	ub, ok2 := b.(*net.UDPAddr)
+	if ok1 && ok2 {
+		return ua.IP.Equal(ub.IP) && ua.Port == ub.Port
+	}
+	return a.String() == b.String()
+}
+
+// onlyPortChanged says if two addresses only differ in their port number.
+// This is usually the result of a NAT rebinding, and doesn't require resetting the congestion controller.
+func onlyPortChanged(a, b net.Addr) bool {
+	ua, ok1 := a.(*net.UDPAddr)
+	ub, ok2 := b.(*net.UDPAddr)
+	return ok1 && ok2 && ua.IP.Equal(ub.IP)
+}
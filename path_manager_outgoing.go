@@ -0,0 +1,351 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+var (
+	// ErrPathClosed is returned when trying to use a path that was closed.
+	ErrPathClosed = errors.New("path closed")
+	// ErrPathNotValidated is returned when trying to switch to a path that was not validated.
+	ErrPathNotValidated = errors.New("path not yet validated")
+)
+
+// maxProbeBackoff limits the exponential backoff of the PATH_CHALLENGE retransmissions.
+const maxProbeBackoff = 5
+
+// maxPathChallenges is the number of outstanding PATH_CHALLENGEs accepted per path.
+const maxPathChallenges = 4
+
+// maxPreferredAddressProbes is the number of probes sent to the server's preferred address,
+// before giving up on migrating to it.
+const maxPreferredAddressProbes = 5
+
+// Path is a network path of a client connection, other than the path the connection was established on.
+// A path is probed using Probe, and once it is validated, the connection can switch to it using Switch.
+type Path struct {
+	id          pathID
+	pathManager *pathManagerOutgoing
+	validated   <-chan struct{}
+	closed      <-chan struct{}
+	connCtx     context.Context
+	// multipath is set if the connection uses multiple paths at the same time
+	multipath bool
+}
+
+// Probe validates the path, by sending PATH_CHALLENGE frames on it.
+// It blocks until the path is validated, the context is canceled, the path is closed or the connection is closed.
+func (p *Path) Probe(ctx context.Context) error {
+	select {
+	case <-p.validated:
+		return nil
+	default:
+	}
+	p.pathManager.startProbing(p.id)
+	defer p.pathManager.stopProbing(p.id)
+	select {
+	case <-p.validated:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-p.closed:
+		return ErrPathClosed
+	case <-p.connCtx.Done():
+		return context.Cause(p.connCtx)
+	}
+}
+
+// Switch switches the connection to this path.
+// The path needs to be validated first, using Probe.
+// Switching happens asynchronously: packets sent shortly after Switch returns might still be sent on the previous path.
+// Switching is not possible on a multipath connection, since all validated paths are used.
+func (p *Path) Switch() error {
+	if p.multipath {
+		return errors.New("can't switch paths on a multipath connection")
+	}
+	return p.pathManager.switchToPath(p.id)
+}
+
+// Close abandons the path.
+// The path that the connection is currently using can't be closed.
+func (p *Path) Close() error {
+	return p.pathManager.closePath(p.id)
+}
+
+type pathOutgoing struct {
+	id pathID
+	// the sendConn for this path, using the Transport the path was created with
+	conn   sendConn
+	runner connRunner
+
+	pathChallenges [][8]byte
+	isValidated    bool
+	validated      chan struct{}
+	closed         chan struct{}
+
+	// probing is set while PATH_CHALLENGE frames are sent on this path
+	probing       bool
+	probeCount    int
+	nextProbeTime time.Time
+	// maxProbes limits the number of probes sent (0 means no limit)
+	maxProbes int
+	// switchOnValidation is set if the connection switches to the path as soon as it is validated
+	switchOnValidation bool
+}
+
+// The pathManagerOutgoing is used by the client to probe and switch to new paths.
+// Its methods are called from the application (via Path), and from the connection's run loop.
+type pathManagerOutgoing struct {
+	mx sync.Mutex
+
+	nextPathID pathID
+	paths      map[pathID]*pathOutgoing
+	activePath pathID
+
+	pathToSwitchTo *pathOutgoing
+	// connection IDs of these paths need to be retired
+	pathsToRetire []pathID
+
+	runners *connRunners
+	// the runner of the transport the connection was dialed on
+	initialRunner   connRunner
+	scheduleSending func()
+}
+
+func newPathManagerOutgoing(runners *connRunners, initialRunner connRunner, scheduleSending func()) *pathManagerOutgoing {
+	return &pathManagerOutgoing{
+		nextPathID:      initialPathID + 1,
+		activePath:      initialPathID,
+		paths:           make(map[pathID]*pathOutgoing),
+		runners:         runners,
+		initialRunner:   initialRunner,
+		scheduleSending: scheduleSending,
+	}
+}
+
+// NewPath creates a new path.
+// The connection IDs of the connection are registered with the runner, such that packets received on the path
+// are passed to the connection.
+// If runner is nil, the path uses the transport the connection was dialed on.
+func (pm *pathManagerOutgoing) NewPath(conn sendConn, runner connRunner, maxProbes int, switchOnValidation bool) *pathOutgoing {
+	if runner == nil {
+		runner = pm.initialRunner
+	}
+	pm.runners.AddRunner(runner)
+
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	p := &pathOutgoing{
+		id:                 pm.nextPathID,
+		conn:               conn,
+		runner:             runner,
+		validated:          make(chan struct{}),
+		closed:             make(chan struct{}),
+		maxProbes:          maxProbes,
+		switchOnValidation: switchOnValidation,
+	}
+	pm.nextPathID++
+	pm.paths[p.id] = p
+	return p
+}
+
+func (pm *pathManagerOutgoing) startProbing(id pathID) {
+	pm.mx.Lock()
+	p, ok := pm.paths[id]
+	if ok && !p.isValidated && !p.probing {
+		p.probing = true
+		p.probeCount = 0
+		p.nextProbeTime = time.Time{}
+	}
+	pm.mx.Unlock()
+	if ok {
+		pm.scheduleSending()
+	}
+}
+
+func (pm *pathManagerOutgoing) stopProbing(id pathID) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	if p, ok := pm.paths[id]; ok {
+		p.probing = false
+	}
+}
+
+func (pm *pathManagerOutgoing) switchToPath(id pathID) error {
+	pm.mx.Lock()
+	p, ok := pm.paths[id]
+	if !ok {
+		pm.mx.Unlock()
+		return ErrPathClosed
+	}
+	if !p.isValidated {
+		pm.mx.Unlock()
+		return ErrPathNotValidated
+	}
+	pm.pathToSwitchTo = p
+	pm.mx.Unlock()
+	pm.scheduleSending()
+	return nil
+}
+
+func (pm *pathManagerOutgoing) closePath(id pathID) error {
+	pm.mx.Lock()
+	if id == pm.activePath {
+		pm.mx.Unlock()
+		return errors.New("can't close the active path")
+	}
+	p, ok := pm.paths[id]
+	if !ok {
+		pm.mx.Unlock()
+		return nil
+	}
+	pm.removePath(p)
+	pm.mx.Unlock()
+
+	pm.runners.RemoveRunner(p.runner)
+	pm.scheduleSending()
+	return nil
+}
+
+func (pm *pathManagerOutgoing) removePath(p *pathOutgoing) {
+	delete(pm.paths, p.id)
+	if pm.pathToSwitchTo == p {
+		pm.pathToSwitchTo = nil
+	}
+	close(p.closed)
+	pm.pathsToRetire = append(pm.pathsToRetire, p.id)
+}
+
+// NextPathToProbe returns the next path that a PATH_CHALLENGE needs to be sent on.
+// It generates the PATH_CHALLENGE frame, and schedules the next probe using an exponential backoff.
+// It is only called from the connection's run loop.
+// Paths that were not validated after sending maxProbes probes are removed.
+func (pm *pathManagerOutgoing) NextPathToProbe(now time.Time, pto time.Duration) (pathID, sendConn, *wire.PathChallengeFrame, bool) {
+	pm.mx.Lock()
+	var failed []*pathOutgoing
+	defer func() {
+		pm.mx.Unlock()
+		for _, p := range failed {
+			pm.runners.RemoveRunner(p.runner)
+		}
+	}()
+
+	for id, p := range pm.paths {
+		if !p.probing || p.isValidated || now.Before(p.nextProbeTime) {
+			continue
+		}
+		if p.maxProbes > 0 && p.probeCount >= p.maxProbes {
+			pm.removePath(p)
+			failed = append(failed, p)
+			continue
+		}
+		var data [8]byte
+		_, _ = rand.Read(data[:])
+		// Only the PATH_CHALLENGEs of the most recent probes are accepted.
+		if len(p.pathChallenges) >= maxPathChallenges {
+			p.pathChallenges = p.pathChallenges[1:]
+		}
+		p.pathChallenges = append(p.pathChallenges, data)
+		p.nextProbeTime = now.Add(pto << min(p.probeCount, maxProbeBackoff))
+		p.probeCount++
+		return id, p.conn, &wire.PathChallengeFrame{Data: data}, true
+	}
+	return 0, nil, nil, false
+}
+
+// BackoffProbe delays the next probe on a path, if the probe couldn't be sent.
+func (pm *pathManagerOutgoing) BackoffProbe(id pathID, now time.Time, pto time.Duration) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	if p, ok := pm.paths[id]; ok {
+		p.nextProbeTime = now.Add(pto)
+	}
+}
+
+// NextProbeTime returns the time when the next PATH_CHALLENGE needs to be sent.
+func (pm *pathManagerOutgoing) NextProbeTime() time.Time {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	var t time.Time
+	for _, p := range pm.paths {
+		if !p.probing || p.isValidated {
+			continue
+		}
+		if t.IsZero() || p.nextProbeTime.Before(t) {
+			t = p.nextProbeTime
+		}
+	}
+	return t
+}
+
+// HandlePathResponseFrame validates the path that the PATH_CHALLENGE was sent on.
+// It returns the ID of the validated path.
+// PATH_RESPONSE frames that don't match any PATH_CHALLENGE are ignored.
+func (pm *pathManagerOutgoing) HandlePathResponseFrame(f *wire.PathResponseFrame) (pathID, bool) {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	for _, p := range pm.paths {
+		if p.isValidated {
+			continue
+		}
+		for _, data := range p.pathChallenges {
+			if data != f.Data {
+				continue
+			}
+			p.isValidated = true
+			p.probing = false
+			close(p.validated)
+			if p.switchOnValidation {
+				pm.pathToSwitchTo = p
+				pm.scheduleSending()
+			}
+			return p.id, true
+		}
+	}
+	return 0, false
+}
+
+// ShouldSwitchPath returns the path that the connection needs to switch to.
+// Once the connection leaves the path it was established on, that path can't be used any more.
+func (pm *pathManagerOutgoing) ShouldSwitchPath() (pathID, sendConn, bool) {
+	pm.mx.Lock()
+	p := pm.pathToSwitchTo
+	if p == nil {
+		pm.mx.Unlock()
+		return 0, nil, false
+	}
+	pm.pathToSwitchTo = nil
+	if p.id == pm.activePath {
+		pm.mx.Unlock()
+		return 0, nil, false
+	}
+	leftInitialPath := pm.activePath == initialPathID
+	pm.activePath = p.id
+	pm.mx.Unlock()
+
+	if leftInitialPath {
+		pm.runners.RemoveRunner(pm.initialRunner)
+	}
+	return p.id, p.conn, true
+}
+
+// PathsToRetire returns the closed paths, whose connection IDs need to be retired.
+func (pm *pathManagerOutgoing) PathsToRetire() []pathID {
+	pm.mx.Lock()
+	defer pm.mx.Unlock()
+
+	paths := pm.pathsToRetire
+	pm.pathsToRetire = nil
+	return paths
+}
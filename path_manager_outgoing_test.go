@@ -0,0 +1,169 @@
+package quic
+
+import (
+	"context"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager (for outgoing paths)", func() {
+	var (
+		pm                *pathManagerOutgoing
+		initialRunner     *MockConnRunner
+		handler           *MockPacketHandler
+		scheduledSendings int
+		connCtx           context.Context
+		initialConnID     = protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	)
+
+	BeforeEach(func() {
+		scheduledSendings = 0
+		initialRunner = NewMockConnRunner(mockCtrl)
+		handler = NewMockPacketHandler(mockCtrl)
+		pm = newPathManagerOutgoing(
+			newConnRunners(initialRunner, initialConnID, handler),
+			initialRunner,
+			func() { scheduledSendings++ },
+		)
+		connCtx = context.Background()
+	})
+
+	newPath := func(maxProbes int, switchOnValidation bool) (*Path, *MockConnRunner, *MockSendConn) {
+		runner := NewMockConnRunner(mockCtrl)
+		runner.EXPECT().Add(initialConnID, handler).Return(true)
+		conn := NewMockSendConn(mockCtrl)
+		p := pm.NewPath(conn, runner, maxProbes, switchOnValidation)
+		return &Path{id: p.id, pathManager: pm, validated: p.validated, closed: p.closed, connCtx: connCtx}, runner, conn
+	}
+
+	It("probes a path", func() {
+		path, _, conn := newPath(0, false)
+		now := time.Now()
+		_, _, _, ok := pm.NextPathToProbe(now, time.Second)
+		Expect(ok).To(BeFalse())
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- path.Probe(context.Background()) }()
+		Eventually(func() bool {
+			pm.mx.Lock()
+			defer pm.mx.Unlock()
+			return pm.paths[path.id].probing
+		}).Should(BeTrue())
+		id, c, pc, ok := pm.NextPathToProbe(now, time.Second)
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(path.id))
+		Expect(c).To(Equal(conn))
+		Expect(pm.NextProbeTime()).To(Equal(now.Add(time.Second)))
+		// the next probe is sent after the PTO
+		_, _, _, ok = pm.NextPathToProbe(now.Add(time.Second/2), time.Second)
+		Expect(ok).To(BeFalse())
+		_, _, pc2, ok := pm.NextPathToProbe(now.Add(time.Second), time.Second)
+		Expect(ok).To(BeTrue())
+		Expect(pc2.Data).ToNot(Equal(pc.Data))
+		// exponential backoff
+		Expect(pm.NextProbeTime()).To(Equal(now.Add(3 * time.Second)))
+		Consistently(errChan).ShouldNot(Receive())
+
+		// a response to the first PATH_CHALLENGE is accepted
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: pc.Data})
+		Eventually(errChan).Should(Receive(BeNil()))
+		Expect(pm.NextProbeTime()).To(BeZero())
+		// probing a validated path returns immediately
+		Expect(path.Probe(context.Background())).To(Succeed())
+	})
+
+	It("switches to a validated path", func() {
+		path, _, conn := newPath(0, false)
+		Expect(path.Switch()).To(MatchError(ErrPathNotValidated))
+		pm.startProbing(path.id)
+		_, _, pc, ok := pm.NextPathToProbe(time.Now(), time.Second)
+		Expect(ok).To(BeTrue())
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: pc.Data})
+		_, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+
+		scheduledSendings = 0
+		Expect(path.Switch()).To(Succeed())
+		Expect(scheduledSendings).To(Equal(1))
+		// the initial runner isn't needed anymore once we switched away from the initial path
+		initialRunner.EXPECT().Remove(initialConnID)
+		id, c, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(path.id))
+		Expect(c).To(Equal(conn))
+		_, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+		// the active path can't be closed
+		Expect(path.Close()).ToNot(Succeed())
+	})
+
+	It("switches to a path as soon as it is validated", func() {
+		path, _, _ := newPath(0, true)
+		pm.startProbing(path.id)
+		_, _, pc, ok := pm.NextPathToProbe(time.Now(), time.Second)
+		Expect(ok).To(BeTrue())
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: pc.Data})
+		initialRunner.EXPECT().Remove(initialConnID)
+		id, _, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(path.id))
+	})
+
+	It("closes a path", func() {
+		path, runner, _ := newPath(0, false)
+		errChan := make(chan error, 1)
+		go func() { errChan <- path.Probe(context.Background()) }()
+		Consistently(errChan).ShouldNot(Receive())
+		runner.EXPECT().Remove(initialConnID)
+		Expect(path.Close()).To(Succeed())
+		Eventually(errChan).Should(Receive(MatchError(ErrPathClosed)))
+		Expect(pm.PathsToRetire()).To(Equal([]pathID{path.id}))
+		Expect(pm.PathsToRetire()).To(BeEmpty())
+		Expect(path.Switch()).To(MatchError(ErrPathClosed))
+		// closing a path a second time is a no-op
+		Expect(path.Close()).To(Succeed())
+	})
+
+	It("stops probing when the context is canceled", func() {
+		path, _, _ := newPath(0, false)
+		ctx, cancel := context.WithCancel(context.Background())
+		errChan := make(chan error, 1)
+		go func() { errChan <- path.Probe(ctx) }()
+		Consistently(errChan).ShouldNot(Receive())
+		cancel()
+		Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+		Eventually(func() bool {
+			pm.mx.Lock()
+			defer pm.mx.Unlock()
+			return pm.paths[path.id].probing
+		}).Should(BeFalse())
+		_, _, _, ok := pm.NextPathToProbe(time.Now(), time.Second)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("abandons a path after sending the maximum number of probes", func() {
+		path, runner, _ := newPath(2, true)
+		pm.startProbing(path.id)
+		now := time.Now()
+		_, _, _, ok := pm.NextPathToProbe(now, time.Second)
+		Expect(ok).To(BeTrue())
+		now = pm.NextProbeTime()
+		_, _, _, ok = pm.NextPathToProbe(now, time.Second)
+		Expect(ok).To(BeTrue())
+		now = pm.NextProbeTime()
+		runner.EXPECT().Remove(initialConnID)
+		_, _, _, ok = pm.NextPathToProbe(now, time.Second)
+		Expect(ok).To(BeFalse())
+		Expect(pm.PathsToRetire()).To(Equal([]pathID{path.id}))
+	})
+
+	It("uses the initial runner for new paths if no runner is provided", func() {
+		p := pm.NewPath(NewMockSendConn(mockCtrl), nil, 0, false)
+		Expect(p.runner).To(Equal(initialRunner))
+	})
+})
@@ -0,0 +1,153 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager", func() {
+	var (
+		pm            *pathManager
+		connIDs       []protocol.ConnectionID
+		retiredConnID []pathID
+	)
+
+	connIDForPath := func(id pathID) protocol.ConnectionID {
+		return protocol.ParseConnectionID([]byte{byte(id), 1, 2, 3})
+	}
+
+	BeforeEach(func() {
+		connIDs = nil
+		retiredConnID = nil
+		pm = newPathManager(
+			func(id pathID) (protocol.ConnectionID, bool) {
+				connID := connIDForPath(id)
+				connIDs = append(connIDs, connID)
+				return connID, true
+			},
+			func(id pathID) { retiredConnID = append(retiredConnID, id) },
+			utils.DefaultLogger,
+		)
+	})
+
+	addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1000}
+	addr2 := &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1000}
+
+	getPathChallenge := func(frames []ackhandler.Frame) *wire.PathChallengeFrame {
+		for _, f := range frames {
+			if pc, ok := f.Frame.(*wire.PathChallengeFrame); ok {
+				return pc
+			}
+		}
+		return nil
+	}
+
+	It("validates a new path and switches to it", func() {
+		now := time.Now()
+		id, connID, frames, shouldSwitch := pm.HandlePacket(addr1, packetInfo{}, now, time.Second, nil, true, 1000)
+		Expect(shouldSwitch).To(BeFalse())
+		Expect(connID).To(Equal(connIDForPath(id)))
+		Expect(frames).To(HaveLen(1))
+		pc := getPathChallenge(frames)
+		Expect(pc).ToNot(BeNil())
+
+		// a PATH_RESPONSE with the wrong data is ignored
+		_, _, _, shouldSwitch = pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: [8]byte{'f', 'o', 'o'}})
+		Expect(shouldSwitch).To(BeFalse())
+		// The client already sent a non-probing packet on this path, so we switch once the path is validated.
+		id2, addr, _, shouldSwitch := pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: pc.Data})
+		Expect(shouldSwitch).To(BeTrue())
+		Expect(id2).To(Equal(id))
+		Expect(addr).To(Equal(addr1))
+		pm.SwitchedPath(id)
+		Expect(pm.paths).To(BeEmpty())
+	})
+
+	It("responds to PATH_CHALLENGEs", func() {
+		_, _, frames, _ := pm.HandlePacket(addr1, packetInfo{}, time.Now(), time.Second, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3}}, false, 1000)
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3}}))
+		Expect(frames[1].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+	})
+
+	It("switches to a validated path when a non-probing packet is received", func() {
+		now := time.Now()
+		id, _, frames, shouldSwitch := pm.HandlePacket(addr1, packetInfo{}, now, time.Second, nil, false, 1000)
+		Expect(shouldSwitch).To(BeFalse())
+		_, _, _, shouldSwitch = pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: getPathChallenge(frames).Data})
+		Expect(shouldSwitch).To(BeFalse())
+		id2, _, frames, shouldSwitch := pm.HandlePacket(addr1, packetInfo{}, now, time.Second, nil, true, 1000)
+		Expect(id2).To(Equal(id))
+		Expect(frames).To(BeEmpty())
+		Expect(shouldSwitch).To(BeTrue())
+	})
+
+	It("retransmits PATH_CHALLENGEs after a PTO", func() {
+		now := time.Now()
+		_, _, frames, _ := pm.HandlePacket(addr1, packetInfo{}, now, time.Second, nil, false, 1000)
+		pc1 := getPathChallenge(frames)
+		Expect(pc1).ToNot(BeNil())
+		_, _, frames, _ = pm.HandlePacket(addr1, packetInfo{}, now.Add(time.Second/2), time.Second, nil, false, 1000)
+		Expect(frames).To(BeEmpty())
+		_, _, frames, _ = pm.HandlePacket(addr1, packetInfo{}, now.Add(time.Second), time.Second, nil, false, 1000)
+		pc2 := getPathChallenge(frames)
+		Expect(pc2).ToNot(BeNil())
+		Expect(pc2.Data).ToNot(Equal(pc1.Data))
+	})
+
+	It("enforces the amplification limit on unvalidated paths", func() {
+		id, _, frames, _ := pm.HandlePacket(addr1, packetInfo{}, time.Now(), time.Second, nil, false, 100)
+		Expect(pm.AmplificationLimit(id)).To(Equal(protocol.ByteCount(300)))
+		pm.SentPacket(id, 250)
+		Expect(pm.AmplificationLimit(id)).To(Equal(protocol.ByteCount(50)))
+		pm.SentPacket(id, 100)
+		Expect(pm.AmplificationLimit(id)).To(BeZero())
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: getPathChallenge(frames).Data})
+		Expect(pm.AmplificationLimit(id)).To(Equal(protocol.MaxByteCount))
+	})
+
+	It("doesn't send anything if no connection ID is available", func() {
+		pm.getConnID = func(pathID) (protocol.ConnectionID, bool) { return protocol.ConnectionID{}, false }
+		_, _, frames, shouldSwitch := pm.HandlePacket(addr1, packetInfo{}, time.Now(), time.Second, &wire.PathChallengeFrame{}, true, 1000)
+		Expect(frames).To(BeEmpty())
+		Expect(shouldSwitch).To(BeFalse())
+	})
+
+	It("abandons the least recently used path", func() {
+		now := time.Now()
+		var ids []pathID
+		for i := 0; i < maxPaths; i++ {
+			id, _, _, _ := pm.HandlePacket(&net.UDPAddr{IP: net.IPv4(10, 0, 0, byte(i)), Port: 1000}, packetInfo{}, now, time.Second, nil, false, 1000)
+			ids = append(ids, id)
+		}
+		// use the first path again
+		pm.HandlePacket(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 0), Port: 1000}, packetInfo{}, now, time.Second, nil, false, 1000)
+		Expect(retiredConnID).To(BeEmpty())
+		pm.HandlePacket(addr2, packetInfo{}, now, time.Second, nil, false, 1000)
+		Expect(retiredConnID).To(Equal([]pathID{ids[1]}))
+		Expect(pm.paths).To(HaveLen(maxPaths))
+	})
+
+	It("tells probing from non-probing frames", func() {
+		Expect(isProbingFrame(&wire.PathChallengeFrame{})).To(BeTrue())
+		Expect(isProbingFrame(&wire.PathResponseFrame{})).To(BeTrue())
+		Expect(isProbingFrame(&wire.NewConnectionIDFrame{})).To(BeTrue())
+		Expect(isProbingFrame(&wire.PingFrame{})).To(BeFalse())
+		Expect(isProbingFrame(&wire.StreamFrame{})).To(BeFalse())
+	})
+
+	It("detects NAT rebindings", func() {
+		Expect(onlyPortChanged(addr1, &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 2000})).To(BeTrue())
+		Expect(onlyPortChanged(addr1, addr2)).To(BeFalse())
+		Expect(addrsEqual(addr1, &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1000})).To(BeTrue())
+		Expect(addrsEqual(addr1, addr2)).To(BeFalse())
+	})
+})
@@ -109,6 +109,18 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		ChoseALPN: func(protocol string) {
 			t.recordEvent(time.Now(), eventALPNInformation{chosenALPN: protocol})
 		},
+		RecoveredSourceSymbols: func(blockID protocol.BlockID, length protocol.ByteCount) {
+			t.recordEvent(time.Now(), &eventSourceSymbolsRecovered{BlockID: blockID, Length: length})
+		},
+		RejectedFECFrame: func(f logging.Frame, reason logging.FECFrameRejectReason) {
+			t.recordEvent(time.Now(), &eventFECFrameRejected{Frame: frame{Frame: f}, Trigger: fecFrameRejectReason(reason)})
+		},
+		UpdatedLossEstimate: func(estimate logging.LossEstimate) {
+			t.recordEvent(time.Now(), &eventLossEstimateUpdated{Estimate: estimate})
+		},
+		DroppedDatagram: func(length logging.ByteCount, reason logging.DatagramDropReason) {
+			t.recordEvent(time.Now(), &eventDatagramFrameDropped{Length: length, Trigger: datagramDropReason(reason)})
+		},
 		Debug: func(name, msg string) {
 			t.Debug(name, msg)
 		},
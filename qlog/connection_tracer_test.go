@@ -723,6 +723,56 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("trigger", "reordering_threshold"))
 		})
 
+		It("records recovered source symbols", func() {
+			tracer.RecoveredSourceSymbols(7, 1337)
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("recovery:source_symbols_recovered"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("block_id", float64(7)))
+			Expect(ev).To(HaveKeyWithValue("length", float64(1337)))
+		})
+
+		It("records loss estimate updates", func() {
+			tracer.UpdatedLossEstimate(logging.LossEstimate{P: 0.25, R: 0.5, H: 0.125, K: 1, NumPackets: 1000, NumLost: 20, BurstLengths: []uint64{10, 5}})
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("recovery:loss_estimate_updated"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("p", 0.25))
+			Expect(ev).To(HaveKeyWithValue("r", 0.5))
+			Expect(ev).To(HaveKeyWithValue("h", 0.125))
+			Expect(ev).To(HaveKeyWithValue("k", float64(1)))
+			Expect(ev).To(HaveKeyWithValue("packets", float64(1000)))
+			Expect(ev).To(HaveKeyWithValue("lost", float64(20)))
+			Expect(ev).To(HaveKeyWithValue("burst_lengths", []interface{}{float64(10), float64(5)}))
+		})
+
+		It("records rejected FEC frames", func() {
+			tracer.RejectedFECFrame(&logging.SourceSymbolFrame{SID: 42, Length: 1337}, logging.FECFrameRejectOutsideWindow)
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("transport:fec_frame_rejected"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("trigger", "outside_window"))
+			Expect(ev).To(HaveKey("frame"))
+			Expect(ev["frame"].(map[string]interface{})).To(HaveKeyWithValue("frame_type", "source_symbol"))
+		})
+
+		It("records dropped datagrams", func() {
+			tracer.DroppedDatagram(1337, logging.DatagramDropExpired)
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("transport:datagram_frame_dropped"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("length", float64(1337)))
+			Expect(ev).To(HaveKeyWithValue("trigger", "expired"))
+		})
+
 		It("records congestion state updates", func() {
 			tracer.UpdatedCongestionState(logging.CongestionStateCongestionAvoidance)
 			tracer.Close()
@@ -358,6 +358,75 @@ func (e eventPacketLost) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("trigger", e.Trigger.String())
 }
 
+type eventSourceSymbolsRecovered struct {
+	BlockID protocol.BlockID
+	Length  protocol.ByteCount
+}
+
+func (e eventSourceSymbolsRecovered) Category() category { return categoryRecovery }
+func (e eventSourceSymbolsRecovered) Name() string       { return "source_symbols_recovered" }
+func (e eventSourceSymbolsRecovered) IsNil() bool        { return false }
+
+func (e eventSourceSymbolsRecovered) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Uint64Key("block_id", uint64(e.BlockID))
+	enc.Int64Key("length", int64(e.Length))
+}
+
+type burstLengths []uint64
+
+func (l burstLengths) IsNil() bool { return false }
+func (l burstLengths) MarshalJSONArray(enc *gojay.Encoder) {
+	for _, n := range l {
+		enc.AddUint64(n)
+	}
+}
+
+type eventLossEstimateUpdated struct {
+	Estimate logging.LossEstimate
+}
+
+func (e eventLossEstimateUpdated) Category() category { return categoryRecovery }
+func (e eventLossEstimateUpdated) Name() string       { return "loss_estimate_updated" }
+func (e eventLossEstimateUpdated) IsNil() bool        { return false }
+
+func (e eventLossEstimateUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Float64Key("p", e.Estimate.P)
+	enc.Float64Key("r", e.Estimate.R)
+	enc.Float64Key("h", e.Estimate.H)
+	enc.Float64Key("k", e.Estimate.K)
+	enc.Uint64Key("packets", e.Estimate.NumPackets)
+	enc.Uint64Key("lost", e.Estimate.NumLost)
+	enc.ArrayKey("burst_lengths", burstLengths(e.Estimate.BurstLengths))
+}
+
+type eventFECFrameRejected struct {
+	Frame   frame
+	Trigger fecFrameRejectReason
+}
+
+func (e eventFECFrameRejected) Category() category { return categoryTransport }
+func (e eventFECFrameRejected) Name() string       { return "fec_frame_rejected" }
+func (e eventFECFrameRejected) IsNil() bool        { return false }
+
+func (e eventFECFrameRejected) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.ObjectKey("frame", e.Frame)
+	enc.StringKey("trigger", e.Trigger.String())
+}
+
+type eventDatagramFrameDropped struct {
+	Length  logging.ByteCount
+	Trigger datagramDropReason
+}
+
+func (e eventDatagramFrameDropped) Category() category { return categoryTransport }
+func (e eventDatagramFrameDropped) Name() string       { return "datagram_frame_dropped" }
+func (e eventDatagramFrameDropped) IsNil() bool        { return false }
+
+func (e eventDatagramFrameDropped) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Int64Key("length", int64(e.Length))
+	enc.StringKey("trigger", e.Trigger.String())
+}
+
 type eventKeyUpdated struct {
 	Trigger  keyUpdateTrigger
 	KeyType  keyType
@@ -63,6 +63,28 @@ func (f frame) MarshalJSONObject(enc *gojay.Encoder) {
 		marshalRepairFrame(enc, frame)
 	case *logging.SourceSymbolFrame:
 		marshalSourceSymbolFrame(enc, frame)
+	case *logging.FECWindowFrame:
+		marshalFECWindowFrame(enc, frame)
+	case *logging.PathAckFrame:
+		marshalPathAckFrame(enc, frame)
+	case *logging.PathAbandonFrame:
+		marshalPathAbandonFrame(enc, frame)
+	case *logging.PathStatusFrame:
+		marshalPathStatusFrame(enc, frame)
+	case *logging.PathNewConnectionIDFrame:
+		marshalPathNewConnectionIDFrame(enc, frame)
+	case *logging.PathRetireConnectionIDFrame:
+		marshalPathRetireConnectionIDFrame(enc, frame)
+	case *logging.MaxPathIDFrame:
+		marshalMaxPathIDFrame(enc, frame)
+	case *logging.PathsBlockedFrame:
+		marshalPathsBlockedFrame(enc, frame)
+	case *logging.PathCIDsBlockedFrame:
+		marshalPathCIDsBlockedFrame(enc, frame)
+	case *logging.AckFrequencyFrame:
+		marshalAckFrequencyFrame(enc, frame)
+	case *logging.ImmediateAckFrame:
+		enc.StringKey("frame_type", "immediate_ack")
 	default:
 		panic("unknown frame type")
 	}
@@ -106,6 +128,12 @@ func (ar ackRange) IsNil() bool { return false }
 
 func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.StringKey("frame_type", "ack")
+	marshalAckFrameFields(enc, f)
+}
+
+// marshalAckFrameFields marshals all fields of an ACK frame but the frame type.
+// It is shared with the PATH_ACK frame.
+func marshalAckFrameFields(enc *gojay.Encoder, f *logging.AckFrame) {
 	enc.FloatKeyOmitEmpty("ack_delay", milliseconds(f.DelayTime))
 	enc.ArrayKey("acked_ranges", ackRanges(f.AckRanges))
 	if hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0; hasECN {
@@ -116,10 +144,17 @@ func marshalAckFrame(enc *gojay.Encoder, f *logging.AckFrame) {
 }
 
 func marshalResetStreamFrame(enc *gojay.Encoder, f *logging.ResetStreamFrame) {
-	enc.StringKey("frame_type", "reset_stream")
+	if f.ReliableSize > 0 {
+		enc.StringKey("frame_type", "reset_stream_at")
+	} else {
+		enc.StringKey("frame_type", "reset_stream")
+	}
 	enc.Int64Key("stream_id", int64(f.StreamID))
 	enc.Int64Key("error_code", int64(f.ErrorCode))
 	enc.Int64Key("final_size", int64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		enc.Int64Key("reliable_size", int64(f.ReliableSize))
+	}
 }
 
 func marshalStopSendingFrame(enc *gojay.Encoder, f *logging.StopSendingFrame) {
@@ -183,6 +218,12 @@ func marshalStreamsBlockedFrame(enc *gojay.Encoder, f *logging.StreamsBlockedFra
 
 func marshalNewConnectionIDFrame(enc *gojay.Encoder, f *logging.NewConnectionIDFrame) {
 	enc.StringKey("frame_type", "new_connection_id")
+	marshalNewConnectionIDFrameFields(enc, f)
+}
+
+// marshalNewConnectionIDFrameFields marshals all fields of a NEW_CONNECTION_ID frame but the frame type.
+// It is shared with the PATH_NEW_CONNECTION_ID frame.
+func marshalNewConnectionIDFrameFields(enc *gojay.Encoder, f *logging.NewConnectionIDFrame) {
 	enc.Int64Key("sequence_number", int64(f.SequenceNumber))
 	enc.Int64Key("retire_prior_to", int64(f.RetirePriorTo))
 	enc.IntKey("length", f.ConnectionID.Len())
@@ -231,10 +272,87 @@ func marshalDatagramFrame(enc *gojay.Encoder, f *logging.DatagramFrame) {
 }
 
 func marshalRepairFrame(enc *gojay.Encoder, f *logging.RepairFrame) {
+	enc.StringKey("frame_type", "repair")
+	marshalFECProtectionClass(enc, f.Class)
 	enc.Int64Key("block_id", int64(f.BlockID))
 	enc.Int64Key("parity_id", int64(f.ParityID))
+	enc.Int64Key("length", int64(f.Length))
 }
 
 func marshalSourceSymbolFrame(enc *gojay.Encoder, f *logging.SourceSymbolFrame) {
+	enc.StringKey("frame_type", "source_symbol")
+	marshalFECProtectionClass(enc, f.Class)
 	enc.Int64Key("sid", int64(f.SID))
+	enc.Int64Key("length", int64(f.Length))
+}
+
+// marshalFECProtectionClass only logs the protection class of frames that don't use the default class.
+func marshalFECProtectionClass(enc *gojay.Encoder, class logging.FECProtectionClass) {
+	if class != 0 {
+		enc.StringKey("protection_class", class.String())
+	}
+}
+
+func marshalFECWindowFrame(enc *gojay.Encoder, f *logging.FECWindowFrame) {
+	enc.StringKey("frame_type", "fec_window")
+	enc.Int64Key("epoch", int64(f.Epoch))
+	enc.Int64Key("size", int64(f.Size))
+}
+
+func marshalPathAckFrame(enc *gojay.Encoder, f *logging.PathAckFrame) {
+	enc.StringKey("frame_type", "path_ack")
+	enc.Int64Key("path_id", int64(f.PathID))
+	marshalAckFrameFields(enc, &f.AckFrame)
+}
+
+func marshalPathAbandonFrame(enc *gojay.Encoder, f *logging.PathAbandonFrame) {
+	enc.StringKey("frame_type", "path_abandon")
+	enc.Int64Key("path_id", int64(f.PathID))
+	enc.Int64Key("error_code", int64(f.ErrorCode))
+}
+
+func marshalPathStatusFrame(enc *gojay.Encoder, f *logging.PathStatusFrame) {
+	if f.Backup {
+		enc.StringKey("frame_type", "path_status_backup")
+	} else {
+		enc.StringKey("frame_type", "path_status_available")
+	}
+	enc.Int64Key("path_id", int64(f.PathID))
+	enc.Int64Key("path_status_sequence_number", int64(f.StatusSequenceNumber))
+}
+
+func marshalPathNewConnectionIDFrame(enc *gojay.Encoder, f *logging.PathNewConnectionIDFrame) {
+	enc.StringKey("frame_type", "path_new_connection_id")
+	enc.Int64Key("path_id", int64(f.PathID))
+	marshalNewConnectionIDFrameFields(enc, &f.NewConnectionIDFrame)
+}
+
+func marshalPathRetireConnectionIDFrame(enc *gojay.Encoder, f *logging.PathRetireConnectionIDFrame) {
+	enc.StringKey("frame_type", "path_retire_connection_id")
+	enc.Int64Key("path_id", int64(f.PathID))
+	enc.Int64Key("sequence_number", int64(f.SequenceNumber))
+}
+
+func marshalMaxPathIDFrame(enc *gojay.Encoder, f *logging.MaxPathIDFrame) {
+	enc.StringKey("frame_type", "max_path_id")
+	enc.Int64Key("maximum_path_id", int64(f.MaxPathID))
+}
+
+func marshalPathsBlockedFrame(enc *gojay.Encoder, f *logging.PathsBlockedFrame) {
+	enc.StringKey("frame_type", "paths_blocked")
+	enc.Int64Key("maximum_path_id", int64(f.MaxPathID))
+}
+
+func marshalPathCIDsBlockedFrame(enc *gojay.Encoder, f *logging.PathCIDsBlockedFrame) {
+	enc.StringKey("frame_type", "path_cids_blocked")
+	enc.Int64Key("path_id", int64(f.PathID))
+	enc.Int64Key("next_sequence_number", int64(f.NextSequenceNumber))
+}
+
+func marshalAckFrequencyFrame(enc *gojay.Encoder, f *logging.AckFrequencyFrame) {
+	enc.StringKey("frame_type", "ack_frequency")
+	enc.Int64Key("sequence_number", int64(f.SequenceNumber))
+	enc.Int64Key("ack_eliciting_threshold", int64(f.AckElicitingThreshold))
+	enc.FloatKey("request_max_ack_delay", milliseconds(f.RequestMaxAckDelay))
+	enc.Int64Key("reordering_threshold", int64(f.ReorderingThreshold))
 }
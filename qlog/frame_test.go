@@ -113,6 +113,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals RESET_STREAM_AT frames", func() {
+		check(
+			&logging.ResetStreamFrame{
+				StreamID:     987,
+				FinalSize:    1234,
+				ErrorCode:    42,
+				ReliableSize: 100,
+			},
+			map[string]interface{}{
+				"frame_type":    "reset_stream_at",
+				"stream_id":     987,
+				"error_code":    42,
+				"final_size":    1234,
+				"reliable_size": 100,
+			},
+		)
+	})
+
 	It("marshals STOP_SENDING frames", func() {
 		check(
 			&logging.StopSendingFrame{
@@ -373,4 +391,196 @@ var _ = Describe("Frames", func() {
 			},
 		)
 	})
+
+	It("marshals REPAIR frames", func() {
+		check(
+			&logging.RepairFrame{BlockID: 42, ParityID: 3, Length: 1337},
+			map[string]interface{}{
+				"frame_type": "repair",
+				"block_id":   42,
+				"parity_id":  3,
+				"length":     1337,
+			},
+		)
+	})
+
+	It("marshals REPAIR frames of a protection class", func() {
+		check(
+			&logging.RepairFrame{Class: protocol.FECProtectionHigh, BlockID: 42, ParityID: 3, Length: 1337},
+			map[string]interface{}{
+				"frame_type":       "repair",
+				"protection_class": "high",
+				"block_id":         42,
+				"parity_id":        3,
+				"length":           1337,
+			},
+		)
+	})
+
+	It("marshals SOURCE_SYMBOL frames", func() {
+		check(
+			&logging.SourceSymbolFrame{SID: 42, Length: 1337},
+			map[string]interface{}{
+				"frame_type": "source_symbol",
+				"sid":        42,
+				"length":     1337,
+			},
+		)
+	})
+
+	It("marshals SOURCE_SYMBOL frames of a protection class", func() {
+		check(
+			&logging.SourceSymbolFrame{Class: protocol.FECProtectionLow, SID: 42, Length: 1337},
+			map[string]interface{}{
+				"frame_type":       "source_symbol",
+				"protection_class": "low",
+				"sid":              42,
+				"length":           1337,
+			},
+		)
+	})
+
+	It("marshals FEC_WINDOW frames", func() {
+		check(
+			&logging.FECWindowFrame{Epoch: 3, Size: 1337},
+			map[string]interface{}{
+				"frame_type": "fec_window",
+				"epoch":      3,
+				"size":       1337,
+			},
+		)
+	})
+
+	It("marshals PATH_ACK frames", func() {
+		check(
+			&logging.PathAckFrame{
+				PathID:   3,
+				AckFrame: logging.AckFrame{AckRanges: []logging.AckRange{{Smallest: 120, Largest: 120}}},
+			},
+			map[string]interface{}{
+				"frame_type":   "path_ack",
+				"path_id":      3,
+				"acked_ranges": [][]float64{{120}},
+			},
+		)
+	})
+
+	It("marshals PATH_ABANDON frames", func() {
+		check(
+			&logging.PathAbandonFrame{PathID: 2, ErrorCode: 0x1},
+			map[string]interface{}{
+				"frame_type": "path_abandon",
+				"path_id":    2,
+				"error_code": 1,
+			},
+		)
+	})
+
+	It("marshals PATH_STATUS frames", func() {
+		check(
+			&logging.PathStatusFrame{PathID: 1, StatusSequenceNumber: 4, Backup: true},
+			map[string]interface{}{
+				"frame_type":                  "path_status_backup",
+				"path_id":                     1,
+				"path_status_sequence_number": 4,
+			},
+		)
+		check(
+			&logging.PathStatusFrame{PathID: 1, StatusSequenceNumber: 5},
+			map[string]interface{}{
+				"frame_type":                  "path_status_available",
+				"path_id":                     1,
+				"path_status_sequence_number": 5,
+			},
+		)
+	})
+
+	It("marshals PATH_NEW_CONNECTION_ID frames", func() {
+		check(
+			&logging.PathNewConnectionIDFrame{
+				PathID: 7,
+				NewConnectionIDFrame: logging.NewConnectionIDFrame{
+					SequenceNumber:      42,
+					RetirePriorTo:       24,
+					ConnectionID:        protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+					StatelessResetToken: protocol.StatelessResetToken{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf},
+				},
+			},
+			map[string]interface{}{
+				"frame_type":            "path_new_connection_id",
+				"path_id":               7,
+				"sequence_number":       42,
+				"retire_prior_to":       24,
+				"length":                4,
+				"connection_id":         "deadbeef",
+				"stateless_reset_token": "000102030405060708090a0b0c0d0e0f",
+			},
+		)
+	})
+
+	It("marshals PATH_RETIRE_CONNECTION_ID frames", func() {
+		check(
+			&logging.PathRetireConnectionIDFrame{PathID: 7, SequenceNumber: 1337},
+			map[string]interface{}{
+				"frame_type":      "path_retire_connection_id",
+				"path_id":         7,
+				"sequence_number": 1337,
+			},
+		)
+	})
+
+	It("marshals MAX_PATH_ID and PATHS_BLOCKED frames", func() {
+		check(
+			&logging.MaxPathIDFrame{MaxPathID: 8},
+			map[string]interface{}{
+				"frame_type":      "max_path_id",
+				"maximum_path_id": 8,
+			},
+		)
+		check(
+			&logging.PathsBlockedFrame{MaxPathID: 8},
+			map[string]interface{}{
+				"frame_type":      "paths_blocked",
+				"maximum_path_id": 8,
+			},
+		)
+	})
+
+	It("marshals PATH_CIDS_BLOCKED frames", func() {
+		check(
+			&logging.PathCIDsBlockedFrame{PathID: 2, NextSequenceNumber: 10},
+			map[string]interface{}{
+				"frame_type":           "path_cids_blocked",
+				"path_id":              2,
+				"next_sequence_number": 10,
+			},
+		)
+	})
+
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        3,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    2 * time.Millisecond,
+				ReorderingThreshold:   2,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         3,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   2,
+				"reordering_threshold":    2,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
 })
@@ -209,6 +209,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.FECError:
+		return "fec_error"
 	default:
 		return ""
 	}
@@ -283,6 +285,38 @@ func (r packetDropReason) String() string {
 	}
 }
 
+type fecFrameRejectReason logging.FECFrameRejectReason
+
+func (r fecFrameRejectReason) String() string {
+	switch logging.FECFrameRejectReason(r) {
+	case logging.FECFrameRejectNotNegotiated:
+		return "not_negotiated"
+	case logging.FECFrameRejectInvalidSymbol:
+		return "invalid_symbol"
+	case logging.FECFrameRejectOutsideWindow:
+		return "outside_window"
+	default:
+		return "unknown FEC frame reject reason"
+	}
+}
+
+type datagramDropReason logging.DatagramDropReason
+
+func (r datagramDropReason) String() string {
+	switch logging.DatagramDropReason(r) {
+	case logging.DatagramDropExpired:
+		return "expired"
+	case logging.DatagramDropSendQueueFull:
+		return "send_queue_full"
+	case logging.DatagramDropTooLarge:
+		return "too_large"
+	case logging.DatagramDropReceiveQueueFull:
+		return "receive_queue_full"
+	default:
+		return "unknown datagram drop reason"
+	}
+}
+
 type timerType logging.TimerType
 
 func (t timerType) String() string {
@@ -308,6 +342,14 @@ func (s congestionState) String() string {
 		return "recovery"
 	case logging.CongestionStateApplicationLimited:
 		return "application_limited"
+	case logging.CongestionStateBBRStartup:
+		return "startup"
+	case logging.CongestionStateBBRDrain:
+		return "drain"
+	case logging.CongestionStateBBRProbeBW:
+		return "probe_bw"
+	case logging.CongestionStateBBRProbeRTT:
+		return "probe_rtt"
 	default:
 		return "unknown congestion state"
 	}
@@ -53,6 +53,19 @@ var _ = Describe("Types", func() {
 		Expect(packetDropReason(logging.PacketDropUnexpectedVersion).String()).To(Equal("unexpected_version"))
 	})
 
+	It("has a string representation for the FEC frame reject reason", func() {
+		Expect(fecFrameRejectReason(logging.FECFrameRejectNotNegotiated).String()).To(Equal("not_negotiated"))
+		Expect(fecFrameRejectReason(logging.FECFrameRejectInvalidSymbol).String()).To(Equal("invalid_symbol"))
+		Expect(fecFrameRejectReason(logging.FECFrameRejectOutsideWindow).String()).To(Equal("outside_window"))
+	})
+
+	It("has a string representation for the datagram drop reason", func() {
+		Expect(datagramDropReason(logging.DatagramDropExpired).String()).To(Equal("expired"))
+		Expect(datagramDropReason(logging.DatagramDropSendQueueFull).String()).To(Equal("send_queue_full"))
+		Expect(datagramDropReason(logging.DatagramDropTooLarge).String()).To(Equal("too_large"))
+		Expect(datagramDropReason(logging.DatagramDropReceiveQueueFull).String()).To(Equal("receive_queue_full"))
+	})
+
 	It("has a string representation for the timer type", func() {
 		Expect(timerType(logging.TimerTypeACK).String()).To(Equal("ack"))
 		Expect(timerType(logging.TimerTypePTO).String()).To(Equal("pto"))
@@ -117,6 +130,7 @@ var _ = Describe("Types", func() {
 			Expect(transportError(qerr.ApplicationErrorErrorCode).String()).To(Equal("application_error"))
 			Expect(transportError(qerr.CryptoBufferExceeded).String()).To(Equal("crypto_buffer_exceeded"))
 			Expect(transportError(qerr.NoViablePathError).String()).To(Equal("no_viable_path"))
+			Expect(transportError(qerr.FECError).String()).To(Equal("fec_error"))
 			Expect(transportError(1337).String()).To(BeEmpty())
 		})
 	})
@@ -126,6 +140,10 @@ var _ = Describe("Types", func() {
 		Expect(congestionState(logging.CongestionStateCongestionAvoidance).String()).To(Equal("congestion_avoidance"))
 		Expect(congestionState(logging.CongestionStateApplicationLimited).String()).To(Equal("application_limited"))
 		Expect(congestionState(logging.CongestionStateRecovery).String()).To(Equal("recovery"))
+		Expect(congestionState(logging.CongestionStateBBRStartup).String()).To(Equal("startup"))
+		Expect(congestionState(logging.CongestionStateBBRDrain).String()).To(Equal("drain"))
+		Expect(congestionState(logging.CongestionStateBBRProbeBW).String()).To(Equal("probe_bw"))
+		Expect(congestionState(logging.CongestionStateBBRProbeRTT).String()).To(Equal("probe_rtt"))
 	})
 
 	It("has a string representation for the ECN bits", func() {
@@ -36,11 +36,15 @@ type receiveStream struct {
 	currentFrameDone   func()
 	readPosInFrame     int
 	currentFrameIsLast bool // is the currentFrame the last frame on this stream
+	readOffset         protocol.ByteCount
 
 	finRead             bool // set once we read a frame with a Fin
 	closeForShutdownErr error
 	cancelReadErr       error
 	resetRemotelyErr    *StreamError
+	// set when a RESET_STREAM_AT frame was received, until the application has read the data up to the reliable size
+	reliableResetErr *StreamError
+	reliableSize     protocol.ByteCount
 
 	readChan chan struct{}
 	readOnce chan struct{} // cap: 1, to protect against concurrent use of Read
@@ -169,6 +173,7 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 
 		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
 		s.readPosInFrame += m
+		s.readOffset += protocol.ByteCount(m)
 		bytesRead += m
 
 		// when a RESET_STREAM was received, the flow controller was already
@@ -178,11 +183,17 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 		}
 
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
-			s.finRead = true
 			s.currentFrame = nil
 			if s.currentFrameDone != nil {
 				s.currentFrameDone()
 			}
+			// All data up to the reliable size of a RESET_STREAM_AT frame was read.
+			if s.reliableResetErr != nil {
+				s.resetRemotelyErr = s.reliableResetErr
+				s.flowController.Abandon()
+				return true, bytesRead, s.resetRemotelyErr
+			}
+			s.finRead = true
 			return true, bytesRead, io.EOF
 		}
 	}
@@ -196,8 +207,21 @@ func (s *receiveStream) dequeueNextFrame() {
 		s.currentFrameDone()
 	}
 	offset, s.currentFrame, s.currentFrameDone = s.frameQueue.Pop()
-	s.currentFrameIsLast = offset+protocol.ByteCount(len(s.currentFrame)) >= s.finalOffset
 	s.readPosInFrame = 0
+	s.limitCurrentFrame(offset)
+}
+
+// limitCurrentFrame cuts off data beyond the reliable size of a RESET_STREAM_AT frame,
+// and determines if the current frame is the last frame that is delivered to the application.
+func (s *receiveStream) limitCurrentFrame(offset protocol.ByteCount) {
+	end := s.finalOffset
+	if s.reliableResetErr != nil {
+		end = s.reliableSize
+	}
+	if s.currentFrame != nil && offset+protocol.ByteCount(len(s.currentFrame)) > end {
+		s.currentFrame = s.currentFrame[:max(end, offset)-offset]
+	}
+	s.currentFrameIsLast = offset+protocol.ByteCount(len(s.currentFrame)) >= end
 }
 
 func (s *receiveStream) CancelRead(errorCode StreamErrorCode) {
@@ -283,13 +307,30 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	if s.resetRemotelyErr != nil {
 		return false, nil
 	}
-	s.resetRemotelyErr = &StreamError{
+	// A RESET_STREAM_AT frame can reduce, but not increase the reliable size.
+	if s.reliableResetErr != nil && frame.ReliableSize >= s.reliableSize {
+		return false, nil
+	}
+	err := &StreamError{
 		StreamID:  s.streamID,
 		ErrorCode: frame.ErrorCode,
 		Remote:    true,
 	}
+	// Data up to the reliable size is delivered to the application before the reset is reported.
+	if frame.ReliableSize > s.readOffset && s.cancelReadErr == nil {
+		s.reliableResetErr = err
+		s.reliableSize = frame.ReliableSize
+		if s.currentFrame != nil {
+			s.limitCurrentFrame(s.readOffset - protocol.ByteCount(s.readPosInFrame))
+		}
+		s.signalRead()
+		return false, nil
+	}
+	// If a RESET_STREAM_AT frame was received before, the stream wasn't completed yet.
+	completed := newlyRcvdFinalOffset || (s.reliableResetErr != nil && s.cancelReadErr == nil)
+	s.resetRemotelyErr = err
 	s.signalRead()
-	return newlyRcvdFinalOffset, nil
+	return completed, nil
 }
 
 func (s *receiveStream) SetReadDeadline(t time.Time) error {
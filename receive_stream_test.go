@@ -676,6 +676,121 @@ var _ = Describe("Receive Stream", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamFrame{
+				StreamID:     streamID,
+				FinalSize:    42,
+				ErrorCode:    1234,
+				ReliableSize: 6,
+			}
+			resetErr := &StreamError{StreamID: streamID, ErrorCode: 1234, Remote: true}
+
+			It("delivers data up to the reliable size before reporting the reset", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(resetErr))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError(resetErr))
+			})
+
+			It("waits for data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 10)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError(resetErr))
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("reports the reset immediately if the data up to the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				_, err := strWithTimeout.Read(make([]byte, 6))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				_, err = strWithTimeout.Read(make([]byte, 6))
+				Expect(err).To(MatchError(resetErr))
+			})
+
+			It("cuts the current frame at the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				b := make([]byte, 3)
+				_, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b).To(Equal([]byte("foo")))
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b = make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(resetErr))
+				Expect(b[:n]).To(Equal([]byte("bar")))
+			})
+
+			It("only allows reducing the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(3)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarfoob")})).To(Succeed())
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 42, ErrorCode: 1234, ReliableSize: 8})).To(Succeed())
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 42, ErrorCode: 1234, ReliableSize: 3})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError(resetErr))
+				Expect(b[:n]).To(Equal([]byte("foo")))
+			})
+
+			It("completes the stream when a RESET_STREAM frame is received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(2)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{StreamID: streamID, FinalSize: 42, ErrorCode: 1234})).To(Succeed())
+				_, err := strWithTimeout.Read(make([]byte, 10))
+				Expect(err).To(MatchError(resetErr))
+			})
+
+			It("doesn't deliver any data if reading was canceled", func() {
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				str.CancelRead(4321)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+			})
+		})
 	})
 
 	Context("flow control", func() {
@@ -10,7 +10,8 @@ import (
 // TODO (ddritzenhoff) do a find-f for datagram once you've added everything.
 
 const (
-	// TODO (ddritzenhoff) maybe make this based on the schema, somehow?
+	// maxRepairSendQueueLen is the default number of REPAIR frames that can be queued.
+	// The limit is raised for schemes that generate more repair symbols per block, see Reserve.
 	maxRepairSendQueueLen = 32
 )
 
@@ -18,6 +19,7 @@ type repairQueue struct {
 	sendMx    sync.Mutex
 	sendQueue ringbuffer.RingBuffer[*wire.RepairFrame]
 	sent      chan struct{} // used to notify Add that a repair frame was dequeued
+	maxLen    int
 
 	// TODO (ddritzenhoff) I'm pretty sure I don't need a receive queue, as I'd immediately just add the repair from to the fecManager block.
 
@@ -32,18 +34,27 @@ func newRepairQueue(hasData func()) *repairQueue {
 	return &repairQueue{
 		hasData: hasData,
 		sent:    make(chan struct{}, 1),
+		maxLen:  maxRepairSendQueueLen,
 		closed:  make(chan struct{}),
 	}
 }
 
+// Reserve makes sure that at least n REPAIR frames can be queued.
+// It is used for schemes that generate more repair symbols for a block than fit into the default queue.
+func (h *repairQueue) Reserve(n int) {
+	h.sendMx.Lock()
+	defer h.sendMx.Unlock()
+	h.maxLen = max(h.maxLen, n)
+}
+
 // Add queues a new REPAIR frame for sending.
-// Up to 32 REPAIR frames will be queued.
+// Up to 32 REPAIR frames will be queued, unless more were reserved.
 // Once that limit is reached, Add blocks until the queue size has reduced.
 func (h *repairQueue) Add(f *wire.RepairFrame) error {
 	h.sendMx.Lock()
 
 	for {
-		if h.sendQueue.Len() < maxRepairSendQueueLen {
+		if h.sendQueue.Len() < h.maxLen {
 			h.sendQueue.PushBack(f)
 			h.sendMx.Unlock()
 			h.hasData()
@@ -72,6 +83,13 @@ func (h *repairQueue) Add(f *wire.RepairFrame) error {
 	}
 }
 
+// Available returns the number of REPAIR frames that can be added before the queue is full.
+func (h *repairQueue) Available() int {
+	h.sendMx.Lock()
+	defer h.sendMx.Unlock()
+	return h.maxLen - h.sendQueue.Len()
+}
+
 // Peek gets the next REPAIR frame for sending.
 // If actually sent out, Pop needs to be called before the next call to Peek.
 func (h *repairQueue) Peek() *wire.RepairFrame {
@@ -93,6 +111,13 @@ func (h *repairQueue) Pop() {
 	}
 }
 
+// Clear drops all queued REPAIR frames.
+func (h *repairQueue) Clear() {
+	h.sendMx.Lock()
+	defer h.sendMx.Unlock()
+	h.sendQueue.Clear()
+}
+
 func (h *repairQueue) CloseWithError(e error) {
 	h.closeErr = e
 	close(h.closed)
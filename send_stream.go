@@ -2,6 +2,7 @@ package quic
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -21,6 +22,8 @@ type sendStreamI interface {
 	popStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (frame ackhandler.StreamFrame, ok, hasMore bool)
 	closeForShutdown(error)
 	updateSendWindow(protocol.ByteCount)
+	// fecProtectionClass returns the protection class of the STREAM frames, and if they are FEC protected at all.
+	fecProtectionClass() (protocol.FECProtectionClass, bool)
 }
 
 type sendStream struct {
@@ -39,6 +42,9 @@ type sendStream struct {
 
 	cancelWriteErr      error
 	closeForShutdownErr error
+	// reliableSize is set when the stream is reset using a RESET_STREAM_AT frame.
+	// Data up to this offset is still delivered reliably.
+	reliableSize protocol.ByteCount
 
 	finishedWriting bool // set once Close() is called
 	finSent         bool // set when a STREAM_FRAME with FIN bit has been sent
@@ -54,6 +60,7 @@ type sendStream struct {
 	flowController flowcontrol.StreamFlowController
 
 	fecProtected bool
+	fecClass     protocol.FECProtectionClass
 }
 
 var (
@@ -141,7 +148,7 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		if s.cancelWriteErr == nil && s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -225,6 +232,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 	if f != nil {
 		s.numOutstandingFrames++
 	}
+	fecClass := s.fecClass
 	s.mutex.Unlock()
 
 	if f == nil {
@@ -232,6 +240,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 	}
 	// A stream will always be either FEC protected or not at all. Therefore, it's safe to set the `FECProtected` field as all the frames here are a product of this stream.
 	f.FECProtected = s.fecProtected
+	f.FECProtectionClass = fecClass
 	return ackhandler.StreamFrame{
 		Frame:   f,
 		Handler: (*sendStreamAckHandler)(s),
@@ -239,7 +248,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+	if (s.cancelWriteErr != nil && s.reliableSize == 0) || s.closeForShutdownErr != nil {
 		return nil, false
 	}
 
@@ -255,7 +264,13 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		}
 	}
 
-	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
+	if s.cancelWriteErr != nil {
+		// After a RESET_STREAM_AT, only the data up to the reliable size is sent.
+		// This data was buffered in nextFrame when the stream was reset.
+		if s.nextFrame == nil {
+			return nil, false
+		}
+	} else if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
 			return &wire.StreamFrame{
@@ -285,6 +300,9 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
 	}
+	if s.cancelWriteErr != nil {
+		return f, s.nextFrame != nil
+	}
 	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
 	if f.Fin {
 		s.finSent = true
@@ -372,6 +390,10 @@ func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.By
 
 func (s *sendStream) isNewlyCompleted() bool {
 	completed := (s.finSent || s.cancelWriteErr != nil) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0
+	// after a RESET_STREAM_AT, all data up to the reliable size needs to be sent (and acknowledged)
+	if s.reliableSize > 0 && s.nextFrame != nil {
+		completed = false
+	}
 	if completed && !s.completed {
 		s.completed = true
 		return true
@@ -426,6 +448,83 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	}
 }
 
+func (s *sendStream) CancelWriteAt(errorCode StreamErrorCode, reliableSize uint64) error {
+	if reliableSize == 0 {
+		s.cancelWriteImpl(errorCode, false)
+		return nil
+	}
+	if !s.sender.supportsResetStreamAt() {
+		return errors.New("peer doesn't support RESET_STREAM_AT")
+	}
+
+	s.mutex.Lock()
+	if s.closeForShutdownErr != nil {
+		s.mutex.Unlock()
+		return s.closeForShutdownErr
+	}
+	if s.cancelWriteErr != nil {
+		s.mutex.Unlock()
+		return nil
+	}
+	written := s.writeOffset
+	if s.nextFrame != nil {
+		written += s.nextFrame.DataLen()
+	}
+	if protocol.ByteCount(reliableSize) > written {
+		s.mutex.Unlock()
+		return fmt.Errorf("reliable size (%d) larger than the amount of data written (%d)", reliableSize, written)
+	}
+	s.reliableSize = protocol.ByteCount(reliableSize)
+	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
+	s.ctxCancel(s.cancelWriteErr)
+	// Drop all data beyond the reliable size.
+	// Data that was already sent is retransmitted if it is lost, but only up to the reliable size.
+	retransmissionQueue := s.retransmissionQueue[:0]
+	for _, f := range s.retransmissionQueue {
+		if s.trimToReliableSize(f) {
+			retransmissionQueue = append(retransmissionQueue, f)
+		}
+	}
+	s.retransmissionQueue = retransmissionQueue
+	if s.nextFrame != nil && !s.trimToReliableSize(s.nextFrame) {
+		s.nextFrame = nil
+	}
+	hasData := s.nextFrame != nil || len(s.retransmissionQueue) > 0
+	resetFrame := &wire.ResetStreamFrame{
+		StreamID:     s.streamID,
+		FinalSize:    max(s.writeOffset, s.reliableSize),
+		ErrorCode:    errorCode,
+		ReliableSize: s.reliableSize,
+	}
+	newlyCompleted := s.isNewlyCompleted()
+	s.mutex.Unlock()
+
+	s.signalWrite()
+	s.sender.queueControlFrame(resetFrame)
+	if hasData {
+		s.sender.onHasStreamData(s.streamID)
+	}
+	if newlyCompleted {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return nil
+}
+
+// trimToReliableSize removes all data beyond the reliable size from a STREAM frame.
+// It returns false if the frame doesn't contain any data below the reliable size.
+// The frame is returned to the pool in that case.
+func (s *sendStream) trimToReliableSize(f *wire.StreamFrame) bool {
+	if f.Offset >= s.reliableSize {
+		f.PutBack()
+		return false
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+	}
+	f.Fin = false
+	return true
+}
+
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	updated := s.flowController.UpdateSendWindow(limit)
 	if !updated { // duplicate or reordered MAX_STREAM_DATA frame
@@ -455,6 +554,32 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetFECProtectionClass(class protocol.FECProtectionClass) error {
+	if class > protocol.MaxFECProtectionClass {
+		return fmt.Errorf("invalid FEC protection class: %d", class)
+	}
+	s.mutex.Lock()
+	s.fecClass = class
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *sendStream) SetPriority(prio protocol.StreamPriority) {
+	s.mutex.Lock()
+	completed := s.completed
+	s.mutex.Unlock()
+	if completed {
+		return
+	}
+	s.sender.onStreamPriorityChanged(s.streamID, prio)
+}
+
+func (s *sendStream) fecProtectionClass() (protocol.FECProtectionClass, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.fecClass, s.fecProtected
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -482,7 +607,7 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	sf.PutBack()
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
@@ -501,16 +626,25 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 func (s *sendStreamAckHandler) OnLost(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	sf.DataLenPresent = true
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	if s.cancelWriteErr != nil && !(*sendStream)(s).trimToReliableSize(sf) {
+		// the frame only contained data beyond the reliable size
+		newlyCompleted := (*sendStream)(s).isNewlyCompleted()
+		s.mutex.Unlock()
+		if newlyCompleted {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	sf.DataLenPresent = true
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID)
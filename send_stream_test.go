@@ -74,6 +74,42 @@ var _ = Describe("Send Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("sets the priority", func() {
+		prio := protocol.StreamPriority{Urgency: 1, Incremental: true, Weight: 4}
+		mockSender.EXPECT().onStreamPriorityChanged(streamID, prio)
+		str.SetPriority(prio)
+	})
+
+	It("doesn't set the priority after the stream completed", func() {
+		mockSender.EXPECT().queueControlFrame(gomock.Any())
+		mockSender.EXPECT().onStreamCompleted(streamID)
+		str.CancelWrite(1234)
+		str.SetPriority(protocol.StreamPriority{Urgency: 1})
+	})
+
+	It("sets the FEC protection class of STREAM frames", func() {
+		str = newSendStreamWithFEC(streamID, mockSender, mockFC, true)
+		class, protected := str.fecProtectionClass()
+		Expect(protected).To(BeTrue())
+		Expect(class).To(Equal(protocol.FECProtectionDefault))
+		Expect(str.SetFECProtectionClass(protocol.FECProtectionHigh)).To(Succeed())
+		class, _ = str.fecProtectionClass()
+		Expect(class).To(Equal(protocol.FECProtectionHigh))
+		Expect(str.SetFECProtectionClass(protocol.MaxFECProtectionClass + 1)).To(MatchError("invalid FEC protection class: 3"))
+		class, _ = str.fecProtectionClass()
+		Expect(class).To(Equal(protocol.FECProtectionHigh))
+
+		mockSender.EXPECT().onHasStreamData(streamID)
+		go str.Write([]byte("foobar"))
+		waitForWrite()
+		mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+		mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+		frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+		Expect(ok).To(BeTrue())
+		Expect(frame.Frame.FECProtected).To(BeTrue())
+		Expect(frame.Frame.FECProtectionClass).To(Equal(protocol.FECProtectionHigh))
+	})
+
 	Context("writing", func() {
 		It("writes and gets all data at once", func() {
 			done := make(chan struct{})
@@ -902,6 +938,170 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("resetting with a reliable size", func() {
+			It("errors if the peer doesn't support RESET_STREAM_AT", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				Expect(str.CancelWriteAt(1234, 10)).To(MatchError("peer doesn't support RESET_STREAM_AT"))
+			})
+
+			It("errors if the reliable size is larger than the amount of data written", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				Expect(str.CancelWriteAt(1234, 101)).To(MatchError("reliable size (101) larger than the amount of data written (100)"))
+			})
+
+			It("sends a RESET_STREAM frame if the reliable size is 0", func() {
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{StreamID: streamID, ErrorCode: 1234})
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.CancelWriteAt(1234, 0)).To(Succeed())
+			})
+
+			It("sends data up to the reliable size, and retransmits it", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+50, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame1.Frame.DataLen()).To(BeEquivalentTo(50))
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:     streamID,
+						ErrorCode:    1234,
+						FinalSize:    70,
+						ReliableSize: 70,
+					}),
+					mockSender.EXPECT().onHasStreamData(streamID),
+				)
+				Expect(str.CancelWriteAt(1234, 70)).To(Succeed())
+				Expect(str.Context().Done()).To(BeClosed())
+				_, err = str.Write([]byte("foobar"))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+
+				frame2, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(frame2.Frame.Offset).To(BeEquivalentTo(50))
+				Expect(frame2.Frame.Data).To(Equal(getDataAtOffset(50, 20)))
+				Expect(frame2.Frame.Fin).To(BeFalse())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+
+				// lost data below the reliable size is retransmitted
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.Handler.OnLost(frame1.Frame)
+				frame3, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame3.Frame.Offset).To(BeZero())
+				Expect(frame3.Frame.Data).To(Equal(getData(50)))
+
+				frame2.Handler.OnAcked(frame2.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame3.Handler.OnAcked(frame3.Frame)
+			})
+
+			It("doesn't retransmit data beyond the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+60, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame2.Frame.DataLen()).To(BeEquivalentTo(40))
+
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    100,
+					ReliableSize: 42,
+				})
+				Expect(str.CancelWriteAt(1234, 42)).To(Succeed())
+
+				// the frame only contains data beyond the reliable size
+				frame2.Handler.OnLost(frame2.Frame)
+				// the frame is cut at the reliable size
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.Handler.OnLost(frame1.Frame)
+				frame3, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame3.Frame.Offset).To(BeZero())
+				Expect(frame3.Frame.Data).To(Equal(getData(42)))
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame3.Handler.OnAcked(frame3.Frame)
+			})
+
+			It("unblocks Write, and doesn't send data that wasn't accepted", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(gomock.Any())
+				writeReturned := make(chan struct{})
+				var n int
+				go func() {
+					defer GinkgoRecover()
+					var err error
+					n, err = strWithTimeout.Write(getData(5000))
+					Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+					close(writeReturned)
+				}()
+				waitForWrite()
+				frame, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+50, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    50,
+					ReliableSize: 50,
+				})
+				Expect(str.CancelWriteAt(1234, 50)).To(Succeed())
+				Eventually(writeReturned).Should(BeClosed())
+				Expect(n).To(Equal(50))
+				_, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				Expect(hasMore).To(BeFalse())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame.Handler.OnAcked(frame.Frame)
+			})
+
+			It("doesn't send a FIN", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(3)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				Expect(str.CancelWriteAt(1234, 100)).To(Succeed())
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(100))
+				frame, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(frame.Frame.DataLen()).To(BeEquivalentTo(100))
+				Expect(frame.Frame.Fin).To(BeFalse())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+			})
+
+			It("only resets once", func() {
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWrite(1234)
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				Expect(str.CancelWriteAt(4321, 0x1337)).To(Succeed())
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
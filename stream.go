@@ -25,6 +25,9 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	queueControlFrame(wire.Frame)
 	onHasStreamData(protocol.StreamID)
+	onStreamPriorityChanged(protocol.StreamID, protocol.StreamPriority)
+	// supportsResetStreamAt says if RESET_STREAM_AT frames can be sent
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -44,6 +47,14 @@ func (s *uniStreamSender) onHasStreamData(id protocol.StreamID) {
 	s.streamSender.onHasStreamData(id)
 }
 
+func (s *uniStreamSender) onStreamPriorityChanged(id protocol.StreamID, prio protocol.StreamPriority) {
+	s.streamSender.onStreamPriorityChanged(id, prio)
+}
+
+func (s *uniStreamSender) supportsResetStreamAt() bool {
+	return s.streamSender.supportsResetStreamAt()
+}
+
 func (s *uniStreamSender) onStreamCompleted(protocol.StreamID) {
 	s.onStreamCompletedImpl()
 }
@@ -62,6 +73,7 @@ type streamI interface {
 	handleStopSendingFrame(*wire.StopSendingFrame)
 	popStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (ackhandler.StreamFrame, bool, bool)
 	updateSendWindow(protocol.ByteCount)
+	fecProtectionClass() (protocol.FECProtectionClass, bool)
 }
 
 var (